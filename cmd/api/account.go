@@ -15,6 +15,7 @@ type createAccountRequest struct {
 	AccountType   int64  `json:"account_type" validate:"required"`
 	BuildingID    int64  `json:"building_id" validate:"required"`
 	IsDefault     int   `json:"isDefault"`
+	Currency      *string `json:"currency" validate:"omitempty,len=3,alpha,uppercase"` // omit for the base currency
 }
 
 type updateAccountRequest struct {
@@ -23,6 +24,7 @@ type updateAccountRequest struct {
 	AccountType   int64  `json:"account_type" validate:"required"`
 	BuildingID    int64  `json:"building_id" validate:"required"`
 	IsDefault     int   `json:"isDefault"`
+	Currency      *string `json:"currency" validate:"omitempty,len=3,alpha,uppercase"` // omit for the base currency
 }
 
 // Handlers
@@ -92,6 +94,7 @@ func (app *application) createAccountHandler(w http.ResponseWriter, r *http.Requ
 		AccountType:   req.AccountType,
 		BuildingID:    req.BuildingID,
 		IsDefault:     req.IsDefault,
+		Currency:      req.Currency,
 	}
 
 	if err := app.service.Account.Create(r.Context(), account); err != nil {
@@ -130,6 +133,7 @@ func (app *application) updateAccountHandler(w http.ResponseWriter, r *http.Requ
 		AccountType:   req.AccountType,
 		BuildingID:    req.BuildingID,
 		IsDefault:     req.IsDefault,
+		Currency:      req.Currency,
 	}
 
 	if err := app.service.Account.Update(r.Context(), account); err != nil {
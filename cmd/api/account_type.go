@@ -9,17 +9,19 @@ import (
 )
 
 type createAccountTypeRequest struct {
-	TypeName   string `json:"typeName" validate:"required"`
-	Type       string `json:"type" validate:"required"`
-	SubType    string `json:"sub_type" validate:"required"`
-	TypeStatus string `json:"typeStatus" validate:"required"`
+	TypeName         string `json:"typeName" validate:"required"`
+	Type             string `json:"type" validate:"required"`
+	SubType          string `json:"sub_type" validate:"required"`
+	TypeStatus       string `json:"typeStatus" validate:"required"`
+	CashFlowCategory string `json:"cash_flow_category" validate:"omitempty,oneof=cash operating investing financing non_cash"`
 }
 
 type updateAccountTypeRequest struct {
-	TypeName   string `json:"typeName" validate:"required"`
-	Type       string `json:"type" validate:"required"`
-	SubType    string `json:"sub_type" validate:"required"`
-	TypeStatus string `json:"typeStatus" validate:"required"`
+	TypeName         string `json:"typeName" validate:"required"`
+	Type             string `json:"type" validate:"required"`
+	SubType          string `json:"sub_type" validate:"required"`
+	TypeStatus       string `json:"typeStatus" validate:"required"`
+	CashFlowCategory string `json:"cash_flow_category" validate:"omitempty,oneof=cash operating investing financing non_cash"`
 }
 
 func (app *application) getAccountTypesHandler(w http.ResponseWriter, r *http.Request) {
@@ -76,10 +78,11 @@ func (app *application) createAccountTypeHandler(w http.ResponseWriter, r *http.
 	}
 
 	at := &store.AccountType{
-		TypeName:   req.TypeName,
-		Type:       req.Type,
-		SubType:    req.SubType,
-		TypeStatus: req.TypeStatus,
+		TypeName:         req.TypeName,
+		Type:             req.Type,
+		SubType:          req.SubType,
+		TypeStatus:       req.TypeStatus,
+		CashFlowCategory: req.CashFlowCategory,
 	}
 
 	if err := app.service.AccountType.Create(r.Context(), at); err != nil {
@@ -112,11 +115,12 @@ func (app *application) updateAccountTypeHandler(w http.ResponseWriter, r *http.
 	}
 
 	at := &store.AccountType{
-		ID:         id,
-		TypeName:   req.TypeName,
-		Type:       req.Type,
-		SubType:    req.SubType,
-		TypeStatus: req.TypeStatus,
+		ID:               id,
+		TypeName:         req.TypeName,
+		Type:             req.Type,
+		SubType:          req.SubType,
+		TypeStatus:       req.TypeStatus,
+		CashFlowCategory: req.CashFlowCategory,
 	}
 
 	if err := app.service.AccountType.Update(r.Context(), at); err != nil {
@@ -119,6 +119,8 @@ func (app *application) mount() http.Handler {
 			r.Route("/{buildingID}", func(r chi.Router) {
 				r.Get("/", app.getBuildingHandler)
 				r.Put("/", app.updateBuildingHandler)
+				r.Put("/payment-settings", app.updateBuildingPaymentSettingsHandler)
+				r.Put("/currency-settings", app.updateBuildingCurrencySettingsHandler)
 				r.Delete("/", app.deleteBuildingHandler)
 				r.Get("/available-units", app.getAvailableUnitsByBuildingIDHandler)
 
@@ -143,6 +145,7 @@ func (app *application) mount() http.Handler {
 						r.Get("/units", app.getUnitsByPeopleHandler)
 						r.Get("/available-credits", app.getAvailableCreditsHandler)
 						r.Put("/", app.updatePersonHandler)
+						r.Put("/withholding", app.setPersonWithholdingHandler)
 						r.Delete("/", app.deletePersonHandler)
 					})
 				})
@@ -166,6 +169,16 @@ func (app *application) mount() http.Handler {
 						r.Get("/", app.getItemHandler)
 						r.Put("/", app.updateItemHandler)
 						r.Delete("/", app.deleteItemHandler)
+						r.Get("/movements", app.getItemMovementsHandler)
+					})
+				})
+
+				r.Route("/inventory-adjustments", func(r chi.Router) {
+					r.Get("/", app.getInventoryAdjustmentsHandler)
+					r.Post("/", app.createInventoryAdjustmentHandler)
+					r.Route("/{adjustmentID}", func(r chi.Router) {
+						r.Get("/", app.getInventoryAdjustmentHandler)
+						r.Put("/", app.updateInventoryAdjustmentHandler)
 					})
 				})
 
@@ -186,6 +199,99 @@ func (app *application) mount() http.Handler {
 					})
 				})
 
+				r.Route("/tax-codes", func(r chi.Router) {
+					r.Get("/", app.getTaxCodesHandler)
+					r.Post("/", app.createTaxCodeHandler)
+					r.Route("/{taxCodeID}", func(r chi.Router) {
+						r.Get("/", app.getTaxCodeHandler)
+						r.Put("/", app.updateTaxCodeHandler)
+						r.Delete("/", app.deleteTaxCodeHandler)
+					})
+				})
+
+				r.Route("/exchange-rates", func(r chi.Router) {
+					r.Get("/", app.getExchangeRatesHandler)
+					r.Post("/", app.saveExchangeRateHandler)
+					r.Delete("/{rateID}", app.deleteExchangeRateHandler)
+				})
+
+				r.Route("/fx-revaluations", func(r chi.Router) {
+					r.Get("/", app.getFXRevaluationsHandler)
+					r.Post("/", app.createFXRevaluationHandler)
+					r.Get("/{revaluationID}", app.getFXRevaluationHandler)
+				})
+
+				r.Route("/late-fee-policies", func(r chi.Router) {
+					r.Get("/", app.getLateFeePoliciesHandler)
+					r.Post("/", app.createLateFeePolicyHandler)
+					r.Route("/{policyID}", func(r chi.Router) {
+						r.Get("/", app.getLateFeePolicyHandler)
+						r.Put("/", app.updateLateFeePolicyHandler)
+						r.Delete("/", app.deleteLateFeePolicyHandler)
+					})
+				})
+
+				r.Route("/late-fees", func(r chi.Router) {
+					r.Get("/", app.getLateFeeAssessmentsHandler)
+					r.Post("/preview", app.previewLateFeesHandler)
+					r.Post("/run", app.runLateFeesHandler)
+				})
+
+				r.Route("/receive-payments", func(r chi.Router) {
+					r.Get("/", app.getReceivedPaymentsHandler)
+					r.Post("/", app.createReceivedPaymentHandler)
+					r.Post("/preview", app.previewReceivedPaymentHandler)
+					r.Get("/{receivedPaymentID}", app.getReceivedPaymentHandler)
+				})
+
+				r.Route("/deposits", func(r chi.Router) {
+					r.Get("/", app.getDepositsHandler)
+					r.Post("/", app.createDepositHandler)
+					r.Get("/undeposited", app.getUndepositedFundsHandler)
+					r.Get("/{depositID}", app.getDepositHandler)
+				})
+
+				r.Route("/bank-statements", func(r chi.Router) {
+					r.Get("/imports", app.getBankStatementImportsHandler)
+					r.Post("/imports", app.importBankStatementHandler)
+					r.Get("/lines", app.getBankStatementLinesHandler)
+					r.Post("/auto-match", app.autoMatchBankStatementHandler)
+					r.Post("/apply-rules", app.applyBankRulesHandler)
+					r.Route("/lines/{lineID}", func(r chi.Router) {
+						r.Get("/candidates", app.getBankStatementLineCandidatesHandler)
+						r.Post("/match", app.matchBankStatementLineHandler)
+						r.Post("/reset", app.resetBankStatementLineHandler)
+						r.Post("/ignore", app.ignoreBankStatementLineHandler)
+						r.Post("/check", app.createCheckFromBankStatementLineHandler)
+						r.Post("/receipt", app.createReceiptFromBankStatementLineHandler)
+						r.Get("/draft", app.getBankStatementLineDraftHandler)
+						r.Post("/draft", app.postBankStatementLineDraftHandler)
+					})
+				})
+
+				r.Route("/bank-rules", func(r chi.Router) {
+					r.Get("/", app.getBankRulesHandler)
+					r.Post("/", app.createBankRuleHandler)
+					r.Route("/{bankRuleID}", func(r chi.Router) {
+						r.Get("/", app.getBankRuleHandler)
+						r.Put("/", app.updateBankRuleHandler)
+						r.Delete("/", app.deleteBankRuleHandler)
+					})
+				})
+
+				r.Route("/reconciliations", func(r chi.Router) {
+					r.Get("/", app.getReconciliationsHandler)
+					r.Post("/", app.createReconciliationHandler)
+					r.Route("/{reconciliationID}", func(r chi.Router) {
+						r.Get("/", app.getReconciliationHandler)
+						r.Put("/", app.updateReconciliationHandler)
+						r.Delete("/", app.deleteReconciliationHandler)
+						r.Put("/items", app.clearReconciliationItemsHandler)
+						r.Post("/finish", app.finishReconciliationHandler)
+						r.Get("/report", app.getReconciliationReportHandler)
+					})
+				})
+
 				r.Route("/invoice-payments", func(r chi.Router) {
 					r.Post("/", app.createInvoicePaymentHandler)
 					r.Get("/", app.getInvoicePaymentsHandler)
@@ -229,6 +335,9 @@ func (app *application) mount() http.Handler {
 					r.Route("/{checkID}", func(r chi.Router) {
 						r.Get("/", app.getCheckHandler)
 						r.Put("/", app.updateCheckHandler)
+						r.Get("/approval", app.getCheckApprovalHandler)
+						r.Post("/approve", app.approveCheckHandler)
+						r.Post("/reject", app.rejectCheckHandler)
 						// r.Delete("/", app.deleteCheckHandler)
 					})
 				})
@@ -239,6 +348,46 @@ func (app *application) mount() http.Handler {
 					r.Route("/{billID}", func(r chi.Router) {
 						r.Get("/", app.getBillHandler)
 						r.Put("/", app.updateBillHandler)
+						r.Get("/available-credits", app.getBillAvailableCreditsHandler)
+						r.Get("/applied-credits", app.getBillAppliedCreditsHandler)
+						r.Post("/apply-credit", app.applyBillCreditHandler)
+						r.Get("/approval", app.getBillApprovalHandler)
+						r.Post("/approve", app.approveBillHandler)
+						r.Post("/reject", app.rejectBillHandler)
+					})
+				})
+
+				r.Route("/purchase-orders", func(r chi.Router) {
+					r.Get("/", app.getPurchaseOrdersHandler)
+					r.Post("/", app.createPurchaseOrderHandler)
+					r.Route("/{poID}", func(r chi.Router) {
+						r.Get("/", app.getPurchaseOrderHandler)
+						r.Put("/", app.updatePurchaseOrderHandler)
+						r.Delete("/", app.deletePurchaseOrderHandler)
+						r.Post("/close", app.closePurchaseOrderHandler)
+						r.Post("/reopen", app.reopenPurchaseOrderHandler)
+						r.Get("/bill", app.getPurchaseOrderBillDraftHandler)
+						r.Post("/match", app.matchPurchaseOrderBillHandler)
+					})
+				})
+
+				r.Route("/approval-thresholds", func(r chi.Router) {
+					r.Get("/", app.getApprovalThresholdsHandler)
+					r.Post("/", app.createApprovalThresholdHandler)
+					r.Route("/{thresholdID}", func(r chi.Router) {
+						r.Get("/", app.getApprovalThresholdHandler)
+						r.Put("/", app.updateApprovalThresholdHandler)
+						r.Delete("/", app.deleteApprovalThresholdHandler)
+					})
+				})
+
+				r.Route("/vendor-credits", func(r chi.Router) {
+					r.Get("/", app.getVendorCreditsHandler)
+					r.Post("/", app.createVendorCreditHandler)
+					r.Delete("/applications/{applicationID}", app.deleteBillAppliedCreditHandler)
+					r.Route("/{vendorCreditID}", func(r chi.Router) {
+						r.Get("/", app.getVendorCreditHandler)
+						r.Put("/", app.updateVendorCreditHandler)
 					})
 				})
 
@@ -251,6 +400,30 @@ func (app *application) mount() http.Handler {
 					})
 				})
 
+				r.Route("/bill-payment-runs", func(r chi.Router) {
+					r.Get("/", app.getBillPaymentRunsHandler)
+					r.Post("/", app.createBillPaymentRunHandler)
+					r.Get("/payable-bills", app.getPayableBillsHandler)
+					r.Get("/{runID}", app.getBillPaymentRunHandler)
+				})
+
+				r.Route("/recurring-bills", func(r chi.Router) {
+					r.Get("/", app.getBillTemplatesHandler)
+					r.Post("/", app.createBillTemplateHandler)
+					r.Post("/preview", app.previewRecurringBillsHandler)
+					r.Post("/run", app.runRecurringBillsHandler)
+					r.Route("/runs", func(r chi.Router) {
+						r.Get("/", app.getBillTemplateRunsHandler)
+						r.Post("/{runID}/approve", app.approveBillTemplateRunHandler)
+						r.Post("/{runID}/reject", app.rejectBillTemplateRunHandler)
+					})
+					r.Route("/{templateID}", func(r chi.Router) {
+						r.Get("/", app.getBillTemplateHandler)
+						r.Put("/", app.updateBillTemplateHandler)
+						r.Delete("/", app.deleteBillTemplateHandler)
+					})
+				})
+
 				r.Route("/journals", func(r chi.Router) {
 					r.Get("/", app.getJournalsHandler)
 					r.Post("/", app.createJournalHandler)
@@ -275,11 +448,22 @@ func (app *application) mount() http.Handler {
 					r.Get("/trial-balance", app.getTrialBalanceHandler)
 					r.Get("/customer-balance-summary", app.getCustomerBalanceSummaryHandler)
 					r.Get("/customer-balance-detail", app.getCustomerBalanceDetailHandler)
+					r.Get("/customer-statement", app.getCustomerStatementHandler)
+					r.Get("/customer-statements", app.getCustomerStatementsHandler)
+					r.Get("/ar-aging", app.getARAgingHandler)
 					r.Get("/vendor-balance-summary", app.getVendorBalanceSummaryHandler)
 					r.Get("/vendor-balance-detail", app.getVendorBalanceDetailHandler)
+					r.Get("/ap-aging", app.getAPAgingHandler)
+					r.Get("/bills-due", app.getBillsDueHandler)
 					r.Get("/transaction-details-by-account", app.getTransactionDetailsHandler)
 					r.Get("/profit-and-loss-standard", app.getProfitAndLossStandardHandler)
 					r.Get("/profit-and-loss-by-unit", app.getProfitAndLossByUnitHandler)
+					r.Get("/cash-flow-statement", app.getCashFlowStatementHandler)
+					r.Get("/inventory-valuation", app.getInventoryValuationHandler)
+					r.Get("/inventory-movements", app.getInventoryMovementsHandler)
+					r.Get("/tax-summary", app.getTaxSummaryHandler)
+					r.Get("/withholding-register", app.getWithholdingRegisterHandler)
+					r.Get("/withholding-certificate", app.getWithholdingCertificateHandler)
 				})
 
 			})
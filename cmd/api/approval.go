@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/env"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+// Handlers
+
+func (app *application) getApprovalThresholdsHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var documentType *string
+	if documentTypeStr := r.URL.Query().Get("document_type"); documentTypeStr != "" {
+		documentType = &documentTypeStr
+	}
+
+	thresholds, err := app.service.Approval.GetThresholds(r.Context(), buildingID, documentType)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, thresholds); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getApprovalThresholdHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "thresholdID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	threshold, err := app.service.Approval.GetThreshold(r.Context(), id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, threshold); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) createApprovalThresholdHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.CreateApprovalThresholdRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.BuildingID = buildingID
+
+	threshold, err := app.service.Approval.CreateThreshold(r.Context(), req)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusCreated, threshold); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) updateApprovalThresholdHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	thresholdID, err := strconv.ParseInt(chi.URLParam(r, "thresholdID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.UpdateApprovalThresholdRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.ID = thresholdID
+	req.BuildingID = buildingID
+
+	threshold, err := app.service.Approval.UpdateThreshold(r.Context(), req)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, threshold); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) deleteApprovalThresholdHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	thresholdID, err := strconv.ParseInt(chi.URLParam(r, "thresholdID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := app.service.Approval.DeleteThreshold(r.Context(), buildingID, thresholdID); err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) getBillApprovalHandler(w http.ResponseWriter, r *http.Request) {
+	billID, err := strconv.ParseInt(chi.URLParam(r, "billID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	approval, err := app.service.Bill.GetApproval(r.Context(), billID)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, approval); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) approveBillHandler(w http.ResponseWriter, r *http.Request) {
+	app.decideDocument(w, r, "billID", app.service.Bill.Approve)
+}
+
+func (app *application) rejectBillHandler(w http.ResponseWriter, r *http.Request) {
+	app.decideDocument(w, r, "billID", app.service.Bill.Reject)
+}
+
+func (app *application) getCheckApprovalHandler(w http.ResponseWriter, r *http.Request) {
+	checkID, err := strconv.ParseInt(chi.URLParam(r, "checkID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	approval, err := app.service.Check.GetApproval(r.Context(), checkID)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, approval); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) approveCheckHandler(w http.ResponseWriter, r *http.Request) {
+	app.decideDocument(w, r, "checkID", app.service.Check.Approve)
+}
+
+func (app *application) rejectCheckHandler(w http.ResponseWriter, r *http.Request) {
+	app.decideDocument(w, r, "checkID", app.service.Check.Reject)
+}
+
+type documentDecision func(ctx context.Context, buildingID int64, id int64, userID int64, req dto.ApprovalDecisionRequest) (*dto.ApprovalStatusResponse, error)
+
+// decideDocument takes the approver from the token and the note from the body and hands them to
+// the bill or check service
+func (app *application) decideDocument(w http.ResponseWriter, r *http.Request, param string, decide documentDecision) {
+	jwtSecret := env.GetString("JWT_SECRET", "dev_secret_change_me")
+	userID, err := getUserIDFromJWT(r, jwtSecret)
+	if err != nil {
+		app.unauthorizedErrorResponse(w, r, err)
+		return
+	}
+
+	buildingID, err := strconv.ParseInt(chi.URLParam(r, "buildingID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.ApprovalDecisionRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	approval, err := decide(r.Context(), buildingID, id, userID, req)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, approval); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
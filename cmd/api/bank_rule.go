@@ -0,0 +1,138 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+// Handlers
+
+func (app *application) getBankRulesHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	rules, err := app.service.BankRule.GetAll(r.Context(), buildingID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, rules); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getBankRuleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "bankRuleID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	rule, err := app.service.BankRule.GetByID(r.Context(), id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, rule); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) createBankRuleHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.CreateBankRuleRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.BuildingID = buildingID
+
+	rule, err := app.service.BankRule.Create(r.Context(), req)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusCreated, rule); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) updateBankRuleHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "bankRuleID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.UpdateBankRuleRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.ID = id
+	req.BuildingID = buildingID
+
+	rule, err := app.service.BankRule.Update(r.Context(), req)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, rule); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) deleteBankRuleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "bankRuleID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := app.service.BankRule.Delete(r.Context(), id); err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
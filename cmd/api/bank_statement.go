@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+// Handlers
+
+func (app *application) getBankStatementImportsHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var accountID *int64
+	if accountIDStr := r.URL.Query().Get("account_id"); accountIDStr != "" {
+		if id, err := strconv.ParseInt(accountIDStr, 10, 64); err == nil {
+			accountID = &id
+		}
+	}
+
+	imports, err := app.service.BankStatement.GetImports(r.Context(), buildingID, accountID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, imports); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getBankStatementLinesHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var accountID, importID *int64
+	var status *string
+
+	q := r.URL.Query()
+	if accountIDStr := q.Get("account_id"); accountIDStr != "" {
+		if id, err := strconv.ParseInt(accountIDStr, 10, 64); err == nil {
+			accountID = &id
+		}
+	}
+	if importIDStr := q.Get("import_id"); importIDStr != "" {
+		if id, err := strconv.ParseInt(importIDStr, 10, 64); err == nil {
+			importID = &id
+		}
+	}
+	if s := q.Get("status"); s != "" {
+		status = &s
+	}
+
+	lines, err := app.service.BankStatement.GetLines(r.Context(), buildingID, accountID, importID, status)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, lines); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getBankStatementLineCandidatesHandler(w http.ResponseWriter, r *http.Request) {
+	lineID, err := strconv.ParseInt(chi.URLParam(r, "lineID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var maxDays *int
+	if days := r.URL.Query().Get("max_days"); days != "" {
+		if d, err := strconv.Atoi(days); err == nil && d >= 0 {
+			maxDays = &d
+		}
+	}
+
+	candidates, err := app.service.BankStatement.GetCandidates(r.Context(), lineID, maxDays)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, candidates); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) importBankStatementHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.ImportBankStatementRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.BuildingID = buildingID
+
+	result, err := app.service.BankStatement.Import(r.Context(), req)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusCreated, result); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) autoMatchBankStatementHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.AutoMatchBankStatementRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	result, err := app.service.BankStatement.AutoMatch(r.Context(), buildingID, req)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, result); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) matchBankStatementLineHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.MatchBankStatementLineRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	app.bankStatementLineAction(w, r, func(ctx context.Context, lineID int64) error {
+		return app.service.BankStatement.Match(ctx, lineID, req)
+	})
+}
+
+func (app *application) resetBankStatementLineHandler(w http.ResponseWriter, r *http.Request) {
+	app.bankStatementLineAction(w, r, app.service.BankStatement.Reset)
+}
+
+func (app *application) ignoreBankStatementLineHandler(w http.ResponseWriter, r *http.Request) {
+	app.bankStatementLineAction(w, r, app.service.BankStatement.Ignore)
+}
+
+func (app *application) createCheckFromBankStatementLineHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateCheckFromStatementLineRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	app.bankStatementLineAction(w, r, func(ctx context.Context, lineID int64) error {
+		return app.service.BankStatement.CreateCheck(ctx, lineID, req)
+	})
+}
+
+func (app *application) createReceiptFromBankStatementLineHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateReceiptFromStatementLineRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	app.bankStatementLineAction(w, r, func(ctx context.Context, lineID int64) error {
+		return app.service.BankStatement.CreateReceipt(ctx, lineID, req)
+	})
+}
+
+// bankStatementLineAction runs a state change on the line in the URL and responds with the updated line
+func (app *application) bankStatementLineAction(w http.ResponseWriter, r *http.Request, action func(ctx context.Context, lineID int64) error) {
+	lineID, err := strconv.ParseInt(chi.URLParam(r, "lineID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := action(r.Context(), lineID); err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	line, err := app.service.BankStatement.GetLine(r.Context(), lineID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, line); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) applyBankRulesHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.ApplyBankRulesRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	result, err := app.service.BankStatement.ApplyRules(r.Context(), buildingID, req)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, result); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getBankStatementLineDraftHandler(w http.ResponseWriter, r *http.Request) {
+	lineID, err := strconv.ParseInt(chi.URLParam(r, "lineID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	draft, err := app.service.BankStatement.GetDraft(r.Context(), lineID)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, draft); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) postBankStatementLineDraftHandler(w http.ResponseWriter, r *http.Request) {
+	// checks need no body; receipts send their receipt number
+	var req dto.PostBankRuleDraftRequest
+	if err := readJSON(w, r, &req); err != nil && err != io.EOF {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	app.bankStatementLineAction(w, r, func(ctx context.Context, lineID int64) error {
+		return app.service.BankStatement.PostDraft(ctx, lineID, req)
+	})
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+// Handlers
+
+func (app *application) getBillPaymentRunsHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	runs, err := app.service.BillPaymentRun.GetAll(r.Context(), buildingID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, runs); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getPayableBillsHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var dueOnOrBefore *string
+	if due := r.URL.Query().Get("due_on_or_before"); due != "" {
+		dueOnOrBefore = &due
+	}
+
+	bills, err := app.service.BillPaymentRun.GetPayableBills(r.Context(), buildingID, dueOnOrBefore)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, bills); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getBillPaymentRunHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "runID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	run, err := app.service.BillPaymentRun.GetByID(r.Context(), id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "pdf" {
+		filename := fmt.Sprintf("checks-run-%d.pdf", id)
+		if err := app.pdfResponse(w, filename, renderChecksPDF(run.Checks)); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, run); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) createBillPaymentRunHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.CreateBillPaymentRunRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.BuildingID = buildingID
+
+	run, err := app.service.BillPaymentRun.Create(r.Context(), req)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusCreated, run); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -21,6 +21,18 @@ type updateBuildingRequest struct {
 	Name string `json:"name" validate:"required"`
 }
 
+type updateBuildingPaymentSettingsRequest struct {
+	AllowOverpayment           bool   `json:"allow_overpayment"`
+	CustomerDepositsAccountID  *int64 `json:"customer_deposits_account_id"`
+	VendorPrepaymentsAccountID *int64 `json:"vendor_prepayments_account_id"`
+	UndepositedFundsAccountID  *int64 `json:"undeposited_funds_account_id"`
+}
+
+type updateBuildingCurrencySettingsRequest struct {
+	BaseCurrency        string `json:"base_currency" validate:"required,len=3"`
+	FXGainLossAccountID *int64 `json:"fx_gain_loss_account_id"`
+}
+
 func getUserIDFromJWT(r *http.Request, jwtSecret string) (int64, error) {
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
@@ -192,6 +204,95 @@ func (app *application) updateBuildingHandler(w http.ResponseWriter, r *http.Req
 	}
 }
 
+func (app *application) updateBuildingPaymentSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req updateBuildingPaymentSettingsRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	building := &store.Building{
+		ID:                         id,
+		AllowOverpayment:           req.AllowOverpayment,
+		CustomerDepositsAccountID:  req.CustomerDepositsAccountID,
+		VendorPrepaymentsAccountID: req.VendorPrepaymentsAccountID,
+		UndepositedFundsAccountID:  req.UndepositedFundsAccountID,
+	}
+
+	if err := app.service.Building.UpdatePaymentSettings(r.Context(), building); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundError(w, r, err)
+		default:
+			app.badRequestError(w, r, err)
+		}
+		return
+	}
+
+	updatedBuilding, err := app.service.Building.GetByID(r.Context(), id)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, updatedBuilding); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) updateBuildingCurrencySettingsHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req updateBuildingCurrencySettingsRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	building := &store.Building{
+		ID:                  id,
+		BaseCurrency:        req.BaseCurrency,
+		FXGainLossAccountID: req.FXGainLossAccountID,
+	}
+
+	if err := app.service.Building.UpdateCurrencySettings(r.Context(), building); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundError(w, r, err)
+		default:
+			app.badRequestError(w, r, err)
+		}
+		return
+	}
+
+	updatedBuilding, err := app.service.Building.GetByID(r.Context(), id)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, updatedBuilding); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
 func (app *application) deleteBuildingHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "buildingID")
 	id, err := strconv.ParseInt(idStr, 10, 64)
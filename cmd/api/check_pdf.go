@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/pdf"
+)
+
+// renderChecksPDF prints voucher checks, one per page: the check on the top third and the same
+// remittance stub twice below it, one for the vendor and one for the file
+func renderChecksPDF(checks []dto.PrintableCheckDto) []byte {
+	doc := pdf.NewDocument()
+
+	if len(checks) == 0 {
+		doc.AddPage()
+		doc.Text(50, 60, 12, false, "No checks in this payment run.")
+		return doc.Bytes()
+	}
+
+	const (
+		left      = 40.0
+		right     = pdf.PageWidth - 40
+		third     = pdf.PageHeight / 3
+		rowHeight = 13.0
+		maxRows   = 12 // remittance lines that fit on a stub
+	)
+
+	stub := func(top float64, check dto.PrintableCheckDto) {
+		doc.Text(left, top+30, 10, true, check.PayeeName)
+		doc.TextRight(right, top+30, 10, false, fmt.Sprintf("Check %d   %s", check.CheckNo, check.Date))
+
+		y := top + 55
+		doc.Text(left, y, 8, true, "Bill No")
+		doc.Text(left+90, y, 8, true, "Bill Date")
+		doc.Text(left+160, y, 8, true, "Due Date")
+		doc.Text(left+230, y, 8, true, "Description")
+		doc.TextRight(right-80, y, 8, true, "Bill Amount")
+		doc.TextRight(right, y, 8, true, "Paid")
+		doc.Line(left, y+4, right, y+4)
+		y += rowHeight
+
+		for i, line := range check.Remittance {
+			if i == maxRows-1 && len(check.Remittance) > maxRows {
+				doc.Text(left, y, 8, false, fmt.Sprintf("... and %d more bills, see the payment register", len(check.Remittance)-i))
+				y += rowHeight
+				break
+			}
+			doc.Text(left, y, 8, false, truncateText(line.BillNo, 16))
+			doc.Text(left+90, y, 8, false, line.BillDate)
+			doc.Text(left+160, y, 8, false, line.DueDate)
+			doc.Text(left+230, y, 8, false, truncateText(line.Description, 30))
+			doc.TextRight(right-80, y, 8, false, line.BillAmount)
+			doc.TextRight(right, y, 8, false, line.AmountPaid)
+			y += rowHeight
+		}
+
+		doc.Line(left, y-9, right, y-9)
+		if check.Withholding != "" {
+			// the bills are paid in full, the vendor gets them less the tax kept back
+			doc.Text(left+230, y, 8, false, "Tax withheld")
+			doc.TextRight(right, y, 8, false, "-"+check.Withholding)
+			y += rowHeight
+		}
+		doc.Text(left+230, y, 8, true, "Check total")
+		doc.TextRight(right, y, 8, true, check.Amount)
+	}
+
+	for _, check := range checks {
+		doc.AddPage()
+
+		// check face
+		doc.Text(left, 40, 10, true, check.BankAccountName)
+		doc.TextRight(right, 40, 12, true, fmt.Sprintf("%d", check.CheckNo))
+		doc.TextRight(right, 70, 10, false, fmt.Sprintf("Date  %s", check.Date))
+
+		doc.Text(left, 110, 8, false, "PAY TO THE")
+		doc.Text(left, 120, 8, false, "ORDER OF")
+		doc.Text(left+60, 120, 11, true, check.PayeeName)
+		doc.Line(left+55, 124, right-110, 124)
+		doc.TextRight(right, 120, 11, true, fmt.Sprintf("**%s**", check.Amount))
+
+		doc.Text(left, 150, 10, false, check.AmountInWords+" Dollars")
+		doc.Line(left, 154, right, 154)
+
+		doc.Text(left, 215, 8, false, "MEMO")
+		doc.Text(left+35, 215, 9, false, truncateText(check.Memo, 50))
+		doc.Line(left+30, 219, left+260, 219)
+		doc.Line(right-200, 219, right, 219)
+		doc.Text(right-200, 229, 7, false, "AUTHORIZED SIGNATURE")
+
+		stub(third, check)
+		stub(third*2, check)
+	}
+
+	return doc.Bytes()
+}
+
+func truncateText(s string, n int) string {
+	if len(s) > n {
+		return s[:n-3] + "..."
+	}
+	return s
+}
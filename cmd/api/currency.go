@@ -0,0 +1,156 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+// Handlers
+
+func (app *application) getExchangeRatesHandler(w http.ResponseWriter, r *http.Request) {
+	buildingID, err := strconv.ParseInt(chi.URLParam(r, "buildingID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var currency *string
+	if currencyStr := r.URL.Query().Get("currency"); currencyStr != "" {
+		currency = &currencyStr
+	}
+
+	rates, err := app.service.Currency.GetRates(r.Context(), buildingID, currency)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, rates); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// saveExchangeRateHandler records the day's rate of a currency; posting the same day again replaces it
+func (app *application) saveExchangeRateHandler(w http.ResponseWriter, r *http.Request) {
+	buildingID, err := strconv.ParseInt(chi.URLParam(r, "buildingID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.ExchangeRatePayload
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.BuildingID = buildingID
+
+	rate, err := app.service.Currency.SaveRate(r.Context(), req)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusCreated, rate); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) deleteExchangeRateHandler(w http.ResponseWriter, r *http.Request) {
+	rateID, err := strconv.ParseInt(chi.URLParam(r, "rateID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := app.service.Currency.DeleteRate(r.Context(), rateID); err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) getFXRevaluationsHandler(w http.ResponseWriter, r *http.Request) {
+	buildingID, err := strconv.ParseInt(chi.URLParam(r, "buildingID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	revaluations, err := app.service.Currency.GetRevaluations(r.Context(), buildingID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, revaluations); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getFXRevaluationHandler(w http.ResponseWriter, r *http.Request) {
+	revaluationID, err := strconv.ParseInt(chi.URLParam(r, "revaluationID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	revaluation, err := app.service.Currency.GetRevaluation(r.Context(), revaluationID)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, revaluation); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// createFXRevaluationHandler runs the period-end revaluation of open foreign currency balances
+func (app *application) createFXRevaluationHandler(w http.ResponseWriter, r *http.Request) {
+	buildingID, err := strconv.ParseInt(chi.URLParam(r, "buildingID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.CreateFXRevaluationRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.BuildingID = buildingID
+
+	revaluation, err := app.service.Currency.Revalue(r.Context(), req)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusCreated, revaluation); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+// Handlers
+
+func (app *application) getDepositsHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var startDate *string
+	var endDate *string
+
+	q := r.URL.Query()
+	if start := q.Get("start_date"); start != "" {
+		startDate = &start
+	}
+	if end := q.Get("end_date"); end != "" {
+		endDate = &end
+	}
+
+	deposits, err := app.service.Deposit.GetAll(r.Context(), buildingID, startDate, endDate)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, deposits); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getDepositHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "depositID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	deposit, err := app.service.Deposit.GetByID(r.Context(), id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, deposit); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getUndepositedFundsHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	undeposited, err := app.service.Deposit.GetUndeposited(r.Context(), buildingID)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, undeposited); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) createDepositHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.CreateDepositRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.BuildingID = buildingID
+
+	deposit, err := app.service.Deposit.Create(r.Context(), req)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusCreated, deposit); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
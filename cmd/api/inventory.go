@@ -0,0 +1,162 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+// Handlers
+
+// getItemMovementsHandler lists the stock received and issued for an inventory item
+func (app *application) getItemMovementsHandler(w http.ResponseWriter, r *http.Request) {
+	itemID, err := strconv.ParseInt(chi.URLParam(r, "itemID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+
+	var startDate, endDate *string
+	if start := q.Get("start_date"); start != "" {
+		startDate = &start
+	}
+	if end := q.Get("end_date"); end != "" {
+		endDate = &end
+	}
+
+	movements, err := app.service.Inventory.GetMovements(r.Context(), itemID, startDate, endDate)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, movements); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getInventoryAdjustmentsHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+
+	var startDate, endDate *string
+	if start := q.Get("start_date"); start != "" {
+		startDate = &start
+	}
+	if end := q.Get("end_date"); end != "" {
+		endDate = &end
+	}
+
+	adjustments, err := app.service.InventoryAdjustment.GetAll(r.Context(), buildingID, startDate, endDate)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, adjustments); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getInventoryAdjustmentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "adjustmentID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	adjustment, err := app.service.InventoryAdjustment.GetByID(r.Context(), id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, adjustment); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) createInventoryAdjustmentHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.CreateInventoryAdjustmentRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.BuildingID = buildingID
+
+	adjustment, err := app.service.InventoryAdjustment.Create(r.Context(), req)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusCreated, adjustment); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) updateInventoryAdjustmentHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "adjustmentID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.UpdateInventoryAdjustmentRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.ID = id
+	req.BuildingID = buildingID
+
+	adjustment, err := app.service.InventoryAdjustment.Update(r.Context(), req)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, adjustment); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -19,8 +19,8 @@ type createItemRequest struct {
 	COGSAccount    *int64  `json:"cogs_account"`
 	ExpenseAccount *int64  `json:"expense_account"`
 
-	OnHand     float64 `json:"on_hand" validate:"required"`
-	AvgCost    float64 `json:"avg_cost" validate:"required"`
+	OnHand     float64 `json:"on_hand" validate:"gte=0"`  // opening stock; afterwards moved by bills, invoices and sales receipts
+	AvgCost    float64 `json:"avg_cost" validate:"gte=0"`
 	Date       string  `json:"date" validate:"required"`
 	BuildingID int64   `json:"building_id" validate:"required"`
 }
@@ -84,6 +84,10 @@ func (app *application) createItemHandler(w http.ResponseWriter, r *http.Request
 		Name:        req.Name,
 		Type:        req.Type,
 		Description: req.Description,
+		AssetAccount:   req.AssetAccount,
+		IncomeAccount:  req.IncomeAccount,
+		COGSAccount:    req.COGSAccount,
+		ExpenseAccount: req.ExpenseAccount,
 		OnHand:      req.OnHand,
 		AvgCost:     req.AvgCost,
 		Date:        date.String(), // TODO : check this time string,
@@ -93,7 +97,7 @@ func (app *application) createItemHandler(w http.ResponseWriter, r *http.Request
 	
 
 	if err := app.service.Item.Create(r.Context(), item); err != nil {
-		app.internalServerError(w, r, err)
+		app.badRequestError(w, r, err)
 		return
 	}
 
@@ -121,6 +125,10 @@ func (app *application) updateItemHandler(w http.ResponseWriter, r *http.Request
 		Name:        req.Name,
 		Type:        req.Type,
 		Description: req.Description,
+		AssetAccount:   req.AssetAccount,
+		IncomeAccount:  req.IncomeAccount,
+		COGSAccount:    req.COGSAccount,
+		ExpenseAccount: req.ExpenseAccount,
 		OnHand:      req.OnHand,
 		AvgCost:     req.AvgCost,
 		Date:        date.String(), // TODO : check this time string,
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+// Handlers
+
+func (app *application) getLateFeePoliciesHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	policies, err := app.service.LateFee.GetPolicies(r.Context(), buildingID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, policies); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getLateFeePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	buildingID, err := strconv.ParseInt(chi.URLParam(r, "buildingID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	idStr := chi.URLParam(r, "policyID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	policy, err := app.service.LateFee.GetPolicy(r.Context(), buildingID, id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, policy); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) createLateFeePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.CreateLateFeePolicyRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.BuildingID = buildingID
+
+	policy, err := app.service.LateFee.CreatePolicy(r.Context(), req)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusCreated, policy); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) updateLateFeePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	policyID, err := strconv.ParseInt(chi.URLParam(r, "policyID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.UpdateLateFeePolicyRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.ID = policyID
+	req.BuildingID = buildingID
+
+	policy, err := app.service.LateFee.UpdatePolicy(r.Context(), req)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, policy); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) deleteLateFeePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	buildingID, err := strconv.ParseInt(chi.URLParam(r, "buildingID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	policyID, err := strconv.ParseInt(chi.URLParam(r, "policyID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := app.service.LateFee.DeletePolicy(r.Context(), buildingID, policyID); err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) getLateFeeAssessmentsHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var invoiceID *int64
+	if invoiceIDStr := r.URL.Query().Get("invoice_id"); invoiceIDStr != "" {
+		if id, err := strconv.ParseInt(invoiceIDStr, 10, 64); err == nil {
+			invoiceID = &id
+		}
+	}
+
+	assessments, err := app.service.LateFee.GetAssessments(r.Context(), buildingID, invoiceID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, assessments); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) previewLateFeesHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.LateFeeRunRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	preview, err := app.service.LateFee.Preview(r.Context(), buildingID, req)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, preview); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) runLateFeesHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.LateFeeRunRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	result, err := app.service.LateFee.Run(r.Context(), buildingID, req)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, result); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
 	"github.com/mysecodgit/go_accounting/internal/db"
 	"github.com/mysecodgit/go_accounting/internal/env"
 	"github.com/mysecodgit/go_accounting/internal/service"
@@ -37,6 +38,13 @@ func main() {
 	logger := zap.Must(zap.NewProduction()).Sugar()
 	defer logger.Sync()
 
+	// half_up or half_even (banker's) for every amount the money package rounds
+	rounding, err := money.ParseRoundingMode(env.GetString("MONEY_ROUNDING", "half_up"))
+	if err != nil {
+		logger.Fatal(err)
+	}
+	money.SetRounding(rounding)
+
 	// Database
 
 	db, err := db.New(
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/mysecodgit/go_accounting/internal/dto"
 	"github.com/mysecodgit/go_accounting/internal/store"
 )
 
@@ -191,3 +192,40 @@ func (app *application) getAvailableCreditsHandler(w http.ResponseWriter, r *htt
 		app.internalServerError(w, r, err)
 	}
 }
+
+// setPersonWithholdingHandler sets the withholding tax deducted from payments to a vendor
+func (app *application) setPersonWithholdingHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "peopleID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.VendorWithholdingRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.PeopleID = id
+
+	person, err := app.service.People.SetWithholding(r.Context(), req)
+	if err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundError(w, r, err)
+		default:
+			app.badRequestError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, person); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
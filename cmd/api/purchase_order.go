@@ -0,0 +1,276 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+// Handlers
+
+func (app *application) getPurchaseOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+
+	var peopleID *int64
+	if pidStr := q.Get("people_id"); pidStr != "" {
+		if pid, err := strconv.ParseInt(pidStr, 10, 64); err == nil {
+			peopleID = &pid
+		}
+	}
+
+	var status *string
+	if statusStr := q.Get("status"); statusStr != "" {
+		status = &statusStr
+	}
+
+	orders, err := app.service.PurchaseOrder.GetAll(r.Context(), buildingID, peopleID, status)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, orders); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getPurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	poID, err := strconv.ParseInt(chi.URLParam(r, "poID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	order, err := app.service.PurchaseOrder.GetByID(r.Context(), poID)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, order); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) createPurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.CreatePurchaseOrderRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.BuildingID = buildingID
+
+	order, err := app.service.PurchaseOrder.Create(r.Context(), req)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusCreated, order); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) updatePurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	poID, err := strconv.ParseInt(chi.URLParam(r, "poID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.UpdatePurchaseOrderRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.ID = poID
+	req.BuildingID = buildingID
+
+	order, err := app.service.PurchaseOrder.Update(r.Context(), req)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, order); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) deletePurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	poID, err := strconv.ParseInt(chi.URLParam(r, "poID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := app.service.PurchaseOrder.Delete(r.Context(), poID); err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) closePurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	poID, err := strconv.ParseInt(chi.URLParam(r, "poID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	order, err := app.service.PurchaseOrder.Close(r.Context(), poID, buildingID)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, order); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) reopenPurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	poID, err := strconv.ParseInt(chi.URLParam(r, "poID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	order, err := app.service.PurchaseOrder.Reopen(r.Context(), poID, buildingID)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, order); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// getPurchaseOrderBillDraftHandler returns a bill pre-filled from what is left on the purchase order
+func (app *application) getPurchaseOrderBillDraftHandler(w http.ResponseWriter, r *http.Request) {
+	poID, err := strconv.ParseInt(chi.URLParam(r, "poID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	bill, err := app.service.PurchaseOrder.GetBillDraft(r.Context(), poID)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, bill); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// matchPurchaseOrderBillHandler checks a bill against the purchase order before it is saved.
+// Pass ?bill_id= when checking an edit so the bill is not matched against itself.
+func (app *application) matchPurchaseOrderBillHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	poID, err := strconv.ParseInt(chi.URLParam(r, "poID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var billID int64
+	if billIDStr := r.URL.Query().Get("bill_id"); billIDStr != "" {
+		if billID, err = strconv.ParseInt(billIDStr, 10, 64); err != nil {
+			app.badRequestError(w, r, err)
+			return
+		}
+	}
+
+	var req dto.CreateBillRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.BuildingID = buildingID
+	req.PurchaseOrderID = &poID
+
+	match, err := app.service.PurchaseOrder.Match(r.Context(), req.BillPayloadDTO, billID)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, match); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
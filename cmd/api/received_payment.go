@@ -0,0 +1,117 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+// Handlers
+
+func (app *application) getReceivedPaymentsHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var peopleID *int
+	if peopleIdStr := r.URL.Query().Get("people_id"); peopleIdStr != "" {
+		if pid, err := strconv.Atoi(peopleIdStr); err == nil {
+			peopleID = &pid
+		}
+	}
+
+	payments, err := app.service.ReceivedPayment.GetAll(r.Context(), buildingID, peopleID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, payments); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getReceivedPaymentHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "receivedPaymentID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	payment, err := app.service.ReceivedPayment.GetByID(r.Context(), id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, payment); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) previewReceivedPaymentHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.ReceivePaymentPayload
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.BuildingID = buildingID
+
+	preview, err := app.service.ReceivedPayment.Preview(r.Context(), req)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, preview); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) createReceivedPaymentHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.CreateReceivePaymentRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.BuildingID = buildingID
+
+	payment, err := app.service.ReceivedPayment.Create(r.Context(), req)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusCreated, payment); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
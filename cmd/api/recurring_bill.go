@@ -0,0 +1,273 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/env"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+// Handlers
+
+func (app *application) getBillTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	templates, err := app.service.RecurringBill.GetTemplates(r.Context(), buildingID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, templates); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getBillTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "templateID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	template, err := app.service.RecurringBill.GetTemplate(r.Context(), id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, template); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) createBillTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.CreateBillTemplateRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.BuildingID = buildingID
+
+	template, err := app.service.RecurringBill.CreateTemplate(r.Context(), req)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusCreated, template); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) updateBillTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	templateID, err := strconv.ParseInt(chi.URLParam(r, "templateID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.UpdateBillTemplateRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	req.ID = templateID
+	req.BuildingID = buildingID
+
+	template, err := app.service.RecurringBill.UpdateTemplate(r.Context(), req)
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, template); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) deleteBillTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	templateID, err := strconv.ParseInt(chi.URLParam(r, "templateID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := app.service.RecurringBill.DeleteTemplate(r.Context(), templateID); err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) getBillTemplateRunsHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var templateID *int64
+	if templateIDStr := r.URL.Query().Get("template_id"); templateIDStr != "" {
+		if id, err := strconv.ParseInt(templateIDStr, 10, 64); err == nil {
+			templateID = &id
+		}
+	}
+
+	var status *string
+	if statusStr := r.URL.Query().Get("status"); statusStr != "" {
+		status = &statusStr
+	}
+
+	runs, err := app.service.RecurringBill.GetRuns(r.Context(), buildingID, templateID, status)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, runs); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) previewRecurringBillsHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.RecurringBillRunRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	preview, err := app.service.RecurringBill.Preview(r.Context(), buildingID, req)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, preview); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) runRecurringBillsHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var req dto.RecurringBillRunRequest
+	if err := readJSON(w, r, &req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(req); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	result, err := app.service.RecurringBill.Run(r.Context(), buildingID, req)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, result); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) approveBillTemplateRunHandler(w http.ResponseWriter, r *http.Request) {
+	app.reviewBillTemplateRun(w, r, true)
+}
+
+func (app *application) rejectBillTemplateRunHandler(w http.ResponseWriter, r *http.Request) {
+	app.reviewBillTemplateRun(w, r, false)
+}
+
+func (app *application) reviewBillTemplateRun(w http.ResponseWriter, r *http.Request, approve bool) {
+	jwtSecret := env.GetString("JWT_SECRET", "dev_secret_change_me")
+	userID, err := getUserIDFromJWT(r, jwtSecret)
+	if err != nil {
+		app.unauthorizedErrorResponse(w, r, err)
+		return
+	}
+
+	idStr := chi.URLParam(r, "buildingID")
+	buildingID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	runID, err := strconv.ParseInt(chi.URLParam(r, "runID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var run *dto.BillTemplateRunDto
+	if approve {
+		run, err = app.service.RecurringBill.ApproveRun(r.Context(), buildingID, runID, userID)
+	} else {
+		run, err = app.service.RecurringBill.RejectRun(r.Context(), buildingID, runID, userID)
+	}
+	if err != nil {
+		if err == store.ErrNotFound {
+			app.notFoundError(w, r, err)
+			return
+		}
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, run); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
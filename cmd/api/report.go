@@ -4,9 +4,13 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/service"
 )
 
 func (app *application) getBalanceSheetHandler(w http.ResponseWriter, r *http.Request) {
@@ -30,7 +34,37 @@ func (app *application) getBalanceSheetHandler(w http.ResponseWriter, r *http.Re
 		asOfDate = &today
 	}
 
-	balanceSheet, err := app.service.Report.GetBalanceSheet(r.Context(), int(id), *asOfDate)
+	basis, err := service.ParseReportBasis(q.Get("basis"))
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if columns := q.Get("columns"); columns != "" {
+		mode, err := service.ParseColumnMode(columns)
+		if err != nil {
+			app.badRequestError(w, r, err)
+			return
+		}
+
+		comparative, err := report.GetComparativeBalanceSheet(r.Context(), int(id), q.Get("start_date"), *asOfDate, mode, basis)
+		if err != nil {
+			app.badRequestError(w, r, err)
+			return
+		}
+		if err := app.jsonResponse(w, http.StatusOK, comparative); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	balanceSheet, err := report.GetBalanceSheet(r.Context(), int(id), *asOfDate, basis)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -58,7 +92,32 @@ func (app *application) getTrialBalanceHandler(w http.ResponseWriter, r *http.Re
 		today := time.Now().Format("2006-01-02")
 		asOfDate = &today
 	}
-	trialBalance, err := app.service.Report.GetTrialBalance(r.Context(), int(id), *asOfDate)
+
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if columns := q.Get("columns"); columns != "" {
+		mode, err := service.ParseColumnMode(columns)
+		if err != nil {
+			app.badRequestError(w, r, err)
+			return
+		}
+
+		comparative, err := report.GetComparativeTrialBalance(r.Context(), int(id), q.Get("start_date"), *asOfDate, mode)
+		if err != nil {
+			app.badRequestError(w, r, err)
+			return
+		}
+		if err := app.jsonResponse(w, http.StatusOK, comparative); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	trialBalance, err := report.GetTrialBalance(r.Context(), int(id), *asOfDate)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -85,7 +144,14 @@ func (app *application) getCustomerBalanceSummaryHandler(w http.ResponseWriter,
 		today := time.Now().Format("2006-01-02")
 		asOfDate = &today
 	}
-	customerBalanceSummary, err := app.service.Report.GetCustomerBalanceSummary(r.Context(), int(id), *asOfDate)
+
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	customerBalanceSummary, err := report.GetCustomerBalanceSummary(r.Context(), int(id), *asOfDate)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -120,7 +186,13 @@ func (app *application) getCustomerBalanceDetailHandler(w http.ResponseWriter, r
 		}
 	}
 
-	customerBalanceDetail, err := app.service.Report.GetCustomerBalanceDetail(r.Context(), int(id), *asOfDate, peopleID)
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	customerBalanceDetail, err := report.GetCustomerBalanceDetail(r.Context(), int(id), *asOfDate, peopleID)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -147,7 +219,14 @@ func (app *application) getVendorBalanceSummaryHandler(w http.ResponseWriter, r
 		today := time.Now().Format("2006-01-02")
 		asOfDate = &today
 	}
-	vendorBalanceSummary, err := app.service.Report.GetVendorBalanceSummary(r.Context(), int(id), *asOfDate)
+
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	vendorBalanceSummary, err := report.GetVendorBalanceSummary(r.Context(), int(id), *asOfDate)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -182,7 +261,13 @@ func (app *application) getVendorBalanceDetailHandler(w http.ResponseWriter, r *
 		}
 	}
 
-	vendorBalanceDetail, err := app.service.Report.GetVendorBalanceDetail(r.Context(), int(id), *asOfDate, peopleID)
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	vendorBalanceDetail, err := report.GetVendorBalanceDetail(r.Context(), int(id), *asOfDate, peopleID)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -231,7 +316,13 @@ func (app *application) getTransactionDetailsHandler(w http.ResponseWriter, r *h
 		}
 	}
 
-	transactionDetails, err := app.service.Report.GetTransactionDetails(r.Context(), int(id), *startDate, *endDate, accountIDs, unitID)
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	transactionDetails, err := report.GetTransactionDetails(r.Context(), int(id), *startDate, *endDate, accountIDs, unitID)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -259,7 +350,45 @@ func (app *application) getProfitAndLossStandardHandler(w http.ResponseWriter, r
 		endDate = &endDateStr
 	}
 
-	profitAndLossStandard, err := app.service.Report.GetProfitAndLossStandard(r.Context(), int(id), *startDate, *endDate)
+	basis, err := service.ParseReportBasis(q.Get("basis"))
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if columns := q.Get("columns"); columns != "" {
+		mode, err := service.ParseColumnMode(columns)
+		if err != nil {
+			app.badRequestError(w, r, err)
+			return
+		}
+		if endDate == nil {
+			app.badRequestError(w, r, fmt.Errorf("end_date is required"))
+			return
+		}
+
+		var start string
+		if startDate != nil {
+			start = *startDate
+		}
+		comparative, err := report.GetComparativeProfitAndLoss(r.Context(), int(id), start, *endDate, mode, basis)
+		if err != nil {
+			app.badRequestError(w, r, err)
+			return
+		}
+		if err := app.jsonResponse(w, http.StatusOK, comparative); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	profitAndLossStandard, err := report.GetProfitAndLossStandard(r.Context(), int(id), *startDate, *endDate, basis)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -269,6 +398,40 @@ func (app *application) getProfitAndLossStandardHandler(w http.ResponseWriter, r
 	}
 }
 
+// getCashFlowStatementHandler returns the statement of cash flows, for the current month unless
+// start_date and end_date are given
+func (app *application) getCashFlowStatementHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	startDate, endDate := statementPeriod(q.Get("start_date"), q.Get("end_date"))
+	if err := checkPeriod(startDate, endDate); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	cashFlow, err := report.GetCashFlowStatement(r.Context(), int(id), startDate, endDate)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, cashFlow); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
 func (app *application) getProfitAndLossByUnitHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "buildingID")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -292,7 +455,13 @@ func (app *application) getProfitAndLossByUnitHandler(w http.ResponseWriter, r *
 		return
 	}
 
-	profitAndLossByUnit, err := app.service.Report.GetProfitAndLossByUnit(r.Context(), int(id), *startDate, *endDate)
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	profitAndLossByUnit, err := report.GetProfitAndLossByUnit(r.Context(), int(id), *startDate, *endDate)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -301,3 +470,475 @@ func (app *application) getProfitAndLossByUnitHandler(w http.ResponseWriter, r *
 		app.internalServerError(w, r, err)
 	}
 }
+
+func (app *application) getCustomerStatementHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	peopleID, err := strconv.Atoi(q.Get("people_id"))
+	if err != nil {
+		app.badRequestError(w, r, fmt.Errorf("people_id is required"))
+		return
+	}
+
+	startDate, endDate := statementPeriod(q.Get("start_date"), q.Get("end_date"))
+	if err := checkPeriod(startDate, endDate); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	statement, err := report.GetCustomerStatement(r.Context(), int(id), peopleID, startDate, endDate)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if q.Get("format") == "pdf" {
+		filename := fmt.Sprintf("statement-%d-%s.pdf", peopleID, endDate)
+		if err := app.pdfResponse(w, filename, renderCustomerStatementsPDF([]dto.CustomerStatement{*statement})); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, statement); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getCustomerStatementsHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	startDate, endDate := statementPeriod(q.Get("start_date"), q.Get("end_date"))
+	if err := checkPeriod(startDate, endDate); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	statements, err := report.GetCustomerStatements(r.Context(), int(id), startDate, endDate)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if q.Get("format") == "pdf" {
+		filename := fmt.Sprintf("statements-%d-%s.pdf", id, endDate)
+		if err := app.pdfResponse(w, filename, renderCustomerStatementsPDF(statements.Statements)); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, statements); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// statementPeriod defaults to the current month up to today
+func statementPeriod(startDate, endDate string) (string, string) {
+	now := time.Now()
+	if endDate == "" {
+		endDate = now.Format("2006-01-02")
+	}
+	if startDate == "" {
+		startDate = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).Format("2006-01-02")
+	}
+	return startDate, endDate
+}
+
+// checkPeriod rejects dates that are not YYYY-MM-DD and a start after the end
+func checkPeriod(startDate, endDate string) error {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return fmt.Errorf("invalid start_date: %s", startDate)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return fmt.Errorf("invalid end_date: %s", endDate)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("start_date must not be after end_date")
+	}
+	return nil
+}
+
+// asOfDateOrToday checks an as_of_date, defaulting to today
+func asOfDateOrToday(asOfDate string) (string, error) {
+	if asOfDate == "" {
+		return time.Now().Format("2006-01-02"), nil
+	}
+	if _, err := time.Parse("2006-01-02", asOfDate); err != nil {
+		return "", fmt.Errorf("invalid as_of_date: %s", asOfDate)
+	}
+	return asOfDate, nil
+}
+
+func (app *application) getARAgingHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	asOfDate, err := asOfDateOrToday(q.Get("as_of_date"))
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	boundaries, err := parseAgingBoundaries(q.Get("buckets"))
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	aging, err := report.GetARAging(r.Context(), int(id), asOfDate, boundaries)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, aging); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// parseAgingBoundaries reads bucket upper bounds such as "30,60,90"
+func parseAgingBoundaries(value string) ([]int, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	boundaries := []int{}
+	for _, part := range strings.Split(value, ",") {
+		days, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid buckets value: %s", part)
+		}
+		boundaries = append(boundaries, days)
+	}
+	return boundaries, nil
+}
+
+func (app *application) getAPAgingHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	asOfDate, err := asOfDateOrToday(q.Get("as_of_date"))
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	boundaries, err := parseAgingBoundaries(q.Get("buckets"))
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	aging, err := report.GetAPAging(r.Context(), int(id), asOfDate, boundaries)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, aging); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getBillsDueHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	asOfDate, err := asOfDateOrToday(q.Get("as_of_date"))
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	days := 30
+	if daysStr := q.Get("days"); daysStr != "" {
+		days, err = strconv.Atoi(daysStr)
+		if err != nil {
+			app.badRequestError(w, r, fmt.Errorf("invalid days value: %s", daysStr))
+			return
+		}
+	}
+
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	billsDue, err := report.GetBillsDue(r.Context(), int(id), asOfDate, days)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, billsDue); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) getInventoryValuationHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	asOfDate, err := asOfDateOrToday(r.URL.Query().Get("as_of_date"))
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	valuation, err := report.GetInventoryValuation(r.Context(), int(id), asOfDate)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, valuation); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// getInventoryMovementsHandler reports stock movements per item; pass ?item_id= for a single item
+func (app *application) getInventoryMovementsHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	startDate, endDate := statementPeriod(q.Get("start_date"), q.Get("end_date"))
+	if err := checkPeriod(startDate, endDate); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var itemID *int
+	if itemIDStr := q.Get("item_id"); itemIDStr != "" {
+		iid, err := strconv.Atoi(itemIDStr)
+		if err != nil {
+			app.badRequestError(w, r, fmt.Errorf("invalid item_id value: %s", itemIDStr))
+			return
+		}
+		itemID = &iid
+	}
+
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	history, err := report.GetInventoryMovementHistory(r.Context(), int(id), startDate, endDate, itemID)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, history); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// getTaxSummaryHandler reports output and input tax for a filing period
+func (app *application) getTaxSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	startDate, endDate := statementPeriod(q.Get("start_date"), q.Get("end_date"))
+	if err := checkPeriod(startDate, endDate); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	summary, err := report.GetTaxSummary(r.Context(), int(id), startDate, endDate)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, summary); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// getWithholdingRegisterHandler lists the tax withheld from vendor payments in a period
+func (app *application) getWithholdingRegisterHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	startDate, endDate := statementPeriod(q.Get("start_date"), q.Get("end_date"))
+	if err := checkPeriod(startDate, endDate); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	var peopleID *int
+	if peopleIDStr := q.Get("people_id"); peopleIDStr != "" {
+		parsed, err := strconv.Atoi(peopleIDStr)
+		if err != nil {
+			app.badRequestError(w, r, err)
+			return
+		}
+		peopleID = &parsed
+	}
+
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	register, err := report.GetWithholdingRegister(r.Context(), int(id), startDate, endDate, peopleID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.jsonResponse(w, http.StatusOK, register); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// getWithholdingCertificateHandler returns a vendor's withholding certificate, as a PDF with format=pdf
+func (app *application) getWithholdingCertificateHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "buildingID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	peopleID, err := strconv.Atoi(q.Get("people_id"))
+	if err != nil {
+		app.badRequestError(w, r, fmt.Errorf("people_id is required"))
+		return
+	}
+
+	startDate, endDate := statementPeriod(q.Get("start_date"), q.Get("end_date"))
+	if err := checkPeriod(startDate, endDate); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	report, err := app.reportService(r, id)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	certificate, err := report.GetWithholdingCertificate(r.Context(), int(id), peopleID, startDate, endDate)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	if q.Get("format") == "pdf" {
+		filename := fmt.Sprintf("withholding-certificate-%d-%s.pdf", peopleID, endDate)
+		if err := app.pdfResponse(w, filename, renderWithholdingCertificatePDF(*certificate)); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, certificate); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// reportService returns the report service printing amounts as the request asks. By default
+// amounts are plain numbers; format=display, and PDFs, show them in the building's currency the
+// way locale= writes them (en-US unless given), e.g. "$1,234.50" or "1.234,50 €".
+func (app *application) reportService(r *http.Request, buildingID int64) (*service.ReportService, error) {
+	q := r.URL.Query()
+	switch q.Get("format") {
+	case "display", "pdf":
+	default:
+		return app.service.Report, nil
+	}
+
+	building, err := app.service.Building.GetByID(r.Context(), buildingID)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := money.NewFormatter(building.BaseCurrency, q.Get("locale"))
+	if err != nil {
+		return nil, err
+	}
+	return app.service.Report.WithFormat(format), nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/pdf"
+)
+
+func (app *application) pdfResponse(w http.ResponseWriter, filename string, data []byte) error {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(http.StatusOK)
+	_, err := w.Write(data)
+	return err
+}
+
+// renderCustomerStatementsPDF renders each statement starting on its own page
+func renderCustomerStatementsPDF(statements []dto.CustomerStatement) []byte {
+	doc := pdf.NewDocument()
+
+	if len(statements) == 0 {
+		doc.AddPage()
+		doc.Text(50, 60, 12, false, "No statements with an outstanding balance.")
+		return doc.Bytes()
+	}
+
+	const (
+		left       = 50.0
+		right      = pdf.PageWidth - 50
+		rowHeight  = 16.0
+		pageBottom = pdf.PageHeight - 60
+	)
+
+	// column positions: date, type, reference, description, charge (right), credit (right), balance (right)
+	header := func(y float64) float64 {
+		doc.Text(left, y, 9, true, "Date")
+		doc.Text(left+65, y, 9, true, "Type")
+		doc.Text(left+140, y, 9, true, "Reference")
+		doc.Text(left+220, y, 9, true, "Description")
+		doc.TextRight(right-150, y, 9, true, "Charges")
+		doc.TextRight(right-75, y, 9, true, "Credits")
+		doc.TextRight(right, y, 9, true, "Balance")
+		doc.Line(left, y+4, right, y+4)
+		return y + rowHeight
+	}
+
+	truncate := func(s string, n int) string {
+		if len(s) > n {
+			return s[:n-3] + "..."
+		}
+		return s
+	}
+
+	for _, statement := range statements {
+		doc.AddPage()
+
+		doc.Text(left, 60, 18, true, "Statement of Account")
+		doc.Text(left, 85, 11, true, statement.PeopleName)
+		doc.TextRight(right, 60, 10, false, fmt.Sprintf("Period: %s to %s", statement.StartDate, statement.EndDate))
+		doc.TextRight(right, 75, 10, false, fmt.Sprintf("Amount due: %s", statement.ClosingBalance))
+
+		y := header(120)
+
+		doc.Text(left, y, 9, false, statement.StartDate)
+		doc.Text(left+220, y, 9, false, "Opening balance")
+		doc.TextRight(right, y, 9, false, statement.OpeningBalance)
+		y += rowHeight
+
+		for _, line := range statement.Lines {
+			if y > pageBottom-120 {
+				doc.AddPage()
+				y = header(60)
+			}
+
+			doc.Text(left, y, 9, false, line.Date)
+			doc.Text(left+65, y, 9, false, line.Type)
+			doc.Text(left+140, y, 9, false, truncate(line.Reference, 14))
+			doc.Text(left+220, y, 9, false, truncate(line.Description, 26))
+			doc.TextRight(right-150, y, 9, false, line.Charge)
+			doc.TextRight(right-75, y, 9, false, line.Credit)
+			doc.TextRight(right, y, 9, false, line.Balance)
+			y += rowHeight
+		}
+
+		doc.Line(left, y-10, right, y-10)
+		doc.Text(left+220, y, 9, true, "Closing balance")
+		doc.TextRight(right-150, y, 9, true, statement.TotalCharges)
+		doc.TextRight(right-75, y, 9, true, statement.TotalCredits)
+		doc.TextRight(right, y, 9, true, statement.ClosingBalance)
+		y += rowHeight * 2
+
+		// aging summary
+		labels := []string{"Current", "1-30 days", "31-60 days", "61-90 days", "Over 90 days", "Total due"}
+		values := []string{
+			statement.Aging.Current,
+			statement.Aging.Days1To30,
+			statement.Aging.Days31To60,
+			statement.Aging.Days61To90,
+			statement.Aging.Over90,
+			statement.Aging.Total,
+		}
+		colWidth := (right - left) / float64(len(labels))
+		for i := range labels {
+			x := left + colWidth*float64(i+1) - 5
+			doc.TextRight(x, y, 9, true, labels[i])
+			doc.TextRight(x, y+rowHeight, 9, false, values[i])
+		}
+		doc.Line(left, y+4, right, y+4)
+	}
+
+	return doc.Bytes()
+}
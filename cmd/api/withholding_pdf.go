@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/pdf"
+)
+
+// renderWithholdingCertificatePDF renders the certificate of tax withheld from a vendor's payments
+func renderWithholdingCertificatePDF(certificate dto.WithholdingCertificate) []byte {
+	doc := pdf.NewDocument()
+
+	const (
+		left       = 50.0
+		right      = pdf.PageWidth - 50
+		rowHeight  = 16.0
+		pageBottom = pdf.PageHeight - 60
+	)
+
+	// column positions: date, reference, bill, rate (right), gross (right), withheld (right), net (right)
+	header := func(y float64) float64 {
+		doc.Text(left, y, 9, true, "Date")
+		doc.Text(left+65, y, 9, true, "Reference")
+		doc.Text(left+160, y, 9, true, "Bill")
+		doc.TextRight(right-225, y, 9, true, "Rate %")
+		doc.TextRight(right-150, y, 9, true, "Gross")
+		doc.TextRight(right-75, y, 9, true, "Withheld")
+		doc.TextRight(right, y, 9, true, "Net paid")
+		doc.Line(left, y+4, right, y+4)
+		return y + rowHeight
+	}
+
+	truncate := func(s string, n int) string {
+		if len(s) > n {
+			return s[:n-3] + "..."
+		}
+		return s
+	}
+
+	doc.AddPage()
+
+	doc.Text(left, 60, 18, true, "Certificate of Tax Withheld")
+	doc.TextRight(right, 60, 10, false, fmt.Sprintf("Period: %s to %s", certificate.StartDate, certificate.EndDate))
+	doc.Text(left, 90, 10, false, "Withholding agent:")
+	doc.Text(left+110, 90, 10, true, certificate.BuildingName)
+	doc.Text(left, 105, 10, false, "Payee:")
+	doc.Text(left+110, 105, 10, true, certificate.PeopleName)
+
+	y := header(140)
+
+	if len(certificate.Lines) == 0 {
+		doc.Text(left, y, 9, false, "No tax was withheld in this period.")
+		y += rowHeight
+	}
+
+	for _, line := range certificate.Lines {
+		if y > pageBottom-80 {
+			doc.AddPage()
+			y = header(60)
+		}
+
+		doc.Text(left, y, 9, false, line.Date)
+		doc.Text(left+65, y, 9, false, truncate(line.Reference, 16))
+		doc.Text(left+160, y, 9, false, truncate(line.BillNo, 16))
+		doc.TextRight(right-225, y, 9, false, line.Rate)
+		doc.TextRight(right-150, y, 9, false, line.Gross)
+		doc.TextRight(right-75, y, 9, false, line.Withheld)
+		doc.TextRight(right, y, 9, false, line.Net)
+		y += rowHeight
+	}
+
+	doc.Line(left, y-10, right, y-10)
+	doc.Text(left+160, y, 9, true, "Total")
+	doc.TextRight(right-150, y, 9, true, certificate.Gross)
+	doc.TextRight(right-75, y, 9, true, certificate.Withheld)
+	doc.TextRight(right, y, 9, true, certificate.Net)
+	y += rowHeight * 3
+
+	doc.Text(left, y, 9, false, fmt.Sprintf("We certify that %s was withheld from payments to %s in the period above",
+		certificate.Withheld, certificate.PeopleName))
+	doc.Text(left, y+rowHeight, 9, false, "and is held for remittance to the tax authority.")
+
+	doc.Line(left, y+rowHeight*5, left+200, y+rowHeight*5)
+	doc.Text(left, y+rowHeight*6, 9, false, "Authorised signature")
+
+	return doc.Bytes()
+}
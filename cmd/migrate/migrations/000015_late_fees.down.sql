@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS late_fee_assessments;
+DROP TABLE IF EXISTS late_fee_policies;
@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS late_fee_policies (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  building_id int(11) NOT NULL,
+  name varchar(255) NOT NULL,
+  fee_type enum('flat','percentage') NOT NULL DEFAULT 'flat',
+  flat_amount_cents bigint(20) NOT NULL DEFAULT 0,
+  percentage_scaled bigint(20) NOT NULL DEFAULT 0,
+  grace_days int(11) NOT NULL DEFAULT 0,
+  max_applications int(11) NOT NULL DEFAULT 1,
+  item_id int(11) NOT NULL,
+  status enum('0','1') NOT NULL DEFAULT '1',
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  updated_at timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (id),
+  KEY lfp_building_id (building_id),
+  KEY lfp_item_id (item_id),
+  CONSTRAINT fk_lfp_building FOREIGN KEY (building_id) REFERENCES buildings (id),
+  CONSTRAINT fk_lfp_item FOREIGN KEY (item_id) REFERENCES items (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+-- one assessment per invoice and period keeps the run idempotent
+CREATE TABLE IF NOT EXISTS late_fee_assessments (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  policy_id int(11) NOT NULL,
+  invoice_id int(11) NOT NULL,
+  fee_invoice_id int(11) NOT NULL,
+  period varchar(7) NOT NULL,
+  application_no int(11) NOT NULL,
+  amount_cents bigint(20) NOT NULL,
+  assessed_date date NOT NULL,
+  building_id int(11) NOT NULL,
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (id),
+  UNIQUE KEY lfa_invoice_period (invoice_id, period),
+  KEY lfa_policy_id (policy_id),
+  KEY lfa_fee_invoice_id (fee_invoice_id),
+  KEY lfa_building_id (building_id),
+  CONSTRAINT fk_lfa_policy FOREIGN KEY (policy_id) REFERENCES late_fee_policies (id),
+  CONSTRAINT fk_lfa_invoice FOREIGN KEY (invoice_id) REFERENCES invoices (id),
+  CONSTRAINT fk_lfa_fee_invoice FOREIGN KEY (fee_invoice_id) REFERENCES invoices (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
@@ -0,0 +1,30 @@
+-- a received payment is one deposit transaction allocated across several invoices;
+-- its invoice_payments rows share the same transaction_id
+CREATE TABLE IF NOT EXISTS received_payments (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  transaction_id int(11) NOT NULL,
+  reference varchar(255) NOT NULL,
+  date date NOT NULL,
+  people_id int(11) NOT NULL,
+  account_id int(11) NOT NULL,
+  amount_cents bigint(20) NOT NULL,
+  unapplied_cents bigint(20) NOT NULL DEFAULT 0,
+  credit_memo_id int(11) DEFAULT NULL,
+  memo text NOT NULL,
+  user_id int(11) NOT NULL,
+  building_id int(11) NOT NULL,
+  status enum('0','1') NOT NULL DEFAULT '1',
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  updated_at timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (id),
+  KEY rp_transaction_id (transaction_id),
+  KEY rp_people_id (people_id),
+  KEY rp_account_id (account_id),
+  KEY rp_credit_memo_id (credit_memo_id),
+  KEY rp_building_id (building_id),
+  CONSTRAINT fk_rp_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id),
+  CONSTRAINT fk_rp_people FOREIGN KEY (people_id) REFERENCES people (id),
+  CONSTRAINT fk_rp_account FOREIGN KEY (account_id) REFERENCES accounts (id),
+  CONSTRAINT fk_rp_credit_memo FOREIGN KEY (credit_memo_id) REFERENCES credit_memo (id),
+  CONSTRAINT fk_rp_building FOREIGN KEY (building_id) REFERENCES buildings (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
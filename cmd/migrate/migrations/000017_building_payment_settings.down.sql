@@ -0,0 +1,6 @@
+ALTER TABLE buildings
+  DROP FOREIGN KEY fk_buildings_customer_deposits,
+  DROP FOREIGN KEY fk_buildings_vendor_prepayments,
+  DROP COLUMN vendor_prepayments_account_id,
+  DROP COLUMN customer_deposits_account_id,
+  DROP COLUMN allow_overpayment;
@@ -0,0 +1,6 @@
+ALTER TABLE buildings
+  ADD COLUMN allow_overpayment tinyint(1) NOT NULL DEFAULT 0 AFTER name,
+  ADD COLUMN customer_deposits_account_id int(11) DEFAULT NULL AFTER allow_overpayment,
+  ADD COLUMN vendor_prepayments_account_id int(11) DEFAULT NULL AFTER customer_deposits_account_id,
+  ADD CONSTRAINT fk_buildings_customer_deposits FOREIGN KEY (customer_deposits_account_id) REFERENCES accounts (id),
+  ADD CONSTRAINT fk_buildings_vendor_prepayments FOREIGN KEY (vendor_prepayments_account_id) REFERENCES accounts (id);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS deposit_lines;
+DROP TABLE IF EXISTS deposits;
+
+ALTER TABLE buildings
+  DROP FOREIGN KEY fk_buildings_undeposited_funds,
+  DROP COLUMN undeposited_funds_account_id;
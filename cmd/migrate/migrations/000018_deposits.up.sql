@@ -0,0 +1,43 @@
+ALTER TABLE buildings
+  ADD COLUMN undeposited_funds_account_id int(11) DEFAULT NULL AFTER vendor_prepayments_account_id,
+  ADD CONSTRAINT fk_buildings_undeposited_funds FOREIGN KEY (undeposited_funds_account_id) REFERENCES accounts (id);
+
+CREATE TABLE IF NOT EXISTS deposits (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  transaction_id int(11) NOT NULL,
+  reference varchar(255) NOT NULL,
+  date date NOT NULL,
+  bank_account_id int(11) NOT NULL,
+  clearing_account_id int(11) NOT NULL,
+  amount_cents bigint(20) NOT NULL,
+  memo text NOT NULL,
+  user_id int(11) NOT NULL,
+  building_id int(11) NOT NULL,
+  status enum('0','1') NOT NULL DEFAULT '1',
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  updated_at timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (id),
+  KEY dep_transaction_id (transaction_id),
+  KEY dep_bank_account_id (bank_account_id),
+  KEY dep_building_id (building_id),
+  CONSTRAINT fk_dep_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id),
+  CONSTRAINT fk_dep_bank_account FOREIGN KEY (bank_account_id) REFERENCES accounts (id),
+  CONSTRAINT fk_dep_clearing_account FOREIGN KEY (clearing_account_id) REFERENCES accounts (id),
+  CONSTRAINT fk_dep_building FOREIGN KEY (building_id) REFERENCES buildings (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+-- each undeposited split (a debit to the clearing account) can be banked once
+CREATE TABLE IF NOT EXISTS deposit_lines (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  deposit_id int(11) NOT NULL,
+  split_id int(11) NOT NULL,
+  source_transaction_id int(11) NOT NULL,
+  amount_cents bigint(20) NOT NULL,
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (id),
+  UNIQUE KEY dl_split_id (split_id),
+  KEY dl_deposit_id (deposit_id),
+  KEY dl_source_transaction_id (source_transaction_id),
+  CONSTRAINT fk_dl_deposit FOREIGN KEY (deposit_id) REFERENCES deposits (id) ON DELETE CASCADE,
+  CONSTRAINT fk_dl_split FOREIGN KEY (split_id) REFERENCES splits (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
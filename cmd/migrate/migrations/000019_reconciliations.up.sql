@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS reconciliations (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  account_id int(11) NOT NULL,
+  building_id int(11) NOT NULL,
+  statement_date date NOT NULL,
+  beginning_balance_cents bigint(20) NOT NULL DEFAULT 0,
+  ending_balance_cents bigint(20) NOT NULL,
+  status enum('open','finished') NOT NULL DEFAULT 'open',
+  finished_at timestamp NULL DEFAULT NULL,
+  user_id int(11) NOT NULL,
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  updated_at timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (id),
+  KEY rec_account_id (account_id),
+  KEY rec_building_id (building_id),
+  CONSTRAINT fk_rec_account FOREIGN KEY (account_id) REFERENCES accounts (id),
+  CONSTRAINT fk_rec_building FOREIGN KEY (building_id) REFERENCES buildings (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+-- splits ticked as cleared; once the reconciliation is finished they are locked
+CREATE TABLE IF NOT EXISTS reconciliation_items (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  reconciliation_id int(11) NOT NULL,
+  split_id int(11) NOT NULL,
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (id),
+  UNIQUE KEY ri_reconciliation_split (reconciliation_id, split_id),
+  KEY ri_split_id (split_id),
+  CONSTRAINT fk_ri_reconciliation FOREIGN KEY (reconciliation_id) REFERENCES reconciliations (id) ON DELETE CASCADE,
+  CONSTRAINT fk_ri_split FOREIGN KEY (split_id) REFERENCES splits (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
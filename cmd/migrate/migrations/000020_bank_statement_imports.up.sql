@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS bank_statement_imports (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  account_id int(11) NOT NULL,
+  building_id int(11) NOT NULL,
+  format varchar(20) NOT NULL,
+  file_name varchar(255) NOT NULL,
+  line_count int(11) NOT NULL DEFAULT 0,
+  duplicate_count int(11) NOT NULL DEFAULT 0,
+  user_id int(11) NOT NULL,
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (id),
+  KEY bsi_account_id (account_id),
+  KEY bsi_building_id (building_id),
+  CONSTRAINT fk_bsi_account FOREIGN KEY (account_id) REFERENCES accounts (id),
+  CONSTRAINT fk_bsi_building FOREIGN KEY (building_id) REFERENCES buildings (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+-- staged statement lines; dedupe_key is the bank's FITID or a content hash, unique per bank account
+CREATE TABLE IF NOT EXISTS bank_statement_lines (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  import_id int(11) NOT NULL,
+  account_id int(11) NOT NULL,
+  building_id int(11) NOT NULL,
+  date date NOT NULL,
+  amount_cents bigint(20) NOT NULL,
+  description varchar(500) NOT NULL DEFAULT '',
+  reference varchar(255) NOT NULL DEFAULT '',
+  fitid varchar(255) DEFAULT NULL,
+  dedupe_key varchar(255) NOT NULL,
+  status enum('new','matched','created','ignored') NOT NULL DEFAULT 'new',
+  split_id int(11) DEFAULT NULL,
+  transaction_id int(11) DEFAULT NULL,
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  updated_at timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (id),
+  UNIQUE KEY bsl_account_dedupe_key (account_id, dedupe_key),
+  UNIQUE KEY bsl_split_id (split_id),
+  KEY bsl_import_id (import_id),
+  KEY bsl_building_status (building_id, status),
+  CONSTRAINT fk_bsl_import FOREIGN KEY (import_id) REFERENCES bank_statement_imports (id) ON DELETE CASCADE,
+  CONSTRAINT fk_bsl_account FOREIGN KEY (account_id) REFERENCES accounts (id),
+  CONSTRAINT fk_bsl_split FOREIGN KEY (split_id) REFERENCES splits (id),
+  CONSTRAINT fk_bsl_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
@@ -0,0 +1,5 @@
+ALTER TABLE bank_statement_lines
+  DROP FOREIGN KEY fk_bsl_rule,
+  DROP COLUMN rule_id;
+
+DROP TABLE IF EXISTS bank_rules;
@@ -0,0 +1,32 @@
+-- rules categorize imported bank lines; the lowest priority that matches wins
+CREATE TABLE IF NOT EXISTS bank_rules (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  building_id int(11) NOT NULL,
+  name varchar(255) NOT NULL,
+  priority int(11) NOT NULL DEFAULT 100,
+  bank_account_id int(11) DEFAULT NULL,
+  direction enum('any','in','out') NOT NULL DEFAULT 'any',
+  description_contains varchar(255) DEFAULT NULL,
+  description_regex varchar(255) DEFAULT NULL,
+  min_amount_cents bigint(20) DEFAULT NULL,
+  max_amount_cents bigint(20) DEFAULT NULL,
+  target_account_id int(11) DEFAULT NULL,
+  item_id int(11) DEFAULT NULL,
+  unit_id int(11) DEFAULT NULL,
+  people_id int(11) DEFAULT NULL,
+  status enum('0','1') NOT NULL DEFAULT '1',
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  updated_at timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (id),
+  KEY br_building_priority (building_id, priority),
+  CONSTRAINT fk_br_building FOREIGN KEY (building_id) REFERENCES buildings (id),
+  CONSTRAINT fk_br_bank_account FOREIGN KEY (bank_account_id) REFERENCES accounts (id),
+  CONSTRAINT fk_br_target_account FOREIGN KEY (target_account_id) REFERENCES accounts (id),
+  CONSTRAINT fk_br_item FOREIGN KEY (item_id) REFERENCES items (id),
+  CONSTRAINT fk_br_unit FOREIGN KEY (unit_id) REFERENCES units (id),
+  CONSTRAINT fk_br_people FOREIGN KEY (people_id) REFERENCES people (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+ALTER TABLE bank_statement_lines
+  ADD COLUMN rule_id int(11) DEFAULT NULL AFTER transaction_id,
+  ADD CONSTRAINT fk_bsl_rule FOREIGN KEY (rule_id) REFERENCES bank_rules (id) ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS bill_applied_credits;
+DROP TABLE IF EXISTS vendor_credits;
@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS vendor_credits (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  transaction_id int(11) NOT NULL,
+  reference varchar(255) NOT NULL,
+  date date NOT NULL,
+  people_id int(11) NOT NULL,
+  ap_account_id int(11) NOT NULL,
+  expense_account_id int(11) NOT NULL,
+  unit_id int(11) DEFAULT NULL,
+  amount_cents bigint(20) NOT NULL,
+  memo text NOT NULL,
+  user_id int(11) NOT NULL,
+  building_id int(11) NOT NULL,
+  status enum('0','1') NOT NULL DEFAULT '1',
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  updated_at timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (id),
+  KEY vc_transaction_id (transaction_id),
+  KEY vc_people_id (people_id),
+  KEY vc_building_id (building_id),
+  CONSTRAINT fk_vc_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id),
+  CONSTRAINT fk_vc_people FOREIGN KEY (people_id) REFERENCES people (id),
+  CONSTRAINT fk_vc_ap_account FOREIGN KEY (ap_account_id) REFERENCES accounts (id),
+  CONSTRAINT fk_vc_expense_account FOREIGN KEY (expense_account_id) REFERENCES accounts (id),
+  CONSTRAINT fk_vc_unit FOREIGN KEY (unit_id) REFERENCES units (id),
+  CONSTRAINT fk_vc_building FOREIGN KEY (building_id) REFERENCES buildings (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+-- applying a vendor credit only moves open balance between two A/P documents, so it posts no splits
+CREATE TABLE IF NOT EXISTS bill_applied_credits (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  bill_id int(11) NOT NULL,
+  vendor_credit_id int(11) NOT NULL,
+  amount_cents bigint(20) NOT NULL,
+  description text NOT NULL,
+  date date NOT NULL,
+  status enum('0','1') NOT NULL DEFAULT '1',
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  updated_at timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (id),
+  KEY bac_bill_id (bill_id),
+  KEY bac_vendor_credit_id (vendor_credit_id),
+  CONSTRAINT fk_bac_bill FOREIGN KEY (bill_id) REFERENCES bills (id),
+  CONSTRAINT fk_bac_vendor_credit FOREIGN KEY (vendor_credit_id) REFERENCES vendor_credits (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
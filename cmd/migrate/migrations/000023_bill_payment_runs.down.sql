@@ -0,0 +1,7 @@
+ALTER TABLE bill_payments
+  DROP FOREIGN KEY fk_bill_payments_run,
+  DROP KEY bp_account_check_no,
+  DROP COLUMN check_no,
+  DROP COLUMN run_id;
+
+DROP TABLE IF EXISTS bill_payment_runs;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS bill_payment_runs (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  building_id int(11) NOT NULL,
+  bank_account_id int(11) NOT NULL,
+  date date NOT NULL,
+  due_on_or_before date DEFAULT NULL,
+  memo text NOT NULL,
+  payment_count int(11) NOT NULL,
+  amount_cents bigint(20) NOT NULL,
+  user_id int(11) NOT NULL,
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  updated_at timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (id),
+  KEY bpr_building_id (building_id),
+  KEY bpr_bank_account_id (bank_account_id),
+  CONSTRAINT fk_bpr_building FOREIGN KEY (building_id) REFERENCES buildings (id),
+  CONSTRAINT fk_bpr_bank_account FOREIGN KEY (bank_account_id) REFERENCES accounts (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+-- a run pays each vendor with one check; every bill it covers gets a bill_payments row with that check number
+ALTER TABLE bill_payments
+  ADD COLUMN run_id int(11) DEFAULT NULL AFTER account_id,
+  ADD COLUMN check_no int(11) DEFAULT NULL AFTER run_id,
+  ADD KEY bp_account_check_no (account_id, check_no),
+  ADD CONSTRAINT fk_bill_payments_run FOREIGN KEY (run_id) REFERENCES bill_payment_runs (id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS bill_template_runs;
+DROP TABLE IF EXISTS bill_template_lines;
+DROP TABLE IF EXISTS bill_templates;
@@ -0,0 +1,62 @@
+CREATE TABLE IF NOT EXISTS bill_templates (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  building_id int(11) NOT NULL,
+  name varchar(255) NOT NULL,
+  people_id int(11) NOT NULL,
+  ap_account_id int(11) NOT NULL,
+  unit_id int(11) DEFAULT NULL,
+  description text DEFAULT NULL,
+  frequency enum('monthly','quarterly','yearly') NOT NULL DEFAULT 'monthly',
+  interval_count int(11) NOT NULL DEFAULT 1,
+  day_of_month int(11) NOT NULL DEFAULT 1,
+  due_days int(11) NOT NULL DEFAULT 0,
+  start_date date NOT NULL,
+  end_date date DEFAULT NULL,
+  as_draft enum('0','1') NOT NULL DEFAULT '0',
+  status enum('0','1') NOT NULL DEFAULT '1',
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  updated_at timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (id),
+  KEY bt_building_id (building_id),
+  KEY bt_people_id (people_id),
+  CONSTRAINT fk_bt_building FOREIGN KEY (building_id) REFERENCES buildings (id),
+  CONSTRAINT fk_bt_people FOREIGN KEY (people_id) REFERENCES people (id),
+  CONSTRAINT fk_bt_ap_account FOREIGN KEY (ap_account_id) REFERENCES accounts (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+CREATE TABLE IF NOT EXISTS bill_template_lines (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  template_id int(11) NOT NULL,
+  account_id int(11) NOT NULL,
+  unit_id int(11) DEFAULT NULL,
+  people_id int(11) DEFAULT NULL,
+  description text DEFAULT NULL,
+  amount_cents bigint(20) NOT NULL,
+  PRIMARY KEY (id),
+  KEY btl_template_id (template_id),
+  CONSTRAINT fk_btl_template FOREIGN KEY (template_id) REFERENCES bill_templates (id) ON DELETE CASCADE,
+  CONSTRAINT fk_btl_account FOREIGN KEY (account_id) REFERENCES accounts (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+-- one run per template and period keeps the scheduler idempotent
+CREATE TABLE IF NOT EXISTS bill_template_runs (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  template_id int(11) NOT NULL,
+  period varchar(7) NOT NULL,
+  bill_no varchar(255) NOT NULL,
+  bill_date date NOT NULL,
+  due_date date NOT NULL,
+  amount_cents bigint(20) NOT NULL,
+  bill_id int(11) DEFAULT NULL,
+  status enum('draft','posted','rejected') NOT NULL,
+  reviewed_by int(11) DEFAULT NULL,
+  reviewed_at timestamp NULL DEFAULT NULL,
+  building_id int(11) NOT NULL,
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (id),
+  UNIQUE KEY btr_template_period (template_id, period),
+  KEY btr_bill_id (bill_id),
+  KEY btr_building_id (building_id),
+  CONSTRAINT fk_btr_template FOREIGN KEY (template_id) REFERENCES bill_templates (id),
+  CONSTRAINT fk_btr_bill FOREIGN KEY (bill_id) REFERENCES bills (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
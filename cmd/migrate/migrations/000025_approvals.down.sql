@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS document_approvals;
+DROP TABLE IF EXISTS approval_thresholds;
+
+ALTER TABLE checks DROP COLUMN approval_status;
+ALTER TABLE bills DROP COLUMN approval_status;
@@ -0,0 +1,42 @@
+-- existing bills and checks are already on the books
+ALTER TABLE bills
+  ADD COLUMN approval_status enum('draft','pending','approved','rejected') NOT NULL DEFAULT 'approved' AFTER status;
+
+ALTER TABLE checks
+  ADD COLUMN approval_status enum('draft','pending','approved','rejected') NOT NULL DEFAULT 'approved' AFTER total_amount;
+
+-- a document needs one approval per active threshold at or below its amount, lowest first
+CREATE TABLE IF NOT EXISTS approval_thresholds (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  building_id int(11) NOT NULL,
+  document_type enum('bill','check') NOT NULL,
+  min_amount_cents bigint(20) NOT NULL,
+  role_id bigint(20) UNSIGNED NOT NULL,
+  status enum('0','1') NOT NULL DEFAULT '1',
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  updated_at timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (id),
+  KEY at_building_type (building_id, document_type),
+  CONSTRAINT fk_at_building FOREIGN KEY (building_id) REFERENCES buildings (id),
+  CONSTRAINT fk_at_role FOREIGN KEY (role_id) REFERENCES roles (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+-- editing a document voids its earlier decisions (status '0') so it is approved again
+CREATE TABLE IF NOT EXISTS document_approvals (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  document_type enum('bill','check') NOT NULL,
+  document_id int(11) NOT NULL,
+  threshold_id int(11) DEFAULT NULL,
+  action enum('approved','rejected') NOT NULL,
+  user_id int(11) NOT NULL,
+  note text DEFAULT NULL,
+  status enum('0','1') NOT NULL DEFAULT '1',
+  building_id int(11) NOT NULL,
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (id),
+  KEY da_document (document_type, document_id),
+  KEY da_threshold_id (threshold_id),
+  KEY da_building_id (building_id),
+  CONSTRAINT fk_da_threshold FOREIGN KEY (threshold_id) REFERENCES approval_thresholds (id),
+  CONSTRAINT fk_da_user FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
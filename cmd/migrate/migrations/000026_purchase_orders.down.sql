@@ -0,0 +1,8 @@
+ALTER TABLE bill_expense_lines
+  DROP FOREIGN KEY fk_bel_po_line,
+  DROP KEY bel_po_line_id,
+  DROP COLUMN qty_scaled,
+  DROP COLUMN po_line_id;
+
+DROP TABLE IF EXISTS purchase_order_lines;
+DROP TABLE IF EXISTS purchase_orders;
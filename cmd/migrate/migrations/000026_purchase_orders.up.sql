@@ -0,0 +1,48 @@
+CREATE TABLE IF NOT EXISTS purchase_orders (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  po_no varchar(255) NOT NULL,
+  po_date date NOT NULL,
+  expected_date date DEFAULT NULL,
+  people_id int(11) NOT NULL,
+  unit_id int(11) DEFAULT NULL,
+  description text DEFAULT NULL,
+  amount_cents bigint(20) NOT NULL,
+  -- percent over the ordered qty, rate or amount a bill may reach before it is flagged, scaled by 1e5
+  tolerance_scaled bigint(20) NOT NULL DEFAULT 0,
+  status enum('open','partially_billed','closed') NOT NULL DEFAULT 'open',
+  -- closed by hand rather than by billing every line
+  manually_closed enum('0','1') NOT NULL DEFAULT '0',
+  user_id int(11) NOT NULL,
+  building_id int(11) NOT NULL,
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  updated_at timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (id),
+  UNIQUE KEY po_building_no (building_id, po_no),
+  KEY po_people_id (people_id),
+  CONSTRAINT fk_po_building FOREIGN KEY (building_id) REFERENCES buildings (id),
+  CONSTRAINT fk_po_people FOREIGN KEY (people_id) REFERENCES people (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+CREATE TABLE IF NOT EXISTS purchase_order_lines (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  purchase_order_id int(11) NOT NULL,
+  item_id int(11) DEFAULT NULL,
+  account_id int(11) NOT NULL,
+  unit_id int(11) DEFAULT NULL,
+  description text DEFAULT NULL,
+  qty_scaled bigint(20) NOT NULL,
+  rate_scaled bigint(20) NOT NULL,
+  amount_cents bigint(20) NOT NULL,
+  PRIMARY KEY (id),
+  KEY pol_purchase_order_id (purchase_order_id),
+  CONSTRAINT fk_pol_purchase_order FOREIGN KEY (purchase_order_id) REFERENCES purchase_orders (id) ON DELETE CASCADE,
+  CONSTRAINT fk_pol_item FOREIGN KEY (item_id) REFERENCES items (id),
+  CONSTRAINT fk_pol_account FOREIGN KEY (account_id) REFERENCES accounts (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+-- a bill line billed against a purchase order line, with the qty it bills
+ALTER TABLE bill_expense_lines
+  ADD COLUMN po_line_id int(11) DEFAULT NULL,
+  ADD COLUMN qty_scaled bigint(20) DEFAULT NULL,
+  ADD KEY bel_po_line_id (po_line_id),
+  ADD CONSTRAINT fk_bel_po_line FOREIGN KEY (po_line_id) REFERENCES purchase_order_lines (id);
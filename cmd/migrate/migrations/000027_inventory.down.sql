@@ -0,0 +1,10 @@
+ALTER TABLE bill_expense_lines
+  DROP FOREIGN KEY fk_bel_item,
+  DROP KEY bel_item_id,
+  DROP COLUMN item_id;
+
+DROP TABLE IF EXISTS inventory_movements;
+
+ALTER TABLE items
+  DROP COLUMN value_cents,
+  DROP COLUMN on_hand_scaled;
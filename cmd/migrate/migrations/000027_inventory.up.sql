@@ -0,0 +1,35 @@
+-- on_hand and avg_cost stay as the rounded figures shown on items; stock is tracked in the scaled columns
+ALTER TABLE items
+  ADD COLUMN on_hand_scaled bigint(20) NOT NULL DEFAULT 0 AFTER avg_cost,
+  ADD COLUMN value_cents bigint(20) NOT NULL DEFAULT 0 AFTER on_hand_scaled;
+
+UPDATE items
+SET on_hand_scaled = ROUND(on_hand * 100000),
+    value_cents = ROUND(on_hand * avg_cost * 100)
+WHERE type = 'inventory';
+
+-- every change to an inventory item's stock, in posting order; qty and cost are negative for issues
+CREATE TABLE IF NOT EXISTS inventory_movements (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  item_id int(11) NOT NULL,
+  transaction_id int(11) NOT NULL,
+  source_type enum('bill','invoice','receipt') NOT NULL,
+  movement_date date NOT NULL,
+  qty_scaled bigint(20) NOT NULL,
+  cost_cents bigint(20) NOT NULL,
+  on_hand_after_scaled bigint(20) NOT NULL,
+  value_after_cents bigint(20) NOT NULL,
+  building_id int(11) NOT NULL,
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (id),
+  KEY im_item_id (item_id),
+  KEY im_transaction_id (transaction_id),
+  KEY im_building_date (building_id, movement_date),
+  CONSTRAINT fk_im_item FOREIGN KEY (item_id) REFERENCES items (id),
+  CONSTRAINT fk_im_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+ALTER TABLE bill_expense_lines
+  ADD COLUMN item_id int(11) DEFAULT NULL,
+  ADD KEY bel_item_id (item_id),
+  ADD CONSTRAINT fk_bel_item FOREIGN KEY (item_id) REFERENCES items (id);
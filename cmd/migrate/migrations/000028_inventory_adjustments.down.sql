@@ -0,0 +1,7 @@
+DELETE FROM inventory_movements WHERE source_type = 'adjustment';
+
+ALTER TABLE inventory_movements
+  MODIFY COLUMN source_type enum('bill','invoice','receipt') NOT NULL;
+
+DROP TABLE IF EXISTS inventory_adjustment_lines;
+DROP TABLE IF EXISTS inventory_adjustments;
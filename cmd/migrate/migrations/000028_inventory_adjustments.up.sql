@@ -0,0 +1,43 @@
+-- a stocktake correction; each line moves an item to a counted qty and/or value and the
+-- difference in value is posted between the item's asset account and account_id
+CREATE TABLE IF NOT EXISTS inventory_adjustments (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  transaction_id int(11) NOT NULL,
+  reference varchar(255) NOT NULL,
+  adjustment_date date NOT NULL,
+  account_id int(11) NOT NULL,
+  unit_id int(11) DEFAULT NULL,
+  memo text NOT NULL,
+  user_id int(11) NOT NULL,
+  building_id int(11) NOT NULL,
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  updated_at timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (id),
+  KEY ia_transaction_id (transaction_id),
+  KEY ia_building_date (building_id, adjustment_date),
+  CONSTRAINT fk_ia_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id),
+  CONSTRAINT fk_ia_account FOREIGN KEY (account_id) REFERENCES accounts (id),
+  CONSTRAINT fk_ia_unit FOREIGN KEY (unit_id) REFERENCES units (id),
+  CONSTRAINT fk_ia_building FOREIGN KEY (building_id) REFERENCES buildings (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+-- new_qty_scaled and new_value_cents are what was counted, null when left unchanged;
+-- the change columns are what posting them did to stock
+CREATE TABLE IF NOT EXISTS inventory_adjustment_lines (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  adjustment_id int(11) NOT NULL,
+  item_id int(11) NOT NULL,
+  new_qty_scaled bigint(20) DEFAULT NULL,
+  new_value_cents bigint(20) DEFAULT NULL,
+  qty_change_scaled bigint(20) NOT NULL DEFAULT 0,
+  value_change_cents bigint(20) NOT NULL DEFAULT 0,
+  memo text DEFAULT NULL,
+  PRIMARY KEY (id),
+  KEY ial_adjustment_id (adjustment_id),
+  KEY ial_item_id (item_id),
+  CONSTRAINT fk_ial_adjustment FOREIGN KEY (adjustment_id) REFERENCES inventory_adjustments (id) ON DELETE CASCADE,
+  CONSTRAINT fk_ial_item FOREIGN KEY (item_id) REFERENCES items (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+ALTER TABLE inventory_movements
+  MODIFY COLUMN source_type enum('bill','invoice','receipt','adjustment') NOT NULL;
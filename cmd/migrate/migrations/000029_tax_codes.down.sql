@@ -0,0 +1,22 @@
+ALTER TABLE bill_expense_lines
+  DROP FOREIGN KEY fk_bel_tax_code,
+  DROP KEY bel_tax_code_id,
+  DROP COLUMN tax_cents,
+  DROP COLUMN taxable_cents,
+  DROP COLUMN tax_code_id;
+
+ALTER TABLE receipt_items
+  DROP FOREIGN KEY fk_ri_tax_code,
+  DROP KEY ri_tax_code_id,
+  DROP COLUMN tax_cents,
+  DROP COLUMN taxable_cents,
+  DROP COLUMN tax_code_id;
+
+ALTER TABLE invoice_items
+  DROP FOREIGN KEY fk_ii_tax_code,
+  DROP KEY ii_tax_code_id,
+  DROP COLUMN tax_cents,
+  DROP COLUMN taxable_cents,
+  DROP COLUMN tax_code_id;
+
+DROP TABLE IF EXISTS tax_codes;
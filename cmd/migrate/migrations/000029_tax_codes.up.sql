@@ -0,0 +1,40 @@
+-- sales tax / VAT codes; rate is a percent with 5 decimals (money.RateScale)
+CREATE TABLE IF NOT EXISTS tax_codes (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  building_id int(11) NOT NULL,
+  code varchar(20) NOT NULL,
+  name varchar(255) NOT NULL,
+  rate_scaled bigint(20) NOT NULL DEFAULT 0,
+  mode enum('exclusive','inclusive') NOT NULL DEFAULT 'exclusive',
+  account_id int(11) NOT NULL,
+  status enum('0','1') NOT NULL DEFAULT '1',
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  updated_at timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (id),
+  UNIQUE KEY tc_building_code (building_id, code),
+  KEY tc_account_id (account_id),
+  CONSTRAINT fk_tc_building FOREIGN KEY (building_id) REFERENCES buildings (id),
+  CONSTRAINT fk_tc_account FOREIGN KEY (account_id) REFERENCES accounts (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+-- taxable_cents and tax_cents are what the line posted, so later rate changes do not rewrite history
+ALTER TABLE invoice_items
+  ADD COLUMN tax_code_id int(11) DEFAULT NULL,
+  ADD COLUMN taxable_cents bigint(20) NOT NULL DEFAULT 0,
+  ADD COLUMN tax_cents bigint(20) NOT NULL DEFAULT 0,
+  ADD KEY ii_tax_code_id (tax_code_id),
+  ADD CONSTRAINT fk_ii_tax_code FOREIGN KEY (tax_code_id) REFERENCES tax_codes (id);
+
+ALTER TABLE receipt_items
+  ADD COLUMN tax_code_id int(11) DEFAULT NULL,
+  ADD COLUMN taxable_cents bigint(20) NOT NULL DEFAULT 0,
+  ADD COLUMN tax_cents bigint(20) NOT NULL DEFAULT 0,
+  ADD KEY ri_tax_code_id (tax_code_id),
+  ADD CONSTRAINT fk_ri_tax_code FOREIGN KEY (tax_code_id) REFERENCES tax_codes (id);
+
+ALTER TABLE bill_expense_lines
+  ADD COLUMN tax_code_id int(11) DEFAULT NULL,
+  ADD COLUMN taxable_cents bigint(20) NOT NULL DEFAULT 0,
+  ADD COLUMN tax_cents bigint(20) NOT NULL DEFAULT 0,
+  ADD KEY bel_tax_code_id (tax_code_id),
+  ADD CONSTRAINT fk_bel_tax_code FOREIGN KEY (tax_code_id) REFERENCES tax_codes (id);
@@ -0,0 +1,12 @@
+ALTER TABLE bill_payments
+  DROP FOREIGN KEY fk_bill_payments_withholding_account,
+  DROP KEY bp_withholding_account_id,
+  DROP COLUMN withholding_account_id,
+  DROP COLUMN withholding_cents,
+  DROP COLUMN withholding_rate_scaled;
+
+ALTER TABLE people
+  DROP FOREIGN KEY fk_people_withholding_account,
+  DROP KEY p_withholding_account_id,
+  DROP COLUMN withholding_account_id,
+  DROP COLUMN withholding_rate_scaled;
@@ -0,0 +1,15 @@
+-- withholding tax deducted from vendor payments; rate is a percent with 5 decimals (money.RateScale)
+ALTER TABLE people
+  ADD COLUMN withholding_rate_scaled bigint(20) NOT NULL DEFAULT 0,
+  ADD COLUMN withholding_account_id int(11) DEFAULT NULL,
+  ADD KEY p_withholding_account_id (withholding_account_id),
+  ADD CONSTRAINT fk_people_withholding_account FOREIGN KEY (withholding_account_id) REFERENCES accounts (id);
+
+-- amount_cents stays the amount settled on the bill; the bank paid amount_cents - withholding_cents.
+-- the rate and account are kept so an edit recomputes with what the payment was made under
+ALTER TABLE bill_payments
+  ADD COLUMN withholding_rate_scaled bigint(20) NOT NULL DEFAULT 0,
+  ADD COLUMN withholding_cents bigint(20) NOT NULL DEFAULT 0,
+  ADD COLUMN withholding_account_id int(11) DEFAULT NULL,
+  ADD KEY bp_withholding_account_id (withholding_account_id),
+  ADD CONSTRAINT fk_bill_payments_withholding_account FOREIGN KEY (withholding_account_id) REFERENCES accounts (id);
@@ -0,0 +1,37 @@
+DROP TABLE IF EXISTS fx_revaluation_lines;
+DROP TABLE IF EXISTS fx_revaluations;
+
+ALTER TABLE splits
+  DROP COLUMN foreign_cents;
+
+ALTER TABLE bill_payments
+  DROP COLUMN fx_gain_loss_cents,
+  DROP COLUMN foreign_amount_cents,
+  DROP COLUMN exchange_rate_scaled,
+  DROP COLUMN currency;
+
+ALTER TABLE invoice_payments
+  DROP COLUMN fx_gain_loss_cents,
+  DROP COLUMN foreign_amount_cents,
+  DROP COLUMN exchange_rate_scaled,
+  DROP COLUMN currency;
+
+ALTER TABLE bills
+  DROP COLUMN foreign_amount_cents,
+  DROP COLUMN exchange_rate_scaled,
+  DROP COLUMN currency;
+
+ALTER TABLE invoices
+  DROP COLUMN foreign_amount_cents,
+  DROP COLUMN exchange_rate_scaled,
+  DROP COLUMN currency;
+
+DROP TABLE IF EXISTS exchange_rates;
+
+ALTER TABLE accounts
+  DROP COLUMN currency;
+
+ALTER TABLE buildings
+  DROP FOREIGN KEY fk_buildings_fx_gain_loss_account,
+  DROP COLUMN fx_gain_loss_account_id,
+  DROP COLUMN base_currency;
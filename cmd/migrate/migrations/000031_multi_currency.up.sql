@@ -0,0 +1,88 @@
+-- every amount already posted is in the building's base currency
+ALTER TABLE buildings
+  ADD COLUMN base_currency char(3) NOT NULL DEFAULT 'USD',
+  ADD COLUMN fx_gain_loss_account_id int(11) DEFAULT NULL,
+  ADD CONSTRAINT fk_buildings_fx_gain_loss_account FOREIGN KEY (fx_gain_loss_account_id) REFERENCES accounts (id);
+
+-- NULL is the building's base currency
+ALTER TABLE accounts
+  ADD COLUMN currency char(3) DEFAULT NULL;
+
+-- rate_scaled is base currency per one unit of the currency, 8 decimals (money.FXRateScale)
+CREATE TABLE IF NOT EXISTS exchange_rates (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  building_id int(11) NOT NULL,
+  currency char(3) NOT NULL,
+  rate_date date NOT NULL,
+  rate_scaled bigint(20) NOT NULL,
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  updated_at timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (id),
+  UNIQUE KEY er_building_currency_date (building_id, currency, rate_date),
+  CONSTRAINT fk_er_building FOREIGN KEY (building_id) REFERENCES buildings (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+-- amount_cents stays in base currency so balances, aging and the ledger keep working;
+-- foreign_amount_cents is the amount in the document's currency, NULL for base currency documents
+ALTER TABLE invoices
+  ADD COLUMN currency char(3) DEFAULT NULL,
+  ADD COLUMN exchange_rate_scaled bigint(20) DEFAULT NULL,
+  ADD COLUMN foreign_amount_cents bigint(20) DEFAULT NULL;
+
+ALTER TABLE bills
+  ADD COLUMN currency char(3) DEFAULT NULL,
+  ADD COLUMN exchange_rate_scaled bigint(20) DEFAULT NULL,
+  ADD COLUMN foreign_amount_cents bigint(20) DEFAULT NULL;
+
+-- amount_cents is the base currency the payment took off the document;
+-- fx_gain_loss_cents is what it realized against that, a gain when positive
+ALTER TABLE invoice_payments
+  ADD COLUMN currency char(3) DEFAULT NULL,
+  ADD COLUMN exchange_rate_scaled bigint(20) DEFAULT NULL,
+  ADD COLUMN foreign_amount_cents bigint(20) DEFAULT NULL,
+  ADD COLUMN fx_gain_loss_cents bigint(20) NOT NULL DEFAULT 0;
+
+ALTER TABLE bill_payments
+  ADD COLUMN currency char(3) DEFAULT NULL,
+  ADD COLUMN exchange_rate_scaled bigint(20) DEFAULT NULL,
+  ADD COLUMN foreign_amount_cents bigint(20) DEFAULT NULL,
+  ADD COLUMN fx_gain_loss_cents bigint(20) NOT NULL DEFAULT 0;
+
+-- foreign_cents is the split in its account's currency, debit positive; set on every split
+-- posted to a foreign currency account by an invoice, bill, payment or revaluation
+ALTER TABLE splits
+  ADD COLUMN foreign_cents bigint(20) DEFAULT NULL;
+
+-- a revaluation is posted at period end and reversed the next day, so payments keep realizing
+-- against the document rate
+CREATE TABLE IF NOT EXISTS fx_revaluations (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  building_id int(11) NOT NULL,
+  revaluation_date date NOT NULL,
+  transaction_id int(11) NOT NULL,
+  reversal_transaction_id int(11) NOT NULL,
+  created_at timestamp NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (id),
+  UNIQUE KEY fxr_building_date (building_id, revaluation_date),
+  CONSTRAINT fk_fxr_building FOREIGN KEY (building_id) REFERENCES buildings (id),
+  CONSTRAINT fk_fxr_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id),
+  CONSTRAINT fk_fxr_reversal_transaction FOREIGN KEY (reversal_transaction_id) REFERENCES transactions (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
+
+CREATE TABLE IF NOT EXISTS fx_revaluation_lines (
+  id int(11) NOT NULL AUTO_INCREMENT,
+  revaluation_id int(11) NOT NULL,
+  source_type enum('invoice','bill','account') NOT NULL,
+  source_id int(11) NOT NULL,
+  account_id int(11) NOT NULL,
+  currency char(3) NOT NULL,
+  foreign_cents bigint(20) NOT NULL,
+  rate_scaled bigint(20) NOT NULL,
+  carrying_cents bigint(20) NOT NULL,
+  revalued_cents bigint(20) NOT NULL,
+  adjustment_cents bigint(20) NOT NULL,
+  PRIMARY KEY (id),
+  KEY fxrl_revaluation_id (revaluation_id),
+  CONSTRAINT fk_fxrl_revaluation FOREIGN KEY (revaluation_id) REFERENCES fx_revaluations (id) ON DELETE CASCADE,
+  CONSTRAINT fk_fxrl_account FOREIGN KEY (account_id) REFERENCES accounts (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci;
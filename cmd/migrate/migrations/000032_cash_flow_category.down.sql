@@ -0,0 +1,2 @@
+ALTER TABLE account_types
+  DROP COLUMN cash_flow_category;
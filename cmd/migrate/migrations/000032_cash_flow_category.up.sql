@@ -0,0 +1,21 @@
+-- where an account type's changes go on the statement of cash flows: cash (the cash and bank
+-- accounts the statement reconciles to), operating, investing, financing, or non_cash for
+-- balance sheet accounts moved by non-cash expenses such as accumulated depreciation.
+-- income and expense types flow through net income, so their category is not used.
+ALTER TABLE account_types
+  ADD COLUMN cash_flow_category varchar(20) NOT NULL DEFAULT 'operating';
+
+-- best guesses for existing types from their names; review them after migrating
+UPDATE account_types SET cash_flow_category = 'financing'
+  WHERE LOWER(type) = 'equity'
+     OR (LOWER(type) = 'liability' AND (LOWER(typeName) LIKE '%long term%' OR LOWER(typeName) LIKE '%loan%'));
+
+UPDATE account_types SET cash_flow_category = 'investing'
+  WHERE LOWER(type) = 'asset' AND (LOWER(typeName) LIKE '%fixed%' OR LOWER(typeName) LIKE '%investment%');
+
+UPDATE account_types SET cash_flow_category = 'non_cash'
+  WHERE LOWER(type) = 'asset' AND LOWER(typeName) LIKE '%accumulated%';
+
+UPDATE account_types SET cash_flow_category = 'cash'
+  WHERE LOWER(type) = 'asset' AND (LOWER(typeName) LIKE '%bank%' OR LOWER(typeName) LIKE '%cash%'
+     OR LOWER(sub_type) IN ('bank', 'cash'));
@@ -0,0 +1,22 @@
+-- puts every bill payment back to debit bank, credit A/P, the direction the previous code posts in
+CREATE TEMPORARY TABLE resigned_splits AS
+SELECT s.id, s.debit, s.credit, s.debit_cents, s.credit_cents, s.foreign_cents
+FROM splits s
+JOIN (
+  SELECT DISTINCT ap.transaction_id
+  FROM bill_payments bp
+  JOIN bills b ON b.id = bp.bill_id
+  JOIN splits ap ON ap.transaction_id = bp.transaction_id AND ap.account_id = b.ap_account_id
+  WHERE ap.debit_cents > 0
+) posted ON posted.transaction_id = s.transaction_id;
+
+UPDATE splits s
+JOIN resigned_splits r ON r.id = s.id
+SET
+  s.debit = r.credit,
+  s.credit = r.debit,
+  s.debit_cents = r.credit_cents,
+  s.credit_cents = r.debit_cents,
+  s.foreign_cents = -r.foreign_cents;
+
+DROP TEMPORARY TABLE resigned_splits;
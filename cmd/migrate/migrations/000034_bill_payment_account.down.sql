@@ -0,0 +1,3 @@
+ALTER TABLE bills
+  DROP FOREIGN KEY fk_bills_payment_account,
+  DROP COLUMN payment_account_id;
@@ -0,0 +1,5 @@
+-- the bank or cash account a bill is planned to be paid from, for the bills due schedule;
+-- NULL until someone picks one, the payment itself can still come from any account
+ALTER TABLE bills
+  ADD COLUMN payment_account_id int(11) DEFAULT NULL,
+  ADD CONSTRAINT fk_bills_payment_account FOREIGN KEY (payment_account_id) REFERENCES accounts (id);
@@ -0,0 +1,210 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+/*
+  ---------- rounding ----------
+*/
+
+// RoundingMode decides which way an exact half goes when a product or quotient is rounded
+type RoundingMode int
+
+const (
+	RoundHalfUp   RoundingMode = iota // halves away from zero, 2.5 -> 3 and -2.5 -> -3
+	RoundHalfEven                     // banker's rounding, halves to the even neighbour, 2.5 -> 2 and 3.5 -> 4
+)
+
+var rounding = RoundHalfUp
+
+// SetRounding sets the rounding mode used by every calculation in the package. Call it once at
+// startup; it is not safe to change while requests are being served.
+func SetRounding(mode RoundingMode) {
+	rounding = mode
+}
+
+// Rounding returns the rounding mode in use
+func Rounding() RoundingMode {
+	return rounding
+}
+
+// ParseRoundingMode reads a rounding mode from configuration: "half_up", or "half_even" / "bankers"
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "half_up":
+		return RoundHalfUp, nil
+	case "half_even", "bankers":
+		return RoundHalfEven, nil
+	}
+	return 0, errors.New("unknown rounding mode " + s)
+}
+
+/*
+  ---------- exact decimals ----------
+*/
+
+var (
+	errInvalidDecimal  = errors.New("invalid decimal")
+	errTooManyDecimals = errors.New("too many decimals")
+	errOutOfRange      = errors.New("value out of range")
+)
+
+// parseDecimal parses a plain decimal string such as "-1,234.50" exactly into an integer at
+// 10^scale, so "1.5" at scale 2 is 150. Thousands separators are ignored; exponents are not accepted.
+func parseDecimal(s string, scale int) (int64, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+
+	negative := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	if whole == "" && fraction == "" {
+		return 0, errInvalidDecimal
+	}
+	if len(fraction) > scale {
+		if !isDigits(fraction) {
+			return 0, errInvalidDecimal
+		}
+		return 0, errTooManyDecimals
+	}
+
+	var value uint64
+	for _, part := range []string{whole, fraction + strings.Repeat("0", scale-len(fraction))} {
+		if !isDigits(part) {
+			return 0, errInvalidDecimal
+		}
+		for i := 0; i < len(part); i++ {
+			hi, lo := bits.Mul64(value, 10)
+			lo, carry := bits.Add64(lo, uint64(part[i]-'0'), 0)
+			if hi != 0 || carry != 0 || lo > math.MaxInt64 {
+				return 0, errOutOfRange
+			}
+			value = lo
+		}
+	}
+
+	if negative {
+		return -int64(value), nil
+	}
+	return int64(value), nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseScaled parses a value at 10^scale and checks it against [min, max]. name is used in the
+// errors, e.g. "invalid qty" or "qty cannot have more than 5 decimals".
+func parseScaled(s string, scale int, name string, min, max int64) (int64, error) {
+	value, err := parseDecimal(s, scale)
+	switch err {
+	case nil:
+	case errTooManyDecimals:
+		return 0, errors.New(name + " cannot have more than " + strconv.Itoa(scale) + " decimals")
+	case errOutOfRange:
+		return 0, errors.New(name + " out of allowed range")
+	default:
+		return 0, errors.New("invalid " + name)
+	}
+
+	if value < min || value > max {
+		return 0, errors.New(name + " out of allowed range")
+	}
+	return value, nil
+}
+
+// formatScaled prints an integer at 10^scale exactly, e.g. 150 at scale 2 is "1.50". With trim
+// set trailing zeros are dropped, so 150 at scale 2 is "1.5" and 100 is "1".
+func formatScaled(value int64, scale int, trim bool) string {
+	negative := value < 0
+	magnitude := uint64(value)
+	if negative {
+		magnitude = -magnitude
+	}
+
+	digits := strconv.FormatUint(magnitude, 10)
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+
+	whole, fraction := digits[:len(digits)-scale], digits[len(digits)-scale:]
+	if trim {
+		fraction = strings.TrimRight(fraction, "0")
+	}
+
+	out := whole
+	if fraction != "" {
+		out += "." + fraction
+	}
+	if negative {
+		out = "-" + out
+	}
+	return out
+}
+
+/*
+  ---------- exact arithmetic ----------
+*/
+
+// MulDiv returns a*b/d rounded with the package rounding mode. The product is kept in 128 bits,
+// so the result is exact whenever it fits in an int64; otherwise an error is returned.
+func MulDiv(a, b, d int64) (int64, error) {
+	if d == 0 {
+		return 0, errors.New("division by zero")
+	}
+
+	negative := (a < 0) != (b < 0) != (d < 0)
+	hi, lo := bits.Mul64(absUint(a), absUint(b))
+	divisor := absUint(d)
+	if hi >= divisor {
+		return 0, errOutOfRange
+	}
+
+	quotient, remainder := bits.Div64(hi, lo, divisor)
+	if roundUp(quotient, remainder, divisor) {
+		quotient++
+	}
+
+	if quotient > math.MaxInt64 {
+		return 0, errOutOfRange
+	}
+	if negative {
+		return -int64(quotient), nil
+	}
+	return int64(quotient), nil
+}
+
+// roundUp reports whether the magnitude quotient + remainder/divisor rounds away from zero
+func roundUp(quotient, remainder, divisor uint64) bool {
+	half := divisor - remainder // compared against remainder so 2*remainder cannot overflow
+	switch {
+	case remainder == 0 || remainder < half:
+		return false
+	case remainder > half:
+		return true
+	}
+	if rounding == RoundHalfEven {
+		return quotient%2 == 1
+	}
+	return true
+}
+
+func absUint(v int64) uint64 {
+	if v < 0 {
+		return -uint64(v)
+	}
+	return uint64(v)
+}
@@ -0,0 +1,249 @@
+package money
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+// withRounding switches the package rounding mode for the rest of a test
+func withRounding(t *testing.T, mode RoundingMode) {
+	t.Helper()
+	previous := Rounding()
+	SetRounding(mode)
+	t.Cleanup(func() { SetRounding(previous) })
+}
+
+func TestParseUSDAmount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr string
+	}{
+		{in: "0", want: 0},
+		{in: "1", want: 100},
+		{in: "1.5", want: 150},
+		{in: "1.50", want: 150},
+		{in: ".5", want: 50},
+		{in: "5.", want: 500},
+		{in: "-0.01", want: -1},
+		{in: "+12.34", want: 1234},
+		{in: " 1,234.56 ", want: 123456},
+		{in: "0.1", want: 10}, // not 9 or 11 the way float64 could make it
+		{in: "0.29", want: 29},
+		{in: "99999999999999.99", want: MaxTotalMoney},
+		{in: "-99999999999999.99", want: -MaxTotalMoney},
+		{in: "1.234", wantErr: "amount cannot have more than 2 decimals"},
+		{in: "100000000000000.00", wantErr: "amount out of allowed range"},
+		{in: "99999999999999999999", wantErr: "amount out of allowed range"},
+		{in: "", wantErr: "invalid amount"},
+		{in: ".", wantErr: "invalid amount"},
+		{in: "-", wantErr: "invalid amount"},
+		{in: "1e3", wantErr: "invalid amount"},
+		{in: "1.2.3", wantErr: "invalid amount"},
+		{in: "12a", wantErr: "invalid amount"},
+		{in: "1.x5", wantErr: "invalid amount"},
+		{in: "NaN", wantErr: "invalid amount"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseUSDAmount(tt.in)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ParseUSDAmount(%q) error = %v, want %q", tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseUSDAmount(%q) unexpected error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseUSDAmount(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatScaled(t *testing.T) {
+	tests := []struct {
+		value int64
+		scale int
+		trim  bool
+		want  string
+	}{
+		{value: 0, scale: 2, want: "0.00"},
+		{value: 5, scale: 2, want: "0.05"},
+		{value: -5, scale: 2, want: "-0.05"},
+		{value: 150, scale: 2, want: "1.50"},
+		{value: 150, scale: 2, trim: true, want: "1.5"},
+		{value: 100, scale: 2, trim: true, want: "1"},
+		{value: 123456789, scale: 5, want: "1234.56789"},
+		{value: 1235, scale: 0, want: "1235"},
+		{value: math.MaxInt64, scale: 2, want: "92233720368547758.07"},
+		{value: math.MinInt64, scale: 2, want: "-92233720368547758.08"},
+	}
+
+	for _, tt := range tests {
+		if got := formatScaled(tt.value, tt.scale, tt.trim); got != tt.want {
+			t.Errorf("formatScaled(%d, %d, %v) = %q, want %q", tt.value, tt.scale, tt.trim, got, tt.want)
+		}
+	}
+}
+
+// every amount in range prints and parses back to itself, trimmed or not
+func TestParseFormatRoundTrip(t *testing.T) {
+	roundTrip := func(v int64) bool {
+		cents := v % (MaxTotalMoney + 1)
+		for _, trim := range []bool{false, true} {
+			got, err := ParseUSDAmount(formatScaled(cents, 2, trim))
+			if err != nil || got != cents {
+				return false
+			}
+		}
+		return true
+	}
+
+	if err := quick.Check(roundTrip, &quick.Config{MaxCount: 10000}); err != nil {
+		t.Error(err)
+	}
+	for _, cents := range []int64{0, 1, -1, 99, 100, MaxTotalMoney, -MaxTotalMoney} {
+		if !roundTrip(cents) {
+			t.Errorf("%d does not survive formatScaled and ParseUSDAmount", cents)
+		}
+	}
+}
+
+func FuzzParseUSDAmount(f *testing.F) {
+	for _, seed := range []string{"0", "1.5", "-0.01", "1,234.56", "99999999999999.99", "1.234", "1e3", ""} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		cents, err := ParseUSDAmount(s)
+		if err != nil {
+			return
+		}
+		if cents < -MaxTotalMoney || cents > MaxTotalMoney {
+			t.Fatalf("ParseUSDAmount(%q) = %d, outside MaxTotalMoney", s, cents)
+		}
+		again, err := ParseUSDAmount(formatScaled(cents, 2, false))
+		if err != nil || again != cents {
+			t.Fatalf("ParseUSDAmount(%q) = %d does not round-trip: got %d, %v", s, cents, again, err)
+		}
+	})
+}
+
+func TestMulDiv(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b, d  int64
+		halfUp   int64
+		halfEven int64
+		wantErr  bool
+	}{
+		{name: "exact", a: 6, b: 7, d: 2, halfUp: 21, halfEven: 21},
+		{name: "below half", a: 1, b: 1, d: 3, halfUp: 0, halfEven: 0},
+		{name: "above half", a: 2, b: 1, d: 3, halfUp: 1, halfEven: 1},
+		{name: "half to odd", a: 5, b: 1, d: 2, halfUp: 3, halfEven: 2},
+		{name: "half to even", a: 7, b: 1, d: 2, halfUp: 4, halfEven: 4},
+		{name: "negative half", a: -5, b: 1, d: 2, halfUp: -3, halfEven: -2},
+		{name: "negative divisor", a: 5, b: 1, d: -2, halfUp: -3, halfEven: -2},
+		{name: "two negatives", a: -5, b: -1, d: 2, halfUp: 3, halfEven: 2},
+		{name: "zero", a: 0, b: math.MaxInt64, d: 7, halfUp: 0, halfEven: 0},
+		// the product needs more than 64 bits but the result fits
+		{name: "wide product", a: math.MaxInt64, b: math.MaxInt64, d: math.MaxInt64, halfUp: math.MaxInt64, halfEven: math.MaxInt64},
+		{name: "wide product rounded", a: MaxTotalMoney, b: 3 * FXRateScale, d: 2 * FXRateScale, halfUp: 14_999_999_999_999_999, halfEven: 14_999_999_999_999_998},
+		{name: "min int64", a: math.MinInt64, b: 1, d: 1, wantErr: true},
+		{name: "quotient overflows", a: math.MaxInt64, b: 2, d: 1, wantErr: true},
+		{name: "high word overflows", a: math.MaxInt64, b: math.MaxInt64, d: 1, wantErr: true},
+		{name: "exact at max", a: math.MaxInt64, b: 2, d: 2, halfUp: math.MaxInt64, halfEven: math.MaxInt64},
+		{name: "division by zero", a: 1, b: 1, d: 0, wantErr: true},
+	}
+
+	for _, mode := range []RoundingMode{RoundHalfUp, RoundHalfEven} {
+		withRounding(t, mode)
+		for _, tt := range tests {
+			got, err := MulDiv(tt.a, tt.b, tt.d)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("%s: MulDiv(%d, %d, %d) = %d, want an error", tt.name, tt.a, tt.b, tt.d, got)
+				}
+				continue
+			}
+			want := tt.halfUp
+			if mode == RoundHalfEven {
+				want = tt.halfEven
+			}
+			if err != nil {
+				t.Errorf("%s: MulDiv(%d, %d, %d) unexpected error: %v", tt.name, tt.a, tt.b, tt.d, err)
+				continue
+			}
+			if got != want {
+				t.Errorf("%s (mode %d): MulDiv(%d, %d, %d) = %d, want %d", tt.name, mode, tt.a, tt.b, tt.d, got, want)
+			}
+		}
+	}
+}
+
+// mulDivBig is MulDiv worked out with math/big, for checking it
+func mulDivBig(a, b, d int64, mode RoundingMode) (*big.Int, bool) {
+	if d == 0 {
+		return nil, false
+	}
+	num := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+	den := big.NewInt(d)
+	if den.Sign() < 0 {
+		num.Neg(num)
+		den.Neg(den)
+	}
+
+	negative := num.Sign() < 0
+	num.Abs(num)
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	twice := new(big.Int).Lsh(r, 1)
+	switch twice.Cmp(den) {
+	case 1:
+		q.Add(q, big.NewInt(1))
+	case 0:
+		if mode == RoundHalfUp || q.Bit(0) == 1 {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	if negative {
+		q.Neg(q)
+	}
+	return q, true
+}
+
+func TestMulDivMatchesBig(t *testing.T) {
+	for _, mode := range []RoundingMode{RoundHalfUp, RoundHalfEven} {
+		withRounding(t, mode)
+
+		matches := func(a, b, d int64) bool {
+			want, ok := mulDivBig(a, b, d, mode)
+			got, err := MulDiv(a, b, d)
+			if !ok {
+				return err != nil
+			}
+			// MulDiv keeps results to what fits an int64 magnitude, so MinInt64 is out of range
+			if want.IsInt64() && want.Int64() != math.MinInt64 {
+				return err == nil && got == want.Int64()
+			}
+			return err != nil
+		}
+
+		if err := quick.Check(matches, &quick.Config{MaxCount: 20000}); err != nil {
+			t.Errorf("mode %d: %v", mode, err)
+		}
+
+		// small divisors make exact halves and results near the int64 limit likely
+		near := func(a, b int64, d int8) bool {
+			return matches(a, b, int64(d))
+		}
+		if err := quick.Check(near, &quick.Config{MaxCount: 20000}); err != nil {
+			t.Errorf("mode %d, small divisors: %v", mode, err)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package money
+
+import (
+	"errors"
+	"strings"
+)
+
+/*
+  ---------- currencies ----------
+*/
+
+type currencyFormat struct {
+	symbol     string
+	minorUnits int // digits shown after the decimal separator
+}
+
+// currencyFormats lists the currencies with a known symbol or with no minor units; any other
+// valid code is shown by its code with 2 minor units
+var currencyFormats = map[string]currencyFormat{
+	"USD": {symbol: "$", minorUnits: 2},
+	"EUR": {symbol: "€", minorUnits: 2},
+	"GBP": {symbol: "£", minorUnits: 2},
+	"CAD": {symbol: "CA$", minorUnits: 2},
+	"AUD": {symbol: "A$", minorUnits: 2},
+	"CHF": {symbol: "CHF", minorUnits: 2},
+	"CNY": {symbol: "CN¥", minorUnits: 2},
+	"INR": {symbol: "₹", minorUnits: 2},
+	"KES": {symbol: "KSh", minorUnits: 2},
+	"AED": {symbol: "AED", minorUnits: 2},
+	"SAR": {symbol: "SAR", minorUnits: 2},
+	"JPY": {symbol: "¥", minorUnits: 0},
+	"KRW": {symbol: "₩", minorUnits: 0},
+}
+
+// thousandthCurrencies have 3 minor units. Amounts are kept in cents, so they cannot be shown
+// to the last digit and are not supported.
+var thousandthCurrencies = map[string]bool{
+	"BHD": true,
+	"IQD": true,
+	"JOD": true,
+	"KWD": true,
+	"LYD": true,
+	"OMR": true,
+	"TND": true,
+}
+
+/*
+  ---------- locales ----------
+*/
+
+type localeFormat struct {
+	decimal     string
+	group       string
+	symbolAfter bool // "1.234,56 €" rather than "€1,234.56"
+}
+
+var localeFormats = map[string]localeFormat{
+	"en-US": {decimal: ".", group: ","},
+	"en-GB": {decimal: ".", group: ","},
+	"so-SO": {decimal: ".", group: ","},
+	"de-DE": {decimal: ",", group: ".", symbolAfter: true},
+	"es-ES": {decimal: ",", group: ".", symbolAfter: true},
+	"it-IT": {decimal: ",", group: ".", symbolAfter: true},
+	"fr-FR": {decimal: ",", group: "\u202f", symbolAfter: true}, // narrow no-break space
+	"de-CH": {decimal: ".", group: "\u2019"},
+}
+
+// DefaultLocale is used when no locale is asked for
+const DefaultLocale = "en-US"
+
+// lookupLocale finds a locale by tag, accepting "de_DE" for "de-DE" and a bare language such as
+// "de" for the first locale of that language
+func lookupLocale(tag string) (localeFormat, error) {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if tag == "" {
+		tag = DefaultLocale
+	}
+
+	language, region, _ := strings.Cut(tag, "-")
+	language = strings.ToLower(language)
+	if region != "" {
+		if l, ok := localeFormats[language+"-"+strings.ToUpper(region)]; ok {
+			return l, nil
+		}
+	}
+	for _, fallback := range []string{"en-US", "en-GB", "so-SO", "de-DE", "es-ES", "it-IT", "fr-FR"} {
+		if strings.HasPrefix(fallback, language+"-") {
+			return localeFormats[fallback], nil
+		}
+	}
+	return localeFormat{}, errors.New("unsupported locale " + tag)
+}
+
+/*
+  ---------- display ----------
+*/
+
+// Formatter prints cents for people rather than for other programs. The zero value prints
+// plain amounts exactly like FormatMoneyFromCents.
+type Formatter struct {
+	currency   *currencyFormat // nil for plain amounts
+	locale     localeFormat
+	accounting bool
+}
+
+// NewFormatter returns a Formatter showing amounts in a currency the way a locale writes them:
+// grouped thousands, the currency symbol and the currency's minor units, e.g. "$1,234.50",
+// "1.234,50 €" or "¥1,235"
+func NewFormatter(currency, locale string) (Formatter, error) {
+	code, err := NormalizeCurrency(currency)
+	if err != nil {
+		return Formatter{}, err
+	}
+	if thousandthCurrencies[code] {
+		return Formatter{}, errors.New("amounts are kept in cents and cannot be shown in " + code + ", which has 3 decimals")
+	}
+	l, err := lookupLocale(locale)
+	if err != nil {
+		return Formatter{}, err
+	}
+
+	c, ok := currencyFormats[code]
+	if !ok {
+		c = currencyFormat{symbol: code, minorUnits: 2}
+	}
+	return Formatter{currency: &c, locale: l}, nil
+}
+
+// Accounting returns a copy that shows negative amounts in parentheses, "($1,234.50)", as on
+// financial statements. Plain amounts keep their minus sign.
+func (f Formatter) Accounting() Formatter {
+	f.accounting = true
+	return f
+}
+
+// Cents formats an amount in cents
+func (f Formatter) Cents(cents int64) string {
+	if f.currency == nil {
+		return FormatMoneyFromCents(cents)
+	}
+
+	minorUnits := f.currency.minorUnits
+	value, err := MulDiv(cents, pow10(minorUnits), MoneyScale) // e.g. 123456 cents is 1235 JPY
+	if err != nil {
+		value, minorUnits = cents, 2
+	}
+
+	digits := formatScaled(value, minorUnits, false)
+	negative := strings.HasPrefix(digits, "-")
+	digits = strings.TrimPrefix(digits, "-")
+
+	whole, fraction, _ := strings.Cut(digits, ".")
+	out := groupThousands(whole, f.locale.group)
+	if fraction != "" {
+		out += f.locale.decimal + fraction
+	}
+
+	symbol := f.currency.symbol
+	switch {
+	case f.locale.symbolAfter:
+		out = out + "\u00a0" + symbol
+	case isLetters(symbol):
+		out = symbol + "\u00a0" + out // "CHF 1,234.50" rather than "CHF1,234.50"
+	default:
+		out = symbol + out
+	}
+
+	if negative {
+		if f.accounting {
+			return "(" + out + ")"
+		}
+		return "-" + out
+	}
+	return out
+}
+
+// groupThousands separates a run of digits in threes from the right, e.g. "1234567" -> "1,234,567"
+func groupThousands(digits, separator string) string {
+	if len(digits) <= 3 || separator == "" {
+		return digits
+	}
+
+	var b strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(separator)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+func isLetters(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if (s[i] < 'A' || s[i] > 'Z') && (s[i] < 'a' || s[i] > 'z') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
+
+// Percent formats part as a percentage of whole with 2 decimals, e.g. 25 of 200 is "12.50%"
+func Percent(part, whole int64) (string, error) {
+	hundredths, err := MulDiv(part, 100*100, whole)
+	if err != nil {
+		return "", err
+	}
+	return formatScaled(hundredths, 2, false) + "%", nil
+}
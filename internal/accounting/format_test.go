@@ -0,0 +1,117 @@
+package money
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatterCents(t *testing.T) {
+	tests := []struct {
+		currency   string
+		locale     string
+		accounting bool
+		cents      int64
+		want       string
+	}{
+		// 2 decimals
+		{currency: "USD", locale: "en-US", cents: 123456, want: "$1,234.56"},
+		{currency: "USD", locale: "en-US", cents: 5, want: "$0.05"},
+		{currency: "USD", locale: "en-US", cents: 0, want: "$0.00"},
+		{currency: "usd", locale: "en_US", cents: 123456789, want: "$1,234,567.89"},
+		{currency: "EUR", locale: "de-DE", cents: 123456, want: "1.234,56\u00a0€"},
+		{currency: "EUR", locale: "fr", cents: 123456, want: "1\u202f234,56\u00a0€"},
+		{currency: "CHF", locale: "de-CH", cents: 123456, want: "CHF\u00a01’234.56"},
+		{currency: "SOS", locale: "so-SO", cents: 123456, want: "SOS\u00a01,234.56"}, // no known symbol
+
+		// 0 decimals, rounded from cents
+		{currency: "JPY", locale: "en-US", cents: 123456, want: "¥1,235"},
+		{currency: "JPY", locale: "en-US", cents: 123449, want: "¥1,234"},
+		{currency: "KRW", locale: "de-DE", cents: 100000000, want: "1.000.000\u00a0₩"},
+
+		// negatives
+		{currency: "USD", locale: "en-US", cents: -123456, want: "-$1,234.56"},
+		{currency: "USD", locale: "en-US", cents: -5, want: "-$0.05"},
+		{currency: "EUR", locale: "de-DE", cents: -123456, want: "-1.234,56\u00a0€"},
+		{currency: "JPY", locale: "en-US", cents: -123456, want: "-¥1,235"},
+
+		// accounting brackets
+		{currency: "USD", locale: "en-US", accounting: true, cents: -123456, want: "($1,234.56)"},
+		{currency: "USD", locale: "en-US", accounting: true, cents: 123456, want: "$1,234.56"},
+		{currency: "EUR", locale: "de-DE", accounting: true, cents: -123456, want: "(1.234,56\u00a0€)"},
+		{currency: "JPY", locale: "en-US", accounting: true, cents: -123456, want: "(¥1,235)"},
+		{currency: "CHF", locale: "de-CH", accounting: true, cents: -5, want: "(CHF\u00a00.05)"},
+	}
+
+	for _, tt := range tests {
+		f, err := NewFormatter(tt.currency, tt.locale)
+		if err != nil {
+			t.Errorf("NewFormatter(%q, %q): %v", tt.currency, tt.locale, err)
+			continue
+		}
+		if tt.accounting {
+			f = f.Accounting()
+		}
+		if got := f.Cents(tt.cents); got != tt.want {
+			t.Errorf("NewFormatter(%q, %q) accounting=%v: Cents(%d) = %q, want %q", tt.currency, tt.locale, tt.accounting, tt.cents, got, tt.want)
+		}
+	}
+}
+
+// the zero value prints plain amounts, with a minus sign even when asked for brackets
+func TestFormatterPlain(t *testing.T) {
+	for _, cents := range []int64{0, 5, -5, 123456, -123456} {
+		want := FormatMoneyFromCents(cents)
+		if got := (Formatter{}).Cents(cents); got != want {
+			t.Errorf("Formatter{}.Cents(%d) = %q, want %q", cents, got, want)
+		}
+		if got := (Formatter{}).Accounting().Cents(cents); got != want {
+			t.Errorf("Formatter{}.Accounting().Cents(%d) = %q, want %q", cents, got, want)
+		}
+	}
+}
+
+func TestNewFormatterRejects(t *testing.T) {
+	tests := []struct {
+		currency string
+		locale   string
+		wantErr  string
+	}{
+		// 3 decimals: amounts are kept in cents
+		{currency: "KWD", locale: "en-US", wantErr: "3 decimals"},
+		{currency: "bhd", locale: "en-US", wantErr: "3 decimals"},
+		{currency: "JOD", locale: "en-US", wantErr: "3 decimals"},
+
+		{currency: "US", locale: "en-US", wantErr: "3 letter code"},
+		{currency: "USD", locale: "xx-XX", wantErr: "unsupported locale"},
+	}
+
+	for _, tt := range tests {
+		_, err := NewFormatter(tt.currency, tt.locale)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("NewFormatter(%q, %q) error = %v, want %q", tt.currency, tt.locale, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		part, whole int64
+		want        string
+	}{
+		{part: 25, whole: 200, want: "12.50%"},
+		{part: 1, whole: 3, want: "33.33%"},
+		{part: -1, whole: 8, want: "-12.50%"},
+		{part: 0, whole: 5, want: "0.00%"},
+	}
+
+	for _, tt := range tests {
+		got, err := Percent(tt.part, tt.whole)
+		if err != nil {
+			t.Errorf("Percent(%d, %d): %v", tt.part, tt.whole, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Percent(%d, %d) = %q, want %q", tt.part, tt.whole, got, tt.want)
+		}
+	}
+}
@@ -14,67 +14,22 @@ const (
 	RateScale  int64 = 100_000 // 5 decimals
 	MoneyScale int64 = 100     // cents
 
-	MaxQty        = 9_999_999_999.99999
-	MaxRate       = 9_999_999_999_999.99999
-	MaxTotalMoney = 99_999_999_999_999.99 // 14 digits before decimal
+	MaxQty        int64 = 999_999_999_999_999     // 9,999,999,999.99999 at QtyScale
+	MaxRate       int64 = 999_999_999_999_999_999 // 9,999,999,999,999.99999 at RateScale
+	MaxTotalMoney int64 = 9_999_999_999_999_999   // 99,999,999,999,999.99 in cents, 14 digits before decimal
 
 )
 
-/*
-  ---------- helpers ----------
-*/
-
-func decimalPlaces(s string) int {
-	s = strings.ReplaceAll(s, ",", "")
-	if !strings.Contains(s, ".") {
-		return 0
-	}
-	return len(s) - strings.Index(s, ".") - 1
-}
-
-func parseFloatStrict(s string) (float64, error) {
-	if s == "" {
-		return 0, errors.New("empty value")
-	}
-
-	// Remove thousands separators
-	s = strings.ReplaceAll(s, ",", "")
-
-	return strconv.ParseFloat(s, 64)
-}
-
 /*
   ---------- qty ----------
 */
 
 func ParsePreviousValue(previousValueStr string) (int64, error) {
-	previousValue, err := parseFloatStrict(previousValueStr)
-	if err != nil {
-		return 0, errors.New("invalid previous value")
-	}
-
-	if decimalPlaces(previousValueStr) > 5 {
-		return 0, errors.New("previous value cannot have more than 5 decimals")
-	}
-
-	// if previousValue < 0 || previousValue > MaxQty {
-	// 	return 0, errors.New("previous value out of allowed range")
-	// }
-
-	return int64(math.Round(previousValue * float64(PreviousValueScale))), nil
+	return parseScaled(previousValueStr, 5, "previous value", math.MinInt64, math.MaxInt64)
 }
 
 func ParseCurrentValue(currentValueStr string) (int64, error) {
-	currentValue, err := parseFloatStrict(currentValueStr)
-	if err != nil {
-		return 0, errors.New("invalid current value")
-	}
-
-	if decimalPlaces(currentValueStr) > 5 {
-		return 0, errors.New("current value cannot have more than 5 decimals")
-	}
-
-	return int64(math.Round(currentValue * float64(CurrentValueScale))), nil
+	return parseScaled(currentValueStr, 5, "current value", math.MinInt64, math.MaxInt64)
 }
 
 
@@ -83,56 +38,69 @@ func ParseCurrentValue(currentValueStr string) (int64, error) {
 */
 
 func ParseQty(qtyStr string) (int64, error) {
-	qty, err := parseFloatStrict(qtyStr)
-	if err != nil {
-		return 0, errors.New("invalid qty")
-	}
+	return parseScaled(qtyStr, 5, "qty", 0, MaxQty)
+}
 
-	if decimalPlaces(qtyStr) > 5 {
-		return 0, errors.New("qty cannot have more than 5 decimals")
-	}
+/*
+  ---------- rate ----------
+*/
 
-	if qty < 0 || qty > MaxQty {
-		return 0, errors.New("qty out of allowed range")
-	}
+func ParseRate(rateStr string) (int64, error) {
+	return parseScaled(rateStr, 5, "rate", 0, MaxRate)
+}
 
-	return int64(math.Round(qty * float64(QtyScale))), nil
+func ParseUSDAmount(amountStr string) (int64, error) {
+	return parseScaled(amountStr, 2, "amount", -MaxTotalMoney, MaxTotalMoney)
 }
 
 /*
-  ---------- rate ----------
+  ---------- currency ----------
 */
 
-func ParseRate(rateStr string) (int64, error) {
-	rate, err := parseFloatStrict(rateStr)
-	if err != nil {
-		return 0, errors.New("invalid rate")
-	}
+// FXRateScale is the scale of exchange rates: base currency per one unit of a foreign currency
+const FXRateScale int64 = 100_000_000 // 8 decimals
 
-	if decimalPlaces(rateStr) > 5 {
-		return 0, errors.New("rate cannot have more than 5 decimals")
-	}
+// MaxExchangeRate is 9,999,999,999.99999999 at FXRateScale
+const MaxExchangeRate int64 = 999_999_999_999_999_999
 
-	if rate < 0 || rate > MaxRate {
-		return 0, errors.New("rate out of allowed range")
+// NormalizeCurrency upper-cases and checks an ISO 4217 style currency code, e.g. "usd" -> "USD"
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return "", errors.New("currency must be a 3 letter code")
 	}
-
-	return int64(math.Round(rate * float64(RateScale))), nil
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return "", errors.New("currency must be a 3 letter code")
+		}
+	}
+	return code, nil
 }
 
-func ParseUSDAmount(amountStr string) (int64, error) {
-	amount, err := parseFloatStrict(amountStr)
-	if err != nil {
-		return 0, errors.New("invalid amount")
+// ParseAmount parses an amount in any currency into its minor units; like ParseUSDAmount every
+// currency is kept with 2 decimals
+func ParseAmount(amountStr string, currency string) (int64, error) {
+	if _, err := NormalizeCurrency(currency); err != nil {
+		return 0, err
 	}
+	return ParseUSDAmount(amountStr)
+}
 
-	if decimalPlaces(amountStr) > 2 {
-		return 0, errors.New("amount cannot have more than 2 decimals")
-	}
+func ParseExchangeRate(rateStr string) (int64, error) {
+	return parseScaled(rateStr, 8, "exchange rate", 1, MaxExchangeRate)
+}
 
-	// TODO : implement max amount
+// ConvertCents converts foreign currency cents to base currency cents at an FXRateScale rate
+func ConvertCents(foreignCents, rateScaled int64) (int64, error) {
+	cents, err := MulDiv(foreignCents, rateScaled, FXRateScale)
+	if err != nil || cents < -MaxTotalMoney || cents > MaxTotalMoney {
+		return 0, errors.New("converted amount out of allowed range")
+	}
+	return cents, nil
+}
 
-	return int64(math.Round(amount * float64(MoneyScale))), nil
+func FormatExchangeRate(rateScaled int64) string {
+	return formatScaled(rateScaled, 8, true)
 }
 
 /*
@@ -140,9 +108,11 @@ func ParseUSDAmount(amountStr string) (int64, error) {
 */
 
 func CalculateTotalCents(qtyScaled, rateScaled int64) (int64, error) {
-	totalCents := int64(math.Round(
-		(float64(qtyScaled) * float64(rateScaled)) / 100_000_000,
-	))
+	// qty and rate are both at 5 decimals, so the product is at 10 and cents are at 2
+	totalCents, err := MulDiv(qtyScaled, rateScaled, QtyScale*RateScale/MoneyScale)
+	if err != nil || totalCents > MaxTotalMoney {
+		return 0, errors.New("total out of allowed range")
+	}
 
 	if totalCents < 0 {
 		return 0, errors.New("total cannot be negative")
@@ -151,6 +121,22 @@ func CalculateTotalCents(qtyScaled, rateScaled int64) (int64, error) {
 	return totalCents, nil
 }
 
+// AverageCostScaled is the cost of one unit, at RateScale, when qtyScaled units are worth valueCents.
+// A cost too large for an int64 comes back as the largest one that fits.
+func AverageCostScaled(qtyScaled, valueCents int64) int64 {
+	if qtyScaled == 0 {
+		return 0
+	}
+	cost, err := MulDiv(valueCents, RateScale*QtyScale/MoneyScale, qtyScaled)
+	if err != nil {
+		if (valueCents < 0) != (qtyScaled < 0) {
+			return math.MinInt64
+		}
+		return math.MaxInt64
+	}
+	return cost
+}
+
 /*
   ---------- one-shot ----------
 */
@@ -218,19 +204,69 @@ func ConvertLineInput(in LineInput) (*LineResult, error) {
 
 
 func FormatMoneyFromCents(cents int64) string {
-	return strconv.FormatFloat(
-		float64(cents)/float64(MoneyScale),
-		'f',
-		2,
-		64,
-	)
+	return formatScaled(cents, 2, false)
 }
 
 func FormatScaled5(value int64) string {
-	return strconv.FormatFloat(
-		float64(value)/100_000,
-		'f',
-		-1, // no trailing zeros unless needed
-		64,
-	)
+	return formatScaled(value, 5, true) // no trailing zeros unless needed
+}
+
+/*
+  ---------- words ----------
+*/
+
+var (
+	smallNumberWords = []string{
+		"Zero", "One", "Two", "Three", "Four", "Five", "Six", "Seven", "Eight", "Nine", "Ten",
+		"Eleven", "Twelve", "Thirteen", "Fourteen", "Fifteen", "Sixteen", "Seventeen", "Eighteen", "Nineteen",
+	}
+	tensWords  = []string{"", "", "Twenty", "Thirty", "Forty", "Fifty", "Sixty", "Seventy", "Eighty", "Ninety"}
+	scaleWords = []string{"", "Thousand", "Million", "Billion", "Trillion"}
+)
+
+// AmountInWords spells an amount the way it is written on a check, e.g. "One Hundred Five and 20/100"
+func AmountInWords(cents int64) string {
+	if cents < 0 {
+		return "Minus " + AmountInWords(-cents)
+	}
+
+	dollars := cents / MoneyScale
+	remainder := cents % MoneyScale
+
+	words := []string{}
+	if dollars == 0 {
+		words = append(words, smallNumberWords[0])
+	}
+	for scale := 0; dollars > 0; scale++ {
+		group := dollars % 1000
+		dollars /= 1000
+		if group == 0 {
+			continue
+		}
+		part := hundredsInWords(group)
+		if scaleWords[scale] != "" {
+			part += " " + scaleWords[scale]
+		}
+		words = append([]string{part}, words...)
+	}
+
+	return strings.Join(words, " ") + " and " + strconv.FormatInt(remainder/10, 10) + strconv.FormatInt(remainder%10, 10) + "/100"
+}
+
+// hundredsInWords spells 1-999
+func hundredsInWords(n int64) string {
+	words := []string{}
+	if n >= 100 {
+		words = append(words, smallNumberWords[n/100], "Hundred")
+		n %= 100
+	}
+	switch {
+	case n >= 20 && n%10 != 0:
+		words = append(words, tensWords[n/10]+"-"+smallNumberWords[n%10])
+	case n >= 20:
+		words = append(words, tensWords[n/10])
+	case n > 0:
+		words = append(words, smallNumberWords[n])
+	}
+	return strings.Join(words, " ")
 }
@@ -0,0 +1,140 @@
+package money
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMaxTotalMoney(t *testing.T) {
+	tests := []struct {
+		name    string
+		calc    func() (int64, error)
+		want    int64
+		wantErr bool
+	}{
+		{
+			// 1,000 units at 99,999,999,999.99999 each
+			name: "total at the limit",
+			calc: func() (int64, error) { return CalculateTotalCents(1000*QtyScale, MaxTotalMoney) },
+			want: MaxTotalMoney,
+		},
+		{
+			name:    "total a cent over",
+			calc:    func() (int64, error) { return CalculateTotalCents(1000*QtyScale, MaxTotalMoney+1) },
+			wantErr: true,
+		},
+		{
+			name:    "total of the largest qty and rate",
+			calc:    func() (int64, error) { return CalculateTotalCents(MaxQty, MaxRate) },
+			wantErr: true,
+		},
+		{
+			name: "total rounds half a cent",
+			calc: func() (int64, error) { return CalculateTotalCents(QtyScale/2, RateScale/100) }, // 0.5 at 0.01
+			want: 1,
+		},
+		{
+			name:    "negative total",
+			calc:    func() (int64, error) { return CalculateTotalCents(-QtyScale, RateScale) },
+			wantErr: true,
+		},
+		{
+			name: "conversion at the limit",
+			calc: func() (int64, error) { return ConvertCents(MaxTotalMoney, FXRateScale) },
+			want: MaxTotalMoney,
+		},
+		{
+			name:    "conversion over the limit",
+			calc:    func() (int64, error) { return ConvertCents(MaxTotalMoney, 2*FXRateScale) },
+			wantErr: true,
+		},
+		{
+			name:    "negative conversion over the limit",
+			calc:    func() (int64, error) { return ConvertCents(-MaxTotalMoney, 2*FXRateScale) },
+			wantErr: true,
+		},
+		{
+			name:    "conversion overflowing int64",
+			calc:    func() (int64, error) { return ConvertCents(MaxTotalMoney, MaxExchangeRate) },
+			wantErr: true,
+		},
+		{
+			name: "conversion at a fractional rate",
+			calc: func() (int64, error) { return ConvertCents(1000, 123_456_789) }, // 10.00 at 1.23456789
+			want: 1235,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.calc()
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: got %d, want an error", tt.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		parse   func(string) (int64, error)
+		in      string
+		want    int64
+		wantErr string
+	}{
+		{name: "qty", parse: ParseQty, in: "9999999999.99999", want: MaxQty},
+		{name: "qty", parse: ParseQty, in: "10000000000", wantErr: "qty out of allowed range"},
+		{name: "qty", parse: ParseQty, in: "-1", wantErr: "qty out of allowed range"},
+		{name: "qty", parse: ParseQty, in: "0.000001", wantErr: "qty cannot have more than 5 decimals"},
+		{name: "rate", parse: ParseRate, in: "9999999999999.99999", want: MaxRate},
+		{name: "rate", parse: ParseRate, in: "10000000000000", wantErr: "rate out of allowed range"},
+		{name: "exchange rate", parse: ParseExchangeRate, in: "0.00000001", want: 1},
+		{name: "exchange rate", parse: ParseExchangeRate, in: "0", wantErr: "exchange rate out of allowed range"},
+		{name: "exchange rate", parse: ParseExchangeRate, in: "1.000000001", wantErr: "exchange rate cannot have more than 8 decimals"},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.parse(tt.in)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%s %q: error = %v, want %q", tt.name, tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %q: unexpected error: %v", tt.name, tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s %q = %d, want %d", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAverageCostScaled(t *testing.T) {
+	tests := []struct {
+		qtyScaled, valueCents int64
+		want                  int64
+	}{
+		{qtyScaled: 0, valueCents: 100, want: 0},
+		{qtyScaled: 3 * QtyScale, valueCents: 100, want: 33333}, // 1.00 over 3 is 0.33333
+		{qtyScaled: 3 * QtyScale, valueCents: 200, want: 66667},
+		{qtyScaled: 1, valueCents: MaxTotalMoney, want: math.MaxInt64},
+		{qtyScaled: -1, valueCents: MaxTotalMoney, want: math.MinInt64},
+	}
+
+	for _, tt := range tests {
+		if got := AverageCostScaled(tt.qtyScaled, tt.valueCents); got != tt.want {
+			t.Errorf("AverageCostScaled(%d, %d) = %d, want %d", tt.qtyScaled, tt.valueCents, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package bankstatement
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// camtDocument covers the parts of a camt.053 BankToCustomerStatement the importer reads.
+// Element names are matched without namespace so any camt.053.001.xx version is accepted.
+type camtDocument struct {
+	XMLName    xml.Name `xml:"Document"`
+	Statements []struct {
+		Entries []camtEntry `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtEntry struct {
+	Amount      string `xml:"Amt"`
+	CreditDebit string `xml:"CdtDbtInd"`
+	Reversal    bool   `xml:"RvslInd"`
+	Status      struct {
+		Text string `xml:",chardata"` // camt.053.001.02 to .07
+		Code string `xml:"Cd"`        // camt.053.001.08 onwards
+	} `xml:"Sts"`
+	BookingDate     string `xml:"BookgDt>Dt"`
+	BookingDateTime string `xml:"BookgDt>DtTm"`
+	ValueDate       string `xml:"ValDt>Dt"`
+	ServicerRef     string `xml:"AcctSvcrRef"`
+	EntryRef        string `xml:"NtryRef"`
+	AdditionalInfo  string `xml:"AddtlNtryInf"`
+	Details         []struct {
+		EndToEndID   string   `xml:"Refs>EndToEndId"`
+		ServicerRef  string   `xml:"Refs>AcctSvcrRef"`
+		Unstructured []string `xml:"RmtInf>Ustrd"`
+		CreditorName string   `xml:"RltdPties>Cdtr>Nm"`
+		CreditorPty  string   `xml:"RltdPties>Cdtr>Pty>Nm"`
+		DebtorName   string   `xml:"RltdPties>Dbtr>Nm"`
+		DebtorPty    string   `xml:"RltdPties>Dbtr>Pty>Nm"`
+	} `xml:"NtryDtls>TxDtls"`
+}
+
+// parseCAMT053 reads the booked entries of an ISO 20022 camt.053 statement
+func parseCAMT053(content []byte) ([]Line, error) {
+	var doc camtDocument
+	if err := xml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("invalid camt.053 file: %v", err)
+	}
+	if len(doc.Statements) == 0 {
+		return nil, errors.New("not a camt.053 statement")
+	}
+
+	var lines []Line
+	for _, stmt := range doc.Statements {
+		for i, entry := range stmt.Entries {
+			// pending and informational entries are not on the ledger yet
+			status := strings.ToUpper(strings.TrimSpace(entry.Status.Code + entry.Status.Text))
+			if status != "" && status != "BOOK" {
+				continue
+			}
+
+			line, err := camtLine(entry)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: %v", i+1, err)
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, nil
+}
+
+func camtLine(entry camtEntry) (Line, error) {
+	date := firstNonEmpty(entry.BookingDate, entry.BookingDateTime, entry.ValueDate)
+	if len(date) < 10 {
+		return Line{}, fmt.Errorf("missing booking date")
+	}
+
+	amountCents, err := parseAmount(entry.Amount, false)
+	if err != nil {
+		return Line{}, err
+	}
+
+	// a reversal flips the direction of the original entry
+	debit := strings.EqualFold(entry.CreditDebit, "DBIT")
+	if entry.Reversal {
+		debit = !debit
+	}
+	if debit {
+		amountCents = -amountCents
+	}
+
+	var description, reference, fitid string
+	fitid = entry.ServicerRef
+	for _, d := range entry.Details {
+		party := firstNonEmpty(d.CreditorName, d.CreditorPty)
+		if !debit {
+			party = firstNonEmpty(d.DebtorName, d.DebtorPty)
+		}
+		description = strings.TrimSpace(strings.Join(append([]string{party}, d.Unstructured...), " "))
+		reference = d.EndToEndID
+		if strings.EqualFold(reference, "NOTPROVIDED") {
+			reference = ""
+		}
+		if fitid == "" {
+			fitid = d.ServicerRef
+		}
+		break
+	}
+	if description == "" {
+		description = entry.AdditionalInfo
+	}
+	if reference == "" {
+		reference = entry.EntryRef
+	}
+
+	return Line{
+		Date:        date[:10],
+		AmountCents: amountCents,
+		Description: description,
+		Reference:   reference,
+		FITID:       fitid,
+	}, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
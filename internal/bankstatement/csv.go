@@ -0,0 +1,153 @@
+package bankstatement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// CSVMapping tells the importer which columns of a bank's CSV export hold which field.
+// Columns are zero-based. Either AmountColumn (signed) or DebitColumn/CreditColumn must be set.
+type CSVMapping struct {
+	Delimiter         string `json:"delimiter"` // defaults to ","
+	HasHeader         bool   `json:"has_header"`
+	DateColumn        int    `json:"date_column"`
+	DateFormat        string `json:"date_format"` // e.g. "YYYY-MM-DD", "DD/MM/YYYY", "MM/DD/YYYY"
+	AmountColumn      *int   `json:"amount_column"`
+	DebitColumn       *int   `json:"debit_column"`  // money out
+	CreditColumn      *int   `json:"credit_column"` // money in
+	DescriptionColumn *int   `json:"description_column"`
+	ReferenceColumn   *int   `json:"reference_column"`
+	IDColumn          *int   `json:"id_column"` // bank transaction id, used like an OFX FITID
+	DecimalComma      bool   `json:"decimal_comma"`
+}
+
+func (m CSVMapping) layout() string {
+	format := m.DateFormat
+	if format == "" {
+		format = "YYYY-MM-DD"
+	}
+	return strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02").Replace(format)
+}
+
+func parseCSV(content []byte, mapping CSVMapping) ([]Line, error) {
+	if mapping.AmountColumn == nil && mapping.DebitColumn == nil && mapping.CreditColumn == nil {
+		return nil, errors.New("csv mapping needs an amount column or debit and credit columns")
+	}
+
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if mapping.Delimiter != "" {
+		if mapping.Delimiter == `\t` {
+			mapping.Delimiter = "\t"
+		}
+		reader.Comma = []rune(mapping.Delimiter)[0]
+	}
+
+	layout := mapping.layout()
+
+	var lines []Line
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %v", row, err)
+		}
+		if row == 1 && mapping.HasHeader {
+			continue
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		line, err := csvLine(record, mapping, layout)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %v", row, err)
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+func csvLine(record []string, mapping CSVMapping, layout string) (Line, error) {
+	column := func(index *int) (string, error) {
+		if index == nil {
+			return "", nil
+		}
+		if *index < 0 || *index >= len(record) {
+			return "", fmt.Errorf("column %d does not exist", *index)
+		}
+		return strings.TrimSpace(record[*index]), nil
+	}
+
+	rawDate, err := column(&mapping.DateColumn)
+	if err != nil {
+		return Line{}, err
+	}
+	date, err := time.Parse(layout, rawDate)
+	if err != nil {
+		return Line{}, fmt.Errorf("invalid date %q for format %s", rawDate, mapping.DateFormat)
+	}
+
+	var amountCents int64
+	if mapping.AmountColumn != nil {
+		raw, err := column(mapping.AmountColumn)
+		if err != nil {
+			return Line{}, err
+		}
+		if amountCents, err = parseAmount(raw, mapping.DecimalComma); err != nil {
+			return Line{}, err
+		}
+	} else {
+		// separate columns hold unsigned amounts; the blank one is zero
+		for _, c := range []struct {
+			index *int
+			sign  int64
+		}{{mapping.CreditColumn, 1}, {mapping.DebitColumn, -1}} {
+			raw, err := column(c.index)
+			if err != nil {
+				return Line{}, err
+			}
+			if raw == "" {
+				continue
+			}
+			cents, err := parseAmount(raw, mapping.DecimalComma)
+			if err != nil {
+				return Line{}, err
+			}
+			if cents < 0 {
+				cents = -cents
+			}
+			amountCents += c.sign * cents
+		}
+	}
+
+	description, err := column(mapping.DescriptionColumn)
+	if err != nil {
+		return Line{}, err
+	}
+	reference, err := column(mapping.ReferenceColumn)
+	if err != nil {
+		return Line{}, err
+	}
+	fitid, err := column(mapping.IDColumn)
+	if err != nil {
+		return Line{}, err
+	}
+
+	return Line{
+		Date:        date.Format("2006-01-02"),
+		AmountCents: amountCents,
+		Description: description,
+		Reference:   reference,
+		FITID:       fitid,
+	}, nil
+}
@@ -0,0 +1,80 @@
+package bankstatement
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	ofxTransactionStart = regexp.MustCompile(`(?i)<STMTTRN>`)
+	ofxTransactionEnd   = regexp.MustCompile(`(?i)</STMTTRN>|</BANKTRANLIST>`)
+	ofxElement          = regexp.MustCompile(`<([A-Za-z0-9.]+)>([^<\r\n]*)`)
+)
+
+// parseOFX reads the STMTTRN records of an OFX 1.x (SGML, unclosed tags) or 2.x (XML) file
+func parseOFX(content []byte) ([]Line, error) {
+	text := string(content)
+	if !strings.Contains(strings.ToUpper(text), "<OFX>") {
+		return nil, errors.New("not an OFX file")
+	}
+
+	starts := ofxTransactionStart.FindAllStringIndex(text, -1)
+
+	var lines []Line
+	for i, start := range starts {
+		block := text[start[1]:]
+		if i+1 < len(starts) {
+			block = text[start[1]:starts[i+1][0]]
+		}
+		if end := ofxTransactionEnd.FindStringIndex(block); end != nil {
+			block = block[:end[0]]
+		}
+
+		fields := make(map[string]string)
+		for _, m := range ofxElement.FindAllStringSubmatch(block, -1) {
+			fields[strings.ToUpper(m[1])] = strings.TrimSpace(html.UnescapeString(m[2])) // "A&amp;B" is "A&B"
+		}
+
+		line, err := ofxLine(fields)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %v", i+1, err)
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+func ofxLine(fields map[string]string) (Line, error) {
+	posted := fields["DTPOSTED"]
+	if len(posted) < 8 {
+		return Line{}, fmt.Errorf("invalid DTPOSTED %q", posted)
+	}
+	date := posted[0:4] + "-" + posted[4:6] + "-" + posted[6:8]
+
+	amountCents, err := parseAmount(fields["TRNAMT"], false)
+	if err != nil {
+		return Line{}, err
+	}
+
+	description := fields["NAME"]
+	if memo := fields["MEMO"]; memo != "" && memo != description {
+		description = strings.TrimSpace(description + " " + memo)
+	}
+
+	reference := fields["CHECKNUM"]
+	if reference == "" {
+		reference = fields["REFNUM"]
+	}
+
+	return Line{
+		Date:        date,
+		AmountCents: amountCents,
+		Description: description,
+		Reference:   reference,
+		FITID:       fields["FITID"],
+	}, nil
+}
@@ -0,0 +1,130 @@
+// Package bankstatement parses bank statement exports (OFX/QFX, ISO 20022 CAMT.053 and CSV)
+// into a flat list of lines that can be staged against a bank account.
+package bankstatement
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+)
+
+const (
+	FormatOFX     = "ofx" // also QFX, which is OFX with an Intuit header
+	FormatCAMT053 = "camt053"
+	FormatCSV     = "csv"
+)
+
+// Line is one booked entry of a statement
+type Line struct {
+	Date        string // YYYY-MM-DD
+	AmountCents int64  // positive for money in, negative for money out
+	Description string
+	Reference   string
+	FITID       string // bank-assigned id, empty when the format has none
+	DedupeKey   string // set by Parse, unique per bank account
+}
+
+// Parse reads a statement in the given format. The CSV mapping is only used for FormatCSV.
+func Parse(format string, content []byte, mapping *CSVMapping) ([]Line, error) {
+	var (
+		lines []Line
+		err   error
+	)
+
+	switch strings.ToLower(format) {
+	case FormatOFX, "qfx":
+		lines, err = parseOFX(content)
+	case FormatCAMT053, "camt.053", "camt":
+		lines, err = parseCAMT053(content)
+	case FormatCSV:
+		if mapping == nil {
+			return nil, errors.New("csv import needs a column mapping")
+		}
+		lines, err = parseCSV(content, *mapping)
+	default:
+		return nil, fmt.Errorf("unsupported statement format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("statement has no transactions")
+	}
+
+	assignDedupeKeys(lines)
+	return lines, nil
+}
+
+// assignDedupeKeys keys each line by its FITID, or by a hash of its content when the bank sent
+// none. Identical lines within one file (two equal card payments on the same day) get an
+// occurrence suffix so both are kept, while re-importing the same file still finds them.
+func assignDedupeKeys(lines []Line) {
+	seen := make(map[string]int)
+	for i := range lines {
+		if lines[i].FITID != "" {
+			lines[i].DedupeKey = "fitid:" + lines[i].FITID
+			continue
+		}
+
+		sum := sha256.Sum256([]byte(strings.Join([]string{
+			lines[i].Date,
+			strconv.FormatInt(lines[i].AmountCents, 10),
+			strings.ToLower(strings.Join(strings.Fields(lines[i].Description), " ")),
+			lines[i].Reference,
+		}, "|")))
+		hash := hex.EncodeToString(sum[:])
+
+		seen[hash]++
+		if n := seen[hash]; n > 1 {
+			hash = fmt.Sprintf("%s#%d", hash, n)
+		}
+		lines[i].DedupeKey = "hash:" + hash
+	}
+}
+
+// parseAmount converts a statement amount to signed cents. It accepts a leading minus sign,
+// accounting-style parentheses, currency symbols and, when decimalComma is set, "1.234,56".
+func parseAmount(s string, decimalComma bool) (int64, error) {
+	s = strings.TrimSpace(s)
+	negative := false
+
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = strings.Trim(s, "()")
+	}
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9', r == '.', r == ',', r == '-', r == '+':
+			return r
+		}
+		return -1
+	}, s)
+	if strings.HasSuffix(s, "-") {
+		negative = !negative
+		s = strings.TrimSuffix(s, "-")
+	}
+	if strings.HasPrefix(s, "-") {
+		negative = !negative
+		s = strings.TrimPrefix(s, "-")
+	}
+	s = strings.TrimPrefix(s, "+")
+
+	if decimalComma {
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.ReplaceAll(s, ",", ".")
+	}
+
+	cents, err := money.ParseUSDAmount(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if negative {
+		cents = -cents
+	}
+	return cents, nil
+}
@@ -0,0 +1,207 @@
+package bankstatement
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func intPtr(i int) *int { return &i }
+
+const ofxSGML = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20260903120000[-5:EST]
+<TRNAMT>-42.50
+<FITID>2026090301
+<CHECKNUM>1044
+<NAME>Smith &amp; Sons Plumbing
+<MEMO>Invoice &lt;88&gt;
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20260904
+<TRNAMT>1200.00
+<FITID>2026090402
+<REFNUM>R-7
+<NAME>Rent unit 4B
+<MEMO>Rent unit 4B
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const ofxXML = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST>
+<STMTTRN><TRNTYPE>FEE</TRNTYPE><DTPOSTED>20260930</DTPOSTED><TRNAMT>-3.00</TRNAMT><FITID>F1</FITID><NAME>Fees &#38; charges</NAME></STMTTRN>
+</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>
+`
+
+const camt053 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08">
+<BkToCstmrStmt><Stmt>
+<Ntry>
+	<Amt Ccy="EUR">150.25</Amt><CdtDbtInd>CRDT</CdtDbtInd><Sts><Cd>BOOK</Cd></Sts>
+	<BookgDt><Dt>2026-09-02</Dt></BookgDt><AcctSvcrRef>S-1</AcctSvcrRef>
+	<NtryDtls><TxDtls>
+		<Refs><EndToEndId>E2E-9</EndToEndId></Refs>
+		<RltdPties><Dbtr><Pty><Nm>Jane Doe</Nm></Pty></Dbtr></RltdPties>
+		<RmtInf><Ustrd>Rent September</Ustrd></RmtInf>
+	</TxDtls></NtryDtls>
+</Ntry>
+<Ntry>
+	<Amt Ccy="EUR">20.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts><Cd>PDNG</Cd></Sts>
+	<BookgDt><Dt>2026-09-03</Dt></BookgDt>
+</Ntry>
+<Ntry>
+	<Amt Ccy="EUR">9.99</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts>BOOK</Sts>
+	<BookgDt><DtTm>2026-09-04T10:00:00</DtTm></BookgDt><NtryRef>N-3</NtryRef>
+	<AddtlNtryInf>Card fee</AddtlNtryInf>
+	<NtryDtls><TxDtls><Refs><EndToEndId>NOTPROVIDED</EndToEndId><AcctSvcrRef>S-3</AcctSvcrRef></Refs></TxDtls></NtryDtls>
+</Ntry>
+<Ntry>
+	<Amt Ccy="EUR">5.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><RvslInd>true</RvslInd>
+	<BookgDt><Dt>2026-09-05</Dt></BookgDt><AcctSvcrRef>S-4</AcctSvcrRef><AddtlNtryInf>Fee refund</AddtlNtryInf>
+</Ntry>
+</Stmt></BkToCstmrStmt>
+</Document>
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		content string
+		mapping *CSVMapping
+		want    []Line
+	}{
+		{
+			name:    "ofx sgml with entities",
+			format:  FormatOFX,
+			content: ofxSGML,
+			want: []Line{
+				{Date: "2026-09-03", AmountCents: -4250, Description: "Smith & Sons Plumbing Invoice <88>", Reference: "1044", FITID: "2026090301"},
+				{Date: "2026-09-04", AmountCents: 120000, Description: "Rent unit 4B", Reference: "R-7", FITID: "2026090402"},
+			},
+		},
+		{
+			name:    "ofx xml with a numeric entity",
+			format:  "qfx",
+			content: ofxXML,
+			want: []Line{
+				{Date: "2026-09-30", AmountCents: -300, Description: "Fees & charges", FITID: "F1"},
+			},
+		},
+		{
+			name:    "camt.053 skips pending entries and flips reversals",
+			format:  "camt.053",
+			content: camt053,
+			want: []Line{
+				{Date: "2026-09-02", AmountCents: 15025, Description: "Jane Doe Rent September", Reference: "E2E-9", FITID: "S-1"},
+				{Date: "2026-09-04", AmountCents: -999, Description: "Card fee", Reference: "N-3", FITID: "S-3"},
+				{Date: "2026-09-05", AmountCents: 500, Description: "Fee refund", FITID: "S-4"},
+			},
+		},
+		{
+			name:   "csv with a signed amount column",
+			format: FormatCSV,
+			content: "\xef\xbb\xbfDate,Details,Amount,Ref\n" +
+				"2026-09-01,\"Water, September\",-61.20,W9\n" +
+				"\n" +
+				"2026-09-02,Deposit,\"$1,500.00\",\n",
+			mapping: &CSVMapping{HasHeader: true, DateColumn: 0, AmountColumn: intPtr(2), DescriptionColumn: intPtr(1), ReferenceColumn: intPtr(3)},
+			want: []Line{
+				{Date: "2026-09-01", AmountCents: -6120, Description: "Water, September", Reference: "W9"},
+				{Date: "2026-09-02", AmountCents: 150000, Description: "Deposit"},
+			},
+		},
+		{
+			name:    "csv with debit and credit columns",
+			format:  FormatCSV,
+			content: "03/09/2026;Electricity;80,00;;TX1\n04/09/2026;Transfer in;;1.234,56;TX2\n",
+			mapping: &CSVMapping{Delimiter: ";", DateColumn: 0, DateFormat: "DD/MM/YYYY", DebitColumn: intPtr(2), CreditColumn: intPtr(3), DescriptionColumn: intPtr(1), IDColumn: intPtr(4), DecimalComma: true},
+			want: []Line{
+				{Date: "2026-09-03", AmountCents: -8000, Description: "Electricity", FITID: "TX1"},
+				{Date: "2026-09-04", AmountCents: 123456, Description: "Transfer in", FITID: "TX2"},
+			},
+		},
+		{
+			name:    "csv with accounting negatives and tabs",
+			format:  FormatCSV,
+			content: "09/05/2026\t(12.00)\tBank charge\n",
+			mapping: &CSVMapping{Delimiter: `\t`, DateColumn: 0, DateFormat: "MM/DD/YYYY", AmountColumn: intPtr(1), DescriptionColumn: intPtr(2)},
+			want: []Line{
+				{Date: "2026-09-05", AmountCents: -1200, Description: "Bank charge"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.format, []byte(tt.content), tt.mapping)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			for i := range got {
+				got[i].DedupeKey = "" // covered by TestDedupeKeys
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		content string
+		mapping *CSVMapping
+		wantErr string
+	}{
+		{name: "unknown format", format: "qif", content: "!Type:Bank\n", wantErr: "unsupported statement format"},
+		{name: "ofx without OFX tag", format: FormatOFX, content: "<STMTTRN><TRNAMT>1", wantErr: "not an OFX file"},
+		{name: "ofx bad date", format: FormatOFX, content: "<OFX><STMTTRN><DTPOSTED>2026<TRNAMT>1.00</OFX>", wantErr: "invalid DTPOSTED"},
+		{name: "ofx bad amount", format: FormatOFX, content: "<OFX><STMTTRN><DTPOSTED>20260901<TRNAMT>abc</OFX>", wantErr: "invalid amount"},
+		{name: "ofx without transactions", format: FormatOFX, content: "<OFX></OFX>", wantErr: "no transactions"},
+		{name: "camt not xml", format: FormatCAMT053, content: "date,amount", wantErr: "invalid camt.053 file"},
+		{name: "camt without statement", format: FormatCAMT053, content: "<Document></Document>", wantErr: "not a camt.053 statement"},
+		{name: "csv without mapping", format: FormatCSV, content: "2026-09-01,1.00", wantErr: "needs a column mapping"},
+		{name: "csv without amount column", format: FormatCSV, content: "2026-09-01,1.00", mapping: &CSVMapping{}, wantErr: "needs an amount column"},
+		{name: "csv bad date", format: FormatCSV, content: "01/09/2026,1.00", mapping: &CSVMapping{AmountColumn: intPtr(1)}, wantErr: "row 1: invalid date"},
+		{name: "csv missing column", format: FormatCSV, content: "2026-09-01", mapping: &CSVMapping{AmountColumn: intPtr(1)}, wantErr: "column 1 does not exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.format, []byte(tt.content), tt.mapping)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// equal lines without a FITID are both kept, and a FITID is used as is
+func TestDedupeKeys(t *testing.T) {
+	lines := []Line{
+		{Date: "2026-09-01", AmountCents: -500, Description: "Card  payment"},
+		{Date: "2026-09-01", AmountCents: -500, Description: "card payment"},
+		{Date: "2026-09-01", AmountCents: -500, Description: "Card payment", FITID: "X1"},
+	}
+	assignDedupeKeys(lines)
+
+	if lines[2].DedupeKey != "fitid:X1" {
+		t.Errorf("FITID line key = %q, want fitid:X1", lines[2].DedupeKey)
+	}
+	if lines[1].DedupeKey != lines[0].DedupeKey+"#2" {
+		t.Errorf("second equal line key = %q, want %q", lines[1].DedupeKey, lines[0].DedupeKey+"#2")
+	}
+}
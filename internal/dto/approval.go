@@ -0,0 +1,108 @@
+package dto
+
+import (
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+type ApprovalThresholdPayload struct {
+	DocumentType string  `json:"document_type" validate:"required,oneof=bill check"`
+	MinAmount    float64 `json:"min_amount" validate:"gte=0"` // documents of this amount or more need the role
+	RoleID       int64   `json:"role_id" validate:"required"`
+	Status       string  `json:"status"`
+	BuildingID   int64   `json:"building_id"`
+}
+
+type CreateApprovalThresholdRequest struct {
+	ApprovalThresholdPayload
+}
+
+type UpdateApprovalThresholdRequest struct {
+	ID int64 `json:"id"`
+	ApprovalThresholdPayload
+}
+
+type ApprovalThresholdDto struct {
+	ID           int64  `json:"id"`
+	BuildingID   int64  `json:"building_id"`
+	DocumentType string `json:"document_type"`
+	MinAmount    string `json:"min_amount"`
+	RoleID       int64  `json:"role_id"`
+	RoleName     string `json:"role_name"`
+	Status       string `json:"status"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+// map store.ApprovalThreshold to ApprovalThresholdDto
+func MapApprovalThresholdToDto(t store.ApprovalThreshold) ApprovalThresholdDto {
+	return ApprovalThresholdDto{
+		ID:           t.ID,
+		BuildingID:   t.BuildingID,
+		DocumentType: t.DocumentType,
+		MinAmount:    money.FormatMoneyFromCents(t.MinAmountCents),
+		RoleID:       t.RoleID,
+		RoleName:     t.RoleName,
+		Status:       t.Status,
+		CreatedAt:    t.CreatedAt,
+		UpdatedAt:    t.UpdatedAt,
+	}
+}
+
+// map []store.ApprovalThreshold to []ApprovalThresholdDto
+func MapApprovalThresholdsToDto(thresholds []store.ApprovalThreshold) []ApprovalThresholdDto {
+	dtoThresholds := []ApprovalThresholdDto{}
+	for _, t := range thresholds {
+		dtoThresholds = append(dtoThresholds, MapApprovalThresholdToDto(t))
+	}
+	return dtoThresholds
+}
+
+// ApprovalDecisionRequest approves or rejects a draft or pending bill or check. The approver is
+// the logged-in user.
+type ApprovalDecisionRequest struct {
+	Note *string `json:"note"`
+}
+
+type DocumentApprovalDto struct {
+	ID           int64   `json:"id"`
+	DocumentType string  `json:"document_type"`
+	DocumentID   int64   `json:"document_id"`
+	ThresholdID  *int64  `json:"threshold_id"`
+	Action       string  `json:"action"`
+	UserID       int64   `json:"user_id"`
+	UserName     string  `json:"user_name"`
+	Note         *string `json:"note"`
+	Voided       bool    `json:"voided"` // made on an earlier version of the document
+	CreatedAt    string  `json:"created_at"`
+}
+
+// ApprovalStatusResponse is where a document stands in its approval chain
+type ApprovalStatusResponse struct {
+	DocumentType   string                 `json:"document_type"`
+	DocumentID     int64                  `json:"document_id"`
+	ApprovalStatus string                 `json:"approval_status"`
+	Required       []ApprovalThresholdDto `json:"required"`       // thresholds the amount reaches
+	NextThreshold  *ApprovalThresholdDto  `json:"next_threshold"` // nil when nothing is outstanding
+	History        []DocumentApprovalDto  `json:"history"`
+}
+
+// map []store.DocumentApproval to []DocumentApprovalDto
+func MapDocumentApprovalsToDto(approvals []store.DocumentApproval) []DocumentApprovalDto {
+	dtoApprovals := []DocumentApprovalDto{}
+	for _, a := range approvals {
+		dtoApprovals = append(dtoApprovals, DocumentApprovalDto{
+			ID:           a.ID,
+			DocumentType: a.DocumentType,
+			DocumentID:   a.DocumentID,
+			ThresholdID:  a.ThresholdID,
+			Action:       a.Action,
+			UserID:       a.UserID,
+			UserName:     a.UserName,
+			Note:         a.Note,
+			Voided:       a.Status != "1",
+			CreatedAt:    a.CreatedAt,
+		})
+	}
+	return dtoApprovals
+}
@@ -0,0 +1,116 @@
+package dto
+
+import (
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+type BankRulePayload struct {
+	Name                string   `json:"name" validate:"required"`
+	Priority            *int     `json:"priority"` // lower runs first; defaults to 100
+	BankAccountID       *int64   `json:"bank_account_id"`
+	Direction           string   `json:"direction" validate:"omitempty,oneof=any in out"`
+	DescriptionContains *string  `json:"description_contains"`
+	DescriptionRegex    *string  `json:"description_regex"`
+	MinAmount           *float64 `json:"min_amount"`
+	MaxAmount           *float64 `json:"max_amount"`
+	TargetAccountID     *int64   `json:"target_account_id"` // expense account for withdrawals
+	ItemID              *int64   `json:"item_id"`           // income item for deposits
+	UnitID              *int64   `json:"unit_id"`
+	PeopleID            *int64   `json:"people_id"`
+	Status              *string  `json:"status" validate:"omitempty,oneof=0 1"`
+	BuildingID          int64    `json:"building_id"`
+}
+
+type CreateBankRuleRequest struct {
+	BankRulePayload
+}
+
+type UpdateBankRuleRequest struct {
+	ID int64 `json:"id"`
+	BankRulePayload
+}
+
+type ApplyBankRulesRequest struct {
+	AccountID int64 `json:"account_id" validate:"required"`
+}
+
+type ApplyBankRulesResponse struct {
+	Categorized []BankStatementLineDto `json:"categorized"`
+	Unmatched   int                    `json:"unmatched"`
+}
+
+// PostBankRuleDraftRequest posts the draft a rule built for a line; receipts need a number
+type PostBankRuleDraftRequest struct {
+	ReceiptNo *int `json:"receipt_no"`
+}
+
+// BankRuleDraftResponse shows the check or sales receipt a rule would post for a line
+type BankRuleDraftResponse struct {
+	Line         BankStatementLineDto       `json:"line"`
+	Rule         BankRuleDto                `json:"rule"`
+	DocumentType string                     `json:"document_type"` // check | receipt
+	Check        *CreateCheckRequest        `json:"check,omitempty"`
+	Receipt      *CreateSalesReceiptRequest `json:"receipt,omitempty"`
+	Splits       []SplitDto                 `json:"splits"`
+}
+
+type BankRuleDto struct {
+	ID                  int64   `json:"id"`
+	BuildingID          int64   `json:"building_id"`
+	Name                string  `json:"name"`
+	Priority            int     `json:"priority"`
+	BankAccountID       *int64  `json:"bank_account_id"`
+	Direction           string  `json:"direction"`
+	DescriptionContains *string `json:"description_contains"`
+	DescriptionRegex    *string `json:"description_regex"`
+	MinAmount           *string `json:"min_amount"`
+	MaxAmount           *string `json:"max_amount"`
+	TargetAccountID     *int64  `json:"target_account_id"`
+	ItemID              *int64  `json:"item_id"`
+	UnitID              *int64  `json:"unit_id"`
+	PeopleID            *int64  `json:"people_id"`
+	Status              string  `json:"status"`
+	CreatedAt           string  `json:"created_at"`
+}
+
+// map store.BankRule to BankRuleDto
+func MapBankRuleToDto(r store.BankRule) BankRuleDto {
+	var minAmount, maxAmount *string
+	if r.MinAmountCents != nil {
+		v := money.FormatMoneyFromCents(*r.MinAmountCents)
+		minAmount = &v
+	}
+	if r.MaxAmountCents != nil {
+		v := money.FormatMoneyFromCents(*r.MaxAmountCents)
+		maxAmount = &v
+	}
+
+	return BankRuleDto{
+		ID:                  r.ID,
+		BuildingID:          r.BuildingID,
+		Name:                r.Name,
+		Priority:            r.Priority,
+		BankAccountID:       r.BankAccountID,
+		Direction:           r.Direction,
+		DescriptionContains: r.DescriptionContains,
+		DescriptionRegex:    r.DescriptionRegex,
+		MinAmount:           minAmount,
+		MaxAmount:           maxAmount,
+		TargetAccountID:     r.TargetAccountID,
+		ItemID:              r.ItemID,
+		UnitID:              r.UnitID,
+		PeopleID:            r.PeopleID,
+		Status:              r.Status,
+		CreatedAt:           r.CreatedAt,
+	}
+}
+
+// map []store.BankRule to []BankRuleDto
+func MapBankRulesToDto(rules []store.BankRule) []BankRuleDto {
+	dtoRules := []BankRuleDto{}
+	for _, r := range rules {
+		dtoRules = append(dtoRules, MapBankRuleToDto(r))
+	}
+	return dtoRules
+}
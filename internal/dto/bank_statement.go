@@ -0,0 +1,147 @@
+package dto
+
+import (
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/bankstatement"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+type ImportBankStatementRequest struct {
+	AccountID  int64                     `json:"account_id" validate:"required"`
+	Format     string                    `json:"format" validate:"required,oneof=ofx qfx camt053 csv"`
+	FileName   string                    `json:"file_name"`
+	Content    string                    `json:"content" validate:"required"` // raw file contents
+	CSVMapping *bankstatement.CSVMapping `json:"csv_mapping"`
+	BuildingID int64                     `json:"building_id"`
+}
+
+type AutoMatchBankStatementRequest struct {
+	AccountID int64 `json:"account_id" validate:"required"`
+	MaxDays   *int  `json:"max_days" validate:"omitempty,min=0,max=60"` // defaults to 5
+}
+
+type MatchBankStatementLineRequest struct {
+	SplitID int64 `json:"split_id" validate:"required"`
+}
+
+// CreateCheckFromStatementLineRequest books a withdrawal line as a check against one expense account
+type CreateCheckFromStatementLineRequest struct {
+	ExpenseAccountID int64   `json:"expense_account_id" validate:"required"`
+	PeopleID         *int64  `json:"people_id"`
+	UnitID           *int64  `json:"unit_id"`
+	Memo             *string `json:"memo"`
+	LearnRule        bool    `json:"learn_rule"` // save a bank rule that books similar lines the same way
+}
+
+// CreateReceiptFromStatementLineRequest books a deposit line as a sales receipt for one item
+type CreateReceiptFromStatementLineRequest struct {
+	ReceiptNo   int    `json:"receipt_no" validate:"required"`
+	ItemID      int    `json:"item_id" validate:"required"`
+	PeopleID    *int64 `json:"people_id"`
+	UnitID      *int64 `json:"unit_id"`
+	Description string `json:"description"`
+	LearnRule   bool   `json:"learn_rule"` // save a bank rule that books similar lines the same way
+}
+
+type BankStatementImportDto struct {
+	ID             int64  `json:"id"`
+	AccountID      int64  `json:"account_id"`
+	AccountName    string `json:"account_name"`
+	BuildingID     int64  `json:"building_id"`
+	Format         string `json:"format"`
+	FileName       string `json:"file_name"`
+	LineCount      int    `json:"line_count"`
+	DuplicateCount int    `json:"duplicate_count"`
+	CreatedAt      string `json:"created_at"`
+}
+
+type BankStatementImportResponse struct {
+	Import BankStatementImportDto `json:"import"`
+	Lines  []BankStatementLineDto `json:"lines"` // newly staged lines; duplicates are left out
+}
+
+type BankStatementLineDto struct {
+	ID            int64   `json:"id"`
+	ImportID      int64   `json:"import_id"`
+	AccountID     int64   `json:"account_id"`
+	Date          string  `json:"date"`
+	Amount        string  `json:"amount"` // negative for money out
+	Description   string  `json:"description"`
+	Reference     string  `json:"reference"`
+	FITID         *string `json:"fitid"`
+	Status        string  `json:"status"`
+	SplitID       *int64  `json:"split_id"`
+	TransactionID *int64  `json:"transaction_id"`
+	RuleID        *int64  `json:"rule_id"`
+	RuleName      string  `json:"rule_name"`
+}
+
+type BankMatchCandidateDto struct {
+	SplitID        int64  `json:"split_id"`
+	TransactionID  int64  `json:"transaction_id"`
+	Type           string `json:"type"`
+	Date           string `json:"date"`
+	Reference      string `json:"reference"`
+	Memo           string `json:"memo"`
+	PeopleName     string `json:"people_name"`
+	Amount         string `json:"amount"`
+	DaysApart      int    `json:"days_apart"`
+	ReferenceMatch bool   `json:"reference_match"`
+}
+
+type AutoMatchBankStatementResponse struct {
+	Matched   []BankStatementLineDto `json:"matched"`
+	Unmatched int                    `json:"unmatched"`
+}
+
+// map store.BankStatementImport to BankStatementImportDto
+func MapBankStatementImportToDto(i store.BankStatementImport) BankStatementImportDto {
+	return BankStatementImportDto{
+		ID:             i.ID,
+		AccountID:      i.AccountID,
+		AccountName:    i.AccountName,
+		BuildingID:     i.BuildingID,
+		Format:         i.Format,
+		FileName:       i.FileName,
+		LineCount:      i.LineCount,
+		DuplicateCount: i.DuplicateCount,
+		CreatedAt:      i.CreatedAt,
+	}
+}
+
+// map []store.BankStatementImport to []BankStatementImportDto
+func MapBankStatementImportsToDto(imports []store.BankStatementImport) []BankStatementImportDto {
+	dtoImports := []BankStatementImportDto{}
+	for _, i := range imports {
+		dtoImports = append(dtoImports, MapBankStatementImportToDto(i))
+	}
+	return dtoImports
+}
+
+// map store.BankStatementLine to BankStatementLineDto
+func MapBankStatementLineToDto(l store.BankStatementLine) BankStatementLineDto {
+	return BankStatementLineDto{
+		ID:            l.ID,
+		ImportID:      l.ImportID,
+		AccountID:     l.AccountID,
+		Date:          l.Date,
+		Amount:        money.FormatMoneyFromCents(l.AmountCents),
+		Description:   l.Description,
+		Reference:     l.Reference,
+		FITID:         l.FITID,
+		Status:        l.Status,
+		SplitID:       l.SplitID,
+		TransactionID: l.TransactionID,
+		RuleID:        l.RuleID,
+		RuleName:      l.RuleName,
+	}
+}
+
+// map []store.BankStatementLine to []BankStatementLineDto
+func MapBankStatementLinesToDto(lines []store.BankStatementLine) []BankStatementLineDto {
+	dtoLines := []BankStatementLineDto{}
+	for _, l := range lines {
+		dtoLines = append(dtoLines, MapBankStatementLineToDto(l))
+	}
+	return dtoLines
+}
@@ -0,0 +1,138 @@
+package dto
+
+import (
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+// BillPaymentRunBillInput picks one bill for the run; Amount defaults to the open balance
+type BillPaymentRunBillInput struct {
+	BillID int64    `json:"bill_id" validate:"required"`
+	Amount *float64 `json:"amount" validate:"omitempty,gt=0"`
+}
+
+type CreateBillPaymentRunRequest struct {
+	BankAccountID int64                     `json:"bank_account_id" validate:"required"`
+	Date          string                    `json:"date" validate:"required"`
+	DueOnOrBefore *string                   `json:"due_on_or_before"`
+	PeopleIDs     []int64                   `json:"people_ids"`                                // limit the run to these vendors
+	Bills         []BillPaymentRunBillInput `json:"bills" validate:"omitempty,dive"`           // pay only these bills; all open bills when empty
+	FirstCheckNo  *int                      `json:"first_check_no" validate:"omitempty,min=1"` // defaults to the account's next check number
+	Memo          string                    `json:"memo"`
+	BuildingID    int64                     `json:"building_id"`
+}
+
+type PayableBillDto struct {
+	BillID      int64  `json:"bill_id"`
+	BillNo      string `json:"bill_no"`
+	BillDate    string `json:"bill_date"`
+	DueDate     string `json:"due_date"`
+	Description string `json:"description"`
+	Amount      string `json:"amount"`
+	Balance     string `json:"balance"`
+}
+
+type PayableVendorDto struct {
+	PeopleID   int64            `json:"people_id"`
+	PeopleName string           `json:"people_name"`
+	Bills      []PayableBillDto `json:"bills"`
+	Total      string           `json:"total"`
+}
+
+type PayableBillsResponse struct {
+	DueOnOrBefore *string            `json:"due_on_or_before"`
+	Vendors       []PayableVendorDto `json:"vendors"`
+	Total         string             `json:"total"`
+}
+
+type BillPaymentRunDto struct {
+	ID              int64   `json:"id"`
+	BuildingID      int64   `json:"building_id"`
+	BankAccountID   int64   `json:"bank_account_id"`
+	BankAccountName string  `json:"bank_account_name"`
+	Date            string  `json:"date"`
+	DueOnOrBefore   *string `json:"due_on_or_before"`
+	Memo            string  `json:"memo"`
+	PaymentCount    int     `json:"payment_count"`
+	Amount          string  `json:"amount"`
+	CreatedAt       string  `json:"created_at"`
+}
+
+// BillPaymentRegisterLine is one check written by the run
+type BillPaymentRegisterLine struct {
+	CheckNo       int    `json:"check_no"`
+	TransactionID int64  `json:"transaction_id"`
+	Date          string `json:"date"`
+	PeopleID      int64  `json:"people_id"`
+	PayeeName     string `json:"payee_name"`
+	BillCount     int    `json:"bill_count"`
+	Amount        string `json:"amount"`
+}
+
+type RemittanceLineDto struct {
+	BillID      int64  `json:"bill_id"`
+	BillNo      string `json:"bill_no"`
+	BillDate    string `json:"bill_date"`
+	DueDate     string `json:"due_date"`
+	Description string `json:"description"`
+	BillAmount  string `json:"bill_amount"`
+	AmountPaid  string `json:"amount_paid"`
+	Withholding string `json:"withholding"` // kept back from what was paid on the bill
+}
+
+// PrintableCheckDto is the check face plus the remittance stub listing the bills it pays
+type PrintableCheckDto struct {
+	CheckNo         int                 `json:"check_no"`
+	Date            string              `json:"date"`
+	PayeeName       string              `json:"payee_name"`
+	Amount          string              `json:"amount"`
+	AmountInWords   string              `json:"amount_in_words"`
+	Withholding     string              `json:"withholding"` // total withheld, empty when none
+	Memo            string              `json:"memo"`
+	BankAccountName string              `json:"bank_account_name"`
+	Remittance      []RemittanceLineDto `json:"remittance"`
+}
+
+type BillPaymentRunResponse struct {
+	Run      BillPaymentRunDto         `json:"run"`
+	Register []BillPaymentRegisterLine `json:"register"`
+	Checks   []PrintableCheckDto       `json:"checks"`
+}
+
+// map store.PayableBill to PayableBillDto
+func MapPayableBillToDto(b store.PayableBill) PayableBillDto {
+	return PayableBillDto{
+		BillID:      b.BillID,
+		BillNo:      b.BillNo,
+		BillDate:    b.BillDate,
+		DueDate:     b.DueDate,
+		Description: b.Description,
+		Amount:      money.FormatMoneyFromCents(b.AmountCents),
+		Balance:     money.FormatMoneyFromCents(b.BalanceCents),
+	}
+}
+
+// map store.BillPaymentRun to BillPaymentRunDto
+func MapBillPaymentRunToDto(r store.BillPaymentRun) BillPaymentRunDto {
+	return BillPaymentRunDto{
+		ID:              r.ID,
+		BuildingID:      r.BuildingID,
+		BankAccountID:   r.BankAccountID,
+		BankAccountName: r.BankAccountName,
+		Date:            r.Date,
+		DueOnOrBefore:   r.DueOnOrBefore,
+		Memo:            r.Memo,
+		PaymentCount:    r.PaymentCount,
+		Amount:          money.FormatMoneyFromCents(r.AmountCents),
+		CreatedAt:       r.CreatedAt,
+	}
+}
+
+// map []store.BillPaymentRun to []BillPaymentRunDto
+func MapBillPaymentRunsToDto(runs []store.BillPaymentRun) []BillPaymentRunDto {
+	dtoRuns := []BillPaymentRunDto{}
+	for _, r := range runs {
+		dtoRuns = append(dtoRuns, MapBillPaymentRunToDto(r))
+	}
+	return dtoRuns
+}
@@ -11,6 +11,10 @@ type BillExpenseLineInput struct {
 	PeopleID    *int64  `json:"people_id"`
 	Description *string `json:"description"`
 	Amount      float64 `json:"amount"`
+	POLineID    *int64   `json:"po_line_id"`  // purchase order line this line bills
+	Qty         *float64 `json:"qty"`         // qty billed against the purchase order line, or received of an inventory item
+	ItemID      *int64   `json:"item_id"`     // account_id defaults to the item's expense account, or for inventory its asset account
+	TaxCodeID   *int64   `json:"tax_code_id"` // the amount is net or gross of tax as the code's mode says
 }
 
 type BillPayloadDTO struct {
@@ -18,12 +22,17 @@ type BillPayloadDTO struct {
 	BillDate     string                 `json:"bill_date"`
 	DueDate      string                 `json:"due_date"`
 	APAccountID  int64                  `json:"ap_account_id"`
+	PaymentAccountID *int64             `json:"payment_account_id"` // bank or cash account it is planned to be paid from, for the bills due schedule
 	UnitID       *int64                 `json:"unit_id"`
 	PeopleID     *int64                 `json:"people_id"`
 	BuildingID   int64                  `json:"building_id"`
 	Amount       float64                `json:"amount"`
 	Description  string                 `json:"description"`
 	ExpenseLines []BillExpenseLineInput `json:"expense_lines"`
+	Draft        bool                   `json:"draft"` // save without posting; approve posts it
+	PurchaseOrderID *int64              `json:"purchase_order_id"` // matched line by line through po_line_id
+	Currency     *string                `json:"currency"`      // lines and amount are in this currency; nil for the base currency
+	ExchangeRate *float64               `json:"exchange_rate"` // defaults to the building's rate on the bill date
 }
 
 type CreateBillRequest struct {
@@ -41,9 +50,10 @@ type BillPaymentPayload struct {
 	Date       string  `json:"date"`
 	BillID     int     `json:"bill_id"`
 	AccountID  int     `json:"account_id"` // Asset account (cash/bank)
-	Amount     float64 `json:"amount"`
+	Amount     float64 `json:"amount"` // settled on the bill, before any tax withheld from the vendor
 	Status     int     `json:"status"`
 	BuildingID int64   `json:"building_id"`
+	ExchangeRate *float64 `json:"exchange_rate"` // for a foreign currency bill, whose currency Amount is in; defaults to the building's rate on Date
 }
 
 type CreateBillPaymentRequest struct {
@@ -70,13 +80,18 @@ type BillDto struct {
 	BillDate      string  `json:"bill_date"`
 	DueDate       string  `json:"due_date"`
 	APAccountID   int64   `json:"ap_account_id"`
+	PaymentAccountID *int64 `json:"payment_account_id"`
 	UnitID        *int64  `json:"unit_id"`
 	PeopleID      *int64  `json:"people_id"`
 	UserID        int64   `json:"user_id"`
-	Amount        string `json:"amount"`
+	Amount        string `json:"amount"` // base currency
 	Description   string  `json:"description"`
 	CancelReason  *string `json:"cancel_reason"`
+	Currency      *string `json:"currency"` // nil in base currency
+	ExchangeRate  *string `json:"exchange_rate"`
+	ForeignAmount *string `json:"foreign_amount"`
 	Status        string  `json:"status"` // enum('0','1')
+	ApprovalStatus string `json:"approval_status"`
 	BuildingID    int64   `json:"building_id"`
 	CreatedAt     string  `json:"created_at"`
 	UpdatedAt     string  `json:"updated_at"`
@@ -85,6 +100,7 @@ type BillDto struct {
 // map store.Bill to BillDto
 
 func MapBillToDto(b store.Bill) *BillDto {
+	exchangeRate, foreignAmount := FormatForeignAmounts(b.ExchangeRateScaled, b.ForeignAmountCents)
 	return &BillDto{
 		ID:            b.ID,
 		BillNo:        b.BillNo,
@@ -92,13 +108,18 @@ func MapBillToDto(b store.Bill) *BillDto {
 		BillDate:      b.BillDate,
 		DueDate:       b.DueDate,
 		APAccountID:   b.APAccountID,
+		PaymentAccountID: b.PaymentAccountID,
 		UnitID:        b.UnitID,
 		PeopleID:      b.PeopleID,
 		UserID:        b.UserID,
 		Amount:        money.FormatMoneyFromCents(b.AmountCents),
 		Description:   b.Description,
 		CancelReason:  b.CancelReason,
+		Currency:      b.Currency,
+		ExchangeRate:  exchangeRate,
+		ForeignAmount: foreignAmount,
 		Status:        b.Status,
+		ApprovalStatus: b.ApprovalStatus,
 		BuildingID:    b.BuildingID,
 		CreatedAt:     b.CreatedAt,
 		UpdatedAt:     b.UpdatedAt,
@@ -123,10 +144,22 @@ type BillExpenseLineDto struct {
 	PeopleID    *int64  `json:"people_id"`
 	Description *string `json:"description"`
 	Amount      string `json:"amount"`
+	POLineID    *int64  `json:"po_line_id"`
+	Qty         *string `json:"qty"`
+	ItemID      *int64  `json:"item_id"`
+	TaxCodeID   *int64  `json:"tax_code_id"`
+	Taxable     string  `json:"taxable"`
+	Tax         string  `json:"tax"`
 }
 
 // map store.BillExpenseLine to BillExpenseLineDto
 func MapBillExpenseLineToDto(l store.BillExpenseLine) *BillExpenseLineDto {
+	var qty *string
+	if l.QtyScaled != nil {
+		formatted := money.FormatScaled5(*l.QtyScaled)
+		qty = &formatted
+	}
+
 	return &BillExpenseLineDto{
 		ID:          l.ID,
 		BillID:      l.BillID,
@@ -135,6 +168,12 @@ func MapBillExpenseLineToDto(l store.BillExpenseLine) *BillExpenseLineDto {
 		PeopleID:    l.PeopleID,
 		Description: l.Description,
 		Amount:      money.FormatMoneyFromCents(l.AmountCents),
+		POLineID:    l.POLineID,
+		Qty:         qty,
+		ItemID:      l.ItemID,
+		TaxCodeID:   l.TaxCodeID,
+		Taxable:     money.FormatMoneyFromCents(l.TaxableCents),
+		Tax:         money.FormatMoneyFromCents(l.TaxCents),
 	}
 }
 
@@ -165,16 +204,29 @@ type BillPaymentDto struct {
 	BillID    int64 `json:"bill_id"`
 	UserID    int64 `json:"user_id"`
 	AccountID int64 `json:"account_id"`
+	RunID     *int64 `json:"run_id"`
+	CheckNo   *int   `json:"check_no"`
 
 	Amount string `json:"amount"`
 	Status string  `json:"status"` // enum('0','1')
 
+	WithholdingRate      string `json:"withholding_rate"`
+	Withheld             string `json:"withheld"`
+	WithholdingAccountID *int64 `json:"withholding_account_id"`
+	NetPaid              string `json:"net_paid"` // amount less withheld, what left the bank
+
+	Currency      *string `json:"currency"` // the bill's currency, nil in base currency
+	ExchangeRate  *string `json:"exchange_rate"`
+	ForeignAmount *string `json:"foreign_amount"`
+	FXGainLoss    string  `json:"fx_gain_loss"` // realized, a gain when positive
+
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 }
 
 // map store.BillPayment to BillPaymentDto
 func MapBillPaymentToDto(p store.BillPayment) *BillPaymentDto {
+	exchangeRate, foreignAmount := FormatForeignAmounts(p.ExchangeRateScaled, p.ForeignAmountCents)
 	return &BillPaymentDto{
 		ID: p.ID,
 		TransactionID: p.TransactionID,
@@ -183,8 +235,18 @@ func MapBillPaymentToDto(p store.BillPayment) *BillPaymentDto {
 		BillID: p.BillID,
 		UserID: p.UserID,
 		AccountID: p.AccountID,
+		RunID: p.RunID,
+		CheckNo: p.CheckNo,
 		Amount: money.FormatMoneyFromCents(p.AmountCents),
 		Status: p.Status,
+		WithholdingRate: money.FormatScaled5(p.WithholdingRateScaled),
+		Withheld: money.FormatMoneyFromCents(p.WithholdingCents),
+		WithholdingAccountID: p.WithholdingAccountID,
+		NetPaid: money.FormatMoneyFromCents(p.AmountCents - p.FXGainLossCents - p.WithholdingCents),
+		Currency: p.Currency,
+		ExchangeRate: exchangeRate,
+		ForeignAmount: foreignAmount,
+		FXGainLoss: money.FormatMoneyFromCents(p.FXGainLossCents),
 		CreatedAt: p.CreatedAt,
 		UpdatedAt: p.UpdatedAt,
 	}
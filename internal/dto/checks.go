@@ -19,6 +19,7 @@ type CheckPayloadDTO struct {
 	Memo             *string            `json:"memo"`
 	TotalAmount      float64            `json:"total_amount"`
 	ExpenseLines     []ExpenseLineInput `json:"expense_lines"`
+	Draft            bool               `json:"draft"` // save without posting; approve posts it
 }
 
 type CreateCheckRequest struct {
@@ -39,6 +40,7 @@ type CheckDto struct {
 	BuildingID       int64   `json:"building_id"`
 	Memo             *string `json:"memo"`
 	TotalAmount      string `json:"total_amount"`
+	ApprovalStatus   string  `json:"approval_status"`
 	CreatedAt        string  `json:"created_at"`
 }
 
@@ -53,6 +55,7 @@ func MapCheckToDto(check store.Check) *CheckDto {
 		BuildingID:       check.BuildingID,
 		Memo:             check.Memo,
 		TotalAmount:      money.FormatMoneyFromCents(check.AmountCents),
+		ApprovalStatus:   check.ApprovalStatus,
 		CreatedAt:        check.CreatedAt,
 	}
 }
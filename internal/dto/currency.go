@@ -0,0 +1,116 @@
+package dto
+
+import (
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+type ExchangeRatePayload struct {
+	Currency   string  `json:"currency" validate:"required,len=3"`
+	RateDate   string  `json:"rate_date" validate:"required"`
+	Rate       float64 `json:"rate" validate:"gt=0"` // base currency per one unit of the currency
+	BuildingID int64   `json:"building_id"`
+}
+
+type ExchangeRateDto struct {
+	ID         int64  `json:"id"`
+	BuildingID int64  `json:"building_id"`
+	Currency   string `json:"currency"`
+	RateDate   string `json:"rate_date"`
+	Rate       string `json:"rate"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+// map store.ExchangeRate to ExchangeRateDto
+func MapExchangeRateToDto(r store.ExchangeRate) ExchangeRateDto {
+	return ExchangeRateDto{
+		ID:         r.ID,
+		BuildingID: r.BuildingID,
+		Currency:   r.Currency,
+		RateDate:   r.RateDate,
+		Rate:       money.FormatExchangeRate(r.RateScaled),
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}
+}
+
+// map []store.ExchangeRate to []ExchangeRateDto
+func MapExchangeRatesToDto(rates []store.ExchangeRate) []ExchangeRateDto {
+	dtoRates := []ExchangeRateDto{}
+	for _, r := range rates {
+		dtoRates = append(dtoRates, MapExchangeRateToDto(r))
+	}
+	return dtoRates
+}
+
+type CreateFXRevaluationRequest struct {
+	Date       string `json:"date" validate:"required"` // period end; reversed the day after
+	BuildingID int64  `json:"building_id"`
+}
+
+type FXRevaluationLineDto struct {
+	SourceType string `json:"source_type"`
+	SourceID   int64  `json:"source_id"`
+	AccountID  int64  `json:"account_id"`
+	Currency   string `json:"currency"`
+	Foreign    string `json:"foreign"`
+	Rate       string `json:"rate"`
+	Carrying   string `json:"carrying"`
+	Revalued   string `json:"revalued"`
+	Adjustment string `json:"adjustment"`
+}
+
+type FXRevaluationDto struct {
+	ID                    int64                  `json:"id"`
+	BuildingID            int64                  `json:"building_id"`
+	RevaluationDate       string                 `json:"revaluation_date"`
+	TransactionID         int64                  `json:"transaction_id"`
+	ReversalTransactionID int64                  `json:"reversal_transaction_id"`
+	Adjustment            string                 `json:"adjustment"` // net unrealized gain (loss when negative)
+	CreatedAt             string                 `json:"created_at"`
+	Lines                 []FXRevaluationLineDto `json:"lines,omitempty"`
+}
+
+// map store.FXRevaluation and its lines to FXRevaluationDto
+func MapFXRevaluationToDto(r store.FXRevaluation, lines []store.FXRevaluationLine) FXRevaluationDto {
+	revaluation := FXRevaluationDto{
+		ID:                    r.ID,
+		BuildingID:            r.BuildingID,
+		RevaluationDate:       r.RevaluationDate,
+		TransactionID:         r.TransactionID,
+		ReversalTransactionID: r.ReversalTransactionID,
+		CreatedAt:             r.CreatedAt,
+	}
+
+	var gainCents int64
+	for _, l := range lines {
+		// a debit adjustment on an asset or a liability is a gain either way
+		gainCents += l.AdjustmentCents
+		revaluation.Lines = append(revaluation.Lines, FXRevaluationLineDto{
+			SourceType: l.SourceType,
+			SourceID:   l.SourceID,
+			AccountID:  l.AccountID,
+			Currency:   l.Currency,
+			Foreign:    money.FormatMoneyFromCents(l.ForeignCents),
+			Rate:       money.FormatExchangeRate(l.RateScaled),
+			Carrying:   money.FormatMoneyFromCents(l.CarryingCents),
+			Revalued:   money.FormatMoneyFromCents(l.RevaluedCents),
+			Adjustment: money.FormatMoneyFromCents(l.AdjustmentCents),
+		})
+	}
+	revaluation.Adjustment = money.FormatMoneyFromCents(gainCents)
+
+	return revaluation
+}
+
+// FormatForeignAmounts formats a document's exchange rate and its amount in its own currency;
+// both are nil for base currency documents
+func FormatForeignAmounts(rateScaled, foreignCents *int64) (*string, *string) {
+	if rateScaled == nil || foreignCents == nil {
+		return nil, nil
+	}
+	rate := money.FormatExchangeRate(*rateScaled)
+	amount := money.FormatMoneyFromCents(*foreignCents)
+	return &rate, &amount
+}
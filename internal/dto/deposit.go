@@ -0,0 +1,114 @@
+package dto
+
+import (
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+type CreateDepositRequest struct {
+	Reference     string  `json:"reference" validate:"required"`
+	Date          string  `json:"date" validate:"required"`
+	BankAccountID int64   `json:"bank_account_id" validate:"required"`
+	SplitIDs      []int64 `json:"split_ids" validate:"required,min=1"` // undeposited items to bank
+	Memo          string  `json:"memo"`
+	BuildingID    int64   `json:"building_id"`
+}
+
+type UndepositedItemDto struct {
+	SplitID       int64  `json:"split_id"`
+	TransactionID int64  `json:"transaction_id"`
+	Type          string `json:"type"`
+	Date          string `json:"date"`
+	Reference     string `json:"reference"`
+	PeopleID      *int64 `json:"people_id"`
+	PeopleName    string `json:"people_name"`
+	Amount        string `json:"amount"`
+}
+
+type UndepositedFundsResponse struct {
+	ClearingAccountID int64                `json:"clearing_account_id"`
+	Items             []UndepositedItemDto `json:"items"`
+	Total             string               `json:"total"`
+}
+
+type DepositLineDto struct {
+	SplitID             int64  `json:"split_id"`
+	SourceTransactionID int64  `json:"source_transaction_id"`
+	Type                string `json:"type"`
+	Date                string `json:"date"`
+	Reference           string `json:"reference"`
+	PeopleName          string `json:"people_name"`
+	Amount              string `json:"amount"`
+}
+
+type DepositDto struct {
+	ID                int64            `json:"id"`
+	TransactionID     int64            `json:"transaction_id"`
+	Reference         string           `json:"reference"`
+	Date              string           `json:"date"`
+	BankAccountID     int64            `json:"bank_account_id"`
+	ClearingAccountID int64            `json:"clearing_account_id"`
+	Amount            string           `json:"amount"`
+	Memo              string           `json:"memo"`
+	BuildingID        int64            `json:"building_id"`
+	Status            string           `json:"status"`
+	CreatedAt         string           `json:"created_at"`
+	Lines             []DepositLineDto `json:"lines,omitempty"`
+}
+
+// map store.UndepositedItem to UndepositedItemDto
+func MapUndepositedItemToDto(i store.UndepositedItem) UndepositedItemDto {
+	return UndepositedItemDto{
+		SplitID:       i.SplitID,
+		TransactionID: i.TransactionID,
+		Type:          i.Type,
+		Date:          i.Date,
+		Reference:     i.Reference,
+		PeopleID:      i.PeopleID,
+		PeopleName:    i.PeopleName,
+		Amount:        money.FormatMoneyFromCents(i.AmountCents),
+	}
+}
+
+// map store.Deposit to DepositDto
+func MapDepositToDto(d store.Deposit) DepositDto {
+	return DepositDto{
+		ID:                d.ID,
+		TransactionID:     d.TransactionID,
+		Reference:         d.Reference,
+		Date:              d.Date,
+		BankAccountID:     d.BankAccountID,
+		ClearingAccountID: d.ClearingAccountID,
+		Amount:            money.FormatMoneyFromCents(d.AmountCents),
+		Memo:              d.Memo,
+		BuildingID:        d.BuildingID,
+		Status:            d.Status,
+		CreatedAt:         d.CreatedAt,
+	}
+}
+
+// map []store.Deposit to []DepositDto
+func MapDepositsToDto(deposits []store.Deposit) []DepositDto {
+	dtoDeposits := []DepositDto{}
+	for _, d := range deposits {
+		dtoDeposits = append(dtoDeposits, MapDepositToDto(d))
+	}
+	return dtoDeposits
+}
+
+// map []store.DepositLine to []DepositLineDto
+func MapDepositLinesToDto(lines []store.DepositLine) []DepositLineDto {
+	dtoLines := []DepositLineDto{}
+	for _, l := range lines {
+		dtoLines = append(dtoLines, DepositLineDto{
+			SplitID:             l.SplitID,
+			SourceTransactionID: l.SourceTransactionID,
+			Type:                l.Type,
+			Date:                l.Date,
+			Reference:           l.Reference,
+			PeopleName:          l.PeopleName,
+			Amount:              money.FormatMoneyFromCents(l.AmountCents),
+		})
+	}
+	return dtoLines
+}
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+type InventoryMovementDto struct {
+	ID            int64  `json:"id"`
+	ItemID        int64  `json:"item_id"`
+	ItemName      string `json:"item_name"`
+	TransactionID int64  `json:"transaction_id"`
+	SourceType    string `json:"source_type"` // bill | invoice | receipt | adjustment
+	Date          string `json:"date"`
+	Qty           string `json:"qty"`  // negative when issued
+	Cost          string `json:"cost"` // negative when issued
+	OnHandAfter   string `json:"on_hand_after"`
+	ValueAfter    string `json:"value_after"`
+	AvgCostAfter  string `json:"avg_cost_after"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// map []store.InventoryMovement to []InventoryMovementDto
+func MapInventoryMovementsToDto(movements []store.InventoryMovement) []InventoryMovementDto {
+	dtoMovements := []InventoryMovementDto{}
+	for _, m := range movements {
+		dtoMovements = append(dtoMovements, InventoryMovementDto{
+			ID:            m.ID,
+			ItemID:        m.ItemID,
+			ItemName:      m.ItemName,
+			TransactionID: m.TransactionID,
+			SourceType:    m.SourceType,
+			Date:          m.MovementDate,
+			Qty:           money.FormatScaled5(m.QtyScaled),
+			Cost:          money.FormatMoneyFromCents(m.CostCents),
+			OnHandAfter:   money.FormatScaled5(m.OnHandAfterScaled),
+			ValueAfter:    money.FormatMoneyFromCents(m.ValueAfterCents),
+			AvgCostAfter:  money.FormatScaled5(money.AverageCostScaled(m.OnHandAfterScaled, m.ValueAfterCents)),
+			CreatedAt:     m.CreatedAt,
+		})
+	}
+	return dtoMovements
+}
@@ -0,0 +1,119 @@
+package dto
+
+import (
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+// InventoryAdjustmentLineInput sets an item to what the count found. Leave new_value out to
+// move the qty at average cost, or new_qty out to revalue the stock on hand.
+type InventoryAdjustmentLineInput struct {
+	ItemID   int64    `json:"item_id" validate:"required"`
+	NewQty   *float64 `json:"new_qty" validate:"omitempty,gte=0"`
+	NewValue *float64 `json:"new_value" validate:"omitempty,gte=0"`
+	Memo     *string  `json:"memo"`
+}
+
+type InventoryAdjustmentPayload struct {
+	Reference  string                         `json:"reference" validate:"required"`
+	Date       string                         `json:"date" validate:"required"`
+	AccountID  int64                          `json:"account_id" validate:"required"` // takes the other side of the value change, e.g. inventory shrinkage
+	UnitID     *int64                         `json:"unit_id"`
+	Memo       string                         `json:"memo"`
+	Lines      []InventoryAdjustmentLineInput `json:"lines" validate:"required,min=1,dive"`
+	BuildingID int64                          `json:"building_id"`
+}
+
+type CreateInventoryAdjustmentRequest struct {
+	InventoryAdjustmentPayload
+}
+
+type UpdateInventoryAdjustmentRequest struct {
+	ID int64 `json:"id"`
+	InventoryAdjustmentPayload
+}
+
+type InventoryAdjustmentDto struct {
+	ID            int64  `json:"id"`
+	TransactionID int64  `json:"transaction_id"`
+	Reference     string `json:"reference"`
+	Date          string `json:"date"`
+	AccountID     int64  `json:"account_id"`
+	AccountName   string `json:"account_name"`
+	UnitID        *int64 `json:"unit_id"`
+	ValueChange   string `json:"value_change"`
+	Memo          string `json:"memo"`
+	BuildingID    int64  `json:"building_id"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+type InventoryAdjustmentLineDto struct {
+	ID          int64   `json:"id"`
+	ItemID      int64   `json:"item_id"`
+	ItemName    string  `json:"item_name"`
+	NewQty      *string `json:"new_qty"`
+	NewValue    *string `json:"new_value"`
+	QtyChange   string  `json:"qty_change"`
+	ValueChange string  `json:"value_change"`
+	Memo        *string `json:"memo"`
+}
+
+type InventoryAdjustmentDetailsResponse struct {
+	Adjustment  InventoryAdjustmentDto       `json:"adjustment"`
+	Lines       []InventoryAdjustmentLineDto `json:"lines"`
+	Splits      []SplitDto                   `json:"splits"`
+	Transaction *store.Transaction           `json:"transaction"`
+}
+
+// map store.InventoryAdjustment to InventoryAdjustmentDto
+func MapInventoryAdjustmentToDto(ia store.InventoryAdjustment) InventoryAdjustmentDto {
+	return InventoryAdjustmentDto{
+		ID:            ia.ID,
+		TransactionID: ia.TransactionID,
+		Reference:     ia.Reference,
+		Date:          ia.AdjustmentDate,
+		AccountID:     ia.AccountID,
+		AccountName:   ia.AccountName,
+		UnitID:        ia.UnitID,
+		ValueChange:   money.FormatMoneyFromCents(ia.ValueChangeCents),
+		Memo:          ia.Memo,
+		BuildingID:    ia.BuildingID,
+		CreatedAt:     ia.CreatedAt,
+		UpdatedAt:     ia.UpdatedAt,
+	}
+}
+
+// map []store.InventoryAdjustment to []InventoryAdjustmentDto
+func MapInventoryAdjustmentsToDto(adjustments []store.InventoryAdjustment) []InventoryAdjustmentDto {
+	dtoAdjustments := []InventoryAdjustmentDto{}
+	for _, ia := range adjustments {
+		dtoAdjustments = append(dtoAdjustments, MapInventoryAdjustmentToDto(ia))
+	}
+	return dtoAdjustments
+}
+
+// map []store.InventoryAdjustmentLine to []InventoryAdjustmentLineDto
+func MapInventoryAdjustmentLinesToDto(lines []store.InventoryAdjustmentLine) []InventoryAdjustmentLineDto {
+	dtoLines := []InventoryAdjustmentLineDto{}
+	for _, l := range lines {
+		line := InventoryAdjustmentLineDto{
+			ID:          l.ID,
+			ItemID:      l.ItemID,
+			ItemName:    l.ItemName,
+			QtyChange:   money.FormatScaled5(l.QtyChangeScaled),
+			ValueChange: money.FormatMoneyFromCents(l.ValueChangeCents),
+			Memo:        l.Memo,
+		}
+		if l.NewQtyScaled != nil {
+			newQty := money.FormatScaled5(*l.NewQtyScaled)
+			line.NewQty = &newQty
+		}
+		if l.NewValueCents != nil {
+			newValue := money.FormatMoneyFromCents(*l.NewValueCents)
+			line.NewValue = &newValue
+		}
+		dtoLines = append(dtoLines, line)
+	}
+	return dtoLines
+}
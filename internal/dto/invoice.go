@@ -96,6 +96,7 @@ type InvoiceItemInputDTO struct {
 	Total         float64  `json:"total"` // Use manually edited total if provided
 	PreviousValue *float64 `json:"previous_value"`
 	CurrentValue  *float64 `json:"current_value"`
+	TaxCodeID     *int64   `json:"tax_code_id"` // the line total is net or gross of tax as the code's mode says
 }
 
 type InvoicePayloadDTO struct {
@@ -110,6 +111,9 @@ type InvoicePayloadDTO struct {
 	Status      *int                  `json:"status"` // Use pointer to distinguish between not provided (nil) and explicitly set to 0
 	BuildingID  int64                 `json:"building_id"`
 	Items       []InvoiceItemInputDTO `json:"items"`
+
+	Currency     *string  `json:"currency"`      // lines and amount are in this currency; nil for the base currency
+	ExchangeRate *float64 `json:"exchange_rate"` // defaults to the building's rate on the sales date
 }
 
 type CreateInvoiceRequestDTO struct {
@@ -175,10 +179,14 @@ type InvoiceDto struct {
 	PeopleID *int64 `json:"people_id"`
 
 	UserID       int64   `json:"user_id"`
-	Amount       string  `json:"amount"`
+	Amount       string  `json:"amount"` // base currency
 	Description  string  `json:"description"`
 	CancelReason *string `json:"cancel_reason"`
 
+	Currency      *string `json:"currency"` // nil in base currency
+	ExchangeRate  *string `json:"exchange_rate"`
+	ForeignAmount *string `json:"foreign_amount"`
+
 	Status     *int  `json:"status"` // enum('0','1')
 	BuildingID int64 `json:"building_id"`
 
@@ -209,6 +217,7 @@ type InvoiceItemDto struct {
 
 // map invoice to dto
 func MapInvoiceToDto(i store.Invoice) InvoiceDto {
+	exchangeRate, foreignAmount := FormatForeignAmounts(i.ExchangeRateScaled, i.ForeignAmountCents)
 	return InvoiceDto{
 		ID:            i.ID,
 		InvoiceNo:     i.InvoiceNo,
@@ -222,6 +231,9 @@ func MapInvoiceToDto(i store.Invoice) InvoiceDto {
 		Amount:        money.FormatMoneyFromCents(i.AmountCents),
 		Description:   i.Description,
 		CancelReason:  i.CancelReason,
+		Currency:      i.Currency,
+		ExchangeRate:  exchangeRate,
+		ForeignAmount: foreignAmount,
 		Status:        i.Status,
 		BuildingID:    i.BuildingID,
 		CreatedAt:     i.CreatedAt,
@@ -0,0 +1,132 @@
+package dto
+
+import (
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+type LateFeePolicyPayload struct {
+	Name            string  `json:"name" validate:"required"`
+	FeeType         string  `json:"fee_type" validate:"required,oneof=flat percentage"`
+	FlatAmount      float64 `json:"flat_amount" validate:"gte=0"`
+	Percentage      float64 `json:"percentage" validate:"gte=0,lte=100"` // percent of the open balance, e.g. 5 = 5%
+	GraceDays       int     `json:"grace_days" validate:"gte=0"`
+	MaxApplications int     `json:"max_applications" validate:"gte=1"`
+	ItemID          int64   `json:"item_id" validate:"required"`
+	Status          string  `json:"status"`
+	BuildingID      int64   `json:"building_id"`
+}
+
+type CreateLateFeePolicyRequest struct {
+	LateFeePolicyPayload
+}
+
+type UpdateLateFeePolicyRequest struct {
+	ID int64 `json:"id"`
+	LateFeePolicyPayload
+}
+
+type LateFeePolicyDto struct {
+	ID              int64  `json:"id"`
+	BuildingID      int64  `json:"building_id"`
+	Name            string `json:"name"`
+	FeeType         string `json:"fee_type"`
+	FlatAmount      string `json:"flat_amount"`
+	Percentage      string `json:"percentage"`
+	GraceDays       int    `json:"grace_days"`
+	MaxApplications int    `json:"max_applications"`
+	ItemID          int64  `json:"item_id"`
+	Status          string `json:"status"`
+	CreatedAt       string `json:"created_at"`
+	UpdatedAt       string `json:"updated_at"`
+}
+
+// map store.LateFeePolicy to LateFeePolicyDto
+func MapLateFeePolicyToDto(p store.LateFeePolicy) LateFeePolicyDto {
+	return LateFeePolicyDto{
+		ID:              p.ID,
+		BuildingID:      p.BuildingID,
+		Name:            p.Name,
+		FeeType:         p.FeeType,
+		FlatAmount:      money.FormatMoneyFromCents(p.FlatAmountCents),
+		Percentage:      money.FormatScaled5(p.PercentageScaled),
+		GraceDays:       p.GraceDays,
+		MaxApplications: p.MaxApplications,
+		ItemID:          p.ItemID,
+		Status:          p.Status,
+		CreatedAt:       p.CreatedAt,
+		UpdatedAt:       p.UpdatedAt,
+	}
+}
+
+// map []store.LateFeePolicy to []LateFeePolicyDto
+func MapLateFeePoliciesToDto(policies []store.LateFeePolicy) []LateFeePolicyDto {
+	dtoPolicies := []LateFeePolicyDto{}
+	for _, p := range policies {
+		dtoPolicies = append(dtoPolicies, MapLateFeePolicyToDto(p))
+	}
+	return dtoPolicies
+}
+
+type LateFeeRunRequest struct {
+	PolicyID int64  `json:"policy_id" validate:"required"`
+	AsOfDate string `json:"as_of_date" validate:"required"`
+}
+
+type LateFeeAssessmentLine struct {
+	InvoiceID     int64  `json:"invoice_id"`
+	InvoiceNo     string `json:"invoice_no"`
+	ARAccountID   int    `json:"ar_account_id"`
+	PeopleID      int64  `json:"people_id"`
+	PeopleName    string `json:"people_name"`
+	UnitID        int64  `json:"unit_id"`
+	DueDate       string `json:"due_date"`
+	DaysOverdue   int    `json:"days_overdue"`
+	Balance       string `json:"balance"`
+	Fee           string `json:"fee"`
+	ApplicationNo int    `json:"application_no"`
+	FeeInvoiceNo  string `json:"fee_invoice_no"`
+	FeeInvoiceID  *int64 `json:"fee_invoice_id,omitempty"` // set once the fee is posted
+	Skipped       string `json:"skipped,omitempty"`        // reason the invoice is not charged
+	FeeCents      int64  `json:"-"`
+}
+
+type LateFeeRunResponse struct {
+	PolicyID    int64                   `json:"policy_id"`
+	AsOfDate    string                  `json:"as_of_date"`
+	Period      string                  `json:"period"`
+	Posted      bool                    `json:"posted"` // false for a preview
+	Assessments []LateFeeAssessmentLine `json:"assessments"`
+	TotalFees   string                  `json:"total_fees"`
+}
+
+type LateFeeAssessmentDto struct {
+	ID            int64  `json:"id"`
+	PolicyID      int64  `json:"policy_id"`
+	InvoiceID     int64  `json:"invoice_id"`
+	FeeInvoiceID  int64  `json:"fee_invoice_id"`
+	Period        string `json:"period"`
+	ApplicationNo int    `json:"application_no"`
+	Amount        string `json:"amount"`
+	AssessedDate  string `json:"assessed_date"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// map []store.LateFeeAssessment to []LateFeeAssessmentDto
+func MapLateFeeAssessmentsToDto(assessments []store.LateFeeAssessment) []LateFeeAssessmentDto {
+	dtoAssessments := []LateFeeAssessmentDto{}
+	for _, a := range assessments {
+		dtoAssessments = append(dtoAssessments, LateFeeAssessmentDto{
+			ID:            a.ID,
+			PolicyID:      a.PolicyID,
+			InvoiceID:     a.InvoiceID,
+			FeeInvoiceID:  a.FeeInvoiceID,
+			Period:        a.Period,
+			ApplicationNo: a.ApplicationNo,
+			Amount:        money.FormatMoneyFromCents(a.AmountCents),
+			AssessedDate:  a.AssessedDate,
+			CreatedAt:     a.CreatedAt,
+		})
+	}
+	return dtoAssessments
+}
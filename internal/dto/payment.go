@@ -13,6 +13,7 @@ type InvoicePaymentPayload struct{
 	Amount     float64 `json:"amount"`
 	Status     int    `json:"status"`
 	BuildingID int64     `json:"building_id"`
+	ExchangeRate *float64 `json:"exchange_rate"` // for a foreign currency invoice, whose currency Amount is in; defaults to the building's rate on Date
 }
 
 type CreateInvoicePaymentRequest struct {
@@ -37,6 +38,11 @@ type InvoicePaymentDto struct {
 
 	Amount string `json:"amount"`
 	Status string  `json:"status"` // enum('0','1')
+
+	Currency      *string `json:"currency"` // the invoice's currency, nil in base currency
+	ExchangeRate  *string `json:"exchange_rate"`
+	ForeignAmount *string `json:"foreign_amount"`
+	FXGainLoss    string  `json:"fx_gain_loss"` // realized, a gain when positive
 }
 
 
@@ -52,6 +58,7 @@ type InvoicePaymentResponse struct {
 
 
 func MapInvoicePaymentToDto(p store.InvoicePayment) InvoicePaymentDto {
+	exchangeRate, foreignAmount := FormatForeignAmounts(p.ExchangeRateScaled, p.ForeignAmountCents)
 	return InvoicePaymentDto{
 		ID: p.ID,
 		TransactionID: p.TransactionID,
@@ -62,6 +69,10 @@ func MapInvoicePaymentToDto(p store.InvoicePayment) InvoicePaymentDto {
 		AccountID: p.AccountID,
 		Amount: money.FormatMoneyFromCents(p.AmountCents),
 		Status: p.Status,
+		Currency: p.Currency,
+		ExchangeRate: exchangeRate,
+		ForeignAmount: foreignAmount,
+		FXGainLoss: money.FormatMoneyFromCents(p.FXGainLossCents),
 	}
 }
 
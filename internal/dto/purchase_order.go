@@ -0,0 +1,166 @@
+package dto
+
+import (
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+type PurchaseOrderLineInput struct {
+	ItemID      *int64  `json:"item_id"`
+	AccountID   int64   `json:"account_id"` // defaults to the item's expense, or for inventory its asset, account
+	UnitID      *int64  `json:"unit_id"`
+	Description *string `json:"description"`
+	Qty         float64 `json:"qty" validate:"gt=0"`
+	Rate        float64 `json:"rate" validate:"gte=0"`
+}
+
+type PurchaseOrderPayload struct {
+	PONo         string                   `json:"po_no" validate:"required"`
+	PODate       string                   `json:"po_date" validate:"required"`
+	ExpectedDate *string                  `json:"expected_date"`
+	PeopleID     int64                    `json:"people_id" validate:"required"` // vendor
+	UnitID       *int64                   `json:"unit_id"`
+	Description  string                   `json:"description"`
+	Tolerance    float64                  `json:"tolerance" validate:"gte=0,lte=100"` // percent over order a bill may reach unflagged, e.g. 5 = 5%
+	Lines        []PurchaseOrderLineInput `json:"lines" validate:"required,min=1,dive"`
+	BuildingID   int64                    `json:"building_id"`
+}
+
+type CreatePurchaseOrderRequest struct {
+	PurchaseOrderPayload
+}
+
+type UpdatePurchaseOrderRequest struct {
+	ID int64 `json:"id"`
+	PurchaseOrderPayload
+}
+
+type PurchaseOrderDto struct {
+	ID              int64   `json:"id"`
+	PONo            string  `json:"po_no"`
+	PODate          string  `json:"po_date"`
+	ExpectedDate    *string `json:"expected_date"`
+	PeopleID        int64   `json:"people_id"`
+	PeopleName      string  `json:"people_name"`
+	UnitID          *int64  `json:"unit_id"`
+	Description     string  `json:"description"`
+	Amount          string  `json:"amount"`
+	BilledAmount    string  `json:"billed_amount"`
+	RemainingAmount string  `json:"remaining_amount"`
+	Tolerance       string  `json:"tolerance"`
+	Status          string  `json:"status"` // open | partially_billed | closed
+	ManuallyClosed  bool    `json:"manually_closed"`
+	UserID          int64   `json:"user_id"`
+	BuildingID      int64   `json:"building_id"`
+	CreatedAt       string  `json:"created_at"`
+	UpdatedAt       string  `json:"updated_at"`
+}
+
+type PurchaseOrderLineDto struct {
+	ID              int64   `json:"id"`
+	PurchaseOrderID int64   `json:"purchase_order_id"`
+	ItemID          *int64  `json:"item_id"`
+	ItemName        *string `json:"item_name"`
+	AccountID       int64   `json:"account_id"`
+	UnitID          *int64  `json:"unit_id"`
+	Description     *string `json:"description"`
+	Qty             string  `json:"qty"`
+	Rate            string  `json:"rate"`
+	Amount          string  `json:"amount"`
+	BilledQty       string  `json:"billed_qty"`
+	BilledAmount    string  `json:"billed_amount"`
+	RemainingQty    string  `json:"remaining_qty"`
+	RemainingAmount string  `json:"remaining_amount"`
+}
+
+type PurchaseOrderDetailsResponse struct {
+	PurchaseOrder PurchaseOrderDto       `json:"purchase_order"`
+	Lines         []PurchaseOrderLineDto `json:"lines"`
+}
+
+// map store.PurchaseOrder to PurchaseOrderDto
+func MapPurchaseOrderToDto(po store.PurchaseOrder) PurchaseOrderDto {
+	return PurchaseOrderDto{
+		ID:              po.ID,
+		PONo:            po.PONo,
+		PODate:          po.PODate,
+		ExpectedDate:    po.ExpectedDate,
+		PeopleID:        po.PeopleID,
+		PeopleName:      po.PeopleName,
+		UnitID:          po.UnitID,
+		Description:     po.Description,
+		Amount:          money.FormatMoneyFromCents(po.AmountCents),
+		BilledAmount:    money.FormatMoneyFromCents(po.BilledCents),
+		RemainingAmount: money.FormatMoneyFromCents(max(po.AmountCents-po.BilledCents, 0)),
+		Tolerance:       money.FormatScaled5(po.ToleranceScaled),
+		Status:          po.Status,
+		ManuallyClosed:  po.ManuallyClosed == "1",
+		UserID:          po.UserID,
+		BuildingID:      po.BuildingID,
+		CreatedAt:       po.CreatedAt,
+		UpdatedAt:       po.UpdatedAt,
+	}
+}
+
+// map []store.PurchaseOrder to []PurchaseOrderDto
+func MapPurchaseOrdersToDto(orders []store.PurchaseOrder) []PurchaseOrderDto {
+	dtoOrders := []PurchaseOrderDto{}
+	for _, po := range orders {
+		dtoOrders = append(dtoOrders, MapPurchaseOrderToDto(po))
+	}
+	return dtoOrders
+}
+
+// map []store.PurchaseOrderLine to []PurchaseOrderLineDto
+func MapPurchaseOrderLinesToDto(lines []store.PurchaseOrderLine) []PurchaseOrderLineDto {
+	dtoLines := []PurchaseOrderLineDto{}
+	for _, l := range lines {
+		dtoLines = append(dtoLines, PurchaseOrderLineDto{
+			ID:              l.ID,
+			PurchaseOrderID: l.PurchaseOrderID,
+			ItemID:          l.ItemID,
+			ItemName:        l.ItemName,
+			AccountID:       l.AccountID,
+			UnitID:          l.UnitID,
+			Description:     l.Description,
+			Qty:             money.FormatScaled5(l.QtyScaled),
+			Rate:            money.FormatScaled5(l.RateScaled),
+			Amount:          money.FormatMoneyFromCents(l.AmountCents),
+			BilledQty:       money.FormatScaled5(l.BilledQtyScaled),
+			BilledAmount:    money.FormatMoneyFromCents(l.BilledCents),
+			RemainingQty:    money.FormatScaled5(max(l.QtyScaled-l.BilledQtyScaled, 0)),
+			RemainingAmount: money.FormatMoneyFromCents(max(l.AmountCents-l.BilledCents, 0)),
+		})
+	}
+	return dtoLines
+}
+
+// PurchaseOrderMatchLine compares one bill line with the purchase order line it bills
+type PurchaseOrderMatchLine struct {
+	Line             int      `json:"line"` // position of the bill line, from 1
+	POLineID         *int64   `json:"po_line_id"`
+	Description      *string  `json:"description"`
+	OrderedQty       string   `json:"ordered_qty"`
+	PreviouslyBilled string   `json:"previously_billed_qty"`
+	Qty              string   `json:"qty"`
+	OrderedRate      string   `json:"ordered_rate"`
+	Rate             string   `json:"rate"` // the bill's amount over its qty
+	OrderedAmount    string   `json:"ordered_amount"`
+	PreviousAmount   string   `json:"previously_billed_amount"`
+	Amount           string   `json:"amount"`
+	Warnings         []string `json:"warnings"`
+}
+
+// PurchaseOrderMatchResponse is a bill checked against its purchase order. Bills outside the
+// tolerance are saved pending approval instead of being posted.
+type PurchaseOrderMatchResponse struct {
+	PurchaseOrderID int64                    `json:"purchase_order_id"`
+	PONo            string                   `json:"po_no"`
+	Tolerance       string                   `json:"tolerance"`
+	OrderedAmount   string                   `json:"ordered_amount"`
+	PreviousAmount  string                   `json:"previously_billed_amount"`
+	Amount          string                   `json:"amount"`
+	Lines           []PurchaseOrderMatchLine `json:"lines"`
+	Warnings        []string                 `json:"warnings"`
+	WithinTolerance bool                     `json:"within_tolerance"`
+}
@@ -0,0 +1,110 @@
+package dto
+
+import (
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+type ReceivePaymentAllocationPayload struct {
+	InvoiceID int64   `json:"invoice_id" validate:"required"`
+	Amount    float64 `json:"amount" validate:"gt=0"`
+}
+
+type ReceivePaymentPayload struct {
+	Reference string  `json:"reference" validate:"required"`
+	Date      string  `json:"date" validate:"required"`
+	PeopleID  int64   `json:"people_id" validate:"required"`
+	AccountID int64   `json:"account_id" validate:"required"` // Asset account (cash/bank) the payment is deposited to
+	Amount    float64 `json:"amount" validate:"gt=0"`
+	// Allocations are applied as given; when empty the amount is applied to open invoices oldest due date first
+	Allocations []ReceivePaymentAllocationPayload `json:"allocations" validate:"dive"`
+	// LiabilityAccountID and UnitID receive any unapplied remainder as a customer credit;
+	// LiabilityAccountID defaults to the building's customer deposits account
+	LiabilityAccountID *int64 `json:"liability_account_id"`
+	UnitID             *int64 `json:"unit_id"`
+	Memo               string `json:"memo"`
+	BuildingID         int64  `json:"building_id"`
+}
+
+type CreateReceivePaymentRequest struct {
+	ReceivePaymentPayload
+}
+
+type ReceivePaymentAllocationDto struct {
+	InvoiceID        int64  `json:"invoice_id"`
+	InvoiceNo        string `json:"invoice_no"`
+	InvoicePaymentID *int64 `json:"invoice_payment_id,omitempty"` // set once the payment is posted
+	DueDate          string `json:"due_date,omitempty"`
+	Balance          string `json:"balance,omitempty"` // open balance before this payment
+	Amount           string `json:"amount"`
+}
+
+type ReceivePaymentPreviewResponse struct {
+	PeopleID    int64                         `json:"people_id"`
+	Amount      string                        `json:"amount"`
+	Applied     string                        `json:"applied"`
+	Unapplied   string                        `json:"unapplied"` // becomes a customer credit
+	Allocations []ReceivePaymentAllocationDto `json:"allocations"`
+}
+
+type ReceivedPaymentDto struct {
+	ID            int64                         `json:"id"`
+	TransactionID int64                         `json:"transaction_id"`
+	Reference     string                        `json:"reference"`
+	Date          string                        `json:"date"`
+	PeopleID      int64                         `json:"people_id"`
+	PeopleName    string                        `json:"people_name"`
+	AccountID     int64                         `json:"account_id"`
+	Amount        string                        `json:"amount"`
+	Unapplied     string                        `json:"unapplied"`
+	CreditMemoID  *int64                        `json:"credit_memo_id"`
+	Memo          string                        `json:"memo"`
+	BuildingID    int64                         `json:"building_id"`
+	Status        string                        `json:"status"`
+	CreatedAt     string                        `json:"created_at"`
+	Allocations   []ReceivePaymentAllocationDto `json:"allocations,omitempty"`
+}
+
+// map store.ReceivedPayment to ReceivedPaymentDto
+func MapReceivedPaymentToDto(p store.ReceivedPayment) ReceivedPaymentDto {
+	return ReceivedPaymentDto{
+		ID:            p.ID,
+		TransactionID: p.TransactionID,
+		Reference:     p.Reference,
+		Date:          p.Date,
+		PeopleID:      p.PeopleID,
+		PeopleName:    p.PeopleName,
+		AccountID:     p.AccountID,
+		Amount:        money.FormatMoneyFromCents(p.AmountCents),
+		Unapplied:     money.FormatMoneyFromCents(p.UnappliedCents),
+		CreditMemoID:  p.CreditMemoID,
+		Memo:          p.Memo,
+		BuildingID:    p.BuildingID,
+		Status:        p.Status,
+		CreatedAt:     p.CreatedAt,
+	}
+}
+
+// map []store.ReceivedPayment to []ReceivedPaymentDto
+func MapReceivedPaymentsToDto(payments []store.ReceivedPayment) []ReceivedPaymentDto {
+	dtoPayments := []ReceivedPaymentDto{}
+	for _, p := range payments {
+		dtoPayments = append(dtoPayments, MapReceivedPaymentToDto(p))
+	}
+	return dtoPayments
+}
+
+// map []store.ReceivedPaymentAllocation to []ReceivePaymentAllocationDto
+func MapReceivedPaymentAllocationsToDto(allocations []store.ReceivedPaymentAllocation) []ReceivePaymentAllocationDto {
+	dtoAllocations := []ReceivePaymentAllocationDto{}
+	for _, a := range allocations {
+		invoicePaymentID := a.InvoicePaymentID
+		dtoAllocations = append(dtoAllocations, ReceivePaymentAllocationDto{
+			InvoiceID:        a.InvoiceID,
+			InvoiceNo:        a.InvoiceNo,
+			InvoicePaymentID: &invoicePaymentID,
+			Amount:           money.FormatMoneyFromCents(a.AmountCents),
+		})
+	}
+	return dtoAllocations
+}
@@ -0,0 +1,110 @@
+package dto
+
+import (
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+type CreateReconciliationRequest struct {
+	AccountID     int64   `json:"account_id" validate:"required"`
+	StatementDate string  `json:"statement_date" validate:"required"`
+	EndingBalance float64 `json:"ending_balance"`
+	BuildingID    int64   `json:"building_id"`
+}
+
+type UpdateReconciliationRequest struct {
+	ID            int64   `json:"id"`
+	StatementDate string  `json:"statement_date" validate:"required"`
+	EndingBalance float64 `json:"ending_balance"`
+}
+
+type ClearReconciliationItemsRequest struct {
+	SplitIDs []int64 `json:"split_ids" validate:"required,min=1"`
+	Cleared  bool    `json:"cleared"`
+}
+
+type ReconciliationItemDto struct {
+	SplitID       int64  `json:"split_id"`
+	TransactionID int64  `json:"transaction_id"`
+	Type          string `json:"type"`
+	Date          string `json:"date"`
+	Reference     string `json:"reference"`
+	PeopleName    string `json:"people_name"`
+	Deposit       string `json:"deposit"`    // debit to the bank account
+	Withdrawal    string `json:"withdrawal"` // credit to the bank account
+	Cleared       bool   `json:"cleared"`
+}
+
+type ReconciliationDto struct {
+	ID               int64   `json:"id"`
+	AccountID        int64   `json:"account_id"`
+	AccountName      string  `json:"account_name"`
+	BuildingID       int64   `json:"building_id"`
+	StatementDate    string  `json:"statement_date"`
+	BeginningBalance string  `json:"beginning_balance"`
+	EndingBalance    string  `json:"ending_balance"`
+	Status           string  `json:"status"`
+	FinishedAt       *string `json:"finished_at"`
+	CreatedAt        string  `json:"created_at"`
+}
+
+type ReconciliationDetailsResponse struct {
+	Reconciliation     ReconciliationDto       `json:"reconciliation"`
+	Items              []ReconciliationItemDto `json:"items"`
+	ClearedDeposits    string                  `json:"cleared_deposits"`
+	ClearedWithdrawals string                  `json:"cleared_withdrawals"`
+	ClearedBalance     string                  `json:"cleared_balance"` // beginning balance plus cleared items
+	Difference         string                  `json:"difference"`      // ending balance less cleared balance; must be 0 to finish
+}
+
+type ReconciliationReportResponse struct {
+	Reconciliation       ReconciliationDto       `json:"reconciliation"`
+	Cleared              []ReconciliationItemDto `json:"cleared"`
+	Uncleared            []ReconciliationItemDto `json:"uncleared"`
+	ClearedBalance       string                  `json:"cleared_balance"`
+	UnclearedDeposits    string                  `json:"uncleared_deposits"`
+	UnclearedWithdrawals string                  `json:"uncleared_withdrawals"`
+	AdjustedBalance      string                  `json:"adjusted_balance"` // statement ending balance plus uncleared items
+	BookBalance          string                  `json:"book_balance"`     // ledger balance at the statement date
+	Difference           string                  `json:"difference"`       // book balance less adjusted balance
+}
+
+// map store.Reconciliation to ReconciliationDto
+func MapReconciliationToDto(r store.Reconciliation) ReconciliationDto {
+	return ReconciliationDto{
+		ID:               r.ID,
+		AccountID:        r.AccountID,
+		AccountName:      r.AccountName,
+		BuildingID:       r.BuildingID,
+		StatementDate:    r.StatementDate,
+		BeginningBalance: money.FormatMoneyFromCents(r.BeginningBalanceCents),
+		EndingBalance:    money.FormatMoneyFromCents(r.EndingBalanceCents),
+		Status:           r.Status,
+		FinishedAt:       r.FinishedAt,
+		CreatedAt:        r.CreatedAt,
+	}
+}
+
+// map []store.Reconciliation to []ReconciliationDto
+func MapReconciliationsToDto(reconciliations []store.Reconciliation) []ReconciliationDto {
+	dtoReconciliations := []ReconciliationDto{}
+	for _, r := range reconciliations {
+		dtoReconciliations = append(dtoReconciliations, MapReconciliationToDto(r))
+	}
+	return dtoReconciliations
+}
+
+// map store.ReconciliationItem to ReconciliationItemDto
+func MapReconciliationItemToDto(i store.ReconciliationItem) ReconciliationItemDto {
+	return ReconciliationItemDto{
+		SplitID:       i.SplitID,
+		TransactionID: i.TransactionID,
+		Type:          i.Type,
+		Date:          i.Date,
+		Reference:     i.Reference,
+		PeopleName:    i.PeopleName,
+		Deposit:       money.FormatMoneyFromCents(i.DebitCents),
+		Withdrawal:    money.FormatMoneyFromCents(i.CreditCents),
+		Cleared:       i.Cleared,
+	}
+}
@@ -0,0 +1,185 @@
+package dto
+
+import (
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+type BillTemplatePayload struct {
+	Name          string                 `json:"name" validate:"required"`
+	PeopleID      int64                  `json:"people_id" validate:"required"`
+	APAccountID   int64                  `json:"ap_account_id" validate:"required"`
+	UnitID        *int64                 `json:"unit_id"`
+	Description   string                 `json:"description"`
+	Frequency     string                 `json:"frequency" validate:"required,oneof=monthly quarterly yearly"`
+	IntervalCount int                    `json:"interval_count" validate:"gte=0"` // defaults to 1
+	DayOfMonth    int                    `json:"day_of_month" validate:"required,min=1,max=31"`
+	DueDays       int                    `json:"due_days" validate:"gte=0"`
+	StartDate     string                 `json:"start_date" validate:"required"`
+	EndDate       *string                `json:"end_date"`
+	AsDraft       bool                   `json:"as_draft"` // hold generated bills for approval instead of posting them
+	Status        string                 `json:"status"`
+	ExpenseLines  []BillExpenseLineInput `json:"expense_lines" validate:"required,min=1"`
+	BuildingID    int64                  `json:"building_id"`
+}
+
+type CreateBillTemplateRequest struct {
+	BillTemplatePayload
+}
+
+type UpdateBillTemplateRequest struct {
+	ID int64 `json:"id"`
+	BillTemplatePayload
+}
+
+type BillTemplateLineDto struct {
+	ID          int64   `json:"id"`
+	AccountID   int64   `json:"account_id"`
+	UnitID      *int64  `json:"unit_id"`
+	PeopleID    *int64  `json:"people_id"`
+	Description *string `json:"description"`
+	Amount      string  `json:"amount"`
+}
+
+type BillTemplateDto struct {
+	ID            int64                 `json:"id"`
+	BuildingID    int64                 `json:"building_id"`
+	Name          string                `json:"name"`
+	PeopleID      int64                 `json:"people_id"`
+	PeopleName    string                `json:"people_name"`
+	APAccountID   int64                 `json:"ap_account_id"`
+	UnitID        *int64                `json:"unit_id"`
+	Description   string                `json:"description"`
+	Frequency     string                `json:"frequency"`
+	IntervalCount int                   `json:"interval_count"`
+	DayOfMonth    int                   `json:"day_of_month"`
+	DueDays       int                   `json:"due_days"`
+	StartDate     string                `json:"start_date"`
+	EndDate       *string               `json:"end_date"`
+	AsDraft       bool                  `json:"as_draft"`
+	Status        string                `json:"status"`
+	LastPeriod    *string               `json:"last_period"`
+	NextRunDate   *string               `json:"next_run_date"` // nil once the schedule has ended
+	Amount        string                `json:"amount"`
+	ExpenseLines  []BillTemplateLineDto `json:"expense_lines"`
+	CreatedAt     string                `json:"created_at"`
+	UpdatedAt     string                `json:"updated_at"`
+}
+
+// map store.BillTemplate and its lines to BillTemplateDto
+func MapBillTemplateToDto(t store.BillTemplate, lines []store.BillTemplateLine, nextRunDate *string) BillTemplateDto {
+	dtoLines := []BillTemplateLineDto{}
+	total := int64(0)
+	for _, l := range lines {
+		dtoLines = append(dtoLines, BillTemplateLineDto{
+			ID:          l.ID,
+			AccountID:   l.AccountID,
+			UnitID:      l.UnitID,
+			PeopleID:    l.PeopleID,
+			Description: l.Description,
+			Amount:      money.FormatMoneyFromCents(l.AmountCents),
+		})
+		total += l.AmountCents
+	}
+
+	return BillTemplateDto{
+		ID:            t.ID,
+		BuildingID:    t.BuildingID,
+		Name:          t.Name,
+		PeopleID:      t.PeopleID,
+		PeopleName:    t.PeopleName,
+		APAccountID:   t.APAccountID,
+		UnitID:        t.UnitID,
+		Description:   t.Description,
+		Frequency:     t.Frequency,
+		IntervalCount: t.IntervalCount,
+		DayOfMonth:    t.DayOfMonth,
+		DueDays:       t.DueDays,
+		StartDate:     t.StartDate,
+		EndDate:       t.EndDate,
+		AsDraft:       t.AsDraft == "1",
+		Status:        t.Status,
+		LastPeriod:    t.LastPeriod,
+		NextRunDate:   nextRunDate,
+		Amount:        money.FormatMoneyFromCents(total),
+		ExpenseLines:  dtoLines,
+		CreatedAt:     t.CreatedAt,
+		UpdatedAt:     t.UpdatedAt,
+	}
+}
+
+type RecurringBillRunRequest struct {
+	AsOfDate   string `json:"as_of_date" validate:"required"`
+	TemplateID *int64 `json:"template_id"` // all active templates when empty
+}
+
+// RecurringBillLine is one bill a template is due to generate
+type RecurringBillLine struct {
+	TemplateID   int64  `json:"template_id"`
+	TemplateName string `json:"template_name"`
+	PeopleID     int64  `json:"people_id"`
+	PeopleName   string `json:"people_name"`
+	Period       string `json:"period"`
+	BillNo       string `json:"bill_no"`
+	BillDate     string `json:"bill_date"`
+	DueDate      string `json:"due_date"`
+	Amount       string `json:"amount"`
+	Draft        bool   `json:"draft"`
+	RunID        *int64 `json:"run_id,omitempty"`  // set once the run is recorded
+	BillID       *int64 `json:"bill_id,omitempty"` // set once the bill is posted
+	Error        string `json:"error,omitempty"`   // the template is retried on the next run
+	AmountCents  int64  `json:"-"`
+}
+
+type RecurringBillRunResponse struct {
+	AsOfDate string              `json:"as_of_date"`
+	Posted   bool                `json:"posted"` // false for a preview
+	Bills    []RecurringBillLine `json:"bills"`
+	Total    string              `json:"total"`
+}
+
+type BillTemplateRunDto struct {
+	ID           int64   `json:"id"`
+	TemplateID   int64   `json:"template_id"`
+	TemplateName string  `json:"template_name"`
+	PeopleName   string  `json:"people_name"`
+	Period       string  `json:"period"`
+	BillNo       string  `json:"bill_no"`
+	BillDate     string  `json:"bill_date"`
+	DueDate      string  `json:"due_date"`
+	Amount       string  `json:"amount"`
+	BillID       *int64  `json:"bill_id"`
+	Status       string  `json:"status"`
+	ReviewedBy   *int64  `json:"reviewed_by"`
+	ReviewedAt   *string `json:"reviewed_at"`
+	CreatedAt    string  `json:"created_at"`
+}
+
+// map store.BillTemplateRun to BillTemplateRunDto
+func MapBillTemplateRunToDto(r store.BillTemplateRun) BillTemplateRunDto {
+	return BillTemplateRunDto{
+		ID:           r.ID,
+		TemplateID:   r.TemplateID,
+		TemplateName: r.TemplateName,
+		PeopleName:   r.PeopleName,
+		Period:       r.Period,
+		BillNo:       r.BillNo,
+		BillDate:     r.BillDate,
+		DueDate:      r.DueDate,
+		Amount:       money.FormatMoneyFromCents(r.AmountCents),
+		BillID:       r.BillID,
+		Status:       r.Status,
+		ReviewedBy:   r.ReviewedBy,
+		ReviewedAt:   r.ReviewedAt,
+		CreatedAt:    r.CreatedAt,
+	}
+}
+
+// map []store.BillTemplateRun to []BillTemplateRunDto
+func MapBillTemplateRunsToDto(runs []store.BillTemplateRun) []BillTemplateRunDto {
+	dtoRuns := []BillTemplateRunDto{}
+	for _, r := range runs {
+		dtoRuns = append(dtoRuns, MapBillTemplateRunToDto(r))
+	}
+	return dtoRuns
+}
@@ -29,6 +29,7 @@ type BalanceSheetSection struct {
 type BalanceSheetResponse struct {
 	BuildingID                int                 `json:"building_id"`
 	AsOfDate                  string              `json:"as_of_date"`
+	Basis                     string              `json:"basis"` // accrual or cash
 	Assets                    BalanceSheetSection `json:"assets"`
 	Liabilities               BalanceSheetSection `json:"liabilities"`
 	Equity                    BalanceSheetSection `json:"equity"`
@@ -316,3 +317,348 @@ type ProfitAndLossByUnitResponse struct {
 	GrandTotalExpenses      string         `json:"grand_total_expenses"`
 	GrandTotalNetProfitLoss string         `json:"grand_total_net_profit_loss"`
 }
+
+// Customer Statement DTOs
+type CustomerStatementLine struct {
+	Date        string `json:"date"`
+	Type        string `json:"type"` // invoice, payment, credit memo, discount
+	Reference   string `json:"reference"`
+	Description string `json:"description"`
+	InvoiceNo   string `json:"invoice_no"`
+	Charge      string `json:"charge"`
+	Credit      string `json:"credit"`
+	Balance     string `json:"balance"` // Running balance after this line
+}
+
+type StatementAging struct {
+	Current    string `json:"current"`
+	Days1To30  string `json:"days_1_30"`
+	Days31To60 string `json:"days_31_60"`
+	Days61To90 string `json:"days_61_90"`
+	Over90     string `json:"over_90"`
+	Total      string `json:"total"`
+}
+
+type CustomerStatement struct {
+	BuildingID          int                     `json:"building_id"`
+	PeopleID            int                     `json:"people_id"`
+	PeopleName          string                  `json:"people_name"`
+	StartDate           string                  `json:"start_date"`
+	EndDate             string                  `json:"end_date"`
+	OpeningBalance      string                  `json:"opening_balance"`
+	Lines               []CustomerStatementLine `json:"lines"`
+	TotalCharges        string                  `json:"total_charges"`
+	TotalCredits        string                  `json:"total_credits"`
+	ClosingBalance      string                  `json:"closing_balance"`
+	ClosingBalanceCents int64                   `json:"-"`
+	Aging               StatementAging          `json:"aging"`
+}
+
+type CustomerStatementsResponse struct {
+	BuildingID   int                 `json:"building_id"`
+	StartDate    string              `json:"start_date"`
+	EndDate      string              `json:"end_date"`
+	Statements   []CustomerStatement `json:"statements"`
+	TotalBalance string              `json:"total_balance"`
+}
+
+// Aging DTOs
+type AgingDocument struct {
+	ID          int    `json:"id"`
+	Number      string `json:"number"`
+	Date        string `json:"date"`
+	DueDate     string `json:"due_date"`
+	DaysPastDue int    `json:"days_past_due"`
+	UnitName    string `json:"unit_name,omitempty"`
+	Amount      string `json:"amount"`
+	Balance     string `json:"balance"`
+	Bucket      string `json:"bucket"`
+}
+
+type AgingRow struct {
+	ID        int             `json:"id"`
+	Name      string          `json:"name"`
+	Buckets   []string        `json:"buckets"` // same order as the response buckets
+	Total     string          `json:"total"`
+	Documents []AgingDocument `json:"documents,omitempty"`
+}
+
+type ARAgingResponse struct {
+	BuildingID int        `json:"building_id"`
+	AsOfDate   string     `json:"as_of_date"`
+	Buckets    []string   `json:"buckets"` // e.g. Current, 1-30, 31-60, 61-90, 90+
+	Tenants    []AgingRow `json:"tenants"`
+	Units      []AgingRow `json:"units"`
+	Totals     []string   `json:"totals"`
+	GrandTotal string     `json:"grand_total"`
+}
+
+type APAgingResponse struct {
+	BuildingID int        `json:"building_id"`
+	AsOfDate   string     `json:"as_of_date"`
+	Buckets    []string   `json:"buckets"`
+	Vendors    []AgingRow `json:"vendors"`
+	Totals     []string   `json:"totals"`
+	GrandTotal string     `json:"grand_total"`
+}
+
+// Bills Due DTOs
+type BillDueLine struct {
+	BillID       int    `json:"bill_id"`
+	BillNo       string `json:"bill_no"`
+	BillDate     string `json:"bill_date"`
+	DueDate      string `json:"due_date"`
+	DaysUntilDue int    `json:"days_until_due"` // negative when overdue
+	Overdue      bool   `json:"overdue"`
+	Amount       string `json:"amount"`
+	Balance      string `json:"balance"`
+}
+
+type BillsDueGroup struct {
+	PaymentAccountID   *int          `json:"payment_account_id"`
+	PaymentAccountName string        `json:"payment_account_name"`
+	Bills              []BillDueLine `json:"bills"`
+	Total              string        `json:"total"`
+}
+
+type BillsDueVendor struct {
+	PeopleID int             `json:"people_id"`
+	Name     string          `json:"name"`
+	Groups   []BillsDueGroup `json:"groups"`
+	Total    string          `json:"total"`
+}
+
+type BillsDueAccountTotal struct {
+	PaymentAccountID   *int   `json:"payment_account_id"`
+	PaymentAccountName string `json:"payment_account_name"`
+	Total              string `json:"total"`
+}
+
+type BillsDueResponse struct {
+	BuildingID      int                    `json:"building_id"`
+	AsOfDate        string                 `json:"as_of_date"`
+	ThroughDate     string                 `json:"through_date"`
+	Days            int                    `json:"days"`
+	Vendors         []BillsDueVendor       `json:"vendors"`
+	PaymentAccounts []BillsDueAccountTotal `json:"payment_accounts"` // cash needed per account
+	Total           string                 `json:"total"`
+}
+
+type InventoryValuationLine struct {
+	ItemID           int     `json:"item_id"`
+	ItemName         string  `json:"item_name"`
+	AssetAccountID   *int    `json:"asset_account_id"`
+	AssetAccountName *string `json:"asset_account_name"`
+	Qty              string  `json:"qty"`
+	AvgCost          string  `json:"avg_cost"`
+	Value            string  `json:"value"`
+}
+
+type InventoryValuationResponse struct {
+	BuildingID int                      `json:"building_id"`
+	AsOfDate   string                   `json:"as_of_date"`
+	Items      []InventoryValuationLine `json:"items"`
+	TotalValue string                   `json:"total_value"`
+}
+
+type InventoryMovementHistoryLine struct {
+	TransactionID int    `json:"transaction_id"`
+	Type          string `json:"type"` // bill | invoice | receipt | adjustment
+	Date          string `json:"date"`
+	Number        string `json:"number"`
+	Memo          string `json:"memo"`
+	Qty           string `json:"qty"`  // negative when issued
+	Cost          string `json:"cost"` // negative when issued
+	OnHand        string `json:"on_hand"`
+	Value         string `json:"value"`
+}
+
+type InventoryMovementHistoryItem struct {
+	ItemID       int                            `json:"item_id"`
+	ItemName     string                         `json:"item_name"`
+	OpeningQty   string                         `json:"opening_qty"`
+	OpeningValue string                         `json:"opening_value"`
+	Movements    []InventoryMovementHistoryLine `json:"movements"`
+	QtyIn        string                         `json:"qty_in"`
+	QtyOut       string                         `json:"qty_out"`
+	ClosingQty   string                         `json:"closing_qty"`
+	ClosingValue string                         `json:"closing_value"`
+}
+
+type InventoryMovementHistoryResponse struct {
+	BuildingID int                            `json:"building_id"`
+	StartDate  string                         `json:"start_date"`
+	EndDate    string                         `json:"end_date"`
+	Items      []InventoryMovementHistoryItem `json:"items"`
+}
+
+type TaxSummaryLine struct {
+	TaxCodeID     int    `json:"tax_code_id"`
+	Code          string `json:"code"`
+	Name          string `json:"name"`
+	Rate          string `json:"rate"`
+	OutputTaxable string `json:"output_taxable"` // sales net of tax
+	OutputTax     string `json:"output_tax"`
+	InputTaxable  string `json:"input_taxable"` // purchases net of tax
+	InputTax      string `json:"input_tax"`
+	Net           string `json:"net"` // output less input tax
+}
+
+type TaxSummaryResponse struct {
+	BuildingID int              `json:"building_id"`
+	StartDate  string           `json:"start_date"`
+	EndDate    string           `json:"end_date"`
+	Codes      []TaxSummaryLine `json:"codes"`
+	OutputTax  string           `json:"output_tax"`
+	InputTax   string           `json:"input_tax"`
+	NetPayable string           `json:"net_payable"` // negative when input tax is reclaimable
+}
+
+type WithholdingRegisterLine struct {
+	BillPaymentID int64  `json:"bill_payment_id"`
+	Date          string `json:"date"`
+	Reference     string `json:"reference"`
+	BillNo        string `json:"bill_no"`
+	Rate          string `json:"rate"`
+	Gross         string `json:"gross"` // settled on the bill
+	Withheld      string `json:"withheld"`
+	Net           string `json:"net"` // paid from the bank
+}
+
+type WithholdingRegisterVendor struct {
+	PeopleID   int                       `json:"people_id"`
+	PeopleName string                    `json:"people_name"`
+	Lines      []WithholdingRegisterLine `json:"lines"`
+	Gross      string                    `json:"gross"`
+	Withheld   string                    `json:"withheld"`
+	Net        string                    `json:"net"`
+}
+
+type WithholdingRegisterResponse struct {
+	BuildingID int                         `json:"building_id"`
+	StartDate  string                      `json:"start_date"`
+	EndDate    string                      `json:"end_date"`
+	Vendors    []WithholdingRegisterVendor `json:"vendors"`
+	Withheld   string                      `json:"withheld"`
+}
+
+// WithholdingCertificate is the statement given to a vendor of the tax withheld from their payments
+type WithholdingCertificate struct {
+	BuildingID   int    `json:"building_id"`
+	BuildingName string `json:"building_name"`
+	StartDate    string `json:"start_date"`
+	EndDate      string `json:"end_date"`
+	WithholdingRegisterVendor
+}
+
+// Comparative statement DTOs: the P&L, balance sheet and trial balance in several columns
+
+// ComparativeColumn is one column of a comparative statement; balance sheet and trial balance
+// columns are balances as of EndDate and have no StartDate
+type ComparativeColumn struct {
+	Label     string `json:"label"` // e.g. "Jan 2026", "Q1 2026", "2026"
+	StartDate string `json:"start_date,omitempty"`
+	EndDate   string `json:"end_date"`
+}
+
+// ComparativeAmounts holds one amount per column. Comparing with the same period last year adds
+// the change from the second column to the first, which is nil as a percentage when the second is 0.
+type ComparativeAmounts struct {
+	Amounts       []string `json:"amounts"`
+	Change        *string  `json:"change,omitempty"`
+	ChangePercent *string  `json:"change_percent,omitempty"`
+}
+
+type ComparativeAccountRow struct {
+	AccountID     int    `json:"account_id"`
+	AccountNumber int    `json:"account_number"`
+	AccountName   string `json:"account_name"`
+	AccountType   string `json:"account_type"`
+	ComparativeAmounts
+}
+
+type ComparativeSection struct {
+	SectionName string                  `json:"section_name"`
+	Accounts    []ComparativeAccountRow `json:"accounts"`
+	Total       ComparativeAmounts      `json:"total"`
+}
+
+type ComparativeProfitAndLossResponse struct {
+	BuildingID    int                 `json:"building_id"`
+	StartDate     string              `json:"start_date"`
+	EndDate       string              `json:"end_date"`
+	Basis         string              `json:"basis"`
+	ColumnMode    string              `json:"column_mode"`
+	Columns       []ComparativeColumn `json:"columns"`
+	Income        ComparativeSection  `json:"income"`
+	Expenses      ComparativeSection  `json:"expenses"`
+	NetProfitLoss ComparativeAmounts  `json:"net_profit_loss"`
+}
+
+type ComparativeBalanceSheetResponse struct {
+	BuildingID                int                 `json:"building_id"`
+	AsOfDate                  string              `json:"as_of_date"`
+	Basis                     string              `json:"basis"`
+	ColumnMode                string              `json:"column_mode"`
+	Columns                   []ComparativeColumn `json:"columns"`
+	Assets                    ComparativeSection  `json:"assets"`
+	Liabilities               ComparativeSection  `json:"liabilities"`
+	Equity                    ComparativeSection  `json:"equity"`
+	TotalAssets               ComparativeAmounts  `json:"total_assets"`
+	TotalLiabilitiesAndEquity ComparativeAmounts  `json:"total_liabilities_and_equity"`
+}
+
+type ComparativeTrialBalanceRow struct {
+	AccountID     int                `json:"account_id"`
+	AccountNumber int                `json:"account_number"`
+	AccountName   string             `json:"account_name"`
+	AccountType   string             `json:"account_type"`
+	Debit         ComparativeAmounts `json:"debit"`
+	Credit        ComparativeAmounts `json:"credit"`
+}
+
+type ComparativeTrialBalanceResponse struct {
+	BuildingID  int                          `json:"building_id"`
+	AsOfDate    string                       `json:"as_of_date"`
+	ColumnMode  string                       `json:"column_mode"`
+	Columns     []ComparativeColumn          `json:"columns"`
+	Accounts    []ComparativeTrialBalanceRow `json:"accounts"`
+	TotalDebit  ComparativeAmounts           `json:"total_debit"`
+	TotalCredit ComparativeAmounts           `json:"total_credit"`
+}
+
+// Statement of cash flows DTOs
+
+// CashFlowLine is one account's effect on cash over the period, an inflow when positive
+type CashFlowLine struct {
+	AccountID     int    `json:"account_id"`
+	AccountNumber int    `json:"account_number"`
+	AccountName   string `json:"account_name"`
+	Amount        string `json:"amount"`
+}
+
+type CashFlowOperatingSection struct {
+	NetIncome      string         `json:"net_income"`
+	NonCashItems   []CashFlowLine `json:"non_cash_items"`
+	WorkingCapital []CashFlowLine `json:"working_capital"` // changes in AR, AP and other current assets and liabilities
+	Total          string         `json:"total"`
+}
+
+type CashFlowSection struct {
+	SectionName string         `json:"section_name"`
+	Lines       []CashFlowLine `json:"lines"`
+	Total       string         `json:"total"`
+}
+
+type CashFlowStatementResponse struct {
+	BuildingID      int                      `json:"building_id"`
+	StartDate       string                   `json:"start_date"`
+	EndDate         string                   `json:"end_date"`
+	Operating       CashFlowOperatingSection `json:"operating"`
+	Investing       CashFlowSection          `json:"investing"`
+	Financing       CashFlowSection          `json:"financing"`
+	NetChangeInCash string                   `json:"net_change_in_cash"`
+	OpeningCash     string                   `json:"opening_cash"`
+	ClosingCash     string                   `json:"closing_cash"`
+	IsReconciled    bool                     `json:"is_reconciled"` // the three sections add up to the change in the cash and bank accounts
+}
@@ -9,6 +9,7 @@ type ReceiptItemInput struct {
 	Total         *float64 `json:"total"` // Use manually edited total if provided
 	PreviousValue *float64 `json:"previous_value"`
 	CurrentValue  *float64 `json:"current_value"`
+	TaxCodeID     *int64   `json:"tax_code_id"` // the line total is net or gross of tax as the code's mode says
 }
 
 type SalesReceiptPayload struct {
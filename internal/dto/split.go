@@ -16,7 +16,7 @@ type SplitDto struct {
 	Status        string   `json:"status"`
 	CreatedAt     string   `json:"created_at"`
 	UpdatedAt     string   `json:"updated_at"`
-	
+	Foreign       *string  `json:"foreign"` // in the account's currency, debit positive
 
 	// relationships
 	Account store.Account `json:"account"`
@@ -37,6 +37,11 @@ func MapSplitToDto(s store.Split) SplitDto {
 		credit := money.FormatMoneyFromCents(*s.CreditCents)
 		creditFormatted = &credit
 	}
+	var foreignFormatted *string
+	if s.ForeignCents != nil {
+		foreign := money.FormatMoneyFromCents(*s.ForeignCents)
+		foreignFormatted = &foreign
+	}
 
 	return SplitDto{
 		ID:            s.ID,
@@ -49,6 +54,7 @@ func MapSplitToDto(s store.Split) SplitDto {
 		Status:        s.Status,
 		CreatedAt:     s.CreatedAt,
 		UpdatedAt:     s.UpdatedAt,
+		Foreign:       foreignFormatted,
 	}
 }
 
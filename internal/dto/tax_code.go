@@ -0,0 +1,65 @@
+package dto
+
+import (
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+type TaxCodePayload struct {
+	Code       string  `json:"code" validate:"required,max=20"`
+	Name       string  `json:"name" validate:"required"`
+	Rate       float64 `json:"rate" validate:"gte=0,lte=100"` // percent, e.g. 7.5 = 7.5%
+	Mode       string  `json:"mode" validate:"required,oneof=exclusive inclusive"`
+	AccountID  int64   `json:"account_id" validate:"required"` // liability account tax is posted to
+	Status     string  `json:"status"`
+	BuildingID int64   `json:"building_id"`
+}
+
+type CreateTaxCodeRequest struct {
+	TaxCodePayload
+}
+
+type UpdateTaxCodeRequest struct {
+	ID int64 `json:"id"`
+	TaxCodePayload
+}
+
+type TaxCodeDto struct {
+	ID          int64  `json:"id"`
+	BuildingID  int64  `json:"building_id"`
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Rate        string `json:"rate"`
+	Mode        string `json:"mode"`
+	AccountID   int64  `json:"account_id"`
+	AccountName string `json:"account_name"`
+	Status      string `json:"status"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// map store.TaxCode to TaxCodeDto
+func MapTaxCodeToDto(tc store.TaxCode) TaxCodeDto {
+	return TaxCodeDto{
+		ID:          tc.ID,
+		BuildingID:  tc.BuildingID,
+		Code:        tc.Code,
+		Name:        tc.Name,
+		Rate:        money.FormatScaled5(tc.RateScaled),
+		Mode:        tc.Mode,
+		AccountID:   tc.AccountID,
+		AccountName: tc.AccountName,
+		Status:      tc.Status,
+		CreatedAt:   tc.CreatedAt,
+		UpdatedAt:   tc.UpdatedAt,
+	}
+}
+
+// map []store.TaxCode to []TaxCodeDto
+func MapTaxCodesToDto(codes []store.TaxCode) []TaxCodeDto {
+	dtoCodes := []TaxCodeDto{}
+	for _, tc := range codes {
+		dtoCodes = append(dtoCodes, MapTaxCodeToDto(tc))
+	}
+	return dtoCodes
+}
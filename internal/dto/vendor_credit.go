@@ -0,0 +1,141 @@
+package dto
+
+import (
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+type VendorCreditPayload struct {
+	Reference        string  `json:"reference" validate:"required"`
+	Date             string  `json:"date" validate:"required"`
+	PeopleID         int64   `json:"people_id" validate:"required"`
+	APAccountID      int64   `json:"ap_account_id" validate:"required"`
+	ExpenseAccountID int64   `json:"expense_account_id" validate:"required"` // account the refunded cost is taken out of
+	UnitID           *int64  `json:"unit_id"`
+	Amount           float64 `json:"amount" validate:"required,gt=0"`
+	Memo             string  `json:"memo"`
+	BuildingID       int64   `json:"building_id"`
+}
+
+type CreateVendorCreditRequest struct {
+	VendorCreditPayload
+}
+
+type UpdateVendorCreditRequest struct {
+	ID int64 `json:"id"`
+	VendorCreditPayload
+}
+
+type ApplyVendorCreditRequest struct {
+	BillID         int64   `json:"bill_id" validate:"required"`
+	VendorCreditID int64   `json:"vendor_credit_id" validate:"required"`
+	Amount         float64 `json:"amount" validate:"required,gt=0"`
+	Date           string  `json:"date" validate:"required"`
+	Description    string  `json:"description"`
+	BuildingID     int64   `json:"building_id"`
+}
+
+type VendorCreditDto struct {
+	ID               int64  `json:"id"`
+	TransactionID    int64  `json:"transaction_id"`
+	Reference        string `json:"reference"`
+	Date             string `json:"date"`
+	PeopleID         int64  `json:"people_id"`
+	PeopleName       string `json:"people_name"`
+	APAccountID      int64  `json:"ap_account_id"`
+	ExpenseAccountID int64  `json:"expense_account_id"`
+	UnitID           *int64 `json:"unit_id"`
+	Amount           string `json:"amount"`
+	AppliedAmount    string `json:"applied_amount"`
+	AvailableAmount  string `json:"available_amount"`
+	Memo             string `json:"memo"`
+	BuildingID       int64  `json:"building_id"`
+	Status           string `json:"status"`
+	CreatedAt        string `json:"created_at"`
+	UpdatedAt        string `json:"updated_at"`
+}
+
+type BillAppliedCreditDto struct {
+	ID                    int64  `json:"id"`
+	BillID                int64  `json:"bill_id"`
+	BillNo                string `json:"bill_no"`
+	VendorCreditID        int64  `json:"vendor_credit_id"`
+	VendorCreditReference string `json:"vendor_credit_reference"`
+	Amount                string `json:"amount"`
+	Description           string `json:"description"`
+	Date                  string `json:"date"`
+	Status                string `json:"status"`
+	CreatedAt             string `json:"created_at"`
+}
+
+type VendorCreditDetailsResponse struct {
+	VendorCredit VendorCreditDto        `json:"vendor_credit"`
+	Applications []BillAppliedCreditDto `json:"applications"`
+	Splits       []SplitDto             `json:"splits"`
+	Transaction  *store.Transaction     `json:"transaction"`
+}
+
+// BillAvailableVendorCreditsResponse lists the open credits of a bill's vendor
+type BillAvailableVendorCreditsResponse struct {
+	BillID   int64             `json:"bill_id"`
+	PeopleID int64             `json:"people_id"`
+	Balance  string            `json:"balance"`
+	Credits  []VendorCreditDto `json:"credits"`
+}
+
+// map store.VendorCredit to VendorCreditDto
+func MapVendorCreditToDto(vc store.VendorCredit) VendorCreditDto {
+	return VendorCreditDto{
+		ID:               vc.ID,
+		TransactionID:    vc.TransactionID,
+		Reference:        vc.Reference,
+		Date:             vc.Date,
+		PeopleID:         vc.PeopleID,
+		PeopleName:       vc.PeopleName,
+		APAccountID:      vc.APAccountID,
+		ExpenseAccountID: vc.ExpenseAccountID,
+		UnitID:           vc.UnitID,
+		Amount:           money.FormatMoneyFromCents(vc.AmountCents),
+		AppliedAmount:    money.FormatMoneyFromCents(vc.AppliedCents),
+		AvailableAmount:  money.FormatMoneyFromCents(vc.AmountCents - vc.AppliedCents),
+		Memo:             vc.Memo,
+		BuildingID:       vc.BuildingID,
+		Status:           vc.Status,
+		CreatedAt:        vc.CreatedAt,
+		UpdatedAt:        vc.UpdatedAt,
+	}
+}
+
+// map []store.VendorCredit to []VendorCreditDto
+func MapVendorCreditsToDto(credits []store.VendorCredit) []VendorCreditDto {
+	dtoCredits := []VendorCreditDto{}
+	for _, vc := range credits {
+		dtoCredits = append(dtoCredits, MapVendorCreditToDto(vc))
+	}
+	return dtoCredits
+}
+
+// map store.BillAppliedCredit to BillAppliedCreditDto
+func MapBillAppliedCreditToDto(a store.BillAppliedCredit) BillAppliedCreditDto {
+	return BillAppliedCreditDto{
+		ID:                    a.ID,
+		BillID:                a.BillID,
+		BillNo:                a.BillNo,
+		VendorCreditID:        a.VendorCreditID,
+		VendorCreditReference: a.VendorCreditReference,
+		Amount:                money.FormatMoneyFromCents(a.AmountCents),
+		Description:           a.Description,
+		Date:                  a.Date,
+		Status:                a.Status,
+		CreatedAt:             a.CreatedAt,
+	}
+}
+
+// map []store.BillAppliedCredit to []BillAppliedCreditDto
+func MapBillAppliedCreditsToDto(applications []store.BillAppliedCredit) []BillAppliedCreditDto {
+	dtoApplications := []BillAppliedCreditDto{}
+	for _, a := range applications {
+		dtoApplications = append(dtoApplications, MapBillAppliedCreditToDto(a))
+	}
+	return dtoApplications
+}
@@ -0,0 +1,9 @@
+package dto
+
+// VendorWithholdingRequest sets the withholding tax deducted from payments to a vendor.
+// A rate of 0 turns withholding off.
+type VendorWithholdingRequest struct {
+	Rate      float64 `json:"rate" validate:"gte=0,lt=100"` // percent, e.g. 5 = 5%
+	AccountID *int64  `json:"account_id"`                   // liability account, required when rate > 0
+	PeopleID  int64   `json:"people_id"`
+}
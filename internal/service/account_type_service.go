@@ -30,11 +30,23 @@ func (s *AccountTypeService) GetByID(ctx context.Context, id int64) (*store.Acco
 	return s.store.GetByID(ctx, id)
 }
 
+// Create saves an account type; without a cash flow category its accounts count as operating
 func (s *AccountTypeService) Create(ctx context.Context, at *store.AccountType) error {
+	if at.CashFlowCategory == "" {
+		at.CashFlowCategory = store.CashFlowOperating
+	}
 	return s.store.Create(ctx, at)
 }
 
+// Update saves an account type, keeping its cash flow category when none is given
 func (s *AccountTypeService) Update(ctx context.Context, at *store.AccountType) error {
+	if at.CashFlowCategory == "" {
+		existing, err := s.store.GetByID(ctx, at.ID)
+		if err != nil {
+			return err
+		}
+		at.CashFlowCategory = existing.CashFlowCategory
+	}
 	return s.store.Update(ctx, at)
 }
 
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+)
+
+// DefaultAgingBoundaries are the upper bounds (days past due) of the 1-30, 31-60 and 61-90 buckets
+var DefaultAgingBoundaries = []int{30, 60, 90}
+
+// agingSchedule buckets balances by days past due: Current, one bucket per boundary, and an open-ended last bucket
+type agingSchedule struct {
+	boundaries []int
+}
+
+func newAgingSchedule(boundaries []int) (*agingSchedule, error) {
+	if len(boundaries) == 0 {
+		boundaries = DefaultAgingBoundaries
+	}
+
+	for i, b := range boundaries {
+		if b <= 0 {
+			return nil, fmt.Errorf("aging boundaries must be greater than 0")
+		}
+		if i > 0 && b <= boundaries[i-1] {
+			return nil, fmt.Errorf("aging boundaries must be in ascending order")
+		}
+	}
+
+	return &agingSchedule{boundaries: boundaries}, nil
+}
+
+// Labels returns the bucket names, e.g. Current, 1-30, 31-60, 61-90, 90+
+func (a *agingSchedule) Labels() []string {
+	labels := []string{"Current"}
+	lower := 1
+	for _, b := range a.boundaries {
+		labels = append(labels, fmt.Sprintf("%d-%d", lower, b))
+		lower = b + 1
+	}
+	labels = append(labels, fmt.Sprintf("%d+", a.boundaries[len(a.boundaries)-1]))
+	return labels
+}
+
+func (a *agingSchedule) Size() int {
+	return len(a.boundaries) + 2
+}
+
+// Bucket returns the bucket index for a number of days past due
+func (a *agingSchedule) Bucket(daysPastDue int) int {
+	if daysPastDue <= 0 {
+		return 0
+	}
+	for i, b := range a.boundaries {
+		if daysPastDue <= b {
+			return i + 1
+		}
+	}
+	return len(a.boundaries) + 1
+}
+
+// daysPastDue counts whole days between dueDate and asOf, both YYYY-MM-DD
+func daysPastDue(dueDate string, asOf time.Time) int {
+	due, err := time.Parse("2006-01-02", dueDate)
+	if err != nil {
+		return 0
+	}
+	return int(asOf.Sub(due).Hours() / 24)
+}
+
+// agingRows accumulates bucket totals per key, keeping first-seen order
+type agingRows struct {
+	size  int
+	order []int
+	names map[int]string
+	cents map[int][]int64
+	docs  map[int][]dto.AgingDocument
+}
+
+func newAgingRows(size int) *agingRows {
+	return &agingRows{
+		size:  size,
+		names: make(map[int]string),
+		cents: make(map[int][]int64),
+		docs:  make(map[int][]dto.AgingDocument),
+	}
+}
+
+func (r *agingRows) add(id int, name string, bucket int, cents int64, doc *dto.AgingDocument) {
+	if _, ok := r.cents[id]; !ok {
+		r.order = append(r.order, id)
+		r.names[id] = name
+		r.cents[id] = make([]int64, r.size)
+	}
+	r.cents[id][bucket] += cents
+	if doc != nil {
+		r.docs[id] = append(r.docs[id], *doc)
+	}
+}
+
+func (r *agingRows) rows(format money.Formatter) []dto.AgingRow {
+	rows := []dto.AgingRow{}
+	for _, id := range r.order {
+		total := int64(0)
+		buckets := make([]string, r.size)
+		for i, c := range r.cents[id] {
+			buckets[i] = format.Cents(c)
+			total += c
+		}
+		rows = append(rows, dto.AgingRow{
+			ID:        id,
+			Name:      r.names[id],
+			Buckets:   buckets,
+			Total:     format.Cents(total),
+			Documents: r.docs[id],
+		})
+	}
+	return rows
+}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+/*
+|--------------------------------------------------------------------------
+| Interfaces
+|--------------------------------------------------------------------------
+*/
+
+type ApprovalStore interface {
+	GetThresholds(ctx context.Context, buildingID int64, documentType *string) ([]store.ApprovalThreshold, error)
+	GetThresholdByID(ctx context.Context, id int64) (*store.ApprovalThreshold, error)
+	GetRequiredThresholds(ctx context.Context, buildingID int64, documentType string, amountCents int64) ([]store.ApprovalThreshold, error)
+	CreateThreshold(ctx context.Context, t *store.ApprovalThreshold) error
+	UpdateThreshold(ctx context.Context, t *store.ApprovalThreshold) error
+	DeleteThreshold(ctx context.Context, id int64) error
+	GetApprovals(ctx context.Context, documentType string, documentID int64) ([]store.DocumentApproval, error)
+	CreateApproval(ctx context.Context, tx *sql.Tx, a *store.DocumentApproval) error
+	VoidApprovals(ctx context.Context, tx *sql.Tx, documentType string, documentID int64) error
+	LockDocumentTx(ctx context.Context, tx *sql.Tx, documentType string, documentID int64) (string, error)
+	SetDocumentStatusTx(ctx context.Context, tx *sql.Tx, documentType string, documentID int64, status string) error
+	UserHasRole(ctx context.Context, userID, buildingID, roleID int64) (bool, error)
+	UserHasBuildingRole(ctx context.Context, userID, buildingID int64) (bool, error)
+}
+
+/*
+|--------------------------------------------------------------------------
+| Service
+|--------------------------------------------------------------------------
+*/
+
+// ApprovalService holds the per-building approval thresholds and walks bills and checks
+// through them. Bill and check services call it; they post splits once it reports approved.
+type ApprovalService struct {
+	approvalStore ApprovalStore
+}
+
+// approvalDocument is the part of a bill or check the approval chain looks at
+type approvalDocument struct {
+	Type        string // bill | check
+	ID          int64
+	BuildingID  int64
+	AmountCents int64
+	CreatedBy   int64 // 0 when the document does not record who entered it
+}
+
+/*
+|--------------------------------------------------------------------------
+| Constructor
+|--------------------------------------------------------------------------
+*/
+
+func NewApprovalService(approvalStore ApprovalStore) *ApprovalService {
+	return &ApprovalService{approvalStore: approvalStore}
+}
+
+/*
+|--------------------------------------------------------------------------
+| Queries
+|--------------------------------------------------------------------------
+*/
+
+func (s *ApprovalService) GetThresholds(ctx context.Context, buildingID int64, documentType *string) ([]dto.ApprovalThresholdDto, error) {
+	thresholds, err := s.approvalStore.GetThresholds(ctx, buildingID, documentType)
+	if err != nil {
+		return nil, err
+	}
+	return dto.MapApprovalThresholdsToDto(thresholds), nil
+}
+
+func (s *ApprovalService) GetThreshold(ctx context.Context, id int64) (*dto.ApprovalThresholdDto, error) {
+	threshold, err := s.approvalStore.GetThresholdByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	thresholdDto := dto.MapApprovalThresholdToDto(*threshold)
+	return &thresholdDto, nil
+}
+
+// getStatus reports the thresholds a document needs, the next one outstanding and its decision history
+func (s *ApprovalService) getStatus(ctx context.Context, doc approvalDocument, approvalStatus string) (*dto.ApprovalStatusResponse, error) {
+	required, err := s.approvalStore.GetRequiredThresholds(ctx, doc.BuildingID, doc.Type, doc.AmountCents)
+	if err != nil {
+		return nil, err
+	}
+
+	approvals, err := s.approvalStore.GetApprovals(ctx, doc.Type, doc.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ApprovalStatusResponse{
+		DocumentType:   doc.Type,
+		DocumentID:     doc.ID,
+		ApprovalStatus: approvalStatus,
+		Required:       dto.MapApprovalThresholdsToDto(required),
+		History:        dto.MapDocumentApprovalsToDto(approvals),
+	}
+
+	if approvalStatus == "draft" || approvalStatus == "pending" {
+		if next := nextThreshold(required, approvals); next != nil {
+			nextDto := dto.MapApprovalThresholdToDto(*next)
+			response.NextThreshold = &nextDto
+		}
+	}
+
+	return response, nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Commands
+|--------------------------------------------------------------------------
+*/
+
+func (s *ApprovalService) CreateThreshold(ctx context.Context, req dto.CreateApprovalThresholdRequest) (*dto.ApprovalThresholdDto, error) {
+	threshold, err := buildApprovalThreshold(req.ApprovalThresholdPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.approvalStore.CreateThreshold(ctx, threshold); err != nil {
+		return nil, err
+	}
+
+	return s.GetThreshold(ctx, threshold.ID)
+}
+
+// UpdateThreshold applies to documents approved from now on; earlier decisions stand
+func (s *ApprovalService) UpdateThreshold(ctx context.Context, req dto.UpdateApprovalThresholdRequest) (*dto.ApprovalThresholdDto, error) {
+	existing, err := s.approvalStore.GetThresholdByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.BuildingID != req.BuildingID {
+		return nil, fmt.Errorf("approval threshold does not belong to this building")
+	}
+
+	threshold, err := buildApprovalThreshold(req.ApprovalThresholdPayload)
+	if err != nil {
+		return nil, err
+	}
+	threshold.ID = req.ID
+
+	if err := s.approvalStore.UpdateThreshold(ctx, threshold); err != nil {
+		return nil, err
+	}
+
+	return s.GetThreshold(ctx, threshold.ID)
+}
+
+func (s *ApprovalService) DeleteThreshold(ctx context.Context, buildingID int64, id int64) error {
+	existing, err := s.approvalStore.GetThresholdByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if existing.BuildingID != buildingID {
+		return fmt.Errorf("approval threshold does not belong to this building")
+	}
+
+	return s.approvalStore.DeleteThreshold(ctx, id)
+}
+
+// initialStatus is the approval status a new or edited document starts in: draft when saved as
+// one, pending when its amount reaches a threshold, otherwise approved and posted right away
+func (s *ApprovalService) initialStatus(ctx context.Context, buildingID int64, documentType string, amountCents int64, draft bool) (string, error) {
+	if draft {
+		return "draft", nil
+	}
+
+	required, err := s.approvalStore.GetRequiredThresholds(ctx, buildingID, documentType, amountCents)
+	if err != nil {
+		return "", err
+	}
+
+	if len(required) > 0 {
+		return "pending", nil
+	}
+
+	return "approved", nil
+}
+
+// lockTx locks the bill or check row for an edit or decision and returns its approval status
+func (s *ApprovalService) lockTx(ctx context.Context, tx *sql.Tx, documentType string, documentID int64) (string, error) {
+	return s.approvalStore.LockDocumentTx(ctx, tx, documentType, documentID)
+}
+
+// resetTx voids the decisions on a document that is being edited
+func (s *ApprovalService) resetTx(ctx context.Context, tx *sql.Tx, documentType string, documentID int64) error {
+	return s.approvalStore.VoidApprovals(ctx, tx, documentType, documentID)
+}
+
+// decideTx records an approval or rejection for the next outstanding threshold and returns the
+// document's new approval status. The caller posts the document when it comes back approved.
+// userID is the approver, taken from the caller's token. A document below every threshold still
+// needs its creator or a user with a role in the building. The document must be locked with
+// lockTx first.
+func (s *ApprovalService) decideTx(ctx context.Context, tx *sql.Tx, doc approvalDocument, status string, userID int64, req dto.ApprovalDecisionRequest, approve bool) (string, error) {
+	switch status {
+	case "approved":
+		return "", fmt.Errorf("%s is already approved", doc.Type)
+	case "rejected":
+		return "", fmt.Errorf("%s was rejected, edit it to submit it again", doc.Type)
+	}
+
+	required, err := s.approvalStore.GetRequiredThresholds(ctx, doc.BuildingID, doc.Type, doc.AmountCents)
+	if err != nil {
+		return "", err
+	}
+
+	approvals, err := s.approvalStore.GetApprovals(ctx, doc.Type, doc.ID)
+	if err != nil {
+		return "", err
+	}
+
+	next := nextThreshold(required, approvals)
+
+	var thresholdID *int64
+	if next != nil {
+		hasRole, err := s.approvalStore.UserHasRole(ctx, userID, doc.BuildingID, next.RoleID)
+		if err != nil {
+			return "", err
+		}
+		if !hasRole {
+			return "", fmt.Errorf("%s of %s or more needs the %s role", doc.Type, money.FormatMoneyFromCents(next.MinAmountCents), next.RoleName)
+		}
+
+		// a second pair of eyes means a different approver at every level
+		for _, a := range approvals {
+			if a.Status == "1" && a.Action == "approved" && a.UserID == userID {
+				return "", fmt.Errorf("user already approved this %s", doc.Type)
+			}
+		}
+
+		thresholdID = &next.ID
+	} else if doc.CreatedBy != userID {
+		// below every threshold the one who entered it or anyone working in the building may decide
+		hasRole, err := s.approvalStore.UserHasBuildingRole(ctx, userID, doc.BuildingID)
+		if err != nil {
+			return "", err
+		}
+		if !hasRole {
+			return "", fmt.Errorf("%s needs a user with a role in the building to decide on it", doc.Type)
+		}
+	}
+
+	action := "rejected"
+	if approve {
+		action = "approved"
+	}
+
+	decision := store.DocumentApproval{
+		DocumentType: doc.Type,
+		DocumentID:   doc.ID,
+		ThresholdID:  thresholdID,
+		Action:       action,
+		UserID:       userID,
+		Note:         req.Note,
+		BuildingID:   doc.BuildingID,
+	}
+	if err := s.approvalStore.CreateApproval(ctx, tx, &decision); err != nil {
+		return "", err
+	}
+
+	newStatus := "rejected"
+	if approve {
+		newStatus = "approved"
+		if nextThreshold(required, append(approvals, decision)) != nil {
+			newStatus = "pending"
+		}
+	}
+
+	if err := s.approvalStore.SetDocumentStatusTx(ctx, tx, doc.Type, doc.ID, newStatus); err != nil {
+		return "", err
+	}
+
+	return newStatus, nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Helpers
+|--------------------------------------------------------------------------
+*/
+
+func buildApprovalThreshold(req dto.ApprovalThresholdPayload) (*store.ApprovalThreshold, error) {
+	minAmountCents, err := money.ParseUSDAmount(strconv.FormatFloat(req.MinAmount, 'f', -1, 64))
+	if err != nil {
+		return nil, fmt.Errorf("invalid min amount: %v", err)
+	}
+
+	status := req.Status
+	if status == "" {
+		status = "1"
+	}
+
+	return &store.ApprovalThreshold{
+		BuildingID:     req.BuildingID,
+		DocumentType:   req.DocumentType,
+		MinAmountCents: minAmountCents,
+		RoleID:         req.RoleID,
+		Status:         status,
+	}, nil
+}
+
+// nextThreshold returns the lowest required threshold without a current approval
+func nextThreshold(required []store.ApprovalThreshold, approvals []store.DocumentApproval) *store.ApprovalThreshold {
+	approved := map[int64]bool{}
+	for _, a := range approvals {
+		if a.Status == "1" && a.Action == "approved" && a.ThresholdID != nil {
+			approved[*a.ThresholdID] = true
+		}
+	}
+
+	for i := range required {
+		if !approved[required[i].ID] {
+			return &required[i]
+		}
+	}
+
+	return nil
+}
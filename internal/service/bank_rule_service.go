@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+/*
+|--------------------------------------------------------------------------
+| Interfaces
+|--------------------------------------------------------------------------
+*/
+
+type BankRuleStore interface {
+	GetAll(ctx context.Context, buildingID int64, activeOnly bool) ([]store.BankRule, error)
+	GetByID(ctx context.Context, id int64) (*store.BankRule, error)
+	Create(ctx context.Context, tx *sql.Tx, r *store.BankRule) error
+	Update(ctx context.Context, r *store.BankRule) error
+	Delete(ctx context.Context, id int64) error
+}
+
+/*
+|--------------------------------------------------------------------------
+| Service
+|--------------------------------------------------------------------------
+*/
+
+// BankRuleService maintains the rules that categorize imported bank lines (see BankStatementService.ApplyRules)
+type BankRuleService struct {
+	db            *sql.DB
+	bankRuleStore BankRuleStore
+	accountStore  AccountStore
+}
+
+/*
+|--------------------------------------------------------------------------
+| Constructor
+|--------------------------------------------------------------------------
+*/
+
+func NewBankRuleService(
+	db *sql.DB,
+	bankRuleStore BankRuleStore,
+	accountStore AccountStore,
+) *BankRuleService {
+	return &BankRuleService{
+		db:            db,
+		bankRuleStore: bankRuleStore,
+		accountStore:  accountStore,
+	}
+}
+
+/*
+|--------------------------------------------------------------------------
+| Queries
+|--------------------------------------------------------------------------
+*/
+
+func (s *BankRuleService) GetAll(ctx context.Context, buildingID int64) ([]dto.BankRuleDto, error) {
+	rules, err := s.bankRuleStore.GetAll(ctx, buildingID, false)
+	if err != nil {
+		return nil, err
+	}
+	return dto.MapBankRulesToDto(rules), nil
+}
+
+func (s *BankRuleService) GetByID(ctx context.Context, id int64) (*dto.BankRuleDto, error) {
+	rule, err := s.bankRuleStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ruleDto := dto.MapBankRuleToDto(*rule)
+	return &ruleDto, nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Commands
+|--------------------------------------------------------------------------
+*/
+
+func (s *BankRuleService) Create(ctx context.Context, req dto.CreateBankRuleRequest) (*dto.BankRuleDto, error) {
+	rule, err := s.buildRule(ctx, req.BankRulePayload)
+	if err != nil {
+		return nil, err
+	}
+
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return s.bankRuleStore.Create(ctx, tx, rule)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ruleDto := dto.MapBankRuleToDto(*rule)
+	return &ruleDto, nil
+}
+
+func (s *BankRuleService) Update(ctx context.Context, req dto.UpdateBankRuleRequest) (*dto.BankRuleDto, error) {
+	existing, err := s.bankRuleStore.GetByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.BuildingID != req.BuildingID {
+		return nil, fmt.Errorf("bank rule does not belong to this building")
+	}
+
+	rule, err := s.buildRule(ctx, req.BankRulePayload)
+	if err != nil {
+		return nil, err
+	}
+	rule.ID = existing.ID
+	rule.CreatedAt = existing.CreatedAt
+
+	if err := s.bankRuleStore.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	ruleDto := dto.MapBankRuleToDto(*rule)
+	return &ruleDto, nil
+}
+
+func (s *BankRuleService) Delete(ctx context.Context, id int64) error {
+	return s.bankRuleStore.Delete(ctx, id)
+}
+
+/*
+|--------------------------------------------------------------------------
+| Helpers
+|--------------------------------------------------------------------------
+*/
+
+func (s *BankRuleService) buildRule(ctx context.Context, req dto.BankRulePayload) (*store.BankRule, error) {
+	rule := &store.BankRule{
+		BuildingID:          req.BuildingID,
+		Name:                req.Name,
+		Priority:            100,
+		BankAccountID:       req.BankAccountID,
+		Direction:           req.Direction,
+		DescriptionContains: trimmedOrNil(req.DescriptionContains),
+		DescriptionRegex:    trimmedOrNil(req.DescriptionRegex),
+		TargetAccountID:     req.TargetAccountID,
+		ItemID:              req.ItemID,
+		UnitID:              req.UnitID,
+		PeopleID:            req.PeopleID,
+		Status:              "1",
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if rule.Direction == "" {
+		rule.Direction = "any"
+	}
+	if req.Status != nil {
+		rule.Status = *req.Status
+	}
+
+	for _, bound := range []struct {
+		amount *float64
+		cents  **int64
+	}{{req.MinAmount, &rule.MinAmountCents}, {req.MaxAmount, &rule.MaxAmountCents}} {
+		if bound.amount == nil {
+			continue
+		}
+		cents, err := money.ParseUSDAmount(strconv.FormatFloat(*bound.amount, 'f', -1, 64))
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount range: %v", err)
+		}
+		*bound.cents = &cents
+	}
+
+	if err := validateBankRule(rule); err != nil {
+		return nil, err
+	}
+
+	for _, accountID := range []*int64{rule.BankAccountID, rule.TargetAccountID} {
+		if accountID == nil {
+			continue
+		}
+		account, err := s.accountStore.GetByID(ctx, *accountID)
+		if err != nil {
+			return nil, fmt.Errorf("account %d not found", *accountID)
+		}
+		if account.BuildingID != rule.BuildingID {
+			return nil, fmt.Errorf("account %s does not belong to this building", account.AccountName)
+		}
+	}
+
+	return rule, nil
+}
+
+// validateBankRule checks that a rule has a condition and a target for each direction it accepts
+func validateBankRule(rule *store.BankRule) error {
+	if rule.DescriptionContains == nil && rule.DescriptionRegex == nil && rule.MinAmountCents == nil && rule.MaxAmountCents == nil {
+		return fmt.Errorf("bank rule needs a description or amount condition")
+	}
+	if rule.DescriptionRegex != nil {
+		if _, err := regexp.Compile(*rule.DescriptionRegex); err != nil {
+			return fmt.Errorf("invalid description regex: %v", err)
+		}
+	}
+	if rule.MinAmountCents != nil && rule.MaxAmountCents != nil && *rule.MinAmountCents > *rule.MaxAmountCents {
+		return fmt.Errorf("minimum amount is above the maximum amount")
+	}
+	if rule.Direction != "in" && rule.TargetAccountID == nil {
+		return fmt.Errorf("bank rule needs a target account to book withdrawals as checks")
+	}
+	if rule.Direction != "out" && rule.ItemID == nil {
+		return fmt.Errorf("bank rule needs an item to book deposits as sales receipts")
+	}
+	return nil
+}
+
+// compiledBankRule is a rule with its regex compiled once per run
+type compiledBankRule struct {
+	store.BankRule
+	re *regexp.Regexp
+}
+
+func compileBankRules(rules []store.BankRule) []compiledBankRule {
+	compiled := make([]compiledBankRule, 0, len(rules))
+	for _, rule := range rules {
+		c := compiledBankRule{BankRule: rule}
+		if rule.DescriptionRegex != nil {
+			re, err := regexp.Compile("(?i)" + *rule.DescriptionRegex)
+			if err != nil {
+				continue // a rule saved before validation existed never fires
+			}
+			c.re = re
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled
+}
+
+// matches reports whether every condition of the rule holds for the line
+func (r compiledBankRule) matches(line store.BankStatementLine) bool {
+	if r.BankAccountID != nil && *r.BankAccountID != line.AccountID {
+		return false
+	}
+
+	switch r.Direction {
+	case "in":
+		if line.AmountCents <= 0 {
+			return false
+		}
+	case "out":
+		if line.AmountCents >= 0 {
+			return false
+		}
+	}
+
+	amount := line.AmountCents
+	if amount < 0 {
+		amount = -amount
+	}
+	if r.MinAmountCents != nil && amount < *r.MinAmountCents {
+		return false
+	}
+	if r.MaxAmountCents != nil && amount > *r.MaxAmountCents {
+		return false
+	}
+
+	if r.DescriptionContains != nil && !strings.Contains(strings.ToLower(line.Description), strings.ToLower(*r.DescriptionContains)) {
+		return false
+	}
+	if r.re != nil && !r.re.MatchString(line.Description) {
+		return false
+	}
+
+	return true
+}
+
+// learnedDescription is the stable start of a bank description: the words before the first one
+// carrying a digit, which is usually a card number, date or reference that changes every time
+func learnedDescription(description string) string {
+	words := strings.Fields(description)
+	var stable []string
+	for _, word := range words {
+		if strings.ContainsFunc(word, unicode.IsDigit) {
+			break
+		}
+		stable = append(stable, word)
+	}
+	if len(stable) == 0 {
+		return strings.Join(words, " ")
+	}
+	return strings.Join(stable, " ")
+}
+
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*s)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
@@ -0,0 +1,697 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/bankstatement"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+/*
+|--------------------------------------------------------------------------
+| Interfaces
+|--------------------------------------------------------------------------
+*/
+
+type BankStatementStore interface {
+	GetImports(ctx context.Context, buildingID int64, accountID *int64) ([]store.BankStatementImport, error)
+	GetLines(ctx context.Context, buildingID int64, accountID, importID *int64, status *string) ([]store.BankStatementLine, error)
+	GetLineByID(ctx context.Context, id int64) (*store.BankStatementLine, error)
+	GetMatchCandidates(ctx context.Context, l *store.BankStatementLine, maxDays int, splitID *int64) ([]store.BankMatchCandidate, error)
+	GetTransactionSplitID(ctx context.Context, tx *sql.Tx, transactionID, accountID int64) (int64, error)
+	SetLineRule(ctx context.Context, tx *sql.Tx, id int64, ruleID *int64) error
+	CreateImport(ctx context.Context, tx *sql.Tx, i *store.BankStatementImport) error
+	SetImportCounts(ctx context.Context, tx *sql.Tx, id int64, lineCount, duplicateCount int) error
+	CreateLine(ctx context.Context, tx *sql.Tx, l *store.BankStatementLine) (bool, error)
+	UpdateLineStatus(ctx context.Context, tx *sql.Tx, id int64, fromStatus, status string, splitID, transactionID *int64) error
+}
+
+/*
+|--------------------------------------------------------------------------
+| Service
+|--------------------------------------------------------------------------
+*/
+
+// defaultMatchDays is how far a ledger date may drift from the bank's booking date
+const defaultMatchDays = 5
+
+// BankStatementService stages imported bank statement lines and matches them to the ledger.
+// Lines without a ledger entry are booked as checks or sales receipts through their services,
+// by hand or from the draft of the bank rule that matched them.
+type BankStatementService struct {
+	db                  *sql.DB
+	bankStatementStore  BankStatementStore
+	bankRuleStore       BankRuleStore
+	accountStore        AccountStore
+	checkService        *CheckService
+	salesReceiptService *SalesReceiptService
+}
+
+/*
+|--------------------------------------------------------------------------
+| Constructor
+|--------------------------------------------------------------------------
+*/
+
+func NewBankStatementService(
+	db *sql.DB,
+	bankStatementStore BankStatementStore,
+	bankRuleStore BankRuleStore,
+	accountStore AccountStore,
+	checkService *CheckService,
+	salesReceiptService *SalesReceiptService,
+) *BankStatementService {
+	return &BankStatementService{
+		db:                  db,
+		bankStatementStore:  bankStatementStore,
+		bankRuleStore:       bankRuleStore,
+		accountStore:        accountStore,
+		checkService:        checkService,
+		salesReceiptService: salesReceiptService,
+	}
+}
+
+/*
+|--------------------------------------------------------------------------
+| Queries
+|--------------------------------------------------------------------------
+*/
+
+func (s *BankStatementService) GetImports(ctx context.Context, buildingID int64, accountID *int64) ([]dto.BankStatementImportDto, error) {
+	imports, err := s.bankStatementStore.GetImports(ctx, buildingID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.MapBankStatementImportsToDto(imports), nil
+}
+
+func (s *BankStatementService) GetLines(ctx context.Context, buildingID int64, accountID, importID *int64, status *string) ([]dto.BankStatementLineDto, error) {
+	lines, err := s.bankStatementStore.GetLines(ctx, buildingID, accountID, importID, status)
+	if err != nil {
+		return nil, err
+	}
+	return dto.MapBankStatementLinesToDto(lines), nil
+}
+
+func (s *BankStatementService) GetLine(ctx context.Context, id int64) (*dto.BankStatementLineDto, error) {
+	line, err := s.bankStatementStore.GetLineByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	lineDto := dto.MapBankStatementLineToDto(*line)
+	return &lineDto, nil
+}
+
+// GetCandidates lists ledger splits a line could be matched to, closest date first
+func (s *BankStatementService) GetCandidates(ctx context.Context, lineID int64, maxDays *int) ([]dto.BankMatchCandidateDto, error) {
+	line, err := s.bankStatementStore.GetLineByID(ctx, lineID)
+	if err != nil {
+		return nil, err
+	}
+
+	days := defaultMatchDays
+	if maxDays != nil {
+		days = *maxDays
+	}
+
+	candidates, err := s.bankStatementStore.GetMatchCandidates(ctx, line, days, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []dto.BankMatchCandidateDto{}
+	for _, c := range candidates {
+		response = append(response, dto.BankMatchCandidateDto{
+			SplitID:        c.SplitID,
+			TransactionID:  c.TransactionID,
+			Type:           c.Type,
+			Date:           c.Date,
+			Reference:      c.Reference,
+			Memo:           c.Memo,
+			PeopleName:     c.PeopleName,
+			Amount:         money.FormatMoneyFromCents(c.AmountCents),
+			DaysApart:      c.DaysApart,
+			ReferenceMatch: referenceMatches(line, c),
+		})
+	}
+
+	return response, nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Commands
+|--------------------------------------------------------------------------
+*/
+
+// Import parses a statement file and stages its lines; lines already staged for the account
+// (same FITID or content hash) are counted as duplicates and skipped
+func (s *BankStatementService) Import(ctx context.Context, req dto.ImportBankStatementRequest) (*dto.BankStatementImportResponse, error) {
+	if _, err := s.bankAccount(ctx, req.AccountID, req.BuildingID); err != nil {
+		return nil, err
+	}
+
+	lines, err := bankstatement.Parse(req.Format, []byte(req.Content), req.CSVMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	statementImport := &store.BankStatementImport{
+		AccountID:  req.AccountID,
+		BuildingID: req.BuildingID,
+		Format:     req.Format,
+		FileName:   req.FileName,
+		UserID:     1, // TODO: get user id from jwt
+	}
+
+	var staged []store.BankStatementLine
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.bankStatementStore.CreateImport(ctx, tx, statementImport); err != nil {
+			return err
+		}
+
+		for _, line := range lines {
+			row := store.BankStatementLine{
+				ImportID:    statementImport.ID,
+				AccountID:   req.AccountID,
+				BuildingID:  req.BuildingID,
+				Date:        line.Date,
+				AmountCents: line.AmountCents,
+				Description: truncate(line.Description, 500),
+				Reference:   truncate(line.Reference, 255),
+				DedupeKey:   line.DedupeKey,
+			}
+			if line.FITID != "" {
+				fitid := line.FITID
+				row.FITID = &fitid
+			}
+
+			created, err := s.bankStatementStore.CreateLine(ctx, tx, &row)
+			if err != nil {
+				return err
+			}
+			if created {
+				staged = append(staged, row)
+			}
+		}
+
+		statementImport.LineCount = len(staged)
+		statementImport.DuplicateCount = len(lines) - len(staged)
+		return s.bankStatementStore.SetImportCounts(ctx, tx, statementImport.ID, statementImport.LineCount, statementImport.DuplicateCount)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.BankStatementImportResponse{
+		Import: dto.MapBankStatementImportToDto(*statementImport),
+		Lines:  dto.MapBankStatementLinesToDto(staged),
+	}, nil
+}
+
+// AutoMatch matches every new line of the account that has exactly one plausible split:
+// the only split with a matching reference, or the only split with the same amount in range
+func (s *BankStatementService) AutoMatch(ctx context.Context, buildingID int64, req dto.AutoMatchBankStatementRequest) (*dto.AutoMatchBankStatementResponse, error) {
+	if _, err := s.bankAccount(ctx, req.AccountID, buildingID); err != nil {
+		return nil, err
+	}
+
+	maxDays := defaultMatchDays
+	if req.MaxDays != nil {
+		maxDays = *req.MaxDays
+	}
+
+	status := "new"
+	lines, err := s.bankStatementStore.GetLines(ctx, buildingID, &req.AccountID, nil, &status)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.AutoMatchBankStatementResponse{Matched: []dto.BankStatementLineDto{}}
+	taken := make(map[int64]bool)
+
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		for _, line := range lines {
+			candidates, err := s.bankStatementStore.GetMatchCandidates(ctx, &line, maxDays, nil)
+			if err != nil {
+				return err
+			}
+
+			var open []store.BankMatchCandidate
+			for _, c := range candidates {
+				if !taken[c.SplitID] {
+					open = append(open, c)
+				}
+			}
+
+			candidate := pickMatchCandidate(&line, open)
+			if candidate == nil {
+				response.Unmatched++
+				continue
+			}
+
+			if err := s.bankStatementStore.UpdateLineStatus(ctx, tx, line.ID, "new", "matched", &candidate.SplitID, &candidate.TransactionID); err != nil {
+				return err
+			}
+			taken[candidate.SplitID] = true
+
+			line.Status = "matched"
+			line.SplitID = &candidate.SplitID
+			line.TransactionID = &candidate.TransactionID
+			response.Matched = append(response.Matched, dto.MapBankStatementLineToDto(line))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Match links a new line to a split the user picked; amount and account must agree, the date may differ
+func (s *BankStatementService) Match(ctx context.Context, lineID int64, req dto.MatchBankStatementLineRequest) error {
+	line, err := s.lineWithStatus(ctx, lineID, "new")
+	if err != nil {
+		return err
+	}
+
+	candidates, err := s.bankStatementStore.GetMatchCandidates(ctx, line, 0, &req.SplitID)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("split %d is not an unmatched %s entry on this bank account", req.SplitID, money.FormatMoneyFromCents(line.AmountCents))
+	}
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return s.bankStatementStore.UpdateLineStatus(ctx, tx, line.ID, "new", "matched", &candidates[0].SplitID, &candidates[0].TransactionID)
+	})
+}
+
+// Reset returns a matched, created or ignored line to new. A check or receipt created from the
+// line is kept and can be matched again.
+func (s *BankStatementService) Reset(ctx context.Context, lineID int64) error {
+	line, err := s.bankStatementStore.GetLineByID(ctx, lineID)
+	if err != nil {
+		return err
+	}
+	if line.Status == "new" {
+		return fmt.Errorf("statement line is not matched")
+	}
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return s.bankStatementStore.UpdateLineStatus(ctx, tx, line.ID, line.Status, "new", nil, nil)
+	})
+}
+
+// Ignore sets aside a line that has no place in the ledger, e.g. a bank-side correction
+func (s *BankStatementService) Ignore(ctx context.Context, lineID int64) error {
+	line, err := s.lineWithStatus(ctx, lineID, "new")
+	if err != nil {
+		return err
+	}
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return s.bankStatementStore.UpdateLineStatus(ctx, tx, line.ID, "new", "ignored", nil, nil)
+	})
+}
+
+// CreateCheck books a withdrawal line as a check paid from the statement's bank account. The check
+// goes through the building's approval thresholds like any other; one that needs approval keeps
+// the line until it is approved and posted.
+func (s *BankStatementService) CreateCheck(ctx context.Context, lineID int64, req dto.CreateCheckFromStatementLineRequest) error {
+	line, err := s.lineWithStatus(ctx, lineID, "new")
+	if err != nil {
+		return err
+	}
+
+	checkReq, err := checkRequestForLine(line, req)
+	if err != nil {
+		return err
+	}
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		transactionID, err := s.checkService.CreateTx(ctx, tx, *checkReq)
+		if err != nil {
+			return err
+		}
+		if err := s.markCreated(ctx, tx, line, *transactionID); err != nil {
+			return err
+		}
+		if !req.LearnRule {
+			return nil
+		}
+		return s.learnRule(ctx, tx, line, &store.BankRule{
+			Direction:       "out",
+			TargetAccountID: &req.ExpenseAccountID,
+			UnitID:          req.UnitID,
+			PeopleID:        req.PeopleID,
+		})
+	})
+}
+
+// CreateReceipt books a deposit line as a sales receipt into the statement's bank account
+func (s *BankStatementService) CreateReceipt(ctx context.Context, lineID int64, req dto.CreateReceiptFromStatementLineRequest) error {
+	line, err := s.lineWithStatus(ctx, lineID, "new")
+	if err != nil {
+		return err
+	}
+
+	receiptReq, err := receiptRequestForLine(line, req)
+	if err != nil {
+		return err
+	}
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		transactionID, err := s.salesReceiptService.CreateTx(ctx, tx, *receiptReq)
+		if err != nil {
+			return err
+		}
+		if err := s.markCreated(ctx, tx, line, *transactionID); err != nil {
+			return err
+		}
+		if !req.LearnRule {
+			return nil
+		}
+		itemID := int64(req.ItemID)
+		return s.learnRule(ctx, tx, line, &store.BankRule{
+			Direction: "in",
+			ItemID:    &itemID,
+			UnitID:    req.UnitID,
+			PeopleID:  req.PeopleID,
+		})
+	})
+}
+
+// ApplyRules tags each new line of the account with the first active bank rule that matches it.
+// Lines no rule matches lose any rule they had, so edited rules can be re-run.
+func (s *BankStatementService) ApplyRules(ctx context.Context, buildingID int64, req dto.ApplyBankRulesRequest) (*dto.ApplyBankRulesResponse, error) {
+	if _, err := s.bankAccount(ctx, req.AccountID, buildingID); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.bankRuleStore.GetAll(ctx, buildingID, true)
+	if err != nil {
+		return nil, err
+	}
+	compiled := compileBankRules(rules)
+
+	status := "new"
+	lines, err := s.bankStatementStore.GetLines(ctx, buildingID, &req.AccountID, nil, &status)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ApplyBankRulesResponse{Categorized: []dto.BankStatementLineDto{}}
+
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		for _, line := range lines {
+			var fired *compiledBankRule
+			for i := range compiled {
+				if compiled[i].matches(line) {
+					fired = &compiled[i]
+					break
+				}
+			}
+
+			if fired == nil {
+				response.Unmatched++
+				if line.RuleID != nil {
+					if err := s.bankStatementStore.SetLineRule(ctx, tx, line.ID, nil); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+
+			if err := s.bankStatementStore.SetLineRule(ctx, tx, line.ID, &fired.ID); err != nil {
+				return err
+			}
+			line.RuleID = &fired.ID
+			line.RuleName = fired.Name
+			response.Categorized = append(response.Categorized, dto.MapBankStatementLineToDto(line))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetDraft builds the check or sales receipt the line's rule would post, with its splits, without saving it
+func (s *BankStatementService) GetDraft(ctx context.Context, lineID int64) (*dto.BankRuleDraftResponse, error) {
+	line, rule, err := s.lineWithRule(ctx, lineID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.BankRuleDraftResponse{
+		Line: dto.MapBankStatementLineToDto(*line),
+		Rule: dto.MapBankRuleToDto(*rule),
+	}
+
+	var splits []store.Split
+	if line.AmountCents < 0 {
+		checkReq, err := checkRequestForLine(line, ruleCheckRequest(rule))
+		if err != nil {
+			return nil, err
+		}
+		if splits, err = s.checkService.GenerateCheckSplits(ctx, checkReq.CheckPayloadDTO); err != nil {
+			return nil, err
+		}
+		response.DocumentType = "check"
+		response.Check = checkReq
+	} else {
+		receiptReq, err := receiptRequestForLine(line, ruleReceiptRequest(rule, 0))
+		if err != nil {
+			return nil, err
+		}
+		if splits, err = s.salesReceiptService.GenerateSalesReceiptSplits(ctx, receiptReq.SalesReceiptPayload); err != nil {
+			return nil, err
+		}
+		response.DocumentType = "receipt"
+		response.Receipt = receiptReq
+	}
+	response.Splits = dto.MapSplitsToDto(splits)
+
+	return response, nil
+}
+
+// PostDraft posts the line's rule draft as a check or, given a receipt number, a sales receipt
+func (s *BankStatementService) PostDraft(ctx context.Context, lineID int64, req dto.PostBankRuleDraftRequest) error {
+	line, rule, err := s.lineWithRule(ctx, lineID)
+	if err != nil {
+		return err
+	}
+
+	if line.AmountCents < 0 {
+		return s.CreateCheck(ctx, line.ID, ruleCheckRequest(rule))
+	}
+	if req.ReceiptNo == nil {
+		return fmt.Errorf("receipt_no is required to post a sales receipt")
+	}
+	return s.CreateReceipt(ctx, line.ID, ruleReceiptRequest(rule, *req.ReceiptNo))
+}
+
+/*
+|--------------------------------------------------------------------------
+| Helpers
+|--------------------------------------------------------------------------
+*/
+
+func (s *BankStatementService) bankAccount(ctx context.Context, accountID, buildingID int64) (*store.Account, error) {
+	account, err := s.accountStore.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("bank account not found")
+	}
+	if account.BuildingID != buildingID {
+		return nil, fmt.Errorf("bank account does not belong to this building")
+	}
+	return account, nil
+}
+
+func (s *BankStatementService) lineWithStatus(ctx context.Context, lineID int64, status string) (*store.BankStatementLine, error) {
+	line, err := s.bankStatementStore.GetLineByID(ctx, lineID)
+	if err != nil {
+		return nil, err
+	}
+	if line.Status != status {
+		return nil, fmt.Errorf("statement line is already %s", line.Status)
+	}
+	return line, nil
+}
+
+func (s *BankStatementService) lineWithRule(ctx context.Context, lineID int64) (*store.BankStatementLine, *store.BankRule, error) {
+	line, err := s.lineWithStatus(ctx, lineID, "new")
+	if err != nil {
+		return nil, nil, err
+	}
+	if line.RuleID == nil {
+		return nil, nil, fmt.Errorf("no bank rule matched this statement line")
+	}
+
+	rule, err := s.bankRuleStore.GetByID(ctx, *line.RuleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return line, rule, nil
+}
+
+// learnRule saves a rule that books lines like this one the way the user just did, and tags the line with it
+func (s *BankStatementService) learnRule(ctx context.Context, tx *sql.Tx, line *store.BankStatementLine, rule *store.BankRule) error {
+	contains := learnedDescription(line.Description)
+	if contains == "" {
+		return fmt.Errorf("cannot learn a bank rule from a line without a description")
+	}
+
+	rule.BuildingID = line.BuildingID
+	rule.Name = contains
+	rule.Priority = 100
+	rule.BankAccountID = &line.AccountID
+	rule.DescriptionContains = &contains
+	rule.Status = "1"
+
+	if err := s.bankRuleStore.Create(ctx, tx, rule); err != nil {
+		return err
+	}
+	return s.bankStatementStore.SetLineRule(ctx, tx, line.ID, &rule.ID)
+}
+
+// markCreated links the line to the bank split of the document just created for it. A check
+// waiting for approval has no splits yet, so the line holds on to its transaction alone.
+func (s *BankStatementService) markCreated(ctx context.Context, tx *sql.Tx, line *store.BankStatementLine, transactionID int64) error {
+	splitID, err := s.bankStatementStore.GetTransactionSplitID(ctx, tx, transactionID, line.AccountID)
+	if err == store.ErrNotFound {
+		return s.bankStatementStore.UpdateLineStatus(ctx, tx, line.ID, "new", "created", nil, &transactionID)
+	}
+	if err != nil {
+		return fmt.Errorf("posted document has no split on the bank account: %v", err)
+	}
+	return s.bankStatementStore.UpdateLineStatus(ctx, tx, line.ID, "new", "created", &splitID, &transactionID)
+}
+
+// checkRequestForLine builds the check that books a withdrawal line against one expense account
+func checkRequestForLine(line *store.BankStatementLine, req dto.CreateCheckFromStatementLineRequest) (*dto.CreateCheckRequest, error) {
+	if line.AmountCents >= 0 {
+		return nil, fmt.Errorf("only withdrawals can be booked as checks")
+	}
+
+	amount := float64(-line.AmountCents) / float64(money.MoneyScale)
+	memo := line.Description
+	if req.Memo != nil {
+		memo = *req.Memo
+	}
+	reference := line.Reference
+	description := line.Description
+
+	return &dto.CreateCheckRequest{
+		CheckPayloadDTO: dto.CheckPayloadDTO{
+			CheckDate:        line.Date,
+			ReferenceNumber:  &reference,
+			PaymentAccountID: line.AccountID,
+			BuildingID:       line.BuildingID,
+			Memo:             &memo,
+			TotalAmount:      amount,
+			ExpenseLines: []dto.ExpenseLineInput{{
+				AccountID:   req.ExpenseAccountID,
+				UnitID:      req.UnitID,
+				PeopleID:    req.PeopleID,
+				Description: &description,
+				Amount:      amount,
+			}},
+		},
+	}, nil
+}
+
+// receiptRequestForLine builds the sales receipt that books a deposit line for one item
+func receiptRequestForLine(line *store.BankStatementLine, req dto.CreateReceiptFromStatementLineRequest) (*dto.CreateSalesReceiptRequest, error) {
+	if line.AmountCents <= 0 {
+		return nil, fmt.Errorf("only deposits can be booked as receipts")
+	}
+
+	amount := float64(line.AmountCents) / float64(money.MoneyScale)
+	description := req.Description
+	if description == "" {
+		description = line.Description
+	}
+
+	return &dto.CreateSalesReceiptRequest{
+		SalesReceiptPayload: dto.SalesReceiptPayload{
+			ReceiptNo:   req.ReceiptNo,
+			ReceiptDate: line.Date,
+			UnitID:      req.UnitID,
+			PeopleID:    req.PeopleID,
+			AccountID:   line.AccountID,
+			Amount:      amount,
+			Description: description,
+			BuildingID:  line.BuildingID,
+			Items: []dto.ReceiptItemInput{{
+				ItemID: req.ItemID,
+				Total:  &amount,
+			}},
+		},
+	}, nil
+}
+
+func ruleCheckRequest(rule *store.BankRule) dto.CreateCheckFromStatementLineRequest {
+	req := dto.CreateCheckFromStatementLineRequest{UnitID: rule.UnitID, PeopleID: rule.PeopleID}
+	if rule.TargetAccountID != nil {
+		req.ExpenseAccountID = *rule.TargetAccountID
+	}
+	return req
+}
+
+func ruleReceiptRequest(rule *store.BankRule, receiptNo int) dto.CreateReceiptFromStatementLineRequest {
+	req := dto.CreateReceiptFromStatementLineRequest{ReceiptNo: receiptNo, UnitID: rule.UnitID, PeopleID: rule.PeopleID}
+	if rule.ItemID != nil {
+		req.ItemID = int(*rule.ItemID)
+	}
+	return req
+}
+
+// pickMatchCandidate returns the only split whose reference matches the line, or the only
+// candidate when none carries a matching reference; anything ambiguous is left to the user
+func pickMatchCandidate(line *store.BankStatementLine, candidates []store.BankMatchCandidate) *store.BankMatchCandidate {
+	var referenced []store.BankMatchCandidate
+	for _, c := range candidates {
+		if referenceMatches(line, c) {
+			referenced = append(referenced, c)
+		}
+	}
+
+	switch {
+	case len(referenced) == 1:
+		return &referenced[0]
+	case len(referenced) == 0 && len(candidates) == 1:
+		return &candidates[0]
+	}
+	return nil
+}
+
+// referenceMatches reports whether the ledger reference appears on the statement line.
+// Very short references (check numbers like "1") only count on an exact reference match.
+func referenceMatches(line *store.BankStatementLine, c store.BankMatchCandidate) bool {
+	ref := strings.TrimSpace(c.Reference)
+	if ref == "" {
+		return false
+	}
+	if strings.EqualFold(strings.TrimSpace(line.Reference), ref) {
+		return true
+	}
+	return len(ref) >= 3 && strings.Contains(strings.ToLower(line.Description), strings.ToLower(ref))
+}
+
+func truncate(s string, n int) string {
+	if len([]rune(s)) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -0,0 +1,465 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+/*
+|--------------------------------------------------------------------------
+| Interfaces
+|--------------------------------------------------------------------------
+*/
+
+type BillPaymentRunStore interface {
+	GetAll(ctx context.Context, buildingID int64) ([]store.BillPaymentRun, error)
+	GetByID(ctx context.Context, id int64) (*store.BillPaymentRun, error)
+	GetPayableBills(ctx context.Context, buildingID int64, dueOnOrBefore *string) ([]store.PayableBill, error)
+	NextCheckNoTx(ctx context.Context, tx *sql.Tx, accountID int64) (int, error)
+	CheckNosUsedTx(ctx context.Context, tx *sql.Tx, accountID int64, from, to int) (bool, error)
+	Create(ctx context.Context, tx *sql.Tx, r *store.BillPaymentRun) error
+	GetLines(ctx context.Context, runID int64) ([]store.BillPaymentRunLine, error)
+}
+
+/*
+|--------------------------------------------------------------------------
+| Service
+|--------------------------------------------------------------------------
+*/
+
+// BillPaymentRunService pays many open bills at once: one numbered check per vendor,
+// each allocated across that vendor's bills with one bill payment per bill.
+type BillPaymentRunService struct {
+	db                  *sql.DB
+	billPaymentRunStore BillPaymentRunStore
+	billPaymentStore    BillPaymentStore
+	billStore           BillStore
+	transactionStore    TransactionStore
+	splitStore          SplitStore
+	accountStore        AccountStore
+	peopleStore         PeopleStore
+	currencyService     *CurrencyService
+}
+
+/*
+|--------------------------------------------------------------------------
+| Constructor
+|--------------------------------------------------------------------------
+*/
+
+func NewBillPaymentRunService(
+	db *sql.DB,
+	billPaymentRunStore BillPaymentRunStore,
+	billPaymentStore BillPaymentStore,
+	billStore BillStore,
+	transactionStore TransactionStore,
+	splitStore SplitStore,
+	accountStore AccountStore,
+	peopleStore PeopleStore,
+	currencyService *CurrencyService,
+) *BillPaymentRunService {
+	return &BillPaymentRunService{
+		db:                  db,
+		billPaymentRunStore: billPaymentRunStore,
+		billPaymentStore:    billPaymentStore,
+		billStore:           billStore,
+		transactionStore:    transactionStore,
+		splitStore:          splitStore,
+		accountStore:        accountStore,
+		peopleStore:         peopleStore,
+		currencyService:     currencyService,
+	}
+}
+
+/*
+|--------------------------------------------------------------------------
+| Queries
+|--------------------------------------------------------------------------
+*/
+
+func (s *BillPaymentRunService) GetAll(ctx context.Context, buildingID int64) ([]dto.BillPaymentRunDto, error) {
+	runs, err := s.billPaymentRunStore.GetAll(ctx, buildingID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.MapBillPaymentRunsToDto(runs), nil
+}
+
+func (s *BillPaymentRunService) GetByID(ctx context.Context, id int64) (*dto.BillPaymentRunResponse, error) {
+	run, err := s.billPaymentRunStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.billPaymentRunStore.GetLines(ctx, run.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildBillPaymentRunResponse(*run, lines), nil
+}
+
+// GetPayableBills lists the open vendor bills a run would pick up, grouped by vendor
+func (s *BillPaymentRunService) GetPayableBills(ctx context.Context, buildingID int64, dueOnOrBefore *string) (*dto.PayableBillsResponse, error) {
+	bills, err := s.billPaymentRunStore.GetPayableBills(ctx, buildingID, dueOnOrBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.PayableBillsResponse{
+		DueOnOrBefore: dueOnOrBefore,
+		Vendors:       []dto.PayableVendorDto{},
+	}
+
+	total := int64(0)
+	vendorTotal := int64(0)
+	for i, bill := range bills {
+		if i == 0 || bills[i-1].PeopleID != bill.PeopleID {
+			response.Vendors = append(response.Vendors, dto.PayableVendorDto{
+				PeopleID:   bill.PeopleID,
+				PeopleName: bill.PeopleName,
+				Bills:      []dto.PayableBillDto{},
+			})
+			vendorTotal = 0
+		}
+		vendor := &response.Vendors[len(response.Vendors)-1]
+		vendor.Bills = append(vendor.Bills, dto.MapPayableBillToDto(bill))
+		vendorTotal += bill.BalanceCents
+		vendor.Total = money.FormatMoneyFromCents(vendorTotal)
+		total += bill.BalanceCents
+	}
+	response.Total = money.FormatMoneyFromCents(total)
+
+	return response, nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Commands
+|--------------------------------------------------------------------------
+*/
+
+func (s *BillPaymentRunService) Create(ctx context.Context, req dto.CreateBillPaymentRunRequest) (*dto.BillPaymentRunResponse, error) {
+	bankAccount, err := s.accountStore.GetByID(ctx, req.BankAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("bank account not found")
+	}
+	if bankAccount.BuildingID != req.BuildingID {
+		return nil, fmt.Errorf("bank account does not belong to this building")
+	}
+
+	payments, err := s.selectPayments(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// tax is withheld from each check at the vendor's rate, the same as a single bill payment
+	for i := range payments {
+		payment := &payments[i]
+		payment.rateScaled, payment.withholdingAccountID, err = vendorWithholding(ctx, s.peopleStore, &payment.peopleID)
+		if err != nil {
+			return nil, err
+		}
+		for _, cents := range payment.amountCents {
+			withheld, err := calculateWithholding(cents, payment.rateScaled)
+			if err != nil {
+				return nil, err
+			}
+			payment.withheldCents = append(payment.withheldCents, withheld)
+		}
+	}
+
+	run := &store.BillPaymentRun{
+		BuildingID:      req.BuildingID,
+		BankAccountID:   bankAccount.ID,
+		BankAccountName: bankAccount.AccountName,
+		Date:            req.Date,
+		DueOnOrBefore:   req.DueOnOrBefore,
+		Memo:            req.Memo,
+		PaymentCount:    len(payments),
+		UserID:          1, // TODO: get user id from jwt
+	}
+	for _, payment := range payments {
+		run.AmountCents += payment.checkCents()
+	}
+
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		checkNo, err := s.billPaymentRunStore.NextCheckNoTx(ctx, tx, bankAccount.ID)
+		if err != nil {
+			return err
+		}
+		if req.FirstCheckNo != nil {
+			lastCheckNo := *req.FirstCheckNo + len(payments) - 1
+			used, err := s.billPaymentRunStore.CheckNosUsedTx(ctx, tx, bankAccount.ID, *req.FirstCheckNo, lastCheckNo)
+			if err != nil {
+				return err
+			}
+			if used {
+				return fmt.Errorf("check numbers %d to %d overlap checks already written from %s", *req.FirstCheckNo, lastCheckNo, bankAccount.AccountName)
+			}
+			checkNo = *req.FirstCheckNo
+		}
+
+		if err := s.billPaymentRunStore.Create(ctx, tx, run); err != nil {
+			return err
+		}
+
+		for _, payment := range payments {
+			if err := s.createVendorPayment(ctx, tx, run, payment, checkNo); err != nil {
+				return err
+			}
+			checkNo++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, run.ID)
+}
+
+/*
+|--------------------------------------------------------------------------
+| Helpers
+|--------------------------------------------------------------------------
+*/
+
+// vendorPayment is the check a run writes to one vendor
+type vendorPayment struct {
+	peopleID    int64
+	bills       []store.PayableBill
+	amountCents []int64 // paid on each bill
+
+	rateScaled           int64
+	withholdingAccountID *int64
+	withheldCents        []int64 // withheld from what is paid on each bill
+}
+
+func (p vendorPayment) totalCents() int64 {
+	total := int64(0)
+	for _, cents := range p.amountCents {
+		total += cents
+	}
+	return total
+}
+
+func (p vendorPayment) totalWithheldCents() int64 {
+	total := int64(0)
+	for _, cents := range p.withheldCents {
+		total += cents
+	}
+	return total
+}
+
+// checkCents is what the check is written for: the bills paid less the tax withheld
+func (p vendorPayment) checkCents() int64 {
+	return p.totalCents() - p.totalWithheldCents()
+}
+
+// selectPayments picks the bills to pay and groups them into one payment per vendor, in vendor name order
+func (s *BillPaymentRunService) selectPayments(ctx context.Context, req dto.CreateBillPaymentRunRequest) ([]vendorPayment, error) {
+	payable, err := s.billPaymentRunStore.GetPayableBills(ctx, req.BuildingID, req.DueOnOrBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	vendors := make(map[int64]bool, len(req.PeopleIDs))
+	for _, peopleID := range req.PeopleIDs {
+		vendors[peopleID] = true
+	}
+
+	// requested amount per bill; nil pays the open balance
+	selected := make(map[int64]*float64, len(req.Bills))
+	for _, input := range req.Bills {
+		if _, ok := selected[input.BillID]; ok {
+			return nil, fmt.Errorf("bill %d is selected more than once", input.BillID)
+		}
+		selected[input.BillID] = input.Amount
+	}
+
+	payments := []vendorPayment{}
+	found := 0
+	for _, bill := range payable {
+		if len(vendors) > 0 && !vendors[bill.PeopleID] {
+			continue
+		}
+
+		amountCents := bill.BalanceCents
+		if len(selected) > 0 {
+			amount, ok := selected[bill.BillID]
+			if !ok {
+				continue
+			}
+			found++
+			if amount != nil {
+				amountCents, err = money.ParseUSDAmount(strconv.FormatFloat(*amount, 'f', -1, 64))
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse amount for bill %s: %v", bill.BillNo, err)
+				}
+				if amountCents > bill.BalanceCents {
+					return nil, fmt.Errorf("amount for bill %s exceeds its balance of %s", bill.BillNo, money.FormatMoneyFromCents(bill.BalanceCents))
+				}
+			}
+		}
+
+		if len(payments) == 0 || payments[len(payments)-1].peopleID != bill.PeopleID {
+			payments = append(payments, vendorPayment{peopleID: bill.PeopleID})
+		}
+		payment := &payments[len(payments)-1]
+		payment.bills = append(payment.bills, bill)
+		payment.amountCents = append(payment.amountCents, amountCents)
+	}
+
+	if found < len(selected) {
+		return nil, fmt.Errorf("some selected bills are not open vendor bills matching the run's filters")
+	}
+	if len(payments) == 0 {
+		return nil, fmt.Errorf("no open bills to pay")
+	}
+
+	return payments, nil
+}
+
+// createVendorPayment posts one check: A/P is debited per bill, the bank credited for the check
+// and the withholding liability for the tax kept back from the vendor
+func (s *BillPaymentRunService) createVendorPayment(ctx context.Context, tx *sql.Tx, run *store.BillPaymentRun, payment vendorPayment, checkNo int) error {
+	reference := strconv.Itoa(checkNo)
+	peopleID := payment.peopleID
+
+	transaction := &store.Transaction{
+		Type:              "bill_payment",
+		TransactionDate:   run.Date,
+		TransactionNumber: reference,
+		Memo:              run.Memo,
+		Status:            "1",
+		BuildingID:        run.BuildingID,
+		UserID:            1, // TODO: get user id from jwt
+		UnitID:            nil,
+	}
+
+	transactionID, err := s.transactionStore.Create(ctx, tx, transaction)
+	if err != nil {
+		return err
+	}
+
+	splits := []store.Split{}
+	for i, bill := range payment.bills {
+		// re-read under lock, the balance may have moved since the bills were selected
+		balanceCents, err := s.billStore.GetOpenBalanceTx(ctx, tx, bill.BillID)
+		if err != nil {
+			return err
+		}
+		if payment.amountCents[i] > balanceCents {
+			return fmt.Errorf("bill %s balance changed to %s while the run was being created", bill.BillNo, money.FormatMoneyFromCents(balanceCents))
+		}
+		splits = append(splits, newDebitSplit(*transactionID, bill.APAccountID, payment.amountCents[i], bill.UnitID, &peopleID))
+	}
+	splits = append(splits, newCreditSplit(*transactionID, run.BankAccountID, payment.checkCents(), nil, &peopleID))
+	if withheldCents := payment.totalWithheldCents(); withheldCents > 0 {
+		splits = append(splits, newCreditSplit(*transactionID, *payment.withholdingAccountID, withheldCents, nil, &peopleID))
+	}
+
+	if err := validateBalanced(splits); err != nil {
+		return err
+	}
+
+	if err := s.currencyService.requireBaseCurrency(ctx, splits); err != nil {
+		return err
+	}
+
+	for _, split := range splits {
+		if err := s.splitStore.Create(ctx, tx, &split); err != nil {
+			return err
+		}
+	}
+
+	for i, bill := range payment.bills {
+		billPayment := &store.BillPayment{
+			TransactionID: *transactionID,
+			Reference:     reference,
+			Date:          run.Date,
+			BillID:        bill.BillID,
+			UserID:        1, // TODO: get user id from jwt
+			AccountID:     run.BankAccountID,
+			RunID:         &run.ID,
+			CheckNo:       &checkNo,
+			Amount:        float64(payment.amountCents[i]) / float64(money.MoneyScale),
+			AmountCents:   payment.amountCents[i],
+			Status:        "1",
+
+			WithholdingRateScaled: payment.rateScaled,
+			WithholdingCents:      payment.withheldCents[i],
+			WithholdingAccountID:  payment.withholdingAccountID,
+		}
+		if _, err := s.billPaymentStore.Create(ctx, tx, billPayment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// buildBillPaymentRunResponse turns the run's bill payments (in check order) into the register and printable checks
+func buildBillPaymentRunResponse(run store.BillPaymentRun, lines []store.BillPaymentRunLine) *dto.BillPaymentRunResponse {
+	response := &dto.BillPaymentRunResponse{
+		Run:      dto.MapBillPaymentRunToDto(run),
+		Register: []dto.BillPaymentRegisterLine{},
+		Checks:   []dto.PrintableCheckDto{},
+	}
+
+	checkCents, withheldCents := int64(0), int64(0)
+	for i, line := range lines {
+		if i == 0 || lines[i-1].CheckNo != line.CheckNo {
+			response.Register = append(response.Register, dto.BillPaymentRegisterLine{
+				CheckNo:       line.CheckNo,
+				TransactionID: line.TransactionID,
+				Date:          line.Date,
+				PeopleID:      line.PeopleID,
+				PayeeName:     line.PeopleName,
+			})
+			response.Checks = append(response.Checks, dto.PrintableCheckDto{
+				CheckNo:         line.CheckNo,
+				Date:            line.Date,
+				PayeeName:       line.PeopleName,
+				Memo:            run.Memo,
+				BankAccountName: run.BankAccountName,
+				Remittance:      []dto.RemittanceLineDto{},
+			})
+			checkCents, withheldCents = 0, 0
+		}
+
+		checkCents += line.PaidCents - line.WithheldCents
+		withheldCents += line.WithheldCents
+
+		register := &response.Register[len(response.Register)-1]
+		register.BillCount++
+		register.Amount = money.FormatMoneyFromCents(checkCents)
+
+		check := &response.Checks[len(response.Checks)-1]
+		check.Amount = money.FormatMoneyFromCents(checkCents)
+		check.AmountInWords = money.AmountInWords(checkCents)
+		if withheldCents > 0 {
+			check.Withholding = money.FormatMoneyFromCents(withheldCents)
+		}
+		check.Remittance = append(check.Remittance, dto.RemittanceLineDto{
+			BillID:      line.BillID,
+			BillNo:      line.BillNo,
+			BillDate:    line.BillDate,
+			DueDate:     line.DueDate,
+			Description: line.Description,
+			BillAmount:  money.FormatMoneyFromCents(line.BillCents),
+			AmountPaid:  money.FormatMoneyFromCents(line.PaidCents),
+			Withholding: money.FormatMoneyFromCents(line.WithheldCents),
+		})
+	}
+
+	return response
+}
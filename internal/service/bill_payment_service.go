@@ -34,12 +34,16 @@ type BillPaymentStore interface {
 */
 
 type BillPaymentService struct {
-	db               *sql.DB
-	billPaymentStore BillPaymentStore
-	transactionStore TransactionStore
-	accountStore     AccountStore
-	billStore        BillStore
-	splitStore       SplitStore
+	db                *sql.DB
+	billPaymentStore  BillPaymentStore
+	transactionStore  TransactionStore
+	accountStore      AccountStore
+	billStore         BillStore
+	splitStore        SplitStore
+	buildingStore     BuildingStore
+	peopleStore       PeopleStore
+	vendorCreditStore VendorCreditStore
+	currencyService   *CurrencyService
 }
 
 /*
@@ -55,14 +59,22 @@ func NewBillPaymentService(
 	accountStore AccountStore,
 	billStore BillStore,
 	splitStore SplitStore,
+	buildingStore BuildingStore,
+	peopleStore PeopleStore,
+	vendorCreditStore VendorCreditStore,
+	currencyService *CurrencyService,
 ) *BillPaymentService {
 	return &BillPaymentService{
-		db:               db,
-		billPaymentStore: billPaymentStore,
-		transactionStore: transactionStore,
-		accountStore:     accountStore,
-		billStore:        billStore,
-		splitStore:       splitStore,
+		db:                db,
+		billPaymentStore:  billPaymentStore,
+		transactionStore:  transactionStore,
+		accountStore:      accountStore,
+		billStore:         billStore,
+		splitStore:        splitStore,
+		buildingStore:     buildingStore,
+		peopleStore:       peopleStore,
+		vendorCreditStore: vendorCreditStore,
+		currencyService:   currencyService,
 	}
 }
 
@@ -151,6 +163,15 @@ func (s *BillPaymentService) Create(ctx context.Context, paymentDTO dto.CreateBi
 			return fmt.Errorf("bill does not belong to the specified building")
 		}
 
+		if bill.ApprovalStatus != "approved" {
+			return fmt.Errorf("bill is %s and cannot be paid until it is approved", bill.ApprovalStatus)
+		}
+
+		building, err := s.buildingStore.GetByID(ctx, paymentDTO.BuildingID)
+		if err != nil {
+			return fmt.Errorf("building not found: %v", err)
+		}
+
 		apAccount, err := s.accountStore.GetByID(ctx, bill.APAccountID)
 		if err != nil {
 			return fmt.Errorf("A/P account not found: %v", err)
@@ -162,9 +183,62 @@ func (s *BillPaymentService) Create(ctx context.Context, paymentDTO dto.CreateBi
 			return fmt.Errorf("asset account not found: %v", err)
 		}
 
-		// Validate amount
-		if paymentDTO.Amount == 0 {
-			return fmt.Errorf("amount cannot be zero")
+		amountStr := strconv.FormatFloat(paymentDTO.Amount, 'f', -1, 64)
+		amountCents, err := money.ParseUSDAmount(amountStr)
+		if err != nil {
+			return fmt.Errorf("failed to parse amount: %v", err)
+		}
+
+		balanceCents, err := s.billStore.GetOpenBalanceTx(ctx, tx, bill.ID)
+		if err != nil {
+			return err
+		}
+
+		// a foreign currency bill is paid in its currency; the asset pays at the day's rate and
+		// the difference to what the payment takes off the bill is a realized gain or loss.
+		// Any excess on a base currency bill is held as a vendor prepayment and recorded as a
+		// vendor credit on the same transaction, so it can be applied to the vendor's next bill.
+		var rate fxRate
+		var appliedCents, excessCents, cashCents int64
+		if bill.Currency != nil {
+			rate, err = s.currencyService.documentRate(ctx, paymentDTO.BuildingID, bill.Currency, paymentDTO.Date, paymentDTO.ExchangeRate)
+			if err != nil {
+				return err
+			}
+			settlement, err := s.currencyService.settle(rate, bill.AmountCents, *bill.ForeignAmountCents, balanceCents, amountCents)
+			if err != nil {
+				return err
+			}
+			appliedCents, cashCents = settlement.ReliefCents, settlement.CashCents
+		} else {
+			appliedCents, excessCents, err = splitOverpayment(balanceCents, amountCents, building.AllowOverpayment, building.VendorPrepaymentsAccountID, "vendor prepayments")
+			if err != nil {
+				return err
+			}
+			cashCents = amountCents
+		}
+		if excessCents > 0 && bill.PeopleID == nil {
+			return fmt.Errorf("overpayment needs a bill with a vendor to hold the credit")
+		}
+		gainCents := appliedCents + excessCents - cashCents
+
+		// tax withheld from the vendor is only taken from the part that settles the bill
+		rateScaled, withholdingAccountID, err := vendorWithholding(ctx, s.peopleStore, bill.PeopleID)
+		if err != nil {
+			return err
+		}
+		withheld, err := calculateWithholding(amountCents-excessCents, rateScaled) // in the bill's currency
+		if err != nil {
+			return err
+		}
+		withheldCents, err := rate.toBase(withheld)
+		if err != nil {
+			return err
+		}
+
+		assetForeignCents, err := s.currencyService.accountForeignCents(ctx, assetAccount.ID, rate.Currency, withheld-amountCents)
+		if err != nil {
+			return err
 		}
 
 		// Create transaction
@@ -183,48 +257,64 @@ func (s *BillPaymentService) Create(ctx context.Context, paymentDTO dto.CreateBi
 			return err
 		}
 
-		amountStr := strconv.FormatFloat(paymentDTO.Amount, 'f', -1, 64)
-		amountCents, err := money.ParseUSDAmount(amountStr)
-		if err != nil {
-			return fmt.Errorf("failed to parse amount: %v", err)
-		}
-
 		// Create splits
-		// Debit Asset Account (cash/bank)
-		debitSplit := store.Split{
-			TransactionID: *transactionID,
-			AccountID:     assetAccount.ID,
-			Debit:         &paymentDTO.Amount,
-			DebitCents:    &amountCents,
-			Credit:        nil,
-			CreditCents:   nil,
-			UnitID:        bill.UnitID,
-			PeopleID:      bill.PeopleID,
-			Status:        "1",
+		// Debit A/P (liability decreases) and vendor prepayments, credit the asset account (cash/bank)
+		// and the withholding liability for the tax kept back from the vendor
+		splits := []store.Split{}
+		if appliedCents > 0 {
+			splits = append(splits, newDebitSplit(*transactionID, apAccount.ID, appliedCents, bill.UnitID, bill.PeopleID))
 		}
-		err = s.splitStore.Create(ctx, tx, &debitSplit)
+		if excessCents > 0 {
+			splits = append(splits, newDebitSplit(*transactionID, *building.VendorPrepaymentsAccountID, excessCents, bill.UnitID, bill.PeopleID))
+		}
+		assetSplit := newCreditSplit(*transactionID, assetAccount.ID, cashCents-withheldCents, bill.UnitID, bill.PeopleID)
+		assetSplit.ForeignCents = assetForeignCents
+		splits = append(splits, assetSplit)
+		if withheldCents > 0 {
+			splits = append(splits, newCreditSplit(*transactionID, *withholdingAccountID, withheldCents, bill.UnitID, bill.PeopleID))
+		}
+		fxSplits, err := s.currencyService.realizedSplit(ctx, *transactionID, paymentDTO.BuildingID, gainCents, bill.UnitID, bill.PeopleID)
 		if err != nil {
 			return err
 		}
+		splits = append(splits, fxSplits...)
 
-		// Credit A/P Account (liability decreases)
-		creditSplit := store.Split{
-			TransactionID: *transactionID,
-			AccountID:     apAccount.ID,
-			Credit:        &paymentDTO.Amount,
-			CreditCents:   &amountCents,
-			Debit:         nil,
-			DebitCents:   nil,
-			UnitID:        bill.UnitID,
-			PeopleID:      bill.PeopleID,
-			Status:        "1",
-		}
-		err = s.splitStore.Create(ctx, tx, &creditSplit)
-		if err != nil {
+		if err := validateBalanced(splits); err != nil {
 			return err
 		}
 
+		for _, split := range splits {
+			if err := s.splitStore.Create(ctx, tx, &split); err != nil {
+				return err
+			}
+		}
+
+		if excessCents > 0 {
+			err := s.vendorCreditStore.Create(ctx, tx, &store.VendorCredit{
+				TransactionID:    *transactionID,
+				Reference:        paymentDTO.Reference,
+				Date:             paymentDTO.Date,
+				PeopleID:         *bill.PeopleID,
+				APAccountID:      apAccount.ID,
+				ExpenseAccountID: *building.VendorPrepaymentsAccountID,
+				UnitID:           bill.UnitID,
+				AmountCents:      excessCents,
+				Memo:             "Overpayment on bill " + bill.BillNo,
+				UserID:           1, // TODO: get user id from jwt
+				BuildingID:       paymentDTO.BuildingID,
+				Status:           "1",
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if appliedCents == 0 {
+			return nil
+		}
+
 		// Create bill payment
+		exchangeRateScaled, foreignAmountCents := rate.foreignAmounts(amountCents)
 		billPayment := &store.BillPayment{
 			TransactionID: *transactionID,
 			Reference:     paymentDTO.Reference,
@@ -232,9 +322,18 @@ func (s *BillPaymentService) Create(ctx context.Context, paymentDTO dto.CreateBi
 			BillID:        int64(paymentDTO.BillID),
 			UserID:        1, // TODO: get user id from jwt
 			AccountID:     int64(paymentDTO.AccountID),
-			Amount:        paymentDTO.Amount,
-			AmountCents:   amountCents,
+			Amount:        float64(appliedCents) / float64(money.MoneyScale),
+			AmountCents:   appliedCents,
 			Status:        "1",
+
+			WithholdingRateScaled: rateScaled,
+			WithholdingCents:      withheldCents,
+			WithholdingAccountID:  withholdingAccountID,
+
+			Currency:           rate.Currency,
+			ExchangeRateScaled: exchangeRateScaled,
+			ForeignAmountCents: foreignAmountCents,
+			FXGainLossCents:    gainCents,
 		}
 
 		_, err = s.billPaymentStore.Create(ctx, tx, billPayment)
@@ -264,6 +363,30 @@ func (s *BillPaymentService) Update(
 			return fmt.Errorf("bill payment not found: %v", err)
 		}
 
+		// a run check pays several bills from one transaction, rebuilding it from this bill would drop the others
+		if existing.RunID != nil {
+			return fmt.Errorf("bill payment was made by a bill payment run and cannot be edited")
+		}
+
+		if existing.Currency != nil {
+			return fmt.Errorf("bill payment was made in a foreign currency and cannot be edited")
+		}
+
+		// the excess over the bill is a vendor credit on the same transaction, rebuilding it from the bill would drop it
+		overpaid, err := s.vendorCreditStore.ExistsByTransactionIDTx(ctx, tx, existing.TransactionID)
+		if err != nil {
+			return err
+		}
+		if overpaid {
+			return fmt.Errorf("bill payment has an overpayment held as a vendor credit and cannot be edited")
+		}
+
+		if reason, err := s.splitStore.GetLockReason(ctx, tx, existing.TransactionID); err != nil {
+			return err
+		} else if reason != "" {
+			return fmt.Errorf("bill payment cannot be edited: %s", reason)
+		}
+
 		// Validate account
 		if _, err := s.accountStore.GetByID(ctx, int64(req.AccountID)); err != nil {
 			return fmt.Errorf("account not found")
@@ -275,6 +398,24 @@ func (s *BillPaymentService) Update(
 			return fmt.Errorf("failed to parse amount: %v", err)
 		}
 
+		// the payment being edited no longer counts against the balance; a voided one never did
+		balanceCents, err := s.billStore.GetOpenBalanceTx(ctx, tx, existing.BillID)
+		if err != nil {
+			return err
+		}
+		if existing.Status == "1" {
+			balanceCents += existing.AmountCents
+		}
+		if _, _, err := splitOverpayment(balanceCents, amountCents, false, nil, ""); err != nil {
+			return err
+		}
+
+		// withholding is recomputed at the rate the payment was made under
+		withheldCents, err := calculateWithholding(amountCents, existing.WithholdingRateScaled)
+		if err != nil {
+			return err
+		}
+
 		// Update bill payment
 		updatedPayment := &store.BillPayment{
 			ID:            paymentID,
@@ -287,6 +428,8 @@ func (s *BillPaymentService) Update(
 			Amount:        req.Amount,
 			AmountCents:   amountCents,
 			Status:        strconv.Itoa(req.Status),
+
+			WithholdingCents: withheldCents,
 		}
 
 		if _, err := s.billPaymentStore.Update(ctx, tx, updatedPayment); err != nil {
@@ -327,40 +470,57 @@ func (s *BillPaymentService) Update(
 		}
 
 		// Recreate splits
-		// Debit Asset Account
-		debitSplit := store.Split{
-			TransactionID: existing.TransactionID,
-			AccountID:     int64(req.AccountID),
-			Debit:         &req.Amount,
-			DebitCents:    &amountCents,
-			Credit:        nil,
-			CreditCents:   nil,
-			UnitID:        bill.UnitID,
-			PeopleID:      bill.PeopleID,
-			Status:        "1",
+		// Debit A/P Account, credit the asset account and the withholding liability
+		splits := []store.Split{
+			newDebitSplit(existing.TransactionID, apAccount.ID, amountCents, bill.UnitID, bill.PeopleID),
+			newCreditSplit(existing.TransactionID, int64(req.AccountID), amountCents-withheldCents, bill.UnitID, bill.PeopleID),
 		}
-		err = s.splitStore.Create(ctx, tx, &debitSplit)
-		if err != nil {
-			return err
+		if withheldCents > 0 {
+			splits = append(splits, newCreditSplit(existing.TransactionID, *existing.WithholdingAccountID, withheldCents, bill.UnitID, bill.PeopleID))
 		}
-
-		// Credit A/P Account
-		creditSplit := store.Split{
-			TransactionID: existing.TransactionID,
-			AccountID:     apAccount.ID,
-			Credit:        &req.Amount,
-			CreditCents:   &amountCents,
-			Debit:         nil,
-			DebitCents:   nil,
-			UnitID:        bill.UnitID,
-			PeopleID:      bill.PeopleID,
-			Status:        "1",
-		}
-		err = s.splitStore.Create(ctx, tx, &creditSplit)
-		if err != nil {
-			return err
+		for _, split := range splits {
+			if err := s.splitStore.Create(ctx, tx, &split); err != nil {
+				return err
+			}
 		}
 
 		return nil
 	})
 }
+
+/*
+|---------------------------------------------------------------------------
+| Helpers
+|---------------------------------------------------------------------------
+*/
+
+// vendorWithholding returns the withholding rate and liability account set on the bill's vendor.
+// The rate is 0 when the bill has no vendor or the vendor has no withholding.
+func vendorWithholding(ctx context.Context, peopleStore PeopleStore, peopleID *int64) (int64, *int64, error) {
+	if peopleID == nil {
+		return 0, nil, nil
+	}
+
+	vendor, err := peopleStore.GetByID(ctx, *peopleID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("vendor not found: %v", err)
+	}
+
+	if vendor.WithholdingRateScaled == 0 {
+		return 0, nil, nil
+	}
+	if vendor.WithholdingAccountID == nil {
+		return 0, nil, fmt.Errorf("vendor %s has a withholding rate but no withholding account", vendor.Name)
+	}
+
+	return vendor.WithholdingRateScaled, vendor.WithholdingAccountID, nil
+}
+
+// calculateWithholding works out the tax withheld from an amount settled on a bill
+func calculateWithholding(amountCents, rateScaled int64) (int64, error) {
+	withheld, err := money.MulDiv(amountCents, rateScaled, 100*money.RateScale)
+	if err != nil {
+		return 0, fmt.Errorf("withholding out of allowed range: %v", err)
+	}
+	return withheld, nil
+}
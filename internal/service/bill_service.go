@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
 	"strconv"
 
 	money "github.com/mysecodgit/go_accounting/internal/accounting"
@@ -23,6 +24,7 @@ type BillStore interface {
 	Create(ctx context.Context, tx *sql.Tx, b *store.Bill) (*int64, error)
 	Update(ctx context.Context, tx *sql.Tx, b *store.Bill) (*int64, error)
 	Delete(ctx context.Context, id int64) error
+	GetOpenBalanceTx(ctx context.Context, tx *sql.Tx, id int64) (int64, error)
 }
 
 type BillExpenseLineStore interface {
@@ -44,6 +46,11 @@ type BillService struct {
 	splitStore           SplitStore
 	transactionStore     TransactionStore
 	accountStore         AccountStore
+	approvalService      *ApprovalService
+	purchaseOrderService *PurchaseOrderService
+	inventoryService     *InventoryService
+	taxService           *TaxService
+	currencyService      *CurrencyService
 }
 
 /*
@@ -59,6 +66,11 @@ func NewBillService(
 	splitStore SplitStore,
 	transactionStore TransactionStore,
 	accountStore AccountStore,
+	approvalService *ApprovalService,
+	purchaseOrderService *PurchaseOrderService,
+	inventoryService *InventoryService,
+	taxService *TaxService,
+	currencyService *CurrencyService,
 ) *BillService {
 	return &BillService{
 		db:                   db,
@@ -67,6 +79,11 @@ func NewBillService(
 		splitStore:           splitStore,
 		transactionStore:     transactionStore,
 		accountStore:         accountStore,
+		approvalService:      approvalService,
+		purchaseOrderService: purchaseOrderService,
+		inventoryService:     inventoryService,
+		taxService:           taxService,
+		currencyService:      currencyService,
 	}
 }
 
@@ -139,102 +156,192 @@ func (s *BillService) GetByID(ctx context.Context, id int64) (*dto.BillResponseD
 
 func (s *BillService) Create(ctx context.Context, req dto.CreateBillRequest) error {
 	return withTx(s.db, ctx, func(tx *sql.Tx) error {
-		// Create transaction
-		transaction := &store.Transaction{
-			Type:              "bill",
-			TransactionDate:   req.BillDate,
-			TransactionNumber: req.BillNo,
-			Memo:              req.Description,
-			Status:            "1",
-			BuildingID:        req.BuildingID,
-			UserID:            1, // TODO: get user id from jwt
-			UnitID:            req.UnitID,
-		}
-		transactionID, err := s.transactionStore.Create(ctx, tx, transaction)
-		if err != nil {
-			return err
-		}
+		_, err := s.CreateTx(ctx, tx, req)
+		return err
+	})
+}
 
-		amountStr := strconv.FormatFloat(req.Amount, 'f', -1, 64)
+// CreateTx posts a bill inside an existing transaction and returns the new bill id
+func (s *BillService) CreateTx(ctx context.Context, tx *sql.Tx, req dto.CreateBillRequest) (*int64, error) {
+	if err := s.resolveItemLines(ctx, &req.BillPayloadDTO); err != nil {
+		return nil, err
+	}
+	if err := s.checkPaymentAccount(ctx, req.BillPayloadDTO); err != nil {
+		return nil, err
+	}
+
+	// Create transaction
+	transaction := &store.Transaction{
+		Type:              "bill",
+		TransactionDate:   req.BillDate,
+		TransactionNumber: req.BillNo,
+		Memo:              req.Description,
+		Status:            "1",
+		BuildingID:        req.BuildingID,
+		UserID:            1, // TODO: get user id from jwt
+		UnitID:            req.UnitID,
+	}
+	transactionID, err := s.transactionStore.Create(ctx, tx, transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	amountStr := strconv.FormatFloat(req.Amount, 'f', -1, 64)
+	amountCents, err := money.ParseUSDAmount(amountStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse amount: %v", err)
+	}
+	rate, err := s.currencyService.documentRate(ctx, req.BuildingID, req.Currency, req.BillDate, req.ExchangeRate)
+	if err != nil {
+		return nil, err
+	}
+	exchangeRateScaled, foreignAmountCents := rate.foreignAmounts(amountCents)
+	if amountCents, err = rate.toBase(amountCents); err != nil {
+		return nil, err
+	}
+
+	approvalStatus, err := s.approvalService.initialStatus(ctx, req.BuildingID, "bill", amountCents, req.Draft)
+	if err != nil {
+		return nil, err
+	}
+
+	if approvalStatus, err = s.matchPurchaseOrderTx(ctx, tx, req.BillPayloadDTO, 0, approvalStatus); err != nil {
+		return nil, err
+	}
+
+	// Create bill
+	bill := &store.Bill{
+		TransactionID:      *transactionID,
+		BillNo:             req.BillNo,
+		BillDate:           req.BillDate,
+		DueDate:            req.DueDate,
+		APAccountID:        req.APAccountID,
+		PaymentAccountID:   req.PaymentAccountID,
+		UnitID:             req.UnitID,
+		PeopleID:           req.PeopleID,
+		UserID:             1, // TODO: get user id from jwt
+		Amount:             float64(amountCents) / float64(money.MoneyScale),
+		AmountCents:        amountCents,
+		Currency:           rate.Currency,
+		ExchangeRateScaled: exchangeRateScaled,
+		ForeignAmountCents: foreignAmountCents,
+		Description:        req.Description,
+		CancelReason:       nil,
+		Status:             "1",
+		ApprovalStatus:     approvalStatus,
+		BuildingID:         req.BuildingID,
+	}
+	billID, err := s.billStore.Create(ctx, tx, bill)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create expense lines
+	for _, line := range req.ExpenseLines {
+
+		amountStr := strconv.FormatFloat(line.Amount, 'f', -1, 64)
 		amountCents, err := money.ParseUSDAmount(amountStr)
 		if err != nil {
-			return fmt.Errorf("failed to parse amount: %v", err)
+			return nil, fmt.Errorf("failed to parse amount: %v", err)
 		}
-		// Create bill
-		bill := &store.Bill{
-			TransactionID: *transactionID,
-			BillNo:        req.BillNo,
-			BillDate:      req.BillDate,
-			DueDate:       req.DueDate,
-			APAccountID:   req.APAccountID,
-			UnitID:        req.UnitID,
-			PeopleID:      req.PeopleID,
-			UserID:        1, // TODO: get user id from jwt
-			Amount:        req.Amount,
-			AmountCents:   amountCents,
-			Description:   req.Description,
-			CancelReason:  nil,
-			Status:        "1",
-			BuildingID:    req.BuildingID,
-		}
-		billID, err := s.billStore.Create(ctx, tx, bill)
+
+		qtyScaled, err := billLineQty(line)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		// Create expense lines
-		for _, line := range req.ExpenseLines {
-
-			amountStr := strconv.FormatFloat(req.Amount, 'f', -1, 64)
-			amountCents, err := money.ParseUSDAmount(amountStr)
-			if err != nil {
-				return fmt.Errorf("failed to parse amount: %v", err)
-			}
-
-			expenseLine := &store.BillExpenseLine{
-				BillID:      *billID,
-				AccountID:   line.AccountID,
-				UnitID:      line.UnitID,
-				PeopleID:    line.PeopleID,
-				Description: line.Description,
-				Amount:      line.Amount,
-				AmountCents: amountCents,
-			}
-			_, err = s.billExpenseLineStore.Create(ctx, tx, expenseLine)
-			if err != nil {
-				return err
-			}
+		tax, err := s.taxService.lineTax(ctx, line.TaxCodeID, req.BuildingID, amountCents)
+		if err != nil {
+			return nil, err
 		}
 
-		// Generate splits
-		splits, err := s.GenerateBillSplits(ctx, req.BillPayloadDTO)
+		expenseLine := &store.BillExpenseLine{
+			BillID:       *billID,
+			AccountID:    line.AccountID,
+			UnitID:       line.UnitID,
+			PeopleID:     line.PeopleID,
+			Description:  line.Description,
+			Amount:       line.Amount,
+			AmountCents:  amountCents,
+			POLineID:     line.POLineID,
+			QtyScaled:    qtyScaled,
+			ItemID:       line.ItemID,
+			TaxCodeID:    tax.TaxCodeID,
+			TaxableCents: tax.TaxableCents,
+			TaxCents:     tax.TaxCents,
+		}
+		_, err = s.billExpenseLineStore.Create(ctx, tx, expenseLine)
 		if err != nil {
-			return err
+			return nil, err
 		}
+	}
 
-		if err := validateSplitsBalanced(splits); err != nil {
-			return err
+	if req.PurchaseOrderID != nil {
+		if err := s.purchaseOrderService.refreshStatusTx(ctx, tx, *req.PurchaseOrderID); err != nil {
+			return nil, err
 		}
+	}
 
-		for _, split := range splits {
-			split.TransactionID = *transactionID
-			err = s.splitStore.Create(ctx, tx, &split)
-			if err != nil {
-				return err
-			}
+	// drafts and bills waiting for approval stay off the books
+	if approvalStatus != "approved" {
+		return billID, nil
+	}
+
+	// Generate splits
+	splits, err := s.GenerateBillSplits(ctx, req.BillPayloadDTO)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateSplitsBalanced(splits); err != nil {
+		return nil, err
+	}
+
+	for _, split := range splits {
+		split.TransactionID = *transactionID
+		err = s.splitStore.Create(ctx, tx, &split)
+		if err != nil {
+			return nil, err
 		}
-		return nil
-	})
+	}
+
+	if err := s.receiveInventoryTx(ctx, tx, *transactionID, req.BillPayloadDTO); err != nil {
+		return nil, err
+	}
+	return billID, nil
 }
 
 func (s *BillService) Update(ctx context.Context, req dto.UpdateBillRequest, billID int64) error {
+	if err := s.resolveItemLines(ctx, &req.BillPayloadDTO); err != nil {
+		return err
+	}
+	if err := s.checkPaymentAccount(ctx, req.BillPayloadDTO); err != nil {
+		return err
+	}
+
 	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if _, err := s.approvalService.lockTx(ctx, tx, "bill", billID); err != nil {
+			return fmt.Errorf("bill not found: %v", err)
+		}
+
 		// Fetch existing bill
 		existingBill, err := s.billStore.GetByID(ctx, billID)
 		if err != nil {
 			return fmt.Errorf("bill not found: %v", err)
 		}
 
+		if reason, err := s.splitStore.GetLockReason(ctx, tx, existingBill.TransactionID); err != nil {
+			return err
+		} else if reason != "" {
+			return fmt.Errorf("bill cannot be edited: %s", reason)
+		}
+
+		// purchase orders the bill was billed against before the edit
+		purchaseOrderIDs, err := s.purchaseOrderService.billPurchaseOrdersTx(ctx, tx, billID)
+		if err != nil {
+			return err
+		}
+
 		// Delete expense lines
 		expenseLines, err := s.billExpenseLineStore.GetAllByBillID(ctx, existingBill.ID)
 		if err != nil {
@@ -252,24 +359,72 @@ func (s *BillService) Update(ctx context.Context, req dto.UpdateBillRequest, bil
 		if err != nil {
 			return fmt.Errorf("failed to parse amount: %v", err)
 		}
+		rate, err := s.currencyService.documentRate(ctx, req.BuildingID, req.Currency, req.BillDate, req.ExchangeRate)
+		if err != nil {
+			return err
+		}
+		exchangeRateScaled, foreignAmountCents := rate.foreignAmounts(amountCents)
+		if amountCents, err = rate.toBase(amountCents); err != nil {
+			return err
+		}
+
+		// an edit goes through approval again when the new amount needs it
+		approvalStatus, err := s.approvalService.initialStatus(ctx, req.BuildingID, "bill", amountCents, req.Draft)
+		if err != nil {
+			return err
+		}
+
+		if approvalStatus, err = s.matchPurchaseOrderTx(ctx, tx, req.BillPayloadDTO, billID, approvalStatus); err != nil {
+			return err
+		}
+
+		// payments on a foreign currency bill were realized against its rate and amount
+		if existingBill.Currency != nil || req.Currency != nil {
+			balance, err := s.billStore.GetOpenBalanceTx(ctx, tx, billID)
+			if err != nil {
+				return err
+			}
+			if existingBill.ApprovalStatus == "approved" && balance != existingBill.AmountCents {
+				return fmt.Errorf("a foreign currency bill with payments or credits cannot be edited")
+			}
+		}
+
+		if existingBill.ApprovalStatus == "approved" && approvalStatus != "approved" {
+			balance, err := s.billStore.GetOpenBalanceTx(ctx, tx, billID)
+			if err != nil {
+				return err
+			}
+			if balance != existingBill.AmountCents {
+				return fmt.Errorf("bill has payments or credits applied and cannot go back to approval")
+			}
+		}
+
+		if err := s.approvalService.resetTx(ctx, tx, "bill", billID); err != nil {
+			return err
+		}
 
 		// Update bill
 		updatedBill := &store.Bill{
-			ID:            billID,
-			TransactionID: existingBill.TransactionID,
-			BillNo:        req.BillNo,
-			BillDate:      req.BillDate,
-			DueDate:       req.DueDate,
-			APAccountID:   req.APAccountID,
-			UnitID:        req.UnitID,
-			PeopleID:      req.PeopleID,
-			UserID:        1, // TODO: get user id from jwt
-			Amount:        req.Amount,
-			AmountCents:   amountCents,
-			Description:   req.Description,
-			CancelReason:  existingBill.CancelReason,
-			Status:        existingBill.Status,
-			BuildingID:    req.BuildingID,
+			ID:                 billID,
+			TransactionID:      existingBill.TransactionID,
+			BillNo:             req.BillNo,
+			BillDate:           req.BillDate,
+			DueDate:            req.DueDate,
+			APAccountID:        req.APAccountID,
+			PaymentAccountID:   req.PaymentAccountID,
+			UnitID:             req.UnitID,
+			PeopleID:           req.PeopleID,
+			UserID:             1, // TODO: get user id from jwt
+			Amount:             float64(amountCents) / float64(money.MoneyScale),
+			AmountCents:        amountCents,
+			Currency:           rate.Currency,
+			ExchangeRateScaled: exchangeRateScaled,
+			ForeignAmountCents: foreignAmountCents,
+			Description:        req.Description,
+			CancelReason:       existingBill.CancelReason,
+			Status:             existingBill.Status,
+			ApprovalStatus:     approvalStatus,
+			BuildingID:         req.BuildingID,
 		}
 
 		_, err = s.billStore.Update(ctx, tx, updatedBill)
@@ -279,20 +434,36 @@ func (s *BillService) Update(ctx context.Context, req dto.UpdateBillRequest, bil
 
 		// Recreate expense lines
 		for _, line := range req.ExpenseLines {
-			amountStr := strconv.FormatFloat(req.Amount, 'f', -1, 64)
+			amountStr := strconv.FormatFloat(line.Amount, 'f', -1, 64)
 			amountCents, err := money.ParseUSDAmount(amountStr)
 			if err != nil {
 				return fmt.Errorf("failed to parse amount: %v", err)
 			}
 
+			qtyScaled, err := billLineQty(line)
+			if err != nil {
+				return err
+			}
+
+			tax, err := s.taxService.lineTax(ctx, line.TaxCodeID, req.BuildingID, amountCents)
+			if err != nil {
+				return err
+			}
+
 			expenseLine := &store.BillExpenseLine{
-				BillID:      billID,
-				AccountID:   line.AccountID,
-				UnitID:      line.UnitID,
-				PeopleID:    line.PeopleID,
-				Description: line.Description,
-				Amount:      line.Amount,
-				AmountCents: amountCents,
+				BillID:       billID,
+				AccountID:    line.AccountID,
+				UnitID:       line.UnitID,
+				PeopleID:     line.PeopleID,
+				Description:  line.Description,
+				Amount:       line.Amount,
+				AmountCents:  amountCents,
+				POLineID:     line.POLineID,
+				QtyScaled:    qtyScaled,
+				ItemID:       line.ItemID,
+				TaxCodeID:    tax.TaxCodeID,
+				TaxableCents: tax.TaxableCents,
+				TaxCents:     tax.TaxCents,
 			}
 			_, err = s.billExpenseLineStore.Create(ctx, tx, expenseLine)
 			if err != nil {
@@ -300,6 +471,13 @@ func (s *BillService) Update(ctx context.Context, req dto.UpdateBillRequest, bil
 			}
 		}
 
+		if req.PurchaseOrderID != nil && !slices.Contains(purchaseOrderIDs, *req.PurchaseOrderID) {
+			purchaseOrderIDs = append(purchaseOrderIDs, *req.PurchaseOrderID)
+		}
+		if err := s.purchaseOrderService.refreshStatusTx(ctx, tx, purchaseOrderIDs...); err != nil {
+			return err
+		}
+
 		// Update transaction
 		transaction := &store.Transaction{
 			ID:                existingBill.TransactionID,
@@ -322,6 +500,16 @@ func (s *BillService) Update(ctx context.Context, req dto.UpdateBillRequest, bil
 			return fmt.Errorf("failed to soft delete splits: %v", err)
 		}
 
+		// take back what the bill put into stock; it is received again below if the bill still posts
+		itemIDs, err := s.inventoryService.reverseTx(ctx, tx, existingBill.TransactionID)
+		if err != nil {
+			return err
+		}
+
+		if approvalStatus != "approved" {
+			return s.inventoryService.checkStockTx(ctx, tx, itemIDs)
+		}
+
 		// Generate splits
 		splits, err := s.GenerateBillSplits(ctx, req.BillPayloadDTO)
 		if err != nil {
@@ -340,10 +528,252 @@ func (s *BillService) Update(ctx context.Context, req dto.UpdateBillRequest, bil
 			}
 		}
 
-		return nil
+		if err := s.receiveInventoryTx(ctx, tx, existingBill.TransactionID, req.BillPayloadDTO); err != nil {
+			return err
+		}
+		return s.inventoryService.checkStockTx(ctx, tx, itemIDs)
 	})
 }
 
+// Approve records an approval on a draft or pending bill and posts it once the last required approval is in
+func (s *BillService) Approve(ctx context.Context, buildingID int64, billID int64, userID int64, req dto.ApprovalDecisionRequest) (*dto.ApprovalStatusResponse, error) {
+	return s.decide(ctx, buildingID, billID, userID, req, true)
+}
+
+// Reject closes the approval chain; editing the bill submits it again
+func (s *BillService) Reject(ctx context.Context, buildingID int64, billID int64, userID int64, req dto.ApprovalDecisionRequest) (*dto.ApprovalStatusResponse, error) {
+	return s.decide(ctx, buildingID, billID, userID, req, false)
+}
+
+func (s *BillService) GetApproval(ctx context.Context, billID int64) (*dto.ApprovalStatusResponse, error) {
+	bill, err := s.billStore.GetByID(ctx, billID)
+	if err != nil {
+		return nil, err
+	}
+	return s.approvalService.getStatus(ctx, billApprovalDocument(bill), bill.ApprovalStatus)
+}
+
+func (s *BillService) decide(ctx context.Context, buildingID int64, billID int64, userID int64, req dto.ApprovalDecisionRequest, approve bool) (*dto.ApprovalStatusResponse, error) {
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		status, err := s.approvalService.lockTx(ctx, tx, "bill", billID)
+		if err != nil {
+			return err
+		}
+
+		bill, err := s.billStore.GetByID(ctx, billID)
+		if err != nil {
+			return err
+		}
+
+		if bill.BuildingID != buildingID {
+			return fmt.Errorf("bill does not belong to this building")
+		}
+
+		status, err = s.approvalService.decideTx(ctx, tx, billApprovalDocument(bill), status, userID, req, approve)
+		if err != nil {
+			return err
+		}
+
+		if status == "rejected" {
+			// a rejected bill no longer counts against its purchase orders
+			purchaseOrderIDs, err := s.purchaseOrderService.billPurchaseOrdersTx(ctx, tx, billID)
+			if err != nil {
+				return err
+			}
+			return s.purchaseOrderService.refreshStatusTx(ctx, tx, purchaseOrderIDs...)
+		}
+
+		if status != "approved" {
+			return nil
+		}
+
+		return s.postTx(ctx, tx, bill)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetApproval(ctx, billID)
+}
+
+// postTx writes the splits of a bill that was saved without them
+func (s *BillService) postTx(ctx context.Context, tx *sql.Tx, bill *store.Bill) error {
+	expenseLines, err := s.billExpenseLineStore.GetAllByBillID(ctx, bill.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get expense lines: %v", err)
+	}
+
+	payload := dto.BillPayloadDTO{
+		BillNo:      bill.BillNo,
+		BillDate:    bill.BillDate,
+		DueDate:     bill.DueDate,
+		APAccountID: bill.APAccountID,
+		UnitID:      bill.UnitID,
+		PeopleID:    bill.PeopleID,
+		BuildingID:  bill.BuildingID,
+		Amount:      bill.Amount,
+		Description: bill.Description,
+	}
+	// a foreign currency bill is posted at the rate it was saved with
+	if bill.Currency != nil {
+		rate := float64(*bill.ExchangeRateScaled) / float64(money.FXRateScale)
+		payload.Currency = bill.Currency
+		payload.ExchangeRate = &rate
+		payload.Amount = float64(*bill.ForeignAmountCents) / float64(money.MoneyScale)
+	}
+	for _, line := range expenseLines {
+		var qty *float64
+		if line.QtyScaled != nil {
+			q := float64(*line.QtyScaled) / float64(money.QtyScale)
+			qty = &q
+		}
+
+		payload.ExpenseLines = append(payload.ExpenseLines, dto.BillExpenseLineInput{
+			AccountID:   line.AccountID,
+			UnitID:      line.UnitID,
+			PeopleID:    line.PeopleID,
+			Description: line.Description,
+			Amount:      line.Amount,
+			Qty:         qty,
+			ItemID:      line.ItemID,
+			TaxCodeID:   line.TaxCodeID,
+		})
+	}
+
+	splits, err := s.GenerateBillSplits(ctx, payload)
+	if err != nil {
+		return err
+	}
+
+	if err := validateSplitsBalanced(splits); err != nil {
+		return err
+	}
+
+	for _, split := range splits {
+		split.TransactionID = bill.TransactionID
+		if err := s.splitStore.Create(ctx, tx, &split); err != nil {
+			return err
+		}
+	}
+
+	return s.receiveInventoryTx(ctx, tx, bill.TransactionID, payload)
+}
+
+// matchPurchaseOrderTx matches a bill against its purchase order and holds it for approval
+// when it goes beyond the purchase order's tolerance
+func (s *BillService) matchPurchaseOrderTx(ctx context.Context, tx *sql.Tx, req dto.BillPayloadDTO, billID int64, approvalStatus string) (string, error) {
+	if req.PurchaseOrderID == nil {
+		for _, line := range req.ExpenseLines {
+			if line.POLineID != nil {
+				return "", fmt.Errorf("po_line_id needs the bill's purchase_order_id")
+			}
+		}
+		return approvalStatus, nil
+	}
+
+	match, err := s.purchaseOrderService.matchTx(ctx, tx, req, billID)
+	if err != nil {
+		return "", err
+	}
+
+	if !match.WithinTolerance && approvalStatus == "approved" {
+		return "pending", nil
+	}
+	return approvalStatus, nil
+}
+
+// resolveItemLines fills in the account of each item line before the bill is matched and posted
+func (s *BillService) resolveItemLines(ctx context.Context, req *dto.BillPayloadDTO) error {
+	for i, line := range req.ExpenseLines {
+		if line.ItemID == nil {
+			continue
+		}
+
+		accountID, err := s.inventoryService.billLineAccount(ctx, line, req.BuildingID)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", i+1, err)
+		}
+		req.ExpenseLines[i].AccountID = accountID
+	}
+
+	return nil
+}
+
+// receiveInventoryTx takes the bill's inventory lines into stock at what they were billed at
+func (s *BillService) receiveInventoryTx(ctx context.Context, tx *sql.Tx, transactionID int64, req dto.BillPayloadDTO) error {
+	rate, err := s.currencyService.documentRate(ctx, req.BuildingID, req.Currency, req.BillDate, req.ExchangeRate)
+	if err != nil {
+		return err
+	}
+
+	lines := []inventoryLine{}
+	for _, line := range req.ExpenseLines {
+		if line.ItemID == nil {
+			continue
+		}
+
+		qtyScaled, err := billLineQty(line)
+		if err != nil {
+			return err
+		}
+		amountCents, err := money.ParseUSDAmount(strconv.FormatFloat(line.Amount, 'f', -1, 64))
+		if err != nil {
+			return fmt.Errorf("failed to parse amount: %v", err)
+		}
+
+		// reclaimable tax is not part of what the stock cost
+		tax, err := s.taxService.lineTax(ctx, line.TaxCodeID, req.BuildingID, amountCents)
+		if err != nil {
+			return err
+		}
+
+		costCents, err := rate.toBase(tax.TaxableCents)
+		if err != nil {
+			return err
+		}
+
+		inventoryLine := inventoryLine{ItemID: *line.ItemID, CostCents: costCents}
+		if qtyScaled != nil {
+			inventoryLine.QtyScaled = *qtyScaled
+		}
+		lines = append(lines, inventoryLine)
+	}
+
+	return s.inventoryService.receiveTx(ctx, tx, inventoryPosting{
+		TransactionID: transactionID,
+		SourceType:    "bill",
+		Date:          req.BillDate,
+		BuildingID:    req.BuildingID,
+		UnitID:        req.UnitID,
+		PeopleID:      req.PeopleID,
+	}, lines)
+}
+
+func billApprovalDocument(bill *store.Bill) approvalDocument {
+	return approvalDocument{
+		Type:        "bill",
+		ID:          bill.ID,
+		BuildingID:  bill.BuildingID,
+		AmountCents: bill.AmountCents,
+		CreatedBy:   bill.UserID,
+	}
+}
+
+// checkPaymentAccount checks the account the bill is planned to be paid from, when one is given
+func (s *BillService) checkPaymentAccount(ctx context.Context, req dto.BillPayloadDTO) error {
+	if req.PaymentAccountID == nil {
+		return nil
+	}
+	account, err := s.accountStore.GetByID(ctx, *req.PaymentAccountID)
+	if err != nil {
+		return fmt.Errorf("payment account not found")
+	}
+	if account.BuildingID != req.BuildingID {
+		return fmt.Errorf("payment account does not belong to this building")
+	}
+	return nil
+}
+
 func (s *BillService) GenerateBillSplits(
 	ctx context.Context,
 	req dto.BillPayloadDTO,
@@ -380,6 +810,8 @@ func (s *BillService) GenerateBillSplits(
 	splits = append(splits, creditSplit)
 
 	// Debit expense accounts
+	var linesCents int64
+	taxed := false
 	for _, line := range req.ExpenseLines {
 		_, err := s.accountStore.GetByID(ctx, line.AccountID)
 		if err != nil {
@@ -392,16 +824,46 @@ func (s *BillService) GenerateBillSplits(
 			return nil, fmt.Errorf("failed to parse amount: %v", err)
 		}
 
-		splits = append(splits, store.Split{
-			AccountID:   line.AccountID,
-			Debit:       &line.Amount,
-			DebitCents:  &amountCents,
-			Credit:      nil,
-			CreditCents: nil,
-			UnitID:      line.UnitID,
-			PeopleID:    line.PeopleID,
-			Status:      "1",
-		})
+		tax, err := s.taxService.lineTax(ctx, line.TaxCodeID, req.BuildingID, amountCents)
+		if err != nil {
+			return nil, err
+		}
+		linesCents += tax.TaxableCents + tax.TaxCents
+
+		if line.TaxCodeID == nil {
+			splits = append(splits, store.Split{
+				AccountID:   line.AccountID,
+				Debit:       &line.Amount,
+				DebitCents:  &amountCents,
+				Credit:      nil,
+				CreditCents: nil,
+				UnitID:      line.UnitID,
+				PeopleID:    line.PeopleID,
+				Status:      "1",
+			})
+			continue
+		}
+
+		// input tax is reclaimed through the tax code's liability account
+		taxed = true
+		splits = append(splits, newDebitSplit(0, line.AccountID, tax.TaxableCents, line.UnitID, line.PeopleID))
+		if tax.TaxCents != 0 {
+			splits = append(splits, newDebitSplit(0, tax.AccountID, tax.TaxCents, line.UnitID, line.PeopleID))
+		}
+	}
+
+	if taxed && linesCents != amountCents {
+		return nil, fmt.Errorf("amount %s does not match the bill total of %s including tax",
+			money.FormatMoneyFromCents(amountCents), money.FormatMoneyFromCents(linesCents))
+	}
+
+	// a foreign currency bill posts in base currency at its rate
+	rate, err := s.currencyService.documentRate(ctx, req.BuildingID, req.Currency, req.BillDate, req.ExchangeRate)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.currencyService.convertSplits(ctx, splits, rate, req.APAccountID); err != nil {
+		return nil, err
 	}
 
 	return splits, nil
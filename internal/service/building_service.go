@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
 	"github.com/mysecodgit/go_accounting/internal/store"
 )
 
@@ -13,6 +15,8 @@ type BuildingStore interface {
 	GetByID(ctx context.Context, id int64) (*store.Building, error)
 	Create(ctx context.Context, tx *sql.Tx, building *store.Building) error
 	Update(ctx context.Context, building *store.Building) error
+	UpdatePaymentSettings(ctx context.Context, building *store.Building) error
+	UpdateCurrencySettings(ctx context.Context, building *store.Building) error
 	Delete(ctx context.Context, id int64) error
 }
 
@@ -55,6 +59,22 @@ func (s *BuildingService) Update(ctx context.Context, building *store.Building)
 	return s.buildingStore.Update(ctx, building)
 }
 
+func (s *BuildingService) UpdatePaymentSettings(ctx context.Context, building *store.Building) error {
+	if building.AllowOverpayment && building.CustomerDepositsAccountID == nil && building.VendorPrepaymentsAccountID == nil {
+		return fmt.Errorf("allowing overpayments needs a customer deposits or vendor prepayments account")
+	}
+	return s.buildingStore.UpdatePaymentSettings(ctx, building)
+}
+
+func (s *BuildingService) UpdateCurrencySettings(ctx context.Context, building *store.Building) error {
+	currency, err := money.NormalizeCurrency(building.BaseCurrency)
+	if err != nil {
+		return err
+	}
+	building.BaseCurrency = currency
+	return s.buildingStore.UpdateCurrencySettings(ctx, building)
+}
+
 func (s *BuildingService) Delete(ctx context.Context, id int64) error {
 	return s.buildingStore.Delete(ctx, id)
 }
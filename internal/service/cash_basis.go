@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+// ReportBasis decides when the P&L and balance sheet recognize income and expenses
+type ReportBasis string
+
+const (
+	BasisAccrual ReportBasis = "accrual" // when the invoice or bill posts
+	BasisCash    ReportBasis = "cash"    // as the invoice or bill is paid
+)
+
+// ParseReportBasis reads a basis= query value; empty is accrual
+func ParseReportBasis(value string) (ReportBasis, error) {
+	switch ReportBasis(strings.ToLower(strings.TrimSpace(value))) {
+	case "", BasisAccrual:
+		return BasisAccrual, nil
+	case BasisCash:
+		return BasisCash, nil
+	}
+	return "", fmt.Errorf("invalid basis value: %s", value)
+}
+
+// cashBasisAdjustment is what cash basis changes on one account: the unsettled share of each
+// invoice and bill split posted to it, taken back out
+type cashBasisAdjustment struct {
+	AccountID         int
+	AccountNumber     int
+	AccountName       string
+	AccountType       string
+	AccountTypeName   string
+	AccountTypeStatus string
+	DebitCents        int64
+	CreditCents       int64
+}
+
+// Balance returns the adjustment signed like the account's balance
+func (a cashBasisAdjustment) Balance() int64 {
+	return signedBalance(a.AccountTypeStatus, a.DebitCents, a.CreditCents)
+}
+
+func signedBalance(typeStatus string, debitCents, creditCents int64) int64 {
+	if strings.ToLower(typeStatus) == "debit" {
+		return debitCents - creditCents
+	}
+	return creditCents - debitCents
+}
+
+// cashBasisAdjustments returns, per account, what turns its accrual balance as of asOfDate into
+// its cash basis balance. A document half paid keeps half of each of its splits, so its income
+// or expense lines are recognized in proportion and its AR or AP is left with nothing.
+func (s *ReportService) cashBasisAdjustments(ctx context.Context, buildingID int, asOfDate string) (map[int]*cashBasisAdjustment, error) {
+	splits, err := s.reportStore.GetUnsettledDocumentSplits(ctx, buildingID, asOfDate)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustments := make(map[int]*cashBasisAdjustment)
+	for _, split := range splits {
+		debit, err := money.MulDiv(split.DebitCents, split.UnsettledCents, split.DocumentCents)
+		if err != nil {
+			return nil, err
+		}
+		credit, err := money.MulDiv(split.CreditCents, split.UnsettledCents, split.DocumentCents)
+		if err != nil {
+			return nil, err
+		}
+
+		adjustment, ok := adjustments[split.AccountID]
+		if !ok {
+			adjustment = &cashBasisAdjustment{
+				AccountID:         split.AccountID,
+				AccountNumber:     split.AccountNumber,
+				AccountName:       split.AccountName,
+				AccountType:       split.AccountType,
+				AccountTypeName:   split.AccountTypeName,
+				AccountTypeStatus: split.AccountTypeStatus,
+			}
+			adjustments[split.AccountID] = adjustment
+		}
+		adjustment.DebitCents -= debit
+		adjustment.CreditCents -= credit
+	}
+	return adjustments, nil
+}
+
+// cashBasisPeriodAdjustments returns the cash basis adjustments to activity between startDate
+// and endDate: those as of the end less those already made as of the day before the start
+func (s *ReportService) cashBasisPeriodAdjustments(ctx context.Context, buildingID int, startDate string, endDate string) (map[int]*cashBasisAdjustment, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start_date: %s", startDate)
+	}
+
+	adjustments, err := s.cashBasisAdjustments(ctx, buildingID, endDate)
+	if err != nil {
+		return nil, err
+	}
+	opening, err := s.cashBasisAdjustments(ctx, buildingID, start.AddDate(0, 0, -1).Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	for accountID, o := range opening {
+		adjustment, ok := adjustments[accountID]
+		if !ok {
+			copied := *o
+			copied.DebitCents, copied.CreditCents = 0, 0
+			adjustment = &copied
+			adjustments[accountID] = adjustment
+		}
+		adjustment.DebitCents -= o.DebitCents
+		adjustment.CreditCents -= o.CreditCents
+	}
+	return adjustments, nil
+}
+
+// applyCashBasisToBalances adjusts balance sheet account balances, which cover every account
+func applyCashBasisToBalances(balances []store.AccountBalance, adjustments map[int]*cashBasisAdjustment) {
+	for i := range balances {
+		adjustment, ok := adjustments[balances[i].AccountID]
+		if !ok {
+			continue
+		}
+		balances[i].Debit += adjustment.DebitCents
+		balances[i].Credit += adjustment.CreditCents
+		balances[i].Balance += adjustment.Balance()
+	}
+}
+
+// applyCashBasisToPLRows adjusts the P&L rows of one account type. Accounts with no activity in
+// the period under accrual can still have some under cash basis, and the other way round.
+func applyCashBasisToPLRows(rows []store.PLAccountRow, adjustments map[int]*cashBasisAdjustment, accountType string) []store.PLAccountRow {
+	seen := make(map[int]bool)
+	adjusted := []store.PLAccountRow{}
+	for _, row := range rows {
+		seen[row.AccountID] = true
+		if adjustment, ok := adjustments[row.AccountID]; ok {
+			row.TotalDebit += adjustment.DebitCents
+			row.TotalCredit += adjustment.CreditCents
+			row.Balance += adjustment.Balance()
+		}
+		if row.TotalDebit == 0 && row.TotalCredit == 0 {
+			continue
+		}
+		adjusted = append(adjusted, row)
+	}
+
+	for accountID, adjustment := range adjustments {
+		if seen[accountID] || adjustment.AccountType != accountType {
+			continue
+		}
+		if adjustment.DebitCents == 0 && adjustment.CreditCents == 0 {
+			continue
+		}
+		adjusted = append(adjusted, store.PLAccountRow{
+			AccountID:     adjustment.AccountID,
+			AccountNumber: adjustment.AccountNumber,
+			AccountName:   adjustment.AccountName,
+			AccountType:   adjustment.AccountTypeName,
+			TotalDebit:    adjustment.DebitCents,
+			TotalCredit:   adjustment.CreditCents,
+			Balance:       adjustment.Balance(),
+		})
+	}
+
+	sort.Slice(adjusted, func(i, j int) bool {
+		return adjusted[i].AccountNumber < adjusted[j].AccountNumber
+	})
+	return adjusted
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+// GetCashFlowStatement reports the statement of cash flows for startDate to endDate by the
+// indirect method: net income adjusted for non-cash items and changes in working capital, then
+// investing and financing activity. Each balance sheet account goes to the section its account
+// type's cash flow category names, and the sections reconcile to the change in the cash accounts.
+func (s *ReportService) GetCashFlowStatement(ctx context.Context, buildingID int, startDate string, endDate string) (*dto.CashFlowStatementResponse, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date: %s", startDate)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date: %s", endDate)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("start date must not be after the end date")
+	}
+
+	const opening, period = 0, 1
+	totals, err := s.reportStore.GetAccountTotalsByRange(ctx, buildingID, []store.DateRange{
+		{End: start.AddDate(0, 0, -1).Format("2006-01-02")},
+		{Start: startDate, End: endDate},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	statement := s.format.Accounting()
+	var netIncome, openingCash, cashChange int64
+	var nonCashCents, workingCapitalCents, investingCents, financingCents int64
+	nonCash := []dto.CashFlowLine{}
+	workingCapital := []dto.CashFlowLine{}
+	investing := []dto.CashFlowLine{}
+	financing := []dto.CashFlowLine{}
+
+	for _, t := range totals {
+		switch strings.ToLower(t.AccountType) {
+		case "income", "expense":
+			if t.Range == period {
+				netIncome += t.CreditCents - t.DebitCents
+			}
+			continue
+		}
+
+		if t.CashFlowCategory == store.CashFlowCash {
+			if t.Range == opening {
+				openingCash += t.DebitCents - t.CreditCents
+			} else {
+				cashChange += t.DebitCents - t.CreditCents
+			}
+			continue
+		}
+		if t.Range != period {
+			continue
+		}
+
+		// a rise in an asset uses cash and a rise in a liability or equity provides it
+		amount := t.CreditCents - t.DebitCents
+		if amount == 0 {
+			continue
+		}
+		line := dto.CashFlowLine{
+			AccountID:     t.AccountID,
+			AccountNumber: t.AccountNumber,
+			AccountName:   t.AccountName,
+			Amount:        statement.Cents(amount),
+		}
+
+		switch t.CashFlowCategory {
+		case store.CashFlowInvesting:
+			investing = append(investing, line)
+			investingCents += amount
+		case store.CashFlowFinancing:
+			financing = append(financing, line)
+			financingCents += amount
+		case store.CashFlowNonCash:
+			nonCash = append(nonCash, line)
+			nonCashCents += amount
+		default:
+			workingCapital = append(workingCapital, line)
+			workingCapitalCents += amount
+		}
+	}
+
+	operatingCents := netIncome + nonCashCents + workingCapitalCents
+
+	return &dto.CashFlowStatementResponse{
+		BuildingID: buildingID,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Operating: dto.CashFlowOperatingSection{
+			NetIncome:      statement.Cents(netIncome),
+			NonCashItems:   nonCash,
+			WorkingCapital: workingCapital,
+			Total:          statement.Cents(operatingCents),
+		},
+		Investing:       dto.CashFlowSection{SectionName: "Investing", Lines: investing, Total: statement.Cents(investingCents)},
+		Financing:       dto.CashFlowSection{SectionName: "Financing", Lines: financing, Total: statement.Cents(financingCents)},
+		NetChangeInCash: statement.Cents(cashChange),
+		OpeningCash:     statement.Cents(openingCash),
+		ClosingCash:     statement.Cents(openingCash + cashChange),
+		IsReconciled:    operatingCents+investingCents+financingCents == cashChange,
+	}, nil
+}
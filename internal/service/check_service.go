@@ -39,6 +39,8 @@ type CheckService struct {
 	splitStore       SplitStore
 	transactionStore TransactionStore
 	accountStore     AccountStore
+	approvalService  *ApprovalService
+	currencyService  *CurrencyService
 }
 
 type ExpenseLineStore interface {
@@ -62,6 +64,8 @@ func NewCheckService(db *sql.DB,
 	splitStore SplitStore,
 	transactionStore TransactionStore,
 	accountStore AccountStore,
+	approvalService *ApprovalService,
+	currencyService *CurrencyService,
 ) *CheckService {
 	return &CheckService{
 		db:               db,
@@ -70,6 +74,8 @@ func NewCheckService(db *sql.DB,
 		splitStore:       splitStore,
 		transactionStore: transactionStore,
 		accountStore:     accountStore,
+		approvalService:  approvalService,
+		currencyService:  currencyService,
 	}
 }
 
@@ -135,92 +141,116 @@ func (s *CheckService) GetByID(ctx context.Context, id int64) (*dto.CheckRespons
 func (s *CheckService) Create(ctx context.Context, req dto.CreateCheckRequest) error {
 
 	return withTx(s.db, ctx, func(tx *sql.Tx) error {
-		// create transaction
-		transaction := &store.Transaction{
-			Type:              "check",
-			TransactionDate:   req.CheckDate,
-			TransactionNumber: *req.ReferenceNumber,
-			Memo:              *req.Memo,
-			Status:            "1",
-			BuildingID:        req.BuildingID,
-			UserID:            1, // TODO: get user id from jwt
-			UnitID:            nil,
-		}
-		transactionId, err := s.transactionStore.Create(ctx, tx, transaction)
-		if err != nil {
-			return err
-		}
+		_, err := s.CreateTx(ctx, tx, req)
+		return err
+	})
+
+}
 
-		totalAmountStr := strconv.FormatFloat(req.TotalAmount, 'f', -1, 64)
-		totalAmountCents, err := money.ParseUSDAmount(totalAmountStr)
+// CreateTx posts a check inside an existing transaction and returns its transaction id
+func (s *CheckService) CreateTx(ctx context.Context, tx *sql.Tx, req dto.CreateCheckRequest) (*int64, error) {
+	// create transaction
+	transaction := &store.Transaction{
+		Type:              "check",
+		TransactionDate:   req.CheckDate,
+		TransactionNumber: *req.ReferenceNumber,
+		Memo:              *req.Memo,
+		Status:            "1",
+		BuildingID:        req.BuildingID,
+		UserID:            1, // TODO: get user id from jwt
+		UnitID:            nil,
+	}
+	transactionId, err := s.transactionStore.Create(ctx, tx, transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	totalAmountStr := strconv.FormatFloat(req.TotalAmount, 'f', -1, 64)
+	totalAmountCents, err := money.ParseUSDAmount(totalAmountStr)
+	if err != nil {
+		return nil, err
+	}
+
+	approvalStatus, err := s.approvalService.initialStatus(ctx, req.BuildingID, "check", totalAmountCents, req.Draft)
+	if err != nil {
+		return nil, err
+	}
+
+	// create check
+	check := &store.Check{
+		TransactionID:    *transactionId,
+		CheckDate:        req.CheckDate,
+		ReferenceNumber:  *req.ReferenceNumber,
+		PaymentAccountID: req.PaymentAccountID,
+		BuildingID:       req.BuildingID,
+		Memo:             req.Memo,
+		TotalAmount:      req.TotalAmount,
+		AmountCents:      totalAmountCents,
+		ApprovalStatus:   approvalStatus,
+	}
+	checkId, err := s.checkStore.Create(ctx, tx, check)
+	if err != nil {
+		return nil, err
+	}
+
+	// create expense lines
+	for _, line := range req.ExpenseLines {
+		amountStr := strconv.FormatFloat(line.Amount, 'f', -1, 64)
+		amountCents, err := money.ParseUSDAmount(amountStr)
 		if err != nil {
-			return err
+			return nil, err
 		}
-
-		// create check
-		check := &store.Check{
-			TransactionID:    *transactionId,
-			CheckDate:        req.CheckDate,
-			ReferenceNumber:  *req.ReferenceNumber,
-			PaymentAccountID: req.PaymentAccountID,
-			BuildingID:       req.BuildingID,
-			Memo:             req.Memo,
-			TotalAmount:      req.TotalAmount,
-			AmountCents:      totalAmountCents,
+		expenseLine := &store.ExpenseLine{
+			CheckID:     *checkId,
+			AccountID:   line.AccountID,
+			UnitID:      line.UnitID,
+			PeopleID:    line.PeopleID,
+			Description: line.Description,
+			Amount:      line.Amount,
+			AmountCents: amountCents,
 		}
-		checkId, err := s.checkStore.Create(ctx, tx, check)
+		_, err = s.expenseLineStore.Create(ctx, tx, expenseLine)
 		if err != nil {
-			return err
+			return nil, err
 		}
+	}
 
-		// create expense lines
-		for _, line := range req.ExpenseLines {
-			amountStr := strconv.FormatFloat(line.Amount, 'f', -1, 64)
-			amountCents, err := money.ParseUSDAmount(amountStr)
-			if err != nil {
-				return err
-			}
-			expenseLine := &store.ExpenseLine{
-				CheckID:     *checkId,
-				AccountID:   line.AccountID,
-				UnitID:      line.UnitID,
-				PeopleID:    line.PeopleID,
-				Description: line.Description,
-				Amount:      line.Amount,
-				AmountCents: amountCents,
-			}
-			_, err = s.expenseLineStore.Create(ctx, tx, expenseLine)
-			if err != nil {
-				return err
-			}
-		}
+	// drafts and checks waiting for approval stay off the books
+	if approvalStatus != "approved" {
+		return transactionId, nil
+	}
 
-		// generate splits
-		splits, err := s.GenerateCheckSplits(ctx, req.CheckPayloadDTO)
-		if err != nil {
-			return err
-		}
+	// generate splits
+	splits, err := s.GenerateCheckSplits(ctx, req.CheckPayloadDTO)
+	if err != nil {
+		return nil, err
+	}
 
-		// validate splits
-		if err := s.ValidateSplits(splits); err != nil {
-			return err
-		}
+	// validate splits
+	if err := s.ValidateSplits(splits); err != nil {
+		return nil, err
+	}
+	if err := s.currencyService.requireBaseCurrency(ctx, splits); err != nil {
+		return nil, err
+	}
 
-		for _, split := range splits {
-			split.TransactionID = *transactionId
-			err = s.splitStore.Create(ctx, tx, &split)
-			if err != nil {
-				return err
-			}
+	for _, split := range splits {
+		split.TransactionID = *transactionId
+		err = s.splitStore.Create(ctx, tx, &split)
+		if err != nil {
+			return nil, err
 		}
-		return nil
-	})
-
+	}
+	return transactionId, nil
 }
 
 func (s *CheckService) Update(ctx context.Context, req dto.UpdateCheckRequest, checkId int64) error {
 	fmt.Println("Update check", checkId)
 	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if _, err := s.approvalService.lockTx(ctx, tx, "check", checkId); err != nil {
+			return fmt.Errorf("check not found: %v", err)
+		}
+
 		// Fetch existing check
 		existingCheck, err := s.checkStore.GetByID(ctx, checkId)
 		if err != nil {
@@ -230,6 +260,12 @@ func (s *CheckService) Update(ctx context.Context, req dto.UpdateCheckRequest, c
 
 		fmt.Println("Existing check", existingCheck)
 
+		if reason, err := s.splitStore.GetLockReason(ctx, tx, existingCheck.TransactionID); err != nil {
+			return err
+		} else if reason != "" {
+			return fmt.Errorf("check cannot be edited: %s", reason)
+		}
+
 		expenseLines, err := s.expenseLineStore.GetAllByCheckID(ctx, existingCheck.ID)
 		if err != nil {
 			fmt.Println("Failed to get expense lines", err)
@@ -251,6 +287,16 @@ func (s *CheckService) Update(ctx context.Context, req dto.UpdateCheckRequest, c
 			return err
 		}
 
+		// an edit goes through approval again when the new amount needs it
+		approvalStatus, err := s.approvalService.initialStatus(ctx, req.BuildingID, "check", amountCents, req.Draft)
+		if err != nil {
+			return err
+		}
+
+		if err := s.approvalService.resetTx(ctx, tx, "check", checkId); err != nil {
+			return err
+		}
+
 		// update check
 		updatedCheck := &store.Check{
 			ID:               checkId,
@@ -262,6 +308,7 @@ func (s *CheckService) Update(ctx context.Context, req dto.UpdateCheckRequest, c
 			Memo:             req.Memo,
 			TotalAmount:      req.TotalAmount,
 			AmountCents:      amountCents,
+			ApprovalStatus:   approvalStatus,
 		}
 
 		_, err = s.checkStore.Update(ctx, tx, updatedCheck)
@@ -318,6 +365,10 @@ func (s *CheckService) Update(ctx context.Context, req dto.UpdateCheckRequest, c
 			return fmt.Errorf("failed to soft delete splits: %v", err)
 		}
 
+		if approvalStatus != "approved" {
+			return nil
+		}
+
 		// generate splits
 		splits, err := s.GenerateCheckSplits(ctx, req.CheckPayloadDTO)
 		if err != nil {
@@ -330,6 +381,9 @@ func (s *CheckService) Update(ctx context.Context, req dto.UpdateCheckRequest, c
 			fmt.Println("Failed to validate splits", err)
 			return err
 		}
+		if err := s.currencyService.requireBaseCurrency(ctx, splits); err != nil {
+			return err
+		}
 
 		for _, split := range splits {
 			split.TransactionID = existingCheck.TransactionID
@@ -355,6 +409,114 @@ func (s *CheckService) Delete(ctx context.Context, id int64) error {
 }
 */
 
+// Approve records an approval on a draft or pending check and posts it once the last required approval is in
+func (s *CheckService) Approve(ctx context.Context, buildingID int64, checkID int64, userID int64, req dto.ApprovalDecisionRequest) (*dto.ApprovalStatusResponse, error) {
+	return s.decide(ctx, buildingID, checkID, userID, req, true)
+}
+
+// Reject closes the approval chain; editing the check submits it again
+func (s *CheckService) Reject(ctx context.Context, buildingID int64, checkID int64, userID int64, req dto.ApprovalDecisionRequest) (*dto.ApprovalStatusResponse, error) {
+	return s.decide(ctx, buildingID, checkID, userID, req, false)
+}
+
+func (s *CheckService) GetApproval(ctx context.Context, checkID int64) (*dto.ApprovalStatusResponse, error) {
+	check, err := s.checkStore.GetByID(ctx, checkID)
+	if err != nil {
+		return nil, err
+	}
+	return s.approvalService.getStatus(ctx, checkApprovalDocument(check), check.ApprovalStatus)
+}
+
+func (s *CheckService) decide(ctx context.Context, buildingID int64, checkID int64, userID int64, req dto.ApprovalDecisionRequest, approve bool) (*dto.ApprovalStatusResponse, error) {
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		status, err := s.approvalService.lockTx(ctx, tx, "check", checkID)
+		if err != nil {
+			return err
+		}
+
+		check, err := s.checkStore.GetByID(ctx, checkID)
+		if err != nil {
+			return err
+		}
+
+		if check.BuildingID != buildingID {
+			return fmt.Errorf("check does not belong to this building")
+		}
+
+		status, err = s.approvalService.decideTx(ctx, tx, checkApprovalDocument(check), status, userID, req, approve)
+		if err != nil {
+			return err
+		}
+
+		if status != "approved" {
+			return nil
+		}
+
+		return s.postTx(ctx, tx, check)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetApproval(ctx, checkID)
+}
+
+// postTx writes the splits of a check that was saved without them
+func (s *CheckService) postTx(ctx context.Context, tx *sql.Tx, check *store.Check) error {
+	expenseLines, err := s.expenseLineStore.GetAllByCheckID(ctx, check.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get expense lines: %v", err)
+	}
+
+	payload := dto.CheckPayloadDTO{
+		CheckDate:        check.CheckDate,
+		ReferenceNumber:  &check.ReferenceNumber,
+		PaymentAccountID: check.PaymentAccountID,
+		BuildingID:       check.BuildingID,
+		Memo:             check.Memo,
+		TotalAmount:      check.TotalAmount,
+	}
+	for _, line := range expenseLines {
+		payload.ExpenseLines = append(payload.ExpenseLines, dto.ExpenseLineInput{
+			AccountID:   line.AccountID,
+			UnitID:      line.UnitID,
+			PeopleID:    line.PeopleID,
+			Description: line.Description,
+			Amount:      line.Amount,
+		})
+	}
+
+	splits, err := s.GenerateCheckSplits(ctx, payload)
+	if err != nil {
+		return err
+	}
+
+	if err := s.ValidateSplits(splits); err != nil {
+		return err
+	}
+	if err := s.currencyService.requireBaseCurrency(ctx, splits); err != nil {
+		return err
+	}
+
+	for _, split := range splits {
+		split.TransactionID = check.TransactionID
+		if err := s.splitStore.Create(ctx, tx, &split); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkApprovalDocument(check *store.Check) approvalDocument {
+	return approvalDocument{
+		Type:        "check",
+		ID:          check.ID,
+		BuildingID:  check.BuildingID,
+		AmountCents: check.AmountCents,
+	}
+}
+
 func (s *CheckService) GenerateCheckSplits(
 	ctx context.Context,
 	req dto.CheckPayloadDTO,
@@ -362,7 +524,7 @@ func (s *CheckService) GenerateCheckSplits(
 
 	// Validate accounts
 	if _, err := s.accountStore.GetByID(ctx, int64(req.PaymentAccountID)); err != nil {
-		return nil, fmt.Errorf("deposit account not found")
+		return nil, fmt.Errorf("payment account not found")
 	}
 
 	amount := req.TotalAmount
@@ -379,18 +541,19 @@ func (s *CheckService) GenerateCheckSplits(
 
 	splits := make([]store.Split, 0)
 
-	debitSplit := store.Split{
+	// money leaves the payment account
+	creditSplit := store.Split{
 		AccountID:   int64(req.PaymentAccountID),
-		Debit:       &amount,
-		DebitCents:  &amountCents,
-		Credit:      nil,
-		CreditCents: nil,
+		Debit:       nil,
+		DebitCents:  nil,
+		Credit:      &amount,
+		CreditCents: &amountCents,
 		UnitID:      nil,
 		PeopleID:    nil,
 		Status:      "1",
 	}
 
-	splits = append(splits, debitSplit)
+	splits = append(splits, creditSplit)
 
 	for _, line := range req.ExpenseLines {
 		_, err := s.accountStore.GetByID(ctx, int64(line.AccountID))
@@ -404,12 +567,13 @@ func (s *CheckService) GenerateCheckSplits(
 			return nil, fmt.Errorf("failed to parse amount: %v", err)
 		}
 
+		lineAmount := line.Amount
 		splits = append(splits, store.Split{
 			AccountID:   int64(line.AccountID),
-			Credit:      &amount,
-			CreditCents: &amountCents,
-			Debit:       nil,
-			DebitCents:  nil,
+			Debit:       &lineAmount,
+			DebitCents:  &amountCents,
+			Credit:      nil,
+			CreditCents: nil,
 			UnitID:      line.UnitID,
 			PeopleID:    line.PeopleID,
 			Status:      "1",
@@ -0,0 +1,579 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+// ColumnMode splits a P&L, balance sheet or trial balance into columns
+type ColumnMode string
+
+const (
+	ColumnsMonth     ColumnMode = "month"
+	ColumnsQuarter   ColumnMode = "quarter"
+	ColumnsYear      ColumnMode = "year"       // year over year, one column per calendar year
+	ColumnsPriorYear ColumnMode = "prior_year" // the period against the same period a year earlier, with the change
+)
+
+// maxComparativeColumns keeps a comparative statement to a size that can still be read
+const maxComparativeColumns = 60
+
+// ParseColumnMode reads a columns= query value
+func ParseColumnMode(value string) (ColumnMode, error) {
+	switch mode := ColumnMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case ColumnsMonth, ColumnsQuarter, ColumnsYear, ColumnsPriorYear:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid columns value: %s", value)
+}
+
+// reportColumn is a column's date range; balances are as of end and have a zero start
+type reportColumn struct {
+	label string
+	start time.Time
+	end   time.Time
+}
+
+// comparativeColumns lays out the columns of a statement from start to end. Month, quarter and
+// year columns are cut at calendar boundaries and clipped to the period; balance columns are
+// balances as of the end of each.
+func comparativeColumns(mode ColumnMode, start, end time.Time, balances bool) ([]reportColumn, error) {
+	if end.Before(start) {
+		return nil, fmt.Errorf("start date must not be after the end date")
+	}
+
+	if mode == ColumnsPriorYear {
+		current := reportColumn{label: start.Format("2006-01-02") + " to " + end.Format("2006-01-02"), start: start, end: end}
+		prior := reportColumn{start: yearEarlier(start), end: yearEarlier(end)}
+		prior.label = prior.start.Format("2006-01-02") + " to " + prior.end.Format("2006-01-02")
+		if balances {
+			current = reportColumn{label: "As of " + end.Format("2006-01-02"), end: end}
+			prior = reportColumn{label: "As of " + prior.end.Format("2006-01-02"), end: prior.end}
+		}
+		return []reportColumn{current, prior}, nil
+	}
+
+	var months int
+	var periodStart time.Time
+	switch mode {
+	case ColumnsMonth:
+		months = 1
+		periodStart = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case ColumnsQuarter:
+		months = 3
+		periodStart = time.Date(start.Year(), (start.Month()-1)/3*3+1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		months = 12
+		periodStart = time.Date(start.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	columns := []reportColumn{}
+	for p := periodStart; !p.After(end); p = p.AddDate(0, months, 0) {
+		if len(columns) == maxComparativeColumns {
+			return nil, fmt.Errorf("too many columns: at most %d are allowed", maxComparativeColumns)
+		}
+
+		column := reportColumn{start: p, end: p.AddDate(0, months, -1)}
+		switch mode {
+		case ColumnsMonth:
+			column.label = p.Format("Jan 2006")
+		case ColumnsQuarter:
+			column.label = fmt.Sprintf("Q%d %d", (p.Month()-1)/3+1, p.Year())
+		default:
+			column.label = p.Format("2006")
+		}
+
+		if column.start.Before(start) {
+			column.start = start
+		}
+		if column.end.After(end) {
+			column.end = end
+		}
+		if balances {
+			column.start = time.Time{}
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// yearEarlier is the same day a year before, 28 February for 29 February
+func yearEarlier(t time.Time) time.Time {
+	earlier := t.AddDate(-1, 0, 0)
+	if earlier.Month() != t.Month() {
+		earlier = earlier.AddDate(0, 0, -earlier.Day())
+	}
+	return earlier
+}
+
+// splitIntoRanges cuts the columns into date ranges that do not overlap, so one grouped query can
+// total them all. It returns the ranges and, for each, the columns it falls in.
+func splitIntoRanges(columns []reportColumn) ([]store.DateRange, [][]int) {
+	cutSet := make(map[time.Time]bool)
+	open := false
+	for _, c := range columns {
+		cutSet[c.end] = true
+		if c.start.IsZero() {
+			open = true
+		} else {
+			cutSet[c.start.AddDate(0, 0, -1)] = true
+		}
+	}
+
+	cuts := make([]time.Time, 0, len(cutSet))
+	for cut := range cutSet {
+		cuts = append(cuts, cut)
+	}
+	sort.Slice(cuts, func(i, j int) bool { return cuts[i].Before(cuts[j]) })
+
+	var ranges []store.DateRange
+	var columnsOf [][]int
+	add := func(from, to time.Time) {
+		var in []int
+		for k, c := range columns {
+			if (c.start.IsZero() || (!from.IsZero() && !from.Before(c.start))) && !to.After(c.end) {
+				in = append(in, k)
+			}
+		}
+		if len(in) == 0 {
+			return
+		}
+
+		r := store.DateRange{End: to.Format("2006-01-02")}
+		if !from.IsZero() {
+			r.Start = from.Format("2006-01-02")
+		}
+		ranges = append(ranges, r)
+		columnsOf = append(columnsOf, in)
+	}
+
+	if open {
+		add(time.Time{}, cuts[0])
+	}
+	for i := 0; i+1 < len(cuts); i++ {
+		add(cuts[i].AddDate(0, 0, 1), cuts[i+1])
+	}
+	return ranges, columnsOf
+}
+
+// comparativeAccount is an account's debits and credits in each column
+type comparativeAccount struct {
+	AccountID         int
+	AccountNumber     int
+	AccountName       string
+	AccountType       string
+	AccountTypeName   string
+	AccountTypeStatus string
+	DebitCents        []int64
+	CreditCents       []int64
+}
+
+func (a *comparativeAccount) balances() []int64 {
+	balances := make([]int64, len(a.DebitCents))
+	for k := range balances {
+		balances[k] = signedBalance(a.AccountTypeStatus, a.DebitCents[k], a.CreditCents[k])
+	}
+	return balances
+}
+
+// comparativeAccounts totals every account in every column, ordered by account number
+func (s *ReportService) comparativeAccounts(ctx context.Context, buildingID int, columns []reportColumn, basis ReportBasis) ([]*comparativeAccount, error) {
+	ranges, columnsOf := splitIntoRanges(columns)
+	totals, err := s.reportStore.GetAccountTotalsByRange(ctx, buildingID, ranges)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make(map[int]*comparativeAccount)
+	account := func(id, number int, name, accountType, typeName, typeStatus string) *comparativeAccount {
+		a, ok := accounts[id]
+		if !ok {
+			a = &comparativeAccount{
+				AccountID:         id,
+				AccountNumber:     number,
+				AccountName:       name,
+				AccountType:       accountType,
+				AccountTypeName:   typeName,
+				AccountTypeStatus: typeStatus,
+				DebitCents:        make([]int64, len(columns)),
+				CreditCents:       make([]int64, len(columns)),
+			}
+			accounts[id] = a
+		}
+		return a
+	}
+
+	for _, t := range totals {
+		a := account(t.AccountID, t.AccountNumber, t.AccountName, t.AccountType, t.AccountTypeName, t.AccountTypeStatus)
+		for _, k := range columnsOf[t.Range] {
+			a.DebitCents[k] += t.DebitCents
+			a.CreditCents[k] += t.CreditCents
+		}
+	}
+
+	if basis == BasisCash {
+		cache := make(map[time.Time]map[int]*cashBasisAdjustment)
+		adjustmentsAsOf := func(date time.Time) (map[int]*cashBasisAdjustment, error) {
+			if adjustments, ok := cache[date]; ok {
+				return adjustments, nil
+			}
+			adjustments, err := s.cashBasisAdjustments(ctx, buildingID, date.Format("2006-01-02"))
+			cache[date] = adjustments
+			return adjustments, err
+		}
+		apply := func(k int, adjustments map[int]*cashBasisAdjustment, sign int64) {
+			for _, adj := range adjustments {
+				a := account(adj.AccountID, adj.AccountNumber, adj.AccountName, adj.AccountType, adj.AccountTypeName, adj.AccountTypeStatus)
+				a.DebitCents[k] += sign * adj.DebitCents
+				a.CreditCents[k] += sign * adj.CreditCents
+			}
+		}
+
+		for k, c := range columns {
+			closing, err := adjustmentsAsOf(c.end)
+			if err != nil {
+				return nil, err
+			}
+			apply(k, closing, 1)
+
+			if !c.start.IsZero() {
+				opening, err := adjustmentsAsOf(c.start.AddDate(0, 0, -1))
+				if err != nil {
+					return nil, err
+				}
+				apply(k, opening, -1)
+			}
+		}
+	}
+
+	sorted := make([]*comparativeAccount, 0, len(accounts))
+	for _, a := range accounts {
+		sorted = append(sorted, a)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].AccountNumber < sorted[j].AccountNumber
+	})
+	return sorted, nil
+}
+
+// comparativeAmounts formats one amount per column, with the change from the second column to
+// the first when comparing against last year
+func comparativeAmounts(format money.Formatter, cents []int64, compare bool) (dto.ComparativeAmounts, error) {
+	amounts := dto.ComparativeAmounts{Amounts: make([]string, len(cents))}
+	for k, c := range cents {
+		amounts.Amounts[k] = format.Cents(c)
+	}
+
+	if compare && len(cents) == 2 {
+		change := cents[0] - cents[1]
+		formatted := format.Cents(change)
+		amounts.Change = &formatted
+		if cents[1] != 0 {
+			percent, err := money.Percent(change, abs64(cents[1]))
+			if err != nil {
+				return amounts, err
+			}
+			amounts.ChangePercent = &percent
+		}
+	}
+	return amounts, nil
+}
+
+// comparativeSection collects a statement section's rows and column totals
+type comparativeSection struct {
+	name    string
+	format  money.Formatter
+	compare bool
+	rows    []dto.ComparativeAccountRow
+	total   []int64
+}
+
+func newComparativeSection(name string, format money.Formatter, columns int, compare bool) *comparativeSection {
+	return &comparativeSection{name: name, format: format, compare: compare, rows: []dto.ComparativeAccountRow{}, total: make([]int64, columns)}
+}
+
+// add adds a row of balances, leaving out rows that are zero in every column
+func (c *comparativeSection) add(accountID, accountNumber int, accountName, accountType string, balances []int64) error {
+	if allZero(balances) {
+		return nil
+	}
+
+	amounts, err := comparativeAmounts(c.format, balances, c.compare)
+	if err != nil {
+		return err
+	}
+	c.rows = append(c.rows, dto.ComparativeAccountRow{
+		AccountID:          accountID,
+		AccountNumber:      accountNumber,
+		AccountName:        accountName,
+		AccountType:        accountType,
+		ComparativeAmounts: amounts,
+	})
+	for k, b := range balances {
+		c.total[k] += b
+	}
+	return nil
+}
+
+func (c *comparativeSection) section() (dto.ComparativeSection, error) {
+	total, err := comparativeAmounts(c.format, c.total, c.compare)
+	return dto.ComparativeSection{SectionName: c.name, Accounts: c.rows, Total: total}, err
+}
+
+func allZero(values []int64) bool {
+	for _, v := range values {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func comparativeColumnDtos(columns []reportColumn) []dto.ComparativeColumn {
+	dtos := []dto.ComparativeColumn{}
+	for _, c := range columns {
+		column := dto.ComparativeColumn{Label: c.label, EndDate: c.end.Format("2006-01-02")}
+		if !c.start.IsZero() {
+			column.StartDate = c.start.Format("2006-01-02")
+		}
+		dtos = append(dtos, column)
+	}
+	return dtos
+}
+
+// comparativePeriod parses a statement's dates. Without a start date the month, quarter and year
+// columns start with the end date's year.
+func comparativePeriod(startDate, endDate string) (time.Time, time.Time, error) {
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end date: %s", endDate)
+	}
+	if startDate == "" {
+		return time.Date(end.Year(), time.January, 1, 0, 0, 0, 0, time.UTC), end, nil
+	}
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start date: %s", startDate)
+	}
+	return start, end, nil
+}
+
+// GetComparativeProfitAndLoss reports income and expenses for startDate to endDate in columns
+func (s *ReportService) GetComparativeProfitAndLoss(ctx context.Context, buildingID int, startDate string, endDate string, mode ColumnMode, basis ReportBasis) (*dto.ComparativeProfitAndLossResponse, error) {
+	start, end, err := comparativePeriod(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := comparativeColumns(mode, start, end, false)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := s.comparativeAccounts(ctx, buildingID, columns, basis)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := s.format.Accounting()
+	compare := mode == ColumnsPriorYear
+	income := newComparativeSection("Income", statement, len(columns), compare)
+	expenses := newComparativeSection("Expense", statement, len(columns), compare)
+	for _, a := range accounts {
+		section := income
+		switch strings.ToLower(a.AccountType) {
+		case "income":
+		case "expense":
+			section = expenses
+		default:
+			continue
+		}
+		if err := section.add(a.AccountID, a.AccountNumber, a.AccountName, a.AccountTypeName, a.balances()); err != nil {
+			return nil, err
+		}
+	}
+
+	netCents := make([]int64, len(columns))
+	for k := range netCents {
+		netCents[k] = income.total[k] - expenses.total[k]
+	}
+
+	response := &dto.ComparativeProfitAndLossResponse{
+		BuildingID: buildingID,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Basis:      string(basis),
+		ColumnMode: string(mode),
+		Columns:    comparativeColumnDtos(columns),
+	}
+	if response.Income, err = income.section(); err != nil {
+		return nil, err
+	}
+	if response.Expenses, err = expenses.section(); err != nil {
+		return nil, err
+	}
+	if response.NetProfitLoss, err = comparativeAmounts(statement, netCents, compare); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetComparativeBalanceSheet reports balances in columns as of the end of each period from
+// startDate up to asOfDate, or as of asOfDate against a year earlier
+func (s *ReportService) GetComparativeBalanceSheet(ctx context.Context, buildingID int, startDate string, asOfDate string, mode ColumnMode, basis ReportBasis) (*dto.ComparativeBalanceSheetResponse, error) {
+	start, end, err := comparativePeriod(startDate, asOfDate)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := comparativeColumns(mode, start, end, true)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := s.comparativeAccounts(ctx, buildingID, columns, basis)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := s.format.Accounting()
+	compare := mode == ColumnsPriorYear
+	assets := newComparativeSection("Assets", statement, len(columns), compare)
+	liabilities := newComparativeSection("Liabilities", statement, len(columns), compare)
+	equity := newComparativeSection("Equity", statement, len(columns), compare)
+	netIncome := make([]int64, len(columns))
+	for _, a := range accounts {
+		balances := a.balances()
+
+		var section *comparativeSection
+		switch strings.ToLower(a.AccountType) {
+		case "asset":
+			section = assets
+		case "liability":
+			section = liabilities
+		case "equity":
+			section = equity
+		case "income":
+			for k, b := range balances {
+				netIncome[k] += b
+			}
+			continue
+		case "expense":
+			for k, b := range balances {
+				netIncome[k] -= b
+			}
+			continue
+		default:
+			continue
+		}
+		if err := section.add(a.AccountID, a.AccountNumber, a.AccountName, a.AccountType, balances); err != nil {
+			return nil, err
+		}
+	}
+
+	// 0 indicates a calculated value, not an actual account
+	if err := equity.add(0, 0, "Net Income", "Net Income", netIncome); err != nil {
+		return nil, err
+	}
+
+	liabilitiesAndEquity := make([]int64, len(columns))
+	for k := range liabilitiesAndEquity {
+		liabilitiesAndEquity[k] = liabilities.total[k] + equity.total[k]
+	}
+
+	response := &dto.ComparativeBalanceSheetResponse{
+		BuildingID: buildingID,
+		AsOfDate:   asOfDate,
+		Basis:      string(basis),
+		ColumnMode: string(mode),
+		Columns:    comparativeColumnDtos(columns),
+	}
+	if response.Assets, err = assets.section(); err != nil {
+		return nil, err
+	}
+	if response.Liabilities, err = liabilities.section(); err != nil {
+		return nil, err
+	}
+	if response.Equity, err = equity.section(); err != nil {
+		return nil, err
+	}
+	if response.TotalAssets, err = comparativeAmounts(statement, assets.total, compare); err != nil {
+		return nil, err
+	}
+	if response.TotalLiabilitiesAndEquity, err = comparativeAmounts(statement, liabilitiesAndEquity, compare); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetComparativeTrialBalance reports debit and credit balances in columns, laid out like the
+// comparative balance sheet
+func (s *ReportService) GetComparativeTrialBalance(ctx context.Context, buildingID int, startDate string, asOfDate string, mode ColumnMode) (*dto.ComparativeTrialBalanceResponse, error) {
+	start, end, err := comparativePeriod(startDate, asOfDate)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := comparativeColumns(mode, start, end, true)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := s.comparativeAccounts(ctx, buildingID, columns, BasisAccrual)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := s.format.Accounting()
+	compare := mode == ColumnsPriorYear
+	rows := []dto.ComparativeTrialBalanceRow{}
+	totalDebit := make([]int64, len(columns))
+	totalCredit := make([]int64, len(columns))
+	for _, a := range accounts {
+		// an account's net balance goes on whichever side it falls, as on the trial balance
+		debits := make([]int64, len(columns))
+		credits := make([]int64, len(columns))
+		for k := range columns {
+			if net := a.DebitCents[k] - a.CreditCents[k]; net >= 0 {
+				debits[k] = net
+			} else {
+				credits[k] = -net
+			}
+			totalDebit[k] += debits[k]
+			totalCredit[k] += credits[k]
+		}
+		if allZero(debits) && allZero(credits) {
+			continue
+		}
+
+		row := dto.ComparativeTrialBalanceRow{
+			AccountID:     a.AccountID,
+			AccountNumber: a.AccountNumber,
+			AccountName:   a.AccountName,
+			AccountType:   a.AccountType,
+		}
+		if row.Debit, err = comparativeAmounts(statement, debits, compare); err != nil {
+			return nil, err
+		}
+		if row.Credit, err = comparativeAmounts(statement, credits, compare); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+
+	response := &dto.ComparativeTrialBalanceResponse{
+		BuildingID: buildingID,
+		AsOfDate:   asOfDate,
+		ColumnMode: string(mode),
+		Columns:    comparativeColumnDtos(columns),
+		Accounts:   rows,
+	}
+	if response.TotalDebit, err = comparativeAmounts(statement, totalDebit, compare); err != nil {
+		return nil, err
+	}
+	if response.TotalCredit, err = comparativeAmounts(statement, totalCredit, compare); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -35,6 +35,7 @@ type CreditMemoService struct {
 	transactionStore TransactionStore
 	splitStore       SplitStore
 	accountStore     AccountStore
+	currencyService  *CurrencyService
 }
 
 /*
@@ -49,6 +50,7 @@ func NewCreditMemoService(
 	transactionStore TransactionStore,
 	splitStore SplitStore,
 	accountStore AccountStore,
+	currencyService *CurrencyService,
 ) *CreditMemoService {
 	return &CreditMemoService{
 		db:               db,
@@ -56,6 +58,7 @@ func NewCreditMemoService(
 		transactionStore: transactionStore,
 		splitStore:       splitStore,
 		accountStore:     accountStore,
+		currencyService:  currencyService,
 	}
 }
 
@@ -159,6 +162,10 @@ func (s *CreditMemoService) Create(
 			return err
 		}
 
+		if err := s.currencyService.requireBaseCurrency(ctx, splits); err != nil {
+			return err
+		}
+
 		for _, split := range splits {
 			split.TransactionID = *transactionID
 			if err := s.splitStore.Create(ctx, tx, &split); err != nil {
@@ -209,6 +216,21 @@ func (s *CreditMemoService) Update(
 			return fmt.Errorf("credit memo not found: %v", err)
 		}
 
+		// unapplied credit from a received payment shares the payment's transaction
+		existingTransaction, err := s.transactionStore.GetByID(ctx, existingCM.TransactionID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch transaction: %v", err)
+		}
+		if existingTransaction.Type == "payment" {
+			return fmt.Errorf("credit memo was created by a received payment and cannot be edited")
+		}
+
+		if reason, err := s.splitStore.GetLockReason(ctx, tx, existingCM.TransactionID); err != nil {
+			return err
+		} else if reason != "" {
+			return fmt.Errorf("credit memo cannot be edited: %s", reason)
+		}
+
 		// 2️⃣ Update transaction
 		transaction := &store.Transaction{
 			ID:                existingCM.TransactionID,
@@ -243,6 +265,10 @@ func (s *CreditMemoService) Update(
 			return fmt.Errorf("splits not balanced: %v", err)
 		}
 
+		if err := s.currencyService.requireBaseCurrency(ctx, splits); err != nil {
+			return err
+		}
+
 		// 6️⃣ Save new splits
 		for _, split := range splits {
 			split.TransactionID = *transactionID
@@ -0,0 +1,659 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+/*
+|--------------------------------------------------------------------------
+| Interfaces
+|--------------------------------------------------------------------------
+*/
+
+type ExchangeRateStore interface {
+	GetAll(ctx context.Context, buildingID int64, currency *string) ([]store.ExchangeRate, error)
+	GetByID(ctx context.Context, id int64) (*store.ExchangeRate, error)
+	GetRate(ctx context.Context, buildingID int64, currency string, date string) (*store.ExchangeRate, error)
+	Save(ctx context.Context, r *store.ExchangeRate) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type FXRevaluationStore interface {
+	GetAll(ctx context.Context, buildingID int64) ([]store.FXRevaluation, error)
+	GetByID(ctx context.Context, id int64) (*store.FXRevaluation, error)
+	GetLines(ctx context.Context, revaluationID int64) ([]store.FXRevaluationLine, error)
+	Create(ctx context.Context, tx *sql.Tx, r *store.FXRevaluation) error
+	CreateLine(ctx context.Context, tx *sql.Tx, l *store.FXRevaluationLine) error
+	GetOpenDocuments(ctx context.Context, buildingID int64, asOfDate string) ([]store.FXOpenDocument, error)
+	GetAccountBalances(ctx context.Context, buildingID int64, asOfDate string) ([]store.FXAccountBalance, error)
+}
+
+/*
+|--------------------------------------------------------------------------
+| Service
+|--------------------------------------------------------------------------
+*/
+
+// CurrencyService keeps the daily exchange rates of a building and converts foreign currency
+// documents and payments to the base currency. Ledger amounts are always base currency; splits
+// on foreign currency accounts also carry their amount in the account's currency.
+type CurrencyService struct {
+	db                 *sql.DB
+	exchangeRateStore  ExchangeRateStore
+	fxRevaluationStore FXRevaluationStore
+	buildingStore      BuildingStore
+	accountStore       AccountStore
+	transactionStore   TransactionStore
+	splitStore         SplitStore
+}
+
+// fxRate is the rate a document or payment is converted at. Currency is nil in base currency.
+type fxRate struct {
+	Currency   *string
+	RateScaled int64
+}
+
+// fxSettlement is how a payment in a document's currency settles it in base currency
+type fxSettlement struct {
+	ReliefCents  int64 // taken off the document's base balance
+	CashCents    int64 // the payment converted at its own rate
+	ForeignCents int64
+}
+
+/*
+|--------------------------------------------------------------------------
+| Constructor
+|--------------------------------------------------------------------------
+*/
+
+func NewCurrencyService(
+	db *sql.DB,
+	exchangeRateStore ExchangeRateStore,
+	fxRevaluationStore FXRevaluationStore,
+	buildingStore BuildingStore,
+	accountStore AccountStore,
+	transactionStore TransactionStore,
+	splitStore SplitStore,
+) *CurrencyService {
+	return &CurrencyService{
+		db:                 db,
+		exchangeRateStore:  exchangeRateStore,
+		fxRevaluationStore: fxRevaluationStore,
+		buildingStore:      buildingStore,
+		accountStore:       accountStore,
+		transactionStore:   transactionStore,
+		splitStore:         splitStore,
+	}
+}
+
+/*
+|--------------------------------------------------------------------------
+| Queries
+|--------------------------------------------------------------------------
+*/
+
+func (s *CurrencyService) GetRates(ctx context.Context, buildingID int64, currency *string) ([]dto.ExchangeRateDto, error) {
+	rates, err := s.exchangeRateStore.GetAll(ctx, buildingID, currency)
+	if err != nil {
+		return nil, err
+	}
+	return dto.MapExchangeRatesToDto(rates), nil
+}
+
+func (s *CurrencyService) GetRevaluations(ctx context.Context, buildingID int64) ([]dto.FXRevaluationDto, error) {
+	revaluations, err := s.fxRevaluationStore.GetAll(ctx, buildingID)
+	if err != nil {
+		return nil, err
+	}
+
+	dtoRevaluations := []dto.FXRevaluationDto{}
+	for _, r := range revaluations {
+		lines, err := s.fxRevaluationStore.GetLines(ctx, r.ID)
+		if err != nil {
+			return nil, err
+		}
+		revaluation := dto.MapFXRevaluationToDto(r, lines)
+		revaluation.Lines = nil
+		dtoRevaluations = append(dtoRevaluations, revaluation)
+	}
+	return dtoRevaluations, nil
+}
+
+func (s *CurrencyService) GetRevaluation(ctx context.Context, id int64) (*dto.FXRevaluationDto, error) {
+	r, err := s.fxRevaluationStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.fxRevaluationStore.GetLines(ctx, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	revaluation := dto.MapFXRevaluationToDto(*r, lines)
+	return &revaluation, nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Commands
+|--------------------------------------------------------------------------
+*/
+
+// SaveRate records the day's rate of a currency, replacing one already entered for that day
+func (s *CurrencyService) SaveRate(ctx context.Context, req dto.ExchangeRatePayload) (*dto.ExchangeRateDto, error) {
+	building, err := s.buildingStore.GetByID(ctx, req.BuildingID)
+	if err != nil {
+		return nil, err
+	}
+
+	currency, err := money.NormalizeCurrency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if currency == building.BaseCurrency {
+		return nil, fmt.Errorf("%s is the building's base currency", currency)
+	}
+
+	if _, err := time.Parse("2006-01-02", req.RateDate); err != nil {
+		return nil, fmt.Errorf("invalid rate date")
+	}
+
+	rateScaled, err := money.ParseExchangeRate(strconv.FormatFloat(req.Rate, 'f', -1, 64))
+	if err != nil {
+		return nil, err
+	}
+
+	rate := &store.ExchangeRate{
+		BuildingID: req.BuildingID,
+		Currency:   currency,
+		RateDate:   req.RateDate,
+		RateScaled: rateScaled,
+	}
+	if err := s.exchangeRateStore.Save(ctx, rate); err != nil {
+		return nil, err
+	}
+
+	saved, err := s.exchangeRateStore.GetByID(ctx, rate.ID)
+	if err != nil {
+		return nil, err
+	}
+	rateDto := dto.MapExchangeRateToDto(*saved)
+	return &rateDto, nil
+}
+
+func (s *CurrencyService) DeleteRate(ctx context.Context, id int64) error {
+	return s.exchangeRateStore.Delete(ctx, id)
+}
+
+// Revalue restates open foreign currency invoices, bills and account balances at the rate of the
+// date. The unrealized difference goes to the building's FX gain/loss account and is reversed the
+// next day, so later payments still realize against the document rate.
+func (s *CurrencyService) Revalue(ctx context.Context, req dto.CreateFXRevaluationRequest) (*dto.FXRevaluationDto, error) {
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date")
+	}
+	reversalDate := date.AddDate(0, 0, 1).Format("2006-01-02")
+
+	building, err := s.buildingStore.GetByID(ctx, req.BuildingID)
+	if err != nil {
+		return nil, err
+	}
+	fxAccountID, err := s.gainLossAccount(ctx, building)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := s.fxRevaluationStore.GetOpenDocuments(ctx, req.BuildingID, req.Date)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := s.fxRevaluationStore.GetAccountBalances(ctx, req.BuildingID, req.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	// carrying and revalued amounts are debit positive, so a bill's balance is negative
+	var lines []store.FXRevaluationLine
+	var splits []store.Split
+	rates := map[string]int64{}
+
+	for _, d := range documents {
+		rateScaled, err := s.revaluationRate(ctx, rates, req.BuildingID, d.Currency, req.Date)
+		if err != nil {
+			return nil, err
+		}
+
+		carrying := d.BalanceCents
+		foreign, err := openForeignCents(d.BalanceCents, d.AmountCents, d.ForeignAmountCents)
+		if err != nil {
+			return nil, err
+		}
+		if d.SourceType == "bill" {
+			carrying, foreign = -carrying, -foreign
+		}
+		revalued, err := money.ConvertCents(foreign, rateScaled)
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, store.FXRevaluationLine{
+			SourceType:      d.SourceType,
+			SourceID:        d.SourceID,
+			AccountID:       d.AccountID,
+			Currency:        d.Currency,
+			ForeignCents:    foreign,
+			RateScaled:      rateScaled,
+			CarryingCents:   carrying,
+			RevaluedCents:   revalued,
+			AdjustmentCents: revalued - carrying,
+		})
+		if split, ok := adjustmentSplit(d.AccountID, revalued-carrying, d.UnitID, d.PeopleID); ok {
+			splits = append(splits, split)
+		}
+	}
+
+	for _, b := range balances {
+		if b.Currency == building.BaseCurrency {
+			continue
+		}
+		if b.UntaggedSplits > 0 {
+			return nil, fmt.Errorf("account %s has %d postings without a %s amount and cannot be revalued",
+				b.AccountName, b.UntaggedSplits, b.Currency)
+		}
+		if b.BalanceCents == 0 && b.ForeignCents == 0 {
+			continue
+		}
+
+		rateScaled, err := s.revaluationRate(ctx, rates, req.BuildingID, b.Currency, req.Date)
+		if err != nil {
+			return nil, err
+		}
+		revalued, err := money.ConvertCents(b.ForeignCents, rateScaled)
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, store.FXRevaluationLine{
+			SourceType:      "account",
+			SourceID:        b.AccountID,
+			AccountID:       b.AccountID,
+			Currency:        b.Currency,
+			ForeignCents:    b.ForeignCents,
+			RateScaled:      rateScaled,
+			CarryingCents:   b.BalanceCents,
+			RevaluedCents:   revalued,
+			AdjustmentCents: revalued - b.BalanceCents,
+		})
+		if split, ok := adjustmentSplit(b.AccountID, revalued-b.BalanceCents, nil, nil); ok {
+			// the balance in the account's own currency does not move
+			zero := int64(0)
+			split.ForeignCents = &zero
+			splits = append(splits, split)
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("nothing in a foreign currency is open on %s", req.Date)
+	}
+
+	var gainCents int64
+	for _, l := range lines {
+		gainCents += l.AdjustmentCents
+	}
+	if split, ok := adjustmentSplit(fxAccountID, -gainCents, nil, nil); ok {
+		splits = append(splits, split)
+	}
+
+	var revaluation store.FXRevaluation
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		transactionID, err := s.postRevaluation(ctx, tx, req.BuildingID, req.Date, "FX revaluation "+req.Date, splits, false)
+		if err != nil {
+			return err
+		}
+		reversalID, err := s.postRevaluation(ctx, tx, req.BuildingID, reversalDate, "Reversal of FX revaluation "+req.Date, splits, true)
+		if err != nil {
+			return err
+		}
+
+		revaluation = store.FXRevaluation{
+			BuildingID:            req.BuildingID,
+			RevaluationDate:       req.Date,
+			TransactionID:         transactionID,
+			ReversalTransactionID: reversalID,
+		}
+		if err := s.fxRevaluationStore.Create(ctx, tx, &revaluation); err != nil {
+			return fmt.Errorf("a revaluation already exists for %s: %v", req.Date, err)
+		}
+
+		for i := range lines {
+			lines[i].RevaluationID = revaluation.ID
+			if err := s.fxRevaluationStore.CreateLine(ctx, tx, &lines[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetRevaluation(ctx, revaluation.ID)
+}
+
+/*
+|--------------------------------------------------------------------------
+| Helpers
+|--------------------------------------------------------------------------
+*/
+
+// documentRate works out the rate a document or payment in currency is posted at: the rate given
+// with it, else the building's latest rate on or before the date. The base currency needs none.
+func (s *CurrencyService) documentRate(ctx context.Context, buildingID int64, currency *string, date string, explicitRate *float64) (fxRate, error) {
+	if currency == nil || *currency == "" {
+		return fxRate{}, nil
+	}
+
+	building, err := s.buildingStore.GetByID(ctx, buildingID)
+	if err != nil {
+		return fxRate{}, fmt.Errorf("building not found: %v", err)
+	}
+
+	code, err := money.NormalizeCurrency(*currency)
+	if err != nil {
+		return fxRate{}, err
+	}
+	if code == building.BaseCurrency {
+		return fxRate{}, nil
+	}
+
+	if explicitRate != nil {
+		rateScaled, err := money.ParseExchangeRate(strconv.FormatFloat(*explicitRate, 'f', -1, 64))
+		if err != nil {
+			return fxRate{}, err
+		}
+		return fxRate{Currency: &code, RateScaled: rateScaled}, nil
+	}
+
+	rate, err := s.exchangeRateStore.GetRate(ctx, buildingID, code, date)
+	if err != nil {
+		if err == store.ErrNotFound {
+			return fxRate{}, fmt.Errorf("no %s exchange rate on or before %s", code, date)
+		}
+		return fxRate{}, err
+	}
+	return fxRate{Currency: &code, RateScaled: rate.RateScaled}, nil
+}
+
+// toBase converts cents in the rate's currency to base currency
+func (r fxRate) toBase(cents int64) (int64, error) {
+	if r.Currency == nil {
+		return cents, nil
+	}
+	return money.ConvertCents(cents, r.RateScaled)
+}
+
+// foreignAmounts returns the rate and the amount in the rate's currency a document or payment of
+// amountCents stores; both are nil in base currency
+func (r fxRate) foreignAmounts(amountCents int64) (*int64, *int64) {
+	if r.Currency == nil {
+		return nil, nil
+	}
+	rateScaled := r.RateScaled
+	return &rateScaled, &amountCents
+}
+
+// convertSplits converts a document's splits, written in its currency, to base currency. The
+// balancing A/R or A/P split is converted as a whole and any rounding left over is taken up by
+// the largest other split. Splits on foreign currency accounts keep their amount in ForeignCents.
+func (s *CurrencyService) convertSplits(ctx context.Context, splits []store.Split, rate fxRate, balancingAccountID int64) error {
+	for i := range splits {
+		foreign, err := s.accountForeignCents(ctx, splits[i].AccountID, rate.Currency, signedCents(splits[i]))
+		if err != nil {
+			return err
+		}
+		if foreign != nil && splits[i].AccountID == balancingAccountID {
+			return fmt.Errorf("A/R and A/P accounts are kept in the base currency")
+		}
+		splits[i].ForeignCents = foreign
+	}
+
+	if rate.Currency == nil {
+		return nil
+	}
+
+	var net int64
+	largest := -1
+	for i := range splits {
+		cents, err := rate.toBase(signedCents(splits[i]))
+		if err != nil {
+			return err
+		}
+		setSignedCents(&splits[i], cents)
+		net += cents
+
+		if splits[i].AccountID != balancingAccountID && (largest < 0 || abs64(cents) > abs64(signedCents(splits[largest]))) {
+			largest = i
+		}
+	}
+
+	if net != 0 && largest >= 0 {
+		setSignedCents(&splits[largest], signedCents(splits[largest])-net)
+	}
+	return nil
+}
+
+// accountForeignCents checks a posting of signedCents in currency (nil for base) against the
+// account's currency and returns the amount to keep on the split in the account's currency
+func (s *CurrencyService) accountForeignCents(ctx context.Context, accountID int64, currency *string, signedCents int64) (*int64, error) {
+	account, err := s.accountStore.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found: %v", err)
+	}
+	if account.Currency == nil {
+		return nil, nil
+	}
+
+	building, err := s.buildingStore.GetByID(ctx, account.BuildingID)
+	if err != nil {
+		return nil, fmt.Errorf("building not found: %v", err)
+	}
+	if *account.Currency == building.BaseCurrency {
+		return nil, nil
+	}
+
+	if currency == nil || *currency != *account.Currency {
+		return nil, fmt.Errorf("account %s is kept in %s and only takes %s postings",
+			account.AccountName, *account.Currency, *account.Currency)
+	}
+	return &signedCents, nil
+}
+
+// requireBaseCurrency rejects splits on foreign currency accounts from documents that only post in
+// the base currency. Those splits would carry no amount in the account's currency and the account
+// could no longer be revalued.
+func (s *CurrencyService) requireBaseCurrency(ctx context.Context, splits []store.Split) error {
+	for _, split := range splits {
+		if _, err := s.accountForeignCents(ctx, split.AccountID, nil, signedCents(split)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// settle works out how paidCents in a foreign document's currency settles it. The base relief is
+// the document's own rate, so paying off the whole foreign balance clears the base balance exactly.
+// Foreign currency payments cannot exceed the balance.
+func (s *CurrencyService) settle(rate fxRate, amountCents, foreignAmountCents, balanceCents, paidCents int64) (fxSettlement, error) {
+	if paidCents <= 0 {
+		return fxSettlement{}, fmt.Errorf("amount must be greater than 0")
+	}
+
+	openForeign, err := openForeignCents(balanceCents, amountCents, foreignAmountCents)
+	if err != nil {
+		return fxSettlement{}, err
+	}
+	if paidCents > openForeign {
+		return fxSettlement{}, fmt.Errorf("payment exceeds the open balance. Balance: %s, Requested: %s",
+			money.FormatMoneyFromCents(max(openForeign, 0)), money.FormatMoneyFromCents(paidCents))
+	}
+
+	relief := balanceCents
+	if paidCents < openForeign {
+		relief, err = scaleCents(paidCents, foreignAmountCents, amountCents)
+		if err != nil {
+			return fxSettlement{}, err
+		}
+	}
+
+	cash, err := rate.toBase(paidCents)
+	if err != nil {
+		return fxSettlement{}, err
+	}
+
+	return fxSettlement{
+		ReliefCents:  relief,
+		CashCents:    cash,
+		ForeignCents: paidCents,
+	}, nil
+}
+
+func (s *CurrencyService) gainLossAccount(ctx context.Context, building *store.Building) (int64, error) {
+	if building.FXGainLossAccountID == nil {
+		return 0, fmt.Errorf("building has no FX gain/loss account")
+	}
+	return *building.FXGainLossAccountID, nil
+}
+
+// realizedSplit books a realized gain (loss when negative) against the building's FX account
+func (s *CurrencyService) realizedSplit(ctx context.Context, transactionID, buildingID, gainCents int64, unitID, peopleID *int64) ([]store.Split, error) {
+	if gainCents == 0 {
+		return nil, nil
+	}
+
+	building, err := s.buildingStore.GetByID(ctx, buildingID)
+	if err != nil {
+		return nil, fmt.Errorf("building not found: %v", err)
+	}
+	accountID, err := s.gainLossAccount(ctx, building)
+	if err != nil {
+		return nil, err
+	}
+
+	if gainCents > 0 {
+		return []store.Split{newCreditSplit(transactionID, accountID, gainCents, unitID, peopleID)}, nil
+	}
+	return []store.Split{newDebitSplit(transactionID, accountID, -gainCents, unitID, peopleID)}, nil
+}
+
+func (s *CurrencyService) revaluationRate(ctx context.Context, rates map[string]int64, buildingID int64, currency, date string) (int64, error) {
+	if rateScaled, ok := rates[currency]; ok {
+		return rateScaled, nil
+	}
+
+	rate, err := s.documentRate(ctx, buildingID, &currency, date, nil)
+	if err != nil {
+		return 0, err
+	}
+	rates[currency] = rate.RateScaled
+	return rate.RateScaled, nil
+}
+
+func (s *CurrencyService) postRevaluation(ctx context.Context, tx *sql.Tx, buildingID int64, date, memo string, splits []store.Split, reverse bool) (int64, error) {
+	transactionID, err := s.transactionStore.Create(ctx, tx, &store.Transaction{
+		Type:              "fx revaluation",
+		TransactionDate:   date,
+		TransactionNumber: "",
+		Memo:              memo,
+		Status:            "1",
+		BuildingID:        buildingID,
+		UserID:            1, // TODO: get user id from jwt
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, split := range splits {
+		split.TransactionID = *transactionID
+		if reverse {
+			split.Debit, split.Credit = split.Credit, split.Debit
+			split.DebitCents, split.CreditCents = split.CreditCents, split.DebitCents
+		}
+		if err := s.splitStore.Create(ctx, tx, &split); err != nil {
+			return 0, err
+		}
+	}
+	return *transactionID, nil
+}
+
+// openForeignCents scales a base balance to the document's currency by the document's own rate
+func openForeignCents(balanceCents, amountCents, foreignAmountCents int64) (int64, error) {
+	return scaleCents(balanceCents, amountCents, foreignAmountCents)
+}
+
+// scaleCents is cents of fromTotal expressed as a share of toTotal, exact for the whole amount
+func scaleCents(cents, fromTotal, toTotal int64) (int64, error) {
+	if fromTotal == 0 {
+		return 0, nil
+	}
+	if cents == fromTotal {
+		return toTotal, nil
+	}
+	scaled, err := money.MulDiv(cents, toTotal, fromTotal)
+	if err != nil {
+		return 0, fmt.Errorf("converted amount out of allowed range: %v", err)
+	}
+	return scaled, nil
+}
+
+// adjustmentSplit debits a positive adjustment and credits a negative one
+func adjustmentSplit(accountID, cents int64, unitID, peopleID *int64) (store.Split, bool) {
+	switch {
+	case cents > 0:
+		return newDebitSplit(0, accountID, cents, unitID, peopleID), true
+	case cents < 0:
+		return newCreditSplit(0, accountID, -cents, unitID, peopleID), true
+	}
+	return store.Split{}, false
+}
+
+// signedCents is a split's amount, debit positive
+func signedCents(sp store.Split) int64 {
+	var cents int64
+	if sp.DebitCents != nil {
+		cents += *sp.DebitCents
+	}
+	if sp.CreditCents != nil {
+		cents -= *sp.CreditCents
+	}
+	return cents
+}
+
+// setSignedCents posts a debit positive amount on the side it belongs to
+func setSignedCents(sp *store.Split, cents int64) {
+	sp.Debit, sp.DebitCents, sp.Credit, sp.CreditCents = nil, nil, nil, nil
+	if cents >= 0 {
+		amount := float64(cents) / float64(money.MoneyScale)
+		sp.Debit, sp.DebitCents = &amount, &cents
+		return
+	}
+	cents = -cents
+	amount := float64(cents) / float64(money.MoneyScale)
+	sp.Credit, sp.CreditCents = &amount, &cents
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+// txDriver is a database/sql driver whose transactions do nothing, so services can run withTx
+// against the in-memory stores below
+type txDriver struct{}
+type txConn struct{}
+
+func (txDriver) Open(string) (driver.Conn, error) { return txConn{}, nil }
+
+func (txConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("no statements") }
+func (txConn) Close() error                        { return nil }
+func (txConn) Begin() (driver.Tx, error)           { return txConn{}, nil }
+func (txConn) Commit() error                       { return nil }
+func (txConn) Rollback() error                     { return nil }
+
+func init() {
+	sql.Register("service_test", txDriver{})
+}
+
+// ledger holds the accounts, transactions and splits of one building in memory. Each store below
+// embeds its interface and implements only what the services under test call.
+type ledger struct {
+	building     store.Building
+	accounts     map[int64]*store.Account
+	transactions []store.Transaction
+	splits       []store.Split
+	rateScaled   int64
+	revaluations []store.FXRevaluation
+	revalLines   []store.FXRevaluationLine
+}
+
+type ledgerAccountStore struct {
+	AccountStore
+	l *ledger
+}
+
+func (s ledgerAccountStore) GetByID(ctx context.Context, id int64) (*store.Account, error) {
+	if a, ok := s.l.accounts[id]; ok {
+		return a, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+type ledgerBuildingStore struct {
+	BuildingStore
+	l *ledger
+}
+
+func (s ledgerBuildingStore) GetByID(ctx context.Context, id int64) (*store.Building, error) {
+	return &s.l.building, nil
+}
+
+type ledgerTransactionStore struct {
+	TransactionStore
+	l *ledger
+}
+
+func (s ledgerTransactionStore) Create(ctx context.Context, tx *sql.Tx, t *store.Transaction) (*int64, error) {
+	id := int64(len(s.l.transactions) + 1)
+	t.ID = id
+	s.l.transactions = append(s.l.transactions, *t)
+	return &id, nil
+}
+
+type ledgerSplitStore struct {
+	SplitStore
+	l *ledger
+}
+
+func (s ledgerSplitStore) Create(ctx context.Context, tx *sql.Tx, split *store.Split) error {
+	s.l.splits = append(s.l.splits, *split)
+	return nil
+}
+
+type ledgerCheckStore struct{ CheckStore }
+
+func (ledgerCheckStore) Create(ctx context.Context, tx *sql.Tx, c *store.Check) (*int64, error) {
+	id := int64(1)
+	return &id, nil
+}
+
+type ledgerExpenseLineStore struct{ ExpenseLineStore }
+
+func (ledgerExpenseLineStore) Create(ctx context.Context, tx *sql.Tx, l *store.ExpenseLine) (*int64, error) {
+	id := int64(1)
+	return &id, nil
+}
+
+type ledgerApprovalStore struct{ ApprovalStore }
+
+func (ledgerApprovalStore) GetRequiredThresholds(ctx context.Context, buildingID int64, documentType string, amountCents int64) ([]store.ApprovalThreshold, error) {
+	return nil, nil
+}
+
+type ledgerExchangeRateStore struct {
+	ExchangeRateStore
+	l *ledger
+}
+
+func (s ledgerExchangeRateStore) GetRate(ctx context.Context, buildingID int64, currency string, date string) (*store.ExchangeRate, error) {
+	return &store.ExchangeRate{BuildingID: buildingID, Currency: currency, RateDate: date, RateScaled: s.l.rateScaled}, nil
+}
+
+type ledgerFXRevaluationStore struct {
+	FXRevaluationStore
+	l *ledger
+}
+
+func (s ledgerFXRevaluationStore) GetOpenDocuments(ctx context.Context, buildingID int64, asOfDate string) ([]store.FXOpenDocument, error) {
+	return nil, nil
+}
+
+// GetAccountBalances sums the splits on every foreign currency account, as the SQL store does
+func (s ledgerFXRevaluationStore) GetAccountBalances(ctx context.Context, buildingID int64, asOfDate string) ([]store.FXAccountBalance, error) {
+	var balances []store.FXAccountBalance
+	for id := int64(1); id <= int64(len(s.l.accounts)); id++ {
+		a := s.l.accounts[id]
+		if a.Currency == nil {
+			continue
+		}
+		b := store.FXAccountBalance{AccountID: a.ID, AccountName: a.AccountName, Currency: *a.Currency}
+		for _, split := range s.l.splits {
+			if split.AccountID != a.ID {
+				continue
+			}
+			b.BalanceCents += signedCents(split)
+			if split.ForeignCents == nil {
+				b.UntaggedSplits++
+			} else {
+				b.ForeignCents += *split.ForeignCents
+			}
+		}
+		balances = append(balances, b)
+	}
+	return balances, nil
+}
+
+func (s ledgerFXRevaluationStore) Create(ctx context.Context, tx *sql.Tx, r *store.FXRevaluation) error {
+	r.ID = int64(len(s.l.revaluations) + 1)
+	s.l.revaluations = append(s.l.revaluations, *r)
+	return nil
+}
+
+func (s ledgerFXRevaluationStore) CreateLine(ctx context.Context, tx *sql.Tx, line *store.FXRevaluationLine) error {
+	s.l.revalLines = append(s.l.revalLines, *line)
+	return nil
+}
+
+func (s ledgerFXRevaluationStore) GetByID(ctx context.Context, id int64) (*store.FXRevaluation, error) {
+	return &s.l.revaluations[id-1], nil
+}
+
+func (s ledgerFXRevaluationStore) GetLines(ctx context.Context, revaluationID int64) ([]store.FXRevaluationLine, error) {
+	return s.l.revalLines, nil
+}
+
+const (
+	usdBankAccountID int64 = iota + 1
+	eurBankAccountID
+	expenseAccountID
+	equityAccountID
+	fxAccountID
+)
+
+// newLedger is a USD building with a EUR bank account opened at 100.00 EUR = 110.00 USD
+func newLedger(t *testing.T) (*ledger, *CheckService, *CurrencyService) {
+	t.Helper()
+
+	db, err := sql.Open("service_test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	eur := "EUR"
+	fx := fxAccountID
+	l := &ledger{
+		building: store.Building{ID: 1, BaseCurrency: "USD", FXGainLossAccountID: &fx},
+		accounts: map[int64]*store.Account{
+			usdBankAccountID: {ID: usdBankAccountID, AccountName: "Bank USD", BuildingID: 1},
+			eurBankAccountID: {ID: eurBankAccountID, AccountName: "Bank EUR", BuildingID: 1, Currency: &eur},
+			expenseAccountID: {ID: expenseAccountID, AccountName: "Repairs", BuildingID: 1},
+			equityAccountID:  {ID: equityAccountID, AccountName: "Opening balance equity", BuildingID: 1},
+			fxAccountID:      {ID: fxAccountID, AccountName: "FX gain/loss", BuildingID: 1},
+		},
+		rateScaled: 120_000_000, // 1.20
+	}
+
+	opening := newDebitSplit(0, eurBankAccountID, 11000, nil, nil)
+	foreign := int64(10000)
+	opening.ForeignCents = &foreign
+	l.splits = append(l.splits, opening, newCreditSplit(0, equityAccountID, 11000, nil, nil))
+
+	accounts := ledgerAccountStore{l: l}
+	buildings := ledgerBuildingStore{l: l}
+	transactions := ledgerTransactionStore{l: l}
+	splits := ledgerSplitStore{l: l}
+
+	currencyService := NewCurrencyService(db, ledgerExchangeRateStore{l: l}, ledgerFXRevaluationStore{l: l}, buildings, accounts, transactions, splits)
+	checkService := NewCheckService(db, ledgerCheckStore{}, ledgerExpenseLineStore{}, splits, transactions, accounts, NewApprovalService(ledgerApprovalStore{}), currencyService)
+
+	return l, checkService, currencyService
+}
+
+func checkRequest(paymentAccountID int64, amount float64) dto.CreateCheckRequest {
+	reference, memo := "1001", "Plumber"
+	return dto.CreateCheckRequest{CheckPayloadDTO: dto.CheckPayloadDTO{
+		CheckDate:        "2026-09-15",
+		ReferenceNumber:  &reference,
+		PaymentAccountID: paymentAccountID,
+		BuildingID:       1,
+		Memo:             &memo,
+		TotalAmount:      amount,
+		ExpenseLines:     []dto.ExpenseLineInput{{AccountID: expenseAccountID, Amount: amount}},
+	}}
+}
+
+func TestCheckRejectsForeignCurrencyAccount(t *testing.T) {
+	l, checks, _ := newLedger(t)
+	before := len(l.splits)
+
+	err := checks.Create(context.Background(), checkRequest(eurBankAccountID, 25))
+	if err == nil || !strings.Contains(err.Error(), "kept in EUR") {
+		t.Fatalf("check on the EUR account: error = %v, want it rejected", err)
+	}
+	if len(l.splits) != before {
+		t.Errorf("rejected check posted %d splits", len(l.splits)-before)
+	}
+}
+
+func TestRevalueAfterCheck(t *testing.T) {
+	l, checks, currency := newLedger(t)
+	ctx := context.Background()
+
+	// the EUR account turns the check down, the USD one posts it
+	if err := checks.Create(ctx, checkRequest(eurBankAccountID, 25)); err == nil {
+		t.Error("check on the EUR account was posted")
+	}
+	if err := checks.Create(ctx, checkRequest(usdBankAccountID, 25)); err != nil {
+		t.Fatalf("check on the USD account: %v", err)
+	}
+
+	revaluation, err := currency.Revalue(ctx, dto.CreateFXRevaluationRequest{Date: "2026-09-30", BuildingID: 1})
+	if err != nil {
+		t.Fatalf("Revalue after a check: %v", err)
+	}
+
+	if len(l.revalLines) != 1 {
+		t.Fatalf("revaluation has %d lines, want 1", len(l.revalLines))
+	}
+	line := l.revalLines[0]
+	if line.AccountID != eurBankAccountID || line.ForeignCents != 10000 || line.CarryingCents != 11000 || line.RevaluedCents != 12000 || line.AdjustmentCents != 1000 {
+		t.Errorf("revaluation line = %+v, want 100.00 EUR carried at 110.00 revalued to 120.00", line)
+	}
+	if want := money.FormatMoneyFromCents(1000); revaluation.Adjustment != want {
+		t.Errorf("revaluation adjustment = %s, want %s", revaluation.Adjustment, want)
+	}
+
+	// the revaluation and its reversal each balance
+	for _, transactionID := range []int64{revaluation.TransactionID, revaluation.ReversalTransactionID} {
+		var net int64
+		for _, split := range l.splits {
+			if split.TransactionID == transactionID {
+				net += signedCents(split)
+			}
+		}
+		if net != 0 {
+			t.Errorf("transaction %d is out of balance by %d", transactionID, net)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+/*
+|--------------------------------------------------------------------------
+| Interfaces
+|--------------------------------------------------------------------------
+*/
+
+type DepositStore interface {
+	GetAll(ctx context.Context, buildingID int64, startDate, endDate *string) ([]store.Deposit, error)
+	GetByID(ctx context.Context, id int64) (*store.Deposit, error)
+	GetLines(ctx context.Context, depositID int64) ([]store.DepositLine, error)
+	GetUndeposited(ctx context.Context, buildingID int64, clearingAccountID int64) ([]store.UndepositedItem, error)
+	Create(ctx context.Context, tx *sql.Tx, d *store.Deposit) error
+	CreateLine(ctx context.Context, tx *sql.Tx, l *store.DepositLine) error
+}
+
+/*
+|--------------------------------------------------------------------------
+| Service
+|--------------------------------------------------------------------------
+*/
+
+// DepositService banks payments and receipts held in the building's undeposited funds
+// account, moving their total into a bank account with one ledger line.
+type DepositService struct {
+	db               *sql.DB
+	depositStore     DepositStore
+	transactionStore TransactionStore
+	splitStore       SplitStore
+	accountStore     AccountStore
+	buildingStore    BuildingStore
+	currencyService  *CurrencyService
+}
+
+/*
+|--------------------------------------------------------------------------
+| Constructor
+|--------------------------------------------------------------------------
+*/
+
+func NewDepositService(
+	db *sql.DB,
+	depositStore DepositStore,
+	transactionStore TransactionStore,
+	splitStore SplitStore,
+	accountStore AccountStore,
+	buildingStore BuildingStore,
+	currencyService *CurrencyService,
+) *DepositService {
+	return &DepositService{
+		db:               db,
+		depositStore:     depositStore,
+		transactionStore: transactionStore,
+		splitStore:       splitStore,
+		accountStore:     accountStore,
+		buildingStore:    buildingStore,
+		currencyService:  currencyService,
+	}
+}
+
+/*
+|--------------------------------------------------------------------------
+| Queries
+|--------------------------------------------------------------------------
+*/
+
+func (s *DepositService) GetAll(ctx context.Context, buildingID int64, startDate, endDate *string) ([]dto.DepositDto, error) {
+	deposits, err := s.depositStore.GetAll(ctx, buildingID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	return dto.MapDepositsToDto(deposits), nil
+}
+
+func (s *DepositService) GetByID(ctx context.Context, id int64) (*dto.DepositDto, error) {
+	deposit, err := s.depositStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.depositStore.GetLines(ctx, deposit.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	depositDto := dto.MapDepositToDto(*deposit)
+	depositDto.Lines = dto.MapDepositLinesToDto(lines)
+	return &depositDto, nil
+}
+
+func (s *DepositService) GetUndeposited(ctx context.Context, buildingID int64) (*dto.UndepositedFundsResponse, error) {
+	clearingAccountID, err := s.clearingAccount(ctx, buildingID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.depositStore.GetUndeposited(ctx, buildingID, clearingAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.UndepositedFundsResponse{
+		ClearingAccountID: clearingAccountID,
+		Items:             []dto.UndepositedItemDto{},
+	}
+	total := int64(0)
+	for _, item := range items {
+		response.Items = append(response.Items, dto.MapUndepositedItemToDto(item))
+		total += item.AmountCents
+	}
+	response.Total = money.FormatMoneyFromCents(total)
+
+	return response, nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Commands
+|--------------------------------------------------------------------------
+*/
+
+func (s *DepositService) Create(ctx context.Context, req dto.CreateDepositRequest) (*dto.DepositDto, error) {
+	clearingAccountID, err := s.clearingAccount(ctx, req.BuildingID)
+	if err != nil {
+		return nil, err
+	}
+
+	bankAccount, err := s.accountStore.GetByID(ctx, req.BankAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("bank account not found")
+	}
+	if bankAccount.BuildingID != req.BuildingID {
+		return nil, fmt.Errorf("bank account does not belong to this building")
+	}
+	if bankAccount.ID == clearingAccountID {
+		return nil, fmt.Errorf("bank account cannot be the undeposited funds account")
+	}
+
+	undeposited, err := s.depositStore.GetUndeposited(ctx, req.BuildingID, clearingAccountID)
+	if err != nil {
+		return nil, err
+	}
+	undepositedBySplit := make(map[int64]store.UndepositedItem, len(undeposited))
+	for _, item := range undeposited {
+		undepositedBySplit[item.SplitID] = item
+	}
+
+	items := []store.UndepositedItem{}
+	totalCents := int64(0)
+	seen := make(map[int64]bool)
+	for _, splitID := range req.SplitIDs {
+		item, ok := undepositedBySplit[splitID]
+		if !ok {
+			return nil, fmt.Errorf("split %d is not an undeposited payment or receipt", splitID)
+		}
+		if seen[splitID] {
+			return nil, fmt.Errorf("split %d is selected more than once", splitID)
+		}
+		seen[splitID] = true
+		items = append(items, item)
+		totalCents += item.AmountCents
+	}
+
+	var response dto.DepositDto
+
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		transaction := &store.Transaction{
+			Type:              "deposit",
+			TransactionDate:   req.Date,
+			TransactionNumber: req.Reference,
+			Memo:              req.Memo,
+			Status:            "1",
+			BuildingID:        req.BuildingID,
+			UserID:            1, // TODO: get user id from jwt
+			UnitID:            nil,
+		}
+
+		transactionID, err := s.transactionStore.Create(ctx, tx, transaction)
+		if err != nil {
+			return err
+		}
+
+		// one bank debit for the total; the clearing account is relieved per item
+		splits := []store.Split{newDebitSplit(*transactionID, bankAccount.ID, totalCents, nil, nil)}
+		for _, item := range items {
+			splits = append(splits, newCreditSplit(*transactionID, clearingAccountID, item.AmountCents, item.UnitID, item.PeopleID))
+		}
+
+		if err := validateBalanced(splits); err != nil {
+			return err
+		}
+
+		if err := s.currencyService.requireBaseCurrency(ctx, splits); err != nil {
+			return err
+		}
+
+		for _, split := range splits {
+			if err := s.splitStore.Create(ctx, tx, &split); err != nil {
+				return err
+			}
+		}
+
+		deposit := &store.Deposit{
+			TransactionID:     *transactionID,
+			Reference:         req.Reference,
+			Date:              req.Date,
+			BankAccountID:     bankAccount.ID,
+			ClearingAccountID: clearingAccountID,
+			AmountCents:       totalCents,
+			Memo:              req.Memo,
+			UserID:            1, // TODO: get user id from jwt
+			BuildingID:        req.BuildingID,
+			Status:            "1",
+		}
+		if err := s.depositStore.Create(ctx, tx, deposit); err != nil {
+			return err
+		}
+
+		// deposit_lines.split_id is unique, so an item banked concurrently fails here
+		lines := []store.DepositLine{}
+		for _, item := range items {
+			line := store.DepositLine{
+				DepositID:           deposit.ID,
+				SplitID:             item.SplitID,
+				SourceTransactionID: item.TransactionID,
+				AmountCents:         item.AmountCents,
+				Type:                item.Type,
+				Date:                item.Date,
+				Reference:           item.Reference,
+				PeopleName:          item.PeopleName,
+			}
+			if err := s.depositStore.CreateLine(ctx, tx, &line); err != nil {
+				return err
+			}
+			lines = append(lines, line)
+		}
+
+		response = dto.MapDepositToDto(*deposit)
+		response.Lines = dto.MapDepositLinesToDto(lines)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Helpers
+|--------------------------------------------------------------------------
+*/
+
+func (s *DepositService) clearingAccount(ctx context.Context, buildingID int64) (int64, error) {
+	building, err := s.buildingStore.GetByID(ctx, buildingID)
+	if err != nil {
+		return 0, fmt.Errorf("building not found: %v", err)
+	}
+	if building.UndepositedFundsAccountID == nil {
+		return 0, fmt.Errorf("building has no undeposited funds account")
+	}
+	return *building.UndepositedFundsAccountID, nil
+}
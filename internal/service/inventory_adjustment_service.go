@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+/*
+|--------------------------------------------------------------------------
+| Interfaces
+|--------------------------------------------------------------------------
+*/
+
+type InventoryAdjustmentStore interface {
+	GetAll(ctx context.Context, buildingID int64, startDate, endDate *string) ([]store.InventoryAdjustment, error)
+	GetByID(ctx context.Context, id int64) (*store.InventoryAdjustment, error)
+	Create(ctx context.Context, tx *sql.Tx, ia *store.InventoryAdjustment) error
+	Update(ctx context.Context, tx *sql.Tx, ia *store.InventoryAdjustment) error
+	GetLines(ctx context.Context, adjustmentID int64) ([]store.InventoryAdjustmentLine, error)
+	CreateLine(ctx context.Context, tx *sql.Tx, l *store.InventoryAdjustmentLine) error
+	DeleteLines(ctx context.Context, tx *sql.Tx, adjustmentID int64) error
+}
+
+/*
+|--------------------------------------------------------------------------
+| Service
+|--------------------------------------------------------------------------
+*/
+
+// InventoryAdjustmentService records stocktake corrections. Each line moves an item to its
+// counted qty and/or value and the value difference is posted against the adjustment account.
+type InventoryAdjustmentService struct {
+	db                       *sql.DB
+	inventoryAdjustmentStore InventoryAdjustmentStore
+	transactionStore         TransactionStore
+	splitStore               SplitStore
+	accountStore             AccountStore
+	inventoryService         *InventoryService
+	currencyService          *CurrencyService
+}
+
+/*
+|--------------------------------------------------------------------------
+| Constructor
+|--------------------------------------------------------------------------
+*/
+
+func NewInventoryAdjustmentService(
+	db *sql.DB,
+	inventoryAdjustmentStore InventoryAdjustmentStore,
+	transactionStore TransactionStore,
+	splitStore SplitStore,
+	accountStore AccountStore,
+	inventoryService *InventoryService,
+	currencyService *CurrencyService,
+) *InventoryAdjustmentService {
+	return &InventoryAdjustmentService{
+		db:                       db,
+		inventoryAdjustmentStore: inventoryAdjustmentStore,
+		transactionStore:         transactionStore,
+		splitStore:               splitStore,
+		accountStore:             accountStore,
+		inventoryService:         inventoryService,
+		currencyService:          currencyService,
+	}
+}
+
+/*
+|--------------------------------------------------------------------------
+| Queries
+|--------------------------------------------------------------------------
+*/
+
+func (s *InventoryAdjustmentService) GetAll(ctx context.Context, buildingID int64, startDate, endDate *string) ([]dto.InventoryAdjustmentDto, error) {
+	adjustments, err := s.inventoryAdjustmentStore.GetAll(ctx, buildingID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	return dto.MapInventoryAdjustmentsToDto(adjustments), nil
+}
+
+func (s *InventoryAdjustmentService) GetByID(ctx context.Context, id int64) (*dto.InventoryAdjustmentDetailsResponse, error) {
+	adjustment, err := s.inventoryAdjustmentStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.inventoryAdjustmentStore.GetLines(ctx, adjustment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch lines: %v", err)
+	}
+
+	transaction, err := s.transactionStore.GetByID(ctx, adjustment.TransactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transaction: %v", err)
+	}
+
+	splits, err := s.splitStore.GetByTransactionID(ctx, adjustment.TransactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch splits: %v", err)
+	}
+
+	return &dto.InventoryAdjustmentDetailsResponse{
+		Adjustment:  dto.MapInventoryAdjustmentToDto(*adjustment),
+		Lines:       dto.MapInventoryAdjustmentLinesToDto(lines),
+		Splits:      dto.MapSplitsToDto(splits),
+		Transaction: transaction,
+	}, nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Commands
+|--------------------------------------------------------------------------
+*/
+
+func (s *InventoryAdjustmentService) Create(ctx context.Context, req dto.CreateInventoryAdjustmentRequest) (*dto.InventoryAdjustmentDetailsResponse, error) {
+	lines, counts, err := s.validatePayload(ctx, req.InventoryAdjustmentPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustment := &store.InventoryAdjustment{
+		Reference:      req.Reference,
+		AdjustmentDate: req.Date,
+		AccountID:      req.AccountID,
+		UnitID:         req.UnitID,
+		Memo:           req.Memo,
+		UserID:         1, // TODO: get user id from jwt
+		BuildingID:     req.BuildingID,
+	}
+
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		transaction := &store.Transaction{
+			Type:              "inventory adjustment",
+			TransactionDate:   req.Date,
+			TransactionNumber: req.Reference,
+			Memo:              req.Memo,
+			Status:            "1",
+			BuildingID:        req.BuildingID,
+			UserID:            1, // TODO: get user id from jwt
+			UnitID:            req.UnitID,
+		}
+
+		transactionID, err := s.transactionStore.Create(ctx, tx, transaction)
+		if err != nil {
+			return err
+		}
+
+		adjustment.TransactionID = *transactionID
+		if err := s.inventoryAdjustmentStore.Create(ctx, tx, adjustment); err != nil {
+			return err
+		}
+
+		return s.postTx(ctx, tx, adjustment, lines, counts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, adjustment.ID)
+}
+
+// Update puts back what the adjustment did and applies the edited counts to the stock as it is then
+func (s *InventoryAdjustmentService) Update(ctx context.Context, req dto.UpdateInventoryAdjustmentRequest) (*dto.InventoryAdjustmentDetailsResponse, error) {
+	existing, err := s.inventoryAdjustmentStore.GetByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.BuildingID != req.BuildingID {
+		return nil, fmt.Errorf("inventory adjustment does not belong to this building")
+	}
+
+	lines, counts, err := s.validatePayload(ctx, req.InventoryAdjustmentPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustment := &store.InventoryAdjustment{
+		ID:             existing.ID,
+		TransactionID:  existing.TransactionID,
+		Reference:      req.Reference,
+		AdjustmentDate: req.Date,
+		AccountID:      req.AccountID,
+		UnitID:         req.UnitID,
+		Memo:           req.Memo,
+		UserID:         1, // TODO: get user id from jwt
+		BuildingID:     existing.BuildingID,
+	}
+
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if reason, err := s.splitStore.GetLockReason(ctx, tx, existing.TransactionID); err != nil {
+			return err
+		} else if reason != "" {
+			return fmt.Errorf("inventory adjustment cannot be edited: %s", reason)
+		}
+
+		itemIDs, err := s.inventoryService.reverseTx(ctx, tx, existing.TransactionID)
+		if err != nil {
+			return err
+		}
+
+		transaction := &store.Transaction{
+			ID:                existing.TransactionID,
+			Type:              "inventory adjustment",
+			TransactionDate:   req.Date,
+			TransactionNumber: req.Reference,
+			Memo:              req.Memo,
+			Status:            "1",
+			BuildingID:        existing.BuildingID,
+			UserID:            1, // TODO: get user id from jwt
+			UnitID:            req.UnitID,
+		}
+
+		if _, err := s.transactionStore.Update(ctx, tx, transaction); err != nil {
+			return fmt.Errorf("error updating transaction: %v", err)
+		}
+
+		if err := s.splitStore.DeleteByTransactionID(ctx, tx, existing.TransactionID); err != nil {
+			return fmt.Errorf("error deleting existing splits: %v", err)
+		}
+
+		if err := s.inventoryAdjustmentStore.Update(ctx, tx, adjustment); err != nil {
+			return err
+		}
+
+		if err := s.inventoryAdjustmentStore.DeleteLines(ctx, tx, existing.ID); err != nil {
+			return err
+		}
+
+		if err := s.postTx(ctx, tx, adjustment, lines, counts); err != nil {
+			return err
+		}
+
+		return s.inventoryService.checkStockTx(ctx, tx, itemIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, existing.ID)
+}
+
+/*
+|--------------------------------------------------------------------------
+| Helpers
+|--------------------------------------------------------------------------
+*/
+
+// validatePayload checks the adjustment account and converts the counted lines
+func (s *InventoryAdjustmentService) validatePayload(ctx context.Context, req dto.InventoryAdjustmentPayload) ([]store.InventoryAdjustmentLine, []inventoryCount, error) {
+	account, err := s.accountStore.GetByID(ctx, req.AccountID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("account %d not found", req.AccountID)
+	}
+	if account.BuildingID != req.BuildingID {
+		return nil, nil, fmt.Errorf("account %s does not belong to this building", account.AccountName)
+	}
+
+	lines := []store.InventoryAdjustmentLine{}
+	counts := []inventoryCount{}
+	for i, line := range req.Lines {
+		count := inventoryCount{ItemID: line.ItemID}
+
+		if line.NewQty != nil {
+			qtyScaled, err := money.ParseQty(strconv.FormatFloat(*line.NewQty, 'f', -1, 64))
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %v", i+1, err)
+			}
+			count.NewQtyScaled = &qtyScaled
+		}
+		if line.NewValue != nil {
+			valueCents, err := money.ParseUSDAmount(strconv.FormatFloat(*line.NewValue, 'f', -1, 64))
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %v", i+1, err)
+			}
+			count.NewValueCents = &valueCents
+		}
+
+		counts = append(counts, count)
+		lines = append(lines, store.InventoryAdjustmentLine{
+			ItemID:        line.ItemID,
+			NewQtyScaled:  count.NewQtyScaled,
+			NewValueCents: count.NewValueCents,
+			Memo:          line.Memo,
+		})
+	}
+
+	return lines, counts, nil
+}
+
+// postTx applies the counts to stock, saves the lines with what changed and posts the value
+// difference: a gain debits the asset account and credits the adjustment account, a loss the reverse
+func (s *InventoryAdjustmentService) postTx(ctx context.Context, tx *sql.Tx, adjustment *store.InventoryAdjustment, lines []store.InventoryAdjustmentLine, counts []inventoryCount) error {
+	changes, err := s.inventoryService.adjustTx(ctx, tx, inventoryPosting{
+		TransactionID: adjustment.TransactionID,
+		SourceType:    "adjustment",
+		Date:          adjustment.AdjustmentDate,
+		BuildingID:    adjustment.BuildingID,
+		UnitID:        adjustment.UnitID,
+	}, counts)
+	if err != nil {
+		return err
+	}
+
+	assetCents := make(map[int64]int64)
+	var assetAccounts []int64
+	var totalCents int64
+	for i, change := range changes {
+		if change.AssetAccount == adjustment.AccountID {
+			return fmt.Errorf("line %d: the adjustment account cannot be the item's asset account", i+1)
+		}
+
+		lines[i].AdjustmentID = adjustment.ID
+		lines[i].QtyChangeScaled = change.QtyScaled
+		lines[i].ValueChangeCents = change.ValueCents
+		if err := s.inventoryAdjustmentStore.CreateLine(ctx, tx, &lines[i]); err != nil {
+			return err
+		}
+
+		if !slices.Contains(assetAccounts, change.AssetAccount) {
+			assetAccounts = append(assetAccounts, change.AssetAccount)
+		}
+		assetCents[change.AssetAccount] += change.ValueCents
+		totalCents += change.ValueCents
+	}
+
+	splits := []store.Split{}
+	for _, accountID := range assetAccounts {
+		switch cents := assetCents[accountID]; {
+		case cents > 0:
+			splits = append(splits, newDebitSplit(adjustment.TransactionID, accountID, cents, adjustment.UnitID, nil))
+		case cents < 0:
+			splits = append(splits, newCreditSplit(adjustment.TransactionID, accountID, -cents, adjustment.UnitID, nil))
+		}
+	}
+	switch {
+	case totalCents > 0:
+		splits = append(splits, newCreditSplit(adjustment.TransactionID, adjustment.AccountID, totalCents, adjustment.UnitID, nil))
+	case totalCents < 0:
+		splits = append(splits, newDebitSplit(adjustment.TransactionID, adjustment.AccountID, -totalCents, adjustment.UnitID, nil))
+	}
+
+	if err := validateBalanced(splits); err != nil {
+		return err
+	}
+
+	if err := s.currencyService.requireBaseCurrency(ctx, splits); err != nil {
+		return err
+	}
+
+	for _, split := range splits {
+		if err := s.splitStore.Create(ctx, tx, &split); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,426 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+/*
+|--------------------------------------------------------------------------
+| Interfaces
+|--------------------------------------------------------------------------
+*/
+
+type InventoryStore interface {
+	GetStock(ctx context.Context, itemID int64) (*store.ItemStock, error)
+	GetStockTx(ctx context.Context, tx *sql.Tx, itemID int64) (*store.ItemStock, error)
+	SetStockTx(ctx context.Context, tx *sql.Tx, stock *store.ItemStock) error
+	GetMovements(ctx context.Context, itemID int64, startDate, endDate *string) ([]store.InventoryMovement, error)
+	GetMovementsByTransactionTx(ctx context.Context, tx *sql.Tx, transactionID int64) ([]store.InventoryMovement, error)
+	CreateMovement(ctx context.Context, tx *sql.Tx, m *store.InventoryMovement) error
+	DeleteMovementsByTransaction(ctx context.Context, tx *sql.Tx, transactionID int64) error
+}
+
+/*
+|--------------------------------------------------------------------------
+| Service
+|--------------------------------------------------------------------------
+*/
+
+// InventoryService keeps perpetual stock of inventory items at moving average cost. Bills receive
+// stock; invoices and sales receipts issue it and post the cost from the asset account to COGS;
+// inventory adjustments set it to what a count found.
+type InventoryService struct {
+	inventoryStore InventoryStore
+}
+
+// inventoryPosting is the document moving stock
+type inventoryPosting struct {
+	TransactionID int64
+	SourceType    string // bill | invoice | receipt | adjustment
+	Date          string
+	BuildingID    int64
+	UnitID        *int64
+	PeopleID      *int64
+}
+
+// inventoryLine is one item line of the document. CostCents is only set on receipts;
+// issues are costed at the item's average cost.
+type inventoryLine struct {
+	ItemID    int64
+	QtyScaled int64
+	CostCents int64
+}
+
+// inventoryCount is a stocktake line: the qty and/or value the item should be left with
+type inventoryCount struct {
+	ItemID        int64
+	NewQtyScaled  *int64
+	NewValueCents *int64
+}
+
+// inventoryChange is what an adjustment did to one item's stock
+type inventoryChange struct {
+	ItemID       int64
+	AssetAccount int64
+	QtyScaled    int64
+	ValueCents   int64
+}
+
+/*
+|--------------------------------------------------------------------------
+| Constructor
+|--------------------------------------------------------------------------
+*/
+
+func NewInventoryService(inventoryStore InventoryStore) *InventoryService {
+	return &InventoryService{inventoryStore: inventoryStore}
+}
+
+/*
+|--------------------------------------------------------------------------
+| Queries
+|--------------------------------------------------------------------------
+*/
+
+func (s *InventoryService) GetMovements(ctx context.Context, itemID int64, startDate, endDate *string) ([]dto.InventoryMovementDto, error) {
+	if _, err := s.inventoryStore.GetStock(ctx, itemID); err != nil {
+		return nil, err
+	}
+
+	movements, err := s.inventoryStore.GetMovements(ctx, itemID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	return dto.MapInventoryMovementsToDto(movements), nil
+}
+
+// previewIssue costs the lines as issueTx would without touching stock
+func (s *InventoryService) previewIssue(ctx context.Context, p inventoryPosting, lines []inventoryLine) ([]store.Split, error) {
+	stocks := make(map[int64]*store.ItemStock)
+	getStock := func(itemID int64) (*store.ItemStock, error) {
+		if stocks[itemID] == nil {
+			stock, err := s.inventoryStore.GetStock(ctx, itemID)
+			if err != nil {
+				return nil, err
+			}
+			stocks[itemID] = stock
+		}
+		return stocks[itemID], nil
+	}
+	keep := func(stock *store.ItemStock, qtyScaled, costCents int64) error {
+		return nil
+	}
+
+	return s.issue(p, lines, getStock, keep)
+}
+
+/*
+|--------------------------------------------------------------------------
+| Commands
+|--------------------------------------------------------------------------
+*/
+
+// receiveTx adds the inventory lines of a bill to stock and folds their cost into the average
+func (s *InventoryService) receiveTx(ctx context.Context, tx *sql.Tx, p inventoryPosting, lines []inventoryLine) error {
+	for _, line := range lines {
+		stock, err := s.inventoryStore.GetStockTx(ctx, tx, line.ItemID)
+		if err != nil {
+			return fmt.Errorf("item %d not found: %v", line.ItemID, err)
+		}
+		if stock.Type != "inventory" {
+			continue
+		}
+		if line.QtyScaled <= 0 {
+			return fmt.Errorf("inventory item %s needs a qty greater than zero", stock.Name)
+		}
+
+		stock.OnHandScaled += line.QtyScaled
+		stock.ValueCents += line.CostCents
+		if err := s.moveTx(ctx, tx, p, stock, line.QtyScaled, line.CostCents); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// issueTx takes the inventory lines of an invoice or sales receipt out of stock at average cost
+// and returns the COGS debit and asset credit splits for them
+func (s *InventoryService) issueTx(ctx context.Context, tx *sql.Tx, p inventoryPosting, lines []inventoryLine) ([]store.Split, error) {
+	getStock := func(itemID int64) (*store.ItemStock, error) {
+		return s.inventoryStore.GetStockTx(ctx, tx, itemID)
+	}
+	move := func(stock *store.ItemStock, qtyScaled, costCents int64) error {
+		return s.moveTx(ctx, tx, p, stock, -qtyScaled, -costCents)
+	}
+
+	return s.issue(p, lines, getStock, move)
+}
+
+// adjustTx sets each item to its counted qty and value. A qty without a value moves stock at
+// average cost; a value without a qty revalues what is on hand.
+func (s *InventoryService) adjustTx(ctx context.Context, tx *sql.Tx, p inventoryPosting, counts []inventoryCount) ([]inventoryChange, error) {
+	changes := []inventoryChange{}
+	for _, count := range counts {
+		stock, err := s.inventoryStore.GetStockTx(ctx, tx, count.ItemID)
+		if err != nil {
+			return nil, fmt.Errorf("item %d not found: %v", count.ItemID, err)
+		}
+		if stock.Type != "inventory" {
+			return nil, fmt.Errorf("%s is not an inventory item", stock.Name)
+		}
+		if stock.BuildingID != p.BuildingID {
+			return nil, fmt.Errorf("item %s does not belong to this building", stock.Name)
+		}
+		if stock.AssetAccount == nil {
+			return nil, fmt.Errorf("inventory item %s has no asset account", stock.Name)
+		}
+		if count.NewQtyScaled == nil && count.NewValueCents == nil {
+			return nil, fmt.Errorf("%s needs a new qty or a new value", stock.Name)
+		}
+
+		newQty := stock.OnHandScaled
+		if count.NewQtyScaled != nil {
+			newQty = *count.NewQtyScaled
+		}
+		qtyChange := newQty - stock.OnHandScaled
+
+		var newValue int64
+		switch {
+		case count.NewValueCents != nil:
+			newValue = *count.NewValueCents
+		case newQty == 0:
+			newValue = 0
+		case qtyChange < 0:
+			costCents, err := issueCost(stock, -qtyChange)
+			if err != nil {
+				return nil, err
+			}
+			newValue = stock.ValueCents - costCents
+		case qtyChange > 0 && stock.OnHandScaled <= 0:
+			return nil, fmt.Errorf("%s has no stock to take an average cost from; give the new value", stock.Name)
+		default:
+			addedCents, err := money.MulDiv(stock.ValueCents, qtyChange, stock.OnHandScaled)
+			if err != nil {
+				return nil, fmt.Errorf("%s value out of allowed range: %v", stock.Name, err)
+			}
+			newValue = stock.ValueCents + addedCents
+		}
+		if newQty == 0 && newValue != 0 {
+			return nil, fmt.Errorf("%s cannot keep a value with nothing on hand", stock.Name)
+		}
+		valueChange := newValue - stock.ValueCents
+
+		if qtyChange != 0 || valueChange != 0 {
+			stock.OnHandScaled = newQty
+			stock.ValueCents = newValue
+			if err := s.moveTx(ctx, tx, p, stock, qtyChange, valueChange); err != nil {
+				return nil, err
+			}
+		}
+
+		changes = append(changes, inventoryChange{
+			ItemID:       stock.ItemID,
+			AssetAccount: *stock.AssetAccount,
+			QtyScaled:    qtyChange,
+			ValueCents:   valueChange,
+		})
+	}
+
+	return changes, nil
+}
+
+// reverseTx undoes the stock movements of a transaction, newest first, before it is edited.
+// It returns the items touched; callers check them with checkStockTx once the edit is posted again.
+func (s *InventoryService) reverseTx(ctx context.Context, tx *sql.Tx, transactionID int64) ([]int64, error) {
+	movements, err := s.inventoryStore.GetMovementsByTransactionTx(ctx, tx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var itemIDs []int64
+	for _, m := range movements {
+		stock, err := s.inventoryStore.GetStockTx(ctx, tx, m.ItemID)
+		if err != nil {
+			return nil, err
+		}
+
+		stock.OnHandScaled -= m.QtyScaled
+		stock.ValueCents -= m.CostCents
+		if err := s.inventoryStore.SetStockTx(ctx, tx, stock); err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(itemIDs, m.ItemID) {
+			itemIDs = append(itemIDs, m.ItemID)
+		}
+	}
+
+	if err := s.inventoryStore.DeleteMovementsByTransaction(ctx, tx, transactionID); err != nil {
+		return nil, err
+	}
+
+	return itemIDs, nil
+}
+
+// checkStockTx fails when an edit took more of an item out of stock than is left,
+// e.g. cutting the qty of a bill whose items have since been sold
+func (s *InventoryService) checkStockTx(ctx context.Context, tx *sql.Tx, itemIDs []int64) error {
+	for _, itemID := range itemIDs {
+		stock, err := s.inventoryStore.GetStockTx(ctx, tx, itemID)
+		if err != nil {
+			return err
+		}
+		if stock.OnHandScaled < 0 {
+			return fmt.Errorf("%s would go below zero in stock (%s)", stock.Name, money.FormatScaled5(stock.OnHandScaled))
+		}
+	}
+
+	return nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Helpers
+|--------------------------------------------------------------------------
+*/
+
+// issue runs the issuing rules against locked rows for issueTx or against a read-only copy for previewIssue
+func (s *InventoryService) issue(
+	p inventoryPosting,
+	lines []inventoryLine,
+	getStock func(itemID int64) (*store.ItemStock, error),
+	move func(stock *store.ItemStock, qtyScaled, costCents int64) error,
+) ([]store.Split, error) {
+	cogsCents := make(map[int64]int64)
+	assetCents := make(map[int64]int64)
+	var cogsAccounts, assetAccounts []int64
+
+	for _, line := range lines {
+		stock, err := getStock(line.ItemID)
+		if err != nil {
+			return nil, fmt.Errorf("item %d not found: %v", line.ItemID, err)
+		}
+		if stock.Type != "inventory" {
+			continue
+		}
+		if stock.AssetAccount == nil || stock.COGSAccount == nil {
+			return nil, fmt.Errorf("inventory item %s needs an asset and a COGS account", stock.Name)
+		}
+		if line.QtyScaled <= 0 {
+			return nil, fmt.Errorf("inventory item %s needs a qty greater than zero", stock.Name)
+		}
+
+		costCents, err := issueCost(stock, line.QtyScaled)
+		if err != nil {
+			return nil, err
+		}
+
+		stock.OnHandScaled -= line.QtyScaled
+		stock.ValueCents -= costCents
+		if err := move(stock, line.QtyScaled, costCents); err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(cogsAccounts, *stock.COGSAccount) {
+			cogsAccounts = append(cogsAccounts, *stock.COGSAccount)
+		}
+		if !slices.Contains(assetAccounts, *stock.AssetAccount) {
+			assetAccounts = append(assetAccounts, *stock.AssetAccount)
+		}
+		cogsCents[*stock.COGSAccount] += costCents
+		assetCents[*stock.AssetAccount] += costCents
+	}
+
+	// stock that cost nothing posts nothing
+	splits := []store.Split{}
+	for _, accountID := range cogsAccounts {
+		if cogsCents[accountID] > 0 {
+			splits = append(splits, newDebitSplit(p.TransactionID, accountID, cogsCents[accountID], p.UnitID, p.PeopleID))
+		}
+	}
+	for _, accountID := range assetAccounts {
+		if assetCents[accountID] > 0 {
+			splits = append(splits, newCreditSplit(p.TransactionID, accountID, assetCents[accountID], p.UnitID, p.PeopleID))
+		}
+	}
+
+	return splits, nil
+}
+
+// moveTx saves the item's new stock and records the movement that got it there
+func (s *InventoryService) moveTx(ctx context.Context, tx *sql.Tx, p inventoryPosting, stock *store.ItemStock, qtyScaled, costCents int64) error {
+	if err := s.inventoryStore.SetStockTx(ctx, tx, stock); err != nil {
+		return err
+	}
+
+	return s.inventoryStore.CreateMovement(ctx, tx, &store.InventoryMovement{
+		ItemID:            stock.ItemID,
+		TransactionID:     p.TransactionID,
+		SourceType:        p.SourceType,
+		MovementDate:      p.Date,
+		QtyScaled:         qtyScaled,
+		CostCents:         costCents,
+		OnHandAfterScaled: stock.OnHandScaled,
+		ValueAfterCents:   stock.ValueCents,
+		BuildingID:        p.BuildingID,
+	})
+}
+
+// issueCost is what qtyScaled units cost at the item's average cost; the last unit takes
+// whatever value is left so rounding never strands cents in the asset account
+func issueCost(stock *store.ItemStock, qtyScaled int64) (int64, error) {
+	if qtyScaled > stock.OnHandScaled {
+		return 0, fmt.Errorf("not enough %s in stock: %s on hand, %s needed",
+			stock.Name, money.FormatScaled5(max(stock.OnHandScaled, 0)), money.FormatScaled5(qtyScaled))
+	}
+	if qtyScaled == stock.OnHandScaled {
+		return stock.ValueCents, nil
+	}
+
+	costCents, err := money.MulDiv(stock.ValueCents, qtyScaled, stock.OnHandScaled)
+	if err != nil {
+		return 0, fmt.Errorf("%s cost out of allowed range: %v", stock.Name, err)
+	}
+	return costCents, nil
+}
+
+// billLineAccount is the account a bill's item line debits. Inventory can only be billed
+// to the item's asset account; other items default to their expense account.
+func (s *InventoryService) billLineAccount(ctx context.Context, line dto.BillExpenseLineInput, buildingID int64) (int64, error) {
+	stock, err := s.inventoryStore.GetStock(ctx, *line.ItemID)
+	if err != nil {
+		return 0, fmt.Errorf("item not found: %v", err)
+	}
+	if stock.BuildingID != buildingID {
+		return 0, fmt.Errorf("item %s does not belong to this building", stock.Name)
+	}
+
+	if stock.Type != "inventory" {
+		if line.AccountID != 0 {
+			return line.AccountID, nil
+		}
+		if stock.ExpenseAccount == nil {
+			return 0, fmt.Errorf("item %s has no expense account", stock.Name)
+		}
+		return *stock.ExpenseAccount, nil
+	}
+
+	if stock.AssetAccount == nil {
+		return 0, fmt.Errorf("inventory item %s has no asset account", stock.Name)
+	}
+	if line.AccountID != 0 && line.AccountID != *stock.AssetAccount {
+		return 0, fmt.Errorf("inventory item %s must be billed to its asset account", stock.Name)
+	}
+	if line.Qty == nil || *line.Qty <= 0 {
+		return 0, fmt.Errorf("inventory item %s needs a qty greater than zero", stock.Name)
+	}
+	return *stock.AssetAccount, nil
+}
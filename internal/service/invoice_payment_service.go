@@ -22,6 +22,7 @@ type InvoicePaymentStore interface {
 	GetAllByInvoiceID(ctx context.Context, invoiceID int64) ([]store.InvoicePayment, error)
 	GetByID(ctx context.Context, id int64) (*store.InvoicePayment, error)
 	GetByIDTx(ctx context.Context, tx *sql.Tx, id int64) (*store.InvoicePayment, error)
+	IsReceivedPaymentTx(ctx context.Context, tx *sql.Tx, transactionID int64) (bool, error)
 	Create(ctx context.Context, tx *sql.Tx, invoicePayment *store.InvoicePayment) (*store.InvoicePayment, error)
 	Update(ctx context.Context, tx *sql.Tx, invoicePayment *store.InvoicePayment) (*store.InvoicePayment, error)
 	Delete(ctx context.Context, id int64) error
@@ -41,6 +42,9 @@ type InvoicePaymentService struct {
 	accountStore         AccountStore
 	invoiceStore         InvoiceStore
 	splitStore           SplitStore
+	creditMemoStore      CreditMemoStore
+	buildingStore        BuildingStore
+	currencyService      *CurrencyService
 }
 
 /*
@@ -56,6 +60,9 @@ func NewInvoicePaymentService(
 	accountStore AccountStore,
 	invoiceStore InvoiceStore,
 	splitStore SplitStore,
+	creditMemoStore CreditMemoStore,
+	buildingStore BuildingStore,
+	currencyService *CurrencyService,
 ) *InvoicePaymentService {
 	return &InvoicePaymentService{
 		db:                  db,
@@ -64,6 +71,9 @@ func NewInvoicePaymentService(
 		accountStore:        accountStore,
 		invoiceStore:        invoiceStore,
 		splitStore:          splitStore,
+		creditMemoStore:     creditMemoStore,
+		buildingStore:       buildingStore,
+		currencyService:     currencyService,
 	}
 }
 
@@ -158,108 +168,19 @@ func (s *InvoicePaymentService) GetByID(
 func (s *InvoicePaymentService) Create(ctx context.Context, paymentDTO dto.CreateInvoicePaymentRequest) (*dto.InvoicePaymentResponse, error) {
 	var response dto.InvoicePaymentResponse
 
-	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
-
-		invoice, err := s.invoiceStore.GetByID(ctx, int64(paymentDTO.InvoiceID))
-		if err != nil {
-			return fmt.Errorf("invoice not found: %v", err)
-		}
-
-		// Validate invoice belongs to the building
-		if invoice.BuildingID != paymentDTO.BuildingID {
-			return fmt.Errorf("invoice does not belong to the specified building")
-		}
-
-		arAccount, err := s.accountStore.GetByID(ctx, int64(invoice.ARAccountID))
-		if err != nil {
-			return fmt.Errorf("A/R account not found: %v", err)
-		}
-
-		// Get Asset Account from request
-		assetAccount, err := s.accountStore.GetByID(ctx, int64(paymentDTO.AccountID))
-		if err != nil {
-			return fmt.Errorf("asset account not found: %v", err)
-		}
-
-		// Validate amount
-		if paymentDTO.Amount == 0 {
-			return fmt.Errorf("amount cannot be zero")
-		}
-
-		// create transaction
-		transaction := &store.Transaction{
-			Type:              "payment",
-			TransactionDate:   paymentDTO.Date,
-			TransactionNumber: paymentDTO.Reference,
-			Memo:              paymentDTO.Reference,
-			Status:            "1",
-			BuildingID:        paymentDTO.BuildingID,
-			UnitID:            invoice.UnitID,
-			UserID:            1, // TODO: get user id from jwt
-		}
-		transactionId, err := s.transactionStore.Create(ctx, tx, transaction)
-		if err != nil {
-			return err
-		}
-
-		amountStr := strconv.FormatFloat(paymentDTO.Amount, 'f', -1, 64)
-		amountCents, err := money.ParseUSDAmount(amountStr)
-		if err != nil {
-			return fmt.Errorf("invalid amount: %v", err)
-		}
-
-		// create splits
-		debitSplit := store.Split{
-			TransactionID: *transactionId,
-			AccountID:     assetAccount.ID,
-			Debit:         &paymentDTO.Amount,
-			Credit:        nil,
-			DebitCents:    &amountCents,
-			CreditCents:   nil,
-			UnitID:        invoice.UnitID,
-			PeopleID:      invoice.PeopleID,
-			Status:        "1",
-		}
-		err = s.splitStore.Create(ctx, tx, &debitSplit)
-		if err != nil {
-			return err
-		}
-
-		creditSplit := store.Split{
-			TransactionID: *transactionId,
-			AccountID:     arAccount.ID,
-			Credit:        &paymentDTO.Amount,
-			Debit:         nil,
-			DebitCents:    nil,
-			CreditCents:   &amountCents,
-			UnitID:        invoice.UnitID,
-			PeopleID:      invoice.PeopleID,
-			Status:        "1",
-		}
-		err = s.splitStore.Create(ctx, tx, &creditSplit)
-		if err != nil {
-			return err
-		}
-
-		// create invoice payment
-		invoicePayment := &store.InvoicePayment{
-			TransactionID: *transactionId,
-			Reference:     paymentDTO.Reference,
-			Date:          paymentDTO.Date,
-			InvoiceID:     int64(paymentDTO.InvoiceID),
-			UserID:        1, // TODO: get user id from jwt
-			AccountID:     int64(paymentDTO.AccountID),
-			Amount:        paymentDTO.Amount,
-			AmountCents:   amountCents,
-			Status:        "1",
-		}
-
-		_,err = s.invoicePaymentStore.Create(ctx, tx, invoicePayment)
-		if err != nil {
-			return err
-		}
+	poster := invoicePaymentPoster{
+		invoiceStore:        s.invoiceStore,
+		invoicePaymentStore: s.invoicePaymentStore,
+		creditMemoStore:     s.creditMemoStore,
+		transactionStore:    s.transactionStore,
+		splitStore:          s.splitStore,
+		accountStore:        s.accountStore,
+		buildingStore:       s.buildingStore,
+		currencyService:     s.currencyService,
+	}
 
-		return nil
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return poster.post(ctx, tx, paymentDTO)
 	})
 
 	if err != nil {
@@ -269,10 +190,6 @@ func (s *InvoicePaymentService) Create(ctx context.Context, paymentDTO dto.Creat
 	return &response, nil
 }
 
-
-
-
-
 func (s *InvoicePaymentService) Update(
 	ctx context.Context,
 	req dto.UpdateInvoicePaymentRequest,
@@ -287,6 +204,24 @@ func (s *InvoicePaymentService) Update(
 			return fmt.Errorf("invoice payment not found: %v", err)
 		}
 
+		if reason, err := s.splitStore.GetLockReason(ctx, tx, existing.TransactionID); err != nil {
+			return err
+		} else if reason != "" {
+			return fmt.Errorf("invoice payment cannot be edited: %s", reason)
+		}
+		if existing.Currency != nil {
+			return fmt.Errorf("invoice payment cannot be edited: it was made in a foreign currency")
+		}
+
+		// a received payment shares its transaction with its other allocations, rebuilding it from this row would drop them
+		received, err := s.invoicePaymentStore.IsReceivedPaymentTx(ctx, tx, existing.TransactionID)
+		if err != nil {
+			return err
+		}
+		if received {
+			return fmt.Errorf("invoice payment is part of a received payment and cannot be edited")
+		}
+
 		invoice, err := s.invoiceStore.GetByID(ctx, existing.InvoiceID)
 		if err != nil {
 			return fmt.Errorf("invoice not found: %v", err)
@@ -303,6 +238,18 @@ func (s *InvoicePaymentService) Update(
 			return fmt.Errorf("invalid amount: %v", err)
 		}
 
+		// the payment being edited no longer counts against the balance; a voided one never did
+		balanceCents, err := s.invoiceStore.GetOpenBalanceTx(ctx, tx, existing.InvoiceID)
+		if err != nil {
+			return err
+		}
+		if existing.Status == "1" {
+			balanceCents += existing.AmountCents
+		}
+		if _, _, err := splitOverpayment(balanceCents, amountCents, false, nil, ""); err != nil {
+			return err
+		}
+
 		// update invoice payment
 		updatedPayment := &store.InvoicePayment{
 			ID:            paymentID,
@@ -17,6 +17,7 @@ type InvoiceStore interface {
 	Create(ctx context.Context, tx *sql.Tx, invoice *store.Invoice) (*int64, error)
 	Update(ctx context.Context, tx *sql.Tx, invoice *store.Invoice) (*int64, error)
 	Delete(ctx context.Context, id int64) error
+	GetOpenBalanceTx(ctx context.Context, tx *sql.Tx, id int64) (int64, error)
 }
 
 type InvoiceItemStore interface {
@@ -60,6 +61,7 @@ type SplitStore interface {
 	Update(ctx context.Context, split *store.Split) error
 	Delete(ctx context.Context, tx *sql.Tx, id int64) error
 	DeleteByTransactionID(ctx context.Context, tx *sql.Tx, transactionID int64) error
+	GetLockReason(ctx context.Context, tx *sql.Tx, transactionID int64) (string, error)
 }
 
 type InvoiceService struct {
@@ -74,6 +76,10 @@ type InvoiceService struct {
 	splitStore                  SplitStore
 	transactionStore            TransactionStore
 	itemStore                   ItemStore
+	buildingStore               BuildingStore
+	inventoryService            *InventoryService
+	taxService                  *TaxService
+	currencyService             *CurrencyService
 }
 
 func NewInvoiceService(
@@ -88,6 +94,10 @@ func NewInvoiceService(
 	splitStore SplitStore,
 	transactionStore TransactionStore,
 	itemStore ItemStore,
+	buildingStore BuildingStore,
+	inventoryService *InventoryService,
+	taxService *TaxService,
+	currencyService *CurrencyService,
 ) *InvoiceService {
 	return &InvoiceService{
 		db:                          db,
@@ -101,6 +111,10 @@ func NewInvoiceService(
 		splitStore:                  splitStore,
 		transactionStore:            transactionStore,
 		itemStore:                   itemStore,
+		buildingStore:               buildingStore,
+		inventoryService:            inventoryService,
+		taxService:                  taxService,
+		currencyService:             currencyService,
 	}
 }
 
@@ -233,6 +247,7 @@ func (s *InvoiceService) GetByID(ctx context.Context, id int64) (map[string]any,
 			People:        split.People,
 		})
 	}
+	exchangeRate, foreignAmount := dto.FormatForeignAmounts(invoice.ExchangeRateScaled, invoice.ForeignAmountCents)
 	return map[string]any{
 		"invoice": dto.InvoiceDto{
 			ID:            invoice.ID,
@@ -247,6 +262,9 @@ func (s *InvoiceService) GetByID(ctx context.Context, id int64) (map[string]any,
 			Amount:        money.FormatMoneyFromCents(invoice.AmountCents),
 			Description:   invoice.Description,
 			CancelReason:  invoice.CancelReason,
+			Currency:      invoice.Currency,
+			ExchangeRate:  exchangeRate,
+			ForeignAmount: foreignAmount,
 			Status:        invoice.Status,
 			BuildingID:    invoice.BuildingID,
 			CreatedAt:     invoice.CreatedAt,
@@ -273,6 +291,16 @@ func (s *InvoiceService) PreviewInvoiceSplits(ctx context.Context, invoiceDTO dt
 		return nil, err
 	}
 
+	inventoryLines, err := invoiceInventoryLines(invoiceDTO)
+	if err != nil {
+		return nil, err
+	}
+	costSplits, err := s.inventoryService.previewIssue(ctx, invoiceInventoryPosting(0, invoiceDTO), inventoryLines)
+	if err != nil {
+		return nil, err
+	}
+	splits = append(splits, costSplits...)
+
 	if err := validateBalanced(splits); err != nil {
 		return nil, err
 	}
@@ -282,130 +310,165 @@ func (s *InvoiceService) PreviewInvoiceSplits(ctx context.Context, invoiceDTO dt
 func (s *InvoiceService) Create(ctx context.Context, invoiceDTO dto.CreateInvoiceRequestDTO) error {
 
 	return withTx(s.db, ctx, func(tx *sql.Tx) error {
-		// create transaction
-		transaction := &store.Transaction{
-			Type:              "invoice",
-			TransactionDate:   invoiceDTO.SalesDate,
-			TransactionNumber: invoiceDTO.InvoiceNo,
-			Memo:              invoiceDTO.Description,
-			Status:            "1",
-			BuildingID:        invoiceDTO.BuildingID,
-			UserID:            1, // TODO: get user id from jwt
-			UnitID:            &invoiceDTO.UnitID,
-		}
-		transactionId, err := s.transactionStore.Create(ctx, tx, transaction)
+		_, err := s.CreateTx(ctx, tx, invoiceDTO)
+		return err
+	})
+}
+
+// CreateTx posts an invoice inside an existing transaction and returns the new invoice id
+func (s *InvoiceService) CreateTx(ctx context.Context, tx *sql.Tx, invoiceDTO dto.CreateInvoiceRequestDTO) (*int64, error) {
+	// create transaction
+	transaction := &store.Transaction{
+		Type:              "invoice",
+		TransactionDate:   invoiceDTO.SalesDate,
+		TransactionNumber: invoiceDTO.InvoiceNo,
+		Memo:              invoiceDTO.Description,
+		Status:            "1",
+		BuildingID:        invoiceDTO.BuildingID,
+		UserID:            1, // TODO: get user id from jwt
+		UnitID:            &invoiceDTO.UnitID,
+	}
+	transactionId, err := s.transactionStore.Create(ctx, tx, transaction)
+	if err != nil {
+		fmt.Println("*********************** error creating transaction", err)
+		return nil, err
+	}
+
+	// create splits
+
+	splits, err := s.GenerateInvoiceSplits(ctx, invoiceDTO.InvoicePayloadDTO)
+	if err != nil {
+		fmt.Println("*********************** error generating splits", err)
+		return nil, err
+	}
+
+	// inventory lines leave stock at average cost
+	inventoryLines, err := invoiceInventoryLines(invoiceDTO.InvoicePayloadDTO)
+	if err != nil {
+		return nil, err
+	}
+	costSplits, err := s.inventoryService.issueTx(ctx, tx, invoiceInventoryPosting(*transactionId, invoiceDTO.InvoicePayloadDTO), inventoryLines)
+	if err != nil {
+		return nil, err
+	}
+	splits = append(splits, costSplits...)
+
+	if err := validateBalanced(splits); err != nil {
+		fmt.Println("*********************** error validating splits", err)
+		return nil, err
+	}
+
+	for _, split := range splits {
+		split.TransactionID = *transactionId
+		err := s.splitStore.Create(ctx, tx, &split)
 		if err != nil {
-			fmt.Println("*********************** error creating transaction", err)
-			return err
+			fmt.Println("*********************** error creating splits", err)
+			return nil, err
 		}
+	}
 
-		// create splits
+	amountCents, err := money.ParseUSDAmount(strconv.FormatFloat(invoiceDTO.Amount, 'f', -1, 64))
+	if err != nil {
+		fmt.Println("*********************** error parsing amount", err)
+		return nil, err
+	}
+	rate, err := s.currencyService.documentRate(ctx, invoiceDTO.BuildingID, invoiceDTO.Currency, invoiceDTO.SalesDate, invoiceDTO.ExchangeRate)
+	if err != nil {
+		return nil, err
+	}
+	exchangeRateScaled, foreignAmountCents := rate.foreignAmounts(amountCents)
+	if amountCents, err = rate.toBase(amountCents); err != nil {
+		return nil, err
+	}
 
-		splits, err := s.GenerateInvoiceSplits(ctx, invoiceDTO.InvoicePayloadDTO)
+	// create invoice
+	invoice := &store.Invoice{
+		TransactionID:      *transactionId,
+		InvoiceNo:          invoiceDTO.InvoiceNo,
+		SalesDate:          invoiceDTO.SalesDate,
+		DueDate:            invoiceDTO.DueDate,
+		UnitID:             &invoiceDTO.UnitID,
+		PeopleID:           &invoiceDTO.PeopleID,
+		ARAccountID:        invoiceDTO.ARAccountID,
+		Amount:             float64(amountCents) / float64(money.MoneyScale),
+		AmountCents:        amountCents, // TODO : make the amount string on request
+		Currency:           rate.Currency,
+		ExchangeRateScaled: exchangeRateScaled,
+		ForeignAmountCents: foreignAmountCents,
+		Description:        invoiceDTO.Description,
+		Status:             invoiceDTO.Status,
+		BuildingID:         invoiceDTO.BuildingID,
+		UserID:             1, // TODO: get user id from jwt
+	}
+
+	invoiceId, err := s.invoiceStore.Create(ctx, tx, invoice)
+	if err != nil {
+		fmt.Println("*********************** error converting line input", err)
+		return nil, err
+	}
+
+	// create invoice items
+	for _, item := range invoiceDTO.Items {
+		itemrow, err := s.itemStore.GetByID(ctx, int64(item.ItemID))
 		if err != nil {
-			fmt.Println("*********************** error generating splits", err)
-			return err
+			return nil, err
 		}
 
-		if err := validateBalanced(splits); err != nil {
-			fmt.Println("*********************** error validating splits", err)
-			return err
+		var previousValue *string = nil
+		var currentValue *string = nil
+		if item.PreviousValue != nil {
+			previousValueStr := strconv.FormatFloat(*item.PreviousValue, 'f', -1, 64)
+			previousValue = &previousValueStr
 		}
-
-		for _, split := range splits {
-			split.TransactionID = *transactionId
-			err := s.splitStore.Create(ctx, tx, &split)
-			if err != nil {
-				fmt.Println("*********************** error creating splits", err)
-				return err
-			}
+		if item.CurrentValue != nil {
+			currentValueStr := strconv.FormatFloat(*item.CurrentValue, 'f', -1, 64)
+			currentValue = &currentValueStr
 		}
 
-		amountCents, err := money.ParseUSDAmount(strconv.FormatFloat(invoiceDTO.Amount, 'f', -1, 64))
-		if err != nil {
-			fmt.Println("*********************** error parsing amount", err)
-			return err
-		}
+		lineResult, err := money.ConvertLineInput(money.LineInput{
+			Qty:           strconv.FormatFloat(item.Qty, 'f', -1, 64),
+			Rate:          strconv.FormatFloat(item.Rate, 'f', -1, 64),
+			PreviousValue: previousValue,
+			CurrentValue:  currentValue,
+		})
 
-		// create invoice
-		invoice := &store.Invoice{
-			TransactionID: *transactionId,
-			InvoiceNo:     invoiceDTO.InvoiceNo,
-			SalesDate:     invoiceDTO.SalesDate,
-			DueDate:       invoiceDTO.DueDate,
-			UnitID:        &invoiceDTO.UnitID,
-			PeopleID:      &invoiceDTO.PeopleID,
-			ARAccountID:   invoiceDTO.ARAccountID,
-			Amount:        invoiceDTO.Amount,
-			AmountCents:   amountCents, // TODO : make the amount string on request
-			Description:   invoiceDTO.Description,
-			Status:        invoiceDTO.Status,
-			BuildingID:    invoiceDTO.BuildingID,
-			UserID:        1, // TODO: get user id from jwt
-		}
-
-		invoiceId, err := s.invoiceStore.Create(ctx, tx, invoice)
 		if err != nil {
 			fmt.Println("*********************** error converting line input", err)
-			return err
+			return nil, err
 		}
 
-		// create invoice items
-		for _, item := range invoiceDTO.Items {
-			itemrow, err := s.itemStore.GetByID(ctx, int64(item.ItemID))
-			if err != nil {
-				return err
-			}
-
-			var previousValue *string = nil
-			var currentValue *string = nil
-			if item.PreviousValue != nil {
-				previousValueStr := strconv.FormatFloat(*item.PreviousValue, 'f', -1, 64)
-				previousValue = &previousValueStr
-			}
-			if item.CurrentValue != nil {
-				currentValueStr := strconv.FormatFloat(*item.CurrentValue, 'f', -1, 64)
-				currentValue = &currentValueStr
-			}
-
-			lineResult, err := money.ConvertLineInput(money.LineInput{
-				Qty:           strconv.FormatFloat(item.Qty, 'f', -1, 64),
-				Rate:          strconv.FormatFloat(item.Rate, 'f', -1, 64),
-				PreviousValue: previousValue,
-				CurrentValue:  currentValue,
-			})
-
-			if err != nil {
-				fmt.Println("*********************** error converting line input", err)
-				return err
-			}
-
-			invoiceItem := &store.InvoiceItem{
-				InvoiceID:          *invoiceId,
-				ItemID:             item.ItemID,
-				Qty:                item.Qty,
-				Rate:               item.Rate,
-				Total:              item.Total,
-				PreviousValue:      item.PreviousValue,
-				CurrentValue:       item.CurrentValue,
-				ItemName:           itemrow.Name,
-				QtyScaled:          lineResult.QtyScaled,
-				RateScaled:         lineResult.RateScaled,
-				TotalCents:         lineResult.TotalCents,
-				PreviousValueCents: lineResult.PreviousValueScaled,
-				CurrentValueCents:  lineResult.CurrentValueScaled,
-			}
-
-			err = s.invoiceItemStore.Create(ctx, tx, invoiceItem)
-			if err != nil {
-				fmt.Println("*********************** error creating invoice item", err)
-				return err
-			}
+		tax, err := s.taxService.lineTax(ctx, item.TaxCodeID, invoiceDTO.BuildingID, lineResult.TotalCents)
+		if err != nil {
+			return nil, err
 		}
 
-		return nil
+		invoiceItem := &store.InvoiceItem{
+			InvoiceID:          *invoiceId,
+			ItemID:             item.ItemID,
+			Qty:                item.Qty,
+			Rate:               item.Rate,
+			Total:              item.Total,
+			PreviousValue:      item.PreviousValue,
+			CurrentValue:       item.CurrentValue,
+			ItemName:           itemrow.Name,
+			QtyScaled:          lineResult.QtyScaled,
+			RateScaled:         lineResult.RateScaled,
+			TotalCents:         lineResult.TotalCents,
+			PreviousValueCents: lineResult.PreviousValueScaled,
+			CurrentValueCents:  lineResult.CurrentValueScaled,
+			TaxCodeID:          tax.TaxCodeID,
+			TaxableCents:       tax.TaxableCents,
+			TaxCents:           tax.TaxCents,
+		}
+
+		err = s.invoiceItemStore.Create(ctx, tx, invoiceItem)
+		if err != nil {
+			fmt.Println("*********************** error creating invoice item", err)
+			return nil, err
+		}
+	}
 
-	})
+	return invoiceId, nil
 }
 
 func (s *InvoiceService) Update(ctx context.Context, invoiceDTO dto.UpdateInvoiceRequestDTO) error {
@@ -419,6 +482,25 @@ func (s *InvoiceService) Update(ctx context.Context, invoiceDTO dto.UpdateInvoic
 			return err
 		}
 
+		if reason, err := s.splitStore.GetLockReason(ctx, tx, existingInvoice.TransactionID); err != nil {
+			return err
+		} else if reason != "" {
+			return fmt.Errorf("invoice cannot be edited: %s", reason)
+		}
+
+		// payments on a foreign currency invoice were realized against its rate and amount
+		if existingInvoice.Currency != nil || invoiceDTO.Currency != nil {
+			payments, err := s.invoicePaymentStore.GetAllByInvoiceID(ctx, existingInvoice.ID)
+			if err != nil {
+				return err
+			}
+			for _, payment := range payments {
+				if payment.Status == "1" {
+					return fmt.Errorf("a foreign currency invoice with payments cannot be edited")
+				}
+			}
+		}
+
 		// create transaction
 		transaction := &store.Transaction{
 			ID:                existingInvoice.TransactionID,
@@ -453,6 +535,21 @@ func (s *InvoiceService) Update(ctx context.Context, invoiceDTO dto.UpdateInvoic
 			return err
 		}
 
+		// put back what the invoice took out of stock, then issue the edited lines
+		itemIDs, err := s.inventoryService.reverseTx(ctx, tx, existingInvoice.TransactionID)
+		if err != nil {
+			return err
+		}
+		inventoryLines, err := invoiceInventoryLines(invoiceDTO.InvoicePayloadDTO)
+		if err != nil {
+			return err
+		}
+		costSplits, err := s.inventoryService.issueTx(ctx, tx, invoiceInventoryPosting(existingInvoice.TransactionID, invoiceDTO.InvoicePayloadDTO), inventoryLines)
+		if err != nil {
+			return err
+		}
+		splits = append(splits, costSplits...)
+
 		if err := validateBalanced(splits); err != nil {
 			fmt.Println("*********************** error validating splits", err)
 			return err
@@ -472,22 +569,33 @@ func (s *InvoiceService) Update(ctx context.Context, invoiceDTO dto.UpdateInvoic
 			fmt.Println("*********************** error parsing amount", err)
 			return err
 		}
+		rate, err := s.currencyService.documentRate(ctx, invoiceDTO.BuildingID, invoiceDTO.Currency, invoiceDTO.SalesDate, invoiceDTO.ExchangeRate)
+		if err != nil {
+			return err
+		}
+		exchangeRateScaled, foreignAmountCents := rate.foreignAmounts(amountCents)
+		if amountCents, err = rate.toBase(amountCents); err != nil {
+			return err
+		}
 
 		// update invoice
 		invoice := &store.Invoice{
-			ID:            existingInvoice.ID,
-			TransactionID: *transactionId,
-			InvoiceNo:     invoiceDTO.InvoiceNo,
-			SalesDate:     invoiceDTO.SalesDate,
-			DueDate:       invoiceDTO.DueDate,
-			UnitID:        &invoiceDTO.UnitID,
-			PeopleID:      &invoiceDTO.PeopleID,
-			ARAccountID:   invoiceDTO.ARAccountID,
-			Amount:        invoiceDTO.Amount,
-			AmountCents:   amountCents, // TODO : make the amount string on request
-			Description:   invoiceDTO.Description,
-			BuildingID:    invoiceDTO.BuildingID,
-			UserID:        1, // TODO: get user id from jwt
+			ID:                 existingInvoice.ID,
+			TransactionID:      *transactionId,
+			InvoiceNo:          invoiceDTO.InvoiceNo,
+			SalesDate:          invoiceDTO.SalesDate,
+			DueDate:            invoiceDTO.DueDate,
+			UnitID:             &invoiceDTO.UnitID,
+			PeopleID:           &invoiceDTO.PeopleID,
+			ARAccountID:        invoiceDTO.ARAccountID,
+			Amount:             float64(amountCents) / float64(money.MoneyScale),
+			AmountCents:        amountCents, // TODO : make the amount string on request
+			Currency:           rate.Currency,
+			ExchangeRateScaled: exchangeRateScaled,
+			ForeignAmountCents: foreignAmountCents,
+			Description:        invoiceDTO.Description,
+			BuildingID:         invoiceDTO.BuildingID,
+			UserID:             1, // TODO: get user id from jwt
 		}
 
 		invoiceId, err := s.invoiceStore.Update(ctx, tx, invoice)
@@ -528,6 +636,14 @@ func (s *InvoiceService) Update(ctx context.Context, invoiceDTO dto.UpdateInvoic
 				PreviousValue: previousValue,
 				CurrentValue:  currentValue,
 			})
+			if err != nil {
+				return err
+			}
+
+			tax, err := s.taxService.lineTax(ctx, item.TaxCodeID, invoiceDTO.BuildingID, lineResult.TotalCents)
+			if err != nil {
+				return err
+			}
 
 			invoiceItem := &store.InvoiceItem{
 				InvoiceID:          *invoiceId,
@@ -543,6 +659,9 @@ func (s *InvoiceService) Update(ctx context.Context, invoiceDTO dto.UpdateInvoic
 				TotalCents:         lineResult.TotalCents,
 				PreviousValueCents: lineResult.PreviousValueScaled,
 				CurrentValueCents:  lineResult.CurrentValueScaled,
+				TaxCodeID:          tax.TaxCodeID,
+				TaxableCents:       tax.TaxableCents,
+				TaxCents:           tax.TaxCents,
 			}
 
 			err = s.invoiceItemStore.Create(ctx, tx, invoiceItem)
@@ -552,7 +671,7 @@ func (s *InvoiceService) Update(ctx context.Context, invoiceDTO dto.UpdateInvoic
 			}
 		}
 
-		return nil
+		return s.inventoryService.checkStockTx(ctx, tx, itemIDs)
 
 	})
 }
@@ -590,6 +709,7 @@ func (s *InvoiceService) GenerateInvoiceSplits(
 
 	// 1. AR Debit
 	var amountCents int64 = 0
+	taxed := false
 
 	// 2. Item lines
 	for _, line := range req.Items {
@@ -604,19 +724,38 @@ func (s *InvoiceService) GenerateInvoiceSplits(
 			return nil, err
 		}
 
-		amountCents += lineResult.TotalCents
+		if line.TaxCodeID != nil && (item.Type == "discount" || item.Type == "payment") {
+			return nil, fmt.Errorf("%s cannot carry a tax code", item.Name)
+		}
+
+		tax, err := s.taxService.lineTax(ctx, line.TaxCodeID, req.BuildingID, lineResult.TotalCents)
+		if err != nil {
+			return nil, err
+		}
+		if line.TaxCodeID != nil {
+			taxed = true
+		}
+
+		amountCents += tax.TaxableCents + tax.TaxCents
 
 		lineTotal := line.Total
 		if lineTotal == 0 {
 			lineTotal = line.Qty * line.Rate
 		}
+		if line.TaxCodeID != nil {
+			lineTotal = float64(tax.TaxableCents) / float64(money.MoneyScale)
+		}
 
-		totalCents := lineResult.TotalCents
+		totalCents := tax.TaxableCents
 
 		switch item.Type {
 
-		case "service":
+		case "service", "non inventory", "inventory":
+			// inventory also moves its cost to COGS, see InventoryService.issueTx
 			addCredit(*item.IncomeAccount, lineTotal, totalCents)
+			if tax.TaxCents != 0 {
+				addCredit(tax.AccountID, float64(tax.TaxCents)/float64(money.MoneyScale), tax.TaxCents)
+			}
 
 		case "discount":
 			addDebit(*item.IncomeAccount, lineTotal, totalCents)
@@ -630,6 +769,18 @@ func (s *InvoiceService) GenerateInvoiceSplits(
 		}
 	}
 
+	// a taxed invoice is saved with its amount, so the amount has to include the tax
+	if taxed {
+		invoiceCents, err := money.ParseUSDAmount(strconv.FormatFloat(req.Amount, 'f', -1, 64))
+		if err != nil {
+			return nil, err
+		}
+		if invoiceCents != amountCents {
+			return nil, fmt.Errorf("amount %s does not match the invoice total of %s including tax",
+				money.FormatMoneyFromCents(invoiceCents), money.FormatMoneyFromCents(amountCents))
+		}
+	}
+
 	addDebit(int64(req.ARAccountID), req.Amount, amountCents)
 	// 3. Build splits
 	splits := make([]store.Split, 0, len(acc))
@@ -661,9 +812,42 @@ func (s *InvoiceService) GenerateInvoiceSplits(
 		})
 	}
 
+	// a foreign currency invoice posts in base currency at its rate
+	rate, err := s.currencyService.documentRate(ctx, req.BuildingID, req.Currency, req.SalesDate, req.ExchangeRate)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.currencyService.convertSplits(ctx, splits, rate, int64(req.ARAccountID)); err != nil {
+		return nil, err
+	}
+
 	return splits, nil
 }
 
+// invoiceInventoryLines is the qty of every invoice line; the inventory service skips non-inventory items
+func invoiceInventoryLines(req dto.InvoicePayloadDTO) ([]inventoryLine, error) {
+	lines := []inventoryLine{}
+	for _, line := range req.Items {
+		qtyScaled, err := money.ParseQty(strconv.FormatFloat(line.Qty, 'f', -1, 64))
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, inventoryLine{ItemID: int64(line.ItemID), QtyScaled: qtyScaled})
+	}
+	return lines, nil
+}
+
+func invoiceInventoryPosting(transactionID int64, req dto.InvoicePayloadDTO) inventoryPosting {
+	return inventoryPosting{
+		TransactionID: transactionID,
+		SourceType:    "invoice",
+		Date:          req.SalesDate,
+		BuildingID:    req.BuildingID,
+		UnitID:        &req.UnitID,
+		PeopleID:      &req.PeopleID,
+	}
+}
+
 func validateBalanced(splits []store.Split) error {
 	var debit, credit float64
 	var debitCents, creditCents int64
@@ -698,97 +882,19 @@ func validateBalanced(splits []store.Split) error {
 func (s *InvoiceService) CreateInvoicePayment(ctx context.Context, paymentDTO dto.CreateInvoicePaymentRequest) (*dto.InvoicePaymentResponse, error) {
 	var response dto.InvoicePaymentResponse
 
-	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
-
-		invoice, err := s.invoiceStore.GetByID(ctx, int64(paymentDTO.InvoiceID))
-		if err != nil {
-			return fmt.Errorf("invoice not found: %v", err)
-		}
-
-		// Validate invoice belongs to the building
-		if invoice.BuildingID != paymentDTO.BuildingID {
-			return fmt.Errorf("invoice does not belong to the specified building")
-		}
-
-		arAccount, err := s.accountStore.GetByID(ctx, int64(invoice.ARAccountID))
-		if err != nil {
-			return fmt.Errorf("A/R account not found: %v", err)
-		}
-
-		// Get Asset Account from request
-		assetAccount, err := s.accountStore.GetByID(ctx, int64(paymentDTO.AccountID))
-		if err != nil {
-			return fmt.Errorf("asset account not found: %v", err)
-		}
-
-		// Validate amount
-		if paymentDTO.Amount == 0 {
-			return fmt.Errorf("amount cannot be zero")
-		}
-
-		// create transaction
-		transaction := &store.Transaction{
-			Type:              "payment",
-			TransactionDate:   paymentDTO.Date,
-			TransactionNumber: paymentDTO.Reference,
-			Memo:              paymentDTO.Reference,
-			Status:            "1",
-			BuildingID:        paymentDTO.BuildingID,
-			UnitID:            invoice.UnitID,
-			UserID:            1, // TODO: get user id from jwt
-		}
-		transactionId, err := s.transactionStore.Create(ctx, tx, transaction)
-		if err != nil {
-			return err
-		}
-
-		// create splits
-		debitSplit := store.Split{
-			TransactionID: *transactionId,
-			AccountID:     assetAccount.ID,
-			Debit:         &paymentDTO.Amount,
-			Credit:        nil,
-			UnitID:        invoice.UnitID,
-			PeopleID:      invoice.PeopleID,
-			Status:        "1",
-		}
-		err = s.splitStore.Create(ctx, tx, &debitSplit)
-		if err != nil {
-			return err
-		}
-
-		creditSplit := store.Split{
-			TransactionID: *transactionId,
-			AccountID:     arAccount.ID,
-			Credit:        &paymentDTO.Amount,
-			Debit:         nil,
-			UnitID:        invoice.UnitID,
-			PeopleID:      invoice.PeopleID,
-			Status:        "1",
-		}
-		err = s.splitStore.Create(ctx, tx, &creditSplit)
-		if err != nil {
-			return err
-		}
-
-		// create invoice payment
-		invoicePayment := &store.InvoicePayment{
-			TransactionID: *transactionId,
-			Reference:     paymentDTO.Reference,
-			Date:          paymentDTO.Date,
-			InvoiceID:     int64(paymentDTO.InvoiceID),
-			UserID:        1, // TODO: get user id from jwt
-			AccountID:     int64(paymentDTO.AccountID),
-			Amount:        paymentDTO.Amount,
-			Status:        "1",
-		}
-
-		_, err = s.invoicePaymentStore.Create(ctx, tx, invoicePayment)
-		if err != nil {
-			return err
-		}
+	poster := invoicePaymentPoster{
+		invoiceStore:        s.invoiceStore,
+		invoicePaymentStore: s.invoicePaymentStore,
+		creditMemoStore:     s.creditMemoStore,
+		transactionStore:    s.transactionStore,
+		splitStore:          s.splitStore,
+		accountStore:        s.accountStore,
+		buildingStore:       s.buildingStore,
+		currencyService:     s.currencyService,
+	}
 
-		return nil
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return poster.post(ctx, tx, paymentDTO)
 	})
 
 	if err != nil {
@@ -1060,7 +1166,7 @@ func (s *InvoiceService) ApplyInvoiceCredits(ctx context.Context, req dto.Create
 	}
 
 	if amountCents > availableAmount {
-		return fmt.Errorf("amount exceeds available credit. Available: %.2f, Requested: %.2f", money.FormatMoneyFromCents(availableAmount), money.FormatMoneyFromCents(amountCents))
+		return fmt.Errorf("amount exceeds available credit. Available: %s, Requested: %s", money.FormatMoneyFromCents(availableAmount), money.FormatMoneyFromCents(amountCents))
 	}
 
 	// Create invoice applied credit record (no transaction or splits needed)
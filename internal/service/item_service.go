@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/mysecodgit/go_accounting/internal/store"
 )
@@ -30,7 +31,12 @@ func (s *ItemService) GetByID(ctx context.Context, id int64) (*store.Item, error
 	return s.store.GetByID(ctx, id)
 }
 
+// Create adds an item with nothing on hand. Opening stock is posted with an inventory adjustment,
+// which books its value to the asset account and records the movement.
 func (s *ItemService) Create(ctx context.Context, i *store.Item) error {
+	if i.OnHand != 0 {
+		return fmt.Errorf("opening stock cannot be set on a new item, post an inventory adjustment once it is created")
+	}
 	return s.store.Create(ctx, i)
 }
 
@@ -49,6 +49,7 @@ type JournalService struct {
 	transactionStore TransactionStore
 	splitStore       SplitStore
 	accountStore     AccountStore
+	currencyService  *CurrencyService
 }
 
 /*
@@ -64,6 +65,7 @@ func NewJournalService(
 	transactionStore TransactionStore,
 	splitStore SplitStore,
 	accountStore AccountStore,
+	currencyService *CurrencyService,
 ) *JournalService {
 	return &JournalService{
 		db:               db,
@@ -72,6 +74,7 @@ func NewJournalService(
 		transactionStore: transactionStore,
 		splitStore:       splitStore,
 		accountStore:     accountStore,
+		currencyService:  currencyService,
 	}
 }
 
@@ -166,6 +169,9 @@ func (s *JournalService) Create(ctx context.Context, req dto.CreateJournalReques
 			fmt.Println("Error validating splits", err)
 			return err
 		}
+		if err := s.currencyService.requireBaseCurrency(ctx, splits); err != nil {
+			return err
+		}
 
 		// create splits
 		for _, split := range splits {
@@ -258,6 +264,12 @@ func (s *JournalService) Update(ctx context.Context, req dto.UpdateJournalReques
 			return fmt.Errorf("journal not found: %v", err)
 		}
 
+		if reason, err := s.splitStore.GetLockReason(ctx, tx, existingJournal.TransactionID); err != nil {
+			return err
+		} else if reason != "" {
+			return fmt.Errorf("journal cannot be edited: %s", reason)
+		}
+
 		// delete journal lines
 		if err := s.journalLineStore.DeleteByJournalID(ctx, tx, journalID); err != nil {
 			fmt.Println("Error deleting journal lines", err)
@@ -367,6 +379,9 @@ func (s *JournalService) Update(ctx context.Context, req dto.UpdateJournalReques
 		if err := s.ValidateBalanced(splits); err != nil {
 			return fmt.Errorf("splits are not balanced: %v", err)
 		}
+		if err := s.currencyService.requireBaseCurrency(ctx, splits); err != nil {
+			return err
+		}
 
 		// create new splits
 		for _, split := range splits {
@@ -449,7 +464,7 @@ func (s *JournalService) ValidateBalanced(splits []store.Split) error {
 		}
 	}
 	if totalDebitCents != totalCreditCents {
-		return fmt.Errorf("splits are not balanced: %d != %d", money.FormatMoneyFromCents(totalDebitCents), money.FormatMoneyFromCents(totalCreditCents))
+		return fmt.Errorf("splits are not balanced: %s != %s", money.FormatMoneyFromCents(totalDebitCents), money.FormatMoneyFromCents(totalCreditCents))
 	}
 	return nil
 }
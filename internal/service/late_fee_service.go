@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+/*
+|--------------------------------------------------------------------------
+| Interfaces
+|--------------------------------------------------------------------------
+*/
+
+type LateFeePolicyStore interface {
+	GetAll(ctx context.Context, buildingID int64) ([]store.LateFeePolicy, error)
+	GetByID(ctx context.Context, id int64) (*store.LateFeePolicy, error)
+	Create(ctx context.Context, p *store.LateFeePolicy) error
+	Update(ctx context.Context, p *store.LateFeePolicy) error
+	Delete(ctx context.Context, id int64) error
+	HasAssessments(ctx context.Context, id int64) (bool, error)
+}
+
+type LateFeeAssessmentStore interface {
+	GetAll(ctx context.Context, buildingID int64, invoiceID *int64) ([]store.LateFeeAssessment, error)
+	GetOverdueInvoices(ctx context.Context, buildingID int64, asOfDate string, graceDays int, period string) ([]store.OverdueInvoice, error)
+	Create(ctx context.Context, tx *sql.Tx, a *store.LateFeeAssessment) error
+}
+
+/*
+|--------------------------------------------------------------------------
+| Service
+|--------------------------------------------------------------------------
+*/
+
+type LateFeeService struct {
+	db                     *sql.DB
+	lateFeePolicyStore     LateFeePolicyStore
+	lateFeeAssessmentStore LateFeeAssessmentStore
+	itemStore              ItemStore
+	invoiceService         *InvoiceService
+}
+
+/*
+|--------------------------------------------------------------------------
+| Constructor
+|--------------------------------------------------------------------------
+*/
+
+func NewLateFeeService(
+	db *sql.DB,
+	lateFeePolicyStore LateFeePolicyStore,
+	lateFeeAssessmentStore LateFeeAssessmentStore,
+	itemStore ItemStore,
+	invoiceService *InvoiceService,
+) *LateFeeService {
+	return &LateFeeService{
+		db:                     db,
+		lateFeePolicyStore:     lateFeePolicyStore,
+		lateFeeAssessmentStore: lateFeeAssessmentStore,
+		itemStore:              itemStore,
+		invoiceService:         invoiceService,
+	}
+}
+
+/*
+|--------------------------------------------------------------------------
+| Queries
+|--------------------------------------------------------------------------
+*/
+
+func (s *LateFeeService) GetPolicies(ctx context.Context, buildingID int64) ([]dto.LateFeePolicyDto, error) {
+	policies, err := s.lateFeePolicyStore.GetAll(ctx, buildingID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.MapLateFeePoliciesToDto(policies), nil
+}
+
+func (s *LateFeeService) GetPolicy(ctx context.Context, buildingID int64, id int64) (*dto.LateFeePolicyDto, error) {
+	policy, err := s.lateFeePolicyStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.BuildingID != buildingID {
+		return nil, fmt.Errorf("late fee policy does not belong to this building")
+	}
+	policyDto := dto.MapLateFeePolicyToDto(*policy)
+	return &policyDto, nil
+}
+
+func (s *LateFeeService) GetAssessments(ctx context.Context, buildingID int64, invoiceID *int64) ([]dto.LateFeeAssessmentDto, error) {
+	assessments, err := s.lateFeeAssessmentStore.GetAll(ctx, buildingID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.MapLateFeeAssessmentsToDto(assessments), nil
+}
+
+// Preview lists the fees a run would post without writing anything
+func (s *LateFeeService) Preview(ctx context.Context, buildingID int64, req dto.LateFeeRunRequest) (*dto.LateFeeRunResponse, error) {
+	_, response, err := s.assess(ctx, buildingID, req)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Commands
+|--------------------------------------------------------------------------
+*/
+
+func (s *LateFeeService) CreatePolicy(ctx context.Context, req dto.CreateLateFeePolicyRequest) (*dto.LateFeePolicyDto, error) {
+	policy, err := s.buildPolicy(ctx, req.LateFeePolicyPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.lateFeePolicyStore.Create(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	return s.GetPolicy(ctx, policy.BuildingID, policy.ID)
+}
+
+func (s *LateFeeService) UpdatePolicy(ctx context.Context, req dto.UpdateLateFeePolicyRequest) (*dto.LateFeePolicyDto, error) {
+	existing, err := s.lateFeePolicyStore.GetByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.BuildingID != req.BuildingID {
+		return nil, fmt.Errorf("late fee policy does not belong to this building")
+	}
+
+	policy, err := s.buildPolicy(ctx, req.LateFeePolicyPayload)
+	if err != nil {
+		return nil, err
+	}
+	policy.ID = req.ID
+
+	if err := s.lateFeePolicyStore.Update(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	return s.GetPolicy(ctx, policy.BuildingID, policy.ID)
+}
+
+// DeletePolicy removes a policy that never charged a fee. A policy with assessments is kept for
+// their history and only deactivated, so later runs skip it.
+func (s *LateFeeService) DeletePolicy(ctx context.Context, buildingID int64, id int64) error {
+	existing, err := s.lateFeePolicyStore.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if existing.BuildingID != buildingID {
+		return fmt.Errorf("late fee policy does not belong to this building")
+	}
+
+	assessed, err := s.lateFeePolicyStore.HasAssessments(ctx, id)
+	if err != nil {
+		return err
+	}
+	if assessed {
+		existing.Status = "0"
+		return s.lateFeePolicyStore.Update(ctx, existing)
+	}
+
+	return s.lateFeePolicyStore.Delete(ctx, id)
+}
+
+// Run posts a fee invoice for every overdue invoice that has not been charged in this period.
+// Each fee invoice and its assessment record are written in the same db transaction.
+func (s *LateFeeService) Run(ctx context.Context, buildingID int64, req dto.LateFeeRunRequest) (*dto.LateFeeRunResponse, error) {
+	policy, response, err := s.assess(ctx, buildingID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range response.Assessments {
+		line := &response.Assessments[i]
+		if line.Skipped != "" {
+			continue
+		}
+
+		err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+			fee := float64(line.FeeCents) / float64(money.MoneyScale)
+			status := 1
+
+			invoiceID, err := s.invoiceService.CreateTx(ctx, tx, dto.CreateInvoiceRequestDTO{
+				InvoicePayloadDTO: dto.InvoicePayloadDTO{
+					InvoiceNo:   line.FeeInvoiceNo,
+					SalesDate:   req.AsOfDate,
+					DueDate:     req.AsOfDate,
+					UnitID:      line.UnitID,
+					PeopleID:    line.PeopleID,
+					ARAccountID: line.ARAccountID,
+					Amount:      fee,
+					Description: fmt.Sprintf("Late fee on Invoice #%s (%s)", line.InvoiceNo, response.Period),
+					Status:      &status,
+					BuildingID:  buildingID,
+					Items: []dto.InvoiceItemInputDTO{
+						{
+							ItemID: int(policy.ItemID),
+							Qty:    1,
+							Rate:   fee,
+							Total:  fee,
+						},
+					},
+				},
+			})
+			if err != nil {
+				return err
+			}
+
+			// unique (invoice_id, period) rejects a concurrent run for the same period
+			if err := s.lateFeeAssessmentStore.Create(ctx, tx, &store.LateFeeAssessment{
+				PolicyID:      policy.ID,
+				InvoiceID:     line.InvoiceID,
+				FeeInvoiceID:  *invoiceID,
+				Period:        response.Period,
+				ApplicationNo: line.ApplicationNo,
+				AmountCents:   line.FeeCents,
+				AssessedDate:  req.AsOfDate,
+				BuildingID:    buildingID,
+			}); err != nil {
+				return err
+			}
+
+			line.FeeInvoiceID = invoiceID
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to assess late fee on invoice #%s: %v", line.InvoiceNo, err)
+		}
+	}
+
+	response.Posted = true
+	return response, nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Helpers
+|--------------------------------------------------------------------------
+*/
+
+func (s *LateFeeService) buildPolicy(ctx context.Context, req dto.LateFeePolicyPayload) (*store.LateFeePolicy, error) {
+	item, err := s.itemStore.GetByID(ctx, req.ItemID)
+	if err != nil {
+		return nil, fmt.Errorf("item not found: %v", err)
+	}
+
+	if item.BuildingID != req.BuildingID {
+		return nil, fmt.Errorf("item does not belong to this building")
+	}
+
+	// fee invoices credit the item's income account
+	if item.Type != "service" || item.IncomeAccount == nil {
+		return nil, fmt.Errorf("late fee item must be a service item with an income account")
+	}
+
+	flatAmountCents, err := money.ParseUSDAmount(strconv.FormatFloat(req.FlatAmount, 'f', -1, 64))
+	if err != nil {
+		return nil, fmt.Errorf("invalid flat amount: %v", err)
+	}
+
+	percentageScaled, err := money.ParseRate(strconv.FormatFloat(req.Percentage, 'f', -1, 64))
+	if err != nil {
+		return nil, fmt.Errorf("invalid percentage: %v", err)
+	}
+
+	if req.FeeType == "flat" && flatAmountCents <= 0 {
+		return nil, fmt.Errorf("flat amount must be greater than 0")
+	}
+
+	if req.FeeType == "percentage" && percentageScaled <= 0 {
+		return nil, fmt.Errorf("percentage must be greater than 0")
+	}
+
+	status := req.Status
+	if status == "" {
+		status = "1"
+	}
+
+	return &store.LateFeePolicy{
+		BuildingID:       req.BuildingID,
+		Name:             req.Name,
+		FeeType:          req.FeeType,
+		FlatAmountCents:  flatAmountCents,
+		PercentageScaled: percentageScaled,
+		GraceDays:        req.GraceDays,
+		MaxApplications:  req.MaxApplications,
+		ItemID:           req.ItemID,
+		Status:           status,
+	}, nil
+}
+
+// assess computes the fee for each overdue invoice, marking the ones that must be skipped
+func (s *LateFeeService) assess(ctx context.Context, buildingID int64, req dto.LateFeeRunRequest) (*store.LateFeePolicy, *dto.LateFeeRunResponse, error) {
+	asOf, err := time.Parse("2006-01-02", req.AsOfDate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid as_of_date: %v", err)
+	}
+
+	policy, err := s.lateFeePolicyStore.GetByID(ctx, req.PolicyID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("late fee policy not found: %v", err)
+	}
+
+	if policy.BuildingID != buildingID {
+		return nil, nil, fmt.Errorf("late fee policy does not belong to this building")
+	}
+
+	if policy.Status != "1" {
+		return nil, nil, fmt.Errorf("late fee policy is inactive")
+	}
+
+	period := asOf.Format("2006-01")
+
+	invoices, err := s.lateFeeAssessmentStore.GetOverdueInvoices(ctx, buildingID, req.AsOfDate, policy.GraceDays, period)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lines := []dto.LateFeeAssessmentLine{}
+	totalFees := int64(0)
+
+	for _, invoice := range invoices {
+		dueDate, _ := time.Parse("2006-01-02", invoice.DueDate)
+
+		feeCents := policy.FlatAmountCents
+		if policy.FeeType == "percentage" {
+			feeCents, err = money.MulDiv(invoice.BalanceCents, policy.PercentageScaled, 100*money.RateScale)
+			if err != nil {
+				return nil, nil, fmt.Errorf("late fee on invoice %s out of allowed range: %v", invoice.InvoiceNo, err)
+			}
+		}
+
+		line := dto.LateFeeAssessmentLine{
+			InvoiceID:     invoice.ID,
+			InvoiceNo:     invoice.InvoiceNo,
+			ARAccountID:   invoice.ARAccountID,
+			PeopleName:    invoice.PeopleName,
+			DueDate:       invoice.DueDate,
+			DaysOverdue:   int(asOf.Sub(dueDate).Hours() / 24),
+			Balance:       money.FormatMoneyFromCents(invoice.BalanceCents),
+			Fee:           money.FormatMoneyFromCents(feeCents),
+			ApplicationNo: invoice.Applications + 1,
+			FeeInvoiceNo:  fmt.Sprintf("%s-LF%d", invoice.InvoiceNo, invoice.Applications+1),
+			FeeCents:      feeCents,
+		}
+		if invoice.PeopleID != nil {
+			line.PeopleID = *invoice.PeopleID
+		}
+		if invoice.UnitID != nil {
+			line.UnitID = *invoice.UnitID
+		}
+
+		switch {
+		case invoice.AssessedThisPeriod:
+			line.Skipped = "already assessed for " + period
+		case invoice.Applications >= policy.MaxApplications:
+			line.Skipped = "maximum number of late fees reached"
+		case feeCents <= 0:
+			line.Skipped = "fee rounds to zero"
+		case invoice.UnitID == nil:
+			line.Skipped = "invoice has no unit"
+		default:
+			totalFees += feeCents
+		}
+
+		lines = append(lines, line)
+	}
+
+	return policy, &dto.LateFeeRunResponse{
+		PolicyID:    policy.ID,
+		AsOfDate:    req.AsOfDate,
+		Period:      period,
+		Posted:      false,
+		Assessments: lines,
+		TotalFees:   money.FormatMoneyFromCents(totalFees),
+	}, nil
+}
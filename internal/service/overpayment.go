@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+// splitOverpayment checks a payment against the open balance of the invoice or bill it pays.
+// The applied part settles the document; an excess is only accepted when the building allows
+// overpayments and has an account configured to hold it.
+func splitOverpayment(balanceCents, amountCents int64, allowOverpayment bool, excessAccountID *int64, excessAccountName string) (int64, int64, error) {
+	if amountCents <= 0 {
+		return 0, 0, fmt.Errorf("amount must be greater than 0")
+	}
+
+	applied := min(max(balanceCents, 0), amountCents)
+	excess := amountCents - applied
+	if excess == 0 {
+		return applied, 0, nil
+	}
+
+	if !allowOverpayment {
+		return 0, 0, fmt.Errorf("payment exceeds the open balance. Balance: %s, Requested: %s",
+			money.FormatMoneyFromCents(max(balanceCents, 0)), money.FormatMoneyFromCents(amountCents))
+	}
+	if excessAccountID == nil {
+		return 0, 0, fmt.Errorf("building has no %s account for the overpayment of %s",
+			excessAccountName, money.FormatMoneyFromCents(excess))
+	}
+
+	return applied, excess, nil
+}
+
+// invoicePaymentPoster posts a payment against one invoice. Any excess over the open balance is
+// credited to the building's customer deposits account and recorded as a credit memo on the same
+// transaction, so it is offered by InvoiceService.GetInvoiceAvailableCredits.
+type invoicePaymentPoster struct {
+	invoiceStore        InvoiceStore
+	invoicePaymentStore InvoicePaymentStore
+	creditMemoStore     CreditMemoStore
+	transactionStore    TransactionStore
+	splitStore          SplitStore
+	accountStore        AccountStore
+	buildingStore       BuildingStore
+	currencyService     *CurrencyService
+}
+
+func (p invoicePaymentPoster) post(ctx context.Context, tx *sql.Tx, paymentDTO dto.CreateInvoicePaymentRequest) error {
+	invoice, err := p.invoiceStore.GetByID(ctx, int64(paymentDTO.InvoiceID))
+	if err != nil {
+		return fmt.Errorf("invoice not found: %v", err)
+	}
+
+	// Validate invoice belongs to the building
+	if invoice.BuildingID != paymentDTO.BuildingID {
+		return fmt.Errorf("invoice does not belong to the specified building")
+	}
+
+	building, err := p.buildingStore.GetByID(ctx, paymentDTO.BuildingID)
+	if err != nil {
+		return fmt.Errorf("building not found: %v", err)
+	}
+
+	arAccount, err := p.accountStore.GetByID(ctx, int64(invoice.ARAccountID))
+	if err != nil {
+		return fmt.Errorf("A/R account not found: %v", err)
+	}
+
+	// Get Asset Account from request; without one the payment waits in undeposited funds
+	if paymentDTO.AccountID == 0 && building.UndepositedFundsAccountID != nil {
+		paymentDTO.AccountID = int(*building.UndepositedFundsAccountID)
+	}
+	assetAccount, err := p.accountStore.GetByID(ctx, int64(paymentDTO.AccountID))
+	if err != nil {
+		return fmt.Errorf("asset account not found: %v", err)
+	}
+
+	amountCents, err := money.ParseUSDAmount(strconv.FormatFloat(paymentDTO.Amount, 'f', -1, 64))
+	if err != nil {
+		return fmt.Errorf("invalid amount: %v", err)
+	}
+
+	balanceCents, err := p.invoiceStore.GetOpenBalanceTx(ctx, tx, invoice.ID)
+	if err != nil {
+		return err
+	}
+
+	// a foreign currency invoice is paid in its currency; the asset takes the payment at the day's
+	// rate and the difference to what it takes off the invoice is a realized gain or loss
+	var rate fxRate
+	var appliedCents, excessCents, cashCents int64
+	if invoice.Currency != nil {
+		rate, err = p.currencyService.documentRate(ctx, paymentDTO.BuildingID, invoice.Currency, paymentDTO.Date, paymentDTO.ExchangeRate)
+		if err != nil {
+			return err
+		}
+		settlement, err := p.currencyService.settle(rate, invoice.AmountCents, *invoice.ForeignAmountCents, balanceCents, amountCents)
+		if err != nil {
+			return err
+		}
+		appliedCents, cashCents = settlement.ReliefCents, settlement.CashCents
+	} else {
+		appliedCents, excessCents, err = splitOverpayment(balanceCents, amountCents, building.AllowOverpayment, building.CustomerDepositsAccountID, "customer deposits")
+		if err != nil {
+			return err
+		}
+		cashCents = amountCents
+	}
+	if excessCents > 0 && (invoice.PeopleID == nil || invoice.UnitID == nil) {
+		return fmt.Errorf("overpayment needs an invoice with a customer and unit to hold the credit")
+	}
+	gainCents := cashCents - appliedCents - excessCents
+
+	assetForeignCents, err := p.currencyService.accountForeignCents(ctx, assetAccount.ID, rate.Currency, amountCents)
+	if err != nil {
+		return err
+	}
+
+	// create transaction
+	transaction := &store.Transaction{
+		Type:              "payment",
+		TransactionDate:   paymentDTO.Date,
+		TransactionNumber: paymentDTO.Reference,
+		Memo:              paymentDTO.Reference,
+		Status:            "1",
+		BuildingID:        paymentDTO.BuildingID,
+		UnitID:            invoice.UnitID,
+		UserID:            1, // TODO: get user id from jwt
+	}
+	transactionID, err := p.transactionStore.Create(ctx, tx, transaction)
+	if err != nil {
+		return err
+	}
+
+	// debit the asset for what was received, credit A/R for the applied part and deposits for the excess
+	assetSplit := newDebitSplit(*transactionID, assetAccount.ID, cashCents, invoice.UnitID, invoice.PeopleID)
+	assetSplit.ForeignCents = assetForeignCents
+	splits := []store.Split{assetSplit}
+	if appliedCents > 0 {
+		splits = append(splits, newCreditSplit(*transactionID, arAccount.ID, appliedCents, invoice.UnitID, invoice.PeopleID))
+	}
+	if excessCents > 0 {
+		splits = append(splits, newCreditSplit(*transactionID, *building.CustomerDepositsAccountID, excessCents, invoice.UnitID, invoice.PeopleID))
+	}
+	fxSplits, err := p.currencyService.realizedSplit(ctx, *transactionID, paymentDTO.BuildingID, gainCents, invoice.UnitID, invoice.PeopleID)
+	if err != nil {
+		return err
+	}
+	splits = append(splits, fxSplits...)
+
+	if err := validateBalanced(splits); err != nil {
+		return err
+	}
+
+	for _, split := range splits {
+		if err := p.splitStore.Create(ctx, tx, &split); err != nil {
+			return err
+		}
+	}
+
+	if appliedCents > 0 {
+		exchangeRateScaled, foreignAmountCents := rate.foreignAmounts(amountCents)
+		invoicePayment := &store.InvoicePayment{
+			TransactionID: *transactionID,
+			Reference:     paymentDTO.Reference,
+			Date:          paymentDTO.Date,
+			InvoiceID:     invoice.ID,
+			UserID:        1, // TODO: get user id from jwt
+			AccountID:     int64(paymentDTO.AccountID),
+			Amount:        float64(appliedCents) / float64(money.MoneyScale),
+			AmountCents:   appliedCents,
+			Status:        "1",
+
+			Currency:           rate.Currency,
+			ExchangeRateScaled: exchangeRateScaled,
+			ForeignAmountCents: foreignAmountCents,
+			FXGainLossCents:    gainCents,
+		}
+		if _, err := p.invoicePaymentStore.Create(ctx, tx, invoicePayment); err != nil {
+			return err
+		}
+	}
+
+	if excessCents > 0 {
+		_, err := p.creditMemoStore.Create(ctx, tx, &store.CreditMemo{
+			TransactionID:    *transactionID,
+			Reference:        paymentDTO.Reference,
+			Date:             paymentDTO.Date,
+			UserID:           1, // TODO: get user id from jwt
+			DepositTo:        int(assetAccount.ID),
+			LiabilityAccount: int(*building.CustomerDepositsAccountID),
+			PeopleID:         *invoice.PeopleID,
+			BuildingID:       paymentDTO.BuildingID,
+			UnitID:           *invoice.UnitID,
+			Amount:           float64(excessCents) / float64(money.MoneyScale),
+			AmountCents:      excessCents,
+			Description:      "Overpayment on invoice " + invoice.InvoiceNo,
+			Status:           1,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
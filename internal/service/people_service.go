@@ -2,7 +2,11 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
 	"github.com/mysecodgit/go_accounting/internal/store"
 )
 
@@ -11,15 +15,17 @@ type PeopleStore interface {
 	GetByID(ctx context.Context, id int64) (*store.People, error)
 	Create(ctx context.Context, p *store.People) error
 	Update(ctx context.Context, p *store.People) error
+	UpdateWithholding(ctx context.Context, id int64, rateScaled int64, accountID *int64) error
 	Delete(ctx context.Context, id int64) error
 }
 
 type PeopleService struct {
-	store PeopleStore
+	store        PeopleStore
+	accountStore AccountStore
 }
 
-func NewPeopleService(store PeopleStore) *PeopleService {
-	return &PeopleService{store: store}
+func NewPeopleService(store PeopleStore, accountStore AccountStore) *PeopleService {
+	return &PeopleService{store: store, accountStore: accountStore}
 }
 
 func (s *PeopleService) GetAll(ctx context.Context, buildingID int64) ([]store.People, error) {
@@ -41,3 +47,40 @@ func (s *PeopleService) Update(ctx context.Context, p *store.People) error {
 func (s *PeopleService) Delete(ctx context.Context, id int64) error {
 	return s.store.Delete(ctx, id)
 }
+
+// SetWithholding sets the tax withheld from payments to a vendor. Payments already made keep
+// the rate they were made under.
+func (s *PeopleService) SetWithholding(ctx context.Context, req dto.VendorWithholdingRequest) (*store.People, error) {
+	vendor, err := s.store.GetByID(ctx, req.PeopleID)
+	if err != nil {
+		return nil, err
+	}
+
+	rateScaled, err := money.ParseRate(strconv.FormatFloat(req.Rate, 'f', -1, 64))
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate: %v", err)
+	}
+
+	accountID := req.AccountID
+	if rateScaled == 0 {
+		accountID = nil
+	} else {
+		if accountID == nil {
+			return nil, fmt.Errorf("withholding account is required")
+		}
+
+		account, err := s.accountStore.GetByID(ctx, *accountID)
+		if err != nil {
+			return nil, fmt.Errorf("account not found: %v", err)
+		}
+		if account.BuildingID != vendor.BuildingID {
+			return nil, fmt.Errorf("account does not belong to this building")
+		}
+	}
+
+	if err := s.store.UpdateWithholding(ctx, vendor.ID, rateScaled, accountID); err != nil {
+		return nil, err
+	}
+
+	return s.store.GetByID(ctx, vendor.ID)
+}
@@ -0,0 +1,635 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+/*
+|--------------------------------------------------------------------------
+| Interfaces
+|--------------------------------------------------------------------------
+*/
+
+type PurchaseOrderStore interface {
+	GetAll(ctx context.Context, buildingID int64, peopleID *int64, status *string) ([]store.PurchaseOrder, error)
+	GetByID(ctx context.Context, id int64) (*store.PurchaseOrder, error)
+	GetByIDTx(ctx context.Context, tx *sql.Tx, id int64) (*store.PurchaseOrder, error)
+	Create(ctx context.Context, tx *sql.Tx, po *store.PurchaseOrder) error
+	Update(ctx context.Context, tx *sql.Tx, po *store.PurchaseOrder) error
+	Delete(ctx context.Context, id int64) error
+	SetStatusTx(ctx context.Context, tx *sql.Tx, id int64, status, manuallyClosed string) error
+	HasBills(ctx context.Context, id int64) (bool, error)
+	GetIDsByBillTx(ctx context.Context, tx *sql.Tx, billID int64) ([]int64, error)
+	GetLines(ctx context.Context, purchaseOrderID int64) ([]store.PurchaseOrderLine, error)
+	GetLinesTx(ctx context.Context, tx *sql.Tx, purchaseOrderID, excludeBillID int64) ([]store.PurchaseOrderLine, error)
+	CreateLine(ctx context.Context, tx *sql.Tx, l *store.PurchaseOrderLine) error
+	DeleteLines(ctx context.Context, tx *sql.Tx, purchaseOrderID int64) error
+}
+
+/*
+|--------------------------------------------------------------------------
+| Service
+|--------------------------------------------------------------------------
+*/
+
+// PurchaseOrderService keeps purchase orders and matches vendor bills against them. The bill
+// service calls matchTx while saving a bill and holds bills outside the tolerance for approval.
+type PurchaseOrderService struct {
+	db                 *sql.DB
+	purchaseOrderStore PurchaseOrderStore
+	peopleStore        PeopleStore
+	accountStore       AccountStore
+	itemStore          ItemStore
+}
+
+/*
+|--------------------------------------------------------------------------
+| Constructor
+|--------------------------------------------------------------------------
+*/
+
+func NewPurchaseOrderService(
+	db *sql.DB,
+	purchaseOrderStore PurchaseOrderStore,
+	peopleStore PeopleStore,
+	accountStore AccountStore,
+	itemStore ItemStore,
+) *PurchaseOrderService {
+	return &PurchaseOrderService{
+		db:                 db,
+		purchaseOrderStore: purchaseOrderStore,
+		peopleStore:        peopleStore,
+		accountStore:       accountStore,
+		itemStore:          itemStore,
+	}
+}
+
+/*
+|--------------------------------------------------------------------------
+| Queries
+|--------------------------------------------------------------------------
+*/
+
+func (s *PurchaseOrderService) GetAll(ctx context.Context, buildingID int64, peopleID *int64, status *string) ([]dto.PurchaseOrderDto, error) {
+	orders, err := s.purchaseOrderStore.GetAll(ctx, buildingID, peopleID, status)
+	if err != nil {
+		return nil, err
+	}
+	return dto.MapPurchaseOrdersToDto(orders), nil
+}
+
+func (s *PurchaseOrderService) GetByID(ctx context.Context, id int64) (*dto.PurchaseOrderDetailsResponse, error) {
+	po, err := s.purchaseOrderStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.purchaseOrderStore.GetLines(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PurchaseOrderDetailsResponse{
+		PurchaseOrder: dto.MapPurchaseOrderToDto(*po),
+		Lines:         dto.MapPurchaseOrderLinesToDto(lines),
+	}, nil
+}
+
+// GetBillDraft pre-fills a bill with what is left to bill on each line of the purchase order.
+// The caller adds the bill number, dates and A/P account and creates it as any other bill.
+func (s *PurchaseOrderService) GetBillDraft(ctx context.Context, id int64) (*dto.CreateBillRequest, error) {
+	po, err := s.purchaseOrderStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if po.Status == "closed" {
+		return nil, fmt.Errorf("purchase order %s is closed", po.PONo)
+	}
+
+	lines, err := s.purchaseOrderStore.GetLines(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	peopleID := po.PeopleID
+	req := &dto.CreateBillRequest{
+		BillPayloadDTO: dto.BillPayloadDTO{
+			UnitID:          po.UnitID,
+			PeopleID:        &peopleID,
+			BuildingID:      po.BuildingID,
+			Description:     fmt.Sprintf("PO %s", po.PONo),
+			ExpenseLines:    []dto.BillExpenseLineInput{},
+			PurchaseOrderID: &po.ID,
+		},
+	}
+
+	var totalCents int64
+	for _, l := range lines {
+		remainingQty := l.QtyScaled - l.BilledQtyScaled
+		remainingCents := l.AmountCents - l.BilledCents
+		if remainingQty <= 0 || remainingCents <= 0 {
+			continue
+		}
+
+		description := l.Description
+		if description == nil {
+			description = l.ItemName
+		}
+
+		qty := float64(remainingQty) / float64(money.QtyScale)
+		req.ExpenseLines = append(req.ExpenseLines, dto.BillExpenseLineInput{
+			AccountID:   l.AccountID,
+			UnitID:      l.UnitID,
+			Description: description,
+			Amount:      float64(remainingCents) / float64(money.MoneyScale),
+			POLineID:    &l.ID,
+			Qty:         &qty,
+			ItemID:      l.ItemID,
+		})
+		totalCents += remainingCents
+	}
+
+	if len(req.ExpenseLines) == 0 {
+		return nil, fmt.Errorf("purchase order %s has nothing left to bill", po.PONo)
+	}
+	req.Amount = float64(totalCents) / float64(money.MoneyScale)
+
+	return req, nil
+}
+
+// Match checks a bill against its purchase order without saving it
+func (s *PurchaseOrderService) Match(ctx context.Context, req dto.BillPayloadDTO, billID int64) (*dto.PurchaseOrderMatchResponse, error) {
+	var match *dto.PurchaseOrderMatchResponse
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var err error
+		match, err = s.matchTx(ctx, tx, req, billID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return match, nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Commands
+|--------------------------------------------------------------------------
+*/
+
+func (s *PurchaseOrderService) Create(ctx context.Context, req dto.CreatePurchaseOrderRequest) (*dto.PurchaseOrderDetailsResponse, error) {
+	po, lines, err := s.buildPurchaseOrder(ctx, req.PurchaseOrderPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.purchaseOrderStore.Create(ctx, tx, po); err != nil {
+			return err
+		}
+		return s.createLines(ctx, tx, po.ID, lines)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, po.ID)
+}
+
+// Update rewrites a purchase order that nothing has been billed against yet
+func (s *PurchaseOrderService) Update(ctx context.Context, req dto.UpdatePurchaseOrderRequest) (*dto.PurchaseOrderDetailsResponse, error) {
+	existing, err := s.purchaseOrderStore.GetByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.BuildingID != req.BuildingID {
+		return nil, fmt.Errorf("purchase order does not belong to this building")
+	}
+	if existing.Status == "closed" {
+		return nil, fmt.Errorf("purchase order %s is closed", existing.PONo)
+	}
+
+	if billed, err := s.purchaseOrderStore.HasBills(ctx, existing.ID); err != nil {
+		return nil, err
+	} else if billed {
+		return nil, fmt.Errorf("purchase order %s has bills against it and cannot be edited, close it instead", existing.PONo)
+	}
+
+	po, lines, err := s.buildPurchaseOrder(ctx, req.PurchaseOrderPayload)
+	if err != nil {
+		return nil, err
+	}
+	po.ID = existing.ID
+
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.purchaseOrderStore.Update(ctx, tx, po); err != nil {
+			return err
+		}
+		if err := s.purchaseOrderStore.DeleteLines(ctx, tx, po.ID); err != nil {
+			return err
+		}
+		return s.createLines(ctx, tx, po.ID, lines)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, po.ID)
+}
+
+func (s *PurchaseOrderService) Delete(ctx context.Context, id int64) error {
+	po, err := s.purchaseOrderStore.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if billed, err := s.purchaseOrderStore.HasBills(ctx, id); err != nil {
+		return err
+	} else if billed {
+		return fmt.Errorf("purchase order %s has bills against it and cannot be deleted, close it instead", po.PONo)
+	}
+
+	return s.purchaseOrderStore.Delete(ctx, id)
+}
+
+// Close stops further billing on a purchase order whatever is left on it
+func (s *PurchaseOrderService) Close(ctx context.Context, id, buildingID int64) (*dto.PurchaseOrderDetailsResponse, error) {
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		po, err := s.purchaseOrderStore.GetByIDTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if po.BuildingID != buildingID {
+			return fmt.Errorf("purchase order does not belong to this building")
+		}
+		if po.Status == "closed" {
+			return fmt.Errorf("purchase order %s is already closed", po.PONo)
+		}
+
+		return s.purchaseOrderStore.SetStatusTx(ctx, tx, id, "closed", "1")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// Reopen undoes Close; a purchase order closed by being fully billed stays closed
+func (s *PurchaseOrderService) Reopen(ctx context.Context, id, buildingID int64) (*dto.PurchaseOrderDetailsResponse, error) {
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		po, err := s.purchaseOrderStore.GetByIDTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if po.BuildingID != buildingID {
+			return fmt.Errorf("purchase order does not belong to this building")
+		}
+		if po.ManuallyClosed != "1" {
+			return fmt.Errorf("purchase order %s was not closed by hand", po.PONo)
+		}
+
+		if err := s.purchaseOrderStore.SetStatusTx(ctx, tx, id, po.Status, "0"); err != nil {
+			return err
+		}
+		return s.refreshStatusTx(ctx, tx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// matchTx compares the lines of a bill with the purchase order lines they bill. Everything
+// billed before, other than by billID itself, counts towards the ordered qty and amount.
+// Only overruns beyond the purchase order's tolerance are reported.
+func (s *PurchaseOrderService) matchTx(ctx context.Context, tx *sql.Tx, req dto.BillPayloadDTO, billID int64) (*dto.PurchaseOrderMatchResponse, error) {
+	if req.PurchaseOrderID == nil {
+		return nil, fmt.Errorf("purchase_order_id is required")
+	}
+
+	po, err := s.purchaseOrderStore.GetByIDTx(ctx, tx, *req.PurchaseOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("purchase order not found: %v", err)
+	}
+	if po.BuildingID != req.BuildingID {
+		return nil, fmt.Errorf("purchase order does not belong to this building")
+	}
+	if req.PeopleID == nil || *req.PeopleID != po.PeopleID {
+		return nil, fmt.Errorf("bill vendor does not match the vendor of purchase order %s", po.PONo)
+	}
+
+	if po.Status == "closed" {
+		// a bill already on a closed purchase order can still be corrected
+		ids := []int64{}
+		if billID != 0 {
+			if ids, err = s.purchaseOrderStore.GetIDsByBillTx(ctx, tx, billID); err != nil {
+				return nil, err
+			}
+		}
+		if !slices.Contains(ids, po.ID) {
+			return nil, fmt.Errorf("purchase order %s is closed", po.PONo)
+		}
+	}
+
+	lines, err := s.purchaseOrderStore.GetLinesTx(ctx, tx, po.ID, billID)
+	if err != nil {
+		return nil, err
+	}
+
+	poLines := map[int64]store.PurchaseOrderLine{}
+	billedQty := map[int64]int64{}
+	billedCents := map[int64]int64{}
+	var previousCents int64
+	for _, l := range lines {
+		poLines[l.ID] = l
+		billedQty[l.ID] = l.BilledQtyScaled
+		billedCents[l.ID] = l.BilledCents
+		previousCents += l.BilledCents
+	}
+
+	// a ceiling too large for an int64 is as good as none
+	tolerance := func(value int64) int64 {
+		extra, err := money.MulDiv(value, po.ToleranceScaled, 100*money.RateScale)
+		if err != nil || extra > math.MaxInt64-value {
+			return math.MaxInt64
+		}
+		return value + extra
+	}
+
+	match := &dto.PurchaseOrderMatchResponse{
+		PurchaseOrderID: po.ID,
+		PONo:            po.PONo,
+		Tolerance:       money.FormatScaled5(po.ToleranceScaled),
+		OrderedAmount:   money.FormatMoneyFromCents(po.AmountCents),
+		PreviousAmount:  money.FormatMoneyFromCents(previousCents),
+		Lines:           []dto.PurchaseOrderMatchLine{},
+		Warnings:        []string{},
+		WithinTolerance: true,
+	}
+
+	for i, line := range req.ExpenseLines {
+		amountCents, err := money.ParseUSDAmount(strconv.FormatFloat(line.Amount, 'f', -1, 64))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse amount: %v", err)
+		}
+
+		matchLine := dto.PurchaseOrderMatchLine{
+			Line:        i + 1,
+			POLineID:    line.POLineID,
+			Description: line.Description,
+			Amount:      money.FormatMoneyFromCents(amountCents),
+			Warnings:    []string{},
+		}
+
+		if line.POLineID == nil {
+			matchLine.Warnings = append(matchLine.Warnings, "line is not on the purchase order")
+			match.Lines = append(match.Lines, matchLine)
+			match.WithinTolerance = false
+			continue
+		}
+
+		poLine, ok := poLines[*line.POLineID]
+		if !ok {
+			return nil, fmt.Errorf("line %d: purchase order line %d is not on purchase order %s", i+1, *line.POLineID, po.PONo)
+		}
+
+		matchLine.OrderedQty = money.FormatScaled5(poLine.QtyScaled)
+		matchLine.PreviouslyBilled = money.FormatScaled5(billedQty[poLine.ID])
+		matchLine.OrderedRate = money.FormatScaled5(poLine.RateScaled)
+		matchLine.OrderedAmount = money.FormatMoneyFromCents(poLine.AmountCents)
+		matchLine.PreviousAmount = money.FormatMoneyFromCents(billedCents[poLine.ID])
+
+		qtyScaled, err := billLineQty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+
+		if qtyScaled != nil {
+			matchLine.Qty = money.FormatScaled5(*qtyScaled)
+
+			if billedQty[poLine.ID]+*qtyScaled > tolerance(poLine.QtyScaled) {
+				matchLine.Warnings = append(matchLine.Warnings, fmt.Sprintf("brings billed qty to %s of %s ordered",
+					money.FormatScaled5(billedQty[poLine.ID]+*qtyScaled), money.FormatScaled5(poLine.QtyScaled)))
+			}
+
+			if *qtyScaled > 0 {
+				// CalculateTotalCents turned around, the rate is the cost of one unit
+				rateScaled := money.AverageCostScaled(*qtyScaled, amountCents)
+				matchLine.Rate = money.FormatScaled5(rateScaled)
+
+				if rateScaled > tolerance(poLine.RateScaled) {
+					matchLine.Warnings = append(matchLine.Warnings, fmt.Sprintf("rate %s is above the ordered %s",
+						money.FormatScaled5(rateScaled), money.FormatScaled5(poLine.RateScaled)))
+				}
+			}
+
+			billedQty[poLine.ID] += *qtyScaled
+		}
+
+		if billedCents[poLine.ID]+amountCents > tolerance(poLine.AmountCents) {
+			matchLine.Warnings = append(matchLine.Warnings, fmt.Sprintf("brings billed amount to %s of %s ordered",
+				money.FormatMoneyFromCents(billedCents[poLine.ID]+amountCents), money.FormatMoneyFromCents(poLine.AmountCents)))
+		}
+		billedCents[poLine.ID] += amountCents
+
+		if len(matchLine.Warnings) > 0 {
+			match.WithinTolerance = false
+		}
+		match.Lines = append(match.Lines, matchLine)
+	}
+
+	amountCents, err := money.ParseUSDAmount(strconv.FormatFloat(req.Amount, 'f', -1, 64))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse amount: %v", err)
+	}
+	match.Amount = money.FormatMoneyFromCents(amountCents)
+
+	if previousCents+amountCents > tolerance(po.AmountCents) {
+		match.Warnings = append(match.Warnings, fmt.Sprintf("bill brings purchase order %s to %s of %s ordered",
+			po.PONo, money.FormatMoneyFromCents(previousCents+amountCents), money.FormatMoneyFromCents(po.AmountCents)))
+		match.WithinTolerance = false
+	}
+
+	return match, nil
+}
+
+// billPurchaseOrdersTx returns the purchase orders a saved bill is matched against
+func (s *PurchaseOrderService) billPurchaseOrdersTx(ctx context.Context, tx *sql.Tx, billID int64) ([]int64, error) {
+	return s.purchaseOrderStore.GetIDsByBillTx(ctx, tx, billID)
+}
+
+// refreshStatusTx moves a purchase order between open, partially billed and closed after
+// a bill against it is saved or rejected. Purchase orders closed by hand are left alone.
+func (s *PurchaseOrderService) refreshStatusTx(ctx context.Context, tx *sql.Tx, ids ...int64) error {
+	for _, id := range ids {
+		po, err := s.purchaseOrderStore.GetByIDTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if po.ManuallyClosed == "1" {
+			continue
+		}
+
+		lines, err := s.purchaseOrderStore.GetLinesTx(ctx, tx, id, 0)
+		if err != nil {
+			return err
+		}
+
+		billed, complete := false, true
+		for _, l := range lines {
+			if l.BilledQtyScaled > 0 || l.BilledCents > 0 {
+				billed = true
+			}
+			if l.BilledQtyScaled < l.QtyScaled && l.BilledCents < l.AmountCents {
+				complete = false
+			}
+		}
+
+		status := "open"
+		if billed && complete {
+			status = "closed"
+		} else if billed {
+			status = "partially_billed"
+		}
+
+		if status == po.Status {
+			continue
+		}
+		if err := s.purchaseOrderStore.SetStatusTx(ctx, tx, id, status, "0"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Helpers
+|--------------------------------------------------------------------------
+*/
+
+func (s *PurchaseOrderService) buildPurchaseOrder(ctx context.Context, req dto.PurchaseOrderPayload) (*store.PurchaseOrder, []store.PurchaseOrderLine, error) {
+	people, err := s.peopleStore.GetByID(ctx, req.PeopleID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("vendor not found: %v", err)
+	}
+	if people.BuildingID != req.BuildingID {
+		return nil, nil, fmt.Errorf("vendor does not belong to this building")
+	}
+
+	toleranceScaled, err := money.ParseRate(strconv.FormatFloat(req.Tolerance, 'f', -1, 64))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid tolerance: %v", err)
+	}
+
+	if req.ExpectedDate != nil && *req.ExpectedDate == "" {
+		req.ExpectedDate = nil
+	}
+
+	lines := []store.PurchaseOrderLine{}
+	var amountCents int64
+	for i, line := range req.Lines {
+		accountID := line.AccountID
+
+		if line.ItemID != nil {
+			item, err := s.itemStore.GetByID(ctx, *line.ItemID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: item not found: %v", i+1, err)
+			}
+			if item.BuildingID != req.BuildingID {
+				return nil, nil, fmt.Errorf("line %d: item %s does not belong to this building", i+1, item.Name)
+			}
+			if accountID == 0 {
+				itemAccount := item.ExpenseAccount
+				if item.Type == "inventory" {
+					itemAccount = item.AssetAccount
+				}
+				if itemAccount == nil {
+					return nil, nil, fmt.Errorf("line %d: item %s has no account to bill to", i+1, item.Name)
+				}
+				accountID = *itemAccount
+			}
+		}
+
+		if accountID == 0 {
+			return nil, nil, fmt.Errorf("line %d: account_id or item_id is required", i+1)
+		}
+		account, err := s.accountStore.GetByID(ctx, accountID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: account %d not found", i+1, accountID)
+		}
+		if account.BuildingID != req.BuildingID {
+			return nil, nil, fmt.Errorf("line %d: account %s does not belong to this building", i+1, account.AccountName)
+		}
+
+		lineResult, err := money.ConvertLineInput(money.LineInput{
+			Qty:  strconv.FormatFloat(line.Qty, 'f', -1, 64),
+			Rate: strconv.FormatFloat(line.Rate, 'f', -1, 64),
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+
+		lines = append(lines, store.PurchaseOrderLine{
+			ItemID:      line.ItemID,
+			AccountID:   accountID,
+			UnitID:      line.UnitID,
+			Description: line.Description,
+			QtyScaled:   lineResult.QtyScaled,
+			RateScaled:  lineResult.RateScaled,
+			AmountCents: lineResult.TotalCents,
+		})
+		amountCents += lineResult.TotalCents
+	}
+
+	po := &store.PurchaseOrder{
+		PONo:            req.PONo,
+		PODate:          req.PODate,
+		ExpectedDate:    req.ExpectedDate,
+		PeopleID:        req.PeopleID,
+		UnitID:          req.UnitID,
+		Description:     req.Description,
+		AmountCents:     amountCents,
+		ToleranceScaled: toleranceScaled,
+		UserID:          1, // TODO: get user id from jwt
+		BuildingID:      req.BuildingID,
+	}
+
+	return po, lines, nil
+}
+
+func (s *PurchaseOrderService) createLines(ctx context.Context, tx *sql.Tx, purchaseOrderID int64, lines []store.PurchaseOrderLine) error {
+	for _, line := range lines {
+		line.PurchaseOrderID = purchaseOrderID
+		if err := s.purchaseOrderStore.CreateLine(ctx, tx, &line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// billLineQty parses the qty a bill line bills against its purchase order line, if any
+func billLineQty(line dto.BillExpenseLineInput) (*int64, error) {
+	if line.Qty == nil {
+		return nil, nil
+	}
+
+	qtyScaled, err := money.ParseQty(strconv.FormatFloat(*line.Qty, 'f', -1, 64))
+	if err != nil {
+		return nil, err
+	}
+
+	return &qtyScaled, nil
+}
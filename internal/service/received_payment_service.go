@@ -0,0 +1,439 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+/*
+|--------------------------------------------------------------------------
+| Interfaces
+|--------------------------------------------------------------------------
+*/
+
+type ReceivedPaymentStore interface {
+	GetAll(ctx context.Context, buildingID int64, peopleID *int) ([]store.ReceivedPayment, error)
+	GetByID(ctx context.Context, id int64) (*store.ReceivedPayment, error)
+	GetAllocations(ctx context.Context, transactionID int64) ([]store.ReceivedPaymentAllocation, error)
+	GetOpenInvoices(ctx context.Context, buildingID int64, peopleID int64) ([]store.CustomerOpenInvoice, error)
+	GetOpenInvoicesTx(ctx context.Context, tx *sql.Tx, buildingID int64, peopleID int64) ([]store.CustomerOpenInvoice, error)
+	Create(ctx context.Context, tx *sql.Tx, p *store.ReceivedPayment) error
+}
+
+/*
+|--------------------------------------------------------------------------
+| Service
+|--------------------------------------------------------------------------
+*/
+
+// ReceivedPaymentService posts one customer payment as a single deposit transaction
+// allocated across several open invoices. The unapplied remainder becomes a credit memo
+// so it can be applied later through InvoiceService.ApplyInvoiceCredits.
+type ReceivedPaymentService struct {
+	db                   *sql.DB
+	receivedPaymentStore ReceivedPaymentStore
+	invoicePaymentStore  InvoicePaymentStore
+	creditMemoStore      CreditMemoStore
+	transactionStore     TransactionStore
+	splitStore           SplitStore
+	accountStore         AccountStore
+	peopleStore          PeopleStore
+	buildingStore        BuildingStore
+	currencyService      *CurrencyService
+}
+
+// receiveAllocation is one invoice and the cents applied to it
+type receiveAllocation struct {
+	invoice     store.CustomerOpenInvoice
+	amountCents int64
+}
+
+/*
+|--------------------------------------------------------------------------
+| Constructor
+|--------------------------------------------------------------------------
+*/
+
+func NewReceivedPaymentService(
+	db *sql.DB,
+	receivedPaymentStore ReceivedPaymentStore,
+	invoicePaymentStore InvoicePaymentStore,
+	creditMemoStore CreditMemoStore,
+	transactionStore TransactionStore,
+	splitStore SplitStore,
+	accountStore AccountStore,
+	peopleStore PeopleStore,
+	buildingStore BuildingStore,
+	currencyService *CurrencyService,
+) *ReceivedPaymentService {
+	return &ReceivedPaymentService{
+		db:                   db,
+		receivedPaymentStore: receivedPaymentStore,
+		invoicePaymentStore:  invoicePaymentStore,
+		creditMemoStore:      creditMemoStore,
+		transactionStore:     transactionStore,
+		splitStore:           splitStore,
+		accountStore:         accountStore,
+		peopleStore:          peopleStore,
+		buildingStore:        buildingStore,
+		currencyService:      currencyService,
+	}
+}
+
+/*
+|--------------------------------------------------------------------------
+| Queries
+|--------------------------------------------------------------------------
+*/
+
+func (s *ReceivedPaymentService) GetAll(ctx context.Context, buildingID int64, peopleID *int) ([]dto.ReceivedPaymentDto, error) {
+	payments, err := s.receivedPaymentStore.GetAll(ctx, buildingID, peopleID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.MapReceivedPaymentsToDto(payments), nil
+}
+
+func (s *ReceivedPaymentService) GetByID(ctx context.Context, id int64) (*dto.ReceivedPaymentDto, error) {
+	payment, err := s.receivedPaymentStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	allocations, err := s.receivedPaymentStore.GetAllocations(ctx, payment.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentDto := dto.MapReceivedPaymentToDto(*payment)
+	paymentDto.Allocations = dto.MapReceivedPaymentAllocationsToDto(allocations)
+	return &paymentDto, nil
+}
+
+// Preview shows how the amount would be allocated without posting anything
+func (s *ReceivedPaymentService) Preview(ctx context.Context, req dto.ReceivePaymentPayload) (*dto.ReceivePaymentPreviewResponse, error) {
+	openInvoices, err := s.receivedPaymentStore.GetOpenInvoices(ctx, req.BuildingID, req.PeopleID)
+	if err != nil {
+		return nil, err
+	}
+
+	amountCents, allocations, unappliedCents, err := allocateReceivedPayment(req, openInvoices)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ReceivePaymentPreviewResponse{
+		PeopleID:    req.PeopleID,
+		Amount:      money.FormatMoneyFromCents(amountCents),
+		Applied:     money.FormatMoneyFromCents(amountCents - unappliedCents),
+		Unapplied:   money.FormatMoneyFromCents(unappliedCents),
+		Allocations: []dto.ReceivePaymentAllocationDto{},
+	}
+	for _, a := range allocations {
+		response.Allocations = append(response.Allocations, dto.ReceivePaymentAllocationDto{
+			InvoiceID: a.invoice.ID,
+			InvoiceNo: a.invoice.InvoiceNo,
+			DueDate:   a.invoice.DueDate,
+			Balance:   money.FormatMoneyFromCents(a.invoice.BalanceCents),
+			Amount:    money.FormatMoneyFromCents(a.amountCents),
+		})
+	}
+
+	return response, nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Commands
+|--------------------------------------------------------------------------
+*/
+
+func (s *ReceivedPaymentService) Create(ctx context.Context, req dto.CreateReceivePaymentRequest) (*dto.ReceivedPaymentDto, error) {
+	customer, err := s.peopleStore.GetByID(ctx, req.PeopleID)
+	if err != nil {
+		return nil, fmt.Errorf("customer not found")
+	}
+	if customer.BuildingID != req.BuildingID {
+		return nil, fmt.Errorf("customer does not belong to this building")
+	}
+
+	depositAccount, err := s.accountStore.GetByID(ctx, req.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("deposit account not found")
+	}
+	if depositAccount.BuildingID != req.BuildingID {
+		return nil, fmt.Errorf("deposit account does not belong to this building")
+	}
+
+	peopleID := req.PeopleID
+	var response dto.ReceivedPaymentDto
+
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		// the balances are read with the invoices locked, so two payments cannot both settle the same invoice
+		openInvoices, err := s.receivedPaymentStore.GetOpenInvoicesTx(ctx, tx, req.BuildingID, req.PeopleID)
+		if err != nil {
+			return err
+		}
+
+		amountCents, allocations, unappliedCents, err := allocateReceivedPayment(req.ReceivePaymentPayload, openInvoices)
+		if err != nil {
+			return err
+		}
+
+		// the remainder is held in a liability account against a unit, like a credit memo
+		var creditUnitID int64
+		if unappliedCents > 0 {
+			if req.LiabilityAccountID == nil {
+				building, err := s.buildingStore.GetByID(ctx, req.BuildingID)
+				if err != nil {
+					return fmt.Errorf("building not found: %v", err)
+				}
+				req.LiabilityAccountID = building.CustomerDepositsAccountID
+			}
+			if req.LiabilityAccountID == nil {
+				return fmt.Errorf("liability_account_id is required for the unapplied amount of %s", money.FormatMoneyFromCents(unappliedCents))
+			}
+			liabilityAccount, err := s.accountStore.GetByID(ctx, *req.LiabilityAccountID)
+			if err != nil {
+				return fmt.Errorf("liability account not found")
+			}
+			if liabilityAccount.BuildingID != req.BuildingID {
+				return fmt.Errorf("liability account does not belong to this building")
+			}
+
+			unitID := s.creditUnit(req.UnitID, allocations)
+			if unitID == nil {
+				return fmt.Errorf("unit_id is required for the unapplied amount of %s", money.FormatMoneyFromCents(unappliedCents))
+			}
+			creditUnitID = *unitID
+		}
+
+		transaction := &store.Transaction{
+			Type:              "payment",
+			TransactionDate:   req.Date,
+			TransactionNumber: req.Reference,
+			Memo:              req.Memo,
+			Status:            "1",
+			BuildingID:        req.BuildingID,
+			UserID:            1, // TODO: get user id from jwt
+			UnitID:            nil,
+		}
+
+		transactionID, err := s.transactionStore.Create(ctx, tx, transaction)
+		if err != nil {
+			return err
+		}
+
+		// debit the deposit account for the full amount, credit AR per invoice and the liability for the rest
+		splits := []store.Split{newDebitSplit(*transactionID, req.AccountID, amountCents, nil, &peopleID)}
+		for _, a := range allocations {
+			splits = append(splits, newCreditSplit(*transactionID, int64(a.invoice.ARAccountID), a.amountCents, a.invoice.UnitID, &peopleID))
+		}
+		if unappliedCents > 0 {
+			splits = append(splits, newCreditSplit(*transactionID, *req.LiabilityAccountID, unappliedCents, &creditUnitID, &peopleID))
+		}
+
+		if err := validateBalanced(splits); err != nil {
+			return err
+		}
+
+		if err := s.currencyService.requireBaseCurrency(ctx, splits); err != nil {
+			return err
+		}
+
+		for _, split := range splits {
+			if err := s.splitStore.Create(ctx, tx, &split); err != nil {
+				return err
+			}
+		}
+
+		response.Allocations = []dto.ReceivePaymentAllocationDto{}
+		for _, a := range allocations {
+			invoicePayment, err := s.invoicePaymentStore.Create(ctx, tx, &store.InvoicePayment{
+				TransactionID: *transactionID,
+				Reference:     req.Reference,
+				Date:          req.Date,
+				InvoiceID:     a.invoice.ID,
+				UserID:        1, // TODO: get user id from jwt
+				AccountID:     req.AccountID,
+				Amount:        float64(a.amountCents) / float64(money.MoneyScale),
+				AmountCents:   a.amountCents,
+				Status:        "1",
+			})
+			if err != nil {
+				return err
+			}
+
+			response.Allocations = append(response.Allocations, dto.ReceivePaymentAllocationDto{
+				InvoiceID:        a.invoice.ID,
+				InvoiceNo:        a.invoice.InvoiceNo,
+				InvoicePaymentID: &invoicePayment.ID,
+				DueDate:          a.invoice.DueDate,
+				Balance:          money.FormatMoneyFromCents(a.invoice.BalanceCents),
+				Amount:           money.FormatMoneyFromCents(a.amountCents),
+			})
+		}
+
+		var creditMemoID *int64
+		if unappliedCents > 0 {
+			creditMemoID, err = s.creditMemoStore.Create(ctx, tx, &store.CreditMemo{
+				TransactionID:    *transactionID,
+				Reference:        req.Reference,
+				Date:             req.Date,
+				UserID:           1, // TODO: get user id from jwt
+				DepositTo:        int(req.AccountID),
+				LiabilityAccount: int(*req.LiabilityAccountID),
+				PeopleID:         peopleID,
+				BuildingID:       req.BuildingID,
+				UnitID:           creditUnitID,
+				Amount:           float64(unappliedCents) / float64(money.MoneyScale),
+				AmountCents:      unappliedCents,
+				Description:      "Unapplied payment " + req.Reference,
+				Status:           1,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		payment := &store.ReceivedPayment{
+			TransactionID:  *transactionID,
+			Reference:      req.Reference,
+			Date:           req.Date,
+			PeopleID:       peopleID,
+			AccountID:      req.AccountID,
+			AmountCents:    amountCents,
+			UnappliedCents: unappliedCents,
+			CreditMemoID:   creditMemoID,
+			Memo:           req.Memo,
+			UserID:         1, // TODO: get user id from jwt
+			BuildingID:     req.BuildingID,
+			Status:         "1",
+		}
+		if err := s.receivedPaymentStore.Create(ctx, tx, payment); err != nil {
+			return err
+		}
+
+		allocationsDto := response.Allocations
+		response = dto.MapReceivedPaymentToDto(*payment)
+		response.Allocations = allocationsDto
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Helpers
+|--------------------------------------------------------------------------
+*/
+
+// allocateReceivedPayment splits the payment across the customer's open invoices. Manual allocations are
+// checked against each invoice balance; otherwise invoices are paid oldest due date first.
+func allocateReceivedPayment(req dto.ReceivePaymentPayload, openInvoices []store.CustomerOpenInvoice) (int64, []receiveAllocation, int64, error) {
+	amountCents, err := money.ParseUSDAmount(strconv.FormatFloat(req.Amount, 'f', -1, 64))
+	if err != nil {
+		return 0, nil, 0, fmt.Errorf("invalid amount: %v", err)
+	}
+	if amountCents <= 0 {
+		return 0, nil, 0, fmt.Errorf("amount must be greater than 0")
+	}
+
+	allocations := []receiveAllocation{}
+	remaining := amountCents
+
+	if len(req.Allocations) == 0 {
+		for _, invoice := range openInvoices {
+			if remaining == 0 {
+				break
+			}
+			applied := min(invoice.BalanceCents, remaining)
+			allocations = append(allocations, receiveAllocation{invoice: invoice, amountCents: applied})
+			remaining -= applied
+		}
+		return amountCents, allocations, remaining, nil
+	}
+
+	openByID := make(map[int64]store.CustomerOpenInvoice, len(openInvoices))
+	for _, invoice := range openInvoices {
+		openByID[invoice.ID] = invoice
+	}
+
+	seen := make(map[int64]bool)
+	for _, line := range req.Allocations {
+		invoice, ok := openByID[line.InvoiceID]
+		if !ok {
+			return 0, nil, 0, fmt.Errorf("invoice %d is not an open invoice of this customer", line.InvoiceID)
+		}
+		if seen[line.InvoiceID] {
+			return 0, nil, 0, fmt.Errorf("invoice %s is allocated more than once", invoice.InvoiceNo)
+		}
+		seen[line.InvoiceID] = true
+
+		applied, err := money.ParseUSDAmount(strconv.FormatFloat(line.Amount, 'f', -1, 64))
+		if err != nil {
+			return 0, nil, 0, fmt.Errorf("invalid amount for invoice %s: %v", invoice.InvoiceNo, err)
+		}
+		if applied > invoice.BalanceCents {
+			return 0, nil, 0, fmt.Errorf("amount for invoice %s exceeds its balance. Balance: %s, Requested: %s",
+				invoice.InvoiceNo, money.FormatMoneyFromCents(invoice.BalanceCents), money.FormatMoneyFromCents(applied))
+		}
+		if applied > remaining {
+			return 0, nil, 0, fmt.Errorf("allocations exceed the payment amount of %s", money.FormatMoneyFromCents(amountCents))
+		}
+
+		allocations = append(allocations, receiveAllocation{invoice: invoice, amountCents: applied})
+		remaining -= applied
+	}
+
+	return amountCents, allocations, remaining, nil
+}
+
+// creditUnit picks the unit for the unapplied credit: the requested unit, else the unit of the last paid invoice
+func (s *ReceivedPaymentService) creditUnit(unitID *int64, allocations []receiveAllocation) *int64 {
+	if unitID != nil {
+		return unitID
+	}
+	for i := len(allocations) - 1; i >= 0; i-- {
+		if allocations[i].invoice.UnitID != nil {
+			return allocations[i].invoice.UnitID
+		}
+	}
+	return nil
+}
+
+func newDebitSplit(transactionID, accountID, cents int64, unitID, peopleID *int64) store.Split {
+	amount := float64(cents) / float64(money.MoneyScale)
+	return store.Split{
+		TransactionID: transactionID,
+		AccountID:     accountID,
+		Debit:         &amount,
+		DebitCents:    &cents,
+		UnitID:        unitID,
+		PeopleID:      peopleID,
+		Status:        "1",
+	}
+}
+
+func newCreditSplit(transactionID, accountID, cents int64, unitID, peopleID *int64) store.Split {
+	amount := float64(cents) / float64(money.MoneyScale)
+	return store.Split{
+		TransactionID: transactionID,
+		AccountID:     accountID,
+		Credit:        &amount,
+		CreditCents:   &cents,
+		UnitID:        unitID,
+		PeopleID:      peopleID,
+		Status:        "1",
+	}
+}
@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+/*
+|--------------------------------------------------------------------------
+| Interfaces
+|--------------------------------------------------------------------------
+*/
+
+type ReconciliationStore interface {
+	GetAll(ctx context.Context, buildingID int64, accountID *int64) ([]store.Reconciliation, error)
+	GetByID(ctx context.Context, id int64) (*store.Reconciliation, error)
+	GetLatest(ctx context.Context, accountID int64) (*store.Reconciliation, error)
+	GetLatestFinished(ctx context.Context, accountID int64) (*store.Reconciliation, error)
+	GetItems(ctx context.Context, r *store.Reconciliation) ([]store.ReconciliationItem, error)
+	GetBookBalance(ctx context.Context, accountID int64, date string) (int64, error)
+	Create(ctx context.Context, r *store.Reconciliation) error
+	Update(ctx context.Context, r *store.Reconciliation) error
+	SetCleared(ctx context.Context, tx *sql.Tx, reconciliationID int64, splitIDs []int64, cleared bool) error
+	Finish(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64) error
+}
+
+/*
+|--------------------------------------------------------------------------
+| Service
+|--------------------------------------------------------------------------
+*/
+
+// ReconciliationService matches an asset account's splits against a bank statement.
+// Splits cleared by a finished reconciliation are locked against edits (see SplitStore.GetLockReason).
+type ReconciliationService struct {
+	db                  *sql.DB
+	reconciliationStore ReconciliationStore
+	accountStore        AccountStore
+}
+
+/*
+|--------------------------------------------------------------------------
+| Constructor
+|--------------------------------------------------------------------------
+*/
+
+func NewReconciliationService(
+	db *sql.DB,
+	reconciliationStore ReconciliationStore,
+	accountStore AccountStore,
+) *ReconciliationService {
+	return &ReconciliationService{
+		db:                  db,
+		reconciliationStore: reconciliationStore,
+		accountStore:        accountStore,
+	}
+}
+
+/*
+|--------------------------------------------------------------------------
+| Queries
+|--------------------------------------------------------------------------
+*/
+
+func (s *ReconciliationService) GetAll(ctx context.Context, buildingID int64, accountID *int64) ([]dto.ReconciliationDto, error) {
+	reconciliations, err := s.reconciliationStore.GetAll(ctx, buildingID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.MapReconciliationsToDto(reconciliations), nil
+}
+
+func (s *ReconciliationService) GetByID(ctx context.Context, id int64) (*dto.ReconciliationDetailsResponse, error) {
+	reconciliation, err := s.reconciliationStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.reconciliationStore.GetItems(ctx, reconciliation)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ReconciliationDetailsResponse{
+		Reconciliation: dto.MapReconciliationToDto(*reconciliation),
+		Items:          []dto.ReconciliationItemDto{},
+	}
+
+	for _, item := range items {
+		response.Items = append(response.Items, dto.MapReconciliationItemToDto(item))
+	}
+
+	deposits, withdrawals := clearedTotals(items)
+
+	cleared := reconciliation.BeginningBalanceCents + deposits - withdrawals
+	response.ClearedDeposits = money.FormatMoneyFromCents(deposits)
+	response.ClearedWithdrawals = money.FormatMoneyFromCents(withdrawals)
+	response.ClearedBalance = money.FormatMoneyFromCents(cleared)
+	response.Difference = money.FormatMoneyFromCents(reconciliation.EndingBalanceCents - cleared)
+
+	return response, nil
+}
+
+// GetReport lists cleared and uncleared items and ties the statement balance back to the ledger
+func (s *ReconciliationService) GetReport(ctx context.Context, id int64) (*dto.ReconciliationReportResponse, error) {
+	reconciliation, err := s.reconciliationStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.reconciliationStore.GetItems(ctx, reconciliation)
+	if err != nil {
+		return nil, err
+	}
+
+	bookCents, err := s.reconciliationStore.GetBookBalance(ctx, reconciliation.AccountID, reconciliation.StatementDate)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ReconciliationReportResponse{
+		Reconciliation: dto.MapReconciliationToDto(*reconciliation),
+		Cleared:        []dto.ReconciliationItemDto{},
+		Uncleared:      []dto.ReconciliationItemDto{},
+	}
+
+	clearedCents := reconciliation.BeginningBalanceCents
+	unclearedDeposits, unclearedWithdrawals := int64(0), int64(0)
+	for _, item := range items {
+		if item.Cleared {
+			response.Cleared = append(response.Cleared, dto.MapReconciliationItemToDto(item))
+			clearedCents += item.DebitCents - item.CreditCents
+			continue
+		}
+		response.Uncleared = append(response.Uncleared, dto.MapReconciliationItemToDto(item))
+		unclearedDeposits += item.DebitCents
+		unclearedWithdrawals += item.CreditCents
+	}
+
+	adjustedCents := reconciliation.EndingBalanceCents + unclearedDeposits - unclearedWithdrawals
+	response.ClearedBalance = money.FormatMoneyFromCents(clearedCents)
+	response.UnclearedDeposits = money.FormatMoneyFromCents(unclearedDeposits)
+	response.UnclearedWithdrawals = money.FormatMoneyFromCents(unclearedWithdrawals)
+	response.AdjustedBalance = money.FormatMoneyFromCents(adjustedCents)
+	response.BookBalance = money.FormatMoneyFromCents(bookCents)
+	response.Difference = money.FormatMoneyFromCents(bookCents - adjustedCents)
+
+	return response, nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Commands
+|--------------------------------------------------------------------------
+*/
+
+func (s *ReconciliationService) Create(ctx context.Context, req dto.CreateReconciliationRequest) (*dto.ReconciliationDto, error) {
+	account, err := s.accountStore.GetByID(ctx, req.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found")
+	}
+	if account.BuildingID != req.BuildingID {
+		return nil, fmt.Errorf("account does not belong to this building")
+	}
+
+	endingCents, err := money.ParseUSDAmount(strconv.FormatFloat(req.EndingBalance, 'f', -1, 64))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ending balance: %v", err)
+	}
+
+	// the previous statement's ending balance carries forward
+	beginningCents := int64(0)
+	latest, err := s.reconciliationStore.GetLatest(ctx, account.ID)
+	switch {
+	case err == nil && latest.Status == "open":
+		return nil, fmt.Errorf("account already has an open reconciliation for %s", latest.StatementDate)
+	case err == nil:
+		if req.StatementDate <= latest.StatementDate {
+			return nil, fmt.Errorf("statement date must be after the last reconciled statement of %s", latest.StatementDate)
+		}
+		beginningCents = latest.EndingBalanceCents
+	case err != store.ErrNotFound:
+		return nil, err
+	}
+
+	reconciliation := &store.Reconciliation{
+		AccountID:             account.ID,
+		AccountName:           account.AccountName,
+		BuildingID:            req.BuildingID,
+		StatementDate:         req.StatementDate,
+		BeginningBalanceCents: beginningCents,
+		EndingBalanceCents:    endingCents,
+		UserID:                1, // TODO: get user id from jwt
+	}
+	if err := s.reconciliationStore.Create(ctx, reconciliation); err != nil {
+		return nil, err
+	}
+
+	reconciliationDto := dto.MapReconciliationToDto(*reconciliation)
+	return &reconciliationDto, nil
+}
+
+func (s *ReconciliationService) Update(ctx context.Context, req dto.UpdateReconciliationRequest) error {
+	reconciliation, err := s.openReconciliation(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+
+	endingCents, err := money.ParseUSDAmount(strconv.FormatFloat(req.EndingBalance, 'f', -1, 64))
+	if err != nil {
+		return fmt.Errorf("invalid ending balance: %v", err)
+	}
+
+	// only one reconciliation per account is open, so the previous one is the latest finished
+	previous, err := s.reconciliationStore.GetLatestFinished(ctx, reconciliation.AccountID)
+	switch {
+	case err == nil:
+		if req.StatementDate <= previous.StatementDate {
+			return fmt.Errorf("statement date must be after the last reconciled statement of %s", previous.StatementDate)
+		}
+	case err != store.ErrNotFound:
+		return err
+	}
+
+	reconciliation.StatementDate = req.StatementDate
+	reconciliation.EndingBalanceCents = endingCents
+	return s.reconciliationStore.Update(ctx, reconciliation)
+}
+
+// SetCleared ticks or unticks splits; only splits listed for the reconciliation can be ticked
+func (s *ReconciliationService) SetCleared(ctx context.Context, id int64, req dto.ClearReconciliationItemsRequest) error {
+	reconciliation, err := s.openReconciliation(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if req.Cleared {
+		items, err := s.reconciliationStore.GetItems(ctx, reconciliation)
+		if err != nil {
+			return err
+		}
+		eligible := make(map[int64]bool, len(items))
+		for _, item := range items {
+			eligible[item.SplitID] = true
+		}
+		for _, splitID := range req.SplitIDs {
+			if !eligible[splitID] {
+				return fmt.Errorf("split %d is not an unreconciled item of this account up to %s", splitID, reconciliation.StatementDate)
+			}
+		}
+	}
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return s.reconciliationStore.SetCleared(ctx, tx, reconciliation.ID, req.SplitIDs, req.Cleared)
+	})
+}
+
+// Finish locks the cleared splits once the statement and cleared balances agree
+func (s *ReconciliationService) Finish(ctx context.Context, id int64) error {
+	reconciliation, err := s.openReconciliation(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	items, err := s.reconciliationStore.GetItems(ctx, reconciliation)
+	if err != nil {
+		return err
+	}
+
+	deposits, withdrawals := clearedTotals(items)
+	difference := reconciliation.EndingBalanceCents - (reconciliation.BeginningBalanceCents + deposits - withdrawals)
+	if difference != 0 {
+		return fmt.Errorf("reconciliation is out of balance by %s", money.FormatMoneyFromCents(difference))
+	}
+
+	return s.reconciliationStore.Finish(ctx, id)
+}
+
+func (s *ReconciliationService) Delete(ctx context.Context, id int64) error {
+	if _, err := s.openReconciliation(ctx, id); err != nil {
+		return err
+	}
+	return s.reconciliationStore.Delete(ctx, id)
+}
+
+/*
+|--------------------------------------------------------------------------
+| Helpers
+|--------------------------------------------------------------------------
+*/
+
+func (s *ReconciliationService) openReconciliation(ctx context.Context, id int64) (*store.Reconciliation, error) {
+	reconciliation, err := s.reconciliationStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if reconciliation.Status != "open" {
+		return nil, fmt.Errorf("reconciliation is already finished")
+	}
+	return reconciliation, nil
+}
+
+// clearedTotals sums the deposits and withdrawals ticked as cleared
+func clearedTotals(items []store.ReconciliationItem) (int64, int64) {
+	deposits, withdrawals := int64(0), int64(0)
+	for _, item := range items {
+		if item.Cleared {
+			deposits += item.DebitCents
+			withdrawals += item.CreditCents
+		}
+	}
+	return deposits, withdrawals
+}
@@ -0,0 +1,668 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+/*
+|--------------------------------------------------------------------------
+| Interfaces
+|--------------------------------------------------------------------------
+*/
+
+type BillTemplateStore interface {
+	GetAll(ctx context.Context, buildingID int64, activeOnly bool) ([]store.BillTemplate, error)
+	GetByID(ctx context.Context, id int64) (*store.BillTemplate, error)
+	Create(ctx context.Context, tx *sql.Tx, t *store.BillTemplate) error
+	Update(ctx context.Context, tx *sql.Tx, t *store.BillTemplate) error
+	Delete(ctx context.Context, id int64) error
+	GetLines(ctx context.Context, templateID int64) ([]store.BillTemplateLine, error)
+	CreateLine(ctx context.Context, tx *sql.Tx, l *store.BillTemplateLine) error
+	DeleteLines(ctx context.Context, tx *sql.Tx, templateID int64) error
+}
+
+type BillTemplateRunStore interface {
+	GetAll(ctx context.Context, buildingID int64, templateID *int64, status *string) ([]store.BillTemplateRun, error)
+	GetByID(ctx context.Context, id int64) (*store.BillTemplateRun, error)
+	GetByIDTx(ctx context.Context, tx *sql.Tx, id int64) (*store.BillTemplateRun, error)
+	Create(ctx context.Context, tx *sql.Tx, r *store.BillTemplateRun) error
+	Review(ctx context.Context, tx *sql.Tx, r *store.BillTemplateRun) error
+}
+
+/*
+|--------------------------------------------------------------------------
+| Service
+|--------------------------------------------------------------------------
+*/
+
+type RecurringBillService struct {
+	db                   *sql.DB
+	billTemplateStore    BillTemplateStore
+	billTemplateRunStore BillTemplateRunStore
+	accountStore         AccountStore
+	peopleStore          PeopleStore
+	billService          *BillService
+}
+
+/*
+|--------------------------------------------------------------------------
+| Constructor
+|--------------------------------------------------------------------------
+*/
+
+func NewRecurringBillService(
+	db *sql.DB,
+	billTemplateStore BillTemplateStore,
+	billTemplateRunStore BillTemplateRunStore,
+	accountStore AccountStore,
+	peopleStore PeopleStore,
+	billService *BillService,
+) *RecurringBillService {
+	return &RecurringBillService{
+		db:                   db,
+		billTemplateStore:    billTemplateStore,
+		billTemplateRunStore: billTemplateRunStore,
+		accountStore:         accountStore,
+		peopleStore:          peopleStore,
+		billService:          billService,
+	}
+}
+
+/*
+|--------------------------------------------------------------------------
+| Queries
+|--------------------------------------------------------------------------
+*/
+
+func (s *RecurringBillService) GetTemplates(ctx context.Context, buildingID int64) ([]dto.BillTemplateDto, error) {
+	templates, err := s.billTemplateStore.GetAll(ctx, buildingID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	dtoTemplates := []dto.BillTemplateDto{}
+	for _, t := range templates {
+		templateDto, err := s.mapTemplate(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		dtoTemplates = append(dtoTemplates, *templateDto)
+	}
+
+	return dtoTemplates, nil
+}
+
+func (s *RecurringBillService) GetTemplate(ctx context.Context, id int64) (*dto.BillTemplateDto, error) {
+	template, err := s.billTemplateStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.mapTemplate(ctx, *template)
+}
+
+func (s *RecurringBillService) GetRuns(ctx context.Context, buildingID int64, templateID *int64, status *string) ([]dto.BillTemplateRunDto, error) {
+	runs, err := s.billTemplateRunStore.GetAll(ctx, buildingID, templateID, status)
+	if err != nil {
+		return nil, err
+	}
+	return dto.MapBillTemplateRunsToDto(runs), nil
+}
+
+func (s *RecurringBillService) GetRun(ctx context.Context, id int64) (*dto.BillTemplateRunDto, error) {
+	run, err := s.billTemplateRunStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	runDto := dto.MapBillTemplateRunToDto(*run)
+	return &runDto, nil
+}
+
+// Preview lists the bills a run would generate without writing anything
+func (s *RecurringBillService) Preview(ctx context.Context, buildingID int64, req dto.RecurringBillRunRequest) (*dto.RecurringBillRunResponse, error) {
+	_, response, err := s.due(ctx, buildingID, req)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Commands
+|--------------------------------------------------------------------------
+*/
+
+func (s *RecurringBillService) CreateTemplate(ctx context.Context, req dto.CreateBillTemplateRequest) (*dto.BillTemplateDto, error) {
+	template, lines, err := s.buildTemplate(ctx, req.BillTemplatePayload)
+	if err != nil {
+		return nil, err
+	}
+
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.billTemplateStore.Create(ctx, tx, template); err != nil {
+			return err
+		}
+		return s.createLines(ctx, tx, template.ID, lines)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetTemplate(ctx, template.ID)
+}
+
+// UpdateTemplate changes future bills only; bills already generated keep their amounts
+func (s *RecurringBillService) UpdateTemplate(ctx context.Context, req dto.UpdateBillTemplateRequest) (*dto.BillTemplateDto, error) {
+	existing, err := s.billTemplateStore.GetByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.BuildingID != req.BuildingID {
+		return nil, fmt.Errorf("bill template does not belong to this building")
+	}
+
+	template, lines, err := s.buildTemplate(ctx, req.BillTemplatePayload)
+	if err != nil {
+		return nil, err
+	}
+	template.ID = req.ID
+
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.billTemplateStore.Update(ctx, tx, template); err != nil {
+			return err
+		}
+		if err := s.billTemplateStore.DeleteLines(ctx, tx, template.ID); err != nil {
+			return err
+		}
+		return s.createLines(ctx, tx, template.ID, lines)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetTemplate(ctx, template.ID)
+}
+
+func (s *RecurringBillService) DeleteTemplate(ctx context.Context, id int64) error {
+	template, err := s.billTemplateStore.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if template.LastPeriod != nil {
+		return fmt.Errorf("bill template has generated bills, deactivate it instead")
+	}
+
+	return s.billTemplateStore.Delete(ctx, id)
+}
+
+// Run generates every bill that is due up to the as of date. A template that has fallen
+// behind catches up one period at a time; each bill and its run record share a db transaction,
+// so a period is never billed twice.
+func (s *RecurringBillService) Run(ctx context.Context, buildingID int64, req dto.RecurringBillRunRequest) (*dto.RecurringBillRunResponse, error) {
+	templates, response, err := s.due(ctx, buildingID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := map[int64]bool{}
+
+	for i := range response.Bills {
+		line := &response.Bills[i]
+
+		// later periods wait until the failed one goes through
+		if failed[line.TemplateID] {
+			line.Error = "skipped, an earlier period failed"
+			continue
+		}
+
+		template := templates[line.TemplateID]
+
+		err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+			run := &store.BillTemplateRun{
+				TemplateID:  template.ID,
+				Period:      line.Period,
+				BillNo:      line.BillNo,
+				BillDate:    line.BillDate,
+				DueDate:     line.DueDate,
+				AmountCents: line.AmountCents,
+				Status:      "draft",
+				BuildingID:  buildingID,
+			}
+
+			if !line.Draft {
+				billID, err := s.billService.CreateTx(ctx, tx, s.billRequest(template, run))
+				if err != nil {
+					return err
+				}
+				run.BillID = billID
+				run.Status = "posted"
+			}
+
+			// unique (template_id, period) rejects a concurrent run for the same period
+			if err := s.billTemplateRunStore.Create(ctx, tx, run); err != nil {
+				return err
+			}
+
+			line.RunID = &run.ID
+			line.BillID = run.BillID
+			return nil
+		})
+		if err != nil {
+			line.Error = err.Error()
+			failed[line.TemplateID] = true
+		}
+	}
+
+	response.Posted = true
+	return response, nil
+}
+
+// ApproveRun posts the bill held by a draft run, using the template's current lines
+func (s *RecurringBillService) ApproveRun(ctx context.Context, buildingID, runID, reviewedBy int64) (*dto.BillTemplateRunDto, error) {
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		run, err := s.billTemplateRunStore.GetByIDTx(ctx, tx, runID)
+		if err != nil {
+			return err
+		}
+
+		if run.BuildingID != buildingID {
+			return fmt.Errorf("bill template run does not belong to this building")
+		}
+
+		if run.Status != "draft" {
+			return fmt.Errorf("bill template run is already %s", run.Status)
+		}
+
+		template, err := s.loadTemplate(ctx, run.TemplateID)
+		if err != nil {
+			return err
+		}
+
+		run.AmountCents = template.amountCents()
+		billID, err := s.billService.CreateTx(ctx, tx, s.billRequest(template, run))
+		if err != nil {
+			return err
+		}
+
+		run.BillID = billID
+		run.Status = "posted"
+		run.ReviewedBy = &reviewedBy
+		return s.billTemplateRunStore.Review(ctx, tx, run)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetRun(ctx, runID)
+}
+
+// RejectRun closes a draft run without posting; the period is not generated again
+func (s *RecurringBillService) RejectRun(ctx context.Context, buildingID, runID, reviewedBy int64) (*dto.BillTemplateRunDto, error) {
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		run, err := s.billTemplateRunStore.GetByIDTx(ctx, tx, runID)
+		if err != nil {
+			return err
+		}
+
+		if run.BuildingID != buildingID {
+			return fmt.Errorf("bill template run does not belong to this building")
+		}
+
+		if run.Status != "draft" {
+			return fmt.Errorf("bill template run is already %s", run.Status)
+		}
+
+		run.Status = "rejected"
+		run.ReviewedBy = &reviewedBy
+		return s.billTemplateRunStore.Review(ctx, tx, run)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetRun(ctx, runID)
+}
+
+/*
+|--------------------------------------------------------------------------
+| Helpers
+|--------------------------------------------------------------------------
+*/
+
+// billTemplate is a template loaded together with its expense lines
+type billTemplate struct {
+	store.BillTemplate
+	lines []store.BillTemplateLine
+}
+
+func (t *billTemplate) amountCents() int64 {
+	total := int64(0)
+	for _, l := range t.lines {
+		total += l.AmountCents
+	}
+	return total
+}
+
+func (s *RecurringBillService) loadTemplate(ctx context.Context, id int64) (*billTemplate, error) {
+	template, err := s.billTemplateStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("bill template not found: %v", err)
+	}
+
+	lines, err := s.billTemplateStore.GetLines(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &billTemplate{BillTemplate: *template, lines: lines}, nil
+}
+
+func (s *RecurringBillService) mapTemplate(ctx context.Context, t store.BillTemplate) (*dto.BillTemplateDto, error) {
+	lines, err := s.billTemplateStore.GetLines(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var nextRunDate *string
+	if t.Status == "1" {
+		dates, err := billTemplateSchedule(t, time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC), 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(dates) > 0 {
+			next := dates[0].Format("2006-01-02")
+			nextRunDate = &next
+		}
+	}
+
+	templateDto := dto.MapBillTemplateToDto(t, lines, nextRunDate)
+	return &templateDto, nil
+}
+
+func (s *RecurringBillService) buildTemplate(ctx context.Context, req dto.BillTemplatePayload) (*store.BillTemplate, []store.BillTemplateLine, error) {
+	people, err := s.peopleStore.GetByID(ctx, req.PeopleID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("vendor not found: %v", err)
+	}
+	if people.BuildingID != req.BuildingID {
+		return nil, nil, fmt.Errorf("vendor does not belong to this building")
+	}
+
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid start_date: %v", err)
+	}
+
+	if req.EndDate != nil && *req.EndDate == "" {
+		req.EndDate = nil
+	}
+	if req.EndDate != nil {
+		end, err := time.Parse("2006-01-02", *req.EndDate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid end_date: %v", err)
+		}
+		if end.Before(start) {
+			return nil, nil, fmt.Errorf("end date must be on or after the start date")
+		}
+	}
+
+	accountIDs := []int64{req.APAccountID}
+	for _, line := range req.ExpenseLines {
+		accountIDs = append(accountIDs, line.AccountID)
+	}
+	for _, accountID := range accountIDs {
+		account, err := s.accountStore.GetByID(ctx, accountID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("account %d not found", accountID)
+		}
+		if account.BuildingID != req.BuildingID {
+			return nil, nil, fmt.Errorf("account %s does not belong to this building", account.AccountName)
+		}
+	}
+
+	lines := []store.BillTemplateLine{}
+	for _, line := range req.ExpenseLines {
+		if line.AccountID == req.APAccountID {
+			return nil, nil, fmt.Errorf("expense lines cannot post to the A/P account")
+		}
+
+		amountCents, err := money.ParseUSDAmount(strconv.FormatFloat(line.Amount, 'f', -1, 64))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse amount: %v", err)
+		}
+		if amountCents <= 0 {
+			return nil, nil, fmt.Errorf("expense line amount must be greater than zero")
+		}
+
+		lines = append(lines, store.BillTemplateLine{
+			AccountID:   line.AccountID,
+			UnitID:      line.UnitID,
+			PeopleID:    line.PeopleID,
+			Description: line.Description,
+			AmountCents: amountCents,
+		})
+	}
+
+	intervalCount := req.IntervalCount
+	if intervalCount == 0 {
+		intervalCount = 1
+	}
+
+	asDraft := "0"
+	if req.AsDraft {
+		asDraft = "1"
+	}
+
+	status := req.Status
+	if status == "" {
+		status = "1"
+	}
+
+	return &store.BillTemplate{
+		BuildingID:    req.BuildingID,
+		Name:          req.Name,
+		PeopleID:      req.PeopleID,
+		APAccountID:   req.APAccountID,
+		UnitID:        req.UnitID,
+		Description:   req.Description,
+		Frequency:     req.Frequency,
+		IntervalCount: intervalCount,
+		DayOfMonth:    req.DayOfMonth,
+		DueDays:       req.DueDays,
+		StartDate:     req.StartDate,
+		EndDate:       req.EndDate,
+		AsDraft:       asDraft,
+		Status:        status,
+	}, lines, nil
+}
+
+func (s *RecurringBillService) createLines(ctx context.Context, tx *sql.Tx, templateID int64, lines []store.BillTemplateLine) error {
+	for _, line := range lines {
+		line.TemplateID = templateID
+		if err := s.billTemplateStore.CreateLine(ctx, tx, &line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// billRequest turns a template into the bill for one run
+func (s *RecurringBillService) billRequest(t *billTemplate, run *store.BillTemplateRun) dto.CreateBillRequest {
+	description := t.Description
+	if description == "" {
+		description = t.Name
+	}
+
+	expenseLines := []dto.BillExpenseLineInput{}
+	for _, l := range t.lines {
+		expenseLines = append(expenseLines, dto.BillExpenseLineInput{
+			AccountID:   l.AccountID,
+			UnitID:      l.UnitID,
+			PeopleID:    l.PeopleID,
+			Description: l.Description,
+			Amount:      float64(l.AmountCents) / float64(money.MoneyScale),
+		})
+	}
+
+	peopleID := t.PeopleID
+
+	return dto.CreateBillRequest{
+		BillPayloadDTO: dto.BillPayloadDTO{
+			BillNo:       run.BillNo,
+			BillDate:     run.BillDate,
+			DueDate:      run.DueDate,
+			APAccountID:  t.APAccountID,
+			UnitID:       t.UnitID,
+			PeopleID:     &peopleID,
+			BuildingID:   t.BuildingID,
+			Amount:       float64(t.amountCents()) / float64(money.MoneyScale),
+			Description:  fmt.Sprintf("%s (%s)", description, run.Period),
+			ExpenseLines: expenseLines,
+		},
+	}
+}
+
+// due lists the bills each active template owes up to the as of date
+func (s *RecurringBillService) due(ctx context.Context, buildingID int64, req dto.RecurringBillRunRequest) (map[int64]*billTemplate, *dto.RecurringBillRunResponse, error) {
+	asOf, err := time.Parse("2006-01-02", req.AsOfDate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid as_of_date: %v", err)
+	}
+
+	var templates []store.BillTemplate
+	if req.TemplateID != nil {
+		template, err := s.billTemplateStore.GetByID(ctx, *req.TemplateID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("bill template not found: %v", err)
+		}
+		if template.BuildingID != buildingID {
+			return nil, nil, fmt.Errorf("bill template does not belong to this building")
+		}
+		if template.Status != "1" {
+			return nil, nil, fmt.Errorf("bill template is inactive")
+		}
+		templates = append(templates, *template)
+	} else {
+		templates, err = s.billTemplateStore.GetAll(ctx, buildingID, true)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	loaded := map[int64]*billTemplate{}
+	lines := []dto.RecurringBillLine{}
+	total := int64(0)
+
+	for _, t := range templates {
+		dates, err := billTemplateSchedule(t, asOf, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(dates) == 0 {
+			continue
+		}
+
+		template, err := s.loadTemplate(ctx, t.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		loaded[t.ID] = template
+
+		amountCents := template.amountCents()
+
+		for _, date := range dates {
+			period := date.Format("2006-01")
+			lines = append(lines, dto.RecurringBillLine{
+				TemplateID:   t.ID,
+				TemplateName: t.Name,
+				PeopleID:     t.PeopleID,
+				PeopleName:   t.PeopleName,
+				Period:       period,
+				BillNo:       fmt.Sprintf("RB%d-%s", t.ID, period),
+				BillDate:     date.Format("2006-01-02"),
+				DueDate:      date.AddDate(0, 0, t.DueDays).Format("2006-01-02"),
+				Amount:       money.FormatMoneyFromCents(amountCents),
+				Draft:        t.AsDraft == "1",
+				AmountCents:  amountCents,
+			})
+			total += amountCents
+		}
+	}
+
+	return loaded, &dto.RecurringBillRunResponse{
+		AsOfDate: req.AsOfDate,
+		Posted:   false,
+		Bills:    lines,
+		Total:    money.FormatMoneyFromCents(total),
+	}, nil
+}
+
+// billTemplateSchedule returns the bill dates after the template's last run period, up to
+// through and the template's end date. limit caps the number of dates, 0 means no cap.
+func billTemplateSchedule(t store.BillTemplate, through time.Time, limit int) ([]time.Time, error) {
+	start, err := time.Parse("2006-01-02", t.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date on bill template %s: %v", t.Name, err)
+	}
+
+	if t.EndDate != nil {
+		end, err := time.Parse("2006-01-02", *t.EndDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end date on bill template %s: %v", t.Name, err)
+		}
+		if end.Before(through) {
+			through = end
+		}
+	}
+
+	months := 1
+	switch t.Frequency {
+	case "quarterly":
+		months = 3
+	case "yearly":
+		months = 12
+	}
+	step := months * t.IntervalCount
+	if step <= 0 {
+		return nil, fmt.Errorf("invalid interval on bill template %s", t.Name)
+	}
+
+	dates := []time.Time{}
+	for n := 0; ; n++ {
+		month := time.Date(start.Year(), start.Month()+time.Month(n*step), 1, 0, 0, 0, 0, time.UTC)
+
+		// the 31st becomes the last day of shorter months
+		day := t.DayOfMonth
+		if last := month.AddDate(0, 1, -1).Day(); day > last {
+			day = last
+		}
+		date := time.Date(month.Year(), month.Month(), day, 0, 0, 0, 0, time.UTC)
+
+		if date.After(through) {
+			break
+		}
+		if date.Before(start) {
+			continue
+		}
+		if t.LastPeriod != nil && date.Format("2006-01") <= *t.LastPeriod {
+			continue
+		}
+
+		dates = append(dates, date)
+		if limit > 0 && len(dates) == limit {
+			break
+		}
+	}
+
+	return dates, nil
+}
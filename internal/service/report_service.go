@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	money "github.com/mysecodgit/go_accounting/internal/accounting"
 	"github.com/mysecodgit/go_accounting/internal/dto"
@@ -21,11 +22,23 @@ type ReportStore interface {
 	GetTransactionDetails(ctx context.Context, buildingID int, startDate string, endDate string, accountID []int, unitID *int) ([]store.TransactionDetail, error)
 	GetAccountBalanceByAccountType(ctx context.Context, buildingID int, startDate string, endDate string, accountType string) ([]store.PLAccountRow, error)
 	GetAccountBalanceByAccountTypeAndUnit(ctx context.Context, buildingID int, startDate string, endDate string, accountType string) ([]store.PLAccountRowByUnit, error)
+	GetCustomerStatementLines(ctx context.Context, buildingID int, endDate string, peopleID *int) ([]store.CustomerStatementLine, error)
+	GetOpenInvoices(ctx context.Context, buildingID int, asOfDate string) ([]store.OpenInvoice, error)
+	GetOpenBills(ctx context.Context, buildingID int, asOfDate string) ([]store.OpenBill, error)
+	GetInventoryValuation(ctx context.Context, buildingID int, asOfDate string) ([]store.InventoryValuationRow, error)
+	GetInventoryMovementLines(ctx context.Context, buildingID int, startDate string, endDate string, itemID *int) ([]store.InventoryMovementLine, error)
+	GetTaxSummary(ctx context.Context, buildingID int, startDate string, endDate string) ([]store.TaxSummaryRow, error)
+	GetWithholdingRegister(ctx context.Context, buildingID int, startDate string, endDate string, peopleID *int) ([]store.WithholdingRow, error)
+	GetUnsettledDocumentSplits(ctx context.Context, buildingID int, asOfDate string) ([]store.UnsettledDocumentSplit, error)
+	GetAccountTotalsByRange(ctx context.Context, buildingID int, ranges []store.DateRange) ([]store.AccountRangeTotal, error)
 }
 
 type ReportService struct {
-	reportStore ReportStore
-	unitStore   UnitStoreInterface
+	reportStore   ReportStore
+	unitStore     UnitStoreInterface
+	peopleStore   PeopleStore
+	buildingStore BuildingStore
+	format        money.Formatter // how amounts are printed; the zero value prints plain amounts
 }
 
 type UnitStoreInterface interface {
@@ -35,15 +48,29 @@ type UnitStoreInterface interface {
 func NewReportService(
 	reportStore ReportStore,
 	unitStore UnitStoreInterface,
+	peopleStore PeopleStore,
+	buildingStore BuildingStore,
 ) *ReportService {
 	return &ReportService{
-		reportStore: reportStore,
-		unitStore:   unitStore,
+		reportStore:   reportStore,
+		unitStore:     unitStore,
+		peopleStore:   peopleStore,
+		buildingStore: buildingStore,
 	}
 }
 
-// GetBalanceSheet generates a balance sheet report
-func (s *ReportService) GetBalanceSheet(ctx context.Context, buildingID int, asOfDate string) (*dto.BalanceSheetResponse, error) {
+// WithFormat returns a copy of the service whose reports print amounts with format, e.g. a
+// display formatter for the building's currency and the user's locale
+func (s *ReportService) WithFormat(format money.Formatter) *ReportService {
+	formatted := *s
+	formatted.format = format
+	return &formatted
+}
+
+// GetBalanceSheet generates a balance sheet report, on cash basis leaving out what is still
+// unpaid on invoices and bills
+func (s *ReportService) GetBalanceSheet(ctx context.Context, buildingID int, asOfDate string, basis ReportBasis) (*dto.BalanceSheetResponse, error) {
+	statement := s.format.Accounting() // negatives in parentheses on the financial statements
 	fmt.Println("***************************** asOfDate", asOfDate)
 	fmt.Println("***************************** buildingID", buildingID)
 	accountBalances, err := s.reportStore.GetBalanceSheet(ctx, buildingID, asOfDate)
@@ -51,6 +78,14 @@ func (s *ReportService) GetBalanceSheet(ctx context.Context, buildingID int, asO
 		return nil, err
 	}
 	fmt.Println("***************************** accountBalances", accountBalances)
+	if basis == BasisCash {
+		adjustments, err := s.cashBasisAdjustments(ctx, buildingID, asOfDate)
+		if err != nil {
+			return nil, err
+		}
+		applyCashBasisToBalances(accountBalances, adjustments)
+	}
+
 	assets := []dto.AccountBalance{}
 	liabilities := []dto.AccountBalance{}
 	equity := []dto.AccountBalance{}
@@ -59,12 +94,11 @@ func (s *ReportService) GetBalanceSheet(ctx context.Context, buildingID int, asO
 
 	for _, account := range accountBalances {
 		accountType := account.AccountType
-		balance := money.FormatMoneyFromCents(account.Balance)
-
 		// Skip accounts with 0 balance
-		if balance == "0" || balance == "0.0" || balance == "0.00" {
+		if account.Balance == 0 {
 			continue
 		}
+		balance := statement.Cents(account.Balance)
 
 		accountBalance := dto.AccountBalance{
 			AccountID:     account.AccountID,
@@ -111,7 +145,7 @@ func (s *ReportService) GetBalanceSheet(ctx context.Context, buildingID int, asO
 			AccountNumber: "",
 			AccountName:   "Net Income",
 			AccountType:   "Net Income",
-			Balance:       money.FormatMoneyFromCents(netIncome),
+			Balance:       statement.Cents(netIncome),
 			BalanceCents:  netIncome,
 		})
 	}
@@ -140,17 +174,19 @@ func (s *ReportService) GetBalanceSheet(ctx context.Context, buildingID int, asO
 	return &dto.BalanceSheetResponse{
 		BuildingID:                buildingID,
 		AsOfDate:                  asOfDate,
-		Assets:                    dto.BalanceSheetSection{SectionName: "Assets", Accounts: assets, Total: money.FormatMoneyFromCents(totalAssets)},
-		Liabilities:               dto.BalanceSheetSection{SectionName: "Liabilities", Accounts: liabilities, Total: money.FormatMoneyFromCents(totalLiabilities)},
-		Equity:                    dto.BalanceSheetSection{SectionName: "Equity", Accounts: equity, Total: money.FormatMoneyFromCents(totalEquity)},
-		TotalAssets:               money.FormatMoneyFromCents(totalAssets),
-		TotalLiabilitiesAndEquity: money.FormatMoneyFromCents(totalLiabilitiesAndEquity),
+		Basis:                     string(basis),
+		Assets:                    dto.BalanceSheetSection{SectionName: "Assets", Accounts: assets, Total: statement.Cents(totalAssets)},
+		Liabilities:               dto.BalanceSheetSection{SectionName: "Liabilities", Accounts: liabilities, Total: statement.Cents(totalLiabilities)},
+		Equity:                    dto.BalanceSheetSection{SectionName: "Equity", Accounts: equity, Total: statement.Cents(totalEquity)},
+		TotalAssets:               statement.Cents(totalAssets),
+		TotalLiabilitiesAndEquity: statement.Cents(totalLiabilitiesAndEquity),
 		IsBalanced:                isBalanced,
 	}, nil
 
 }
 
 func (s *ReportService) GetTrialBalance(ctx context.Context, buildingID int, asOfDate string) (*dto.TrialBalanceResponse, error) {
+	statement := s.format.Accounting()
 	fmt.Println("***************************** asOfDate", asOfDate)
 	fmt.Println("***************************** buildingID", buildingID)
 	accounts, err := s.reportStore.GetTrialBalance(ctx, buildingID, asOfDate)
@@ -171,8 +207,8 @@ func (s *ReportService) GetTrialBalance(ctx context.Context, buildingID int, asO
 				AccountNumber: account.AccountNumber,
 				AccountName:   account.AccountName,
 				AccountType:   account.AccountType,
-				DebitBalance:  money.FormatMoneyFromCents(account.DebitBalance),
-				CreditBalance: money.FormatMoneyFromCents(account.CreditBalance),
+				DebitBalance:  statement.Cents(account.DebitBalance),
+				CreditBalance: statement.Cents(account.CreditBalance),
 			})
 
 			totalDebit += account.DebitBalance   // these are cents balance from store
@@ -189,8 +225,8 @@ func (s *ReportService) GetTrialBalance(ctx context.Context, buildingID int, asO
 		AccountNumber: 0,
 		AccountName:   "TOTAL",
 		AccountType:   "",
-		DebitBalance:  money.FormatMoneyFromCents(totalDebit),
-		CreditBalance: money.FormatMoneyFromCents(totalCredit),
+		DebitBalance:  statement.Cents(totalDebit),
+		CreditBalance: statement.Cents(totalCredit),
 		IsTotalRow:    true,
 	})
 
@@ -198,8 +234,8 @@ func (s *ReportService) GetTrialBalance(ctx context.Context, buildingID int, asO
 		BuildingID:  buildingID,
 		AsOfDate:    asOfDate,
 		Accounts:    trialBalanceAccounts,
-		TotalDebit:  money.FormatMoneyFromCents(totalDebit),
-		TotalCredit: money.FormatMoneyFromCents(totalCredit),
+		TotalDebit:  statement.Cents(totalDebit),
+		TotalCredit: statement.Cents(totalCredit),
 		IsBalanced:  isBalanced,
 	}, nil
 }
@@ -217,7 +253,7 @@ func (s *ReportService) GetCustomerBalanceSummary(ctx context.Context, buildingI
 		customersList = append(customersList, dto.CustomerBalance{
 			PeopleID:   customer.PeopleID,
 			PeopleName: customer.PeopleName,
-			Balance:    money.FormatMoneyFromCents(customer.Balance),
+			Balance:    s.format.Cents(customer.Balance),
 		})
 		totalBalance += customer.Balance
 	}
@@ -226,7 +262,7 @@ func (s *ReportService) GetCustomerBalanceSummary(ctx context.Context, buildingI
 		BuildingID:   buildingID,
 		AsOfDate:     asOfDate,
 		Customers:    customersList,
-		TotalBalance: money.FormatMoneyFromCents(totalBalance),
+		TotalBalance: s.format.Cents(totalBalance),
 	}, nil
 
 }
@@ -245,10 +281,10 @@ func (s *ReportService) GetCustomerBalanceDetail(ctx context.Context, buildingID
 		customerCredit := ""
 
 		if customerBalanceDetail.Debit != nil {
-			customerDebit = money.FormatMoneyFromCents(*customerBalanceDetail.Debit)
+			customerDebit = s.format.Cents(*customerBalanceDetail.Debit)
 		}
 		if customerBalanceDetail.Credit != nil {
-			customerCredit = money.FormatMoneyFromCents(*customerBalanceDetail.Credit)
+			customerCredit = s.format.Cents(*customerBalanceDetail.Credit)
 		}
 
 		peopleID := customerBalanceDetail.PeopleID
@@ -262,7 +298,7 @@ func (s *ReportService) GetCustomerBalanceDetail(ctx context.Context, buildingID
 		}
 
 		balance := runningBalances[peopleID]
-		balanceString := money.FormatMoneyFromCents(balance)
+		balanceString := s.format.Cents(balance)
 
 		customerBalanceDetailsList = append(customerBalanceDetailsList, CustomerBalanceDetail{
 			PeopleID:          customerBalanceDetail.PeopleID,
@@ -282,7 +318,7 @@ func (s *ReportService) GetCustomerBalanceDetail(ctx context.Context, buildingID
 		})
 	}
 
-	response := GroupTransactionsWithGrandTotals(customerBalanceDetailsList)
+	response := GroupTransactionsWithGrandTotals(customerBalanceDetailsList, s.format)
 
 	return &map[string]any{
 		"buildingID":          buildingID,
@@ -307,7 +343,7 @@ func (s *ReportService) GetVendorBalanceSummary(ctx context.Context, buildingID
 		vendorsList = append(vendorsList, dto.VendorBalance{
 			PeopleID:   vendor.PeopleID,
 			PeopleName: vendor.PeopleName,
-			Balance:    money.FormatMoneyFromCents(vendor.Balance),
+			Balance:    s.format.Cents(vendor.Balance),
 		})
 		totalBalance += vendor.Balance
 	}
@@ -316,7 +352,7 @@ func (s *ReportService) GetVendorBalanceSummary(ctx context.Context, buildingID
 		BuildingID:   buildingID,
 		AsOfDate:     asOfDate,
 		Vendors:      vendorsList,
-		TotalBalance: money.FormatMoneyFromCents(totalBalance),
+		TotalBalance: s.format.Cents(totalBalance),
 	}, nil
 }
 
@@ -333,10 +369,10 @@ func (s *ReportService) GetVendorBalanceDetail(ctx context.Context, buildingID i
 		vendorDebit := ""
 		vendorCredit := ""
 		if vendorBalanceDetail.Debit != nil {
-			vendorDebit = money.FormatMoneyFromCents(*vendorBalanceDetail.Debit)
+			vendorDebit = s.format.Cents(*vendorBalanceDetail.Debit)
 		}
 		if vendorBalanceDetail.Credit != nil {
-			vendorCredit = money.FormatMoneyFromCents(*vendorBalanceDetail.Credit)
+			vendorCredit = s.format.Cents(*vendorBalanceDetail.Credit)
 		}
 
 		// calculate balance
@@ -351,7 +387,7 @@ func (s *ReportService) GetVendorBalanceDetail(ctx context.Context, buildingID i
 		}
 
 		balance := runningBalances[peopleID]
-		balanceString := money.FormatMoneyFromCents(balance)
+		balanceString := s.format.Cents(balance)
 
 		vendorBalanceDetailsList = append(vendorBalanceDetailsList, VendorBalanceDetail{
 			PeopleID:          vendorBalanceDetail.PeopleID,
@@ -371,7 +407,7 @@ func (s *ReportService) GetVendorBalanceDetail(ctx context.Context, buildingID i
 		})
 	}
 
-	response := GroupTransactionsWithGrandTotalsForVendors(vendorBalanceDetailsList)
+	response := GroupTransactionsWithGrandTotalsForVendors(vendorBalanceDetailsList, s.format)
 
 	return &map[string]any{
 		"buildingID":          buildingID,
@@ -463,7 +499,7 @@ type CustomersResponse struct {
 	GrandTotalBalance string     `json:"grand_total_balance"`
 }
 
-func GroupTransactionsWithGrandTotals(rows []CustomerBalanceDetail) CustomersResponse {
+func GroupTransactionsWithGrandTotals(rows []CustomerBalanceDetail, format money.Formatter) CustomersResponse {
 	customersMap := make(map[int]*Customer)
 	accountMap := make(map[int]map[int]*Account)
 
@@ -526,17 +562,17 @@ func GroupTransactionsWithGrandTotals(rows []CustomerBalanceDetail) CustomersRes
 		account.TotalDebitCents += debit
 		account.TotalCreditCents += credit
 
-		account.TotalDebit = money.FormatMoneyFromCents(account.TotalDebitCents)
-		account.TotalCredit = money.FormatMoneyFromCents(account.TotalCreditCents)
-		account.TotalBalance = money.FormatMoneyFromCents(account.TotalDebitCents - account.TotalCreditCents)
+		account.TotalDebit = format.Cents(account.TotalDebitCents)
+		account.TotalCredit = format.Cents(account.TotalCreditCents)
+		account.TotalBalance = format.Cents(account.TotalDebitCents - account.TotalCreditCents)
 
 		// ---- Accumulate Customer totals ----
 		customer.TotalDebitCents += debit
 		customer.TotalCreditCents += credit
 
-		customer.TotalDebit = money.FormatMoneyFromCents(customer.TotalDebitCents)
-		customer.TotalCredit = money.FormatMoneyFromCents(customer.TotalCreditCents)
-		customer.TotalBalance = money.FormatMoneyFromCents(customer.TotalDebitCents - customer.TotalCreditCents)
+		customer.TotalDebit = format.Cents(customer.TotalDebitCents)
+		customer.TotalCredit = format.Cents(customer.TotalCreditCents)
+		customer.TotalBalance = format.Cents(customer.TotalDebitCents - customer.TotalCreditCents)
 
 		// ---- Accumulate Grand totals ----
 		grandDebitCents += debit
@@ -544,9 +580,9 @@ func GroupTransactionsWithGrandTotals(rows []CustomerBalanceDetail) CustomersRes
 	}
 
 	// ---- Format Grand totals ----
-	grandDebit := money.FormatMoneyFromCents(grandDebitCents)
-	grandCredit := money.FormatMoneyFromCents(grandCreditCents)
-	grandBalance := money.FormatMoneyFromCents(grandDebitCents - grandCreditCents)
+	grandDebit := format.Cents(grandDebitCents)
+	grandCredit := format.Cents(grandCreditCents)
+	grandBalance := format.Cents(grandDebitCents - grandCreditCents)
 
 	// ---- Map → Slice ----
 	var customers []Customer
@@ -581,7 +617,7 @@ type VendorsResponse struct {
 	GrandTotalBalance string   `json:"grand_total_balance"`
 }
 
-func GroupTransactionsWithGrandTotalsForVendors(rows []VendorBalanceDetail) VendorsResponse {
+func GroupTransactionsWithGrandTotalsForVendors(rows []VendorBalanceDetail, format money.Formatter) VendorsResponse {
 	vendorsMap := make(map[int]*Vendor)
 	accountMap := make(map[int]map[int]*Account)
 
@@ -650,17 +686,17 @@ func GroupTransactionsWithGrandTotalsForVendors(rows []VendorBalanceDetail) Vend
 		account.TotalDebitCents += debit
 		account.TotalCreditCents += credit
 
-		account.TotalDebit = money.FormatMoneyFromCents(account.TotalDebitCents)
-		account.TotalCredit = money.FormatMoneyFromCents(account.TotalCreditCents)
-		account.TotalBalance = money.FormatMoneyFromCents(account.TotalCreditCents - account.TotalDebitCents)
+		account.TotalDebit = format.Cents(account.TotalDebitCents)
+		account.TotalCredit = format.Cents(account.TotalCreditCents)
+		account.TotalBalance = format.Cents(account.TotalCreditCents - account.TotalDebitCents)
 
 		// ---- Accumulate Customer totals ----
 		vendor.TotalDebitCents += debit
 		vendor.TotalCreditCents += credit
 
-		vendor.TotalDebit = money.FormatMoneyFromCents(vendor.TotalDebitCents)
-		vendor.TotalCredit = money.FormatMoneyFromCents(vendor.TotalCreditCents)
-		vendor.TotalBalance = money.FormatMoneyFromCents(vendor.TotalCreditCents - vendor.TotalDebitCents)
+		vendor.TotalDebit = format.Cents(vendor.TotalDebitCents)
+		vendor.TotalCredit = format.Cents(vendor.TotalCreditCents)
+		vendor.TotalBalance = format.Cents(vendor.TotalCreditCents - vendor.TotalDebitCents)
 
 		// ---- Accumulate Grand totals ----
 		grandDebitCents += debit
@@ -668,9 +704,9 @@ func GroupTransactionsWithGrandTotalsForVendors(rows []VendorBalanceDetail) Vend
 	}
 
 	// ---- Format Grand totals ----
-	grandDebit = money.FormatMoneyFromCents(grandDebitCents)
-	grandCredit = money.FormatMoneyFromCents(grandCreditCents)
-	grandBalance = money.FormatMoneyFromCents(grandCreditCents - grandDebitCents)
+	grandDebit = format.Cents(grandDebitCents)
+	grandCredit = format.Cents(grandCreditCents)
+	grandBalance = format.Cents(grandCreditCents - grandDebitCents)
 
 	// ---- Map → Slice ----
 	var vendors []Vendor
@@ -701,11 +737,11 @@ func (s *ReportService) GetTransactionDetails(ctx context.Context, buildingID in
 		credit := ""
 
 		if transactionDetail.Debit != nil {
-			debit = money.FormatMoneyFromCents(*transactionDetail.Debit)
+			debit = s.format.Cents(*transactionDetail.Debit)
 		}
 
 		if transactionDetail.Credit != nil {
-			credit = money.FormatMoneyFromCents(*transactionDetail.Credit)
+			credit = s.format.Cents(*transactionDetail.Credit)
 		}
 
 		accountID := transactionDetail.AccountID
@@ -733,7 +769,7 @@ func (s *ReportService) GetTransactionDetails(ctx context.Context, buildingID in
 		}
 
 		balance := runningBalances[accountID]
-		balanceString := money.FormatMoneyFromCents(balance)
+		balanceString := s.format.Cents(balance)
 
 		transactionDetailsList = append(transactionDetailsList, TransactionDetail{
 			PeopleID:          transactionDetail.PeopleID,
@@ -755,7 +791,7 @@ func (s *ReportService) GetTransactionDetails(ctx context.Context, buildingID in
 		})
 	}
 
-	response := BuildLedgerResponse(transactionDetailsList, buildingID, startDate, endDate)
+	response := BuildLedgerResponse(transactionDetailsList, buildingID, startDate, endDate, s.format)
 	return &map[string]any{
 		"buildingID":         buildingID,
 		"startDate":          startDate,
@@ -813,6 +849,7 @@ func BuildLedgerResponse(
 	rows []TransactionDetail,
 	buildingID int,
 	startDate, endDate string,
+	format money.Formatter,
 ) LedgerResponse {
 
 	accountMap := make(map[int]*AccountLedger)
@@ -870,8 +907,8 @@ func BuildLedgerResponse(
 		account.TotalDebitCents += debit
 		account.TotalCreditCents += credit
 
-		account.TotalDebit = money.FormatMoneyFromCents(account.TotalDebitCents)
-		account.TotalCredit = money.FormatMoneyFromCents(account.TotalCreditCents)
+		account.TotalDebit = format.Cents(account.TotalDebitCents)
+		account.TotalCredit = format.Cents(account.TotalCreditCents)
 
 		// TODO : not necessary anymore remove it
 		// balance based on account type
@@ -883,14 +920,14 @@ func BuildLedgerResponse(
 		// 	balanceCents = account.TotalCreditCents - account.TotalDebitCents
 		// }
 
-		account.TotalBalance = money.FormatMoneyFromCents(account.TotalDebitCents - account.TotalCreditCents)
+		account.TotalBalance = format.Cents(account.TotalDebitCents - account.TotalCreditCents)
 
 		grandDebitCents += debit
 		grandCreditCents += credit
 	}
 
-	grandDebit = money.FormatMoneyFromCents(grandDebitCents)
-	grandCredit = money.FormatMoneyFromCents(grandCreditCents)
+	grandDebit = format.Cents(grandDebitCents)
+	grandCredit = format.Cents(grandCreditCents)
 
 	// ---- Add TOTAL row per account ----
 	var finalAccounts []*AccountLedger
@@ -931,7 +968,10 @@ type PLAccountRow struct {
 	Balance       string `json:"balance"`
 }
 
-func (s *ReportService) GetProfitAndLossStandard(ctx context.Context, buildingID int, startDate string, endDate string) (*PLReport, error) {
+// GetProfitAndLossStandard reports income and expenses over a period; on cash basis an invoice
+// or bill counts as it is paid rather than when it posts
+func (s *ReportService) GetProfitAndLossStandard(ctx context.Context, buildingID int, startDate string, endDate string, basis ReportBasis) (*PLReport, error) {
+	statement := s.format.Accounting()
 	incomeAccounts, err := s.reportStore.GetAccountBalanceByAccountType(ctx, buildingID, startDate, endDate, "Income")
 	if err != nil {
 		return nil, err
@@ -942,6 +982,15 @@ func (s *ReportService) GetProfitAndLossStandard(ctx context.Context, buildingID
 		return nil, err
 	}
 
+	if basis == BasisCash {
+		adjustments, err := s.cashBasisPeriodAdjustments(ctx, buildingID, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		incomeAccounts = applyCashBasisToPLRows(incomeAccounts, adjustments, "Income")
+		expenseAccounts = applyCashBasisToPLRows(expenseAccounts, adjustments, "Expense")
+	}
+
 	totalExpense := int64(0)
 	for _, expense := range expenseAccounts {
 		totalExpense += expense.Balance
@@ -960,9 +1009,9 @@ func (s *ReportService) GetProfitAndLossStandard(ctx context.Context, buildingID
 			AccountNumber: expense.AccountNumber,
 			AccountName:   expense.AccountName,
 			AccountType:   expense.AccountType,
-			TotalDebit:    money.FormatMoneyFromCents(expense.TotalDebit),
-			TotalCredit:   money.FormatMoneyFromCents(expense.TotalCredit),
-			Balance:       money.FormatMoneyFromCents(expense.Balance),
+			TotalDebit:    statement.Cents(expense.TotalDebit),
+			TotalCredit:   statement.Cents(expense.TotalCredit),
+			Balance:       statement.Cents(expense.Balance),
 		}
 		expenseAccountsList = append(expenseAccountsList, expenseAccount)
 	}
@@ -971,9 +1020,9 @@ func (s *ReportService) GetProfitAndLossStandard(ctx context.Context, buildingID
 			AccountNumber: income.AccountNumber,
 			AccountName:   income.AccountName,
 			AccountType:   income.AccountType,
-			TotalDebit:    money.FormatMoneyFromCents(income.TotalDebit),
-			TotalCredit:   money.FormatMoneyFromCents(income.TotalCredit),
-			Balance:       money.FormatMoneyFromCents(income.Balance),
+			TotalDebit:    statement.Cents(income.TotalDebit),
+			TotalCredit:   statement.Cents(income.TotalCredit),
+			Balance:       statement.Cents(income.Balance),
 		}
 		incomeAccountsList = append(incomeAccountsList, incomeAccount)
 	}
@@ -982,23 +1031,25 @@ func (s *ReportService) GetProfitAndLossStandard(ctx context.Context, buildingID
 		BuildingID: buildingID,
 		StartDate:  startDate,
 		EndDate:    endDate,
+		Basis:      string(basis),
 		Expenses: PLSection{
 			Accounts:    expenseAccountsList,
 			SectionName: "Expense",
-			Total:       money.FormatMoneyFromCents(totalExpense),
+			Total:       statement.Cents(totalExpense),
 		},
 		Income: PLSection{
 			Accounts:    incomeAccountsList,
 			SectionName: "Income",
-			Total:       money.FormatMoneyFromCents(totalIncome),
+			Total:       statement.Cents(totalIncome),
 		},
-		NetProfitLoss: money.FormatMoneyFromCents(totalIncome - totalExpense),
+		NetProfitLoss: statement.Cents(totalIncome - totalExpense),
 	}, nil
 
 }
 
 // GetProfitAndLossByUnit generates a profit and loss report grouped by unit
 func (s *ReportService) GetProfitAndLossByUnit(ctx context.Context, buildingID int, startDate string, endDate string) (*dto.ProfitAndLossByUnitResponse, error) {
+	statement := s.format.Accounting()
 	// Get all units for the building
 	units, err := s.unitStore.GetAll(ctx, int64(buildingID))
 	if err != nil {
@@ -1123,7 +1174,7 @@ func (s *ReportService) GetProfitAndLossByUnit(ctx context.Context, buildingID i
 
 		balancesStr := make(map[int]string)
 		for unitID, balance := range balances {
-			balancesStr[unitID] = money.FormatMoneyFromCents(balance)
+			balancesStr[unitID] = statement.Cents(balance)
 		}
 		incomeAccounts = append(incomeAccounts, dto.AccountRow{
 			AccountID:     accountID,
@@ -1132,7 +1183,7 @@ func (s *ReportService) GetProfitAndLossByUnit(ctx context.Context, buildingID i
 			AccountType:   "income",
 			Balances:      balancesStr,
 			BalancesCents: balances,
-			Total:         money.FormatMoneyFromCents(total),
+			Total:         statement.Cents(total),
 		})
 	}
 
@@ -1147,7 +1198,7 @@ func (s *ReportService) GetProfitAndLossByUnit(ctx context.Context, buildingID i
 
 		balancesStr := make(map[int]string)
 		for unitID, balance := range balances {
-			balancesStr[unitID] = money.FormatMoneyFromCents(balance)
+			balancesStr[unitID] = statement.Cents(balance)
 		}
 
 		expenseAccounts = append(expenseAccounts, dto.AccountRow{
@@ -1157,7 +1208,7 @@ func (s *ReportService) GetProfitAndLossByUnit(ctx context.Context, buildingID i
 			AccountType:   "expense",
 			Balances:      balancesStr,
 			BalancesCents: balances,
-			Total:         money.FormatMoneyFromCents(total),
+			Total:         statement.Cents(total),
 		})
 	}
 
@@ -1201,47 +1252,18 @@ func (s *ReportService) GetProfitAndLossByUnit(ctx context.Context, buildingID i
 
 	grandTotalNetProfitLoss := grandTotalIncome - grandTotalExpenses
 
-	// Round values to 2 decimals
-	// round2 := func(v float64) float64 {
-	// 	return math.Round(v*100) / 100
-	// }
-
-	for i := range incomeAccounts {
-		for unitID := range incomeAccounts[i].Balances {
-			incomeAccounts[i].Balances[unitID] = incomeAccounts[i].Balances[unitID]
-		}
-		incomeAccounts[i].Total = incomeAccounts[i].Total
-	}
-
-	for i := range expenseAccounts {
-		for unitID := range expenseAccounts[i].Balances {
-			expenseAccounts[i].Balances[unitID] = expenseAccounts[i].Balances[unitID]
-		}
-		expenseAccounts[i].Total = expenseAccounts[i].Total
-	}
-
-	for unitID := range totalIncome {
-		totalIncome[unitID] = totalIncome[unitID]
-	}
-	for unitID := range totalExpenses {
-		totalExpenses[unitID] = totalExpenses[unitID]
-	}
-	for unitID := range netProfitLoss {
-		netProfitLoss[unitID] = netProfitLoss[unitID]
-	}
-
 	totalIncomeStr := make(map[int]string)
 
 	for k, v := range totalIncome {
-		totalIncomeStr[k] = money.FormatMoneyFromCents(v)
+		totalIncomeStr[k] = statement.Cents(v)
 	}
 	totalExpensesStr := make(map[int]string)
 	for k, v := range totalExpenses {
-		totalExpensesStr[k] = money.FormatMoneyFromCents(v)
+		totalExpensesStr[k] = statement.Cents(v)
 	}
 	netProfitLossStr := make(map[int]string)
 	for k, v := range netProfitLoss {
-		netProfitLossStr[k] = money.FormatMoneyFromCents(v)
+		netProfitLossStr[k] = statement.Cents(v)
 	}
 
 	return &dto.ProfitAndLossByUnitResponse{
@@ -1254,9 +1276,9 @@ func (s *ReportService) GetProfitAndLossByUnit(ctx context.Context, buildingID i
 		TotalIncome:             totalIncomeStr,
 		TotalExpenses:           totalExpensesStr,
 		NetProfitLoss:           netProfitLossStr,
-		GrandTotalIncome:        money.FormatMoneyFromCents(grandTotalIncome),
-		GrandTotalExpenses:      money.FormatMoneyFromCents(grandTotalExpenses),
-		GrandTotalNetProfitLoss: money.FormatMoneyFromCents(grandTotalNetProfitLoss),
+		GrandTotalIncome:        statement.Cents(grandTotalIncome),
+		GrandTotalExpenses:      statement.Cents(grandTotalExpenses),
+		GrandTotalNetProfitLoss: statement.Cents(grandTotalNetProfitLoss),
 	}, nil
 }
 
@@ -1270,7 +1292,704 @@ type PLReport struct {
 	BuildingID    int       `json:"building_id"`
 	StartDate     string    `json:"start_date"`
 	EndDate       string    `json:"end_date"`
+	Basis         string    `json:"basis"` // accrual or cash
 	Expenses      PLSection `json:"expenses"`
 	Income        PLSection `json:"income"`
 	NetProfitLoss string    `json:"net_profit_loss"`
 }
+
+// GetCustomerStatement builds a statement of account for one customer over a date range
+func (s *ReportService) GetCustomerStatement(ctx context.Context, buildingID int, peopleID int, startDate string, endDate string) (*dto.CustomerStatement, error) {
+	people, err := s.peopleStore.GetByID(ctx, int64(peopleID))
+	if err != nil {
+		return nil, fmt.Errorf("customer not found: %v", err)
+	}
+	if people.BuildingID != int64(buildingID) {
+		return nil, fmt.Errorf("customer does not belong to this building")
+	}
+
+	lines, err := s.reportStore.GetCustomerStatementLines(ctx, buildingID, endDate, &peopleID)
+	if err != nil {
+		return nil, err
+	}
+
+	statement, err := buildCustomerStatement(buildingID, peopleID, people.Name, lines, startDate, endDate, s.format)
+	if err != nil {
+		return nil, err
+	}
+
+	return statement, nil
+}
+
+// GetCustomerStatements builds statements for every customer with a non-zero closing balance
+func (s *ReportService) GetCustomerStatements(ctx context.Context, buildingID int, startDate string, endDate string) (*dto.CustomerStatementsResponse, error) {
+	lines, err := s.reportStore.GetCustomerStatementLines(ctx, buildingID, endDate, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// lines are ordered by people_id, so group consecutive rows
+	statements := []dto.CustomerStatement{}
+	totalBalance := int64(0)
+
+	for start := 0; start < len(lines); {
+		end := start
+		for end < len(lines) && lines[end].PeopleID == lines[start].PeopleID {
+			end++
+		}
+
+		statement, err := buildCustomerStatement(buildingID, lines[start].PeopleID, lines[start].PeopleName, lines[start:end], startDate, endDate, s.format)
+		if err != nil {
+			return nil, err
+		}
+
+		if statement.ClosingBalanceCents != 0 {
+			statements = append(statements, *statement)
+			totalBalance += statement.ClosingBalanceCents
+		}
+
+		start = end
+	}
+
+	return &dto.CustomerStatementsResponse{
+		BuildingID:   buildingID,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		Statements:   statements,
+		TotalBalance: s.format.Cents(totalBalance),
+	}, nil
+}
+
+func buildCustomerStatement(buildingID int, peopleID int, peopleName string, lines []store.CustomerStatementLine, startDate string, endDate string, format money.Formatter) (*dto.CustomerStatement, error) {
+	asOf, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date: %v", err)
+	}
+
+	openingBalance := int64(0)
+	runningBalance := int64(0)
+	totalCharges := int64(0)
+	totalCredits := int64(0)
+	statementLines := []dto.CustomerStatementLine{}
+
+	// remaining balance per invoice as of the end date, used for aging
+	invoiceBalances := make(map[int]int64)
+	invoiceDueDates := make(map[int]string)
+	unappliedCredits := int64(0)
+
+	for _, line := range lines {
+		if line.InvoiceID == 0 {
+			unappliedCredits += line.AmountCents
+		} else {
+			invoiceBalances[line.InvoiceID] += line.AmountCents
+			invoiceDueDates[line.InvoiceID] = line.DueDate
+		}
+
+		if line.Date < startDate {
+			openingBalance += line.AmountCents
+			runningBalance += line.AmountCents
+			continue
+		}
+
+		runningBalance += line.AmountCents
+
+		charge := ""
+		credit := ""
+		if line.AmountCents >= 0 {
+			charge = format.Cents(line.AmountCents)
+			totalCharges += line.AmountCents
+		} else {
+			credit = format.Cents(-line.AmountCents)
+			totalCredits += -line.AmountCents
+		}
+
+		statementLines = append(statementLines, dto.CustomerStatementLine{
+			Date:        line.Date,
+			Type:        line.Type,
+			Reference:   line.Reference,
+			Description: line.Description,
+			InvoiceNo:   line.InvoiceNo,
+			Charge:      charge,
+			Credit:      credit,
+			Balance:     format.Cents(runningBalance),
+		})
+	}
+
+	// age open invoices by days past due, statements always use the standard buckets
+	aging, _ := newAgingSchedule(DefaultAgingBoundaries)
+	buckets := make([]int64, aging.Size())
+	agingTotal := int64(0)
+
+	// unapplied credits are not due, they reduce the current bucket
+	buckets[0] += unappliedCredits
+	agingTotal += unappliedCredits
+	for invoiceID, balance := range invoiceBalances {
+		if balance == 0 {
+			continue
+		}
+
+		buckets[aging.Bucket(daysPastDue(invoiceDueDates[invoiceID], asOf))] += balance
+		agingTotal += balance
+	}
+
+	return &dto.CustomerStatement{
+		BuildingID:          buildingID,
+		PeopleID:            peopleID,
+		PeopleName:          peopleName,
+		StartDate:           startDate,
+		EndDate:             endDate,
+		OpeningBalance:      format.Cents(openingBalance),
+		Lines:               statementLines,
+		TotalCharges:        format.Cents(totalCharges),
+		TotalCredits:        format.Cents(totalCredits),
+		ClosingBalance:      format.Cents(runningBalance),
+		ClosingBalanceCents: runningBalance,
+		Aging: dto.StatementAging{
+			Current:    format.Cents(buckets[0]),
+			Days1To30:  format.Cents(buckets[1]),
+			Days31To60: format.Cents(buckets[2]),
+			Days61To90: format.Cents(buckets[3]),
+			Over90:     format.Cents(buckets[4]),
+			Total:      format.Cents(agingTotal),
+		},
+	}, nil
+}
+
+// GetARAging buckets each open invoice's remaining balance by days past its due date
+func (s *ReportService) GetARAging(ctx context.Context, buildingID int, asOfDate string, boundaries []int) (*dto.ARAgingResponse, error) {
+	asOf, err := time.Parse("2006-01-02", asOfDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid as of date: %v", err)
+	}
+
+	aging, err := newAgingSchedule(boundaries)
+	if err != nil {
+		return nil, err
+	}
+	labels := aging.Labels()
+
+	invoices, err := s.reportStore.GetOpenInvoices(ctx, buildingID, asOfDate)
+	if err != nil {
+		return nil, err
+	}
+
+	tenants := newAgingRows(aging.Size())
+	units := newAgingRows(aging.Size())
+	totals := make([]int64, aging.Size())
+	grandTotal := int64(0)
+
+	for _, invoice := range invoices {
+		days := daysPastDue(invoice.DueDate, asOf)
+		bucket := aging.Bucket(days)
+
+		unitID := 0
+		unitName := "No Unit"
+		if invoice.UnitID != nil {
+			unitID = *invoice.UnitID
+		}
+		if invoice.UnitName != nil {
+			unitName = *invoice.UnitName
+		}
+
+		tenants.add(invoice.PeopleID, invoice.PeopleName, bucket, invoice.BalanceCents, &dto.AgingDocument{
+			ID:          invoice.InvoiceID,
+			Number:      invoice.InvoiceNo,
+			Date:        invoice.SalesDate,
+			DueDate:     invoice.DueDate,
+			DaysPastDue: days,
+			UnitName:    unitName,
+			Amount:      s.format.Cents(invoice.AmountCents),
+			Balance:     s.format.Cents(invoice.BalanceCents),
+			Bucket:      labels[bucket],
+		})
+		units.add(unitID, unitName, bucket, invoice.BalanceCents, nil)
+
+		totals[bucket] += invoice.BalanceCents
+		grandTotal += invoice.BalanceCents
+	}
+
+	totalsStr := make([]string, len(totals))
+	for i, t := range totals {
+		totalsStr[i] = s.format.Cents(t)
+	}
+
+	return &dto.ARAgingResponse{
+		BuildingID: buildingID,
+		AsOfDate:   asOfDate,
+		Buckets:    labels,
+		Tenants:    tenants.rows(s.format),
+		Units:      units.rows(s.format),
+		Totals:     totalsStr,
+		GrandTotal: s.format.Cents(grandTotal),
+	}, nil
+}
+
+// GetAPAging buckets each open bill's remaining balance by days past its due date
+func (s *ReportService) GetAPAging(ctx context.Context, buildingID int, asOfDate string, boundaries []int) (*dto.APAgingResponse, error) {
+	asOf, err := time.Parse("2006-01-02", asOfDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid as of date: %v", err)
+	}
+
+	aging, err := newAgingSchedule(boundaries)
+	if err != nil {
+		return nil, err
+	}
+	labels := aging.Labels()
+
+	bills, err := s.reportStore.GetOpenBills(ctx, buildingID, asOfDate)
+	if err != nil {
+		return nil, err
+	}
+
+	vendors := newAgingRows(aging.Size())
+	totals := make([]int64, aging.Size())
+	grandTotal := int64(0)
+
+	for _, bill := range bills {
+		days := daysPastDue(bill.DueDate, asOf)
+		bucket := aging.Bucket(days)
+		vendorID, vendorName := billVendor(bill)
+
+		vendors.add(vendorID, vendorName, bucket, bill.BalanceCents, &dto.AgingDocument{
+			ID:          bill.BillID,
+			Number:      bill.BillNo,
+			Date:        bill.BillDate,
+			DueDate:     bill.DueDate,
+			DaysPastDue: days,
+			Amount:      s.format.Cents(bill.AmountCents),
+			Balance:     s.format.Cents(bill.BalanceCents),
+			Bucket:      labels[bucket],
+		})
+
+		totals[bucket] += bill.BalanceCents
+		grandTotal += bill.BalanceCents
+	}
+
+	totalsStr := make([]string, len(totals))
+	for i, t := range totals {
+		totalsStr[i] = s.format.Cents(t)
+	}
+
+	return &dto.APAgingResponse{
+		BuildingID: buildingID,
+		AsOfDate:   asOfDate,
+		Buckets:    labels,
+		Vendors:    vendors.rows(s.format),
+		Totals:     totalsStr,
+		GrandTotal: s.format.Cents(grandTotal),
+	}, nil
+}
+
+// GetBillsDue lists open bills due within the next N days (overdue bills included),
+// grouped by vendor and by the account each bill is planned to be paid from
+func (s *ReportService) GetBillsDue(ctx context.Context, buildingID int, asOfDate string, days int) (*dto.BillsDueResponse, error) {
+	asOf, err := time.Parse("2006-01-02", asOfDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid as of date: %v", err)
+	}
+
+	if days < 0 {
+		return nil, fmt.Errorf("days must be 0 or more")
+	}
+
+	throughDate := asOf.AddDate(0, 0, days).Format("2006-01-02")
+
+	bills, err := s.reportStore.GetOpenBills(ctx, buildingID, asOfDate)
+	if err != nil {
+		return nil, err
+	}
+
+	vendors := []dto.BillsDueVendor{}
+	vendorIndex := make(map[int]int)
+	vendorTotals := make(map[int]int64)
+	groupTotals := make(map[int]map[int]int64)
+
+	accounts := []dto.BillsDueAccountTotal{}
+	accountIndex := make(map[int]int)
+	accountTotals := make(map[int]int64)
+
+	total := int64(0)
+
+	for _, bill := range bills {
+		if bill.DueDate > throughDate || bill.BalanceCents <= 0 {
+			continue
+		}
+
+		vendorID, vendorName := billVendor(bill)
+
+		accountID := 0
+		accountName := "Unassigned"
+		if bill.PaymentAccountID != nil {
+			accountID = *bill.PaymentAccountID
+		}
+		if bill.PaymentAccountName != nil {
+			accountName = *bill.PaymentAccountName
+		}
+
+		vi, ok := vendorIndex[vendorID]
+		if !ok {
+			vi = len(vendors)
+			vendorIndex[vendorID] = vi
+			groupTotals[vendorID] = make(map[int]int64)
+			vendors = append(vendors, dto.BillsDueVendor{PeopleID: vendorID, Name: vendorName})
+		}
+
+		gi := -1
+		for i, group := range vendors[vi].Groups {
+			if (group.PaymentAccountID == nil && bill.PaymentAccountID == nil) ||
+				(group.PaymentAccountID != nil && bill.PaymentAccountID != nil && *group.PaymentAccountID == *bill.PaymentAccountID) {
+				gi = i
+				break
+			}
+		}
+		if gi == -1 {
+			gi = len(vendors[vi].Groups)
+			vendors[vi].Groups = append(vendors[vi].Groups, dto.BillsDueGroup{
+				PaymentAccountID:   bill.PaymentAccountID,
+				PaymentAccountName: accountName,
+			})
+		}
+
+		daysUntilDue := -daysPastDue(bill.DueDate, asOf)
+		vendors[vi].Groups[gi].Bills = append(vendors[vi].Groups[gi].Bills, dto.BillDueLine{
+			BillID:       bill.BillID,
+			BillNo:       bill.BillNo,
+			BillDate:     bill.BillDate,
+			DueDate:      bill.DueDate,
+			DaysUntilDue: daysUntilDue,
+			Overdue:      daysUntilDue < 0,
+			Amount:       s.format.Cents(bill.AmountCents),
+			Balance:      s.format.Cents(bill.BalanceCents),
+		})
+
+		groupTotals[vendorID][accountID] += bill.BalanceCents
+		vendors[vi].Groups[gi].Total = s.format.Cents(groupTotals[vendorID][accountID])
+		vendorTotals[vendorID] += bill.BalanceCents
+		vendors[vi].Total = s.format.Cents(vendorTotals[vendorID])
+
+		ai, ok := accountIndex[accountID]
+		if !ok {
+			ai = len(accounts)
+			accountIndex[accountID] = ai
+			accounts = append(accounts, dto.BillsDueAccountTotal{
+				PaymentAccountID:   bill.PaymentAccountID,
+				PaymentAccountName: accountName,
+			})
+		}
+		accountTotals[accountID] += bill.BalanceCents
+		accounts[ai].Total = s.format.Cents(accountTotals[accountID])
+
+		total += bill.BalanceCents
+	}
+
+	return &dto.BillsDueResponse{
+		BuildingID:      buildingID,
+		AsOfDate:        asOfDate,
+		ThroughDate:     throughDate,
+		Days:            days,
+		Vendors:         vendors,
+		PaymentAccounts: accounts,
+		Total:           s.format.Cents(total),
+	}, nil
+}
+
+func billVendor(bill store.OpenBill) (int, string) {
+	vendorID := 0
+	vendorName := "No Vendor"
+	if bill.PeopleID != nil {
+		vendorID = *bill.PeopleID
+	}
+	if bill.PeopleName != nil {
+		vendorName = *bill.PeopleName
+	}
+	return vendorID, vendorName
+}
+
+// GetInventoryValuation lists each inventory item's qty, average cost and value as of a date
+func (s *ReportService) GetInventoryValuation(ctx context.Context, buildingID int, asOfDate string) (*dto.InventoryValuationResponse, error) {
+	rows, err := s.reportStore.GetInventoryValuation(ctx, buildingID, asOfDate)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []dto.InventoryValuationLine{}
+	total := int64(0)
+	for _, row := range rows {
+		if row.QtyScaled == 0 && row.ValueCents == 0 {
+			continue
+		}
+
+		items = append(items, dto.InventoryValuationLine{
+			ItemID:           row.ItemID,
+			ItemName:         row.ItemName,
+			AssetAccountID:   row.AssetAccountID,
+			AssetAccountName: row.AssetAccountName,
+			Qty:              money.FormatScaled5(row.QtyScaled),
+			AvgCost:          money.FormatScaled5(money.AverageCostScaled(row.QtyScaled, row.ValueCents)),
+			Value:            s.format.Cents(row.ValueCents),
+		})
+		total += row.ValueCents
+	}
+
+	return &dto.InventoryValuationResponse{
+		BuildingID: buildingID,
+		AsOfDate:   asOfDate,
+		Items:      items,
+		TotalValue: s.format.Cents(total),
+	}, nil
+}
+
+// GetInventoryMovementHistory lists every bill, invoice, sales receipt and adjustment that moved
+// stock in the period, per item, with the running qty and value after each one
+func (s *ReportService) GetInventoryMovementHistory(ctx context.Context, buildingID int, startDate string, endDate string, itemID *int) (*dto.InventoryMovementHistoryResponse, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date: %v", err)
+	}
+
+	// opening stock is the valuation at the close of the day before the period
+	openings, err := s.reportStore.GetInventoryValuation(ctx, buildingID, start.AddDate(0, 0, -1).Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.reportStore.GetInventoryMovementLines(ctx, buildingID, startDate, endDate, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	type itemTotals struct {
+		qty, value, qtyIn, qtyOut int64
+	}
+
+	items := []dto.InventoryMovementHistoryItem{}
+	itemIndex := make(map[int]int)
+	totals := []itemTotals{}
+
+	addItem := func(id int, name string, qtyScaled, valueCents int64) int {
+		itemIndex[id] = len(items)
+		items = append(items, dto.InventoryMovementHistoryItem{
+			ItemID:       id,
+			ItemName:     name,
+			OpeningQty:   money.FormatScaled5(qtyScaled),
+			OpeningValue: s.format.Cents(valueCents),
+			Movements:    []dto.InventoryMovementHistoryLine{},
+		})
+		totals = append(totals, itemTotals{qty: qtyScaled, value: valueCents})
+		return itemIndex[id]
+	}
+
+	for _, opening := range openings {
+		if itemID != nil && opening.ItemID != *itemID {
+			continue
+		}
+		addItem(opening.ItemID, opening.ItemName, opening.QtyScaled, opening.ValueCents)
+	}
+
+	for _, line := range lines {
+		i, ok := itemIndex[line.ItemID]
+		if !ok {
+			i = addItem(line.ItemID, line.ItemName, 0, 0)
+		}
+
+		t := &totals[i]
+		t.qty += line.QtyScaled
+		t.value += line.CostCents
+		if line.QtyScaled > 0 {
+			t.qtyIn += line.QtyScaled
+		} else {
+			t.qtyOut -= line.QtyScaled
+		}
+
+		items[i].Movements = append(items[i].Movements, dto.InventoryMovementHistoryLine{
+			TransactionID: line.TransactionID,
+			Type:          line.SourceType,
+			Date:          line.Date,
+			Number:        line.Number,
+			Memo:          line.Memo,
+			Qty:           money.FormatScaled5(line.QtyScaled),
+			Cost:          s.format.Cents(line.CostCents),
+			OnHand:        money.FormatScaled5(t.qty),
+			Value:         s.format.Cents(t.value),
+		})
+	}
+
+	history := []dto.InventoryMovementHistoryItem{}
+	for i, item := range items {
+		t := totals[i]
+		if len(item.Movements) == 0 && t.qty == 0 && t.value == 0 {
+			continue
+		}
+
+		item.QtyIn = money.FormatScaled5(t.qtyIn)
+		item.QtyOut = money.FormatScaled5(t.qtyOut)
+		item.ClosingQty = money.FormatScaled5(t.qty)
+		item.ClosingValue = s.format.Cents(t.value)
+		history = append(history, item)
+	}
+
+	return &dto.InventoryMovementHistoryResponse{
+		BuildingID: buildingID,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Items:      history,
+	}, nil
+}
+
+// GetTaxSummary totals the output tax charged on sales and the input tax paid on bills for a
+// filing period, per tax code. Net payable is output less input tax.
+func (s *ReportService) GetTaxSummary(ctx context.Context, buildingID int, startDate string, endDate string) (*dto.TaxSummaryResponse, error) {
+	rows, err := s.reportStore.GetTaxSummary(ctx, buildingID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	type codeTotals struct {
+		outputTaxable, outputTax, inputTaxable, inputTax int64
+	}
+
+	codes := []dto.TaxSummaryLine{}
+	codeIndex := make(map[int]int)
+	totals := []codeTotals{}
+
+	for _, row := range rows {
+		i, ok := codeIndex[row.TaxCodeID]
+		if !ok {
+			i = len(codes)
+			codeIndex[row.TaxCodeID] = i
+			codes = append(codes, dto.TaxSummaryLine{
+				TaxCodeID: row.TaxCodeID,
+				Code:      row.Code,
+				Name:      row.Name,
+				Rate:      money.FormatScaled5(row.RateScaled),
+			})
+			totals = append(totals, codeTotals{})
+		}
+
+		if row.Direction == "output" {
+			totals[i].outputTaxable += row.TaxableCents
+			totals[i].outputTax += row.TaxCents
+		} else {
+			totals[i].inputTaxable += row.TaxableCents
+			totals[i].inputTax += row.TaxCents
+		}
+	}
+
+	var outputTax, inputTax int64
+	for i := range codes {
+		t := totals[i]
+		codes[i].OutputTaxable = s.format.Cents(t.outputTaxable)
+		codes[i].OutputTax = s.format.Cents(t.outputTax)
+		codes[i].InputTaxable = s.format.Cents(t.inputTaxable)
+		codes[i].InputTax = s.format.Cents(t.inputTax)
+		codes[i].Net = s.format.Cents(t.outputTax - t.inputTax)
+
+		outputTax += t.outputTax
+		inputTax += t.inputTax
+	}
+
+	return &dto.TaxSummaryResponse{
+		BuildingID: buildingID,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Codes:      codes,
+		OutputTax:  s.format.Cents(outputTax),
+		InputTax:   s.format.Cents(inputTax),
+		NetPayable: s.format.Cents(outputTax - inputTax),
+	}, nil
+}
+
+// GetWithholdingRegister lists the tax withheld from vendor payments in a period, per vendor
+func (s *ReportService) GetWithholdingRegister(ctx context.Context, buildingID int, startDate string, endDate string, peopleID *int) (*dto.WithholdingRegisterResponse, error) {
+	rows, err := s.reportStore.GetWithholdingRegister(ctx, buildingID, startDate, endDate, peopleID)
+	if err != nil {
+		return nil, err
+	}
+
+	// rows are ordered by vendor, so group consecutive rows
+	vendors := []dto.WithholdingRegisterVendor{}
+	totalWithheld := int64(0)
+
+	for start := 0; start < len(rows); {
+		end := start
+		for end < len(rows) && rows[end].PeopleID == rows[start].PeopleID {
+			end++
+		}
+
+		vendor, withheld := buildWithholdingVendor(rows[start].PeopleID, rows[start].PeopleName, rows[start:end], s.format)
+		vendors = append(vendors, vendor)
+		totalWithheld += withheld
+
+		start = end
+	}
+
+	return &dto.WithholdingRegisterResponse{
+		BuildingID: buildingID,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Vendors:    vendors,
+		Withheld:   s.format.Cents(totalWithheld),
+	}, nil
+}
+
+// GetWithholdingCertificate is the tax withheld from one vendor's payments in a period, for the vendor's records
+func (s *ReportService) GetWithholdingCertificate(ctx context.Context, buildingID int, peopleID int, startDate string, endDate string) (*dto.WithholdingCertificate, error) {
+	building, err := s.buildingStore.GetByID(ctx, int64(buildingID))
+	if err != nil {
+		return nil, fmt.Errorf("building not found: %v", err)
+	}
+
+	vendor, err := s.peopleStore.GetByID(ctx, int64(peopleID))
+	if err != nil {
+		return nil, fmt.Errorf("vendor not found: %v", err)
+	}
+	if vendor.BuildingID != int64(buildingID) {
+		return nil, fmt.Errorf("vendor does not belong to this building")
+	}
+
+	rows, err := s.reportStore.GetWithholdingRegister(ctx, buildingID, startDate, endDate, &peopleID)
+	if err != nil {
+		return nil, err
+	}
+
+	withholding, _ := buildWithholdingVendor(peopleID, vendor.Name, rows, s.format)
+
+	return &dto.WithholdingCertificate{
+		BuildingID:                buildingID,
+		BuildingName:              building.Name,
+		StartDate:                 startDate,
+		EndDate:                   endDate,
+		WithholdingRegisterVendor: withholding,
+	}, nil
+}
+
+// buildWithholdingVendor totals one vendor's withholding rows and returns the cents withheld
+func buildWithholdingVendor(peopleID int, peopleName string, rows []store.WithholdingRow, format money.Formatter) (dto.WithholdingRegisterVendor, int64) {
+	lines := []dto.WithholdingRegisterLine{}
+	var gross, withheld int64
+
+	for _, row := range rows {
+		lines = append(lines, dto.WithholdingRegisterLine{
+			BillPaymentID: row.BillPaymentID,
+			Date:          row.Date,
+			Reference:     row.Reference,
+			BillNo:        row.BillNo,
+			Rate:          money.FormatScaled5(row.RateScaled),
+			Gross:         format.Cents(row.GrossCents),
+			Withheld:      format.Cents(row.WithheldCents),
+			Net:           format.Cents(row.GrossCents - row.WithheldCents),
+		})
+		gross += row.GrossCents
+		withheld += row.WithheldCents
+	}
+
+	return dto.WithholdingRegisterVendor{
+		PeopleID:   peopleID,
+		PeopleName: peopleName,
+		Lines:      lines,
+		Gross:      format.Cents(gross),
+		Withheld:   format.Cents(withheld),
+		Net:        format.Cents(gross - withheld),
+	}, withheld
+}
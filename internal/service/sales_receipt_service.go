@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"fmt"
 	"math"
+	"strconv"
 
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
 	"github.com/mysecodgit/go_accounting/internal/dto"
 	"github.com/mysecodgit/go_accounting/internal/store"
 )
@@ -45,6 +47,9 @@ type SalesReceiptService struct {
 	splitStore        SplitStore
 	accountStore      AccountStore
 	itemStore         ItemStore
+	inventoryService  *InventoryService
+	taxService        *TaxService
+	currencyService   *CurrencyService
 }
 
 func NewSalesReceiptService(
@@ -55,6 +60,9 @@ func NewSalesReceiptService(
 	splitStore SplitStore,
 	accountStore AccountStore,
 	itemStore ItemStore,
+	inventoryService *InventoryService,
+	taxService *TaxService,
+	currencyService *CurrencyService,
 ) *SalesReceiptService {
 	return &SalesReceiptService{
 		db:               db,
@@ -64,6 +72,9 @@ func NewSalesReceiptService(
 		splitStore:        splitStore,
 		accountStore:      accountStore,
 		itemStore:         itemStore,
+		inventoryService:  inventoryService,
+		taxService:        taxService,
+		currencyService:   currencyService,
 	}
 }
 
@@ -118,87 +129,120 @@ func (s *SalesReceiptService) Create(
 ) error {
 
 	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		_, err := s.CreateTx(ctx, tx, req)
+		return err
+	})
+}
 
-		// 1. Create transaction
-		transaction := &store.Transaction{
-			Type:              "receipt",
-			TransactionDate:   req.ReceiptDate,
-			TransactionNumber: fmt.Sprintf("%d", req.ReceiptNo),
-			Memo:              req.Description,
-			Status:            "1",
-			BuildingID:        req.BuildingID,
-			UserID:            1, // TODO: JWT
-			UnitID:            req.UnitID,
-		}
+// CreateTx posts a sales receipt inside an existing transaction and returns its transaction id
+func (s *SalesReceiptService) CreateTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	req dto.CreateSalesReceiptRequest,
+) (*int64, error) {
+
+	// 1. Create transaction
+	transaction := &store.Transaction{
+		Type:              "receipt",
+		TransactionDate:   req.ReceiptDate,
+		TransactionNumber: fmt.Sprintf("%d", req.ReceiptNo),
+		Memo:              req.Description,
+		Status:            "1",
+		BuildingID:        req.BuildingID,
+		UserID:            1, // TODO: JWT
+		UnitID:            req.UnitID,
+	}
 
-		transactionID, err := s.transactionStore.Create(ctx, tx, transaction)
-		if err != nil {
-			return err
-		}
+	transactionID, err := s.transactionStore.Create(ctx, tx, transaction)
+	if err != nil {
+		return nil, err
+	}
 
-		// 2. Generate splits
-		splits, err := s.GenerateSalesReceiptSplits(ctx, req.SalesReceiptPayload)
-		if err != nil {
-			return err
-		}
+	// 2. Generate splits
+	splits, err := s.GenerateSalesReceiptSplits(ctx, req.SalesReceiptPayload)
+	if err != nil {
+		return nil, err
+	}
 
-		if err := s.ValidateBalanced(splits); err != nil {
-			return err
-		}
+	// inventory lines leave stock at average cost
+	inventoryLines, err := receiptInventoryLines(req.SalesReceiptPayload)
+	if err != nil {
+		return nil, err
+	}
+	costSplits, err := s.inventoryService.issueTx(ctx, tx, receiptInventoryPosting(*transactionID, req.SalesReceiptPayload), inventoryLines)
+	if err != nil {
+		return nil, err
+	}
+	splits = append(splits, costSplits...)
 
-		for _, split := range splits {
-			split.TransactionID = *transactionID
-			if err := s.splitStore.Create(ctx, tx, &split); err != nil {
-				return err
-			}
-		}
+	if err := s.ValidateBalanced(splits); err != nil {
+		return nil, err
+	}
 
-		// 3. Create receipt
-		receipt := &store.SalesReceipt{
-			TransactionID: *transactionID,
-			ReceiptNo:     req.ReceiptNo,
-			ReceiptDate:   req.ReceiptDate,
-			UnitID:        req.UnitID,
-			PeopleID:      req.PeopleID,
-			UserID:        1,
-			AccountID:     req.AccountID,
-			Amount:        req.Amount,
-			Description:   &req.Description,
-			Status:        1,
-			BuildingID:    req.BuildingID,
+	if err := s.currencyService.requireBaseCurrency(ctx, splits); err != nil {
+		return nil, err
+	}
+
+	for _, split := range splits {
+		split.TransactionID = *transactionID
+		if err := s.splitStore.Create(ctx, tx, &split); err != nil {
+			return nil, err
 		}
+	}
+
+	// 3. Create receipt
+	receipt := &store.SalesReceipt{
+		TransactionID: *transactionID,
+		ReceiptNo:     req.ReceiptNo,
+		ReceiptDate:   req.ReceiptDate,
+		UnitID:        req.UnitID,
+		PeopleID:      req.PeopleID,
+		UserID:        1,
+		AccountID:     req.AccountID,
+		Amount:        req.Amount,
+		Description:   &req.Description,
+		Status:        1,
+		BuildingID:    req.BuildingID,
+	}
+
+	receiptID, err := s.salesReceiptStore.Create(ctx, tx, receipt)
+	if err != nil {
+		return nil, err
+	}
 
-		receiptID, err := s.salesReceiptStore.Create(ctx, tx, receipt)
+	// 4. Create receipt items
+	for _, line := range req.Items {
+
+		itemRow, err := s.itemStore.GetByID(ctx, int64(line.ItemID))
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		// 4. Create receipt items
-		for _, line := range req.Items {
-
-			itemRow, err := s.itemStore.GetByID(ctx, int64(line.ItemID))
-			if err != nil {
-				return err
-			}
+		tax, err := s.receiptLineTax(ctx, line, req.BuildingID)
+		if err != nil {
+			return nil, err
+		}
 
-			item := &store.ReceiptItem{
-				ReceiptID:     *receiptID,
-				ItemID:        int64(line.ItemID),
-				ItemName:      itemRow.Name,
-				Qty:           line.Qty,
-				Rate:          line.Rate,
-				Total:         *line.Total,
-				PreviousValue: line.PreviousValue,
-				CurrentValue:  line.CurrentValue,
-			}
+		item := &store.ReceiptItem{
+			ReceiptID:     *receiptID,
+			ItemID:        int64(line.ItemID),
+			ItemName:      itemRow.Name,
+			Qty:           line.Qty,
+			Rate:          line.Rate,
+			Total:         *line.Total,
+			PreviousValue: line.PreviousValue,
+			CurrentValue:  line.CurrentValue,
+			TaxCodeID:     tax.TaxCodeID,
+			TaxableCents:  tax.TaxableCents,
+			TaxCents:      tax.TaxCents,
+		}
 
-			if _, err := s.receiptItemStore.Create(ctx, tx, item); err != nil {
-				return err
-			}
+		if _, err := s.receiptItemStore.Create(ctx, tx, item); err != nil {
+			return nil, err
 		}
+	}
 
-		return nil
-	})
+	return transactionID, nil
 }
 
 /*
@@ -219,6 +263,12 @@ func (s *SalesReceiptService) Update(
 			return err
 		}
 
+		if reason, err := s.splitStore.GetLockReason(ctx, tx, existing.TransactionID); err != nil {
+			return err
+		} else if reason != "" {
+			return fmt.Errorf("sales receipt cannot be edited: %s", reason)
+		}
+
 		// Update transaction
 		transaction := &store.Transaction{
 			ID:                existing.TransactionID,
@@ -247,10 +297,29 @@ func (s *SalesReceiptService) Update(
 			return err
 		}
 
+		// put back what the receipt took out of stock, then issue the edited lines
+		itemIDs, err := s.inventoryService.reverseTx(ctx, tx, existing.TransactionID)
+		if err != nil {
+			return err
+		}
+		inventoryLines, err := receiptInventoryLines(req.SalesReceiptPayload)
+		if err != nil {
+			return err
+		}
+		costSplits, err := s.inventoryService.issueTx(ctx, tx, receiptInventoryPosting(existing.TransactionID, req.SalesReceiptPayload), inventoryLines)
+		if err != nil {
+			return err
+		}
+		splits = append(splits, costSplits...)
+
 		if err := s.ValidateBalanced(splits); err != nil {
 			return err
 		}
 
+		if err := s.currencyService.requireBaseCurrency(ctx, splits); err != nil {
+			return err
+		}
+
 		for _, split := range splits {
 			split.TransactionID = *transactionID
 			if err := s.splitStore.Create(ctx, tx, &split); err != nil {
@@ -289,6 +358,11 @@ func (s *SalesReceiptService) Update(
 				return err
 			}
 
+			tax, err := s.receiptLineTax(ctx, line, req.BuildingID)
+			if err != nil {
+				return err
+			}
+
 			item := &store.ReceiptItem{
 				ReceiptID:     existing.ID,
 				ItemID:        int64(line.ItemID),
@@ -298,6 +372,9 @@ func (s *SalesReceiptService) Update(
 				Total:         *line.Total,
 				PreviousValue: line.PreviousValue,
 				CurrentValue:  line.CurrentValue,
+				TaxCodeID:     tax.TaxCodeID,
+				TaxableCents:  tax.TaxableCents,
+				TaxCents:      tax.TaxCents,
 			}
 
 			if _, err := s.receiptItemStore.Create(ctx, tx, item); err != nil {
@@ -305,7 +382,7 @@ func (s *SalesReceiptService) Update(
 			}
 		}
 
-		return nil
+		return s.inventoryService.checkStockTx(ctx, tx, itemIDs)
 	})
 }
 
@@ -327,6 +404,7 @@ func (s *SalesReceiptService) GenerateSalesReceiptSplits(
 			acc[accountID] = &splitAccumulator{}
 		}
 		acc[accountID].Debit += amount
+		acc[accountID].DebitCents += int64(math.Round(amount * float64(money.MoneyScale)))
 	}
 
 	addCredit := func(accountID int64, amount float64) {
@@ -334,11 +412,15 @@ func (s *SalesReceiptService) GenerateSalesReceiptSplits(
 			acc[accountID] = &splitAccumulator{}
 		}
 		acc[accountID].Credit += amount
+		acc[accountID].CreditCents += int64(math.Round(amount * float64(money.MoneyScale)))
 	}
 
 	// 1. Asset account debit
 	addDebit(int64(req.AccountID), req.Amount)
 
+	var totalCents int64
+	taxed := false
+
 	// 2. Item income
 	for _, line := range req.Items {
 
@@ -347,7 +429,40 @@ func (s *SalesReceiptService) GenerateSalesReceiptSplits(
 			return nil, err
 		}
 
-		addCredit(*item.IncomeAccount, *line.Total)
+		if item.IncomeAccount == nil {
+			return nil, fmt.Errorf("item %s has no income account", item.Name)
+		}
+		if line.Total == nil {
+			return nil, fmt.Errorf("item %s has no total", item.Name)
+		}
+
+		tax, err := s.receiptLineTax(ctx, line, req.BuildingID)
+		if err != nil {
+			return nil, err
+		}
+		totalCents += tax.TaxableCents + tax.TaxCents
+
+		if line.TaxCodeID == nil {
+			addCredit(*item.IncomeAccount, *line.Total)
+			continue
+		}
+
+		taxed = true
+		addCredit(*item.IncomeAccount, float64(tax.TaxableCents)/float64(money.MoneyScale))
+		if tax.TaxCents != 0 {
+			addCredit(tax.AccountID, float64(tax.TaxCents)/float64(money.MoneyScale))
+		}
+	}
+
+	if taxed {
+		amountCents, err := money.ParseUSDAmount(strconv.FormatFloat(req.Amount, 'f', -1, 64))
+		if err != nil {
+			return nil, err
+		}
+		if amountCents != totalCents {
+			return nil, fmt.Errorf("amount %s does not match the receipt total of %s including tax",
+				money.FormatMoneyFromCents(amountCents), money.FormatMoneyFromCents(totalCents))
+		}
 	}
 
 	// 3. Build splits
@@ -356,29 +471,71 @@ func (s *SalesReceiptService) GenerateSalesReceiptSplits(
 	for accountID, v := range acc {
 
 		var debit, credit *float64
+		var debitCents, creditCents *int64
 
 		if v.Debit > 0 {
-			d := v.Debit
-			debit = &d
+			d, dc := v.Debit, v.DebitCents
+			debit, debitCents = &d, &dc
 		}
 		if v.Credit > 0 {
-			c := v.Credit
-			credit = &c
+			c, cc := v.Credit, v.CreditCents
+			credit, creditCents = &c, &cc
 		}
 
 		splits = append(splits, store.Split{
-			AccountID: accountID,
-			Debit:     debit,
-			Credit:    credit,
-			UnitID:    req.UnitID,
-			PeopleID:  req.PeopleID,
-			Status:    "1",
+			AccountID:   accountID,
+			Debit:       debit,
+			Credit:      credit,
+			DebitCents:  debitCents,
+			CreditCents: creditCents,
+			UnitID:      req.UnitID,
+			PeopleID:    req.PeopleID,
+			Status:      "1",
 		})
 	}
 
 	return splits, nil
 }
 
+// receiptLineTax works out the tax on a receipt line from its total
+func (s *SalesReceiptService) receiptLineTax(ctx context.Context, line dto.ReceiptItemInput, buildingID int64) (lineTax, error) {
+	var totalCents int64
+	if line.Total != nil {
+		var err error
+		if totalCents, err = money.ParseUSDAmount(strconv.FormatFloat(*line.Total, 'f', -1, 64)); err != nil {
+			return lineTax{}, err
+		}
+	}
+	return s.taxService.lineTax(ctx, line.TaxCodeID, buildingID, totalCents)
+}
+
+// receiptInventoryLines is the qty of every receipt line; the inventory service skips non-inventory items
+func receiptInventoryLines(req dto.SalesReceiptPayload) ([]inventoryLine, error) {
+	lines := []inventoryLine{}
+	for _, line := range req.Items {
+		var qtyScaled int64
+		if line.Qty != nil {
+			var err error
+			if qtyScaled, err = money.ParseQty(strconv.FormatFloat(*line.Qty, 'f', -1, 64)); err != nil {
+				return nil, err
+			}
+		}
+		lines = append(lines, inventoryLine{ItemID: int64(line.ItemID), QtyScaled: qtyScaled})
+	}
+	return lines, nil
+}
+
+func receiptInventoryPosting(transactionID int64, req dto.SalesReceiptPayload) inventoryPosting {
+	return inventoryPosting{
+		TransactionID: transactionID,
+		SourceType:    "receipt",
+		Date:          req.ReceiptDate,
+		BuildingID:    req.BuildingID,
+		UnitID:        req.UnitID,
+		PeopleID:      req.PeopleID,
+	}
+}
+
 /*
 |--------------------------------------------------------------------------
 | Validation
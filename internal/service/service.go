@@ -7,31 +7,46 @@ import (
 )
 
 type Service struct {
-	Auth             *AuthService
-	User             *UserService
-	Building         *BuildingService
-	Unit             *UnitService
-	PeopleType       *PeopleTypeService
-	People           *PeopleService
-	AccountType      *AccountTypeService
-	Account          *AccountService
-	Item             *ItemService
-	Invoice          *InvoiceService
-	Reading          *ReadingService
-	CreditMemo       *CreditMemoService
-	Check            *CheckService
-	Bill             *BillService
-	BillPayment      *BillPaymentService
-	Journal          *JournalService
-	InvoicePayment   *InvoicePaymentService
-	SalesReceipt     *SalesReceiptService
-	Lease            *LeaseService
-	Report           *ReportService
-	UserBuilding     *UserBuildingService
-	Permission       *PermissionService
-	Role             *RoleService
-	RolePermission   *RolePermissionService
-	UserBuildingRole *UserBuildingRoleService
+	Auth                *AuthService
+	User                *UserService
+	Building            *BuildingService
+	Unit                *UnitService
+	PeopleType          *PeopleTypeService
+	People              *PeopleService
+	AccountType         *AccountTypeService
+	Account             *AccountService
+	Item                *ItemService
+	Invoice             *InvoiceService
+	Reading             *ReadingService
+	CreditMemo          *CreditMemoService
+	Check               *CheckService
+	Bill                *BillService
+	BillPayment         *BillPaymentService
+	Journal             *JournalService
+	InvoicePayment      *InvoicePaymentService
+	SalesReceipt        *SalesReceiptService
+	Lease               *LeaseService
+	Report              *ReportService
+	UserBuilding        *UserBuildingService
+	Permission          *PermissionService
+	Role                *RoleService
+	RolePermission      *RolePermissionService
+	UserBuildingRole    *UserBuildingRoleService
+	LateFee             *LateFeeService
+	ReceivedPayment     *ReceivedPaymentService
+	Deposit             *DepositService
+	Reconciliation      *ReconciliationService
+	BankStatement       *BankStatementService
+	BankRule            *BankRuleService
+	VendorCredit        *VendorCreditService
+	BillPaymentRun      *BillPaymentRunService
+	RecurringBill       *RecurringBillService
+	Approval            *ApprovalService
+	PurchaseOrder       *PurchaseOrderService
+	Inventory           *InventoryService
+	InventoryAdjustment *InventoryAdjustmentService
+	Tax                 *TaxService
+	Currency            *CurrencyService
 }
 
 func NewService(
@@ -39,29 +54,62 @@ func NewService(
 	db *sql.DB,
 	jwtSecret string,
 ) *Service {
+	inventoryService := NewInventoryService(store.Inventory)
+
+	taxService := NewTaxService(store.TaxCode, store.Account)
+
+	currencyService := NewCurrencyService(db, store.ExchangeRate, store.FXRevaluation, store.Building, store.Account, store.Transaction, store.Split)
+
+	invoiceService := NewInvoiceService(
+		db,
+		store.CreditMemo,
+		store.Account,
+		store.Invoice,
+		store.InvoiceItem,
+		store.InvoiceAppliedCredit,
+		store.InvoiceAppliedDiscount,
+		store.InvoicePayment,
+		store.Split,
+		store.Transaction,
+		store.Item,
+		store.Building,
+		inventoryService,
+		taxService,
+		currencyService,
+	)
+
+	approvalService := NewApprovalService(store.Approval)
+
+	checkService := NewCheckService(db, store.Check, store.ExpenseLine, store.Split, store.Transaction, store.Account, approvalService, currencyService)
+
+	purchaseOrderService := NewPurchaseOrderService(db, store.PurchaseOrder, store.People, store.Account, store.Item)
+
+	billService := NewBillService(db, store.Bill, store.BillExpenseLine, store.Split, store.Transaction, store.Account, approvalService, purchaseOrderService, inventoryService, taxService, currencyService)
+
+	salesReceiptService := NewSalesReceiptService(
+		db,
+		store.SalesReceipt,
+		store.ReceiptItem,
+		store.Transaction,
+		store.Split,
+		store.Account,
+		store.Item,
+		inventoryService,
+		taxService,
+		currencyService,
+	)
+
 	return &Service{
 		Auth:        NewAuthService(store.User, jwtSecret),
 		User:        NewUserService(store.User),
 		Building:    NewBuildingService(db, store.Building, store.UserBuilding),
 		Unit:        NewUnitService(store.Unit),
 		PeopleType:  NewPeopleTypeService(store.PeopleType),
-		People:      NewPeopleService(store.People),
+		People:      NewPeopleService(store.People, store.Account),
 		AccountType: NewAccountTypeService(store.AccountType),
 		Account:     NewAccountService(store.Account),
 		Item:        NewItemService(store.Item),
-		Invoice: NewInvoiceService(
-			db,
-			store.CreditMemo,
-			store.Account,
-			store.Invoice,
-			store.InvoiceItem,
-			store.InvoiceAppliedCredit,
-			store.InvoiceAppliedDiscount,
-			store.InvoicePayment,
-			store.Split,
-			store.Transaction,
-			store.Item,
-		),
+		Invoice:     invoiceService,
 		Reading: NewReadingService(store.Reading, db),
 		CreditMemo: NewCreditMemoService(
 			db,
@@ -69,11 +117,12 @@ func NewService(
 			store.Transaction,
 			store.Split,
 			store.Account,
+			currencyService,
 		),
-		Check:       NewCheckService(db, store.Check, store.ExpenseLine, store.Split, store.Transaction, store.Account),
-		Bill:        NewBillService(db, store.Bill, store.BillExpenseLine, store.Split, store.Transaction, store.Account),
-		BillPayment: NewBillPaymentService(db, store.BillPayment, store.Transaction, store.Account, store.Bill, store.Split),
-		Journal:     NewJournalService(db, store.Journal, store.JournalLine, store.Transaction, store.Split, store.Account),
+		Check:       checkService,
+		Bill:        billService,
+		BillPayment: NewBillPaymentService(db, store.BillPayment, store.Transaction, store.Account, store.Bill, store.Split, store.Building, store.People, store.VendorCredit, currencyService),
+		Journal:     NewJournalService(db, store.Journal, store.JournalLine, store.Transaction, store.Split, store.Account, currencyService),
 		InvoicePayment: NewInvoicePaymentService(
 			db,
 			store.InvoicePayment,
@@ -81,16 +130,11 @@ func NewService(
 			store.Account,
 			store.Invoice,
 			store.Split,
+			store.CreditMemo,
+			store.Building,
+			currencyService,
 		),
-		SalesReceipt: NewSalesReceiptService(
-			db,
-			store.SalesReceipt,
-			store.ReceiptItem,
-			store.Transaction,
-			store.Split,
-			store.Account,
-			store.Item,
-		),
+		SalesReceipt: salesReceiptService,
 		Lease: NewLeaseService(
 			db,
 			store.Lease,
@@ -101,11 +145,97 @@ func NewService(
 		Report: NewReportService(
 			store.Report,
 			store.Unit,
+			store.People,
+			store.Building,
 		),
 		UserBuilding:     NewUserBuildingService(store.UserBuilding),
 		Permission:       NewPermissionService(store.Permission),
 		Role:             NewRoleService(store.Role),
 		RolePermission:   NewRolePermissionService(store.RolePermission),
 		UserBuildingRole: NewUserBuildingRoleService(store.UserBuildingRole),
+		LateFee: NewLateFeeService(
+			db,
+			store.LateFeePolicy,
+			store.LateFeeAssessment,
+			store.Item,
+			invoiceService,
+		),
+		ReceivedPayment: NewReceivedPaymentService(
+			db,
+			store.ReceivedPayment,
+			store.InvoicePayment,
+			store.CreditMemo,
+			store.Transaction,
+			store.Split,
+			store.Account,
+			store.People,
+			store.Building,
+			currencyService,
+		),
+		Deposit: NewDepositService(
+			db,
+			store.Deposit,
+			store.Transaction,
+			store.Split,
+			store.Account,
+			store.Building,
+			currencyService,
+		),
+		Reconciliation: NewReconciliationService(
+			db,
+			store.Reconciliation,
+			store.Account,
+		),
+		BankStatement: NewBankStatementService(
+			db,
+			store.BankStatement,
+			store.BankRule,
+			store.Account,
+			checkService,
+			salesReceiptService,
+		),
+		BankRule: NewBankRuleService(db, store.BankRule, store.Account),
+		VendorCredit: NewVendorCreditService(
+			db,
+			store.VendorCredit,
+			store.Bill,
+			store.Transaction,
+			store.Split,
+			store.Account,
+			currencyService,
+		),
+		BillPaymentRun: NewBillPaymentRunService(
+			db,
+			store.BillPaymentRun,
+			store.BillPayment,
+			store.Bill,
+			store.Transaction,
+			store.Split,
+			store.Account,
+			store.People,
+			currencyService,
+		),
+		RecurringBill: NewRecurringBillService(
+			db,
+			store.BillTemplate,
+			store.BillTemplateRun,
+			store.Account,
+			store.People,
+			billService,
+		),
+		Approval:      approvalService,
+		PurchaseOrder: purchaseOrderService,
+		Inventory:     inventoryService,
+		InventoryAdjustment: NewInventoryAdjustmentService(
+			db,
+			store.InventoryAdjustment,
+			store.Transaction,
+			store.Split,
+			store.Account,
+			inventoryService,
+			currencyService,
+		),
+		Tax: taxService,
+		Currency: currencyService,
 	}
 }
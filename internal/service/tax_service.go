@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+/*
+|--------------------------------------------------------------------------
+| Interfaces
+|--------------------------------------------------------------------------
+*/
+
+type TaxCodeStore interface {
+	GetAll(ctx context.Context, buildingID int64) ([]store.TaxCode, error)
+	GetByID(ctx context.Context, id int64) (*store.TaxCode, error)
+	Create(ctx context.Context, tc *store.TaxCode) error
+	Update(ctx context.Context, tc *store.TaxCode) error
+	Delete(ctx context.Context, id int64) error
+}
+
+/*
+|--------------------------------------------------------------------------
+| Service
+|--------------------------------------------------------------------------
+*/
+
+// TaxService keeps the sales tax / VAT codes and works out the tax on document lines.
+// Output tax on sales and input tax on bills go to the same liability account of the code.
+type TaxService struct {
+	taxCodeStore TaxCodeStore
+	accountStore AccountStore
+}
+
+// lineTax is how a line amount splits between the income or expense account and the tax account
+type lineTax struct {
+	TaxCodeID    *int64
+	AccountID    int64 // tax liability account, 0 when the line has no tax code
+	TaxableCents int64
+	TaxCents     int64
+}
+
+/*
+|--------------------------------------------------------------------------
+| Constructor
+|--------------------------------------------------------------------------
+*/
+
+func NewTaxService(taxCodeStore TaxCodeStore, accountStore AccountStore) *TaxService {
+	return &TaxService{
+		taxCodeStore: taxCodeStore,
+		accountStore: accountStore,
+	}
+}
+
+/*
+|--------------------------------------------------------------------------
+| Queries
+|--------------------------------------------------------------------------
+*/
+
+func (s *TaxService) GetAll(ctx context.Context, buildingID int64) ([]dto.TaxCodeDto, error) {
+	codes, err := s.taxCodeStore.GetAll(ctx, buildingID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.MapTaxCodesToDto(codes), nil
+}
+
+func (s *TaxService) GetByID(ctx context.Context, id int64) (*dto.TaxCodeDto, error) {
+	code, err := s.taxCodeStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	codeDto := dto.MapTaxCodeToDto(*code)
+	return &codeDto, nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Commands
+|--------------------------------------------------------------------------
+*/
+
+func (s *TaxService) Create(ctx context.Context, req dto.CreateTaxCodeRequest) (*dto.TaxCodeDto, error) {
+	code, err := s.buildTaxCode(ctx, req.TaxCodePayload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.taxCodeStore.Create(ctx, code); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, code.ID)
+}
+
+// Update changes the code for lines saved from now on; posted lines keep the tax they were saved with
+func (s *TaxService) Update(ctx context.Context, req dto.UpdateTaxCodeRequest) (*dto.TaxCodeDto, error) {
+	existing, err := s.taxCodeStore.GetByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.BuildingID != req.BuildingID {
+		return nil, fmt.Errorf("tax code does not belong to this building")
+	}
+
+	code, err := s.buildTaxCode(ctx, req.TaxCodePayload)
+	if err != nil {
+		return nil, err
+	}
+	code.ID = req.ID
+
+	if err := s.taxCodeStore.Update(ctx, code); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, code.ID)
+}
+
+func (s *TaxService) Delete(ctx context.Context, id int64) error {
+	return s.taxCodeStore.Delete(ctx, id)
+}
+
+/*
+|--------------------------------------------------------------------------
+| Helpers
+|--------------------------------------------------------------------------
+*/
+
+func (s *TaxService) buildTaxCode(ctx context.Context, req dto.TaxCodePayload) (*store.TaxCode, error) {
+	account, err := s.accountStore.GetByID(ctx, req.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("account not found: %v", err)
+	}
+
+	if account.BuildingID != req.BuildingID {
+		return nil, fmt.Errorf("account does not belong to this building")
+	}
+
+	rateScaled, err := money.ParseRate(strconv.FormatFloat(req.Rate, 'f', -1, 64))
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate: %v", err)
+	}
+
+	status := req.Status
+	if status == "" {
+		status = "1"
+	}
+
+	return &store.TaxCode{
+		BuildingID: req.BuildingID,
+		Code:       req.Code,
+		Name:       req.Name,
+		RateScaled: rateScaled,
+		Mode:       req.Mode,
+		AccountID:  req.AccountID,
+		Status:     status,
+	}, nil
+}
+
+// lineTax works out the tax on a line amount. Lines without a code are all taxable amount.
+func (s *TaxService) lineTax(ctx context.Context, taxCodeID *int64, buildingID, amountCents int64) (lineTax, error) {
+	if taxCodeID == nil {
+		return lineTax{TaxableCents: amountCents}, nil
+	}
+
+	code, err := s.taxCodeStore.GetByID(ctx, *taxCodeID)
+	if err != nil {
+		return lineTax{}, fmt.Errorf("tax code %d not found", *taxCodeID)
+	}
+	if code.BuildingID != buildingID {
+		return lineTax{}, fmt.Errorf("tax code %s does not belong to this building", code.Code)
+	}
+	if code.Status != "1" {
+		return lineTax{}, fmt.Errorf("tax code %s is inactive", code.Code)
+	}
+
+	taxableCents, taxCents, err := calculateTax(code, amountCents)
+	if err != nil {
+		return lineTax{}, err
+	}
+	return lineTax{
+		TaxCodeID:    taxCodeID,
+		AccountID:    code.AccountID,
+		TaxableCents: taxableCents,
+		TaxCents:     taxCents,
+	}, nil
+}
+
+// calculateTax splits an amount into taxable amount and tax. Exclusive codes add the tax on
+// top of the amount; inclusive codes take it out of the amount.
+func calculateTax(code *store.TaxCode, amountCents int64) (int64, int64, error) {
+	base := 100 * money.RateScale
+
+	if code.Mode == "inclusive" {
+		taxableCents, err := money.MulDiv(amountCents, base, base+code.RateScaled)
+		if err != nil {
+			return 0, 0, fmt.Errorf("tax out of allowed range: %v", err)
+		}
+		return taxableCents, amountCents - taxableCents, nil
+	}
+
+	taxCents, err := money.MulDiv(amountCents, code.RateScaled, base)
+	if err != nil {
+		return 0, 0, fmt.Errorf("tax out of allowed range: %v", err)
+	}
+	return amountCents, taxCents, nil
+}
@@ -0,0 +1,454 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	money "github.com/mysecodgit/go_accounting/internal/accounting"
+	"github.com/mysecodgit/go_accounting/internal/dto"
+	"github.com/mysecodgit/go_accounting/internal/store"
+)
+
+/*
+|--------------------------------------------------------------------------
+| Interfaces
+|--------------------------------------------------------------------------
+*/
+
+type VendorCreditStore interface {
+	GetAll(ctx context.Context, buildingID int64, peopleID *int64, openOnly bool) ([]store.VendorCredit, error)
+	GetByID(ctx context.Context, id int64) (*store.VendorCredit, error)
+	GetAvailableTx(ctx context.Context, tx *sql.Tx, id int64) (int64, error)
+	ExistsByTransactionIDTx(ctx context.Context, tx *sql.Tx, transactionID int64) (bool, error)
+	Create(ctx context.Context, tx *sql.Tx, vc *store.VendorCredit) error
+	Update(ctx context.Context, tx *sql.Tx, vc *store.VendorCredit) error
+	GetApplications(ctx context.Context, billID, vendorCreditID *int64) ([]store.BillAppliedCredit, error)
+	CreateApplication(ctx context.Context, tx *sql.Tx, a *store.BillAppliedCredit) error
+	DeleteApplication(ctx context.Context, id int64) error
+}
+
+/*
+|--------------------------------------------------------------------------
+| Service
+|--------------------------------------------------------------------------
+*/
+
+// VendorCreditService is the payables counterpart of credit memos: a vendor credit debits A/P
+// and credits an expense account, then is applied against the vendor's open bills.
+type VendorCreditService struct {
+	db                *sql.DB
+	vendorCreditStore VendorCreditStore
+	billStore         BillStore
+	transactionStore  TransactionStore
+	splitStore        SplitStore
+	accountStore      AccountStore
+	currencyService   *CurrencyService
+}
+
+/*
+|--------------------------------------------------------------------------
+| Constructor
+|--------------------------------------------------------------------------
+*/
+
+func NewVendorCreditService(
+	db *sql.DB,
+	vendorCreditStore VendorCreditStore,
+	billStore BillStore,
+	transactionStore TransactionStore,
+	splitStore SplitStore,
+	accountStore AccountStore,
+	currencyService *CurrencyService,
+) *VendorCreditService {
+	return &VendorCreditService{
+		db:                db,
+		vendorCreditStore: vendorCreditStore,
+		billStore:         billStore,
+		transactionStore:  transactionStore,
+		splitStore:        splitStore,
+		accountStore:      accountStore,
+		currencyService:   currencyService,
+	}
+}
+
+/*
+|--------------------------------------------------------------------------
+| Queries
+|--------------------------------------------------------------------------
+*/
+
+func (s *VendorCreditService) GetAll(ctx context.Context, buildingID int64, peopleID *int64, openOnly bool) ([]dto.VendorCreditDto, error) {
+	credits, err := s.vendorCreditStore.GetAll(ctx, buildingID, peopleID, openOnly)
+	if err != nil {
+		return nil, err
+	}
+	return dto.MapVendorCreditsToDto(credits), nil
+}
+
+func (s *VendorCreditService) GetByID(ctx context.Context, id int64) (*dto.VendorCreditDetailsResponse, error) {
+	credit, err := s.vendorCreditStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	applications, err := s.vendorCreditStore.GetApplications(ctx, nil, &credit.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch applications: %v", err)
+	}
+
+	transaction, err := s.transactionStore.GetByID(ctx, credit.TransactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transaction: %v", err)
+	}
+
+	splits, err := s.splitStore.GetByTransactionID(ctx, credit.TransactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch splits: %v", err)
+	}
+
+	return &dto.VendorCreditDetailsResponse{
+		VendorCredit: dto.MapVendorCreditToDto(*credit),
+		Applications: dto.MapBillAppliedCreditsToDto(applications),
+		Splits:       dto.MapSplitsToDto(splits),
+		Transaction:  transaction,
+	}, nil
+}
+
+func (s *VendorCreditService) GetBillAppliedCredits(ctx context.Context, billID int64) ([]dto.BillAppliedCreditDto, error) {
+	applications, err := s.vendorCreditStore.GetApplications(ctx, &billID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return dto.MapBillAppliedCreditsToDto(applications), nil
+}
+
+// GetBillAvailableCredits lists the open credits of the bill's vendor with the bill's current balance
+func (s *VendorCreditService) GetBillAvailableCredits(ctx context.Context, billID int64) (*dto.BillAvailableVendorCreditsResponse, error) {
+	bill, err := s.billStore.GetByID(ctx, billID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.BillAvailableVendorCreditsResponse{
+		BillID:  bill.ID,
+		Credits: []dto.VendorCreditDto{},
+	}
+
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		balanceCents, err := s.billStore.GetOpenBalanceTx(ctx, tx, bill.ID)
+		if err != nil {
+			return err
+		}
+		response.Balance = money.FormatMoneyFromCents(balanceCents)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if bill.PeopleID == nil {
+		return response, nil
+	}
+	response.PeopleID = *bill.PeopleID
+
+	credits, err := s.vendorCreditStore.GetAll(ctx, bill.BuildingID, bill.PeopleID, true)
+	if err != nil {
+		return nil, err
+	}
+	response.Credits = dto.MapVendorCreditsToDto(credits)
+
+	return response, nil
+}
+
+/*
+|--------------------------------------------------------------------------
+| Commands
+|--------------------------------------------------------------------------
+*/
+
+func (s *VendorCreditService) Create(ctx context.Context, req dto.CreateVendorCreditRequest) (*dto.VendorCreditDto, error) {
+	amountCents, err := s.validatePayload(ctx, req.VendorCreditPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	credit := &store.VendorCredit{
+		Reference:        req.Reference,
+		Date:             req.Date,
+		PeopleID:         req.PeopleID,
+		APAccountID:      req.APAccountID,
+		ExpenseAccountID: req.ExpenseAccountID,
+		UnitID:           req.UnitID,
+		AmountCents:      amountCents,
+		Memo:             req.Memo,
+		UserID:           1, // TODO: get user id from jwt
+		BuildingID:       req.BuildingID,
+		Status:           "1",
+	}
+
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		transaction := &store.Transaction{
+			Type:              "vendor credit",
+			TransactionDate:   req.Date,
+			TransactionNumber: req.Reference,
+			Memo:              req.Memo,
+			Status:            "1",
+			BuildingID:        req.BuildingID,
+			UserID:            1, // TODO: get user id from jwt
+			UnitID:            req.UnitID,
+		}
+
+		transactionID, err := s.transactionStore.Create(ctx, tx, transaction)
+		if err != nil {
+			return err
+		}
+
+		if err := s.createSplits(ctx, tx, *transactionID, credit); err != nil {
+			return err
+		}
+
+		credit.TransactionID = *transactionID
+		return s.vendorCreditStore.Create(ctx, tx, credit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.MapVendorCreditToDto(*credit)
+	return &response, nil
+}
+
+func (s *VendorCreditService) Update(ctx context.Context, req dto.UpdateVendorCreditRequest) (*dto.VendorCreditDto, error) {
+	existing, err := s.vendorCreditStore.GetByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.BuildingID != req.BuildingID {
+		return nil, fmt.Errorf("vendor credit does not belong to this building")
+	}
+
+	// the excess of a bill payment shares the payment's transaction
+	existingTransaction, err := s.transactionStore.GetByID(ctx, existing.TransactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transaction: %v", err)
+	}
+	if existingTransaction.Type == "bill_payment" {
+		return nil, fmt.Errorf("vendor credit was created by a bill payment overpayment and cannot be edited")
+	}
+
+	amountCents, err := s.validatePayload(ctx, req.VendorCreditPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	credit := &store.VendorCredit{
+		ID:               existing.ID,
+		TransactionID:    existing.TransactionID,
+		Reference:        req.Reference,
+		Date:             req.Date,
+		PeopleID:         req.PeopleID,
+		APAccountID:      req.APAccountID,
+		ExpenseAccountID: req.ExpenseAccountID,
+		UnitID:           req.UnitID,
+		AmountCents:      amountCents,
+		Memo:             req.Memo,
+		UserID:           1, // TODO: get user id from jwt
+		BuildingID:       existing.BuildingID,
+		Status:           existing.Status,
+		CreatedAt:        existing.CreatedAt,
+		PeopleName:       existing.PeopleName,
+	}
+
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if reason, err := s.splitStore.GetLockReason(ctx, tx, existing.TransactionID); err != nil {
+			return err
+		} else if reason != "" {
+			return fmt.Errorf("vendor credit cannot be edited: %s", reason)
+		}
+
+		// locks the credit so an application cannot land between the check and the update
+		availableCents, err := s.vendorCreditStore.GetAvailableTx(ctx, tx, existing.ID)
+		if err != nil {
+			return err
+		}
+		appliedCents := existing.AmountCents - availableCents
+		credit.AppliedCents = appliedCents
+
+		if appliedCents > 0 && credit.PeopleID != existing.PeopleID {
+			return fmt.Errorf("vendor credit has been applied to bills and cannot move to another vendor")
+		}
+		if amountCents < appliedCents {
+			return fmt.Errorf("amount cannot be below the %s already applied to bills", money.FormatMoneyFromCents(appliedCents))
+		}
+
+		transaction := &store.Transaction{
+			ID:                existing.TransactionID,
+			Type:              "vendor credit",
+			TransactionDate:   req.Date,
+			TransactionNumber: req.Reference,
+			Memo:              req.Memo,
+			Status:            "1",
+			BuildingID:        existing.BuildingID,
+			UserID:            1, // TODO: get user id from jwt
+			UnitID:            req.UnitID,
+		}
+
+		if _, err := s.transactionStore.Update(ctx, tx, transaction); err != nil {
+			return fmt.Errorf("error updating transaction: %v", err)
+		}
+
+		if err := s.splitStore.DeleteByTransactionID(ctx, tx, existing.TransactionID); err != nil {
+			return fmt.Errorf("error deleting existing splits: %v", err)
+		}
+
+		if err := s.createSplits(ctx, tx, existing.TransactionID, credit); err != nil {
+			return err
+		}
+
+		return s.vendorCreditStore.Update(ctx, tx, credit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.MapVendorCreditToDto(*credit)
+	return &response, nil
+}
+
+// Apply uses part of a vendor credit to settle an open bill of the same vendor
+func (s *VendorCreditService) Apply(ctx context.Context, req dto.ApplyVendorCreditRequest) (*dto.BillAppliedCreditDto, error) {
+	bill, err := s.billStore.GetByID(ctx, req.BillID)
+	if err != nil {
+		return nil, fmt.Errorf("bill not found: %v", err)
+	}
+	if bill.BuildingID != req.BuildingID {
+		return nil, fmt.Errorf("bill does not belong to this building")
+	}
+	if bill.Status != "1" {
+		return nil, fmt.Errorf("bill is cancelled")
+	}
+	if bill.ApprovalStatus != "approved" {
+		return nil, fmt.Errorf("bill is %s and cannot take credits until it is approved", bill.ApprovalStatus)
+	}
+
+	credit, err := s.vendorCreditStore.GetByID(ctx, req.VendorCreditID)
+	if err != nil {
+		return nil, fmt.Errorf("vendor credit not found: %v", err)
+	}
+	if credit.BuildingID != req.BuildingID {
+		return nil, fmt.Errorf("vendor credit does not belong to this building")
+	}
+	if bill.PeopleID == nil || *bill.PeopleID != credit.PeopleID {
+		return nil, fmt.Errorf("vendor credit and bill belong to different vendors")
+	}
+	if credit.APAccountID != bill.APAccountID {
+		return nil, fmt.Errorf("vendor credit and bill use different A/P accounts")
+	}
+
+	amountCents, err := money.ParseUSDAmount(strconv.FormatFloat(req.Amount, 'f', -1, 64))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse amount: %v", err)
+	}
+
+	application := &store.BillAppliedCredit{
+		BillID:                bill.ID,
+		VendorCreditID:        credit.ID,
+		AmountCents:           amountCents,
+		Description:           req.Description,
+		Date:                  req.Date,
+		Status:                "1",
+		BillNo:                bill.BillNo,
+		VendorCreditReference: credit.Reference,
+	}
+
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		balanceCents, err := s.billStore.GetOpenBalanceTx(ctx, tx, bill.ID)
+		if err != nil {
+			return err
+		}
+		if amountCents > balanceCents {
+			return fmt.Errorf("amount exceeds bill balance. Balance: %s, Requested: %s", money.FormatMoneyFromCents(balanceCents), money.FormatMoneyFromCents(amountCents))
+		}
+
+		availableCents, err := s.vendorCreditStore.GetAvailableTx(ctx, tx, credit.ID)
+		if err != nil {
+			return err
+		}
+		if amountCents > availableCents {
+			return fmt.Errorf("amount exceeds available credit. Available: %s, Requested: %s", money.FormatMoneyFromCents(availableCents), money.FormatMoneyFromCents(amountCents))
+		}
+
+		return s.vendorCreditStore.CreateApplication(ctx, tx, application)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.MapBillAppliedCreditToDto(*application)
+	return &response, nil
+}
+
+// Unapply removes an application, reopening the bill balance and the credit
+func (s *VendorCreditService) Unapply(ctx context.Context, applicationID int64) error {
+	return s.vendorCreditStore.DeleteApplication(ctx, applicationID)
+}
+
+/*
+|--------------------------------------------------------------------------
+| Helpers
+|--------------------------------------------------------------------------
+*/
+
+// validatePayload checks that both accounts belong to the building and returns the amount in cents
+func (s *VendorCreditService) validatePayload(ctx context.Context, req dto.VendorCreditPayload) (int64, error) {
+	if req.APAccountID == req.ExpenseAccountID {
+		return 0, fmt.Errorf("A/P and expense accounts must differ")
+	}
+
+	for _, accountID := range []int64{req.APAccountID, req.ExpenseAccountID} {
+		account, err := s.accountStore.GetByID(ctx, accountID)
+		if err != nil {
+			return 0, fmt.Errorf("account %d not found", accountID)
+		}
+		if account.BuildingID != req.BuildingID {
+			return 0, fmt.Errorf("account %s does not belong to this building", account.AccountName)
+		}
+	}
+
+	amountCents, err := money.ParseUSDAmount(strconv.FormatFloat(req.Amount, 'f', -1, 64))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse amount: %v", err)
+	}
+	if amountCents <= 0 {
+		return 0, fmt.Errorf("amount must be greater than zero")
+	}
+
+	return amountCents, nil
+}
+
+// createSplits debits A/P (the vendor now owes us) and credits the expense account.
+// Both carry the vendor so the credit shows in the vendor balance reports.
+func (s *VendorCreditService) createSplits(ctx context.Context, tx *sql.Tx, transactionID int64, credit *store.VendorCredit) error {
+	splits := []store.Split{
+		newDebitSplit(transactionID, credit.APAccountID, credit.AmountCents, credit.UnitID, &credit.PeopleID),
+		newCreditSplit(transactionID, credit.ExpenseAccountID, credit.AmountCents, credit.UnitID, &credit.PeopleID),
+	}
+
+	if err := validateBalanced(splits); err != nil {
+		return err
+	}
+
+	if err := s.currencyService.requireBaseCurrency(ctx, splits); err != nil {
+		return err
+	}
+
+	for _, split := range splits {
+		if err := s.splitStore.Create(ctx, tx, &split); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
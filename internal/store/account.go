@@ -14,6 +14,7 @@ type Account struct {
 	BuildingID    int64     `json:"building_id"`   // FK → buildings.id
 	Type      AccountType  `json:"type"`
 	IsDefault     int      `json:"isDefault"`
+	Currency      *string   `json:"currency"` // nil is the building's base currency
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
@@ -24,7 +25,7 @@ type AccountStore struct {
 
 func (s *AccountStore) GetAll(ctx context.Context, buildingID int64) ([]Account, error) {
 	query := `
-		SELECT acc.id, acc.account_number, acc.account_name,at.id,at.typeName, acc.isDefault, acc.created_at, acc.updated_at
+		SELECT acc.id, acc.account_number, acc.account_name,at.id,at.typeName, acc.isDefault, acc.currency, acc.created_at, acc.updated_at
 		FROM accounts acc
 		JOIN account_types at ON acc.account_type = at.id
 		WHERE building_id = ?
@@ -49,6 +50,7 @@ func (s *AccountStore) GetAll(ctx context.Context, buildingID int64) ([]Account,
 			&a.Type.ID,
 			&a.Type.TypeName,
 			&a.IsDefault, 
+			&a.Currency,
 			&a.CreatedAt, 
 			&a.UpdatedAt,
 		); err != nil {
@@ -62,7 +64,7 @@ func (s *AccountStore) GetAll(ctx context.Context, buildingID int64) ([]Account,
 
 func (s *AccountStore) GetByID(ctx context.Context, id int64) (*Account, error) {
 	query := `
-		SELECT id, account_number, account_name, account_type, building_id, isDefault, created_at, updated_at
+		SELECT id, account_number, account_name, account_type, building_id, isDefault, currency, created_at, updated_at
 		FROM accounts
 		WHERE id = ?
 	`
@@ -72,7 +74,7 @@ func (s *AccountStore) GetByID(ctx context.Context, id int64) (*Account, error)
 
 	var a Account
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
-		&a.ID, &a.AccountNumber, &a.AccountName, &a.AccountType, &a.BuildingID, &a.IsDefault, &a.CreatedAt, &a.UpdatedAt,
+		&a.ID, &a.AccountNumber, &a.AccountName, &a.AccountType, &a.BuildingID, &a.IsDefault, &a.Currency, &a.CreatedAt, &a.UpdatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -86,14 +88,14 @@ func (s *AccountStore) GetByID(ctx context.Context, id int64) (*Account, error)
 
 func (s *AccountStore) Create(ctx context.Context, a *Account) error {
 	query := `
-		INSERT INTO accounts (account_number, account_name, account_type, building_id, isDefault)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO accounts (account_number, account_name, account_type, building_id, isDefault, currency)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
 	defer cancel()
 
-	result, err := s.db.ExecContext(ctx, query, a.AccountNumber, a.AccountName, a.AccountType, a.BuildingID, a.IsDefault)
+	result, err := s.db.ExecContext(ctx, query, a.AccountNumber, a.AccountName, a.AccountType, a.BuildingID, a.IsDefault, a.Currency)
 	if err != nil {
 		return err
 	}
@@ -110,14 +112,14 @@ func (s *AccountStore) Create(ctx context.Context, a *Account) error {
 func (s *AccountStore) Update(ctx context.Context, a *Account) error {
 	query := `
 		UPDATE accounts
-		SET account_number = ?, account_name = ?, account_type = ?, building_id = ?, isDefault = ?, updated_at = CURRENT_TIMESTAMP
+		SET account_number = ?, account_name = ?, account_type = ?, building_id = ?, isDefault = ?, currency = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
 	defer cancel()
 
-	result, err := s.db.ExecContext(ctx, query, a.AccountNumber, a.AccountName, a.AccountType, a.BuildingID, a.IsDefault, a.ID)
+	result, err := s.db.ExecContext(ctx, query, a.AccountNumber, a.AccountName, a.AccountType, a.BuildingID, a.IsDefault, a.Currency, a.ID)
 	if err != nil {
 		return err
 	}
@@ -12,16 +12,26 @@ type AccountType struct {
 	Type      string    `json:"type"`
 	SubType   string    `json:"sub_type"`
 	TypeStatus string   `json:"typeStatus"`
+	CashFlowCategory string `json:"cash_flow_category"` // one of the CashFlow constants
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Cash flow categories place an account type's accounts on the statement of cash flows
+const (
+	CashFlowCash      = "cash" // the cash and bank accounts the statement reconciles to
+	CashFlowOperating = "operating"
+	CashFlowInvesting = "investing"
+	CashFlowFinancing = "financing"
+	CashFlowNonCash   = "non_cash" // moved by non-cash expenses, e.g. accumulated depreciation
+)
+
 type AccountTypeStore struct {
 	db *sql.DB
 }
 
 func (s *AccountTypeStore) GetAll(ctx context.Context) ([]AccountType, error) {
-	query := `SELECT id, typeName, type, sub_type, typeStatus, created_at, updated_at FROM account_types`
+	query := `SELECT id, typeName, type, sub_type, typeStatus, cash_flow_category, created_at, updated_at FROM account_types`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
 	defer cancel()
@@ -35,7 +45,7 @@ func (s *AccountTypeStore) GetAll(ctx context.Context) ([]AccountType, error) {
 	var list []AccountType
 	for rows.Next() {
 		var at AccountType
-		if err := rows.Scan(&at.ID, &at.TypeName, &at.Type, &at.SubType, &at.TypeStatus, &at.CreatedAt, &at.UpdatedAt); err != nil {
+		if err := rows.Scan(&at.ID, &at.TypeName, &at.Type, &at.SubType, &at.TypeStatus, &at.CashFlowCategory, &at.CreatedAt, &at.UpdatedAt); err != nil {
 			return nil, err
 		}
 		list = append(list, at)
@@ -45,13 +55,13 @@ func (s *AccountTypeStore) GetAll(ctx context.Context) ([]AccountType, error) {
 }
 
 func (s *AccountTypeStore) GetByID(ctx context.Context, id int64) (*AccountType, error) {
-	query := `SELECT id, typeName, type, sub_type, typeStatus, created_at, updated_at FROM account_types WHERE id = ?`
+	query := `SELECT id, typeName, type, sub_type, typeStatus, cash_flow_category, created_at, updated_at FROM account_types WHERE id = ?`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
 	defer cancel()
 
 	var at AccountType
-	err := s.db.QueryRowContext(ctx, query, id).Scan(&at.ID, &at.TypeName, &at.Type, &at.SubType, &at.TypeStatus, &at.CreatedAt, &at.UpdatedAt)
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&at.ID, &at.TypeName, &at.Type, &at.SubType, &at.TypeStatus, &at.CashFlowCategory, &at.CreatedAt, &at.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrNotFound
@@ -63,12 +73,12 @@ func (s *AccountTypeStore) GetByID(ctx context.Context, id int64) (*AccountType,
 }
 
 func (s *AccountTypeStore) Create(ctx context.Context, at *AccountType) error {
-	query := `INSERT INTO account_types (typeName, type, sub_type, typeStatus) VALUES (?, ?, ?, ?)`
+	query := `INSERT INTO account_types (typeName, type, sub_type, typeStatus, cash_flow_category) VALUES (?, ?, ?, ?, ?)`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
 	defer cancel()
 
-	result, err := s.db.ExecContext(ctx, query, at.TypeName, at.Type, at.SubType, at.TypeStatus)
+	result, err := s.db.ExecContext(ctx, query, at.TypeName, at.Type, at.SubType, at.TypeStatus, at.CashFlowCategory)
 	if err != nil {
 		return err
 	}
@@ -83,12 +93,12 @@ func (s *AccountTypeStore) Create(ctx context.Context, at *AccountType) error {
 }
 
 func (s *AccountTypeStore) Update(ctx context.Context, at *AccountType) error {
-	query := `UPDATE account_types SET typeName = ?, type = ?, sub_type = ?, typeStatus = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	query := `UPDATE account_types SET typeName = ?, type = ?, sub_type = ?, typeStatus = ?, cash_flow_category = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
 	defer cancel()
 
-	result, err := s.db.ExecContext(ctx, query, at.TypeName, at.Type, at.SubType, at.TypeStatus, at.ID)
+	result, err := s.db.ExecContext(ctx, query, at.TypeName, at.Type, at.SubType, at.TypeStatus, at.CashFlowCategory, at.ID)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,387 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// ApprovalThreshold requires one approval by a user holding RoleID on bills or checks
+// of at least MinAmountCents
+type ApprovalThreshold struct {
+	ID             int64  `json:"id"`
+	BuildingID     int64  `json:"building_id"`
+	DocumentType   string `json:"document_type"` // bill | check
+	MinAmountCents int64  `json:"min_amount_cents"`
+	RoleID         int64  `json:"role_id"`
+	RoleName       string `json:"role_name"`
+	Status         string `json:"status"` // enum('0','1')
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+}
+
+// DocumentApproval is one approve or reject decision on a bill or check
+type DocumentApproval struct {
+	ID           int64   `json:"id"`
+	DocumentType string  `json:"document_type"`
+	DocumentID   int64   `json:"document_id"`
+	ThresholdID  *int64  `json:"threshold_id"` // nil when the building has no threshold for the amount
+	Action       string  `json:"action"`       // approved | rejected
+	UserID       int64   `json:"user_id"`
+	UserName     string  `json:"user_name"`
+	Note         *string `json:"note"`
+	Status       string  `json:"status"` // '0' once voided by an edit
+	BuildingID   int64   `json:"building_id"`
+	CreatedAt    string  `json:"created_at"`
+}
+
+// approvalTables maps a document type to the table holding its approval_status
+var approvalTables = map[string]string{
+	"bill":  "bills",
+	"check": "checks",
+}
+
+type ApprovalStore struct {
+	db *sql.DB
+}
+
+func NewApprovalStore(db *sql.DB) *ApprovalStore {
+	return &ApprovalStore{db: db}
+}
+
+const approvalThresholdColumns = `
+	t.id, t.building_id, t.document_type, t.min_amount_cents, t.role_id, r.name, t.status,
+	t.created_at, t.updated_at
+`
+
+func scanApprovalThreshold(scan func(dest ...any) error, t *ApprovalThreshold) error {
+	return scan(
+		&t.ID,
+		&t.BuildingID,
+		&t.DocumentType,
+		&t.MinAmountCents,
+		&t.RoleID,
+		&t.RoleName,
+		&t.Status,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+}
+
+func (s *ApprovalStore) GetThresholds(ctx context.Context, buildingID int64, documentType *string) ([]ApprovalThreshold, error) {
+	query := `
+		SELECT ` + approvalThresholdColumns + `
+		FROM approval_thresholds t
+		JOIN roles r ON r.id = t.role_id
+		WHERE t.building_id = ?
+	`
+
+	args := []any{buildingID}
+
+	if documentType != nil && *documentType != "" {
+		query += " AND t.document_type = ?"
+		args = append(args, *documentType)
+	}
+
+	query += " ORDER BY t.document_type, t.min_amount_cents, t.id"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var thresholds []ApprovalThreshold
+	for rows.Next() {
+		var t ApprovalThreshold
+		if err := scanApprovalThreshold(rows.Scan, &t); err != nil {
+			return nil, err
+		}
+		thresholds = append(thresholds, t)
+	}
+
+	return thresholds, nil
+}
+
+func (s *ApprovalStore) GetThresholdByID(ctx context.Context, id int64) (*ApprovalThreshold, error) {
+	query := `
+		SELECT ` + approvalThresholdColumns + `
+		FROM approval_thresholds t
+		JOIN roles r ON r.id = t.role_id
+		WHERE t.id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var t ApprovalThreshold
+	if err := scanApprovalThreshold(s.db.QueryRowContext(ctx, query, id).Scan, &t); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// GetRequiredThresholds returns the active thresholds an amount reaches, in approval order
+func (s *ApprovalStore) GetRequiredThresholds(ctx context.Context, buildingID int64, documentType string, amountCents int64) ([]ApprovalThreshold, error) {
+	query := `
+		SELECT ` + approvalThresholdColumns + `
+		FROM approval_thresholds t
+		JOIN roles r ON r.id = t.role_id
+		WHERE t.building_id = ? AND t.document_type = ? AND t.status = '1' AND t.min_amount_cents <= ?
+		ORDER BY t.min_amount_cents, t.id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, buildingID, documentType, amountCents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var thresholds []ApprovalThreshold
+	for rows.Next() {
+		var t ApprovalThreshold
+		if err := scanApprovalThreshold(rows.Scan, &t); err != nil {
+			return nil, err
+		}
+		thresholds = append(thresholds, t)
+	}
+
+	return thresholds, nil
+}
+
+func (s *ApprovalStore) CreateThreshold(ctx context.Context, t *ApprovalThreshold) error {
+	query := `
+		INSERT INTO approval_thresholds (building_id, document_type, min_amount_cents, role_id, status)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, t.BuildingID, t.DocumentType, t.MinAmountCents, t.RoleID, t.Status)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	t.ID = id
+	return nil
+}
+
+func (s *ApprovalStore) UpdateThreshold(ctx context.Context, t *ApprovalThreshold) error {
+	query := `
+		UPDATE approval_thresholds
+		SET document_type = ?, min_amount_cents = ?, role_id = ?, status = ?
+		WHERE id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, t.DocumentType, t.MinAmountCents, t.RoleID, t.Status, t.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *ApprovalStore) DeleteThreshold(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM approval_thresholds WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// GetApprovals returns every decision on a document, voided ones included, oldest first
+func (s *ApprovalStore) GetApprovals(ctx context.Context, documentType string, documentID int64) ([]DocumentApproval, error) {
+	query := `
+		SELECT a.id, a.document_type, a.document_id, a.threshold_id, a.action, a.user_id, u.name,
+		       a.note, a.status, a.building_id, a.created_at
+		FROM document_approvals a
+		JOIN users u ON u.id = a.user_id
+		WHERE a.document_type = ? AND a.document_id = ?
+		ORDER BY a.id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, documentType, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var approvals []DocumentApproval
+	for rows.Next() {
+		var a DocumentApproval
+		if err := rows.Scan(
+			&a.ID,
+			&a.DocumentType,
+			&a.DocumentID,
+			&a.ThresholdID,
+			&a.Action,
+			&a.UserID,
+			&a.UserName,
+			&a.Note,
+			&a.Status,
+			&a.BuildingID,
+			&a.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, a)
+	}
+
+	return approvals, nil
+}
+
+func (s *ApprovalStore) CreateApproval(ctx context.Context, tx *sql.Tx, a *DocumentApproval) error {
+	query := `
+		INSERT INTO document_approvals
+		(document_type, document_id, threshold_id, action, user_id, note, status, building_id)
+		VALUES (?, ?, ?, ?, ?, ?, '1', ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, a.DocumentType, a.DocumentID, a.ThresholdID, a.Action, a.UserID, a.Note, a.BuildingID)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	a.ID = id
+	a.Status = "1"
+	return nil
+}
+
+// VoidApprovals sets aside the decisions made on an earlier version of the document
+func (s *ApprovalStore) VoidApprovals(ctx context.Context, tx *sql.Tx, documentType string, documentID int64) error {
+	query := `
+		UPDATE document_approvals SET status = '0'
+		WHERE document_type = ? AND document_id = ? AND status = '1'
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, documentType, documentID)
+	return err
+}
+
+// LockDocumentTx locks the bill or check row so two approvers cannot decide it at once
+// and returns its approval status
+func (s *ApprovalStore) LockDocumentTx(ctx context.Context, tx *sql.Tx, documentType string, documentID int64) (string, error) {
+	table, ok := approvalTables[documentType]
+	if !ok {
+		return "", fmt.Errorf("unknown document type %s", documentType)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var status string
+	query := `SELECT approval_status FROM ` + table + ` WHERE id = ? FOR UPDATE`
+	if err := tx.QueryRowContext(ctx, query, documentID).Scan(&status); err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+
+	return status, nil
+}
+
+func (s *ApprovalStore) SetDocumentStatusTx(ctx context.Context, tx *sql.Tx, documentType string, documentID int64, status string) error {
+	table, ok := approvalTables[documentType]
+	if !ok {
+		return fmt.Errorf("unknown document type %s", documentType)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, `UPDATE `+table+` SET approval_status = ? WHERE id = ?`, status, documentID)
+	return err
+}
+
+func (s *ApprovalStore) UserHasRole(ctx context.Context, userID, buildingID, roleID int64) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM user_building_roles
+			WHERE user_id = ? AND building_id = ? AND role_id = ?
+		)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var has bool
+	if err := s.db.QueryRowContext(ctx, query, userID, buildingID, roleID).Scan(&has); err != nil {
+		return false, err
+	}
+
+	return has, nil
+}
+
+// UserHasBuildingRole reports whether the user holds any role in the building
+func (s *ApprovalStore) UserHasBuildingRole(ctx context.Context, userID, buildingID int64) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM user_building_roles
+			WHERE user_id = ? AND building_id = ?
+		)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var has bool
+	if err := s.db.QueryRowContext(ctx, query, userID, buildingID).Scan(&has); err != nil {
+		return false, err
+	}
+
+	return has, nil
+}
@@ -0,0 +1,221 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+// BankRule categorizes imported bank lines. Withdrawals become checks against TargetAccountID,
+// deposits become sales receipts for ItemID.
+type BankRule struct {
+	ID                  int64   `json:"id"`
+	BuildingID          int64   `json:"building_id"`
+	Name                string  `json:"name"`
+	Priority            int     `json:"priority"`
+	BankAccountID       *int64  `json:"bank_account_id"` // nil matches lines of any bank account
+	Direction           string  `json:"direction"`       // any | in | out
+	DescriptionContains *string `json:"description_contains"`
+	DescriptionRegex    *string `json:"description_regex"`
+	MinAmountCents      *int64  `json:"min_amount_cents"` // bounds on the unsigned amount
+	MaxAmountCents      *int64  `json:"max_amount_cents"`
+	TargetAccountID     *int64  `json:"target_account_id"`
+	ItemID              *int64  `json:"item_id"`
+	UnitID              *int64  `json:"unit_id"`
+	PeopleID            *int64  `json:"people_id"`
+	Status              string  `json:"status"`
+	CreatedAt           string  `json:"created_at"`
+	UpdatedAt           string  `json:"updated_at"`
+}
+
+type BankRuleStore struct {
+	db *sql.DB
+}
+
+func NewBankRuleStore(db *sql.DB) *BankRuleStore {
+	return &BankRuleStore{db: db}
+}
+
+const bankRuleColumns = `
+	id, building_id, name, priority, bank_account_id, direction, description_contains,
+	description_regex, min_amount_cents, max_amount_cents, target_account_id, item_id,
+	unit_id, people_id, status, created_at, updated_at
+`
+
+func scanBankRule(scan func(dest ...any) error, r *BankRule) error {
+	return scan(
+		&r.ID,
+		&r.BuildingID,
+		&r.Name,
+		&r.Priority,
+		&r.BankAccountID,
+		&r.Direction,
+		&r.DescriptionContains,
+		&r.DescriptionRegex,
+		&r.MinAmountCents,
+		&r.MaxAmountCents,
+		&r.TargetAccountID,
+		&r.ItemID,
+		&r.UnitID,
+		&r.PeopleID,
+		&r.Status,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	)
+}
+
+// GetAll returns the building's rules in the order they are tried; activeOnly skips disabled rules
+func (s *BankRuleStore) GetAll(ctx context.Context, buildingID int64, activeOnly bool) ([]BankRule, error) {
+	query := `SELECT ` + bankRuleColumns + ` FROM bank_rules WHERE building_id = ?`
+
+	if activeOnly {
+		query += " AND status = '1'"
+	}
+
+	query += " ORDER BY priority, id"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, buildingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []BankRule
+	for rows.Next() {
+		var r BankRule
+		if err := scanBankRule(rows.Scan, &r); err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+func (s *BankRuleStore) GetByID(ctx context.Context, id int64) (*BankRule, error) {
+	query := `SELECT ` + bankRuleColumns + ` FROM bank_rules WHERE id = ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var r BankRule
+	if err := scanBankRule(s.db.QueryRowContext(ctx, query, id).Scan, &r); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+func (s *BankRuleStore) Create(ctx context.Context, tx *sql.Tx, r *BankRule) error {
+	query := `
+		INSERT INTO bank_rules
+		(building_id, name, priority, bank_account_id, direction, description_contains, description_regex,
+		 min_amount_cents, max_amount_cents, target_account_id, item_id, unit_id, people_id, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		r.BuildingID,
+		r.Name,
+		r.Priority,
+		r.BankAccountID,
+		r.Direction,
+		r.DescriptionContains,
+		r.DescriptionRegex,
+		r.MinAmountCents,
+		r.MaxAmountCents,
+		r.TargetAccountID,
+		r.ItemID,
+		r.UnitID,
+		r.PeopleID,
+		r.Status,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	r.ID = id
+	return nil
+}
+
+func (s *BankRuleStore) Update(ctx context.Context, r *BankRule) error {
+	query := `
+		UPDATE bank_rules
+		SET name = ?, priority = ?, bank_account_id = ?, direction = ?, description_contains = ?,
+		    description_regex = ?, min_amount_cents = ?, max_amount_cents = ?, target_account_id = ?,
+		    item_id = ?, unit_id = ?, people_id = ?, status = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(
+		ctx,
+		query,
+		r.Name,
+		r.Priority,
+		r.BankAccountID,
+		r.Direction,
+		r.DescriptionContains,
+		r.DescriptionRegex,
+		r.MinAmountCents,
+		r.MaxAmountCents,
+		r.TargetAccountID,
+		r.ItemID,
+		r.UnitID,
+		r.PeopleID,
+		r.Status,
+		r.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *BankRuleStore) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM bank_rules WHERE id = ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
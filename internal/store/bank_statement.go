@@ -0,0 +1,421 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+type BankStatementImport struct {
+	ID             int64  `json:"id"`
+	AccountID      int64  `json:"account_id"`
+	AccountName    string `json:"account_name"`
+	BuildingID     int64  `json:"building_id"`
+	Format         string `json:"format"`
+	FileName       string `json:"file_name"`
+	LineCount      int    `json:"line_count"`
+	DuplicateCount int    `json:"duplicate_count"`
+	UserID         int64  `json:"user_id"`
+	CreatedAt      string `json:"created_at"`
+}
+
+// BankStatementLine is a staged statement entry waiting to be matched to the ledger
+type BankStatementLine struct {
+	ID            int64   `json:"id"`
+	ImportID      int64   `json:"import_id"`
+	AccountID     int64   `json:"account_id"`
+	BuildingID    int64   `json:"building_id"`
+	Date          string  `json:"date"`
+	AmountCents   int64   `json:"amount_cents"` // positive for money in, negative for money out
+	Description   string  `json:"description"`
+	Reference     string  `json:"reference"`
+	FITID         *string `json:"fitid"`
+	DedupeKey     string  `json:"dedupe_key"`
+	Status        string  `json:"status"`   // new | matched | created | ignored
+	SplitID       *int64  `json:"split_id"` // bank account split the line is matched to
+	TransactionID *int64  `json:"transaction_id"`
+	RuleID        *int64  `json:"rule_id"` // bank rule that categorized the line
+	RuleName      string  `json:"rule_name"`
+	CreatedAt     string  `json:"created_at"`
+	UpdatedAt     string  `json:"updated_at"`
+}
+
+// BankMatchCandidate is an unmatched split of the bank account with the same amount as a line
+type BankMatchCandidate struct {
+	SplitID       int64
+	TransactionID int64
+	Type          string
+	Date          string
+	Reference     string
+	Memo          string
+	PeopleName    string
+	AmountCents   int64
+	DaysApart     int
+}
+
+type BankStatementStore struct {
+	db *sql.DB
+}
+
+func NewBankStatementStore(db *sql.DB) *BankStatementStore {
+	return &BankStatementStore{db: db}
+}
+
+// a line created for a check that waited for approval picks up the check's bank split once it posts
+const bankStatementLineColumns = `
+	l.id, l.import_id, l.account_id, l.building_id, DATE_FORMAT(l.date, '%Y-%m-%d'), l.amount_cents,
+	l.description, l.reference, l.fitid, l.dedupe_key, l.status,
+	IFNULL(l.split_id, (
+		SELECT sp.id FROM splits sp
+		WHERE sp.transaction_id = l.transaction_id AND sp.account_id = l.account_id AND sp.status = '1'
+		ORDER BY sp.id
+		LIMIT 1
+	)),
+	l.transaction_id, l.rule_id, IFNULL(br.name, ''), l.created_at, l.updated_at
+`
+
+func scanBankStatementLine(scan func(dest ...any) error, l *BankStatementLine) error {
+	return scan(
+		&l.ID,
+		&l.ImportID,
+		&l.AccountID,
+		&l.BuildingID,
+		&l.Date,
+		&l.AmountCents,
+		&l.Description,
+		&l.Reference,
+		&l.FITID,
+		&l.DedupeKey,
+		&l.Status,
+		&l.SplitID,
+		&l.TransactionID,
+		&l.RuleID,
+		&l.RuleName,
+		&l.CreatedAt,
+		&l.UpdatedAt,
+	)
+}
+
+func (s *BankStatementStore) GetImports(ctx context.Context, buildingID int64, accountID *int64) ([]BankStatementImport, error) {
+	query := `
+		SELECT i.id, i.account_id, a.account_name, i.building_id, i.format, i.file_name,
+		       i.line_count, i.duplicate_count, i.user_id, i.created_at
+		FROM bank_statement_imports i
+		JOIN accounts a ON a.id = i.account_id
+		WHERE i.building_id = ?
+	`
+
+	args := []any{buildingID}
+
+	if accountID != nil {
+		query += " AND i.account_id = ?"
+		args = append(args, *accountID)
+	}
+
+	query += " ORDER BY i.created_at DESC, i.id DESC"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var imports []BankStatementImport
+	for rows.Next() {
+		var i BankStatementImport
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.AccountName,
+			&i.BuildingID,
+			&i.Format,
+			&i.FileName,
+			&i.LineCount,
+			&i.DuplicateCount,
+			&i.UserID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		imports = append(imports, i)
+	}
+
+	return imports, nil
+}
+
+func (s *BankStatementStore) GetLines(ctx context.Context, buildingID int64, accountID, importID *int64, status *string) ([]BankStatementLine, error) {
+	query := `SELECT ` + bankStatementLineColumns + `
+		FROM bank_statement_lines l
+		LEFT JOIN bank_rules br ON br.id = l.rule_id
+		WHERE l.building_id = ?
+	`
+
+	args := []any{buildingID}
+
+	if accountID != nil {
+		query += " AND l.account_id = ?"
+		args = append(args, *accountID)
+	}
+	if importID != nil {
+		query += " AND l.import_id = ?"
+		args = append(args, *importID)
+	}
+	if status != nil {
+		query += " AND l.status = ?"
+		args = append(args, *status)
+	}
+
+	query += " ORDER BY l.date, l.id"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []BankStatementLine
+	for rows.Next() {
+		var l BankStatementLine
+		if err := scanBankStatementLine(rows.Scan, &l); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+
+	return lines, nil
+}
+
+func (s *BankStatementStore) GetLineByID(ctx context.Context, id int64) (*BankStatementLine, error) {
+	query := `SELECT ` + bankStatementLineColumns + `
+		FROM bank_statement_lines l
+		LEFT JOIN bank_rules br ON br.id = l.rule_id
+		WHERE l.id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var l BankStatementLine
+	if err := scanBankStatementLine(s.db.QueryRowContext(ctx, query, id).Scan, &l); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+// GetMatchCandidates returns active splits of the line's bank account with the same signed amount
+// that no other statement line is matched to, or was created for while it waited for approval. maxDays limits the distance from the line's date;
+// splitID narrows the search to one split and ignores the date window.
+func (s *BankStatementStore) GetMatchCandidates(ctx context.Context, l *BankStatementLine, maxDays int, splitID *int64) ([]BankMatchCandidate, error) {
+	query := `
+		SELECT sp.id, t.id, t.type, DATE_FORMAT(t.transaction_date, '%Y-%m-%d'), t.transaction_number,
+		       IFNULL(t.memo, ''), IFNULL(p.name, ''),
+		       IFNULL(sp.debit_cents, 0) - IFNULL(sp.credit_cents, 0),
+		       ABS(DATEDIFF(t.transaction_date, ?))
+		FROM splits sp
+		JOIN transactions t ON t.id = sp.transaction_id
+		LEFT JOIN people p ON p.id = sp.people_id
+		WHERE sp.account_id = ?
+		  AND sp.status = '1'
+		  AND t.status = '1'
+		  AND IFNULL(sp.debit_cents, 0) - IFNULL(sp.credit_cents, 0) = ?
+		  AND NOT EXISTS (
+			SELECT 1 FROM bank_statement_lines l2
+			WHERE (l2.split_id = sp.id OR (l2.split_id IS NULL AND l2.transaction_id = sp.transaction_id))
+			  AND l2.id <> ?
+		  )
+	`
+
+	args := []any{l.Date, l.AccountID, l.AmountCents, l.ID}
+
+	if splitID != nil {
+		query += " AND sp.id = ?"
+		args = append(args, *splitID)
+	} else {
+		query += " AND ABS(DATEDIFF(t.transaction_date, ?)) <= ?"
+		args = append(args, l.Date, maxDays)
+	}
+
+	query += " ORDER BY ABS(DATEDIFF(t.transaction_date, ?)), sp.id"
+	args = append(args, l.Date)
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []BankMatchCandidate
+	for rows.Next() {
+		var c BankMatchCandidate
+		if err := rows.Scan(
+			&c.SplitID,
+			&c.TransactionID,
+			&c.Type,
+			&c.Date,
+			&c.Reference,
+			&c.Memo,
+			&c.PeopleName,
+			&c.AmountCents,
+			&c.DaysApart,
+		); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+
+	return candidates, nil
+}
+
+// GetTransactionSplitID returns the split a transaction posted to the bank account
+func (s *BankStatementStore) GetTransactionSplitID(ctx context.Context, tx *sql.Tx, transactionID, accountID int64) (int64, error) {
+	query := `
+		SELECT id FROM splits
+		WHERE transaction_id = ? AND account_id = ? AND status = '1'
+		ORDER BY id
+		LIMIT 1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var id int64
+	if err := tx.QueryRowContext(ctx, query, transactionID, accountID).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (s *BankStatementStore) CreateImport(ctx context.Context, tx *sql.Tx, i *BankStatementImport) error {
+	query := `
+		INSERT INTO bank_statement_imports (account_id, building_id, format, file_name, user_id)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, i.AccountID, i.BuildingID, i.Format, i.FileName, i.UserID)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	i.ID = id
+	return nil
+}
+
+// SetImportCounts records how many lines were staged and how many were skipped as duplicates
+func (s *BankStatementStore) SetImportCounts(ctx context.Context, tx *sql.Tx, id int64, lineCount, duplicateCount int) error {
+	query := `UPDATE bank_statement_imports SET line_count = ?, duplicate_count = ? WHERE id = ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, lineCount, duplicateCount, id)
+	return err
+}
+
+// CreateLine stages a line and reports false when the account already has a line with its dedupe key
+func (s *BankStatementStore) CreateLine(ctx context.Context, tx *sql.Tx, l *BankStatementLine) (bool, error) {
+	query := `
+		INSERT INTO bank_statement_lines
+		(import_id, account_id, building_id, date, amount_cents, description, reference, fitid, dedupe_key, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 'new')
+		ON DUPLICATE KEY UPDATE id = id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		l.ImportID,
+		l.AccountID,
+		l.BuildingID,
+		l.Date,
+		l.AmountCents,
+		l.Description,
+		l.Reference,
+		l.FITID,
+		l.DedupeKey,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+
+	l.ID = id
+	l.Status = "new"
+	return true, nil
+}
+
+// UpdateLineStatus moves a line to a new status, only if it is still in the expected one
+func (s *BankStatementStore) UpdateLineStatus(ctx context.Context, tx *sql.Tx, id int64, fromStatus, status string, splitID, transactionID *int64) error {
+	query := `
+		UPDATE bank_statement_lines
+		SET status = ?, split_id = ?, transaction_id = ?
+		WHERE id = ? AND status = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, status, splitID, transactionID, id, fromStatus)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// SetLineRule records which bank rule categorized a line; nil clears it
+func (s *BankStatementStore) SetLineRule(ctx context.Context, tx *sql.Tx, id int64, ruleID *int64) error {
+	query := `UPDATE bank_statement_lines SET rule_id = ? WHERE id = ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, ruleID, id)
+	return err
+}
@@ -6,23 +6,29 @@ import (
 )
 
 type Bill struct {
-	ID            int64   `json:"id"`
-	BillNo        string  `json:"bill_no"`
-	TransactionID int64   `json:"transaction_id"`
-	BillDate      string  `json:"bill_date"`
-	DueDate       string  `json:"due_date"`
-	APAccountID   int64   `json:"ap_account_id"`
-	UnitID        *int64  `json:"unit_id"`
-	PeopleID      *int64  `json:"people_id"`
-	UserID        int64   `json:"user_id"`
-	Amount        float64 `json:"amount"`
-	AmountCents   int64   `json:"amount_cents"`
-	Description   string  `json:"description"`
-	CancelReason  *string `json:"cancel_reason"`
-	Status        string  `json:"status"` // enum('0','1')
-	BuildingID    int64   `json:"building_id"`
-	CreatedAt     string  `json:"created_at"`
-	UpdatedAt     string  `json:"updated_at"`
+	ID               int64   `json:"id"`
+	BillNo           string  `json:"bill_no"`
+	TransactionID    int64   `json:"transaction_id"`
+	BillDate         string  `json:"bill_date"`
+	DueDate          string  `json:"due_date"`
+	APAccountID      int64   `json:"ap_account_id"`
+	PaymentAccountID *int64  `json:"payment_account_id"` // planned to be paid from, nil when none was picked
+	UnitID           *int64  `json:"unit_id"`
+	PeopleID         *int64  `json:"people_id"`
+	UserID           int64   `json:"user_id"`
+	Amount           float64 `json:"amount"`
+	AmountCents      int64   `json:"amount_cents"`
+	Description      string  `json:"description"`
+	CancelReason     *string `json:"cancel_reason"`
+	Status           string  `json:"status"`          // enum('0','1')
+	ApprovalStatus   string  `json:"approval_status"` // draft | pending | approved | rejected, only approved bills have splits
+	BuildingID       int64   `json:"building_id"`
+	// set on foreign currency bills; AmountCents is then the base currency amount
+	Currency           *string `json:"currency"`
+	ExchangeRateScaled *int64  `json:"exchange_rate_scaled"`
+	ForeignAmountCents *int64  `json:"foreign_amount_cents"`
+	CreatedAt          string  `json:"created_at"`
+	UpdatedAt          string  `json:"updated_at"`
 }
 
 type BillStore struct {
@@ -36,8 +42,9 @@ func NewBillStore(db *sql.DB) *BillStore {
 func (s *BillStore) GetAll(ctx context.Context, buildingID int64, startDate, endDate *string, peopleID *int, status *string) ([]Bill, error) {
 	query := `
 		SELECT id, bill_no, transaction_id, bill_date, due_date,
-		       ap_account_id, unit_id, people_id, user_id, amount, amount_cents,
-		       description, cancel_reason, status, building_id, createdAt, updatedAt
+		       ap_account_id, payment_account_id, unit_id, people_id, user_id, amount, amount_cents,
+		       description, cancel_reason, status, approval_status, building_id,
+		       currency, exchange_rate_scaled, foreign_amount_cents, createdAt, updatedAt
 		FROM bills
 		WHERE building_id = ?
 	`
@@ -81,6 +88,7 @@ func (s *BillStore) GetAll(ctx context.Context, buildingID int64, startDate, end
 			&b.BillDate,
 			&b.DueDate,
 			&b.APAccountID,
+			&b.PaymentAccountID,
 			&b.UnitID,
 			&b.PeopleID,
 			&b.UserID,
@@ -89,7 +97,11 @@ func (s *BillStore) GetAll(ctx context.Context, buildingID int64, startDate, end
 			&b.Description,
 			&b.CancelReason,
 			&b.Status,
+			&b.ApprovalStatus,
 			&b.BuildingID,
+			&b.Currency,
+			&b.ExchangeRateScaled,
+			&b.ForeignAmountCents,
 			&b.CreatedAt,
 			&b.UpdatedAt,
 		); err != nil {
@@ -104,8 +116,9 @@ func (s *BillStore) GetAll(ctx context.Context, buildingID int64, startDate, end
 func (s *BillStore) GetByID(ctx context.Context, id int64) (*Bill, error) {
 	query := `
 		SELECT id, bill_no, transaction_id, bill_date, due_date,
-		       ap_account_id, unit_id, people_id, user_id, amount, amount_cents,
-		       description, cancel_reason, status, building_id, createdAt, updatedAt
+		       ap_account_id, payment_account_id, unit_id, people_id, user_id, amount, amount_cents,
+		       description, cancel_reason, status, approval_status, building_id,
+		       currency, exchange_rate_scaled, foreign_amount_cents, createdAt, updatedAt
 		FROM bills
 		WHERE id = ?
 	`
@@ -120,6 +133,7 @@ func (s *BillStore) GetByID(ctx context.Context, id int64) (*Bill, error) {
 		&b.BillDate,
 		&b.DueDate,
 		&b.APAccountID,
+		&b.PaymentAccountID,
 		&b.UnitID,
 		&b.PeopleID,
 		&b.UserID,
@@ -128,7 +142,11 @@ func (s *BillStore) GetByID(ctx context.Context, id int64) (*Bill, error) {
 		&b.Description,
 		&b.CancelReason,
 		&b.Status,
+		&b.ApprovalStatus,
 		&b.BuildingID,
+		&b.Currency,
+		&b.ExchangeRateScaled,
+		&b.ForeignAmountCents,
 		&b.CreatedAt,
 		&b.UpdatedAt,
 	)
@@ -145,9 +163,10 @@ func (s *BillStore) Create(ctx context.Context, tx *sql.Tx, b *Bill) (*int64, er
 	query := `
 		INSERT INTO bills
 		(bill_no, transaction_id, bill_date, due_date,
-		 ap_account_id, unit_id, people_id, user_id, amount, amount_cents,
-		 description, cancel_reason, status, building_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, "1", ?)
+		 ap_account_id, payment_account_id, unit_id, people_id, user_id, amount, amount_cents,
+		 description, cancel_reason, status, approval_status, building_id,
+		 currency, exchange_rate_scaled, foreign_amount_cents)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, "1", ?, ?, ?, ?, ?)
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
@@ -159,6 +178,7 @@ func (s *BillStore) Create(ctx context.Context, tx *sql.Tx, b *Bill) (*int64, er
 		b.BillDate,
 		b.DueDate,
 		b.APAccountID,
+		b.PaymentAccountID,
 		b.UnitID,
 		b.PeopleID,
 		b.UserID,
@@ -166,7 +186,11 @@ func (s *BillStore) Create(ctx context.Context, tx *sql.Tx, b *Bill) (*int64, er
 		b.AmountCents,
 		b.Description,
 		b.CancelReason,
+		b.ApprovalStatus,
 		b.BuildingID,
+		b.Currency,
+		b.ExchangeRateScaled,
+		b.ForeignAmountCents,
 	)
 	if err != nil {
 		return nil, err
@@ -185,8 +209,9 @@ func (s *BillStore) Update(ctx context.Context, tx *sql.Tx, b *Bill) (*int64, er
 	query := `
 		UPDATE bills
 		SET bill_no = ?, bill_date = ?, due_date = ?,
-		    ap_account_id = ?, unit_id = ?, people_id = ?, user_id = ?,
-		    amount = ?, amount_cents = ?, description = ?, cancel_reason = ?, status = ?, building_id = ?
+		    ap_account_id = ?, payment_account_id = ?, unit_id = ?, people_id = ?, user_id = ?,
+		    amount = ?, amount_cents = ?, description = ?, cancel_reason = ?, status = ?, approval_status = ?, building_id = ?,
+		    currency = ?, exchange_rate_scaled = ?, foreign_amount_cents = ?
 		WHERE id = ?
 	`
 
@@ -198,6 +223,7 @@ func (s *BillStore) Update(ctx context.Context, tx *sql.Tx, b *Bill) (*int64, er
 		b.BillDate,
 		b.DueDate,
 		b.APAccountID,
+		b.PaymentAccountID,
 		b.UnitID,
 		b.PeopleID,
 		b.UserID,
@@ -206,7 +232,11 @@ func (s *BillStore) Update(ctx context.Context, tx *sql.Tx, b *Bill) (*int64, er
 		b.Description,
 		b.CancelReason,
 		b.Status,
+		b.ApprovalStatus,
 		b.BuildingID,
+		b.Currency,
+		b.ExchangeRateScaled,
+		b.ForeignAmountCents,
 		b.ID,
 	)
 	if err != nil {
@@ -237,3 +267,27 @@ func (s *BillStore) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+// GetOpenBalanceTx returns amount less payments and applied vendor credits, locking the bill row so concurrent payments see each other
+func (s *BillStore) GetOpenBalanceTx(ctx context.Context, tx *sql.Tx, id int64) (int64, error) {
+	query := `
+		SELECT b.amount_cents
+			- COALESCE((SELECT SUM(bp.amount_cents) FROM bill_payments bp WHERE bp.bill_id = b.id AND bp.status = '1'), 0)
+			- COALESCE((SELECT SUM(bac.amount_cents) FROM bill_applied_credits bac WHERE bac.bill_id = b.id AND bac.status = '1'), 0)
+		FROM bills b
+		WHERE b.id = ?
+		FOR UPDATE
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var balance int64
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&balance); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+
+	return balance, nil
+}
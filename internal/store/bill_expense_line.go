@@ -6,14 +6,20 @@ import (
 )
 
 type BillExpenseLine struct {
-	ID          int64   `json:"id"`
-	BillID      int64   `json:"bill_id"`
-	AccountID   int64   `json:"account_id"`
-	UnitID      *int64  `json:"unit_id"`
-	PeopleID    *int64  `json:"people_id"`
-	Description *string `json:"description"`
-	Amount      float64 `json:"amount"`
-	AmountCents int64   `json:"amount_cents"`
+	ID           int64   `json:"id"`
+	BillID       int64   `json:"bill_id"`
+	AccountID    int64   `json:"account_id"`
+	UnitID       *int64  `json:"unit_id"`
+	PeopleID     *int64  `json:"people_id"`
+	Description  *string `json:"description"`
+	Amount       float64 `json:"amount"`
+	AmountCents  int64   `json:"amount_cents"`
+	POLineID     *int64  `json:"po_line_id"` // purchase order line the amount is billed against
+	QtyScaled    *int64  `json:"qty_scaled"`
+	ItemID       *int64  `json:"item_id"` // inventory items are received into stock when the bill posts
+	TaxCodeID    *int64  `json:"tax_code_id"`
+	TaxableCents int64   `json:"taxable_cents"` // net of tax
+	TaxCents     int64   `json:"tax_cents"`     // input tax reclaimed on the line
 }
 
 type BillExpenseLineStore struct {
@@ -26,7 +32,8 @@ func NewBillExpenseLineStore(db *sql.DB) *BillExpenseLineStore {
 
 func (s *BillExpenseLineStore) GetAllByBillID(ctx context.Context, billID int64) ([]BillExpenseLine, error) {
 	query := `
-		SELECT id, bill_id, account_id, unit_id, people_id, description, amount, amount_cents
+		SELECT id, bill_id, account_id, unit_id, people_id, description, amount, amount_cents,
+		       po_line_id, qty_scaled, item_id, tax_code_id, taxable_cents, tax_cents
 		FROM bill_expense_lines
 		WHERE bill_id = ?
 		ORDER BY id ASC
@@ -53,6 +60,12 @@ func (s *BillExpenseLineStore) GetAllByBillID(ctx context.Context, billID int64)
 			&l.Description,
 			&l.Amount,
 			&l.AmountCents,
+			&l.POLineID,
+			&l.QtyScaled,
+			&l.ItemID,
+			&l.TaxCodeID,
+			&l.TaxableCents,
+			&l.TaxCents,
 		); err != nil {
 			return nil, err
 		}
@@ -64,8 +77,9 @@ func (s *BillExpenseLineStore) GetAllByBillID(ctx context.Context, billID int64)
 func (s *BillExpenseLineStore) Create(ctx context.Context, tx *sql.Tx, l *BillExpenseLine) (*int64, error) {
 	query := `
 		INSERT INTO bill_expense_lines
-		(bill_id, account_id, unit_id, people_id, description, amount, amount_cents)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		(bill_id, account_id, unit_id, people_id, description, amount, amount_cents, po_line_id, qty_scaled, item_id,
+		 tax_code_id, taxable_cents, tax_cents)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
@@ -79,6 +93,12 @@ func (s *BillExpenseLineStore) Create(ctx context.Context, tx *sql.Tx, l *BillEx
 		l.Description,
 		l.Amount,
 		l.AmountCents,
+		l.POLineID,
+		l.QtyScaled,
+		l.ItemID,
+		l.TaxCodeID,
+		l.TaxableCents,
+		l.TaxCents,
 	)
 	if err != nil {
 		return nil, err
@@ -15,11 +15,25 @@ type BillPayment struct {
 	BillID    int64 `json:"bill_id"`
 	UserID    int64 `json:"user_id"`
 	AccountID int64 `json:"account_id"`
+	RunID     *int64 `json:"run_id"`   // set when paid by a bill payment run
+	CheckNo   *int   `json:"check_no"` // shared by every bill a run check covers
 
 	Amount float64 `json:"amount"`
 	AmountCents int64 `json:"amount_cents"`
 	Status string  `json:"status"` // enum('0','1')
 
+	// tax withheld from the vendor; the bank paid AmountCents - WithholdingCents
+	WithholdingRateScaled int64  `json:"withholding_rate_scaled"`
+	WithholdingCents      int64  `json:"withholding_cents"`
+	WithholdingAccountID  *int64 `json:"withholding_account_id"`
+
+	// set when a foreign currency bill is paid; AmountCents is the base currency taken off
+	// the bill and FXGainLossCents what the payment realized against it, a gain when positive
+	Currency           *string `json:"currency"`
+	ExchangeRateScaled *int64  `json:"exchange_rate_scaled"`
+	ForeignAmountCents *int64  `json:"foreign_amount_cents"`
+	FXGainLossCents    int64   `json:"fx_gain_loss_cents"`
+
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 }
@@ -35,8 +49,11 @@ func NewBillPaymentStore(db *sql.DB) *BillPaymentStore {
 func (s *BillPaymentStore) GetAll(ctx context.Context, buildingID int64, startDate *string, endDate *string, peopleID *int, status *string) ([]BillPayment, error) {
 	query := `
 		SELECT bp.id, bp.transaction_id, bp.reference, bp.date,
-		       bp.bill_id, bp.user_id, bp.account_id,
-		       bp.amount, bp.amount_cents, bp.status, bp.createdAt, bp.updatedAt
+		       bp.bill_id, bp.user_id, bp.account_id, bp.run_id, bp.check_no,
+		       bp.amount, bp.amount_cents, bp.status,
+		       bp.withholding_rate_scaled, bp.withholding_cents, bp.withholding_account_id,
+		       bp.currency, bp.exchange_rate_scaled, bp.foreign_amount_cents, bp.fx_gain_loss_cents,
+		       bp.createdAt, bp.updatedAt
 		FROM bill_payments bp
 		INNER JOIN bills b ON bp.bill_id = b.id
 		WHERE b.building_id = ?
@@ -82,9 +99,18 @@ func (s *BillPaymentStore) GetAll(ctx context.Context, buildingID int64, startDa
 			&p.BillID,
 			&p.UserID,
 			&p.AccountID,
+			&p.RunID,
+			&p.CheckNo,
 			&p.Amount,
 			&p.AmountCents,
 			&p.Status,
+			&p.WithholdingRateScaled,
+			&p.WithholdingCents,
+			&p.WithholdingAccountID,
+			&p.Currency,
+			&p.ExchangeRateScaled,
+			&p.ForeignAmountCents,
+			&p.FXGainLossCents,
 			&p.CreatedAt,
 			&p.UpdatedAt,
 		); err != nil {
@@ -99,8 +125,11 @@ func (s *BillPaymentStore) GetAll(ctx context.Context, buildingID int64, startDa
 func (s *BillPaymentStore) GetAllByBillID(ctx context.Context, billID int64) ([]BillPayment, error) {
 	query := `
 		SELECT id, transaction_id, reference, date,
-		       bill_id, user_id, account_id,
-		       amount, amount_cents, status, createdAt, updatedAt
+		       bill_id, user_id, account_id, run_id, check_no,
+		       amount, amount_cents, status,
+		       withholding_rate_scaled, withholding_cents, withholding_account_id,
+		       currency, exchange_rate_scaled, foreign_amount_cents, fx_gain_loss_cents,
+		       createdAt, updatedAt
 		FROM bill_payments
 		WHERE bill_id = ?
 		ORDER BY createdAt DESC
@@ -126,9 +155,18 @@ func (s *BillPaymentStore) GetAllByBillID(ctx context.Context, billID int64) ([]
 			&p.BillID,
 			&p.UserID,
 			&p.AccountID,
+			&p.RunID,
+			&p.CheckNo,
 			&p.Amount,
 			&p.AmountCents,
 			&p.Status,
+			&p.WithholdingRateScaled,
+			&p.WithholdingCents,
+			&p.WithholdingAccountID,
+			&p.Currency,
+			&p.ExchangeRateScaled,
+			&p.ForeignAmountCents,
+			&p.FXGainLossCents,
 			&p.CreatedAt,
 			&p.UpdatedAt,
 		); err != nil {
@@ -142,8 +180,11 @@ func (s *BillPaymentStore) GetAllByBillID(ctx context.Context, billID int64) ([]
 func (s *BillPaymentStore) GetByID(ctx context.Context, id int64) (*BillPayment, error) {
 	query := `
 		SELECT id, transaction_id, reference, date,
-		       bill_id, user_id, account_id,
-		       amount, amount_cents, status, createdAt, updatedAt
+		       bill_id, user_id, account_id, run_id, check_no,
+		       amount, amount_cents, status,
+		       withholding_rate_scaled, withholding_cents, withholding_account_id,
+		       currency, exchange_rate_scaled, foreign_amount_cents, fx_gain_loss_cents,
+		       createdAt, updatedAt
 		FROM bill_payments
 		WHERE id = ?
 	`
@@ -160,9 +201,18 @@ func (s *BillPaymentStore) GetByID(ctx context.Context, id int64) (*BillPayment,
 		&p.BillID,
 		&p.UserID,
 		&p.AccountID,
+		&p.RunID,
+		&p.CheckNo,
 		&p.Amount,
 		&p.AmountCents,
 		&p.Status,
+		&p.WithholdingRateScaled,
+		&p.WithholdingCents,
+		&p.WithholdingAccountID,
+		&p.Currency,
+		&p.ExchangeRateScaled,
+		&p.ForeignAmountCents,
+		&p.FXGainLossCents,
 		&p.CreatedAt,
 		&p.UpdatedAt,
 	)
@@ -178,8 +228,11 @@ func (s *BillPaymentStore) GetByID(ctx context.Context, id int64) (*BillPayment,
 func (s *BillPaymentStore) GetByIDTx(ctx context.Context, tx *sql.Tx, id int64) (*BillPayment, error) {
 	query := `
 		SELECT id, transaction_id, reference, date,
-		       bill_id, user_id, account_id,
-		       amount, amount_cents, status, createdAt, updatedAt
+		       bill_id, user_id, account_id, run_id, check_no,
+		       amount, amount_cents, status,
+		       withholding_rate_scaled, withholding_cents, withholding_account_id,
+		       currency, exchange_rate_scaled, foreign_amount_cents, fx_gain_loss_cents,
+		       createdAt, updatedAt
 		FROM bill_payments
 		WHERE id = ?
 	`
@@ -196,9 +249,18 @@ func (s *BillPaymentStore) GetByIDTx(ctx context.Context, tx *sql.Tx, id int64)
 		&p.BillID,
 		&p.UserID,
 		&p.AccountID,
+		&p.RunID,
+		&p.CheckNo,
 		&p.Amount,
 		&p.AmountCents,
 		&p.Status,
+		&p.WithholdingRateScaled,
+		&p.WithholdingCents,
+		&p.WithholdingAccountID,
+		&p.Currency,
+		&p.ExchangeRateScaled,
+		&p.ForeignAmountCents,
+		&p.FXGainLossCents,
 		&p.CreatedAt,
 		&p.UpdatedAt,
 	)
@@ -214,8 +276,10 @@ func (s *BillPaymentStore) GetByIDTx(ctx context.Context, tx *sql.Tx, id int64)
 func (s *BillPaymentStore) Create(ctx context.Context, tx *sql.Tx, p *BillPayment) (*BillPayment, error) {
 	query := `
 		INSERT INTO bill_payments
-		(transaction_id, reference, date, bill_id, user_id, account_id, amount, amount_cents, status)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, "1")
+		(transaction_id, reference, date, bill_id, user_id, account_id, run_id, check_no, amount, amount_cents, status,
+		 withholding_rate_scaled, withholding_cents, withholding_account_id,
+		 currency, exchange_rate_scaled, foreign_amount_cents, fx_gain_loss_cents)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, "1", ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
@@ -228,8 +292,17 @@ func (s *BillPaymentStore) Create(ctx context.Context, tx *sql.Tx, p *BillPaymen
 		p.BillID,
 		p.UserID,
 		p.AccountID,
+		p.RunID,
+		p.CheckNo,
 		p.Amount,
 		p.AmountCents,
+		p.WithholdingRateScaled,
+		p.WithholdingCents,
+		p.WithholdingAccountID,
+		p.Currency,
+		p.ExchangeRateScaled,
+		p.ForeignAmountCents,
+		p.FXGainLossCents,
 	)
 	if err != nil {
 		return nil, err
@@ -247,7 +320,8 @@ func (s *BillPaymentStore) Create(ctx context.Context, tx *sql.Tx, p *BillPaymen
 func (s *BillPaymentStore) Update(ctx context.Context, tx *sql.Tx, p *BillPayment) (*BillPayment, error) {
 	query := `
 		UPDATE bill_payments
-		SET reference = ?, date = ?, account_id = ?, amount = ?, amount_cents = ?, status = ?
+		SET reference = ?, date = ?, account_id = ?, amount = ?, amount_cents = ?, status = ?,
+		    withholding_cents = ?
 		WHERE id = ?
 	`
 
@@ -261,6 +335,7 @@ func (s *BillPaymentStore) Update(ctx context.Context, tx *sql.Tx, p *BillPaymen
 		p.Amount,
 		p.AmountCents,
 		p.Status,
+		p.WithholdingCents,
 		p.ID,
 	)
 	if err != nil {
@@ -0,0 +1,321 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+// BillPaymentRun pays a batch of open bills from one bank account, one check per vendor
+type BillPaymentRun struct {
+	ID              int64   `json:"id"`
+	BuildingID      int64   `json:"building_id"`
+	BankAccountID   int64   `json:"bank_account_id"`
+	BankAccountName string  `json:"bank_account_name"`
+	Date            string  `json:"date"`
+	DueOnOrBefore   *string `json:"due_on_or_before"`
+	Memo            string  `json:"memo"`
+	PaymentCount    int     `json:"payment_count"`
+	AmountCents     int64   `json:"amount_cents"`
+	UserID          int64   `json:"user_id"`
+	CreatedAt       string  `json:"created_at"`
+}
+
+// PayableBill is an open vendor bill with what is left to pay on it
+type PayableBill struct {
+	BillID       int64
+	BillNo       string
+	BillDate     string
+	DueDate      string
+	Description  string
+	APAccountID  int64
+	UnitID       *int64
+	PeopleID     int64
+	PeopleName   string
+	AmountCents  int64
+	BalanceCents int64
+}
+
+// BillPaymentRunLine is one bill paid by a run check
+type BillPaymentRunLine struct {
+	PaymentID     int64
+	TransactionID int64
+	CheckNo       int
+	Date          string
+	PeopleID      int64
+	PeopleName    string
+	BillID        int64
+	BillNo        string
+	BillDate      string
+	DueDate       string
+	Description   string
+	BillCents     int64
+	PaidCents     int64
+	WithheldCents int64
+}
+
+type BillPaymentRunStore struct {
+	db *sql.DB
+}
+
+func NewBillPaymentRunStore(db *sql.DB) *BillPaymentRunStore {
+	return &BillPaymentRunStore{db: db}
+}
+
+const billPaymentRunColumns = `
+	r.id, r.building_id, r.bank_account_id, a.account_name, DATE_FORMAT(r.date, '%Y-%m-%d'),
+	DATE_FORMAT(r.due_on_or_before, '%Y-%m-%d'), r.memo, r.payment_count, r.amount_cents,
+	r.user_id, r.created_at
+`
+
+func scanBillPaymentRun(scan func(dest ...any) error, r *BillPaymentRun) error {
+	return scan(
+		&r.ID,
+		&r.BuildingID,
+		&r.BankAccountID,
+		&r.BankAccountName,
+		&r.Date,
+		&r.DueOnOrBefore,
+		&r.Memo,
+		&r.PaymentCount,
+		&r.AmountCents,
+		&r.UserID,
+		&r.CreatedAt,
+	)
+}
+
+func (s *BillPaymentRunStore) GetAll(ctx context.Context, buildingID int64) ([]BillPaymentRun, error) {
+	query := `
+		SELECT ` + billPaymentRunColumns + `
+		FROM bill_payment_runs r
+		JOIN accounts a ON a.id = r.bank_account_id
+		WHERE r.building_id = ?
+		ORDER BY r.date DESC, r.id DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, buildingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []BillPaymentRun
+	for rows.Next() {
+		var r BillPaymentRun
+		if err := scanBillPaymentRun(rows.Scan, &r); err != nil {
+			return nil, err
+		}
+		runs = append(runs, r)
+	}
+
+	return runs, nil
+}
+
+func (s *BillPaymentRunStore) GetByID(ctx context.Context, id int64) (*BillPaymentRun, error) {
+	query := `
+		SELECT ` + billPaymentRunColumns + `
+		FROM bill_payment_runs r
+		JOIN accounts a ON a.id = r.bank_account_id
+		WHERE r.id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var r BillPaymentRun
+	if err := scanBillPaymentRun(s.db.QueryRowContext(ctx, query, id).Scan, &r); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// GetPayableBills returns active vendor bills with a balance left, optionally due on or before a date
+func (s *BillPaymentRunStore) GetPayableBills(ctx context.Context, buildingID int64, dueOnOrBefore *string) ([]PayableBill, error) {
+	query := `
+		SELECT b.id, b.bill_no, DATE_FORMAT(b.bill_date, '%Y-%m-%d'), DATE_FORMAT(b.due_date, '%Y-%m-%d'),
+			b.description, b.ap_account_id, b.unit_id, b.people_id, p.name, b.amount_cents,
+			b.amount_cents
+				- COALESCE((SELECT SUM(bp.amount_cents) FROM bill_payments bp WHERE bp.bill_id = b.id AND bp.status = '1'), 0)
+				- COALESCE((SELECT SUM(bac.amount_cents) FROM bill_applied_credits bac WHERE bac.bill_id = b.id AND bac.status = '1'), 0)
+				AS balance_cents
+		FROM bills b
+		JOIN people p ON p.id = b.people_id
+		WHERE b.building_id = ? AND b.status = '1' AND b.approval_status = 'approved'
+			AND b.currency IS NULL -- foreign currency bills are paid one at a time
+	`
+
+	args := []any{buildingID}
+
+	if dueOnOrBefore != nil {
+		query += " AND b.due_date <= ?"
+		args = append(args, *dueOnOrBefore)
+	}
+
+	query += " HAVING balance_cents > 0 ORDER BY p.name, p.id, b.due_date, b.id"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bills []PayableBill
+	for rows.Next() {
+		var b PayableBill
+		if err := rows.Scan(
+			&b.BillID,
+			&b.BillNo,
+			&b.BillDate,
+			&b.DueDate,
+			&b.Description,
+			&b.APAccountID,
+			&b.UnitID,
+			&b.PeopleID,
+			&b.PeopleName,
+			&b.AmountCents,
+			&b.BalanceCents,
+		); err != nil {
+			return nil, err
+		}
+		bills = append(bills, b)
+	}
+
+	return bills, nil
+}
+
+// NextCheckNoTx locks the bank account row so runs against it number their checks one at a time,
+// and returns the number after the highest check already written from it
+func (s *BillPaymentRunStore) NextCheckNoTx(ctx context.Context, tx *sql.Tx, accountID int64) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var id int64
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM accounts WHERE id = ? FOR UPDATE`, accountID).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+
+	var last int
+	query := `SELECT COALESCE(MAX(check_no), 0) FROM bill_payments WHERE account_id = ? AND status = '1'`
+	if err := tx.QueryRowContext(ctx, query, accountID).Scan(&last); err != nil {
+		return 0, err
+	}
+
+	return last + 1, nil
+}
+
+// CheckNosUsedTx reports whether any check number in [from, to] was already written from the account
+func (s *BillPaymentRunStore) CheckNosUsedTx(ctx context.Context, tx *sql.Tx, accountID int64, from, to int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM bill_payments
+			WHERE account_id = ? AND status = '1' AND check_no BETWEEN ? AND ?
+		)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var used bool
+	if err := tx.QueryRowContext(ctx, query, accountID, from, to).Scan(&used); err != nil {
+		return false, err
+	}
+
+	return used, nil
+}
+
+func (s *BillPaymentRunStore) Create(ctx context.Context, tx *sql.Tx, r *BillPaymentRun) error {
+	query := `
+		INSERT INTO bill_payment_runs
+		(building_id, bank_account_id, date, due_on_or_before, memo, payment_count, amount_cents, user_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		r.BuildingID,
+		r.BankAccountID,
+		r.Date,
+		r.DueOnOrBefore,
+		r.Memo,
+		r.PaymentCount,
+		r.AmountCents,
+		r.UserID,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	r.ID = id
+	return nil
+}
+
+// GetLines returns the bills paid by a run in check order
+func (s *BillPaymentRunStore) GetLines(ctx context.Context, runID int64) ([]BillPaymentRunLine, error) {
+	query := `
+		SELECT bp.id, bp.transaction_id, bp.check_no, DATE_FORMAT(bp.date, '%Y-%m-%d'),
+			b.people_id, p.name, b.id, b.bill_no, DATE_FORMAT(b.bill_date, '%Y-%m-%d'),
+			DATE_FORMAT(b.due_date, '%Y-%m-%d'), b.description, b.amount_cents, bp.amount_cents,
+			bp.withholding_cents
+		FROM bill_payments bp
+		JOIN bills b ON b.id = bp.bill_id
+		JOIN people p ON p.id = b.people_id
+		WHERE bp.run_id = ? AND bp.status = '1'
+		ORDER BY bp.check_no, b.due_date, b.id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []BillPaymentRunLine
+	for rows.Next() {
+		var l BillPaymentRunLine
+		if err := rows.Scan(
+			&l.PaymentID,
+			&l.TransactionID,
+			&l.CheckNo,
+			&l.Date,
+			&l.PeopleID,
+			&l.PeopleName,
+			&l.BillID,
+			&l.BillNo,
+			&l.BillDate,
+			&l.DueDate,
+			&l.Description,
+			&l.BillCents,
+			&l.PaidCents,
+			&l.WithheldCents,
+		); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+
+	return lines, nil
+}
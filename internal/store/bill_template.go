@@ -0,0 +1,311 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+// BillTemplate is a fixed vendor contract billed the same amount on a schedule
+type BillTemplate struct {
+	ID            int64   `json:"id"`
+	BuildingID    int64   `json:"building_id"`
+	Name          string  `json:"name"`
+	PeopleID      int64   `json:"people_id"`
+	PeopleName    string  `json:"people_name"`
+	APAccountID   int64   `json:"ap_account_id"`
+	UnitID        *int64  `json:"unit_id"`
+	Description   string  `json:"description"`
+	Frequency     string  `json:"frequency"`      // monthly | quarterly | yearly
+	IntervalCount int     `json:"interval_count"` // every n frequencies, e.g. 2 + monthly = every other month
+	DayOfMonth    int     `json:"day_of_month"`   // clamped to the last day of shorter months
+	DueDays       int     `json:"due_days"`       // days from bill date to due date
+	StartDate     string  `json:"start_date"`
+	EndDate       *string `json:"end_date"`
+	AsDraft       string  `json:"as_draft"`    // enum('0','1')
+	Status        string  `json:"status"`      // enum('0','1')
+	LastPeriod    *string `json:"last_period"` // latest period with a run, YYYY-MM
+	CreatedAt     string  `json:"created_at"`
+	UpdatedAt     string  `json:"updated_at"`
+}
+
+type BillTemplateLine struct {
+	ID          int64   `json:"id"`
+	TemplateID  int64   `json:"template_id"`
+	AccountID   int64   `json:"account_id"`
+	UnitID      *int64  `json:"unit_id"`
+	PeopleID    *int64  `json:"people_id"`
+	Description *string `json:"description"`
+	AmountCents int64   `json:"amount_cents"`
+}
+
+type BillTemplateStore struct {
+	db *sql.DB
+}
+
+func NewBillTemplateStore(db *sql.DB) *BillTemplateStore {
+	return &BillTemplateStore{db: db}
+}
+
+const billTemplateColumns = `
+	t.id, t.building_id, t.name, t.people_id, p.name, t.ap_account_id, t.unit_id,
+	COALESCE(t.description, ''), t.frequency, t.interval_count, t.day_of_month, t.due_days,
+	DATE_FORMAT(t.start_date, '%Y-%m-%d'), DATE_FORMAT(t.end_date, '%Y-%m-%d'), t.as_draft, t.status,
+	(SELECT MAX(r.period) FROM bill_template_runs r WHERE r.template_id = t.id),
+	t.created_at, t.updated_at
+`
+
+func scanBillTemplate(scan func(dest ...any) error, t *BillTemplate) error {
+	return scan(
+		&t.ID,
+		&t.BuildingID,
+		&t.Name,
+		&t.PeopleID,
+		&t.PeopleName,
+		&t.APAccountID,
+		&t.UnitID,
+		&t.Description,
+		&t.Frequency,
+		&t.IntervalCount,
+		&t.DayOfMonth,
+		&t.DueDays,
+		&t.StartDate,
+		&t.EndDate,
+		&t.AsDraft,
+		&t.Status,
+		&t.LastPeriod,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+}
+
+func (s *BillTemplateStore) GetAll(ctx context.Context, buildingID int64, activeOnly bool) ([]BillTemplate, error) {
+	query := `
+		SELECT ` + billTemplateColumns + `
+		FROM bill_templates t
+		JOIN people p ON p.id = t.people_id
+		WHERE t.building_id = ?
+	`
+
+	if activeOnly {
+		query += " AND t.status = '1'"
+	}
+
+	query += " ORDER BY t.name, t.id"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, buildingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []BillTemplate
+	for rows.Next() {
+		var t BillTemplate
+		if err := scanBillTemplate(rows.Scan, &t); err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+
+	return templates, nil
+}
+
+func (s *BillTemplateStore) GetByID(ctx context.Context, id int64) (*BillTemplate, error) {
+	query := `
+		SELECT ` + billTemplateColumns + `
+		FROM bill_templates t
+		JOIN people p ON p.id = t.people_id
+		WHERE t.id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var t BillTemplate
+	if err := scanBillTemplate(s.db.QueryRowContext(ctx, query, id).Scan, &t); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (s *BillTemplateStore) Create(ctx context.Context, tx *sql.Tx, t *BillTemplate) error {
+	query := `
+		INSERT INTO bill_templates
+		(building_id, name, people_id, ap_account_id, unit_id, description, frequency, interval_count,
+		 day_of_month, due_days, start_date, end_date, as_draft, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		t.BuildingID,
+		t.Name,
+		t.PeopleID,
+		t.APAccountID,
+		t.UnitID,
+		t.Description,
+		t.Frequency,
+		t.IntervalCount,
+		t.DayOfMonth,
+		t.DueDays,
+		t.StartDate,
+		t.EndDate,
+		t.AsDraft,
+		t.Status,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	t.ID = id
+	return nil
+}
+
+func (s *BillTemplateStore) Update(ctx context.Context, tx *sql.Tx, t *BillTemplate) error {
+	query := `
+		UPDATE bill_templates
+		SET name = ?, people_id = ?, ap_account_id = ?, unit_id = ?, description = ?, frequency = ?,
+		    interval_count = ?, day_of_month = ?, due_days = ?, start_date = ?, end_date = ?,
+		    as_draft = ?, status = ?
+		WHERE id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		t.Name,
+		t.PeopleID,
+		t.APAccountID,
+		t.UnitID,
+		t.Description,
+		t.Frequency,
+		t.IntervalCount,
+		t.DayOfMonth,
+		t.DueDays,
+		t.StartDate,
+		t.EndDate,
+		t.AsDraft,
+		t.Status,
+		t.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *BillTemplateStore) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM bill_templates WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *BillTemplateStore) GetLines(ctx context.Context, templateID int64) ([]BillTemplateLine, error) {
+	query := `
+		SELECT id, template_id, account_id, unit_id, people_id, description, amount_cents
+		FROM bill_template_lines
+		WHERE template_id = ?
+		ORDER BY id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []BillTemplateLine
+	for rows.Next() {
+		var l BillTemplateLine
+		if err := rows.Scan(
+			&l.ID,
+			&l.TemplateID,
+			&l.AccountID,
+			&l.UnitID,
+			&l.PeopleID,
+			&l.Description,
+			&l.AmountCents,
+		); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+
+	return lines, nil
+}
+
+func (s *BillTemplateStore) CreateLine(ctx context.Context, tx *sql.Tx, l *BillTemplateLine) error {
+	query := `
+		INSERT INTO bill_template_lines (template_id, account_id, unit_id, people_id, description, amount_cents)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, l.TemplateID, l.AccountID, l.UnitID, l.PeopleID, l.Description, l.AmountCents)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	l.ID = id
+	return nil
+}
+
+func (s *BillTemplateStore) DeleteLines(ctx context.Context, tx *sql.Tx, templateID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, `DELETE FROM bill_template_lines WHERE template_id = ?`, templateID)
+	return err
+}
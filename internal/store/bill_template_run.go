@@ -0,0 +1,214 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+// BillTemplateRun records the bill generated from a template for one period
+type BillTemplateRun struct {
+	ID           int64   `json:"id"`
+	TemplateID   int64   `json:"template_id"`
+	TemplateName string  `json:"template_name"`
+	PeopleName   string  `json:"people_name"`
+	Period       string  `json:"period"` // YYYY-MM
+	BillNo       string  `json:"bill_no"`
+	BillDate     string  `json:"bill_date"`
+	DueDate      string  `json:"due_date"`
+	AmountCents  int64   `json:"amount_cents"`
+	BillID       *int64  `json:"bill_id"` // set once posted
+	Status       string  `json:"status"`  // draft | posted | rejected
+	ReviewedBy   *int64  `json:"reviewed_by"`
+	ReviewedAt   *string `json:"reviewed_at"`
+	BuildingID   int64   `json:"building_id"`
+	CreatedAt    string  `json:"created_at"`
+}
+
+type BillTemplateRunStore struct {
+	db *sql.DB
+}
+
+func NewBillTemplateRunStore(db *sql.DB) *BillTemplateRunStore {
+	return &BillTemplateRunStore{db: db}
+}
+
+const billTemplateRunColumns = `
+	r.id, r.template_id, t.name, p.name, r.period, r.bill_no, DATE_FORMAT(r.bill_date, '%Y-%m-%d'),
+	DATE_FORMAT(r.due_date, '%Y-%m-%d'), r.amount_cents, r.bill_id, r.status, r.reviewed_by,
+	r.reviewed_at, r.building_id, r.created_at
+`
+
+func scanBillTemplateRun(scan func(dest ...any) error, r *BillTemplateRun) error {
+	return scan(
+		&r.ID,
+		&r.TemplateID,
+		&r.TemplateName,
+		&r.PeopleName,
+		&r.Period,
+		&r.BillNo,
+		&r.BillDate,
+		&r.DueDate,
+		&r.AmountCents,
+		&r.BillID,
+		&r.Status,
+		&r.ReviewedBy,
+		&r.ReviewedAt,
+		&r.BuildingID,
+		&r.CreatedAt,
+	)
+}
+
+func (s *BillTemplateRunStore) GetAll(ctx context.Context, buildingID int64, templateID *int64, status *string) ([]BillTemplateRun, error) {
+	query := `
+		SELECT ` + billTemplateRunColumns + `
+		FROM bill_template_runs r
+		JOIN bill_templates t ON t.id = r.template_id
+		JOIN people p ON p.id = t.people_id
+		WHERE r.building_id = ?
+	`
+
+	args := []any{buildingID}
+
+	if templateID != nil {
+		query += " AND r.template_id = ?"
+		args = append(args, *templateID)
+	}
+
+	if status != nil && *status != "" {
+		query += " AND r.status = ?"
+		args = append(args, *status)
+	}
+
+	query += " ORDER BY r.bill_date DESC, r.id DESC"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []BillTemplateRun
+	for rows.Next() {
+		var r BillTemplateRun
+		if err := scanBillTemplateRun(rows.Scan, &r); err != nil {
+			return nil, err
+		}
+		runs = append(runs, r)
+	}
+
+	return runs, nil
+}
+
+func (s *BillTemplateRunStore) GetByID(ctx context.Context, id int64) (*BillTemplateRun, error) {
+	query := `
+		SELECT ` + billTemplateRunColumns + `
+		FROM bill_template_runs r
+		JOIN bill_templates t ON t.id = r.template_id
+		JOIN people p ON p.id = t.people_id
+		WHERE r.id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var r BillTemplateRun
+	if err := scanBillTemplateRun(s.db.QueryRowContext(ctx, query, id).Scan, &r); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// GetByIDTx locks the run so a draft is approved or rejected only once
+func (s *BillTemplateRunStore) GetByIDTx(ctx context.Context, tx *sql.Tx, id int64) (*BillTemplateRun, error) {
+	query := `
+		SELECT ` + billTemplateRunColumns + `
+		FROM bill_template_runs r
+		JOIN bill_templates t ON t.id = r.template_id
+		JOIN people p ON p.id = t.people_id
+		WHERE r.id = ?
+		FOR UPDATE
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var r BillTemplateRun
+	if err := scanBillTemplateRun(tx.QueryRowContext(ctx, query, id).Scan, &r); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+func (s *BillTemplateRunStore) Create(ctx context.Context, tx *sql.Tx, r *BillTemplateRun) error {
+	query := `
+		INSERT INTO bill_template_runs
+		(template_id, period, bill_no, bill_date, due_date, amount_cents, bill_id, status, building_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		r.TemplateID,
+		r.Period,
+		r.BillNo,
+		r.BillDate,
+		r.DueDate,
+		r.AmountCents,
+		r.BillID,
+		r.Status,
+		r.BuildingID,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	r.ID = id
+	return nil
+}
+
+// Review records the outcome of a draft run and who decided it
+func (s *BillTemplateRunStore) Review(ctx context.Context, tx *sql.Tx, r *BillTemplateRun) error {
+	query := `
+		UPDATE bill_template_runs
+		SET status = ?, bill_id = ?, amount_cents = ?, reviewed_by = ?, reviewed_at = NOW()
+		WHERE id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, r.Status, r.BillID, r.AmountCents, r.ReviewedBy, r.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
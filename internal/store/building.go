@@ -9,6 +9,17 @@ import (
 type Building struct {
 	ID        int64     `json:"id"`
 	Name      string    `json:"name"`
+	// payments above the open balance are rejected unless AllowOverpayment is set;
+	// the excess is then held in the customer deposits / vendor prepayments account
+	AllowOverpayment           bool   `json:"allow_overpayment"`
+	CustomerDepositsAccountID  *int64 `json:"customer_deposits_account_id"`
+	VendorPrepaymentsAccountID *int64 `json:"vendor_prepayments_account_id"`
+	// payments and receipts posted to this clearing account wait there until banked by a deposit
+	UndepositedFundsAccountID *int64 `json:"undeposited_funds_account_id"`
+	// every posted amount is in the base currency; realized and unrealized exchange
+	// differences on foreign currency documents and accounts go to FXGainLossAccountID
+	BaseCurrency        string `json:"base_currency"`
+	FXGainLossAccountID *int64 `json:"fx_gain_loss_account_id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -19,7 +30,7 @@ type BuildingStore struct {
 
 func (s *BuildingStore) GetAll(ctx context.Context) ([]Building, error) {
 	query := `
-		SELECT id, name, created_at, updated_at
+		SELECT id, name, allow_overpayment, customer_deposits_account_id, vendor_prepayments_account_id, undeposited_funds_account_id, base_currency, fx_gain_loss_account_id, created_at, updated_at
 		FROM buildings
 	`
 
@@ -35,7 +46,7 @@ func (s *BuildingStore) GetAll(ctx context.Context) ([]Building, error) {
 	var buildings []Building
 	for rows.Next() {
 		var b Building
-		if err := rows.Scan(&b.ID, &b.Name, &b.CreatedAt, &b.UpdatedAt); err != nil {
+		if err := rows.Scan(&b.ID, &b.Name, &b.AllowOverpayment, &b.CustomerDepositsAccountID, &b.VendorPrepaymentsAccountID, &b.UndepositedFundsAccountID, &b.BaseCurrency, &b.FXGainLossAccountID, &b.CreatedAt, &b.UpdatedAt); err != nil {
 			return nil, err
 		}
 		buildings = append(buildings, b)
@@ -46,7 +57,7 @@ func (s *BuildingStore) GetAll(ctx context.Context) ([]Building, error) {
 
 func (s *BuildingStore) GetAllByUserID(ctx context.Context, userID int64) ([]Building, error) {
 	query := `
-		SELECT b.id, b.name, b.created_at, b.updated_at
+		SELECT b.id, b.name, b.allow_overpayment, b.customer_deposits_account_id, b.vendor_prepayments_account_id, b.undeposited_funds_account_id, b.base_currency, b.fx_gain_loss_account_id, b.created_at, b.updated_at
 		FROM buildings b
 		left join users_building ub on b.id = ub.building_id
 		WHERE ub.user_id = ?
@@ -63,7 +74,7 @@ func (s *BuildingStore) GetAllByUserID(ctx context.Context, userID int64) ([]Bui
 	var buildings []Building
 	for rows.Next() {
 		var b Building
-		if err := rows.Scan(&b.ID, &b.Name, &b.CreatedAt, &b.UpdatedAt); err != nil {
+		if err := rows.Scan(&b.ID, &b.Name, &b.AllowOverpayment, &b.CustomerDepositsAccountID, &b.VendorPrepaymentsAccountID, &b.UndepositedFundsAccountID, &b.BaseCurrency, &b.FXGainLossAccountID, &b.CreatedAt, &b.UpdatedAt); err != nil {
 			return nil, err
 		}
 		buildings = append(buildings, b)
@@ -74,7 +85,7 @@ func (s *BuildingStore) GetAllByUserID(ctx context.Context, userID int64) ([]Bui
 
 func (s *BuildingStore) GetByID(ctx context.Context, id int64) (*Building, error) {
 	query := `
-		SELECT id, name, created_at, updated_at
+		SELECT id, name, allow_overpayment, customer_deposits_account_id, vendor_prepayments_account_id, undeposited_funds_account_id, base_currency, fx_gain_loss_account_id, created_at, updated_at
 		FROM buildings
 		WHERE id = ?
 	`
@@ -83,7 +94,7 @@ func (s *BuildingStore) GetByID(ctx context.Context, id int64) (*Building, error
 	defer cancel()
 
 	var b Building
-	err := s.db.QueryRowContext(ctx, query, id).Scan(&b.ID, &b.Name, &b.CreatedAt, &b.UpdatedAt)
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&b.ID, &b.Name, &b.AllowOverpayment, &b.CustomerDepositsAccountID, &b.VendorPrepaymentsAccountID, &b.UndepositedFundsAccountID, &b.BaseCurrency, &b.FXGainLossAccountID, &b.CreatedAt, &b.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrNotFound
@@ -144,6 +155,77 @@ func (s *BuildingStore) Update(ctx context.Context, building *Building) error {
 	return nil
 }
 
+func (s *BuildingStore) UpdatePaymentSettings(ctx context.Context, building *Building) error {
+	query := `
+		UPDATE buildings
+		SET allow_overpayment = ?, customer_deposits_account_id = ?, vendor_prepayments_account_id = ?,
+		    undeposited_funds_account_id = ?,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(
+		ctx,
+		query,
+		building.AllowOverpayment,
+		building.CustomerDepositsAccountID,
+		building.VendorPrepaymentsAccountID,
+		building.UndepositedFundsAccountID,
+		building.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *BuildingStore) UpdateCurrencySettings(ctx context.Context, building *Building) error {
+	query := `
+		UPDATE buildings
+		SET base_currency = ?, fx_gain_loss_account_id = ?,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(
+		ctx,
+		query,
+		building.BaseCurrency,
+		building.FXGainLossAccountID,
+		building.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
 func (s *BuildingStore) Delete(ctx context.Context, id int64) error {
 	query := `
 		DELETE FROM buildings
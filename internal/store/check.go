@@ -15,6 +15,7 @@ type Check struct {
 	Memo             *string `json:"memo"`
 	TotalAmount      float64 `json:"total_amount"`
 	AmountCents      int64   `json:"amount_cents"`
+	ApprovalStatus   string  `json:"approval_status"` // draft | pending | approved | rejected, only approved checks have splits
 	CreatedAt        string  `json:"created_at"`
 }
 
@@ -32,7 +33,7 @@ func (s *CheckStore) GetAll(
 	startDate, endDate *string,
 ) ([]Check, error) {
 	query := `SELECT id, transaction_id, check_date, reference_number,
-                     payment_account_id, building_id, memo, total_amount, amount_cents, created_at
+                     payment_account_id, building_id, memo, total_amount, amount_cents, approval_status, created_at
               FROM checks
               WHERE building_id = ?`
 	args := []interface{}{buildingID}
@@ -70,6 +71,7 @@ func (s *CheckStore) GetAll(
 			&c.Memo,
 			&c.TotalAmount,
 			&c.AmountCents,
+			&c.ApprovalStatus,
 			&c.CreatedAt,
 		); err != nil {
 			return nil, err
@@ -82,7 +84,7 @@ func (s *CheckStore) GetAll(
 
 func (s *CheckStore) GetByID(ctx context.Context, id int64) (*Check, error) {
 	query := `SELECT id, transaction_id, check_date, reference_number,
-                     payment_account_id, building_id, memo, total_amount, amount_cents, created_at
+                     payment_account_id, building_id, memo, total_amount, amount_cents, approval_status, created_at
               FROM checks
               WHERE id = ?`
 
@@ -100,6 +102,7 @@ func (s *CheckStore) GetByID(ctx context.Context, id int64) (*Check, error) {
 		&c.Memo,
 		&c.TotalAmount,
 		&c.AmountCents,
+		&c.ApprovalStatus,
 		&c.CreatedAt,
 	)
 	if err != nil {
@@ -114,8 +117,8 @@ func (s *CheckStore) GetByID(ctx context.Context, id int64) (*Check, error) {
 
 func (s *CheckStore) Create(ctx context.Context, tx *sql.Tx, c *Check) (*int64, error) {
 	query := `INSERT INTO checks
-              (transaction_id, check_date, reference_number, payment_account_id, building_id, memo, total_amount, amount_cents)
-              VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+              (transaction_id, check_date, reference_number, payment_account_id, building_id, memo, total_amount, amount_cents, approval_status)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
 	defer cancel()
@@ -129,6 +132,7 @@ func (s *CheckStore) Create(ctx context.Context, tx *sql.Tx, c *Check) (*int64,
 		c.Memo,
 		c.TotalAmount,
 		c.AmountCents,
+		c.ApprovalStatus,
 	)
 	if err != nil {
 		return nil, err
@@ -146,7 +150,7 @@ func (s *CheckStore) Create(ctx context.Context, tx *sql.Tx, c *Check) (*int64,
 func (s *CheckStore) Update(ctx context.Context, tx *sql.Tx, c *Check) (*int64, error) {
 	query := `UPDATE checks
               SET transaction_id = ?, check_date = ?, reference_number = ?,
-                  payment_account_id = ?, building_id = ?, memo = ?, total_amount = ?, amount_cents = ?
+                  payment_account_id = ?, building_id = ?, memo = ?, total_amount = ?, amount_cents = ?, approval_status = ?
               WHERE id = ?`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
@@ -161,6 +165,7 @@ func (s *CheckStore) Update(ctx context.Context, tx *sql.Tx, c *Check) (*int64,
 		c.Memo,
 		c.TotalAmount,
 		c.AmountCents,
+		c.ApprovalStatus,
 		c.ID,
 	)
 	if err != nil {
@@ -0,0 +1,305 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+type Deposit struct {
+	ID                int64  `json:"id"`
+	TransactionID     int64  `json:"transaction_id"`
+	Reference         string `json:"reference"`
+	Date              string `json:"date"`
+	BankAccountID     int64  `json:"bank_account_id"`
+	ClearingAccountID int64  `json:"clearing_account_id"`
+	AmountCents       int64  `json:"amount_cents"`
+	Memo              string `json:"memo"`
+	UserID            int64  `json:"user_id"`
+	BuildingID        int64  `json:"building_id"`
+	Status            string `json:"status"`
+	CreatedAt         string `json:"created_at"`
+	UpdatedAt         string `json:"updated_at"`
+}
+
+type DepositLine struct {
+	ID                  int64  `json:"id"`
+	DepositID           int64  `json:"deposit_id"`
+	SplitID             int64  `json:"split_id"`
+	SourceTransactionID int64  `json:"source_transaction_id"`
+	AmountCents         int64  `json:"amount_cents"`
+	CreatedAt           string `json:"created_at"`
+
+	// source transaction details
+	Type       string `json:"type"`
+	Date       string `json:"date"`
+	Reference  string `json:"reference"`
+	PeopleName string `json:"people_name"`
+}
+
+// UndepositedItem is a debit to the clearing account that no deposit has banked yet
+type UndepositedItem struct {
+	SplitID       int64
+	TransactionID int64
+	Type          string
+	Date          string
+	Reference     string
+	UnitID        *int64
+	PeopleID      *int64
+	PeopleName    string
+	AmountCents   int64
+}
+
+type DepositStore struct {
+	db *sql.DB
+}
+
+func NewDepositStore(db *sql.DB) *DepositStore {
+	return &DepositStore{db: db}
+}
+
+func (s *DepositStore) GetAll(ctx context.Context, buildingID int64, startDate, endDate *string) ([]Deposit, error) {
+	query := `
+		SELECT id, transaction_id, reference, DATE_FORMAT(date, '%Y-%m-%d'), bank_account_id,
+		       clearing_account_id, amount_cents, memo, user_id, building_id, status,
+		       created_at, updated_at
+		FROM deposits
+		WHERE building_id = ?
+	`
+
+	args := []any{buildingID}
+
+	if startDate != nil {
+		query += " AND date >= ?"
+		args = append(args, *startDate)
+	}
+	if endDate != nil {
+		query += " AND date <= ?"
+		args = append(args, *endDate)
+	}
+
+	query += " ORDER BY date DESC, id DESC"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deposits []Deposit
+	for rows.Next() {
+		var d Deposit
+		if err := rows.Scan(
+			&d.ID,
+			&d.TransactionID,
+			&d.Reference,
+			&d.Date,
+			&d.BankAccountID,
+			&d.ClearingAccountID,
+			&d.AmountCents,
+			&d.Memo,
+			&d.UserID,
+			&d.BuildingID,
+			&d.Status,
+			&d.CreatedAt,
+			&d.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		deposits = append(deposits, d)
+	}
+
+	return deposits, nil
+}
+
+func (s *DepositStore) GetByID(ctx context.Context, id int64) (*Deposit, error) {
+	query := `
+		SELECT id, transaction_id, reference, DATE_FORMAT(date, '%Y-%m-%d'), bank_account_id,
+		       clearing_account_id, amount_cents, memo, user_id, building_id, status,
+		       created_at, updated_at
+		FROM deposits
+		WHERE id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var d Deposit
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&d.ID,
+		&d.TransactionID,
+		&d.Reference,
+		&d.Date,
+		&d.BankAccountID,
+		&d.ClearingAccountID,
+		&d.AmountCents,
+		&d.Memo,
+		&d.UserID,
+		&d.BuildingID,
+		&d.Status,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+func (s *DepositStore) GetLines(ctx context.Context, depositID int64) ([]DepositLine, error) {
+	query := `
+		SELECT dl.id, dl.deposit_id, dl.split_id, dl.source_transaction_id, dl.amount_cents, dl.created_at,
+		       t.type, DATE_FORMAT(t.transaction_date, '%Y-%m-%d'), t.transaction_number, IFNULL(p.name, '')
+		FROM deposit_lines dl
+		JOIN transactions t ON t.id = dl.source_transaction_id
+		JOIN splits sp ON sp.id = dl.split_id
+		LEFT JOIN people p ON p.id = sp.people_id
+		WHERE dl.deposit_id = ?
+		ORDER BY t.transaction_date, dl.id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, depositID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []DepositLine
+	for rows.Next() {
+		var l DepositLine
+		if err := rows.Scan(
+			&l.ID,
+			&l.DepositID,
+			&l.SplitID,
+			&l.SourceTransactionID,
+			&l.AmountCents,
+			&l.CreatedAt,
+			&l.Type,
+			&l.Date,
+			&l.Reference,
+			&l.PeopleName,
+		); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+
+	return lines, nil
+}
+
+// GetUndeposited returns active debits to the clearing account that are not on any deposit yet
+func (s *DepositStore) GetUndeposited(ctx context.Context, buildingID int64, clearingAccountID int64) ([]UndepositedItem, error) {
+	query := `
+		SELECT sp.id, t.id, t.type, DATE_FORMAT(t.transaction_date, '%Y-%m-%d'), t.transaction_number,
+		       sp.unit_id, sp.people_id, IFNULL(p.name, ''), sp.debit_cents
+		FROM splits sp
+		JOIN transactions t ON t.id = sp.transaction_id
+		LEFT JOIN people p ON p.id = sp.people_id
+		WHERE t.building_id = ?
+		  AND sp.account_id = ?
+		  AND sp.status = '1'
+		  AND t.status = '1'
+		  AND sp.debit_cents > 0
+		  AND NOT EXISTS (SELECT 1 FROM deposit_lines dl WHERE dl.split_id = sp.id)
+		ORDER BY t.transaction_date, sp.id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, buildingID, clearingAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []UndepositedItem
+	for rows.Next() {
+		var i UndepositedItem
+		if err := rows.Scan(
+			&i.SplitID,
+			&i.TransactionID,
+			&i.Type,
+			&i.Date,
+			&i.Reference,
+			&i.UnitID,
+			&i.PeopleID,
+			&i.PeopleName,
+			&i.AmountCents,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+
+	return items, nil
+}
+
+func (s *DepositStore) Create(ctx context.Context, tx *sql.Tx, d *Deposit) error {
+	query := `
+		INSERT INTO deposits
+		(transaction_id, reference, date, bank_account_id, clearing_account_id,
+		 amount_cents, memo, user_id, building_id, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, '1')
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		d.TransactionID,
+		d.Reference,
+		d.Date,
+		d.BankAccountID,
+		d.ClearingAccountID,
+		d.AmountCents,
+		d.Memo,
+		d.UserID,
+		d.BuildingID,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	d.ID = id
+	return nil
+}
+
+func (s *DepositStore) CreateLine(ctx context.Context, tx *sql.Tx, l *DepositLine) error {
+	query := `
+		INSERT INTO deposit_lines (deposit_id, split_id, source_transaction_id, amount_cents)
+		VALUES (?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, l.DepositID, l.SplitID, l.SourceTransactionID, l.AmountCents)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	l.ID = id
+	return nil
+}
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+// ExchangeRate is the day's rate of a foreign currency: base currency per one unit of Currency
+type ExchangeRate struct {
+	ID         int64  `json:"id"`
+	BuildingID int64  `json:"building_id"`
+	Currency   string `json:"currency"`
+	RateDate   string `json:"rate_date"`
+	RateScaled int64  `json:"rate_scaled"` // 8 decimals (money.FXRateScale)
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+type ExchangeRateStore struct {
+	db *sql.DB
+}
+
+func NewExchangeRateStore(db *sql.DB) *ExchangeRateStore {
+	return &ExchangeRateStore{db: db}
+}
+
+const exchangeRateColumns = `
+	id, building_id, currency, DATE_FORMAT(rate_date, '%Y-%m-%d'), rate_scaled, created_at, updated_at
+`
+
+func scanExchangeRate(scan func(dest ...any) error, r *ExchangeRate) error {
+	return scan(
+		&r.ID,
+		&r.BuildingID,
+		&r.Currency,
+		&r.RateDate,
+		&r.RateScaled,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	)
+}
+
+func (s *ExchangeRateStore) GetAll(ctx context.Context, buildingID int64, currency *string) ([]ExchangeRate, error) {
+	query := `SELECT ` + exchangeRateColumns + ` FROM exchange_rates WHERE building_id = ?`
+	args := []any{buildingID}
+
+	if currency != nil && *currency != "" {
+		query += " AND currency = ?"
+		args = append(args, *currency)
+	}
+
+	query += " ORDER BY currency, rate_date DESC"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []ExchangeRate
+	for rows.Next() {
+		var r ExchangeRate
+		if err := scanExchangeRate(rows.Scan, &r); err != nil {
+			return nil, err
+		}
+		rates = append(rates, r)
+	}
+
+	return rates, nil
+}
+
+func (s *ExchangeRateStore) GetByID(ctx context.Context, id int64) (*ExchangeRate, error) {
+	query := `SELECT ` + exchangeRateColumns + ` FROM exchange_rates WHERE id = ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var r ExchangeRate
+	if err := scanExchangeRate(s.db.QueryRowContext(ctx, query, id).Scan, &r); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// GetRate returns the latest rate of the currency on or before the date
+func (s *ExchangeRateStore) GetRate(ctx context.Context, buildingID int64, currency string, date string) (*ExchangeRate, error) {
+	query := `
+		SELECT ` + exchangeRateColumns + `
+		FROM exchange_rates
+		WHERE building_id = ? AND currency = ? AND rate_date <= ?
+		ORDER BY rate_date DESC
+		LIMIT 1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var r ExchangeRate
+	if err := scanExchangeRate(s.db.QueryRowContext(ctx, query, buildingID, currency, date).Scan, &r); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// Save adds the day's rate, or replaces it when the currency already has one for that day
+func (s *ExchangeRateStore) Save(ctx context.Context, r *ExchangeRate) error {
+	query := `
+		INSERT INTO exchange_rates (building_id, currency, rate_date, rate_scaled)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE rate_scaled = VALUES(rate_scaled), id = LAST_INSERT_ID(id)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, r.BuildingID, r.Currency, r.RateDate, r.RateScaled)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	r.ID = id
+	return nil
+}
+
+func (s *ExchangeRateStore) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM exchange_rates WHERE id = ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
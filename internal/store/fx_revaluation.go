@@ -0,0 +1,336 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+// FXRevaluation is a period-end run restating open foreign currency documents and accounts at the
+// day's rate. It is reversed the next day so payments realize against the document rate.
+type FXRevaluation struct {
+	ID                    int64  `json:"id"`
+	BuildingID            int64  `json:"building_id"`
+	RevaluationDate       string `json:"revaluation_date"`
+	TransactionID         int64  `json:"transaction_id"`
+	ReversalTransactionID int64  `json:"reversal_transaction_id"`
+	CreatedAt             string `json:"created_at"`
+}
+
+type FXRevaluationLine struct {
+	ID              int64  `json:"id"`
+	RevaluationID   int64  `json:"revaluation_id"`
+	SourceType      string `json:"source_type"` // invoice | bill | account
+	SourceID        int64  `json:"source_id"`
+	AccountID       int64  `json:"account_id"`
+	Currency        string `json:"currency"`
+	ForeignCents    int64  `json:"foreign_cents"`
+	RateScaled      int64  `json:"rate_scaled"`
+	CarryingCents   int64  `json:"carrying_cents"` // base currency before the run
+	RevaluedCents   int64  `json:"revalued_cents"`
+	AdjustmentCents int64  `json:"adjustment_cents"`
+}
+
+// FXOpenDocument is a foreign currency invoice or bill with a balance. Balances are in base currency.
+type FXOpenDocument struct {
+	SourceType         string // invoice | bill
+	SourceID           int64
+	DocumentNo         string
+	AccountID          int64 // A/R or A/P
+	UnitID             *int64
+	PeopleID           *int64
+	Currency           string
+	AmountCents        int64
+	ForeignAmountCents int64
+	BalanceCents       int64
+}
+
+// FXAccountBalance is a foreign currency account's balance in base and in its own currency.
+// UntaggedSplits counts postings made without a foreign amount, which cannot be revalued.
+type FXAccountBalance struct {
+	AccountID      int64
+	AccountName    string
+	Currency       string
+	BalanceCents   int64 // debit positive
+	ForeignCents   int64
+	UntaggedSplits int64
+}
+
+type FXRevaluationStore struct {
+	db *sql.DB
+}
+
+func NewFXRevaluationStore(db *sql.DB) *FXRevaluationStore {
+	return &FXRevaluationStore{db: db}
+}
+
+const fxRevaluationColumns = `
+	id, building_id, DATE_FORMAT(revaluation_date, '%Y-%m-%d'), transaction_id, reversal_transaction_id, created_at
+`
+
+func scanFXRevaluation(scan func(dest ...any) error, r *FXRevaluation) error {
+	return scan(
+		&r.ID,
+		&r.BuildingID,
+		&r.RevaluationDate,
+		&r.TransactionID,
+		&r.ReversalTransactionID,
+		&r.CreatedAt,
+	)
+}
+
+func (s *FXRevaluationStore) GetAll(ctx context.Context, buildingID int64) ([]FXRevaluation, error) {
+	query := `SELECT ` + fxRevaluationColumns + ` FROM fx_revaluations WHERE building_id = ? ORDER BY revaluation_date DESC`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, buildingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revaluations []FXRevaluation
+	for rows.Next() {
+		var r FXRevaluation
+		if err := scanFXRevaluation(rows.Scan, &r); err != nil {
+			return nil, err
+		}
+		revaluations = append(revaluations, r)
+	}
+
+	return revaluations, nil
+}
+
+func (s *FXRevaluationStore) GetByID(ctx context.Context, id int64) (*FXRevaluation, error) {
+	query := `SELECT ` + fxRevaluationColumns + ` FROM fx_revaluations WHERE id = ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var r FXRevaluation
+	if err := scanFXRevaluation(s.db.QueryRowContext(ctx, query, id).Scan, &r); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+func (s *FXRevaluationStore) GetLines(ctx context.Context, revaluationID int64) ([]FXRevaluationLine, error) {
+	query := `
+		SELECT id, revaluation_id, source_type, source_id, account_id, currency,
+		       foreign_cents, rate_scaled, carrying_cents, revalued_cents, adjustment_cents
+		FROM fx_revaluation_lines
+		WHERE revaluation_id = ?
+		ORDER BY id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, revaluationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []FXRevaluationLine
+	for rows.Next() {
+		var l FXRevaluationLine
+		if err := rows.Scan(
+			&l.ID,
+			&l.RevaluationID,
+			&l.SourceType,
+			&l.SourceID,
+			&l.AccountID,
+			&l.Currency,
+			&l.ForeignCents,
+			&l.RateScaled,
+			&l.CarryingCents,
+			&l.RevaluedCents,
+			&l.AdjustmentCents,
+		); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+
+	return lines, nil
+}
+
+func (s *FXRevaluationStore) Create(ctx context.Context, tx *sql.Tx, r *FXRevaluation) error {
+	query := `
+		INSERT INTO fx_revaluations (building_id, revaluation_date, transaction_id, reversal_transaction_id)
+		VALUES (?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, r.BuildingID, r.RevaluationDate, r.TransactionID, r.ReversalTransactionID)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	r.ID = id
+	return nil
+}
+
+func (s *FXRevaluationStore) CreateLine(ctx context.Context, tx *sql.Tx, l *FXRevaluationLine) error {
+	query := `
+		INSERT INTO fx_revaluation_lines
+		(revaluation_id, source_type, source_id, account_id, currency,
+		 foreign_cents, rate_scaled, carrying_cents, revalued_cents, adjustment_cents)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query,
+		l.RevaluationID,
+		l.SourceType,
+		l.SourceID,
+		l.AccountID,
+		l.Currency,
+		l.ForeignCents,
+		l.RateScaled,
+		l.CarryingCents,
+		l.RevaluedCents,
+		l.AdjustmentCents,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	l.ID = id
+	return nil
+}
+
+// GetOpenDocuments lists the foreign currency invoices and approved bills open on the date
+func (s *FXRevaluationStore) GetOpenDocuments(ctx context.Context, buildingID int64, asOfDate string) ([]FXOpenDocument, error) {
+	query := `
+		SELECT d.source_type, d.id, d.document_no, d.account_id, d.unit_id, d.people_id, d.currency,
+		       d.amount_cents, d.foreign_amount_cents, d.balance_cents
+		FROM (
+			SELECT 'invoice' AS source_type, i.id, i.invoice_no AS document_no, i.ar_account_id AS account_id,
+				i.unit_id, i.people_id, i.currency, i.amount_cents, i.foreign_amount_cents,
+				i.amount_cents
+					- COALESCE((SELECT SUM(ip.amount_cents) FROM invoice_payments ip WHERE ip.invoice_id = i.id AND ip.status = '1' AND ip.date <= ?), 0)
+					- COALESCE((SELECT SUM(iac.amount_cents) FROM invoice_applied_credits iac WHERE iac.invoice_id = i.id AND iac.status = '1' AND iac.date <= ?), 0)
+					- COALESCE((SELECT SUM(iad.amount_cents) FROM invoice_applied_discounts iad WHERE iad.invoice_id = i.id AND iad.status = '1' AND iad.date <= ?), 0)
+					AS balance_cents
+			FROM invoices i
+			WHERE i.building_id = ? AND i.status = '1' AND i.currency IS NOT NULL AND i.sales_date <= ?
+
+			UNION ALL
+
+			SELECT 'bill', b.id, b.bill_no, b.ap_account_id, b.unit_id, b.people_id, b.currency, b.amount_cents, b.foreign_amount_cents,
+				b.amount_cents
+					- COALESCE((SELECT SUM(bp.amount_cents) FROM bill_payments bp WHERE bp.bill_id = b.id AND bp.status = '1' AND bp.date <= ?), 0)
+					- COALESCE((SELECT SUM(bac.amount_cents) FROM bill_applied_credits bac WHERE bac.bill_id = b.id AND bac.status = '1' AND bac.date <= ?), 0)
+			FROM bills b
+			WHERE b.building_id = ? AND b.status = '1' AND b.approval_status = 'approved'
+				AND b.currency IS NOT NULL AND b.bill_date <= ?
+		) d
+		WHERE d.balance_cents <> 0
+		ORDER BY d.source_type DESC, d.id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query,
+		asOfDate, asOfDate, asOfDate, buildingID, asOfDate,
+		asOfDate, asOfDate, buildingID, asOfDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var documents []FXOpenDocument
+	for rows.Next() {
+		var d FXOpenDocument
+		if err := rows.Scan(
+			&d.SourceType,
+			&d.SourceID,
+			&d.DocumentNo,
+			&d.AccountID,
+			&d.UnitID,
+			&d.PeopleID,
+			&d.Currency,
+			&d.AmountCents,
+			&d.ForeignAmountCents,
+			&d.BalanceCents,
+		); err != nil {
+			return nil, err
+		}
+		documents = append(documents, d)
+	}
+
+	return documents, nil
+}
+
+// GetAccountBalances returns the balance of every foreign currency account on the date
+func (s *FXRevaluationStore) GetAccountBalances(ctx context.Context, buildingID int64, asOfDate string) ([]FXAccountBalance, error) {
+	query := `
+		SELECT a.id, a.account_name, a.currency,
+		       COALESCE(SUM(s.debit_cents), 0) - COALESCE(SUM(s.credit_cents), 0),
+		       COALESCE(SUM(s.foreign_cents), 0),
+		       COALESCE(SUM(s.id IS NOT NULL AND s.foreign_cents IS NULL), 0)
+		FROM accounts a
+		LEFT JOIN (
+			SELECT s.*
+			FROM splits s
+			JOIN transactions t
+			  ON s.transaction_id = t.id
+			 AND s.status = '1'
+			 AND t.status = '1'
+			 AND t.transaction_date <= ?
+		) s ON s.account_id = a.id
+		WHERE a.building_id = ? AND a.currency IS NOT NULL
+		GROUP BY a.id, a.account_name, a.currency
+		ORDER BY a.account_number
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, asOfDate, buildingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances []FXAccountBalance
+	for rows.Next() {
+		var b FXAccountBalance
+		if err := rows.Scan(
+			&b.AccountID,
+			&b.AccountName,
+			&b.Currency,
+			&b.BalanceCents,
+			&b.ForeignCents,
+			&b.UntaggedSplits,
+		); err != nil {
+			return nil, err
+		}
+		balances = append(balances, b)
+	}
+
+	return balances, nil
+}
@@ -0,0 +1,253 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+// ItemStock is an item's perpetual inventory position. Average cost is ValueCents over OnHandScaled.
+type ItemStock struct {
+	ItemID         int64  `json:"item_id"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	AssetAccount   *int64 `json:"asset_account"`
+	COGSAccount    *int64 `json:"cogs_account"`
+	ExpenseAccount *int64 `json:"expense_account"`
+	OnHandScaled   int64  `json:"on_hand_scaled"`
+	ValueCents     int64  `json:"value_cents"`
+	BuildingID     int64  `json:"building_id"`
+}
+
+// InventoryMovement is one receipt, issue or adjustment of an inventory item; qty and cost are negative for issues
+type InventoryMovement struct {
+	ID                int64  `json:"id"`
+	ItemID            int64  `json:"item_id"`
+	ItemName          string `json:"item_name"`
+	TransactionID     int64  `json:"transaction_id"`
+	SourceType        string `json:"source_type"` // bill | invoice | receipt | adjustment
+	MovementDate      string `json:"movement_date"`
+	QtyScaled         int64  `json:"qty_scaled"`
+	CostCents         int64  `json:"cost_cents"`
+	OnHandAfterScaled int64  `json:"on_hand_after_scaled"`
+	ValueAfterCents   int64  `json:"value_after_cents"`
+	BuildingID        int64  `json:"building_id"`
+	CreatedAt         string `json:"created_at"`
+}
+
+type InventoryStore struct {
+	db *sql.DB
+}
+
+func NewInventoryStore(db *sql.DB) *InventoryStore {
+	return &InventoryStore{db: db}
+}
+
+const itemStockColumns = `
+	id, name, type, asset_account, cogs_account, expense_account,
+	on_hand_scaled, value_cents, building_id
+`
+
+func scanItemStock(scan func(dest ...any) error, s *ItemStock) error {
+	return scan(
+		&s.ItemID,
+		&s.Name,
+		&s.Type,
+		&s.AssetAccount,
+		&s.COGSAccount,
+		&s.ExpenseAccount,
+		&s.OnHandScaled,
+		&s.ValueCents,
+		&s.BuildingID,
+	)
+}
+
+func (s *InventoryStore) GetStock(ctx context.Context, itemID int64) (*ItemStock, error) {
+	query := `SELECT ` + itemStockColumns + ` FROM items WHERE id = ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var stock ItemStock
+	if err := scanItemStock(s.db.QueryRowContext(ctx, query, itemID).Scan, &stock); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &stock, nil
+}
+
+// GetStockTx locks the item row so concurrent postings see each other's stock
+func (s *InventoryStore) GetStockTx(ctx context.Context, tx *sql.Tx, itemID int64) (*ItemStock, error) {
+	query := `SELECT ` + itemStockColumns + ` FROM items WHERE id = ? FOR UPDATE`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var stock ItemStock
+	if err := scanItemStock(tx.QueryRowContext(ctx, query, itemID).Scan, &stock); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &stock, nil
+}
+
+// SetStockTx saves the stock position and keeps the rounded on_hand and avg_cost shown on items in step
+func (s *InventoryStore) SetStockTx(ctx context.Context, tx *sql.Tx, stock *ItemStock) error {
+	query := `
+		UPDATE items
+		SET on_hand_scaled = ?, value_cents = ?,
+		    on_hand = on_hand_scaled / 100000,
+		    avg_cost = IF(on_hand_scaled = 0, 0, value_cents * 1000 / on_hand_scaled)
+		WHERE id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, stock.OnHandScaled, stock.ValueCents, stock.ItemID)
+	return err
+}
+
+const inventoryMovementColumns = `
+	m.id, m.item_id, i.name, m.transaction_id, m.source_type, DATE_FORMAT(m.movement_date, '%Y-%m-%d'),
+	m.qty_scaled, m.cost_cents, m.on_hand_after_scaled, m.value_after_cents, m.building_id, m.created_at
+`
+
+func scanInventoryMovement(scan func(dest ...any) error, m *InventoryMovement) error {
+	return scan(
+		&m.ID,
+		&m.ItemID,
+		&m.ItemName,
+		&m.TransactionID,
+		&m.SourceType,
+		&m.MovementDate,
+		&m.QtyScaled,
+		&m.CostCents,
+		&m.OnHandAfterScaled,
+		&m.ValueAfterCents,
+		&m.BuildingID,
+		&m.CreatedAt,
+	)
+}
+
+// GetMovements lists an item's movements in posting order, optionally within a date range
+func (s *InventoryStore) GetMovements(ctx context.Context, itemID int64, startDate, endDate *string) ([]InventoryMovement, error) {
+	query := `
+		SELECT ` + inventoryMovementColumns + `
+		FROM inventory_movements m
+		JOIN items i ON i.id = m.item_id
+		WHERE m.item_id = ?
+	`
+
+	args := []any{itemID}
+
+	if startDate != nil && *startDate != "" {
+		query += " AND m.movement_date >= ?"
+		args = append(args, *startDate)
+	}
+	if endDate != nil && *endDate != "" {
+		query += " AND m.movement_date <= ?"
+		args = append(args, *endDate)
+	}
+
+	query += " ORDER BY m.id"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []InventoryMovement
+	for rows.Next() {
+		var m InventoryMovement
+		if err := scanInventoryMovement(rows.Scan, &m); err != nil {
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+
+	return movements, nil
+}
+
+// GetMovementsByTransactionTx returns a transaction's movements newest first, the order they are undone in
+func (s *InventoryStore) GetMovementsByTransactionTx(ctx context.Context, tx *sql.Tx, transactionID int64) ([]InventoryMovement, error) {
+	query := `
+		SELECT ` + inventoryMovementColumns + `
+		FROM inventory_movements m
+		JOIN items i ON i.id = m.item_id
+		WHERE m.transaction_id = ?
+		ORDER BY m.id DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, query, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []InventoryMovement
+	for rows.Next() {
+		var m InventoryMovement
+		if err := scanInventoryMovement(rows.Scan, &m); err != nil {
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+
+	return movements, nil
+}
+
+func (s *InventoryStore) CreateMovement(ctx context.Context, tx *sql.Tx, m *InventoryMovement) error {
+	query := `
+		INSERT INTO inventory_movements
+		(item_id, transaction_id, source_type, movement_date, qty_scaled, cost_cents,
+		 on_hand_after_scaled, value_after_cents, building_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query,
+		m.ItemID,
+		m.TransactionID,
+		m.SourceType,
+		m.MovementDate,
+		m.QtyScaled,
+		m.CostCents,
+		m.OnHandAfterScaled,
+		m.ValueAfterCents,
+		m.BuildingID,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	m.ID = id
+	return nil
+}
+
+func (s *InventoryStore) DeleteMovementsByTransaction(ctx context.Context, tx *sql.Tx, transactionID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, `DELETE FROM inventory_movements WHERE transaction_id = ?`, transactionID)
+	return err
+}
@@ -0,0 +1,272 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+// InventoryAdjustment corrects stock after a count; the value it adds or removes is posted
+// between the items' asset accounts and AccountID
+type InventoryAdjustment struct {
+	ID               int64  `json:"id"`
+	TransactionID    int64  `json:"transaction_id"`
+	Reference        string `json:"reference"`
+	AdjustmentDate   string `json:"adjustment_date"`
+	AccountID        int64  `json:"account_id"`
+	UnitID           *int64 `json:"unit_id"`
+	ValueChangeCents int64  `json:"value_change_cents"` // sum of the lines
+	Memo             string `json:"memo"`
+	UserID           int64  `json:"user_id"`
+	BuildingID       int64  `json:"building_id"`
+	CreatedAt        string `json:"created_at"`
+	UpdatedAt        string `json:"updated_at"`
+
+	// relationships
+	AccountName string `json:"account_name"`
+}
+
+// InventoryAdjustmentLine is one counted item. NewQtyScaled and NewValueCents are nil when
+// the count left them unchanged.
+type InventoryAdjustmentLine struct {
+	ID               int64   `json:"id"`
+	AdjustmentID     int64   `json:"adjustment_id"`
+	ItemID           int64   `json:"item_id"`
+	ItemName         string  `json:"item_name"`
+	NewQtyScaled     *int64  `json:"new_qty_scaled"`
+	NewValueCents    *int64  `json:"new_value_cents"`
+	QtyChangeScaled  int64   `json:"qty_change_scaled"`
+	ValueChangeCents int64   `json:"value_change_cents"`
+	Memo             *string `json:"memo"`
+}
+
+type InventoryAdjustmentStore struct {
+	db *sql.DB
+}
+
+func NewInventoryAdjustmentStore(db *sql.DB) *InventoryAdjustmentStore {
+	return &InventoryAdjustmentStore{db: db}
+}
+
+const inventoryAdjustmentColumns = `
+	ia.id, ia.transaction_id, ia.reference, DATE_FORMAT(ia.adjustment_date, '%Y-%m-%d'), ia.account_id, ia.unit_id,
+	COALESCE((SELECT SUM(l.value_change_cents) FROM inventory_adjustment_lines l WHERE l.adjustment_id = ia.id), 0),
+	ia.memo, ia.user_id, ia.building_id, ia.created_at, ia.updated_at, a.account_name
+`
+
+func scanInventoryAdjustment(scan func(dest ...any) error, ia *InventoryAdjustment) error {
+	return scan(
+		&ia.ID,
+		&ia.TransactionID,
+		&ia.Reference,
+		&ia.AdjustmentDate,
+		&ia.AccountID,
+		&ia.UnitID,
+		&ia.ValueChangeCents,
+		&ia.Memo,
+		&ia.UserID,
+		&ia.BuildingID,
+		&ia.CreatedAt,
+		&ia.UpdatedAt,
+		&ia.AccountName,
+	)
+}
+
+func (s *InventoryAdjustmentStore) GetAll(ctx context.Context, buildingID int64, startDate, endDate *string) ([]InventoryAdjustment, error) {
+	query := `
+		SELECT ` + inventoryAdjustmentColumns + `
+		FROM inventory_adjustments ia
+		JOIN accounts a ON a.id = ia.account_id
+		WHERE ia.building_id = ?
+	`
+
+	args := []any{buildingID}
+
+	if startDate != nil && *startDate != "" {
+		query += " AND ia.adjustment_date >= ?"
+		args = append(args, *startDate)
+	}
+	if endDate != nil && *endDate != "" {
+		query += " AND ia.adjustment_date <= ?"
+		args = append(args, *endDate)
+	}
+
+	query += " ORDER BY ia.adjustment_date DESC, ia.id DESC"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var adjustments []InventoryAdjustment
+	for rows.Next() {
+		var ia InventoryAdjustment
+		if err := scanInventoryAdjustment(rows.Scan, &ia); err != nil {
+			return nil, err
+		}
+		adjustments = append(adjustments, ia)
+	}
+
+	return adjustments, nil
+}
+
+func (s *InventoryAdjustmentStore) GetByID(ctx context.Context, id int64) (*InventoryAdjustment, error) {
+	query := `
+		SELECT ` + inventoryAdjustmentColumns + `
+		FROM inventory_adjustments ia
+		JOIN accounts a ON a.id = ia.account_id
+		WHERE ia.id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var ia InventoryAdjustment
+	if err := scanInventoryAdjustment(s.db.QueryRowContext(ctx, query, id).Scan, &ia); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &ia, nil
+}
+
+func (s *InventoryAdjustmentStore) Create(ctx context.Context, tx *sql.Tx, ia *InventoryAdjustment) error {
+	query := `
+		INSERT INTO inventory_adjustments
+		(transaction_id, reference, adjustment_date, account_id, unit_id, memo, user_id, building_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query,
+		ia.TransactionID,
+		ia.Reference,
+		ia.AdjustmentDate,
+		ia.AccountID,
+		ia.UnitID,
+		ia.Memo,
+		ia.UserID,
+		ia.BuildingID,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	ia.ID = id
+	return nil
+}
+
+func (s *InventoryAdjustmentStore) Update(ctx context.Context, tx *sql.Tx, ia *InventoryAdjustment) error {
+	query := `
+		UPDATE inventory_adjustments
+		SET reference = ?, adjustment_date = ?, account_id = ?, unit_id = ?, memo = ?, user_id = ?
+		WHERE id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query,
+		ia.Reference,
+		ia.AdjustmentDate,
+		ia.AccountID,
+		ia.UnitID,
+		ia.Memo,
+		ia.UserID,
+		ia.ID,
+	)
+	return err
+}
+
+func (s *InventoryAdjustmentStore) GetLines(ctx context.Context, adjustmentID int64) ([]InventoryAdjustmentLine, error) {
+	query := `
+		SELECT l.id, l.adjustment_id, l.item_id, i.name, l.new_qty_scaled, l.new_value_cents,
+		       l.qty_change_scaled, l.value_change_cents, l.memo
+		FROM inventory_adjustment_lines l
+		JOIN items i ON i.id = l.item_id
+		WHERE l.adjustment_id = ?
+		ORDER BY l.id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, adjustmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []InventoryAdjustmentLine
+	for rows.Next() {
+		var l InventoryAdjustmentLine
+		if err := rows.Scan(
+			&l.ID,
+			&l.AdjustmentID,
+			&l.ItemID,
+			&l.ItemName,
+			&l.NewQtyScaled,
+			&l.NewValueCents,
+			&l.QtyChangeScaled,
+			&l.ValueChangeCents,
+			&l.Memo,
+		); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+
+	return lines, nil
+}
+
+func (s *InventoryAdjustmentStore) CreateLine(ctx context.Context, tx *sql.Tx, l *InventoryAdjustmentLine) error {
+	query := `
+		INSERT INTO inventory_adjustment_lines
+		(adjustment_id, item_id, new_qty_scaled, new_value_cents, qty_change_scaled, value_change_cents, memo)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query,
+		l.AdjustmentID,
+		l.ItemID,
+		l.NewQtyScaled,
+		l.NewValueCents,
+		l.QtyChangeScaled,
+		l.ValueChangeCents,
+		l.Memo,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	l.ID = id
+	return nil
+}
+
+func (s *InventoryAdjustmentStore) DeleteLines(ctx context.Context, tx *sql.Tx, adjustmentID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, `DELETE FROM inventory_adjustment_lines WHERE adjustment_id = ?`, adjustmentID)
+	return err
+}
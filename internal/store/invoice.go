@@ -25,6 +25,11 @@ type Invoice struct {
 	Status     *int `json:"status"` // enum('0','1')
 	BuildingID int64  `json:"building_id"`
 
+	// set on foreign currency invoices; AmountCents is then the base currency amount
+	Currency           *string `json:"currency"`
+	ExchangeRateScaled *int64  `json:"exchange_rate_scaled"`
+	ForeignAmountCents *int64  `json:"foreign_amount_cents"`
+
 	CreatedAt string `json:"createdAt"`
 	UpdatedAt string `json:"updatedAt"`
 
@@ -180,7 +185,8 @@ func (s *InvoiceStore) GetByID(ctx context.Context, id int64) (*Invoice, error)
 		SELECT i.id, i.invoice_no, i.transaction_id, i.sales_date, i.due_date,
 		       i.ar_account_id, i.unit_id, i.people_id, i.user_id,
 		       i.amount, i.amount_cents, i.description, i.cancel_reason, i.status,
-		       i.building_id, i.createdAt, i.updatedAt,
+		       i.building_id, i.currency, i.exchange_rate_scaled, i.foreign_amount_cents,
+		       i.createdAt, i.updatedAt,
 			   a.account_name, u.name unit_name, p.name people_name
 		FROM invoices i
 		LEFT JOIN accounts a ON a.id = i.ar_account_id
@@ -209,6 +215,9 @@ func (s *InvoiceStore) GetByID(ctx context.Context, id int64) (*Invoice, error)
 		&i.CancelReason,
 		&i.Status,
 		&i.BuildingID,
+		&i.Currency,
+		&i.ExchangeRateScaled,
+		&i.ForeignAmountCents,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.ARAccount.AccountName,
@@ -231,8 +240,9 @@ func (s *InvoiceStore) Create(ctx context.Context, tx *sql.Tx, i *Invoice) (*int
 		INSERT INTO invoices
 		(invoice_no, transaction_id, sales_date, due_date,
 		 ar_account_id, unit_id, people_id, user_id,
-		 amount, amount_cents, description, cancel_reason, status, building_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, "1", ?)
+		 amount, amount_cents, description, cancel_reason, status, building_id,
+		 currency, exchange_rate_scaled, foreign_amount_cents)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, "1", ?, ?, ?, ?)
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
@@ -254,6 +264,9 @@ func (s *InvoiceStore) Create(ctx context.Context, tx *sql.Tx, i *Invoice) (*int
 		i.Description,
 		i.CancelReason,
 		i.BuildingID,
+		i.Currency,
+		i.ExchangeRateScaled,
+		i.ForeignAmountCents,
 	)
 	if err != nil {
 		return nil, err
@@ -274,7 +287,8 @@ func (s *InvoiceStore) Update(ctx context.Context, tx *sql.Tx, i *Invoice) (*int
 		SET invoice_no = ?, transaction_id = ?, sales_date = ?, due_date = ?,
 		    ar_account_id = ?, unit_id = ?, people_id = ?, user_id = ?,
 		    amount = ?, amount_cents = ?, description = ?, cancel_reason = ?,
-		    building_id = ?, updatedAt = CURRENT_TIMESTAMP
+		    building_id = ?, currency = ?, exchange_rate_scaled = ?, foreign_amount_cents = ?,
+		    updatedAt = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`
 
@@ -297,6 +311,9 @@ func (s *InvoiceStore) Update(ctx context.Context, tx *sql.Tx, i *Invoice) (*int
 		i.Description,
 		i.CancelReason,
 		i.BuildingID,
+		i.Currency,
+		i.ExchangeRateScaled,
+		i.ForeignAmountCents,
 		i.ID,
 	)
 	if err != nil {
@@ -337,3 +354,30 @@ func (s *InvoiceStore) Delete(ctx context.Context, id int64) error {
 
 	return nil
 }
+
+// GetOpenBalanceTx returns amount less payments, applied credits and discounts,
+// locking the invoice row so concurrent payments see each other
+func (s *InvoiceStore) GetOpenBalanceTx(ctx context.Context, tx *sql.Tx, id int64) (int64, error) {
+	query := `
+		SELECT i.amount_cents
+			- COALESCE((SELECT SUM(ip.amount_cents) FROM invoice_payments ip WHERE ip.invoice_id = i.id AND ip.status = '1'), 0)
+			- COALESCE((SELECT SUM(iac.amount_cents) FROM invoice_applied_credits iac WHERE iac.invoice_id = i.id AND iac.status = '1'), 0)
+			- COALESCE((SELECT SUM(iad.amount_cents) FROM invoice_applied_discounts iad WHERE iad.invoice_id = i.id AND iad.status = '1'), 0)
+		FROM invoices i
+		WHERE i.id = ?
+		FOR UPDATE
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var balance int64
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&balance); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+
+	return balance, nil
+}
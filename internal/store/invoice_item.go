@@ -26,6 +26,10 @@ type InvoiceItem struct {
 	PreviousValueCents *int64 `json:"previous_value_cents"`
 	CurrentValueCents  *int64 `json:"current_value_cents"`
 
+	TaxCodeID    *int64 `json:"tax_code_id"`
+	TaxableCents int64  `json:"taxable_cents"` // net of tax
+	TaxCents     int64  `json:"tax_cents"`
+
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 }
@@ -42,7 +46,8 @@ func (s *InvoiceItemStore) GetAllByInvoiceID(ctx context.Context, invoiceID int6
 	query := `
 		SELECT id, invoice_id, item_id, item_name,
 		       previous_value, current_value, qty, rate,
-		       total, status, created_at, updated_at, qty_scaled, rate_scaled, total_cents, previous_value_cents, current_value_cents
+		       total, status, created_at, updated_at, qty_scaled, rate_scaled, total_cents, previous_value_cents, current_value_cents,
+		       tax_code_id, taxable_cents, tax_cents
 		FROM invoice_items
 		WHERE invoice_id = ?
 	`
@@ -77,6 +82,9 @@ func (s *InvoiceItemStore) GetAllByInvoiceID(ctx context.Context, invoiceID int6
 			&i.TotalCents,
 			&i.PreviousValueCents,
 			&i.CurrentValueCents,
+			&i.TaxCodeID,
+			&i.TaxableCents,
+			&i.TaxCents,
 		); err != nil {
 			return nil, err
 		}
@@ -90,7 +98,8 @@ func (s *InvoiceItemStore) GetByID(ctx context.Context, id int64) (*InvoiceItem,
 	query := `
 		SELECT id, invoice_id, item_id, item_name,
 		       previous_value, current_value, qty, rate,
-		       total, status, created_at, updated_at, qty_scaled, rate_scaled, total_cents, previous_value_cents, current_value_cents
+		       total, status, created_at, updated_at, qty_scaled, rate_scaled, total_cents, previous_value_cents, current_value_cents,
+		       tax_code_id, taxable_cents, tax_cents
 		FROM invoice_items
 		WHERE id = ?
 	`
@@ -117,6 +126,9 @@ func (s *InvoiceItemStore) GetByID(ctx context.Context, id int64) (*InvoiceItem,
 		&i.TotalCents,
 		&i.PreviousValueCents,
 		&i.CurrentValueCents,
+		&i.TaxCodeID,
+		&i.TaxableCents,
+		&i.TaxCents,
 	)
 
 	if err != nil {
@@ -134,8 +146,9 @@ func (s *InvoiceItemStore) Create(ctx context.Context, tx *sql.Tx, i *InvoiceIte
 		INSERT INTO invoice_items
 		(invoice_id, item_id, item_name,
 		 previous_value, current_value, qty, rate,
-		 total, status, qty_scaled, rate_scaled, total_cents, previous_value_cents, current_value_cents)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 total, status, qty_scaled, rate_scaled, total_cents, previous_value_cents, current_value_cents,
+		 tax_code_id, taxable_cents, tax_cents)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
@@ -158,6 +171,9 @@ func (s *InvoiceItemStore) Create(ctx context.Context, tx *sql.Tx, i *InvoiceIte
 		i.TotalCents,
 		i.PreviousValueCents,
 		i.CurrentValueCents,
+		i.TaxCodeID,
+		i.TaxableCents,
+		i.TaxCents,
 	)
 	if err != nil {
 		return err
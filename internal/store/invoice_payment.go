@@ -22,6 +22,13 @@ type InvoicePayment struct {
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 	AmountCents int64 `json:"amount_cents"`
+
+	// set when a foreign currency invoice is paid; AmountCents is the base currency taken off
+	// the invoice and FXGainLossCents what the payment realized against it, a gain when positive
+	Currency           *string `json:"currency"`
+	ExchangeRateScaled *int64  `json:"exchange_rate_scaled"`
+	ForeignAmountCents *int64  `json:"foreign_amount_cents"`
+	FXGainLossCents    int64   `json:"fx_gain_loss_cents"`
 }
 
 
@@ -36,7 +43,9 @@ func NewInvoicePaymentStore(db *sql.DB) *InvoicePaymentStore {
 
 func (s *InvoicePaymentStore) GetAll(ctx context.Context, buildingID int64, startDate *string, endDate *string, peopleID *int, status *string) ([]InvoicePayment, error) {
 	query := `
-		SELECT ip.id, ip.transaction_id, ip.reference, ip.date, ip.invoice_id, ip.user_id, ip.account_id, ip.amount, ip.status, ip.createdAt, ip.updatedAt
+		SELECT ip.id, ip.transaction_id, ip.reference, ip.date, ip.invoice_id, ip.user_id, ip.account_id, ip.amount, ip.status,
+		       ip.currency, ip.exchange_rate_scaled, ip.foreign_amount_cents, ip.fx_gain_loss_cents,
+		       ip.createdAt, ip.updatedAt
 		, ip.amount_cents
 		FROM invoice_payments ip
 		INNER JOIN invoices i ON ip.invoice_id = i.id
@@ -88,6 +97,10 @@ func (s *InvoicePaymentStore) GetAll(ctx context.Context, buildingID int64, star
 			&p.AccountID,
 			&p.Amount,
 			&p.Status,
+			&p.Currency,
+			&p.ExchangeRateScaled,
+			&p.ForeignAmountCents,
+			&p.FXGainLossCents,
 			&p.CreatedAt,
 			&p.UpdatedAt,
 			&p.AmountCents,
@@ -104,7 +117,9 @@ func (s *InvoicePaymentStore) GetAllByInvoiceID(ctx context.Context, invoiceID i
 	query := `
 		SELECT id, transaction_id, reference, date,
 		       invoice_id, user_id, account_id,
-		       amount, amount_cents, status, createdAt, updatedAt
+		       amount, amount_cents, status,
+		       currency, exchange_rate_scaled, foreign_amount_cents, fx_gain_loss_cents,
+		       createdAt, updatedAt
 		FROM invoice_payments
 		WHERE invoice_id = ?
 	`
@@ -132,6 +147,10 @@ func (s *InvoicePaymentStore) GetAllByInvoiceID(ctx context.Context, invoiceID i
 			&p.Amount,
 			&p.AmountCents,
 			&p.Status,
+			&p.Currency,
+			&p.ExchangeRateScaled,
+			&p.ForeignAmountCents,
+			&p.FXGainLossCents,
 			&p.CreatedAt,
 			&p.UpdatedAt,
 		); err != nil {
@@ -147,7 +166,9 @@ func (s *InvoicePaymentStore) GetByID(ctx context.Context, id int64) (*InvoicePa
 	query := `
 		SELECT id, transaction_id, reference, date,
 		       invoice_id, user_id, account_id,
-		       amount, amount_cents, status, createdAt, updatedAt
+		       amount, amount_cents, status,
+		       currency, exchange_rate_scaled, foreign_amount_cents, fx_gain_loss_cents,
+		       createdAt, updatedAt
 		FROM invoice_payments
 		WHERE id = ?
 	`
@@ -167,6 +188,10 @@ func (s *InvoicePaymentStore) GetByID(ctx context.Context, id int64) (*InvoicePa
 		&p.Amount,
 		&p.AmountCents,
 		&p.Status,
+		&p.Currency,
+		&p.ExchangeRateScaled,
+		&p.ForeignAmountCents,
+		&p.FXGainLossCents,
 		&p.CreatedAt,
 		&p.UpdatedAt,
 	)
@@ -185,7 +210,9 @@ func (s *InvoicePaymentStore) GetByIDTx(ctx context.Context, tx *sql.Tx, id int6
 	query := `
 		SELECT id, transaction_id, reference, date,
 		       invoice_id, user_id, account_id,
-		       amount, amount_cents, status, createdAt, updatedAt
+		       amount, amount_cents, status,
+		       currency, exchange_rate_scaled, foreign_amount_cents, fx_gain_loss_cents,
+		       createdAt, updatedAt
 		FROM invoice_payments
 		WHERE id = ?
 	`
@@ -205,6 +232,10 @@ func (s *InvoicePaymentStore) GetByIDTx(ctx context.Context, tx *sql.Tx, id int6
 		&p.Amount,
 		&p.AmountCents,
 		&p.Status,
+		&p.Currency,
+		&p.ExchangeRateScaled,
+		&p.ForeignAmountCents,
+		&p.FXGainLossCents,
 		&p.CreatedAt,
 		&p.UpdatedAt,
 	)
@@ -219,13 +250,30 @@ func (s *InvoicePaymentStore) GetByIDTx(ctx context.Context, tx *sql.Tx, id int6
 	return &p, nil
 }
 
+// IsReceivedPaymentTx reports whether a payment transaction belongs to a received payment,
+// which allocates one deposit across several invoices
+func (s *InvoicePaymentStore) IsReceivedPaymentTx(ctx context.Context, tx *sql.Tx, transactionID int64) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM received_payments WHERE transaction_id = ?)`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, query, transactionID).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
 func (s *InvoicePaymentStore) Create(ctx context.Context, tx *sql.Tx, p *InvoicePayment) (*InvoicePayment, error) {
 	query := `
 		INSERT INTO invoice_payments
 		(transaction_id, reference, date,
 		 invoice_id, user_id, account_id,
-		 amount, amount_cents, status)
-		VALUES (?, ?, ?, ?, ?, ?, ?,?, "1")
+		 amount, amount_cents, status,
+		 currency, exchange_rate_scaled, foreign_amount_cents, fx_gain_loss_cents)
+		VALUES (?, ?, ?, ?, ?, ?, ?,?, "1", ?, ?, ?, ?)
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
@@ -242,6 +290,10 @@ func (s *InvoicePaymentStore) Create(ctx context.Context, tx *sql.Tx, p *Invoice
 		p.AccountID,
 		p.Amount,
 		p.AmountCents,
+		p.Currency,
+		p.ExchangeRateScaled,
+		p.ForeignAmountCents,
+		p.FXGainLossCents,
 	)
 	if err != nil {
 		return nil, err
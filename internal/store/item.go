@@ -124,8 +124,8 @@ func (s *ItemStore) Create(ctx context.Context, i *Item) error {
 	query := `
 		INSERT INTO items
 		(name, type, description, asset_account, income_account, cogs_account,
-		 expense_account, on_hand, avg_cost, date, building_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 expense_account, on_hand, avg_cost, on_hand_scaled, value_cents, date, building_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 0, 0, ?, ?)
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
@@ -159,12 +159,13 @@ func (s *ItemStore) Create(ctx context.Context, i *Item) error {
 	return nil
 }
 
+// Update leaves on_hand and avg_cost alone; after the opening figures they only move with stock postings
 func (s *ItemStore) Update(ctx context.Context, i *Item) error {
 	query := `
 		UPDATE items
 		SET name = ?, type = ?, description = ?,
 		    asset_account = ?, income_account = ?, cogs_account = ?, expense_account = ?,
-		    on_hand = ?, avg_cost = ?, date = ?, building_id = ?,
+		    date = ?, building_id = ?,
 		    updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`
@@ -182,8 +183,6 @@ func (s *ItemStore) Update(ctx context.Context, i *Item) error {
 		i.IncomeAccount,
 		i.COGSAccount,
 		i.ExpenseAccount,
-		i.OnHand,
-		i.AvgCost,
 		i.Date,
 		i.BuildingID,
 		i.ID,
@@ -0,0 +1,183 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+type LateFeeAssessment struct {
+	ID            int64  `json:"id"`
+	PolicyID      int64  `json:"policy_id"`
+	InvoiceID     int64  `json:"invoice_id"`
+	FeeInvoiceID  int64  `json:"fee_invoice_id"`
+	Period        string `json:"period"` // YYYY-MM
+	ApplicationNo int    `json:"application_no"`
+	AmountCents   int64  `json:"amount_cents"`
+	AssessedDate  string `json:"assessed_date"`
+	BuildingID    int64  `json:"building_id"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// OverdueInvoice is an open invoice past its due date plus grace period
+type OverdueInvoice struct {
+	ID                 int64
+	InvoiceNo          string
+	DueDate            string
+	ARAccountID        int
+	UnitID             *int64
+	PeopleID           *int64
+	PeopleName         string
+	AmountCents        int64
+	BalanceCents       int64
+	Applications       int
+	AssessedThisPeriod bool
+}
+
+type LateFeeAssessmentStore struct {
+	db *sql.DB
+}
+
+func NewLateFeeAssessmentStore(db *sql.DB) *LateFeeAssessmentStore {
+	return &LateFeeAssessmentStore{db: db}
+}
+
+func (s *LateFeeAssessmentStore) GetAll(ctx context.Context, buildingID int64, invoiceID *int64) ([]LateFeeAssessment, error) {
+	query := `
+		SELECT id, policy_id, invoice_id, fee_invoice_id, period, application_no,
+		       amount_cents, DATE_FORMAT(assessed_date, '%Y-%m-%d'), building_id, created_at
+		FROM late_fee_assessments
+		WHERE building_id = ?
+	`
+
+	args := []any{buildingID}
+
+	if invoiceID != nil {
+		query += " AND invoice_id = ?"
+		args = append(args, *invoiceID)
+	}
+
+	query += " ORDER BY assessed_date DESC, id DESC"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var assessments []LateFeeAssessment
+	for rows.Next() {
+		var a LateFeeAssessment
+		if err := rows.Scan(
+			&a.ID,
+			&a.PolicyID,
+			&a.InvoiceID,
+			&a.FeeInvoiceID,
+			&a.Period,
+			&a.ApplicationNo,
+			&a.AmountCents,
+			&a.AssessedDate,
+			&a.BuildingID,
+			&a.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		assessments = append(assessments, a)
+	}
+
+	return assessments, nil
+}
+
+// GetOverdueInvoices returns open invoices whose due date plus grace days is before asOfDate.
+// Fee invoices created by earlier runs are excluded so fees are never charged on fees.
+func (s *LateFeeAssessmentStore) GetOverdueInvoices(ctx context.Context, buildingID int64, asOfDate string, graceDays int, period string) ([]OverdueInvoice, error) {
+	query := `
+		SELECT i.id, i.invoice_no, DATE_FORMAT(i.due_date, '%Y-%m-%d'), i.ar_account_id,
+			i.unit_id, i.people_id, IFNULL(p.name, ''), i.amount_cents,
+			i.amount_cents
+				- COALESCE((SELECT SUM(ip.amount_cents) FROM invoice_payments ip WHERE ip.invoice_id = i.id AND ip.status = '1'), 0)
+				- COALESCE((SELECT SUM(iac.amount_cents) FROM invoice_applied_credits iac WHERE iac.invoice_id = i.id AND iac.status = '1'), 0)
+				- COALESCE((SELECT SUM(iad.amount_cents) FROM invoice_applied_discounts iad WHERE iad.invoice_id = i.id AND iad.status = '1'), 0)
+				AS balance_cents,
+			(SELECT COUNT(*) FROM late_fee_assessments lfa WHERE lfa.invoice_id = i.id) AS applications,
+			EXISTS(SELECT 1 FROM late_fee_assessments lfa WHERE lfa.invoice_id = i.id AND lfa.period = ?) AS assessed
+		FROM invoices i
+		LEFT JOIN people p ON p.id = i.people_id
+		WHERE i.building_id = ?
+		  AND i.status = '1'
+		  AND i.people_id IS NOT NULL
+		  AND DATE_ADD(i.due_date, INTERVAL ? DAY) < ?
+		  AND i.id NOT IN (SELECT fee_invoice_id FROM late_fee_assessments)
+		HAVING balance_cents > 0
+		ORDER BY i.due_date, i.id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, period, buildingID, graceDays, asOfDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []OverdueInvoice
+	for rows.Next() {
+		var i OverdueInvoice
+		if err := rows.Scan(
+			&i.ID,
+			&i.InvoiceNo,
+			&i.DueDate,
+			&i.ARAccountID,
+			&i.UnitID,
+			&i.PeopleID,
+			&i.PeopleName,
+			&i.AmountCents,
+			&i.BalanceCents,
+			&i.Applications,
+			&i.AssessedThisPeriod,
+		); err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, i)
+	}
+
+	return invoices, nil
+}
+
+func (s *LateFeeAssessmentStore) Create(ctx context.Context, tx *sql.Tx, a *LateFeeAssessment) error {
+	query := `
+		INSERT INTO late_fee_assessments
+		(policy_id, invoice_id, fee_invoice_id, period, application_no, amount_cents, assessed_date, building_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		a.PolicyID,
+		a.InvoiceID,
+		a.FeeInvoiceID,
+		a.Period,
+		a.ApplicationNo,
+		a.AmountCents,
+		a.AssessedDate,
+		a.BuildingID,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	a.ID = id
+	return nil
+}
@@ -0,0 +1,224 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+type LateFeePolicy struct {
+	ID               int64  `json:"id"`
+	BuildingID       int64  `json:"building_id"`
+	Name             string `json:"name"`
+	FeeType          string `json:"fee_type"` // flat | percentage
+	FlatAmountCents  int64  `json:"flat_amount_cents"`
+	PercentageScaled int64  `json:"percentage_scaled"` // percent, 5 decimals (money.RateScale)
+	GraceDays        int    `json:"grace_days"`
+	MaxApplications  int    `json:"max_applications"`
+	ItemID           int64  `json:"item_id"` // income item used on the fee invoice
+	Status           string `json:"status"`  // enum('0','1')
+	CreatedAt        string `json:"created_at"`
+	UpdatedAt        string `json:"updated_at"`
+}
+
+type LateFeePolicyStore struct {
+	db *sql.DB
+}
+
+func NewLateFeePolicyStore(db *sql.DB) *LateFeePolicyStore {
+	return &LateFeePolicyStore{db: db}
+}
+
+func (s *LateFeePolicyStore) GetAll(ctx context.Context, buildingID int64) ([]LateFeePolicy, error) {
+	query := `
+		SELECT id, building_id, name, fee_type, flat_amount_cents, percentage_scaled,
+		       grace_days, max_applications, item_id, status, created_at, updated_at
+		FROM late_fee_policies
+		WHERE building_id = ?
+		ORDER BY id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, buildingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []LateFeePolicy
+	for rows.Next() {
+		var p LateFeePolicy
+		if err := rows.Scan(
+			&p.ID,
+			&p.BuildingID,
+			&p.Name,
+			&p.FeeType,
+			&p.FlatAmountCents,
+			&p.PercentageScaled,
+			&p.GraceDays,
+			&p.MaxApplications,
+			&p.ItemID,
+			&p.Status,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+
+	return policies, nil
+}
+
+func (s *LateFeePolicyStore) GetByID(ctx context.Context, id int64) (*LateFeePolicy, error) {
+	query := `
+		SELECT id, building_id, name, fee_type, flat_amount_cents, percentage_scaled,
+		       grace_days, max_applications, item_id, status, created_at, updated_at
+		FROM late_fee_policies
+		WHERE id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var p LateFeePolicy
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&p.ID,
+		&p.BuildingID,
+		&p.Name,
+		&p.FeeType,
+		&p.FlatAmountCents,
+		&p.PercentageScaled,
+		&p.GraceDays,
+		&p.MaxApplications,
+		&p.ItemID,
+		&p.Status,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func (s *LateFeePolicyStore) Create(ctx context.Context, p *LateFeePolicy) error {
+	query := `
+		INSERT INTO late_fee_policies
+		(building_id, name, fee_type, flat_amount_cents, percentage_scaled,
+		 grace_days, max_applications, item_id, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(
+		ctx,
+		query,
+		p.BuildingID,
+		p.Name,
+		p.FeeType,
+		p.FlatAmountCents,
+		p.PercentageScaled,
+		p.GraceDays,
+		p.MaxApplications,
+		p.ItemID,
+		p.Status,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	p.ID = id
+	return nil
+}
+
+func (s *LateFeePolicyStore) Update(ctx context.Context, p *LateFeePolicy) error {
+	query := `
+		UPDATE late_fee_policies
+		SET name = ?, fee_type = ?, flat_amount_cents = ?, percentage_scaled = ?,
+		    grace_days = ?, max_applications = ?, item_id = ?, status = ?,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(
+		ctx,
+		query,
+		p.Name,
+		p.FeeType,
+		p.FlatAmountCents,
+		p.PercentageScaled,
+		p.GraceDays,
+		p.MaxApplications,
+		p.ItemID,
+		p.Status,
+		p.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *LateFeePolicyStore) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM late_fee_policies WHERE id = ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// HasAssessments reports whether a policy has charged any late fee
+func (s *LateFeePolicyStore) HasAssessments(ctx context.Context, id int64) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM late_fee_assessments WHERE policy_id = ?)`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
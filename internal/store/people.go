@@ -15,6 +15,10 @@ type People struct {
 	CreatedAt  time.Time  `json:"created_at"`
 	UpdatedAt  time.Time  `json:"updated_at"`
 	Type       PeopleType `json:"type"`
+
+	// withholding tax deducted when paying this vendor, off when the rate is 0
+	WithholdingRateScaled int64  `json:"withholding_rate_scaled"` // percent, 5 decimals (money.RateScale)
+	WithholdingAccountID  *int64 `json:"withholding_account_id"`  // liability account the tax withheld is held in
 }
 
 type PeopleStore struct {
@@ -30,7 +34,9 @@ func (s *PeopleStore) GetAll(ctx context.Context, buildingID int64) ([]People, e
 			pt.id,
 			pt.title,
 			p.created_at,
-			p.updated_at
+			p.updated_at,
+			p.withholding_rate_scaled,
+			p.withholding_account_id
 		FROM people p
 		JOIN people_types pt ON pt.id = p.type_id
 		WHERE p.building_id = ?
@@ -57,6 +63,8 @@ func (s *PeopleStore) GetAll(ctx context.Context, buildingID int64) ([]People, e
 			&p.Type.Title,
 			&p.CreatedAt,
 			&p.UpdatedAt,
+			&p.WithholdingRateScaled,
+			&p.WithholdingAccountID,
 		); err != nil {
 			return nil, err
 		}
@@ -68,13 +76,28 @@ func (s *PeopleStore) GetAll(ctx context.Context, buildingID int64) ([]People, e
 }
 
 func (s *PeopleStore) GetByID(ctx context.Context, id int64) (*People, error) {
-	query := `SELECT id, name, phone, type_id, building_id, created_at, updated_at FROM people WHERE id = ?`
+	query := `
+		SELECT id, name, phone, type_id, building_id, created_at, updated_at,
+		       withholding_rate_scaled, withholding_account_id
+		FROM people
+		WHERE id = ?
+	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
 	defer cancel()
 
 	var p People
-	err := s.db.QueryRowContext(ctx, query, id).Scan(&p.ID, &p.Name, &p.Phone, &p.TypeID, &p.BuildingID, &p.CreatedAt, &p.UpdatedAt)
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&p.ID,
+		&p.Name,
+		&p.Phone,
+		&p.TypeID,
+		&p.BuildingID,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+		&p.WithholdingRateScaled,
+		&p.WithholdingAccountID,
+	)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrNotFound
@@ -128,6 +151,34 @@ func (s *PeopleStore) Update(ctx context.Context, p *People) error {
 	return nil
 }
 
+// UpdateWithholding sets the vendor's withholding tax; Update leaves it as it is
+func (s *PeopleStore) UpdateWithholding(ctx context.Context, id int64, rateScaled int64, accountID *int64) error {
+	query := `
+		UPDATE people
+		SET withholding_rate_scaled = ?, withholding_account_id = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, rateScaled, accountID, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
 func (s *PeopleStore) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM people WHERE id = ?`
 
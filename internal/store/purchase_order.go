@@ -0,0 +1,441 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+// PurchaseOrder is what a vendor was asked to supply before their bill arrives
+type PurchaseOrder struct {
+	ID              int64   `json:"id"`
+	PONo            string  `json:"po_no"`
+	PODate          string  `json:"po_date"`
+	ExpectedDate    *string `json:"expected_date"`
+	PeopleID        int64   `json:"people_id"`
+	PeopleName      string  `json:"people_name"`
+	UnitID          *int64  `json:"unit_id"`
+	Description     string  `json:"description"`
+	AmountCents     int64   `json:"amount_cents"`
+	BilledCents     int64   `json:"billed_cents"`     // billed by bills that are neither cancelled nor rejected
+	ToleranceScaled int64   `json:"tolerance_scaled"` // percent, 5 decimals
+	Status          string  `json:"status"`           // open | partially_billed | closed
+	ManuallyClosed  string  `json:"manually_closed"`  // enum('0','1')
+	UserID          int64   `json:"user_id"`
+	BuildingID      int64   `json:"building_id"`
+	CreatedAt       string  `json:"created_at"`
+	UpdatedAt       string  `json:"updated_at"`
+}
+
+// PurchaseOrderLine orders a qty of an item or of an expense account at a rate
+type PurchaseOrderLine struct {
+	ID              int64   `json:"id"`
+	PurchaseOrderID int64   `json:"purchase_order_id"`
+	ItemID          *int64  `json:"item_id"`
+	ItemName        *string `json:"item_name"`
+	AccountID       int64   `json:"account_id"` // the item's expense or asset account for item lines
+	UnitID          *int64  `json:"unit_id"`
+	Description     *string `json:"description"`
+	QtyScaled       int64   `json:"qty_scaled"`
+	RateScaled      int64   `json:"rate_scaled"`
+	AmountCents     int64   `json:"amount_cents"`
+	BilledQtyScaled int64   `json:"billed_qty_scaled"`
+	BilledCents     int64   `json:"billed_cents"`
+}
+
+type PurchaseOrderStore struct {
+	db *sql.DB
+}
+
+func NewPurchaseOrderStore(db *sql.DB) *PurchaseOrderStore {
+	return &PurchaseOrderStore{db: db}
+}
+
+// billedLines are the bill lines that count against a purchase order line
+const billedLines = `
+	FROM bill_expense_lines bel
+	JOIN bills b ON b.id = bel.bill_id
+	WHERE b.status = '1' AND b.approval_status <> 'rejected'
+`
+
+const purchaseOrderColumns = `
+	po.id, po.po_no, DATE_FORMAT(po.po_date, '%Y-%m-%d'), DATE_FORMAT(po.expected_date, '%Y-%m-%d'),
+	po.people_id, p.name, po.unit_id, COALESCE(po.description, ''), po.amount_cents,
+	(SELECT COALESCE(SUM(bel.amount_cents), 0) ` + billedLines + `
+	   AND bel.po_line_id IN (SELECT l.id FROM purchase_order_lines l WHERE l.purchase_order_id = po.id)),
+	po.tolerance_scaled, po.status, po.manually_closed, po.user_id, po.building_id,
+	po.created_at, po.updated_at
+`
+
+func scanPurchaseOrder(scan func(dest ...any) error, po *PurchaseOrder) error {
+	return scan(
+		&po.ID,
+		&po.PONo,
+		&po.PODate,
+		&po.ExpectedDate,
+		&po.PeopleID,
+		&po.PeopleName,
+		&po.UnitID,
+		&po.Description,
+		&po.AmountCents,
+		&po.BilledCents,
+		&po.ToleranceScaled,
+		&po.Status,
+		&po.ManuallyClosed,
+		&po.UserID,
+		&po.BuildingID,
+		&po.CreatedAt,
+		&po.UpdatedAt,
+	)
+}
+
+func (s *PurchaseOrderStore) GetAll(ctx context.Context, buildingID int64, peopleID *int64, status *string) ([]PurchaseOrder, error) {
+	query := `
+		SELECT ` + purchaseOrderColumns + `
+		FROM purchase_orders po
+		JOIN people p ON p.id = po.people_id
+		WHERE po.building_id = ?
+	`
+
+	args := []any{buildingID}
+
+	if peopleID != nil && *peopleID > 0 {
+		query += " AND po.people_id = ?"
+		args = append(args, *peopleID)
+	}
+	if status != nil && *status != "" {
+		query += " AND po.status = ?"
+		args = append(args, *status)
+	}
+
+	query += " ORDER BY po.po_date DESC, po.id DESC"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []PurchaseOrder
+	for rows.Next() {
+		var po PurchaseOrder
+		if err := scanPurchaseOrder(rows.Scan, &po); err != nil {
+			return nil, err
+		}
+		orders = append(orders, po)
+	}
+
+	return orders, nil
+}
+
+func (s *PurchaseOrderStore) GetByID(ctx context.Context, id int64) (*PurchaseOrder, error) {
+	query := `
+		SELECT ` + purchaseOrderColumns + `
+		FROM purchase_orders po
+		JOIN people p ON p.id = po.people_id
+		WHERE po.id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var po PurchaseOrder
+	if err := scanPurchaseOrder(s.db.QueryRowContext(ctx, query, id).Scan, &po); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &po, nil
+}
+
+// GetByIDTx locks the purchase order so two bills cannot match against it at once
+func (s *PurchaseOrderStore) GetByIDTx(ctx context.Context, tx *sql.Tx, id int64) (*PurchaseOrder, error) {
+	query := `
+		SELECT ` + purchaseOrderColumns + `
+		FROM purchase_orders po
+		JOIN people p ON p.id = po.people_id
+		WHERE po.id = ?
+		FOR UPDATE
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var po PurchaseOrder
+	if err := scanPurchaseOrder(tx.QueryRowContext(ctx, query, id).Scan, &po); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &po, nil
+}
+
+func (s *PurchaseOrderStore) Create(ctx context.Context, tx *sql.Tx, po *PurchaseOrder) error {
+	query := `
+		INSERT INTO purchase_orders
+		(po_no, po_date, expected_date, people_id, unit_id, description, amount_cents,
+		 tolerance_scaled, status, manually_closed, user_id, building_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, 'open', '0', ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query,
+		po.PONo,
+		po.PODate,
+		po.ExpectedDate,
+		po.PeopleID,
+		po.UnitID,
+		po.Description,
+		po.AmountCents,
+		po.ToleranceScaled,
+		po.UserID,
+		po.BuildingID,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	po.ID = id
+	po.Status = "open"
+	po.ManuallyClosed = "0"
+	return nil
+}
+
+func (s *PurchaseOrderStore) Update(ctx context.Context, tx *sql.Tx, po *PurchaseOrder) error {
+	query := `
+		UPDATE purchase_orders
+		SET po_no = ?, po_date = ?, expected_date = ?, people_id = ?, unit_id = ?, description = ?,
+		    amount_cents = ?, tolerance_scaled = ?, user_id = ?
+		WHERE id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query,
+		po.PONo,
+		po.PODate,
+		po.ExpectedDate,
+		po.PeopleID,
+		po.UnitID,
+		po.Description,
+		po.AmountCents,
+		po.ToleranceScaled,
+		po.UserID,
+		po.ID,
+	)
+	return err
+}
+
+// Delete removes the purchase order and, by cascade, its lines
+func (s *PurchaseOrderStore) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM purchase_orders WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *PurchaseOrderStore) SetStatusTx(ctx context.Context, tx *sql.Tx, id int64, status, manuallyClosed string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, `UPDATE purchase_orders SET status = ?, manually_closed = ? WHERE id = ?`, status, manuallyClosed, id)
+	return err
+}
+
+// HasBills reports whether any bill line, cancelled or not, points at the purchase order
+func (s *PurchaseOrderStore) HasBills(ctx context.Context, id int64) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM bill_expense_lines bel
+			JOIN purchase_order_lines l ON l.id = bel.po_line_id
+			WHERE l.purchase_order_id = ?
+		)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var has bool
+	if err := s.db.QueryRowContext(ctx, query, id).Scan(&has); err != nil {
+		return false, err
+	}
+
+	return has, nil
+}
+
+// GetIDsByBillTx returns the purchase orders a bill's lines are matched against
+func (s *PurchaseOrderStore) GetIDsByBillTx(ctx context.Context, tx *sql.Tx, billID int64) ([]int64, error) {
+	query := `
+		SELECT DISTINCT l.purchase_order_id
+		FROM bill_expense_lines bel
+		JOIN purchase_order_lines l ON l.id = bel.po_line_id
+		WHERE bel.bill_id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, query, billID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+const purchaseOrderLineColumns = `
+	l.id, l.purchase_order_id, l.item_id, i.name, l.account_id, l.unit_id, l.description,
+	l.qty_scaled, l.rate_scaled, l.amount_cents,
+	(SELECT COALESCE(SUM(bel.qty_scaled), 0) ` + billedLines + ` AND bel.po_line_id = l.id AND bel.bill_id <> ?),
+	(SELECT COALESCE(SUM(bel.amount_cents), 0) ` + billedLines + ` AND bel.po_line_id = l.id AND bel.bill_id <> ?)
+`
+
+func scanPurchaseOrderLine(scan func(dest ...any) error, l *PurchaseOrderLine) error {
+	return scan(
+		&l.ID,
+		&l.PurchaseOrderID,
+		&l.ItemID,
+		&l.ItemName,
+		&l.AccountID,
+		&l.UnitID,
+		&l.Description,
+		&l.QtyScaled,
+		&l.RateScaled,
+		&l.AmountCents,
+		&l.BilledQtyScaled,
+		&l.BilledCents,
+	)
+}
+
+const purchaseOrderLinesQuery = `
+	SELECT ` + purchaseOrderLineColumns + `
+	FROM purchase_order_lines l
+	LEFT JOIN items i ON i.id = l.item_id
+	WHERE l.purchase_order_id = ?
+	ORDER BY l.id
+`
+
+// GetLines returns the lines of a purchase order with what has been billed against each
+func (s *PurchaseOrderStore) GetLines(ctx context.Context, purchaseOrderID int64) ([]PurchaseOrderLine, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, purchaseOrderLinesQuery, 0, 0, purchaseOrderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []PurchaseOrderLine
+	for rows.Next() {
+		var l PurchaseOrderLine
+		if err := scanPurchaseOrderLine(rows.Scan, &l); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+
+	return lines, nil
+}
+
+// GetLinesTx is GetLines inside a transaction, leaving out what excludeBillID has billed
+// so a bill being edited is not matched against itself
+func (s *PurchaseOrderStore) GetLinesTx(ctx context.Context, tx *sql.Tx, purchaseOrderID, excludeBillID int64) ([]PurchaseOrderLine, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, purchaseOrderLinesQuery, excludeBillID, excludeBillID, purchaseOrderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []PurchaseOrderLine
+	for rows.Next() {
+		var l PurchaseOrderLine
+		if err := scanPurchaseOrderLine(rows.Scan, &l); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+
+	return lines, nil
+}
+
+func (s *PurchaseOrderStore) CreateLine(ctx context.Context, tx *sql.Tx, l *PurchaseOrderLine) error {
+	query := `
+		INSERT INTO purchase_order_lines
+		(purchase_order_id, item_id, account_id, unit_id, description, qty_scaled, rate_scaled, amount_cents)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query,
+		l.PurchaseOrderID,
+		l.ItemID,
+		l.AccountID,
+		l.UnitID,
+		l.Description,
+		l.QtyScaled,
+		l.RateScaled,
+		l.AmountCents,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	l.ID = id
+	return nil
+}
+
+func (s *PurchaseOrderStore) DeleteLines(ctx context.Context, tx *sql.Tx, purchaseOrderID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, `DELETE FROM purchase_order_lines WHERE purchase_order_id = ?`, purchaseOrderID)
+	return err
+}
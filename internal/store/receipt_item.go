@@ -20,6 +20,10 @@ type ReceiptItem struct {
 	Total float64 `json:"total"`
 	Status int    `json:"status"` // enum('0','1')
 
+	TaxCodeID    *int64 `json:"tax_code_id"`
+	TaxableCents int64  `json:"taxable_cents"` // net of tax
+	TaxCents     int64  `json:"tax_cents"`
+
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 }
@@ -41,7 +45,8 @@ func (s *ReceiptItemStore) GetByReceiptID(
 		SELECT
 			id, receipt_id, item_id, item_name,
 			previous_value, current_value, qty, rate,
-			total, status, created_at, updated_at
+			total, status, created_at, updated_at,
+			tax_code_id, taxable_cents, tax_cents
 		FROM receipt_items
 		WHERE receipt_id = ? AND status = '1'
 		ORDER BY id ASC
@@ -73,6 +78,9 @@ func (s *ReceiptItemStore) GetByReceiptID(
 			&i.Status,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.TaxCodeID,
+			&i.TaxableCents,
+			&i.TaxCents,
 		); err != nil {
 			return nil, err
 		}
@@ -93,8 +101,8 @@ func (s *ReceiptItemStore) Create(
 		INSERT INTO receipt_items
 		(receipt_id, item_id, item_name,
 		 previous_value, current_value, qty, rate,
-		 total, status)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, '1')
+		 total, status, tax_code_id, taxable_cents, tax_cents)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, '1', ?, ?, ?)
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
@@ -111,6 +119,9 @@ func (s *ReceiptItemStore) Create(
 		i.Qty,
 		i.Rate,
 		i.Total,
+		i.TaxCodeID,
+		i.TaxableCents,
+		i.TaxCents,
 	)
 	if err != nil {
 		return nil, err
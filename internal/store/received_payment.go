@@ -0,0 +1,290 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+type ReceivedPayment struct {
+	ID             int64  `json:"id"`
+	TransactionID  int64  `json:"transaction_id"`
+	Reference      string `json:"reference"`
+	Date           string `json:"date"`
+	PeopleID       int64  `json:"people_id"`
+	PeopleName     string `json:"people_name"`
+	AccountID      int64  `json:"account_id"`
+	AmountCents    int64  `json:"amount_cents"`
+	UnappliedCents int64  `json:"unapplied_cents"`
+	CreditMemoID   *int64 `json:"credit_memo_id"`
+	Memo           string `json:"memo"`
+	UserID         int64  `json:"user_id"`
+	BuildingID     int64  `json:"building_id"`
+	Status         string `json:"status"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+}
+
+// ReceivedPaymentAllocation is one invoice_payments row written by a received payment
+type ReceivedPaymentAllocation struct {
+	InvoicePaymentID int64
+	InvoiceID        int64
+	InvoiceNo        string
+	AmountCents      int64
+}
+
+// CustomerOpenInvoice is an invoice of one customer that still has a balance
+type CustomerOpenInvoice struct {
+	ID           int64
+	InvoiceNo    string
+	SalesDate    string
+	DueDate      string
+	ARAccountID  int
+	UnitID       *int64
+	PeopleID     int64
+	BalanceCents int64
+}
+
+type ReceivedPaymentStore struct {
+	db *sql.DB
+}
+
+func NewReceivedPaymentStore(db *sql.DB) *ReceivedPaymentStore {
+	return &ReceivedPaymentStore{db: db}
+}
+
+func (s *ReceivedPaymentStore) GetAll(ctx context.Context, buildingID int64, peopleID *int) ([]ReceivedPayment, error) {
+	query := `
+		SELECT rp.id, rp.transaction_id, rp.reference, DATE_FORMAT(rp.date, '%Y-%m-%d'),
+		       rp.people_id, p.name, rp.account_id, rp.amount_cents, rp.unapplied_cents,
+		       rp.credit_memo_id, rp.memo, rp.user_id, rp.building_id, rp.status,
+		       rp.created_at, rp.updated_at
+		FROM received_payments rp
+		JOIN people p ON p.id = rp.people_id
+		WHERE rp.building_id = ?
+	`
+
+	args := []any{buildingID}
+
+	if peopleID != nil {
+		query += " AND rp.people_id = ?"
+		args = append(args, *peopleID)
+	}
+
+	query += " ORDER BY rp.date DESC, rp.id DESC"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []ReceivedPayment
+	for rows.Next() {
+		var p ReceivedPayment
+		if err := rows.Scan(
+			&p.ID,
+			&p.TransactionID,
+			&p.Reference,
+			&p.Date,
+			&p.PeopleID,
+			&p.PeopleName,
+			&p.AccountID,
+			&p.AmountCents,
+			&p.UnappliedCents,
+			&p.CreditMemoID,
+			&p.Memo,
+			&p.UserID,
+			&p.BuildingID,
+			&p.Status,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+
+	return payments, nil
+}
+
+func (s *ReceivedPaymentStore) GetByID(ctx context.Context, id int64) (*ReceivedPayment, error) {
+	query := `
+		SELECT rp.id, rp.transaction_id, rp.reference, DATE_FORMAT(rp.date, '%Y-%m-%d'),
+		       rp.people_id, p.name, rp.account_id, rp.amount_cents, rp.unapplied_cents,
+		       rp.credit_memo_id, rp.memo, rp.user_id, rp.building_id, rp.status,
+		       rp.created_at, rp.updated_at
+		FROM received_payments rp
+		JOIN people p ON p.id = rp.people_id
+		WHERE rp.id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var p ReceivedPayment
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&p.ID,
+		&p.TransactionID,
+		&p.Reference,
+		&p.Date,
+		&p.PeopleID,
+		&p.PeopleName,
+		&p.AccountID,
+		&p.AmountCents,
+		&p.UnappliedCents,
+		&p.CreditMemoID,
+		&p.Memo,
+		&p.UserID,
+		&p.BuildingID,
+		&p.Status,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// GetAllocations returns the invoice payments posted on the received payment's transaction
+func (s *ReceivedPaymentStore) GetAllocations(ctx context.Context, transactionID int64) ([]ReceivedPaymentAllocation, error) {
+	query := `
+		SELECT ip.id, ip.invoice_id, i.invoice_no, ip.amount_cents
+		FROM invoice_payments ip
+		JOIN invoices i ON i.id = ip.invoice_id
+		WHERE ip.transaction_id = ?
+		ORDER BY ip.id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var allocations []ReceivedPaymentAllocation
+	for rows.Next() {
+		var a ReceivedPaymentAllocation
+		if err := rows.Scan(&a.InvoicePaymentID, &a.InvoiceID, &a.InvoiceNo, &a.AmountCents); err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, a)
+	}
+
+	return allocations, nil
+}
+
+// customerOpenInvoicesQuery selects a customer's invoices with a balance, oldest due date first
+const customerOpenInvoicesQuery = `
+	SELECT i.id, i.invoice_no, DATE_FORMAT(i.sales_date, '%Y-%m-%d'), DATE_FORMAT(i.due_date, '%Y-%m-%d'),
+		i.ar_account_id, i.unit_id, i.people_id,
+		i.amount_cents
+			- COALESCE((SELECT SUM(ip.amount_cents) FROM invoice_payments ip WHERE ip.invoice_id = i.id AND ip.status = '1'), 0)
+			- COALESCE((SELECT SUM(iac.amount_cents) FROM invoice_applied_credits iac WHERE iac.invoice_id = i.id AND iac.status = '1'), 0)
+			- COALESCE((SELECT SUM(iad.amount_cents) FROM invoice_applied_discounts iad WHERE iad.invoice_id = i.id AND iad.status = '1'), 0)
+			AS balance_cents
+	FROM invoices i
+	WHERE i.building_id = ? AND i.people_id = ? AND i.status = '1'
+		AND i.currency IS NULL -- foreign currency invoices are paid one at a time
+	HAVING balance_cents > 0
+	ORDER BY i.due_date, i.sales_date, i.id
+`
+
+// GetOpenInvoices returns the customer's invoices with a balance, oldest due date first
+func (s *ReceivedPaymentStore) GetOpenInvoices(ctx context.Context, buildingID int64, peopleID int64) ([]CustomerOpenInvoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, customerOpenInvoicesQuery, buildingID, peopleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCustomerOpenInvoices(rows)
+}
+
+// GetOpenInvoicesTx is GetOpenInvoices locking the invoice rows, so concurrent payments see each other
+func (s *ReceivedPaymentStore) GetOpenInvoicesTx(ctx context.Context, tx *sql.Tx, buildingID int64, peopleID int64) ([]CustomerOpenInvoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := tx.QueryContext(ctx, customerOpenInvoicesQuery+" FOR UPDATE", buildingID, peopleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCustomerOpenInvoices(rows)
+}
+
+func scanCustomerOpenInvoices(rows *sql.Rows) ([]CustomerOpenInvoice, error) {
+	var invoices []CustomerOpenInvoice
+	for rows.Next() {
+		var i CustomerOpenInvoice
+		if err := rows.Scan(
+			&i.ID,
+			&i.InvoiceNo,
+			&i.SalesDate,
+			&i.DueDate,
+			&i.ARAccountID,
+			&i.UnitID,
+			&i.PeopleID,
+			&i.BalanceCents,
+		); err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, i)
+	}
+
+	return invoices, rows.Err()
+}
+
+func (s *ReceivedPaymentStore) Create(ctx context.Context, tx *sql.Tx, p *ReceivedPayment) error {
+	query := `
+		INSERT INTO received_payments
+		(transaction_id, reference, date, people_id, account_id, amount_cents,
+		 unapplied_cents, credit_memo_id, memo, user_id, building_id, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, '1')
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		p.TransactionID,
+		p.Reference,
+		p.Date,
+		p.PeopleID,
+		p.AccountID,
+		p.AmountCents,
+		p.UnappliedCents,
+		p.CreditMemoID,
+		p.Memo,
+		p.UserID,
+		p.BuildingID,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	p.ID = id
+	return nil
+}
@@ -0,0 +1,386 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+type Reconciliation struct {
+	ID                    int64   `json:"id"`
+	AccountID             int64   `json:"account_id"`
+	AccountName           string  `json:"account_name"`
+	BuildingID            int64   `json:"building_id"`
+	StatementDate         string  `json:"statement_date"`
+	BeginningBalanceCents int64   `json:"beginning_balance_cents"`
+	EndingBalanceCents    int64   `json:"ending_balance_cents"`
+	Status                string  `json:"status"` // open | finished
+	FinishedAt            *string `json:"finished_at"`
+	UserID                int64   `json:"user_id"`
+	CreatedAt             string  `json:"created_at"`
+	UpdatedAt             string  `json:"updated_at"`
+}
+
+// ReconciliationItem is a split of the reconciled account up to the statement date
+type ReconciliationItem struct {
+	SplitID       int64
+	TransactionID int64
+	Type          string
+	Date          string
+	Reference     string
+	PeopleName    string
+	DebitCents    int64
+	CreditCents   int64
+	Cleared       bool
+}
+
+type ReconciliationStore struct {
+	db *sql.DB
+}
+
+func NewReconciliationStore(db *sql.DB) *ReconciliationStore {
+	return &ReconciliationStore{db: db}
+}
+
+const reconciliationColumns = `
+	r.id, r.account_id, a.account_name, r.building_id, DATE_FORMAT(r.statement_date, '%Y-%m-%d'),
+	r.beginning_balance_cents, r.ending_balance_cents, r.status, r.finished_at, r.user_id,
+	r.created_at, r.updated_at
+`
+
+func scanReconciliation(scan func(dest ...any) error, r *Reconciliation) error {
+	return scan(
+		&r.ID,
+		&r.AccountID,
+		&r.AccountName,
+		&r.BuildingID,
+		&r.StatementDate,
+		&r.BeginningBalanceCents,
+		&r.EndingBalanceCents,
+		&r.Status,
+		&r.FinishedAt,
+		&r.UserID,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	)
+}
+
+func (s *ReconciliationStore) GetAll(ctx context.Context, buildingID int64, accountID *int64) ([]Reconciliation, error) {
+	query := `SELECT ` + reconciliationColumns + `
+		FROM reconciliations r
+		JOIN accounts a ON a.id = r.account_id
+		WHERE r.building_id = ?
+	`
+
+	args := []any{buildingID}
+
+	if accountID != nil {
+		query += " AND r.account_id = ?"
+		args = append(args, *accountID)
+	}
+
+	query += " ORDER BY r.statement_date DESC, r.id DESC"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reconciliations []Reconciliation
+	for rows.Next() {
+		var r Reconciliation
+		if err := scanReconciliation(rows.Scan, &r); err != nil {
+			return nil, err
+		}
+		reconciliations = append(reconciliations, r)
+	}
+
+	return reconciliations, nil
+}
+
+func (s *ReconciliationStore) GetByID(ctx context.Context, id int64) (*Reconciliation, error) {
+	query := `SELECT ` + reconciliationColumns + `
+		FROM reconciliations r
+		JOIN accounts a ON a.id = r.account_id
+		WHERE r.id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var r Reconciliation
+	if err := scanReconciliation(s.db.QueryRowContext(ctx, query, id).Scan, &r); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// GetLatest returns the account's most recent reconciliation of any status
+func (s *ReconciliationStore) GetLatest(ctx context.Context, accountID int64) (*Reconciliation, error) {
+	query := `SELECT ` + reconciliationColumns + `
+		FROM reconciliations r
+		JOIN accounts a ON a.id = r.account_id
+		WHERE r.account_id = ?
+		ORDER BY r.statement_date DESC, r.id DESC
+		LIMIT 1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var r Reconciliation
+	if err := scanReconciliation(s.db.QueryRowContext(ctx, query, accountID).Scan, &r); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// GetLatestFinished returns the account's most recent finished reconciliation
+func (s *ReconciliationStore) GetLatestFinished(ctx context.Context, accountID int64) (*Reconciliation, error) {
+	query := `SELECT ` + reconciliationColumns + `
+		FROM reconciliations r
+		JOIN accounts a ON a.id = r.account_id
+		WHERE r.account_id = ? AND r.status = 'finished'
+		ORDER BY r.statement_date DESC, r.id DESC
+		LIMIT 1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var r Reconciliation
+	if err := scanReconciliation(s.db.QueryRowContext(ctx, query, accountID).Scan, &r); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// GetItems returns the account's active splits up to the statement date that no finished
+// reconciliation has cleared, flagged with whether this reconciliation clears them
+func (s *ReconciliationStore) GetItems(ctx context.Context, r *Reconciliation) ([]ReconciliationItem, error) {
+	query := `
+		SELECT sp.id, t.id, t.type, DATE_FORMAT(t.transaction_date, '%Y-%m-%d'), t.transaction_number,
+		       IFNULL(p.name, ''), IFNULL(sp.debit_cents, 0), IFNULL(sp.credit_cents, 0),
+		       EXISTS (SELECT 1 FROM reconciliation_items ri WHERE ri.reconciliation_id = ? AND ri.split_id = sp.id)
+		FROM splits sp
+		JOIN transactions t ON t.id = sp.transaction_id
+		LEFT JOIN people p ON p.id = sp.people_id
+		WHERE sp.account_id = ?
+		  AND sp.status = '1'
+		  AND t.status = '1'
+		  AND t.transaction_date <= ?
+		  AND NOT EXISTS (
+			SELECT 1 FROM reconciliation_items ri
+			JOIN reconciliations r2 ON r2.id = ri.reconciliation_id
+			WHERE ri.split_id = sp.id AND r2.status = 'finished' AND r2.id <> ?
+		  )
+		ORDER BY t.transaction_date, sp.id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, r.ID, r.AccountID, r.StatementDate, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []ReconciliationItem
+	for rows.Next() {
+		var i ReconciliationItem
+		if err := rows.Scan(
+			&i.SplitID,
+			&i.TransactionID,
+			&i.Type,
+			&i.Date,
+			&i.Reference,
+			&i.PeopleName,
+			&i.DebitCents,
+			&i.CreditCents,
+			&i.Cleared,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+
+	return items, nil
+}
+
+// GetBookBalance returns debits less credits on the account up to and including date
+func (s *ReconciliationStore) GetBookBalance(ctx context.Context, accountID int64, date string) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(IFNULL(sp.debit_cents, 0) - IFNULL(sp.credit_cents, 0)), 0)
+		FROM splits sp
+		JOIN transactions t ON t.id = sp.transaction_id
+		WHERE sp.account_id = ? AND sp.status = '1' AND t.status = '1' AND t.transaction_date <= ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var balance int64
+	if err := s.db.QueryRowContext(ctx, query, accountID, date).Scan(&balance); err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+func (s *ReconciliationStore) Create(ctx context.Context, r *Reconciliation) error {
+	query := `
+		INSERT INTO reconciliations
+		(account_id, building_id, statement_date, beginning_balance_cents, ending_balance_cents, status, user_id)
+		VALUES (?, ?, ?, ?, ?, 'open', ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(
+		ctx,
+		query,
+		r.AccountID,
+		r.BuildingID,
+		r.StatementDate,
+		r.BeginningBalanceCents,
+		r.EndingBalanceCents,
+		r.UserID,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	r.ID = id
+	r.Status = "open"
+	return nil
+}
+
+// Update changes the statement of an open reconciliation
+func (s *ReconciliationStore) Update(ctx context.Context, r *Reconciliation) error {
+	query := `
+		UPDATE reconciliations
+		SET statement_date = ?, ending_balance_cents = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = 'open'
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, r.StatementDate, r.EndingBalanceCents, r.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// SetCleared ticks or unticks splits on an open reconciliation
+func (s *ReconciliationStore) SetCleared(ctx context.Context, tx *sql.Tx, reconciliationID int64, splitIDs []int64, cleared bool) error {
+	if len(splitIDs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	if cleared {
+		query := `INSERT IGNORE INTO reconciliation_items (reconciliation_id, split_id) VALUES (?, ?)`
+		for _, splitID := range splitIDs {
+			if _, err := tx.ExecContext(ctx, query, reconciliationID, splitID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(splitIDs)), ",")
+	query := `DELETE FROM reconciliation_items WHERE reconciliation_id = ? AND split_id IN (` + placeholders + `)`
+
+	args := []any{reconciliationID}
+	for _, splitID := range splitIDs {
+		args = append(args, splitID)
+	}
+
+	_, err := tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (s *ReconciliationStore) Finish(ctx context.Context, id int64) error {
+	query := `
+		UPDATE reconciliations
+		SET status = 'finished', finished_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = 'open'
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete removes an open reconciliation and its ticks; finished ones are kept
+func (s *ReconciliationStore) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM reconciliations WHERE id = ? AND status = 'open'`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
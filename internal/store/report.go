@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"database/sql"
+	"strconv"
 	"strings"
 )
 
@@ -352,6 +353,8 @@ type VendorBalanceDetail struct {
 	Credit            *int64
 }
 
+// Vendor credits debit A/P with the vendor on the split, so they show here and net the balance.
+// Applying one to a bill posts nothing; it only moves open balance between the two documents.
 func (s *ReportStore) GetVendorBalanceDetail(ctx context.Context, buildingID int, asOfDate string, peopleID *int) ([]VendorBalanceDetail, error) {
 	query := `
 		SELECT p.id people_id ,p.name,ac.id account_id,ac.account_number,ac.account_name,t.transaction_date,t.transaction_number,t.type,t.memo,s.debit_cents,s.credit_cents FROM splits s
@@ -491,6 +494,7 @@ WHERE s.status = '1' and t.transaction_date between ? and ? and t.building_id =
 }
 
 type PLAccountRow struct {
+	AccountID     int     `json:"account_id"`
 	AccountNumber int     `json:"account_number"`
 	AccountName   string  `json:"account_name"`
 	AccountType   string  `json:"typeName"`
@@ -504,6 +508,7 @@ type PLAccountRow struct {
 func (s *ReportStore) GetAccountBalanceByAccountType(ctx context.Context, buildingID int, startDate string, endDate string, accountType string) ([]PLAccountRow, error) {
 	query := `
 		SELECT 
+	ac.id,
 	ac.account_number,
     ac.account_name,
     at.typeName,
@@ -543,6 +548,7 @@ ORDER BY ac.account_number;
 	for rows.Next() {
 		var plAccount PLAccountRow
 		if err := rows.Scan(
+			&plAccount.AccountID,
 			&plAccount.AccountNumber,
 			&plAccount.AccountName,
 			&plAccount.AccountType,
@@ -632,3 +638,671 @@ func (s *ReportStore) GetAccountBalanceByAccountTypeAndUnit(ctx context.Context,
 	}
 	return plAccounts, nil
 }
+
+// DateRange is an inclusive range of transaction dates; an empty Start runs from the first transaction
+type DateRange struct {
+	Start string
+	End   string
+}
+
+// AccountRangeTotal is what an account's splits add up to within one of the requested date ranges
+type AccountRangeTotal struct {
+	AccountID         int
+	AccountNumber     int
+	AccountName       string
+	AccountType       string // Asset, Liability, Equity, Income or Expense
+	AccountTypeName   string
+	AccountTypeStatus string
+	CashFlowCategory  string
+	Range             int // index into the requested ranges
+	DebitCents        int64
+	CreditCents       int64
+}
+
+// GetAccountTotalsByRange totals every account's splits in each of several date ranges with one
+// grouped query. The ranges must not overlap; splits outside all of them are left out.
+func (s *ReportStore) GetAccountTotalsByRange(ctx context.Context, buildingID int, ranges []DateRange) ([]AccountRangeTotal, error) {
+	if len(ranges) == 0 {
+		return nil, nil
+	}
+
+	args := []any{}
+	dateRange := "CASE"
+	for i, r := range ranges {
+		if r.Start == "" {
+			dateRange += " WHEN t.transaction_date <= ? THEN " + strconv.Itoa(i)
+			args = append(args, r.End)
+		} else {
+			dateRange += " WHEN t.transaction_date BETWEEN ? AND ? THEN " + strconv.Itoa(i)
+			args = append(args, r.Start, r.End)
+		}
+	}
+	dateRange += " END"
+
+	query := `
+		SELECT a.id, a.account_number, a.account_name, at.type, at.typeName, at.typeStatus, at.cash_flow_category,
+			` + dateRange + ` AS date_range,
+			COALESCE(SUM(s.debit_cents), 0), COALESCE(SUM(s.credit_cents), 0)
+		FROM splits s
+		JOIN transactions t ON t.id = s.transaction_id
+		JOIN accounts a ON a.id = s.account_id
+		JOIN account_types at ON at.id = a.account_type
+		WHERE s.status = '1'
+		  AND t.status = '1'
+		  AND a.building_id = ?
+		GROUP BY a.id, a.account_number, a.account_name, at.type, at.typeName, at.typeStatus, at.cash_flow_category, date_range
+		HAVING date_range IS NOT NULL
+		ORDER BY a.account_number, date_range
+	`
+	args = append(args, buildingID)
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []AccountRangeTotal
+	for rows.Next() {
+		var total AccountRangeTotal
+		if err := rows.Scan(
+			&total.AccountID,
+			&total.AccountNumber,
+			&total.AccountName,
+			&total.AccountType,
+			&total.AccountTypeName,
+			&total.AccountTypeStatus,
+			&total.CashFlowCategory,
+			&total.Range,
+			&total.DebitCents,
+			&total.CreditCents,
+		); err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+	return totals, nil
+}
+
+// UnsettledDocumentSplit is a split of a posted invoice or bill together with how much of the
+// document was still unsettled as of a date. Cash basis reports take that share of every split
+// back out, so a document only counts as far as it has been paid.
+type UnsettledDocumentSplit struct {
+	AccountID         int
+	AccountNumber     int
+	AccountName       string
+	AccountType       string // Asset, Liability, Equity, Income or Expense
+	AccountTypeName   string
+	AccountTypeStatus string
+	DebitCents        int64
+	CreditCents       int64
+	DocumentCents     int64
+	UnsettledCents    int64
+}
+
+// GetUnsettledDocumentSplits returns the splits of the invoices and bills posted on or before
+// asOfDate that were not fully settled by then. Payments, applied credits and discounts all
+// settle a document.
+func (s *ReportStore) GetUnsettledDocumentSplits(ctx context.Context, buildingID int, asOfDate string) ([]UnsettledDocumentSplit, error) {
+	query := `
+		SELECT a.id, a.account_number, a.account_name, at.type, at.typeName, at.typeStatus,
+			COALESCE(s.debit_cents, 0), COALESCE(s.credit_cents, 0), d.amount_cents, d.unsettled_cents
+		FROM (
+			SELECT i.transaction_id, i.amount_cents,
+				i.amount_cents
+					- COALESCE((SELECT SUM(ip.amount_cents) FROM invoice_payments ip WHERE ip.invoice_id = i.id AND ip.status = '1' AND ip.date <= ?), 0)
+					- COALESCE((SELECT SUM(iac.amount_cents) FROM invoice_applied_credits iac WHERE iac.invoice_id = i.id AND iac.status = '1' AND iac.date <= ?), 0)
+					- COALESCE((SELECT SUM(iad.amount_cents) FROM invoice_applied_discounts iad WHERE iad.invoice_id = i.id AND iad.status = '1' AND iad.date <= ?), 0)
+					AS unsettled_cents
+			FROM invoices i
+			WHERE i.building_id = ? AND i.status = '1'
+			UNION ALL
+			SELECT b.transaction_id, b.amount_cents,
+				b.amount_cents
+					- COALESCE((SELECT SUM(bp.amount_cents) FROM bill_payments bp WHERE bp.bill_id = b.id AND bp.status = '1' AND bp.date <= ?), 0)
+					- COALESCE((SELECT SUM(bac.amount_cents) FROM bill_applied_credits bac WHERE bac.bill_id = b.id AND bac.status = '1' AND bac.date <= ?), 0)
+					AS unsettled_cents
+			FROM bills b
+			WHERE b.building_id = ? AND b.status = '1' AND b.approval_status = 'approved'
+		) d
+		JOIN transactions t ON t.id = d.transaction_id
+		JOIN splits s ON s.transaction_id = t.id
+		JOIN accounts a ON a.id = s.account_id
+		JOIN account_types at ON at.id = a.account_type
+		WHERE s.status = '1'
+		  AND t.status = '1'
+		  AND t.transaction_date <= ?
+		  AND d.amount_cents <> 0
+		  AND d.unsettled_cents <> 0
+	`
+
+	args := []any{asOfDate, asOfDate, asOfDate, buildingID, asOfDate, asOfDate, buildingID, asOfDate}
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var splits []UnsettledDocumentSplit
+	for rows.Next() {
+		var split UnsettledDocumentSplit
+		if err := rows.Scan(
+			&split.AccountID,
+			&split.AccountNumber,
+			&split.AccountName,
+			&split.AccountType,
+			&split.AccountTypeName,
+			&split.AccountTypeStatus,
+			&split.DebitCents,
+			&split.CreditCents,
+			&split.DocumentCents,
+			&split.UnsettledCents,
+		); err != nil {
+			return nil, err
+		}
+		splits = append(splits, split)
+	}
+	return splits, nil
+}
+
+// Customer statement lines (invoices, payments, applied credits, discounts and unapplied credits)
+type CustomerStatementLine struct {
+	PeopleID    int
+	PeopleName  string
+	Date        string
+	Type        string // invoice, payment, credit memo, discount, unapplied credit
+	Reference   string
+	Description string
+	InvoiceID   int // 0 for an unapplied credit
+	InvoiceNo   string
+	DueDate     string
+	AmountCents int64 // positive increases the balance, negative decreases it
+}
+
+func (s *ReportStore) GetCustomerStatementLines(ctx context.Context, buildingID int, endDate string, peopleID *int) ([]CustomerStatementLine, error) {
+	query := `
+		SELECT l.people_id, l.people_name, l.date, l.type, l.reference, l.description,
+			l.invoice_id, l.invoice_no, l.due_date, l.amount_cents
+		FROM (
+			SELECT i.people_id, p.name people_name, DATE_FORMAT(i.sales_date, '%Y-%m-%d') date,
+				'invoice' type, i.invoice_no reference, i.description,
+				i.id invoice_id, i.invoice_no, DATE_FORMAT(i.due_date, '%Y-%m-%d') due_date,
+				IFNULL(i.amount_cents, 0) amount_cents, 1 sort_order
+			FROM invoices i
+			JOIN people p ON p.id = i.people_id
+			WHERE i.status = '1' AND i.building_id = ? AND i.sales_date <= ?
+
+			UNION ALL
+
+			SELECT i.people_id, p.name, DATE_FORMAT(ip.date, '%Y-%m-%d'),
+				'payment', ip.reference, CONCAT('Payment - Invoice #', i.invoice_no),
+				i.id, i.invoice_no, DATE_FORMAT(i.due_date, '%Y-%m-%d'),
+				-ip.amount_cents, 2
+			FROM invoice_payments ip
+			JOIN invoices i ON i.id = ip.invoice_id AND i.status = '1'
+			JOIN people p ON p.id = i.people_id
+			WHERE ip.status = '1' AND i.building_id = ? AND ip.date <= ?
+
+			UNION ALL
+
+			SELECT i.people_id, p.name, DATE_FORMAT(iac.date, '%Y-%m-%d'),
+				'credit memo', cm.reference, iac.description,
+				i.id, i.invoice_no, DATE_FORMAT(i.due_date, '%Y-%m-%d'),
+				-iac.amount_cents, 3
+			FROM invoice_applied_credits iac
+			JOIN invoices i ON i.id = iac.invoice_id AND i.status = '1'
+			JOIN credit_memo cm ON cm.id = iac.credit_memo_id
+			JOIN people p ON p.id = i.people_id
+			WHERE iac.status = '1' AND i.building_id = ? AND iac.date <= ?
+
+			UNION ALL
+
+			SELECT i.people_id, p.name, DATE_FORMAT(iad.date, '%Y-%m-%d'),
+				'discount', iad.reference, iad.description,
+				i.id, i.invoice_no, DATE_FORMAT(i.due_date, '%Y-%m-%d'),
+				-iad.amount_cents, 4
+			FROM invoice_applied_discounts iad
+			JOIN invoices i ON i.id = iad.invoice_id AND i.status = '1'
+			JOIN people p ON p.id = i.people_id
+			WHERE iad.status = '1' AND i.building_id = ? AND iad.date <= ?
+
+			UNION ALL
+
+			-- what is left of a credit memo, including payment remainders and overpayments, after the credits applied by the end date
+			SELECT cm.people_id, p.name, DATE_FORMAT(cm.date, '%Y-%m-%d'),
+				'unapplied credit', cm.reference, cm.description,
+				0, '', '',
+				-(cm.amount_cents - COALESCE((SELECT SUM(iac.amount_cents) FROM invoice_applied_credits iac
+					WHERE iac.credit_memo_id = cm.id AND iac.status = '1' AND iac.date <= ?), 0)), 5
+			FROM credit_memo cm
+			JOIN people p ON p.id = cm.people_id
+			WHERE cm.status = '1' AND cm.building_id = ? AND cm.date <= ?
+		) l
+		WHERE NOT (l.type = 'unapplied credit' AND l.amount_cents = 0)
+	`
+
+	args := []any{buildingID, endDate, buildingID, endDate, buildingID, endDate, buildingID, endDate, endDate, buildingID, endDate}
+
+	if peopleID != nil {
+		query += " AND l.people_id = ?"
+		args = append(args, *peopleID)
+	}
+
+	query += " ORDER BY l.people_id, l.date, l.sort_order, l.invoice_id"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []CustomerStatementLine
+	for rows.Next() {
+		var line CustomerStatementLine
+		if err := rows.Scan(
+			&line.PeopleID,
+			&line.PeopleName,
+			&line.Date,
+			&line.Type,
+			&line.Reference,
+			&line.Description,
+			&line.InvoiceID,
+			&line.InvoiceNo,
+			&line.DueDate,
+			&line.AmountCents,
+		); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// Open invoices with their remaining balance as of a date
+type OpenInvoice struct {
+	InvoiceID    int
+	InvoiceNo    string
+	SalesDate    string
+	DueDate      string
+	PeopleID     int
+	PeopleName   string
+	UnitID       *int
+	UnitName     *string
+	AmountCents  int64
+	BalanceCents int64
+}
+
+func (s *ReportStore) GetOpenInvoices(ctx context.Context, buildingID int, asOfDate string) ([]OpenInvoice, error) {
+	query := `
+		SELECT i.id, i.invoice_no, DATE_FORMAT(i.sales_date, '%Y-%m-%d'), DATE_FORMAT(i.due_date, '%Y-%m-%d'),
+			i.people_id, p.name, i.unit_id, u.name, i.amount_cents,
+			i.amount_cents
+				- COALESCE((SELECT SUM(ip.amount_cents) FROM invoice_payments ip WHERE ip.invoice_id = i.id AND ip.status = '1' AND ip.date <= ?), 0)
+				- COALESCE((SELECT SUM(iac.amount_cents) FROM invoice_applied_credits iac WHERE iac.invoice_id = i.id AND iac.status = '1' AND iac.date <= ?), 0)
+				- COALESCE((SELECT SUM(iad.amount_cents) FROM invoice_applied_discounts iad WHERE iad.invoice_id = i.id AND iad.status = '1' AND iad.date <= ?), 0)
+				AS balance_cents
+		FROM invoices i
+		JOIN people p ON p.id = i.people_id
+		LEFT JOIN units u ON u.id = i.unit_id
+		WHERE i.building_id = ? AND i.status = '1' AND i.sales_date <= ?
+		HAVING balance_cents <> 0
+		ORDER BY p.name, i.due_date, i.id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, asOfDate, asOfDate, asOfDate, buildingID, asOfDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []OpenInvoice
+	for rows.Next() {
+		var invoice OpenInvoice
+		if err := rows.Scan(
+			&invoice.InvoiceID,
+			&invoice.InvoiceNo,
+			&invoice.SalesDate,
+			&invoice.DueDate,
+			&invoice.PeopleID,
+			&invoice.PeopleName,
+			&invoice.UnitID,
+			&invoice.UnitName,
+			&invoice.AmountCents,
+			&invoice.BalanceCents,
+		); err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, invoice)
+	}
+	return invoices, nil
+}
+
+// Open bills with their remaining balance as of a date.
+// PaymentAccount is the account the bill is planned to be paid from, if one was picked.
+type OpenBill struct {
+	BillID             int
+	BillNo             string
+	BillDate           string
+	DueDate            string
+	PeopleID           *int
+	PeopleName         *string
+	APAccountID        int
+	PaymentAccountID   *int
+	PaymentAccountName *string
+	AmountCents        int64
+	BalanceCents       int64
+}
+
+func (s *ReportStore) GetOpenBills(ctx context.Context, buildingID int, asOfDate string) ([]OpenBill, error) {
+	query := `
+		SELECT b.id, b.bill_no, DATE_FORMAT(b.bill_date, '%Y-%m-%d'), DATE_FORMAT(b.due_date, '%Y-%m-%d'),
+			b.people_id, p.name, b.ap_account_id,
+			pa.id, pa.account_name,
+			b.amount_cents,
+			b.amount_cents
+				- COALESCE((SELECT SUM(bp.amount_cents) FROM bill_payments bp WHERE bp.bill_id = b.id AND bp.status = '1' AND bp.date <= ?), 0)
+				- COALESCE((SELECT SUM(bac.amount_cents) FROM bill_applied_credits bac WHERE bac.bill_id = b.id AND bac.status = '1' AND bac.date <= ?), 0)
+				AS balance_cents
+		FROM bills b
+		LEFT JOIN people p ON p.id = b.people_id
+		LEFT JOIN accounts pa ON pa.id = b.payment_account_id
+		WHERE b.building_id = ? AND b.status = '1' AND b.approval_status = 'approved' AND b.bill_date <= ?
+		HAVING balance_cents <> 0
+		ORDER BY p.name, b.due_date, b.id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, asOfDate, asOfDate, buildingID, asOfDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bills []OpenBill
+	for rows.Next() {
+		var bill OpenBill
+		if err := rows.Scan(
+			&bill.BillID,
+			&bill.BillNo,
+			&bill.BillDate,
+			&bill.DueDate,
+			&bill.PeopleID,
+			&bill.PeopleName,
+			&bill.APAccountID,
+			&bill.PaymentAccountID,
+			&bill.PaymentAccountName,
+			&bill.AmountCents,
+			&bill.BalanceCents,
+		); err != nil {
+			return nil, err
+		}
+		bills = append(bills, bill)
+	}
+	return bills, nil
+}
+
+// InventoryValuationRow is an inventory item's stock as of a date: its stock today
+// less everything that moved after the date
+type InventoryValuationRow struct {
+	ItemID           int
+	ItemName         string
+	AssetAccountID   *int
+	AssetAccountName *string
+	QtyScaled        int64
+	ValueCents       int64
+}
+
+func (s *ReportStore) GetInventoryValuation(ctx context.Context, buildingID int, asOfDate string) ([]InventoryValuationRow, error) {
+	query := `
+		SELECT i.id, i.name, i.asset_account, a.account_name,
+			i.on_hand_scaled - COALESCE(SUM(m.qty_scaled), 0),
+			i.value_cents - COALESCE(SUM(m.cost_cents), 0)
+		FROM items i
+		LEFT JOIN accounts a ON a.id = i.asset_account
+		LEFT JOIN inventory_movements m ON m.item_id = i.id AND m.movement_date > ?
+		WHERE i.building_id = ? AND i.type = 'inventory'
+		GROUP BY i.id, i.name, i.asset_account, a.account_name, i.on_hand_scaled, i.value_cents
+		ORDER BY i.name
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, asOfDate, buildingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []InventoryValuationRow
+	for rows.Next() {
+		var item InventoryValuationRow
+		if err := rows.Scan(
+			&item.ItemID,
+			&item.ItemName,
+			&item.AssetAccountID,
+			&item.AssetAccountName,
+			&item.QtyScaled,
+			&item.ValueCents,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// InventoryMovementLine is a stock movement with the bill, invoice, sales receipt or
+// adjustment it came from
+type InventoryMovementLine struct {
+	ItemID        int
+	ItemName      string
+	TransactionID int
+	SourceType    string
+	Date          string
+	Number        string
+	Memo          string
+	QtyScaled     int64
+	CostCents     int64
+}
+
+func (s *ReportStore) GetInventoryMovementLines(ctx context.Context, buildingID int, startDate string, endDate string, itemID *int) ([]InventoryMovementLine, error) {
+	query := `
+		SELECT m.item_id, i.name, m.transaction_id, m.source_type, DATE_FORMAT(m.movement_date, '%Y-%m-%d'),
+			t.transaction_number, t.memo, m.qty_scaled, m.cost_cents
+		FROM inventory_movements m
+		JOIN items i ON i.id = m.item_id
+		JOIN transactions t ON t.id = m.transaction_id
+		WHERE m.building_id = ? AND m.movement_date BETWEEN ? AND ?
+	`
+
+	args := []any{buildingID, startDate, endDate}
+
+	if itemID != nil {
+		query += " AND m.item_id = ?"
+		args = append(args, *itemID)
+	}
+
+	query += " ORDER BY i.name, m.item_id, m.movement_date, m.id"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []InventoryMovementLine
+	for rows.Next() {
+		var line InventoryMovementLine
+		if err := rows.Scan(
+			&line.ItemID,
+			&line.ItemName,
+			&line.TransactionID,
+			&line.SourceType,
+			&line.Date,
+			&line.Number,
+			&line.Memo,
+			&line.QtyScaled,
+			&line.CostCents,
+		); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// TaxSummaryRow is the tax a code collected on sales (output) or paid on bills (input) in a period
+type TaxSummaryRow struct {
+	TaxCodeID    int
+	Code         string
+	Name         string
+	RateScaled   int64
+	Direction    string // output | input
+	TaxableCents int64
+	TaxCents     int64
+}
+
+// GetTaxSummary sums the tax recorded on invoice, sales receipt and approved bill lines dated in the period
+func (s *ReportStore) GetTaxSummary(ctx context.Context, buildingID int, startDate string, endDate string) ([]TaxSummaryRow, error) {
+	query := `
+		SELECT tc.id, tc.code, tc.name, tc.rate_scaled, t.direction, SUM(t.taxable_cents), SUM(t.tax_cents)
+		FROM (
+			SELECT ii.tax_code_id, 'output' AS direction, ii.taxable_cents, ii.tax_cents
+			FROM invoice_items ii
+			JOIN invoices i ON i.id = ii.invoice_id
+			WHERE i.building_id = ? AND i.status = '1' AND i.sales_date BETWEEN ? AND ?
+				AND ii.tax_code_id IS NOT NULL
+
+			UNION ALL
+
+			SELECT ri.tax_code_id, 'output', ri.taxable_cents, ri.tax_cents
+			FROM receipt_items ri
+			JOIN sales_receipt sr ON sr.id = ri.receipt_id
+			WHERE sr.building_id = ? AND sr.status = '1' AND ri.status = '1'
+				AND DATE(sr.receipt_date) BETWEEN ? AND ? AND ri.tax_code_id IS NOT NULL
+
+			UNION ALL
+
+			SELECT bel.tax_code_id, 'input', bel.taxable_cents, bel.tax_cents
+			FROM bill_expense_lines bel
+			JOIN bills b ON b.id = bel.bill_id
+			WHERE b.building_id = ? AND b.status = '1' AND b.approval_status = 'approved'
+				AND b.bill_date BETWEEN ? AND ? AND bel.tax_code_id IS NOT NULL
+		) t
+		JOIN tax_codes tc ON tc.id = t.tax_code_id
+		GROUP BY tc.id, tc.code, tc.name, tc.rate_scaled, t.direction
+		ORDER BY tc.code, t.direction DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query,
+		buildingID, startDate, endDate,
+		buildingID, startDate, endDate,
+		buildingID, startDate, endDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summary []TaxSummaryRow
+	for rows.Next() {
+		var row TaxSummaryRow
+		if err := rows.Scan(
+			&row.TaxCodeID,
+			&row.Code,
+			&row.Name,
+			&row.RateScaled,
+			&row.Direction,
+			&row.TaxableCents,
+			&row.TaxCents,
+		); err != nil {
+			return nil, err
+		}
+		summary = append(summary, row)
+	}
+	return summary, nil
+}
+
+// WithholdingRow is a bill payment that had withholding tax deducted
+type WithholdingRow struct {
+	BillPaymentID int64
+	Date          string
+	Reference     string
+	BillNo        string
+	PeopleID      int
+	PeopleName    string
+	RateScaled    int64
+	GrossCents    int64
+	WithheldCents int64
+}
+
+// GetWithholdingRegister lists the active bill payments in the period with tax withheld, by vendor
+func (s *ReportStore) GetWithholdingRegister(ctx context.Context, buildingID int, startDate string, endDate string, peopleID *int) ([]WithholdingRow, error) {
+	query := `
+		SELECT bp.id, DATE_FORMAT(bp.date, '%Y-%m-%d'), bp.reference, b.bill_no, p.id, p.name,
+		       bp.withholding_rate_scaled, bp.amount_cents, bp.withholding_cents
+		FROM bill_payments bp
+		JOIN bills b ON b.id = bp.bill_id
+		JOIN people p ON p.id = b.people_id
+		WHERE b.building_id = ? AND bp.status = '1' AND bp.withholding_cents > 0
+			AND DATE(bp.date) BETWEEN ? AND ?
+	`
+	args := []any{buildingID, startDate, endDate}
+
+	if peopleID != nil {
+		query += " AND b.people_id = ?"
+		args = append(args, *peopleID)
+	}
+
+	query += " ORDER BY p.name, p.id, bp.date, bp.id"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var register []WithholdingRow
+	for rows.Next() {
+		var row WithholdingRow
+		if err := rows.Scan(
+			&row.BillPaymentID,
+			&row.Date,
+			&row.Reference,
+			&row.BillNo,
+			&row.PeopleID,
+			&row.PeopleName,
+			&row.RateScaled,
+			&row.GrossCents,
+			&row.WithheldCents,
+		); err != nil {
+			return nil, err
+		}
+		register = append(register, row)
+	}
+	return register, nil
+}
@@ -21,6 +21,7 @@ type Split struct {
 	UpdatedAt     string   `json:"updated_at"`
 	DebitCents    *int64   `json:"debit_cents"`
 	CreditCents   *int64   `json:"credit_cents"`
+	ForeignCents  *int64   `json:"foreign_cents"` // in the account's currency, debit positive; nil in base currency
 
 	// relationships
 	Account Account `json:"account"`
@@ -79,6 +80,7 @@ func (s *SplitStore) GetAll(ctx context.Context, transactionID int64) ([]Split,
 			&sp.People.Name,
 			&sp.DebitCents,
 			&sp.CreditCents,
+			&sp.ForeignCents,
 		); err != nil {
 			return nil, err
 		}
@@ -91,7 +93,7 @@ func (s *SplitStore) GetAll(ctx context.Context, transactionID int64) ([]Split,
 // GetByID returns a single split by ID
 func (s *SplitStore) GetByID(ctx context.Context, id int64) (*Split, error) {
 	query := `
-		SELECT id, transaction_id, account_id, debit,credit,unit_id,people_id, status, created_at, updated_at, debit_cents, credit_cents
+		SELECT id, transaction_id, account_id, debit,credit,unit_id,people_id, status, created_at, updated_at, debit_cents, credit_cents, foreign_cents
 		FROM splits
 		WHERE id = ?
 	`
@@ -113,6 +115,7 @@ func (s *SplitStore) GetByID(ctx context.Context, id int64) (*Split, error) {
 		&sp.UpdatedAt,
 		&sp.DebitCents,
 		&sp.CreditCents,
+		&sp.ForeignCents,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -127,7 +130,7 @@ func (s *SplitStore) GetByID(ctx context.Context, id int64) (*Split, error) {
 func (s *SplitStore) GetByTransactionID(ctx context.Context, transactionID int64) ([]Split, error) {
 
 	query := `
-		SELECT id, transaction_id, account_id, debit,credit,unit_id,people_id, status, created_at, updated_at, debit_cents, credit_cents
+		SELECT id, transaction_id, account_id, debit,credit,unit_id,people_id, status, created_at, updated_at, debit_cents, credit_cents, foreign_cents
 		FROM splits
 		WHERE transaction_id = ?
 	`
@@ -159,6 +162,7 @@ func (s *SplitStore) GetByTransactionID(ctx context.Context, transactionID int64
 			&sp.UpdatedAt,
 			&sp.DebitCents,
 			&sp.CreditCents,
+			&sp.ForeignCents,
 		); err != nil {
 			return nil, err
 		}
@@ -171,8 +175,8 @@ func (s *SplitStore) GetByTransactionID(ctx context.Context, transactionID int64
 // Create inserts a new split
 func (s *SplitStore) Create(ctx context.Context, tx *sql.Tx, sp *Split) error {
 	query := `
-		INSERT INTO splits (transaction_id, account_id, debit, credit, unit_id, people_id, status, debit_cents, credit_cents)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO splits (transaction_id, account_id, debit, credit, unit_id, people_id, status, debit_cents, credit_cents, foreign_cents)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
@@ -193,6 +197,7 @@ func (s *SplitStore) Create(ctx context.Context, tx *sql.Tx, sp *Split) error {
 		sp.Status,
 		sp.DebitCents,
 		sp.CreditCents,
+		sp.ForeignCents,
 	)
 	if err != nil {
 		return err
@@ -290,3 +295,54 @@ func (s *SplitStore) DeleteByTransactionID(ctx context.Context, tx *sql.Tx, tran
 
 	return nil
 }
+
+// GetLockReason reports why a transaction's splits must not be rebuilt, or "" when they can be.
+// Splits cleared by a finished reconciliation, banked by a deposit or matched to an imported
+// bank statement line are referenced by id. The splits stay locked until tx ends, so a
+// reconciliation or deposit cannot take them between the check and the rebuild.
+func (s *SplitStore) GetLockReason(ctx context.Context, tx *sql.Tx, transactionID int64) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	lockQuery := `SELECT id FROM splits WHERE transaction_id = ? AND status = '1' FOR UPDATE`
+	rows, err := tx.QueryContext(ctx, lockQuery, transactionID)
+	if err != nil {
+		return "", err
+	}
+	rows.Close()
+
+	query := `
+		SELECT
+			EXISTS (
+				SELECT 1 FROM reconciliation_items ri
+				JOIN reconciliations r ON r.id = ri.reconciliation_id
+				JOIN splits sp ON sp.id = ri.split_id
+				WHERE sp.transaction_id = ? AND sp.status = '1' AND r.status = 'finished'
+			),
+			EXISTS (
+				SELECT 1 FROM deposit_lines dl
+				JOIN splits sp ON sp.id = dl.split_id
+				WHERE sp.transaction_id = ? AND sp.status = '1'
+			),
+			EXISTS (
+				SELECT 1 FROM bank_statement_lines bsl
+				JOIN splits sp ON sp.id = bsl.split_id
+				WHERE sp.transaction_id = ? AND sp.status = '1'
+			)
+	`
+
+	var reconciled, deposited, matched bool
+	if err := tx.QueryRowContext(ctx, query, transactionID, transactionID, transactionID).Scan(&reconciled, &deposited, &matched); err != nil {
+		return "", err
+	}
+
+	switch {
+	case reconciled:
+		return "it has been cleared in a finished bank reconciliation", nil
+	case deposited:
+		return "it has been banked by a deposit", nil
+	case matched:
+		return "it has been matched to an imported bank statement line", nil
+	}
+	return "", nil
+}
@@ -48,6 +48,24 @@ type Storage struct {
 	Role *RoleStore
 	RolePermission *RolePermissionStore
 	UserBuildingRole *UserBuildingRoleStore
+	LateFeePolicy *LateFeePolicyStore
+	LateFeeAssessment *LateFeeAssessmentStore
+	ReceivedPayment *ReceivedPaymentStore
+	Deposit *DepositStore
+	Reconciliation *ReconciliationStore
+	BankStatement *BankStatementStore
+	BankRule *BankRuleStore
+	VendorCredit *VendorCreditStore
+	BillPaymentRun *BillPaymentRunStore
+	BillTemplate *BillTemplateStore
+	BillTemplateRun *BillTemplateRunStore
+	Approval *ApprovalStore
+	PurchaseOrder *PurchaseOrderStore
+	Inventory *InventoryStore
+	InventoryAdjustment *InventoryAdjustmentStore
+	TaxCode *TaxCodeStore
+	ExchangeRate *ExchangeRateStore
+	FXRevaluation *FXRevaluationStore
 }
 
 func NewStorage(db *sql.DB) Storage {
@@ -86,6 +104,24 @@ func NewStorage(db *sql.DB) Storage {
 		Role: &RoleStore{db},
 		RolePermission: &RolePermissionStore{db},
 		UserBuildingRole: &UserBuildingRoleStore{db},
+		LateFeePolicy: &LateFeePolicyStore{db},
+		LateFeeAssessment: &LateFeeAssessmentStore{db},
+		ReceivedPayment: &ReceivedPaymentStore{db},
+		Deposit: &DepositStore{db},
+		Reconciliation: &ReconciliationStore{db},
+		BankStatement: &BankStatementStore{db},
+		BankRule: &BankRuleStore{db},
+		VendorCredit: &VendorCreditStore{db},
+		BillPaymentRun: &BillPaymentRunStore{db},
+		BillTemplate: &BillTemplateStore{db},
+		BillTemplateRun: &BillTemplateRunStore{db},
+		Approval: &ApprovalStore{db},
+		PurchaseOrder: &PurchaseOrderStore{db},
+		Inventory: &InventoryStore{db},
+		InventoryAdjustment: &InventoryAdjustmentStore{db},
+		TaxCode: &TaxCodeStore{db},
+		ExchangeRate: &ExchangeRateStore{db},
+		FXRevaluation: &FXRevaluationStore{db},
 	}
 }
 
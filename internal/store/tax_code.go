@@ -0,0 +1,199 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+type TaxCode struct {
+	ID         int64  `json:"id"`
+	BuildingID int64  `json:"building_id"`
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	RateScaled int64  `json:"rate_scaled"` // percent, 5 decimals (money.RateScale)
+	Mode       string `json:"mode"`        // exclusive | inclusive
+	AccountID  int64  `json:"account_id"`  // liability account the tax is posted to
+	Status     string `json:"status"`      // enum('0','1')
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+
+	// relationships
+	AccountName string `json:"account_name"`
+}
+
+type TaxCodeStore struct {
+	db *sql.DB
+}
+
+func NewTaxCodeStore(db *sql.DB) *TaxCodeStore {
+	return &TaxCodeStore{db: db}
+}
+
+const taxCodeColumns = `
+	tc.id, tc.building_id, tc.code, tc.name, tc.rate_scaled, tc.mode, tc.account_id,
+	tc.status, tc.created_at, tc.updated_at, a.account_name
+`
+
+func scanTaxCode(scan func(dest ...any) error, tc *TaxCode) error {
+	return scan(
+		&tc.ID,
+		&tc.BuildingID,
+		&tc.Code,
+		&tc.Name,
+		&tc.RateScaled,
+		&tc.Mode,
+		&tc.AccountID,
+		&tc.Status,
+		&tc.CreatedAt,
+		&tc.UpdatedAt,
+		&tc.AccountName,
+	)
+}
+
+func (s *TaxCodeStore) GetAll(ctx context.Context, buildingID int64) ([]TaxCode, error) {
+	query := `
+		SELECT ` + taxCodeColumns + `
+		FROM tax_codes tc
+		JOIN accounts a ON a.id = tc.account_id
+		WHERE tc.building_id = ?
+		ORDER BY tc.code
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, buildingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codes []TaxCode
+	for rows.Next() {
+		var tc TaxCode
+		if err := scanTaxCode(rows.Scan, &tc); err != nil {
+			return nil, err
+		}
+		codes = append(codes, tc)
+	}
+
+	return codes, nil
+}
+
+func (s *TaxCodeStore) GetByID(ctx context.Context, id int64) (*TaxCode, error) {
+	query := `
+		SELECT ` + taxCodeColumns + `
+		FROM tax_codes tc
+		JOIN accounts a ON a.id = tc.account_id
+		WHERE tc.id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var tc TaxCode
+	if err := scanTaxCode(s.db.QueryRowContext(ctx, query, id).Scan, &tc); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &tc, nil
+}
+
+func (s *TaxCodeStore) Create(ctx context.Context, tc *TaxCode) error {
+	query := `
+		INSERT INTO tax_codes
+		(building_id, code, name, rate_scaled, mode, account_id, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(
+		ctx,
+		query,
+		tc.BuildingID,
+		tc.Code,
+		tc.Name,
+		tc.RateScaled,
+		tc.Mode,
+		tc.AccountID,
+		tc.Status,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	tc.ID = id
+	return nil
+}
+
+func (s *TaxCodeStore) Update(ctx context.Context, tc *TaxCode) error {
+	query := `
+		UPDATE tax_codes
+		SET code = ?, name = ?, rate_scaled = ?, mode = ?, account_id = ?, status = ?,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(
+		ctx,
+		query,
+		tc.Code,
+		tc.Name,
+		tc.RateScaled,
+		tc.Mode,
+		tc.AccountID,
+		tc.Status,
+		tc.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete fails on the foreign keys once a line uses the code; set it inactive instead
+func (s *TaxCodeStore) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM tax_codes WHERE id = ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,373 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+// VendorCredit is money a vendor owes back: it debits A/P and credits an expense account,
+// and its amount is used up by applying it against the vendor's open bills.
+type VendorCredit struct {
+	ID               int64  `json:"id"`
+	TransactionID    int64  `json:"transaction_id"`
+	Reference        string `json:"reference"`
+	Date             string `json:"date"`
+	PeopleID         int64  `json:"people_id"`
+	APAccountID      int64  `json:"ap_account_id"`
+	ExpenseAccountID int64  `json:"expense_account_id"`
+	UnitID           *int64 `json:"unit_id"`
+	AmountCents      int64  `json:"amount_cents"`
+	AppliedCents     int64  `json:"applied_cents"` // sum of active bill applications
+	Memo             string `json:"memo"`
+	UserID           int64  `json:"user_id"`
+	BuildingID       int64  `json:"building_id"`
+	Status           string `json:"status"`
+	CreatedAt        string `json:"created_at"`
+	UpdatedAt        string `json:"updated_at"`
+
+	// relationships
+	PeopleName string `json:"people_name"`
+}
+
+type BillAppliedCredit struct {
+	ID             int64  `json:"id"`
+	BillID         int64  `json:"bill_id"`
+	VendorCreditID int64  `json:"vendor_credit_id"`
+	AmountCents    int64  `json:"amount_cents"`
+	Description    string `json:"description"`
+	Date           string `json:"date"`
+	Status         string `json:"status"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+
+	// relationships
+	BillNo                string `json:"bill_no"`
+	VendorCreditReference string `json:"vendor_credit_reference"`
+}
+
+type VendorCreditStore struct {
+	db *sql.DB
+}
+
+func NewVendorCreditStore(db *sql.DB) *VendorCreditStore {
+	return &VendorCreditStore{db: db}
+}
+
+const vendorCreditColumns = `
+	vc.id, vc.transaction_id, vc.reference, DATE_FORMAT(vc.date, '%Y-%m-%d'), vc.people_id,
+	vc.ap_account_id, vc.expense_account_id, vc.unit_id, vc.amount_cents,
+	COALESCE((SELECT SUM(bac.amount_cents) FROM bill_applied_credits bac WHERE bac.vendor_credit_id = vc.id AND bac.status = '1'), 0),
+	vc.memo, vc.user_id, vc.building_id, vc.status, vc.created_at, vc.updated_at, p.name
+`
+
+func scanVendorCredit(scan func(dest ...any) error, vc *VendorCredit) error {
+	return scan(
+		&vc.ID,
+		&vc.TransactionID,
+		&vc.Reference,
+		&vc.Date,
+		&vc.PeopleID,
+		&vc.APAccountID,
+		&vc.ExpenseAccountID,
+		&vc.UnitID,
+		&vc.AmountCents,
+		&vc.AppliedCents,
+		&vc.Memo,
+		&vc.UserID,
+		&vc.BuildingID,
+		&vc.Status,
+		&vc.CreatedAt,
+		&vc.UpdatedAt,
+		&vc.PeopleName,
+	)
+}
+
+// GetAll lists the building's vendor credits; openOnly keeps those with an unapplied amount
+func (s *VendorCreditStore) GetAll(ctx context.Context, buildingID int64, peopleID *int64, openOnly bool) ([]VendorCredit, error) {
+	query := `
+		SELECT ` + vendorCreditColumns + `
+		FROM vendor_credits vc
+		JOIN people p ON p.id = vc.people_id
+		WHERE vc.building_id = ? AND vc.status = '1'
+	`
+
+	args := []any{buildingID}
+
+	if peopleID != nil {
+		query += " AND vc.people_id = ?"
+		args = append(args, *peopleID)
+	}
+
+	if openOnly {
+		query += ` AND vc.amount_cents > COALESCE((SELECT SUM(bac.amount_cents) FROM bill_applied_credits bac WHERE bac.vendor_credit_id = vc.id AND bac.status = '1'), 0)`
+	}
+
+	query += " ORDER BY vc.date DESC, vc.id DESC"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var credits []VendorCredit
+	for rows.Next() {
+		var vc VendorCredit
+		if err := scanVendorCredit(rows.Scan, &vc); err != nil {
+			return nil, err
+		}
+		credits = append(credits, vc)
+	}
+
+	return credits, nil
+}
+
+func (s *VendorCreditStore) GetByID(ctx context.Context, id int64) (*VendorCredit, error) {
+	query := `
+		SELECT ` + vendorCreditColumns + `
+		FROM vendor_credits vc
+		JOIN people p ON p.id = vc.people_id
+		WHERE vc.id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var vc VendorCredit
+	if err := scanVendorCredit(s.db.QueryRowContext(ctx, query, id).Scan, &vc); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &vc, nil
+}
+
+// GetAvailableTx returns amount less applications, locking the credit row so concurrent applications see each other
+func (s *VendorCreditStore) GetAvailableTx(ctx context.Context, tx *sql.Tx, id int64) (int64, error) {
+	query := `
+		SELECT vc.amount_cents
+			- COALESCE((SELECT SUM(bac.amount_cents) FROM bill_applied_credits bac WHERE bac.vendor_credit_id = vc.id AND bac.status = '1'), 0)
+		FROM vendor_credits vc
+		WHERE vc.id = ?
+		FOR UPDATE
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var available int64
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&available); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+
+	return available, nil
+}
+
+// ExistsByTransactionIDTx reports whether a vendor credit was recorded on a transaction, as the
+// excess of a bill payment is
+func (s *VendorCreditStore) ExistsByTransactionIDTx(ctx context.Context, tx *sql.Tx, transactionID int64) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM vendor_credits WHERE transaction_id = ? AND status = '1')`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, query, transactionID).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (s *VendorCreditStore) Create(ctx context.Context, tx *sql.Tx, vc *VendorCredit) error {
+	query := `
+		INSERT INTO vendor_credits
+		(transaction_id, reference, date, people_id, ap_account_id, expense_account_id,
+		 unit_id, amount_cents, memo, user_id, building_id, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		vc.TransactionID,
+		vc.Reference,
+		vc.Date,
+		vc.PeopleID,
+		vc.APAccountID,
+		vc.ExpenseAccountID,
+		vc.UnitID,
+		vc.AmountCents,
+		vc.Memo,
+		vc.UserID,
+		vc.BuildingID,
+		vc.Status,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	vc.ID = id
+	return nil
+}
+
+func (s *VendorCreditStore) Update(ctx context.Context, tx *sql.Tx, vc *VendorCredit) error {
+	query := `
+		UPDATE vendor_credits
+		SET reference = ?, date = ?, people_id = ?, ap_account_id = ?, expense_account_id = ?,
+		    unit_id = ?, amount_cents = ?, memo = ?, user_id = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		vc.Reference,
+		vc.Date,
+		vc.PeopleID,
+		vc.APAccountID,
+		vc.ExpenseAccountID,
+		vc.UnitID,
+		vc.AmountCents,
+		vc.Memo,
+		vc.UserID,
+		vc.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// GetApplications returns active applications filtered by bill, vendor credit, or both
+func (s *VendorCreditStore) GetApplications(ctx context.Context, billID, vendorCreditID *int64) ([]BillAppliedCredit, error) {
+	query := `
+		SELECT bac.id, bac.bill_id, bac.vendor_credit_id, bac.amount_cents, bac.description,
+		       DATE_FORMAT(bac.date, '%Y-%m-%d'), bac.status, bac.created_at, bac.updated_at,
+		       b.bill_no, vc.reference
+		FROM bill_applied_credits bac
+		JOIN bills b ON b.id = bac.bill_id
+		JOIN vendor_credits vc ON vc.id = bac.vendor_credit_id
+		WHERE bac.status = '1'
+	`
+
+	args := []any{}
+
+	if billID != nil {
+		query += " AND bac.bill_id = ?"
+		args = append(args, *billID)
+	}
+	if vendorCreditID != nil {
+		query += " AND bac.vendor_credit_id = ?"
+		args = append(args, *vendorCreditID)
+	}
+
+	query += " ORDER BY bac.date, bac.id"
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var applications []BillAppliedCredit
+	for rows.Next() {
+		var a BillAppliedCredit
+		if err := rows.Scan(
+			&a.ID,
+			&a.BillID,
+			&a.VendorCreditID,
+			&a.AmountCents,
+			&a.Description,
+			&a.Date,
+			&a.Status,
+			&a.CreatedAt,
+			&a.UpdatedAt,
+			&a.BillNo,
+			&a.VendorCreditReference,
+		); err != nil {
+			return nil, err
+		}
+		applications = append(applications, a)
+	}
+
+	return applications, nil
+}
+
+func (s *VendorCreditStore) CreateApplication(ctx context.Context, tx *sql.Tx, a *BillAppliedCredit) error {
+	query := `
+		INSERT INTO bill_applied_credits
+		(bill_id, vendor_credit_id, amount_cents, description, date, status)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, a.BillID, a.VendorCreditID, a.AmountCents, a.Description, a.Date, a.Status)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	a.ID = id
+	return nil
+}
+
+// DeleteApplication removes an application, giving its amount back to both the bill and the credit
+func (s *VendorCreditStore) DeleteApplication(ctx context.Context, id int64) error {
+	query := `DELETE FROM bill_applied_credits WHERE id = ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeOutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}